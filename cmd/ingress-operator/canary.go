@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+)
+
+// serveHealthCheckCanaryCommand is the subcommand that the canary daemonset
+// runs to serve the canary route.
+const serveHealthCheckCanaryCommand = "serve-healthcheck-canary"
+
+// serveHealthCheckCanary serves a fixed response on the canary port until the
+// process exits.  The response echoes the port that received the request in a
+// header, so that a check of the canary route can tell that the router
+// forwarded the request to the canary service.
+func serveHealthCheckCanary(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: ingress-operator %s", serveHealthCheckCanaryCommand)
+	}
+	port := fmt.Sprintf("%d", controller.CanaryPort)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(controller.CanaryPortHeader, port)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, controller.CanaryResponse)
+	})
+	return http.ListenAndServe(":"+port, handler)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ghodss/yaml"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// dumpCommand is the subcommand that prints the operands that the operator
+// would apply for an ingresscontroller without applying them.
+const dumpCommand = "dump"
+
+// dump prints the rendered operands of the ingresscontroller with the given
+// name as a stream of YAML documents.  It loads the same configuration file and
+// environment variables as the operator, so it is meant to be run in the
+// operator's container:
+//
+//	oc exec -n openshift-ingress-operator deployments/ingress-operator -c ingress-operator -- ingress-operator dump default
+func dump(args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: ingress-operator %s <ingresscontroller name>", dumpCommand)
+	}
+	operatorConfig, err := operatorconfig.Load(os.Getenv)
+	if err != nil {
+		return fmt.Errorf("failed to load operator configuration: %v", err)
+	}
+	operatorNamespace := operatorConfig.Namespaces.Operator
+	operandNamespace := operatorConfig.Namespaces.Operand
+	if len(operandNamespace) == 0 {
+		operandNamespace = controller.DefaultOperandNamespace
+	}
+
+	kubeConfig, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get kube config: %v", err)
+	}
+	kubeClient, err := operatorclient.NewRefreshableClient(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kube client: %v", err)
+	}
+	ic := &operatorv1.IngressController{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: operatorNamespace, Name: args[0]}, ic); err != nil {
+		return fmt.Errorf("failed to get ingresscontroller %s/%s: %v", operatorNamespace, args[0], err)
+	}
+	infraConfig := &configv1.Infrastructure{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig); err != nil {
+		return fmt.Errorf("failed to get infrastructure 'config': %v", err)
+	}
+
+	objects, err := controller.DesiredOperands(kubeClient, controller.Config{
+		KubeConfig:             kubeConfig,
+		Namespace:              operatorNamespace,
+		OperandNamespace:       operandNamespace,
+		IngressControllerImage: operatorConfig.Images.IngressController,
+	}, ic, infraConfig)
+	if err != nil {
+		return fmt.Errorf("failed to render operands for ingresscontroller %s/%s: %v", ic.Namespace, ic.Name, err)
+	}
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %T: %v", obj, err)
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,6 +38,21 @@ const (
 var log = logf.Logger.WithName("entrypoint")
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == dumpCommand {
+		if err := dump(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == serveHealthCheckCanaryCommand {
+		if err := serveHealthCheckCanary(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	metrics.DefaultBindAddress = ":60000"
 
 	// Get a kube client.
@@ -51,22 +67,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Collect operator configuration.
-	operatorNamespace := os.Getenv("WATCH_NAMESPACE")
-	if len(operatorNamespace) == 0 {
-		log.Error(fmt.Errorf("missing environment variable"), "'WATCH_NAMESPACE' environment variable must be set")
-		os.Exit(1)
-	}
-	ingressControllerImage := os.Getenv("IMAGE")
-	if len(ingressControllerImage) == 0 {
-		log.Error(fmt.Errorf("missing environment variable"), "'IMAGE' environment variable must be set")
+	// Collect operator configuration from the configuration file and the
+	// environment.
+	operatorConfig, err := operatorconfig.Load(os.Getenv)
+	if err != nil {
+		log.Error(err, "failed to load operator configuration")
 		os.Exit(1)
 	}
-	releaseVersion := os.Getenv("RELEASE_VERSION")
-	if len(releaseVersion) == 0 {
-		releaseVersion = controller.UnknownVersionValue
+	if len(operatorConfig.OperatorReleaseVersion) == 0 {
 		log.Info("RELEASE_VERSION environment variable missing", "release version", controller.UnknownVersionValue)
 	}
+	operator.SetConfigDefaults(&operatorConfig)
+	if err := logf.SetLevel(operatorConfig.Logging.Level); err != nil {
+		log.Error(err, "failed to set log level")
+	}
 
 	// Retrieve the cluster infrastructure config.
 	infraConfig := &configv1.Infrastructure{}
@@ -97,11 +111,18 @@ func main() {
 		os.Exit(1)
 	}
 
-	operatorConfig := operatorconfig.Config{
-		OperatorReleaseVersion: releaseVersion,
-		Namespace:              operatorNamespace,
-		IngressControllerImage: ingressControllerImage,
+	// Determine which experimental features the cluster's feature set
+	// enables.  The operator restarts if they change.
+	featureGate := &configv1.FeatureGate{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, featureGate); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "failed to get featuregate 'cluster'")
+			os.Exit(1)
+		}
+		featureGate = nil
 	}
+	operatorConfig.FeatureGates = operatorconfig.FeatureGatesFor(featureGate)
+	log.Info("enabled feature gates", "features", operatorConfig.FeatureGates)
 
 	// Set up the DNS manager.
 	dnsManager, err := createDNSManager(kubeClient, operatorConfig, infraConfig, dnsConfig, installConfig)
@@ -129,7 +150,7 @@ func createDNSManager(cl client.Client, operatorConfig operatorconfig.Config, in
 	switch infraConfig.Status.Platform {
 	case configv1.AWSPlatformType:
 		awsCreds := &corev1.Secret{}
-		err := cl.Get(context.TODO(), types.NamespacedName{Namespace: operatorConfig.Namespace, Name: cloudCredentialsSecretName}, awsCreds)
+		err := cl.Get(context.TODO(), types.NamespacedName{Namespace: operatorConfig.Namespaces.Operator, Name: cloudCredentialsSecretName}, awsCreds)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get aws creds from secret %s/%s: %v", awsCreds.Namespace, awsCreds.Name, err)
 		}
@@ -139,6 +160,7 @@ func createDNSManager(cl client.Client, operatorConfig operatorconfig.Config, in
 			AccessKey: string(awsCreds.Data["aws_secret_access_key"]),
 			DNS:       dnsConfig,
 			Region:    installConfig.Platform.AWS.Region,
+			ClusterID: infraConfig.Status.InfrastructureName,
 		}, operatorConfig.OperatorReleaseVersion)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create AWS DNS manager: %v", err)
@@ -0,0 +1,10 @@
+// +k8s:deepcopy-gen=package,register
+// +groupName=ingress.operator.openshift.io
+
+// Package v1 contains API Schema definitions for the ingress v1 API group.
+//
+// The ingress.operator.openshift.io API group has the resources that the
+// ingress operator defines for its own use and for other components that
+// integrate with ingress, such as DNSRecord.  The operator installs the CRDs
+// for these resources itself.
+package v1
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupName is the group name of the ingress API.
+	GroupName = "ingress.operator.openshift.io"
+	// GroupVersion is the group and version of the ingress v1 API.
+	GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1"}
+
+	schemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme adds the types in the ingress v1 API to a scheme.
+	AddToScheme = schemeBuilder.AddToScheme
+)
+
+// Resource takes an unqualified resource and returns a group-qualified
+// GroupResource.
+func Resource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion,
+		&DNSRecord{},
+		&DNSRecordList{},
+	)
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+	return nil
+}
@@ -0,0 +1,302 @@
+package v1
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="DNS Name",type=string,JSONPath=`.spec.dnsName`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targets[*]`
+// +kubebuilder:printcolumn:name="Zone",type=string,JSONPath=`.status.zones[*].dnsZone.id`
+// +kubebuilder:printcolumn:name="Published",type=string,JSONPath=`.status.zones[*].conditions[?(@.type=="Published")].status`
+
+// DNSRecord is a DNS record managed in the zones defined by
+// dns.config.openshift.io/cluster .spec.publicZone and .spec.privateZone.
+//
+// Cluster admin manipulation of this resource is not supported.  This resource
+// is only for internal communication of OpenShift operators.
+type DNSRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the specification of the desired behavior of the dnsRecord.
+	Spec DNSRecordSpec `json:"spec"`
+	// status is the most recently observed status of the dnsRecord.
+	Status DNSRecordStatus `json:"status,omitempty"`
+}
+
+// DNSRecordSpec contains the details of a DNS record.
+type DNSRecordSpec struct {
+	// dnsName is the hostname of the DNS record
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	DNSName string `json:"dnsName"`
+	// recordType is the DNS record type. For example, "A" or "CNAME".
+	//
+	// +required
+	RecordType DNSRecordType `json:"recordType"`
+	// targets are record targets.  A CNAME record must have exactly one
+	// target, which is a hostname.  An A record may have several targets,
+	// which are IPv4 addresses; they are published as a single record with
+	// multiple values so that resolvers spread clients across them.
+	//
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	Targets []string `json:"targets"`
+	// recordTTL is the record TTL in seconds. If zero, the default is 30.
+	// RecordTTL will not be used in AWS regions Alias targets, but
+	// will be used in CNAME targets, per AWS API contract.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RecordTTL int64 `json:"recordTTL,omitempty"`
+	// zoneType restricts the record to the cluster's public or private
+	// zone.  If empty, the record is published to both zones.
+	//
+	// +kubebuilder:validation:Enum=Public;Private
+	// +optional
+	ZoneType DNSZoneType `json:"zoneType,omitempty"`
+	// routingPolicy, if set, publishes the record as one of several
+	// records with the same name and type, such as the records of the
+	// clusters in an active-passive pair, among which the DNS provider
+	// chooses according to the policy.  Every record with the same name
+	// and type in a zone must have a routing policy of the same type.  If
+	// empty, the record is the only record with its name and type.
+	//
+	// Routing policies are only supported by the AWS Route 53 provider.
+	//
+	// +optional
+	RoutingPolicy *DNSRoutingPolicy `json:"routingPolicy,omitempty"`
+	// dryRun, if true, makes the dnsrecord controller compute the change
+	// that publishing the record requires in each zone and report it in
+	// status.zones[].plannedChange instead of applying it, so that an
+	// administrator who does not allow the cluster to change the zones can
+	// review the change and apply it.  A record that the operator already
+	// published is left as it is until the DNSRecord is deleted, and
+	// deleting the DNSRecord does not delete a record that the
+	// administrator applied.
+	//
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// DNSRoutingPolicy is the routing policy of a record that is published as one
+// of several records with the same name and type.
+type DNSRoutingPolicy struct {
+	// type is the type of the routing policy.  The field for the type
+	// must be set.
+	//
+	// +required
+	Type DNSRoutingPolicyType `json:"type"`
+	// setIdentifier distinguishes the record from the other records with
+	// the same name and type.  It must be unique among them.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	// +required
+	SetIdentifier string `json:"setIdentifier"`
+	// weighted is the policy for the Weighted type.
+	//
+	// +optional
+	Weighted *WeightedDNSRoutingPolicy `json:"weighted,omitempty"`
+	// failover is the policy for the Failover type.
+	//
+	// +optional
+	Failover *FailoverDNSRoutingPolicy `json:"failover,omitempty"`
+	// geolocation is the policy for the Geolocation type.
+	//
+	// +optional
+	Geolocation *GeolocationDNSRoutingPolicy `json:"geolocation,omitempty"`
+}
+
+// DNSRoutingPolicyType is a type of DNS routing policy.
+// +kubebuilder:validation:Enum=Weighted;Failover;Geolocation
+type DNSRoutingPolicyType string
+
+const (
+	// WeightedRoutingPolicyType answers queries with the records in
+	// proportion to their weights.
+	WeightedRoutingPolicyType DNSRoutingPolicyType = "Weighted"
+
+	// FailoverRoutingPolicyType answers queries with the primary record
+	// while it is healthy and with the secondary record otherwise.
+	FailoverRoutingPolicyType DNSRoutingPolicyType = "Failover"
+
+	// GeolocationRoutingPolicyType answers queries with the record for
+	// the location that the query originates from.
+	GeolocationRoutingPolicyType DNSRoutingPolicyType = "Geolocation"
+)
+
+// WeightedDNSRoutingPolicy is the policy of a Weighted record.
+type WeightedDNSRoutingPolicy struct {
+	// weight is the share of queries that the record answers relative to
+	// the sum of the weights of the records with the same name and type.
+	// A record with weight 0 answers no queries unless every record has
+	// weight 0.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	// +required
+	Weight int64 `json:"weight"`
+}
+
+// DNSFailoverRole is the role of a Failover record.
+// +kubebuilder:validation:Enum=Primary;Secondary
+type DNSFailoverRole string
+
+const (
+	// PrimaryFailoverRole answers queries while it is healthy.
+	PrimaryFailoverRole DNSFailoverRole = "Primary"
+
+	// SecondaryFailoverRole answers queries while the primary record is
+	// unhealthy.
+	SecondaryFailoverRole DNSFailoverRole = "Secondary"
+)
+
+// FailoverDNSRoutingPolicy is the policy of a Failover record.
+type FailoverDNSRoutingPolicy struct {
+	// role is the role of the record.
+	//
+	// +required
+	Role DNSFailoverRole `json:"role"`
+	// healthCheckID is the ID of a health check of the DNS provider that
+	// determines whether the record is healthy.  Records that resolve to
+	// a load balancer are also unhealthy while the load balancer has no
+	// healthy targets.
+	//
+	// +optional
+	HealthCheckID string `json:"healthCheckID,omitempty"`
+}
+
+// GeolocationDNSRoutingPolicy is the policy of a Geolocation record.  Exactly
+// one of continentCode or countryCode must be set.
+type GeolocationDNSRoutingPolicy struct {
+	// continentCode is the two-letter code of the continent, such as
+	// "EU", whose queries the record answers.
+	//
+	// +optional
+	ContinentCode string `json:"continentCode,omitempty"`
+	// countryCode is the two-letter ISO 3166 code of the country whose
+	// queries the record answers, or "*" for queries from locations that
+	// no other record covers.
+	//
+	// +optional
+	CountryCode string `json:"countryCode,omitempty"`
+	// subdivisionCode is the code of the subdivision of the country, such
+	// as a state of the United States, whose queries the record answers.
+	// It requires countryCode.
+	//
+	// +optional
+	SubdivisionCode string `json:"subdivisionCode,omitempty"`
+}
+
+// DNSZoneType is a type of DNS zone in the cluster DNS config.
+type DNSZoneType string
+
+const (
+	// PublicZoneType is the zone in dns.config.openshift.io/cluster
+	// .spec.publicZone.
+	PublicZoneType DNSZoneType = "Public"
+	// PrivateZoneType is the zone in dns.config.openshift.io/cluster
+	// .spec.privateZone.
+	PrivateZoneType DNSZoneType = "Private"
+)
+
+// DNSRecordStatus is the most recently observed status of each record.
+type DNSRecordStatus struct {
+	// zones are the status of the record in each zone.
+	Zones []DNSZoneStatus `json:"zones,omitempty"`
+
+	// observedGeneration is the most recently observed generation of the
+	// DNSRecord.  When the DNSRecord is updated, the controller updates the
+	// corresponding record in each managed zone.  If an update for a
+	// particular zone fails, that failure is recorded in the status
+	// condition for the zone so that the controller can determine that it
+	// needs to retry the update for that specific zone.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions are any conditions associated with the record as a whole
+	// rather than with a particular zone.
+	//
+	// If the cluster DNS config has a private zone but no public zone, the
+	// "PublishedPrivateOnly" condition is set with status "True" on
+	// records that would otherwise be published to the public zone, so
+	// that the missing public record is not mistaken for a failure.
+	// +optional
+	Conditions []DNSZoneCondition `json:"conditions,omitempty"`
+}
+
+// DNSZoneStatus is the status of a record within a specific zone.
+type DNSZoneStatus struct {
+	// dnsZone is the zone where the record is published.
+	DNSZone configv1.DNSZone `json:"dnsZone"`
+	// conditions are any conditions associated with the record in the zone.
+	//
+	// If publishing the record succeeds, the "Published" condition will be
+	// set with status "True" and upon failure it will be set to "False" along
+	// with the reason and message describing the cause of the failure.
+	Conditions []DNSZoneCondition `json:"conditions,omitempty"`
+	// plannedChange is the change that publishing the record in the zone
+	// requires, in the format of the DNS provider's API, if spec.dryRun is
+	// true.  For AWS, it is the input of the Route 53
+	// ChangeResourceRecordSets API in JSON, which the AWS CLI accepts with
+	// the --cli-input-json option.
+	//
+	// +optional
+	PlannedChange string `json:"plannedChange,omitempty"`
+}
+
+const (
+	// DNSRecordPublishedConditionType means the record is published to a
+	// zone.  The condition has reason "DryRun" or "DryRunUnsupported" if
+	// the record is a dry run, and is then true only if the operator
+	// published the record before the record became a dry run.
+	DNSRecordPublishedConditionType = "Published"
+
+	// DNSRecordPublishedPrivateOnlyConditionType means the record is not
+	// published to a public zone because the cluster DNS config has only a
+	// private zone.
+	DNSRecordPublishedPrivateOnlyConditionType = "PublishedPrivateOnly"
+)
+
+// DNSZoneCondition is just the standard condition fields.
+type DNSZoneCondition struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Type string `json:"type"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Status             string      `json:"status"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+}
+
+// DNSRecordType is a DNS resource record type.
+// +kubebuilder:validation:Enum=CNAME;A
+type DNSRecordType string
+
+const (
+	// CNAMERecordType is an RFC 1035 CNAME record.
+	CNAMERecordType DNSRecordType = "CNAME"
+
+	// ARecordType is an RFC 1035 A record.
+	ARecordType DNSRecordType = "A"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSRecordList contains a list of dnsrecords.
+type DNSRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSRecord `json:"items"`
+}
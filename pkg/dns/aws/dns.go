@@ -1,11 +1,13 @@
 package aws
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
 
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/dns"
 	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
 
@@ -26,8 +28,10 @@ import (
 )
 
 var (
-	_   dns.Manager = &Manager{}
-	log             = logf.Logger.WithName("dns")
+	_   dns.Manager             = &Manager{}
+	_   dns.Planner             = &Manager{}
+	_   dns.LoadBalancerDeleter = &Manager{}
+	log                         = logf.Logger.WithName("dns")
 )
 
 // Manager provides AWS DNS record management. In this implementation, calling
@@ -38,6 +42,7 @@ var (
 // compared to storing additional metadata (like tags or TXT records).
 type Manager struct {
 	elb     *elb.ELB
+	elbv2   *elbv2Client
 	route53 *route53.Route53
 	tags    *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
 
@@ -55,9 +60,11 @@ type Manager struct {
 	lbZones map[string]string
 
 	// updatedRecords is a cache of records which have been created or updated
-	// during the life of this manager. The key is zoneID+domain+target. This is a
-	// quick hack to minimize AWS API calls, and also prevent changes to existing
-	// records (something not yet supported).
+	// during the life of this manager. The key is zoneID+domain+target, or for
+	// A records the zone ID, domain, targets, and TTL, followed by the
+	// routing policy, if the record has one. This is a quick hack to
+	// minimize AWS API calls, and also prevent changes to existing records
+	// (something not yet supported).
 	updatedRecords sets.String
 }
 
@@ -71,8 +78,23 @@ type Config struct {
 	Region string
 	// DNS is public and private DNS zone configuration for the cluster.
 	DNS *configv1.DNS
+	// ClusterID is the infrastructure name of the cluster.  The manager
+	// deletes only load balancers that have the clusterTagKeyPrefix tag
+	// for it.
+	ClusterID string
 }
 
+const (
+	// clusterTagKeyPrefix is the prefix of the key of the tag with which
+	// the cloud provider marks the load balancers of a cluster.  The key
+	// ends with the cluster's infrastructure name.
+	clusterTagKeyPrefix = "kubernetes.io/cluster/"
+
+	// maxDescribeTagsLoadBalancers is the maximum number of load
+	// balancers whose tags one DescribeTags call returns.
+	maxDescribeTagsLoadBalancers = 20
+)
+
 func NewManager(config Config, operatorReleaseVersion string) (*Manager, error) {
 	creds := credentials.NewStaticCredentials(config.AccessID, config.AccessKey, "")
 	sess, err := session.NewSessionWithOptions(session.Options{
@@ -102,6 +124,7 @@ func NewManager(config Config, operatorReleaseVersion string) (*Manager, error)
 
 	return &Manager{
 		elb:     elb.New(sess, aws.NewConfig().WithRegion(region)),
+		elbv2:   newELBv2Client(sess, aws.NewConfig().WithRegion(region)),
 		route53: route53.New(sess),
 		// TODO: This API will only return hostedzone resources (which are global)
 		// when the region is forced to us-east-1. We don't yet understand why.
@@ -216,6 +239,128 @@ func (m *Manager) getLBHostedZone(name string) (string, error) {
 	return id, nil
 }
 
+// DeleteLoadBalancer deletes each classic ELB and each NLB that has the given
+// name or whose DNS name is one of the hostnames parameter and returns true if
+// it found any.  Load balancers that are not tagged as belonging to the
+// cluster are left alone and are not counted as found.
+func (m *Manager) DeleteLoadBalancer(name string, hostnames []string) (bool, error) {
+	if len(m.config.ClusterID) == 0 {
+		return false, fmt.Errorf("refusing to delete load balancers: the cluster ID is unknown")
+	}
+	matches := func(lbName, dnsName string) bool {
+		if len(name) != 0 && lbName == name {
+			return true
+		}
+		for _, hostname := range hostnames {
+			if strings.EqualFold(dnsName, hostname) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var classic []string
+	fn := func(resp *elb.DescribeLoadBalancersOutput, lastPage bool) (shouldContinue bool) {
+		for _, lb := range resp.LoadBalancerDescriptions {
+			if matches(aws.StringValue(lb.LoadBalancerName), aws.StringValue(lb.DNSName)) {
+				classic = append(classic, aws.StringValue(lb.LoadBalancerName))
+			}
+		}
+		return true
+	}
+	if err := m.elb.DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{}, fn); err != nil {
+		return false, fmt.Errorf("failed to describe load balancers: %v", err)
+	}
+	var arns []string
+	fnv2 := func(resp *elbv2DescribeLoadBalancersOutput) bool {
+		for _, lb := range resp.LoadBalancers {
+			if matches(aws.StringValue(lb.LoadBalancerName), aws.StringValue(lb.DNSName)) {
+				arns = append(arns, aws.StringValue(lb.LoadBalancerArn))
+			}
+		}
+		return true
+	}
+	if err := m.elbv2.describeLoadBalancersPages(fnv2); err != nil {
+		return false, fmt.Errorf("failed to describe network load balancers: %v", err)
+	}
+	classic, err := m.clusterOwned(classic, m.describeClassicTags)
+	if err != nil {
+		return false, fmt.Errorf("failed to describe load balancer tags: %v", err)
+	}
+	arns, err = m.clusterOwned(arns, m.elbv2.describeTags)
+	if err != nil {
+		return false, fmt.Errorf("failed to describe network load balancer tags: %v", err)
+	}
+	if len(classic) == 0 && len(arns) == 0 {
+		log.Info("load balancer not found; nothing to delete", "name", name, "dns names", hostnames)
+		return false, nil
+	}
+
+	for _, lbName := range classic {
+		if _, err := m.elb.DeleteLoadBalancer(&elb.DeleteLoadBalancerInput{LoadBalancerName: aws.String(lbName)}); err != nil {
+			return true, fmt.Errorf("failed to delete load balancer %s: %v", lbName, err)
+		}
+		log.Info("deleted load balancer", "name", lbName)
+	}
+	for _, arn := range arns {
+		if err := m.elbv2.deleteLoadBalancer(arn); err != nil {
+			return true, fmt.Errorf("failed to delete network load balancer %s: %v", arn, err)
+		}
+		log.Info("deleted network load balancer", "arn", arn)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, hostname := range hostnames {
+		delete(m.lbZones, hostname)
+	}
+	return true, nil
+}
+
+// clusterOwned returns the given load balancers that have the cluster's tag,
+// using the given function to get the tag keys of at most
+// maxDescribeTagsLoadBalancers load balancers at a time.
+func (m *Manager) clusterOwned(ids []string, describeTags func([]string) (map[string]sets.String, error)) ([]string, error) {
+	clusterTagKey := clusterTagKeyPrefix + m.config.ClusterID
+	var owned []string
+	for start := 0; start < len(ids); start += maxDescribeTagsLoadBalancers {
+		end := start + maxDescribeTagsLoadBalancers
+		if end > len(ids) {
+			end = len(ids)
+		}
+		keys, err := describeTags(ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids[start:end] {
+			if !keys[id].Has(clusterTagKey) {
+				log.Info("not deleting load balancer that does not have the cluster's tag", "load balancer", id, "tag", clusterTagKey)
+				continue
+			}
+			owned = append(owned, id)
+		}
+	}
+	return owned, nil
+}
+
+// describeClassicTags returns the tag keys of each of the classic ELBs with
+// the given names, of which there may be at most 20.
+func (m *Manager) describeClassicTags(names []string) (map[string]sets.String, error) {
+	output, err := m.elb.DescribeTags(&elb.DescribeTagsInput{LoadBalancerNames: aws.StringSlice(names)})
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]sets.String{}
+	for _, description := range output.TagDescriptions {
+		name := aws.StringValue(description.LoadBalancerName)
+		keys[name] = sets.NewString()
+		for _, tag := range description.Tags {
+			keys[name].Insert(aws.StringValue(tag.Key))
+		}
+	}
+	return keys, nil
+}
+
 type action string
 
 const (
@@ -231,12 +376,59 @@ func (m *Manager) Delete(record *dns.Record) error {
 	return m.change(record, deleteAction)
 }
 
-// change will perform an action on a record. The target must correspond to the
-// hostname of an ELB which will be automatically discovered.
+// PlanEnsure returns the input of the ChangeResourceRecordSets call that Ensure
+// would make for the given record, in JSON, without making the call.  The AWS
+// CLI accepts the result with "aws route53 change-resource-record-sets
+// --cli-input-json".
+func (m *Manager) PlanEnsure(record *dns.Record) (string, error) {
+	zoneID, err := m.getZoneID(record.Zone)
+	if err != nil {
+		return "", fmt.Errorf("failed to find hosted zone for record %v: %v", record, err)
+	}
+	var recordSet *route53.ResourceRecordSet
+	switch record.Type {
+	case dns.ALIASRecord:
+		alias := record.Alias
+		if alias == nil {
+			return "", fmt.Errorf("missing alias record")
+		}
+		targetHostedZoneID, err := m.getLBHostedZone(alias.Target)
+		if err != nil {
+			return "", fmt.Errorf("failed to get hosted zone for load balancer target %q: %v", alias.Target, err)
+		}
+		recordSet, err = aliasRecordSet(alias.Domain, alias.Target, targetHostedZoneID, record.RoutingPolicy)
+		if err != nil {
+			return "", err
+		}
+	case dns.ARecord:
+		a := record.A
+		if a == nil {
+			return "", fmt.Errorf("missing A record")
+		}
+		recordSet, err = aRecordSet(a.Domain, a.Targets, a.TTL, record.RoutingPolicy)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported record type %s", record.Type)
+	}
+	return changeInputJSON(changeInput(zoneID, recordSet, string(upsertAction)))
+}
+
+// change will perform an action on a record.
 func (m *Manager) change(record *dns.Record, action action) error {
-	if record.Type != dns.ALIASRecord {
-		return fmt.Errorf("unsupported record type %s", record.Type)
+	switch record.Type {
+	case dns.ALIASRecord:
+		return m.changeAlias(record, action)
+	case dns.ARecord:
+		return m.changeA(record, action)
 	}
+	return fmt.Errorf("unsupported record type %s", record.Type)
+}
+
+// changeAlias will perform an action on an alias record. The target must
+// correspond to the hostname of an ELB which will be automatically discovered.
+func (m *Manager) changeAlias(record *dns.Record, action action) error {
 	alias := record.Alias
 	if alias == nil {
 		return fmt.Errorf("missing alias record")
@@ -264,13 +456,13 @@ func (m *Manager) change(record *dns.Record, action action) error {
 	// TODO: handle the caching/diff detection in a better way.
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	key := zoneID + domain + target
+	key := zoneID + domain + target + routingPolicyKey(record.RoutingPolicy)
 	// Only process updates once for now because we're not diffing.
 	if m.updatedRecords.Has(key) && action == upsertAction {
 		log.Info("skipping DNS record update", "record", record)
 		return nil
 	}
-	err = m.updateAlias(domain, zoneID, target, targetHostedZoneID, string(action))
+	err = m.updateAlias(domain, zoneID, target, targetHostedZoneID, record.RoutingPolicy, string(action))
 	if err != nil {
 		return fmt.Errorf("failed to update alias in zone %s: %v", zoneID, err)
 	}
@@ -285,28 +477,79 @@ func (m *Manager) change(record *dns.Record, action action) error {
 	return nil
 }
 
+// changeA will perform an action on an A record, which is published as a single
+// record set with all of the record's addresses.
+func (m *Manager) changeA(record *dns.Record, action action) error {
+	a := record.A
+	if a == nil {
+		return fmt.Errorf("missing A record")
+	}
+	if len(a.Domain) == 0 {
+		return fmt.Errorf("domain is required")
+	}
+	if len(a.Targets) == 0 {
+		return fmt.Errorf("targets are required")
+	}
+
+	zoneID, err := m.getZoneID(record.Zone)
+	if err != nil {
+		return fmt.Errorf("failed to find hosted zone for record %v: %v", record, err)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := fmt.Sprintf("%s%s%s/%d%s", zoneID, a.Domain, strings.Join(a.Targets, ","), a.TTL, routingPolicyKey(record.RoutingPolicy))
+	// Only process updates once for now because we're not diffing.
+	if m.updatedRecords.Has(key) && action == upsertAction {
+		log.Info("skipping DNS record update", "record", record)
+		return nil
+	}
+	if err := m.updateA(a.Domain, zoneID, a.Targets, a.TTL, record.RoutingPolicy, string(action)); err != nil {
+		return fmt.Errorf("failed to update A record in zone %s: %v", zoneID, err)
+	}
+	switch action {
+	case upsertAction:
+		m.updatedRecords.Insert(key)
+		log.Info("upserted DNS record", "record", record)
+	case deleteAction:
+		m.updatedRecords.Delete(key)
+		log.Info("deleted DNS record", "record", record)
+	}
+	return nil
+}
+
+// updateA creates or updates an A record for domain in zoneID with the given
+// addresses and routing policy.  Route 53 returns all of the addresses in
+// answers, which clients use in turn.
+func (m *Manager) updateA(domain, zoneID string, targets []string, ttl int64, policy *ingressv1.DNSRoutingPolicy, action string) error {
+	recordSet, err := aRecordSet(domain, targets, ttl, policy)
+	if err != nil {
+		return err
+	}
+	resp, err := m.route53.ChangeResourceRecordSets(changeInput(zoneID, recordSet, action))
+	if err != nil {
+		if action == string(deleteAction) {
+			if aerr, ok := err.(awserr.Error); ok {
+				if strings.Contains(aerr.Message(), "not found") {
+					log.Info("record not found", "zone id", zoneID, "domain", domain, "targets", targets)
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("couldn't update DNS record in zone %s: %v", zoneID, err)
+	}
+	log.Info("updated DNS record", "zone id", zoneID, "domain", domain, "targets", targets, "response", resp)
+	return nil
+}
+
 // updateAlias creates or updates an alias for domain in zoneID pointed at
-// target in targetHostedZoneID.
-func (m *Manager) updateAlias(domain, zoneID, target, targetHostedZoneID, action string) error {
-	resp, err := m.route53.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
-		HostedZoneId: aws.String(zoneID),
-		ChangeBatch: &route53.ChangeBatch{
-			Changes: []*route53.Change{
-				{
-					Action: aws.String(action),
-					ResourceRecordSet: &route53.ResourceRecordSet{
-						Name: aws.String(domain),
-						Type: aws.String("A"),
-						AliasTarget: &route53.AliasTarget{
-							HostedZoneId:         aws.String(targetHostedZoneID),
-							DNSName:              aws.String(target),
-							EvaluateTargetHealth: aws.Bool(false),
-						},
-					},
-				},
-			},
-		},
-	})
+// target in targetHostedZoneID with the given routing policy.
+func (m *Manager) updateAlias(domain, zoneID, target, targetHostedZoneID string, policy *ingressv1.DNSRoutingPolicy, action string) error {
+	recordSet, err := aliasRecordSet(domain, target, targetHostedZoneID, policy)
+	if err != nil {
+		return err
+	}
+	resp, err := m.route53.ChangeResourceRecordSets(changeInput(zoneID, recordSet, action))
 	if err != nil {
 		if action == string(deleteAction) {
 			if aerr, ok := err.(awserr.Error); ok {
@@ -321,3 +564,166 @@ func (m *Manager) updateAlias(domain, zoneID, target, targetHostedZoneID, action
 	log.Info("updated DNS record", "zone id", zoneID, "domain", domain, "target", target, "response", resp)
 	return nil
 }
+
+// aRecordSet returns the record set of an A record for domain with the given
+// addresses, TTL, and routing policy.
+func aRecordSet(domain string, targets []string, ttl int64, policy *ingressv1.DNSRoutingPolicy) (*route53.ResourceRecordSet, error) {
+	records := make([]*route53.ResourceRecord, 0, len(targets))
+	for _, target := range targets {
+		records = append(records, &route53.ResourceRecord{Value: aws.String(target)})
+	}
+	recordSet := &route53.ResourceRecordSet{
+		Name:            aws.String(domain),
+		Type:            aws.String("A"),
+		TTL:             aws.Int64(ttl),
+		ResourceRecords: records,
+	}
+	if err := applyRoutingPolicy(recordSet, policy); err != nil {
+		return nil, err
+	}
+	return recordSet, nil
+}
+
+// aliasRecordSet returns the record set of an alias for domain pointed at
+// target in targetHostedZoneID with the given routing policy.
+func aliasRecordSet(domain, target, targetHostedZoneID string, policy *ingressv1.DNSRoutingPolicy) (*route53.ResourceRecordSet, error) {
+	recordSet := &route53.ResourceRecordSet{
+		Name: aws.String(domain),
+		Type: aws.String("A"),
+		AliasTarget: &route53.AliasTarget{
+			HostedZoneId:         aws.String(targetHostedZoneID),
+			DNSName:              aws.String(target),
+			EvaluateTargetHealth: aws.Bool(false),
+		},
+	}
+	if err := applyRoutingPolicy(recordSet, policy); err != nil {
+		return nil, err
+	}
+	return recordSet, nil
+}
+
+// changeInput returns the input of a ChangeResourceRecordSets call that
+// performs the given action on recordSet in zoneID.
+func changeInput(zoneID string, recordSet *route53.ResourceRecordSet, action string) *route53.ChangeResourceRecordSetsInput {
+	return &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{
+				{
+					Action:            aws.String(action),
+					ResourceRecordSet: recordSet,
+				},
+			},
+		},
+	}
+}
+
+// changeInputJSON returns the given ChangeResourceRecordSets input in the JSON
+// format that the AWS CLI accepts.  The SDK types have no JSON tags, so their
+// field names match the API's, but unset fields must be omitted rather than
+// marshaled as null.
+func changeInputJSON(input *route53.ChangeResourceRecordSetsInput) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+	data, err = json.Marshal(withoutNulls(value))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// withoutNulls returns the given unmarshaled JSON value with every null object
+// member removed.
+func withoutNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, member := range v {
+			if member == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = withoutNulls(member)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = withoutNulls(v[i])
+		}
+	}
+	return value
+}
+
+// routingPolicyKey returns the part of an updatedRecords key that identifies
+// the given routing policy, which is empty if the policy is nil.
+func routingPolicyKey(policy *ingressv1.DNSRoutingPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return ""
+	}
+	return "/" + string(data)
+}
+
+// applyRoutingPolicy sets the fields of the given record set that publish it
+// with the given routing policy, if any.  An alias record set with a failover
+// policy evaluates the health of its load balancer target so that Route 53
+// fails over when the load balancer has no healthy targets.
+func applyRoutingPolicy(recordSet *route53.ResourceRecordSet, policy *ingressv1.DNSRoutingPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if len(policy.SetIdentifier) == 0 {
+		return fmt.Errorf("routing policy must have a set identifier")
+	}
+	recordSet.SetIdentifier = aws.String(policy.SetIdentifier)
+	switch policy.Type {
+	case ingressv1.WeightedRoutingPolicyType:
+		if policy.Weighted == nil {
+			return fmt.Errorf("weighted routing policy must have a weight")
+		}
+		recordSet.Weight = aws.Int64(policy.Weighted.Weight)
+	case ingressv1.FailoverRoutingPolicyType:
+		if policy.Failover == nil {
+			return fmt.Errorf("failover routing policy must have a role")
+		}
+		switch policy.Failover.Role {
+		case ingressv1.PrimaryFailoverRole:
+			recordSet.Failover = aws.String(route53.ResourceRecordSetFailoverPrimary)
+		case ingressv1.SecondaryFailoverRole:
+			recordSet.Failover = aws.String(route53.ResourceRecordSetFailoverSecondary)
+		default:
+			return fmt.Errorf("unsupported failover role %q", policy.Failover.Role)
+		}
+		if len(policy.Failover.HealthCheckID) != 0 {
+			recordSet.HealthCheckId = aws.String(policy.Failover.HealthCheckID)
+		}
+		if recordSet.AliasTarget != nil {
+			recordSet.AliasTarget.EvaluateTargetHealth = aws.Bool(true)
+		}
+	case ingressv1.GeolocationRoutingPolicyType:
+		if policy.Geolocation == nil {
+			return fmt.Errorf("geolocation routing policy must have a location")
+		}
+		location := &route53.GeoLocation{}
+		if len(policy.Geolocation.ContinentCode) != 0 {
+			location.ContinentCode = aws.String(policy.Geolocation.ContinentCode)
+		}
+		if len(policy.Geolocation.CountryCode) != 0 {
+			location.CountryCode = aws.String(policy.Geolocation.CountryCode)
+		}
+		if len(policy.Geolocation.SubdivisionCode) != 0 {
+			location.SubdivisionCode = aws.String(policy.Geolocation.SubdivisionCode)
+		}
+		recordSet.GeoLocation = location
+	default:
+		return fmt.Errorf("unsupported routing policy type %q", policy.Type)
+	}
+	return nil
+}
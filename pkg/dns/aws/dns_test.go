@@ -0,0 +1,245 @@
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestApplyRoutingPolicy(t *testing.T) {
+	aliasRecordSet := func() *route53.ResourceRecordSet {
+		return &route53.ResourceRecordSet{
+			AliasTarget: &route53.AliasTarget{EvaluateTargetHealth: aws.Bool(false)},
+		}
+	}
+	testCases := []struct {
+		description string
+		policy      *ingressv1.DNSRoutingPolicy
+		check       func(*route53.ResourceRecordSet) bool
+		expectErr   bool
+	}{
+		{
+			description: "no policy",
+			check: func(rrs *route53.ResourceRecordSet) bool {
+				return rrs.SetIdentifier == nil && rrs.Weight == nil && rrs.Failover == nil && rrs.GeoLocation == nil
+			},
+		},
+		{
+			description: "weighted",
+			policy: &ingressv1.DNSRoutingPolicy{
+				Type:          ingressv1.WeightedRoutingPolicyType,
+				SetIdentifier: "east",
+				Weighted:      &ingressv1.WeightedDNSRoutingPolicy{Weight: 0},
+			},
+			check: func(rrs *route53.ResourceRecordSet) bool {
+				return aws.StringValue(rrs.SetIdentifier) == "east" && rrs.Weight != nil && *rrs.Weight == 0 && !aws.BoolValue(rrs.AliasTarget.EvaluateTargetHealth)
+			},
+		},
+		{
+			description: "failover evaluates target health",
+			policy: &ingressv1.DNSRoutingPolicy{
+				Type:          ingressv1.FailoverRoutingPolicyType,
+				SetIdentifier: "west",
+				Failover:      &ingressv1.FailoverDNSRoutingPolicy{Role: ingressv1.SecondaryFailoverRole, HealthCheckID: "abc"},
+			},
+			check: func(rrs *route53.ResourceRecordSet) bool {
+				return aws.StringValue(rrs.Failover) == route53.ResourceRecordSetFailoverSecondary && aws.StringValue(rrs.HealthCheckId) == "abc" && aws.BoolValue(rrs.AliasTarget.EvaluateTargetHealth)
+			},
+		},
+		{
+			description: "geolocation",
+			policy: &ingressv1.DNSRoutingPolicy{
+				Type:          ingressv1.GeolocationRoutingPolicyType,
+				SetIdentifier: "eu",
+				Geolocation:   &ingressv1.GeolocationDNSRoutingPolicy{ContinentCode: "EU"},
+			},
+			check: func(rrs *route53.ResourceRecordSet) bool {
+				return rrs.GeoLocation != nil && aws.StringValue(rrs.GeoLocation.ContinentCode) == "EU" && rrs.GeoLocation.CountryCode == nil
+			},
+		},
+		{
+			description: "missing weight",
+			policy: &ingressv1.DNSRoutingPolicy{
+				Type:          ingressv1.WeightedRoutingPolicyType,
+				SetIdentifier: "east",
+			},
+			expectErr: true,
+		},
+		{
+			description: "unsupported type",
+			policy: &ingressv1.DNSRoutingPolicy{
+				Type:          "Latency",
+				SetIdentifier: "east",
+			},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		rrs := aliasRecordSet()
+		err := applyRoutingPolicy(rrs, tc.policy)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+			continue
+		}
+		if !tc.expectErr && !tc.check(rrs) {
+			t.Errorf("%q: unexpected record set %v", tc.description, rrs)
+		}
+	}
+}
+
+// TestChangeInputJSON verifies that a planned change omits unset fields and
+// uses the field names of the Route 53 API.
+func TestChangeInputJSON(t *testing.T) {
+	recordSet, err := aRecordSet("*.apps.example.com.", []string{"192.0.2.1"}, 30, &ingressv1.DNSRoutingPolicy{
+		Type:          ingressv1.WeightedRoutingPolicyType,
+		SetIdentifier: "east",
+		Weighted:      &ingressv1.WeightedDNSRoutingPolicy{Weight: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := changeInputJSON(changeInput("Z123", recordSet, string(upsertAction)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"ChangeBatch":{"Changes":[{"Action":"UPSERT","ResourceRecordSet":{"Name":"*.apps.example.com.","ResourceRecords":[{"Value":"192.0.2.1"}],"SetIdentifier":"east","TTL":30,"Type":"A","Weight":10}}]},"HostedZoneId":"Z123"}`
+	if data != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+// fakeELB serves the classic ELB and ELBv2 DescribeLoadBalancers,
+// DescribeTags, and DeleteLoadBalancer actions for the given load balancers
+// and records the load balancers that are deleted.  The load balancers named
+// in owned have the tag of the cluster "test".
+type fakeELB struct {
+	lock    sync.Mutex
+	classic map[string]string
+	v2      map[string]string
+	owned   sets.String
+	deleted []string
+}
+
+// writeTagDescriptions writes the tag descriptions of the load balancers in
+// the given form field, identified in the response by the given element.
+func (f *fakeELB) writeTagDescriptions(w http.ResponseWriter, r *http.Request, field, element string) {
+	fmt.Fprint(w, "<TagDescriptions>")
+	for i := 1; len(r.Form.Get(fmt.Sprintf("%s.member.%d", field, i))) != 0; i++ {
+		id := r.Form.Get(fmt.Sprintf("%s.member.%d", field, i))
+		fmt.Fprintf(w, "<member><%s>%s</%s><Tags><member><Key>example.com/other</Key><Value>x</Value></member>", element, id, element)
+		if f.owned.Has(strings.TrimPrefix(id, "arn:")) {
+			fmt.Fprint(w, "<member><Key>kubernetes.io/cluster/test</Key><Value>owned</Value></member>")
+		}
+		fmt.Fprint(w, "</Tags></member>")
+	}
+	fmt.Fprint(w, "</TagDescriptions>")
+}
+
+func (f *fakeELB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	action, version := r.Form.Get("Action"), r.Form.Get("Version")
+	fmt.Fprintf(w, "<%sResponse><%sResult>", action, action)
+	switch {
+	case action == "DescribeLoadBalancers" && version == elbv2APIVersion:
+		fmt.Fprint(w, "<LoadBalancers>")
+		for name, dnsName := range f.v2 {
+			fmt.Fprintf(w, "<member><LoadBalancerArn>arn:%s</LoadBalancerArn><LoadBalancerName>%s</LoadBalancerName><DNSName>%s</DNSName></member>", name, name, dnsName)
+		}
+		fmt.Fprint(w, "</LoadBalancers>")
+	case action == "DescribeLoadBalancers":
+		fmt.Fprint(w, "<LoadBalancerDescriptions>")
+		for name, dnsName := range f.classic {
+			fmt.Fprintf(w, "<member><LoadBalancerName>%s</LoadBalancerName><DNSName>%s</DNSName></member>", name, dnsName)
+		}
+		fmt.Fprint(w, "</LoadBalancerDescriptions>")
+	case action == "DescribeTags" && version == elbv2APIVersion:
+		f.writeTagDescriptions(w, r, "ResourceArns", "ResourceArn")
+	case action == "DescribeTags":
+		f.writeTagDescriptions(w, r, "LoadBalancerNames", "LoadBalancerName")
+	case action == "DeleteLoadBalancer" && version == elbv2APIVersion:
+		f.deleted = append(f.deleted, r.Form.Get("LoadBalancerArn"))
+	case action == "DeleteLoadBalancer":
+		f.deleted = append(f.deleted, r.Form.Get("LoadBalancerName"))
+	}
+	fmt.Fprintf(w, "</%sResult></%sResponse>", action, action)
+}
+
+func TestDeleteLoadBalancer(t *testing.T) {
+	testCases := []struct {
+		description   string
+		name          string
+		hostnames     []string
+		expectDeleted []string
+	}{
+		{
+			description:   "classic load balancer by DNS name",
+			hostnames:     []string{"CLASSIC.elb.amazonaws.com"},
+			expectDeleted: []string{"aclassic"},
+		},
+		{
+			description:   "network load balancer by DNS name",
+			hostnames:     []string{"nlb.elb.amazonaws.com"},
+			expectDeleted: []string{"arn:anlb"},
+		},
+		{
+			description:   "network load balancer by name",
+			name:          "anlb",
+			expectDeleted: []string{"arn:anlb"},
+		},
+		{
+			description: "no match",
+			name:        "aother",
+			hostnames:   []string{"other.elb.amazonaws.com"},
+		},
+		{
+			description: "load balancer of another cluster",
+			hostnames:   []string{"foreign.elb.amazonaws.com"},
+		},
+	}
+	for _, tc := range testCases {
+		fake := &fakeELB{
+			classic: map[string]string{"aclassic": "classic.elb.amazonaws.com", "aforeign": "foreign.elb.amazonaws.com"},
+			v2:      map[string]string{"anlb": "nlb.elb.amazonaws.com"},
+			owned:   sets.NewString("aclassic", "anlb"),
+		}
+		server := httptest.NewServer(fake)
+		sess := session.Must(session.NewSession(aws.NewConfig().
+			WithCredentials(credentials.NewStaticCredentials("id", "key", "")).
+			WithRegion("us-east-1").
+			WithEndpoint(server.URL)))
+		m := &Manager{elb: elb.New(sess), elbv2: newELBv2Client(sess), lbZones: map[string]string{}, config: Config{ClusterID: "test"}}
+		found, err := m.DeleteLoadBalancer(tc.name, tc.hostnames)
+		server.Close()
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if expectFound := len(tc.expectDeleted) != 0; found != expectFound {
+			t.Errorf("%q: expected found %t, got %t", tc.description, expectFound, found)
+		}
+		sort.Strings(fake.deleted)
+		if !reflect.DeepEqual(fake.deleted, tc.expectDeleted) {
+			t.Errorf("%q: expected deleted %v, got %v", tc.description, tc.expectDeleted, fake.deleted)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol/query"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// elbv2Client is a minimal client for the Elastic Load Balancing v2 API, which
+// manages network load balancers.  The vendored AWS SDK does not include the
+// elbv2 package.  The v2 API uses the same endpoint, signing name, and query
+// protocol as the classic ELB API, so the client is built the same way as the
+// elb package's, with only the operations that the operator needs.
+type elbv2Client struct {
+	*client.Client
+}
+
+// elbv2APIVersion is the version of the Elastic Load Balancing v2 API.
+const elbv2APIVersion = "2015-12-01"
+
+// newELBv2Client returns an elbv2Client for the given session and configs.
+func newELBv2Client(p client.ConfigProvider, cfgs ...*aws.Config) *elbv2Client {
+	c := p.ClientConfig("elasticloadbalancing", cfgs...)
+	svc := &elbv2Client{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:   "elasticloadbalancing",
+				ServiceID:     "Elastic Load Balancing v2",
+				SigningName:   c.SigningName,
+				SigningRegion: c.SigningRegion,
+				Endpoint:      c.Endpoint,
+				APIVersion:    elbv2APIVersion,
+			},
+			c.Handlers,
+		),
+	}
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	svc.Handlers.Build.PushBackNamed(query.BuildHandler)
+	svc.Handlers.Unmarshal.PushBackNamed(query.UnmarshalHandler)
+	svc.Handlers.UnmarshalMeta.PushBackNamed(query.UnmarshalMetaHandler)
+	svc.Handlers.UnmarshalError.PushBackNamed(query.UnmarshalErrorHandler)
+	return svc
+}
+
+// elbv2LoadBalancer describes a v2 load balancer.
+type elbv2LoadBalancer struct {
+	_ struct{} `type:"structure"`
+
+	LoadBalancerArn  *string `type:"string"`
+	LoadBalancerName *string `type:"string"`
+	DNSName          *string `type:"string"`
+	Type             *string `type:"string"`
+}
+
+type elbv2DescribeLoadBalancersInput struct {
+	_ struct{} `type:"structure"`
+
+	Marker *string `type:"string"`
+}
+
+type elbv2DescribeLoadBalancersOutput struct {
+	_ struct{} `type:"structure"`
+
+	LoadBalancers []*elbv2LoadBalancer `type:"list"`
+	NextMarker    *string              `type:"string"`
+}
+
+type elbv2DeleteLoadBalancerInput struct {
+	_ struct{} `type:"structure"`
+
+	LoadBalancerArn *string `type:"string" required:"true"`
+}
+
+type elbv2DeleteLoadBalancerOutput struct {
+	_ struct{} `type:"structure"`
+}
+
+type elbv2DescribeTagsInput struct {
+	_ struct{} `type:"structure"`
+
+	ResourceArns []*string `type:"list" required:"true"`
+}
+
+type elbv2Tag struct {
+	_ struct{} `type:"structure"`
+
+	Key   *string `type:"string"`
+	Value *string `type:"string"`
+}
+
+type elbv2TagDescription struct {
+	_ struct{} `type:"structure"`
+
+	ResourceArn *string     `type:"string"`
+	Tags        []*elbv2Tag `type:"list"`
+}
+
+type elbv2DescribeTagsOutput struct {
+	_ struct{} `type:"structure"`
+
+	TagDescriptions []*elbv2TagDescription `type:"list"`
+}
+
+// describeLoadBalancersPages calls fn with each page of v2 load balancers until
+// fn returns false or there are no more pages.
+func (c *elbv2Client) describeLoadBalancersPages(fn func(*elbv2DescribeLoadBalancersOutput) bool) error {
+	input := &elbv2DescribeLoadBalancersInput{}
+	for {
+		output := &elbv2DescribeLoadBalancersOutput{}
+		op := &request.Operation{Name: "DescribeLoadBalancers", HTTPMethod: "POST", HTTPPath: "/"}
+		if err := c.NewRequest(op, input, output).Send(); err != nil {
+			return err
+		}
+		if !fn(output) || len(aws.StringValue(output.NextMarker)) == 0 {
+			return nil
+		}
+		input = &elbv2DescribeLoadBalancersInput{Marker: output.NextMarker}
+	}
+}
+
+// describeTags returns the tag keys of each of the v2 load balancers with the
+// given ARNs, of which there may be at most 20.
+func (c *elbv2Client) describeTags(arns []string) (map[string]sets.String, error) {
+	output := &elbv2DescribeTagsOutput{}
+	op := &request.Operation{Name: "DescribeTags", HTTPMethod: "POST", HTTPPath: "/"}
+	if err := c.NewRequest(op, &elbv2DescribeTagsInput{ResourceArns: aws.StringSlice(arns)}, output).Send(); err != nil {
+		return nil, err
+	}
+	keys := map[string]sets.String{}
+	for _, description := range output.TagDescriptions {
+		arn := aws.StringValue(description.ResourceArn)
+		keys[arn] = sets.NewString()
+		for _, tag := range description.Tags {
+			keys[arn].Insert(aws.StringValue(tag.Key))
+		}
+	}
+	return keys, nil
+}
+
+// deleteLoadBalancer deletes the v2 load balancer with the given ARN.
+func (c *elbv2Client) deleteLoadBalancer(arn string) error {
+	op := &request.Operation{Name: "DeleteLoadBalancer", HTTPMethod: "POST", HTTPPath: "/"}
+	return c.NewRequest(op, &elbv2DeleteLoadBalancerInput{LoadBalancerArn: aws.String(arn)}, &elbv2DeleteLoadBalancerOutput{}).Send()
+}
@@ -2,8 +2,10 @@ package dns
 
 import (
 	"fmt"
+	"strings"
 
 	configv1 "github.com/openshift/api/config/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
 )
 
 // Manager knows how to manage DNS zones only as pertains to routing.
@@ -15,6 +17,25 @@ type Manager interface {
 	Delete(record *Record) error
 }
 
+// Planner is implemented by managers that can describe the change that Ensure
+// would make without making it.
+type Planner interface {
+	// PlanEnsure returns the change that Ensure would make for record, in
+	// the format of the provider's API.
+	PlanEnsure(record *Record) (string, error)
+}
+
+// LoadBalancerDeleter is implemented by managers that can delete a cloud load
+// balancer directly, for when the cloud provider's service controller is not
+// running to delete it.
+type LoadBalancerDeleter interface {
+	// DeleteLoadBalancer deletes each load balancer that has the given
+	// name or one of the given DNS names and returns true if it found
+	// any.  A load balancer is deleted only once this returns false
+	// without an error.
+	DeleteLoadBalancer(name string, hostnames []string) (bool, error)
+}
+
 var _ Manager = &NoopManager{}
 
 type NoopManager struct{}
@@ -31,6 +52,29 @@ type Record struct {
 
 	// Alias is options for an ALIAS record.
 	Alias *AliasRecord
+
+	// A is options for an A record.
+	A *AddressRecord
+
+	// RoutingPolicy, if set, publishes the record as one of several
+	// records with the same name and type.
+	RoutingPolicy *ingressv1.DNSRoutingPolicy
+}
+
+func (r *Record) String() string {
+	var s string
+	switch {
+	case r.Alias != nil:
+		s = r.Alias.String()
+	case r.A != nil:
+		s = r.A.String()
+	default:
+		s = string(r.Type)
+	}
+	if r.RoutingPolicy != nil {
+		s = fmt.Sprintf("%s (%s %s)", s, r.RoutingPolicy.Type, r.RoutingPolicy.SetIdentifier)
+	}
+	return s
 }
 
 // RecordType is a DNS record type.
@@ -39,6 +83,9 @@ type RecordType string
 const (
 	// ALIASRecord is a DNS ALIAS record.
 	ALIASRecord RecordType = "ALIAS"
+
+	// ARecord is a DNS A record.
+	ARecord RecordType = "A"
 )
 
 // AliasRecord is a DNS ALIAS record.
@@ -53,3 +100,19 @@ type AliasRecord struct {
 func (r *AliasRecord) String() string {
 	return fmt.Sprintf("%s -> %s", r.Domain, r.Target)
 }
+
+// AddressRecord is a DNS A record, which may have multiple addresses.
+type AddressRecord struct {
+	// Domain is the record name.
+	Domain string
+
+	// Targets are the IPv4 addresses of Domain.
+	Targets []string
+
+	// TTL is the record TTL in seconds.
+	TTL int64
+}
+
+func (r *AddressRecord) String() string {
+	return fmt.Sprintf("%s -> %s", r.Domain, strings.Join(r.Targets, ", "))
+}
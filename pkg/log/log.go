@@ -2,19 +2,44 @@ package log
 
 import (
 	"fmt"
+	"net/http"
+	"os"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	// LogFormatEnvVar is the environment variable that selects the log
+	// output format.  Valid values are "console" (the default) and "json".
+	LogFormatEnvVar = "LOG_FORMAT"
+
+	// LogLevelEnvVar is the environment variable that sets the initial log
+	// level.  Valid values are "Normal" (the default), "Debug", "Trace", and
+	// "TraceAll".
+	LogLevelEnvVar = "LOG_LEVEL"
 )
 
 // Logger is a simple logging interface for Go.
 var Logger logr.Logger
 
+// level is the level of Logger.  It can be changed at runtime using SetLevel.
+var level = zap.NewAtomicLevelAt(zap.InfoLevel)
+
 func init() {
-	// Build a zap development logger.
-	zapLogger, err := zap.NewDevelopment(zap.AddCallerSkip(1), zap.AddStacktrace(zap.FatalLevel))
+	// Build a zap development logger, using JSON output if requested.
+	config := zap.NewDevelopmentConfig()
+	config.Level = level
+	if os.Getenv(LogFormatEnvVar) == "json" {
+		config.Encoding = "json"
+		config.EncoderConfig = zap.NewProductionEncoderConfig()
+	}
+	zapLogger, err := config.Build(zap.AddCallerSkip(1), zap.AddStacktrace(zap.FatalLevel))
 	if err != nil {
 		panic(fmt.Sprintf("error building logger: %v", err))
 	}
@@ -23,7 +48,59 @@ func init() {
 	// zapr defines an implementation of the Logger
 	// interface built on top of Zap (go.uber.org/zap).
 	Logger = zapr.NewLogger(zapLogger).WithName("operator")
-	Logger.Info("started zapr logger")
+
+	if value := os.Getenv(LogLevelEnvVar); len(value) != 0 {
+		if err := SetLevel(operatorv1.LogLevel(value)); err != nil {
+			Logger.Error(err, "ignoring invalid log level", "env", LogLevelEnvVar)
+		}
+	}
+	Logger.Info("started zapr logger", "level", level.String())
+}
+
+// zapLevelFor maps an operator log level to a zap level.  Verbosity in logr
+// corresponds to the inverse of zap's level, so Logger.V(n) is enabled when the
+// zap level is at most -n.
+func zapLevelFor(l operatorv1.LogLevel) (zapcore.Level, error) {
+	switch l {
+	case operatorv1.Normal, "":
+		return zapcore.InfoLevel, nil
+	case operatorv1.Debug:
+		return zapcore.Level(-2), nil
+	case operatorv1.Trace:
+		return zapcore.Level(-4), nil
+	case operatorv1.TraceAll:
+		return zapcore.Level(-8), nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level %q", l)
+	}
+}
+
+// SetLevel changes the level of Logger and all loggers derived from it.
+func SetLevel(l operatorv1.LogLevel) error {
+	zapLevel, err := zapLevelFor(l)
+	if err != nil {
+		return err
+	}
+	if level.Level() != zapLevel {
+		level.SetLevel(zapLevel)
+		Logger.Info("changed log level", "level", string(l))
+	}
+	return nil
+}
+
+// LevelHandler returns an HTTP handler that reports the current log level on
+// GET using zap's JSON format, for example {"level":"debug"}.  The handler is
+// read-only because it is served without authentication; the level is changed
+// through the operator's configuration.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "the log level is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		level.ServeHTTP(w, r)
+	})
 }
 
 // SetRuntimeLogger sets a concrete logging implementation for all
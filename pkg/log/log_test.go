@@ -0,0 +1,62 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestSetLevel(t *testing.T) {
+	defer SetLevel(operatorv1.Normal)
+
+	testCases := []struct {
+		level      operatorv1.LogLevel
+		expectErr  bool
+		expectV    int
+		expectNotV int
+	}{
+		{level: operatorv1.Normal, expectV: 0, expectNotV: 1},
+		{level: operatorv1.Debug, expectV: 2, expectNotV: 3},
+		{level: operatorv1.Trace, expectV: 4, expectNotV: 5},
+		{level: operatorv1.TraceAll, expectV: 8, expectNotV: 9},
+		{level: "Verbose", expectErr: true, expectV: 8, expectNotV: 9},
+	}
+
+	for _, tc := range testCases {
+		err := SetLevel(tc.level)
+		if tc.expectErr != (err != nil) {
+			t.Errorf("%q: expected error %t, got %v", tc.level, tc.expectErr, err)
+		}
+		if !Logger.V(tc.expectV).Enabled() {
+			t.Errorf("%q: expected V(%d) to be enabled", tc.level, tc.expectV)
+		}
+		if Logger.V(tc.expectNotV).Enabled() {
+			t.Errorf("%q: expected V(%d) to be disabled", tc.level, tc.expectNotV)
+		}
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer SetLevel(operatorv1.Normal)
+
+	if err := SetLevel(operatorv1.Normal); err != nil {
+		t.Fatal(err)
+	}
+	get := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/loglevel", nil))
+	if get.Code != http.StatusOK || !strings.Contains(get.Body.String(), `"level":"info"`) {
+		t.Errorf("expected GET to report the info level, got %d %q", get.Code, get.Body.String())
+	}
+
+	put := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(put, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`)))
+	if put.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected PUT to be rejected, got %d", put.Code)
+	}
+	if Logger.V(1).Enabled() {
+		t.Errorf("expected PUT not to change the log level")
+	}
+}
@@ -1,7 +1,12 @@
 // Code generated by go-bindata. DO NOT EDIT.
 // sources:
+// assets/canary/daemonset.yaml (1.195kB)
+// assets/canary/namespace.yaml (124B)
+// assets/canary/route.yaml (280B)
+// assets/canary/service.yaml (297B)
+// assets/crds/dnsrecord.yaml (9.12kB)
 // assets/router/cluster-role-binding.yaml (329B)
-// assets/router/cluster-role.yaml (788B)
+// assets/router/cluster-role.yaml (856B)
 // assets/router/deployment.yaml (1.723kB)
 // assets/router/metrics/cluster-role-binding.yaml (285B)
 // assets/router/metrics/cluster-role.yaml (259B)
@@ -10,7 +15,8 @@
 // assets/router/namespace.yaml (332B)
 // assets/router/service-account.yaml (213B)
 // assets/router/service-cloud.yaml (631B)
-// assets/router/service-internal.yaml (429B)
+// assets/router/service-internal.yaml (507B)
+// assets/router/service-nodeport.yaml (816B)
 
 package manifests
 
@@ -79,6 +85,106 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
+var _assetsCanaryDaemonsetYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x53\xc1\x6e\xdb\x30\x0c\xbd\xfb\x2b\x08\x14\xbb\x2d\x69\xba\x53\xa1\xeb\x36\xec\xb2\x0d\xc5\xd2\xee\x3a\x30\xf2\x4b\x2c\x44\x16\x35\x8a\xee\x9a\xbf\x1f\xe4\xc4\xae\xd3\xcb\x4e\x83\x0c\x24\x78\x8f\x7a\x7c\x8f\xa0\x6e\xe8\xb1\x03\x79\x4e\xac\x27\xe2\x9c\x63\xf0\x6c\x41\xd2\x7b\xfa\xd3\x05\xdf\x51\x81\x3e\xa3\x90\xbd\x16\xa9\x0c\x06\x2a\x42\xd6\xb1\x8d\x84\x64\x28\x9b\x68\x73\x53\x6b\xc8\x77\xf0\xc7\x91\x08\xe9\xa0\x28\x85\x5a\x36\xa6\xcc\xd6\x11\x52\x4b\x26\xf5\x67\x4d\x63\xe7\xd0\xf3\x01\x14\x0a\x15\x18\xb1\x91\x0e\xc9\x42\x8f\x75\x73\x0c\xa9\x75\xf4\x89\xd1\x4b\xda\xc2\x1a\xce\xe1\x27\xb4\x04\x49\xae\xfa\x2c\xb7\xcf\x77\x4d\x0f\xe3\xaa\xed\x1a\xa2\xc4\x3d\xdc\xd4\x71\x75\x0e\x74\x81\x4b\x66\x0f\x47\x92\x91\x4a\x17\xf6\xb6\x7a\x53\x55\x32\x7c\x95\x28\x88\xf0\x26\x5a\xff\x13\xf5\x6c\xbe\xfb\xca\x3b\xc4\x72\x06\x68\x52\x3f\x5f\x5b\x4f\xb1\xd7\xb3\xf0\x3a\xc8\x6d\x3b\x3a\x2e\x98\xbb\x9c\xab\xdd\x65\x7c\xbf\xbc\x24\x53\x89\x11\xda\x10\x0d\xb9\x65\xc3\xd6\x94\x0d\x87\xd3\xb9\x8d\x9d\x32\x1c\xfd\x90\x18\x43\x3a\x3c\x8d\x05\x23\xae\x4b\x64\x72\xd4\xf3\xcb\x53\xe2\x67\x0e\x91\x77\x11\x8e\xee\x36\xef\x1a\x22\x43\x9f\xe3\x5c\xb5\x9c\x52\x3d\xf1\x2a\xd3\x7f\x48\x45\x34\x4d\xb4\x9e\x24\x2d\xb6\x57\x93\xad\xdf\x71\xd8\x41\x13\x0c\xa5\xca\x4b\x71\x14\x43\x1a\x5e\x2e\xbc\x49\xac\x2e\x82\xa4\xd9\xe7\x8a\x8e\x38\xb9\x51\x6d\xa5\x12\xb1\xbe\x56\x08\x69\xaf\x7c\x29\x25\x9a\x42\x38\xfa\xfc\x12\x8a\x95\x99\xc0\x7e\x0f\x6f\x8e\xbe\xcb\xd6\x77\x68\x87\x88\x0b\x55\x03\x70\x48\xd0\x45\xc3\xf3\x4a\x8d\x2f\x60\xd5\x81\xa3\x75\xe3\x6a\xbf\x2e\xd7\x74\xb5\xef\x39\xb5\xd3\x3d\xa2\xd5\x34\xd2\xd5\xe4\x63\x41\xfd\x53\x2e\x8b\xda\x6c\xe2\xd5\x46\x67\x96\x67\x70\xe1\xf7\x41\xd4\x1c\xdd\x6f\xee\x37\x0b\x36\xab\x98\x78\x89\x8e\x1e\x3f\x3e\xcc\xb8\x82\xdb\x90\x50\xca\x83\xca\xee\xb2\x1c\xe7\xaf\x8a\x7f\x81\x2d\x21\x1a\x1f\xac\xa3\xdb\x6b\x6c\xec\xf6\xc6\x4b\x86\x06\x69\xb7\xf0\x92\xda\x52\x77\x70\xe6\x14\x45\x06\xf5\x58\xe4\x21\x52\xfc\x1e\x50\x96\x19\xeb\xf1\x79\xa8\x57\xfb\x2b\xb0\x47\x2f\x7a\x72\xf4\x61\xf3\x2d\x34\x7f\x07\x00\x41\x47\xd4\xf0\xab\x04\x00\x00")
+
+func assetsCanaryDaemonsetYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsCanaryDaemonsetYaml,
+		"assets/canary/daemonset.yaml",
+	)
+}
+
+func assetsCanaryDaemonsetYaml() (*asset, error) {
+	bytes, err := assetsCanaryDaemonsetYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/canary/daemonset.yaml", size: 1195, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x3e, 0xe1, 0xab, 0x7b, 0xea, 0xf7, 0x83, 0x9e, 0xef, 0xa6, 0x78, 0x1c, 0x6e, 0x15, 0x45, 0xa8, 0x66, 0xd, 0xdb, 0x2, 0xfe, 0x14, 0xb5, 0x76, 0x46, 0x53, 0x53, 0x8c, 0x97, 0x5c, 0x50, 0xb}}
+	return a, nil
+}
+
+var _assetsCanaryNamespaceYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x7c\x00\x83\xff\x6b\x69\x6e\x64\x3a\x20\x4e\x61\x6d\x65\x73\x70\x61\x63\x65\x0a\x61\x70\x69\x56\x65\x72\x73\x69\x6f\x6e\x3a\x20\x76\x31\x0a\x6d\x65\x74\x61\x64\x61\x74\x61\x3a\x0a\x20\x20\x6e\x61\x6d\x65\x3a\x20\x6f\x70\x65\x6e\x73\x68\x69\x66\x74\x2d\x69\x6e\x67\x72\x65\x73\x73\x2d\x63\x61\x6e\x61\x72\x79\x0a\x20\x20\x61\x6e\x6e\x6f\x74\x61\x74\x69\x6f\x6e\x73\x3a\x0a\x20\x20\x20\x20\x6f\x70\x65\x6e\x73\x68\x69\x66\x74\x2e\x69\x6f\x2f\x6e\x6f\x64\x65\x2d\x73\x65\x6c\x65\x63\x74\x6f\x72\x3a\x20\x22\x22\x0a\x03\x00\xee\x6f\x7a\xeb\x7c\x00\x00\x00")
+
+func assetsCanaryNamespaceYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsCanaryNamespaceYaml,
+		"assets/canary/namespace.yaml",
+	)
+}
+
+func assetsCanaryNamespaceYaml() (*asset, error) {
+	bytes, err := assetsCanaryNamespaceYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/canary/namespace.yaml", size: 124, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfa, 0xbe, 0xf, 0xe9, 0xe4, 0xde, 0x20, 0xfc, 0xbd, 0x8, 0xcf, 0x4f, 0x86, 0xdf, 0x8b, 0x6d, 0x40, 0x53, 0xe1, 0xe7, 0xc, 0x94, 0x75, 0xa4, 0x2b, 0x7f, 0x8c, 0xc7, 0xaf, 0xbd, 0xd9, 0x93}}
+	return a, nil
+}
+
+var _assetsCanaryRouteYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x5c\x8e\xb1\x6a\x04\x31\x0c\x44\x7b\x7f\xc5\xc0\xd5\xb7\x21\xad\xbf\x22\x24\x21\xbd\xb0\x75\xbb\x26\xbb\x92\x91\x74\x07\xf9\xfb\xe0\x5b\x93\x22\x95\x99\xb1\x66\xe6\x5d\xf0\xb9\x31\x0a\x09\xd9\x0f\x4c\xef\xc1\x0b\x9e\x56\xe5\x1b\xdd\xf7\x40\x93\xd5\xd8\xbd\xa8\x84\xe9\xbe\xb3\x81\xea\xd1\xc2\xd1\x02\x24\x15\x2b\x0b\x1b\x05\x0f\xc3\xd3\x05\x9b\x7a\x2c\xe9\xbb\x49\xcd\x78\x1f\x7d\x89\x7a\xfb\x62\xf3\xa6\x92\xe7\x82\x76\x16\xdf\xda\x2d\x96\xa6\x2f\x8f\xd7\x74\x70\x50\xa5\xa0\x9c\x00\xa1\x83\xf3\x04\x9a\xd2\x3b\x15\xce\xf8\x4b\x5d\x27\xd3\x75\x5e\x79\xe7\x32\xa2\x5d\x2d\xc6\x0b\x04\xd9\xca\xf1\x36\x34\xb6\x88\x9e\x80\xd0\xf3\xeb\x24\xfb\x60\x7b\xb4\xc2\x4f\xe7\x5c\xfc\xd7\xf9\x3b\x00\xd5\x13\xfc\xd5\x18\x01\x00\x00")
+
+func assetsCanaryRouteYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsCanaryRouteYaml,
+		"assets/canary/route.yaml",
+	)
+}
+
+func assetsCanaryRouteYaml() (*asset, error) {
+	bytes, err := assetsCanaryRouteYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/canary/route.yaml", size: 280, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb2, 0x9, 0xfc, 0x87, 0x30, 0xa9, 0xcc, 0xec, 0xff, 0xb1, 0xc6, 0xff, 0x80, 0x4b, 0x48, 0x32, 0x8f, 0xd3, 0x71, 0x18, 0x24, 0x3a, 0xa0, 0xce, 0xb3, 0x8c, 0xc3, 0x51, 0xe2, 0x4a, 0x86, 0xc9}}
+	return a, nil
+}
+
+var _assetsCanaryServiceYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x5c\x8e\xb1\x4e\x03\x41\x0c\x44\xfb\xfd\x0a\xff\xc0\x1d\xa1\x8b\xdc\xa6\xa2\x8b\x04\xa2\x45\xd6\xde\x90\x5b\xb1\xb7\x5e\xd9\x26\x52\xfe\x1e\x2d\x17\x52\x50\x59\xf6\x8c\xdf\xcc\x57\x69\x0b\xd3\x2b\xec\x5a\x32\x92\xf4\xf2\x0e\xf3\xa2\x8d\xe9\xfa\x9c\x36\x84\x2c\x12\xc2\x89\xa8\xc9\x06\xa6\xd2\x2e\x06\xf7\x29\x4b\x13\xbb\xdd\xcf\xde\x25\x83\x49\x3b\x9a\xaf\xe5\x33\xa6\x7f\x2e\xef\xc8\x03\x11\xb7\x0e\xa6\x53\xfd\xf6\x80\xbd\x9c\x13\x91\xa3\x22\x87\xda\x50\xe9\x0f\xbe\x7f\xcd\xda\x61\x12\x6a\xf3\x83\x3b\x17\x7d\x5a\x04\x9b\x36\xc7\x23\x64\x77\x33\xed\xf3\x23\x6b\x0b\xd3\x5a\x61\x89\xa8\xab\x85\x0f\xf6\x74\xaf\xbf\x46\xf4\xdf\xa8\xa1\x30\x1d\x0f\xc7\xc3\xbe\x9a\x86\x66\xad\x4c\x6f\xa7\xd1\x8b\x28\xc4\x2e\x88\xb3\x5a\x30\xad\x11\x3d\xfd\x0c\x00\xe9\xfb\xea\xeb\x29\x01\x00\x00")
+
+func assetsCanaryServiceYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsCanaryServiceYaml,
+		"assets/canary/service.yaml",
+	)
+}
+
+func assetsCanaryServiceYaml() (*asset, error) {
+	bytes, err := assetsCanaryServiceYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/canary/service.yaml", size: 297, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf7, 0x55, 0x47, 0x7e, 0xc5, 0x6, 0xf1, 0x70, 0x13, 0x9a, 0xa3, 0x6a, 0x6, 0x1d, 0x25, 0x3e, 0x93, 0xeb, 0xf1, 0xde, 0x9e, 0x2d, 0x68, 0x1d, 0xea, 0x74, 0x2e, 0x9, 0x8c, 0xca, 0xe9, 0x94}}
+	return a, nil
+}
+
+var _assetsCrdsDnsrecordYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x59\xdf\x8f\xe3\xb6\xf1\x7f\xd7\x5f\x31\xd8\x7b\xc8\xf7\x1b\xac\x95\x6e\xda\x14\x81\x81\xa0\x5d\xec\xb6\xc1\xb6\xd7\xed\xe2\x76\x91\x02\x0d\xee\x81\x96\xc6\x12\x7b\x12\xa9\x90\x23\xfb\xdc\xa2\xff\x7b\x31\x14\x29\x4b\xb2\x7e\xf9\x5a\xcb\x0f\x36\x39\x1c\xce\xcf\x0f\x87\xa3\x77\xf0\xf8\xfc\xfa\x01\x13\x6d\x52\x90\x16\x84\x02\x5d\xa1\x11\xa4\xcd\x46\x1f\x15\xa6\xf0\xf0\xe1\x31\x06\x78\xcb\xb1\x9d\x00\xa9\x2c\x89\xa2\x60\xea\x14\xea\x2a\x33\x22\x45\x0b\x92\x40\x50\xf4\x0e\x2c\x09\x43\x75\x05\x46\x50\x8e\x06\x28\x17\x0a\x0c\x16\x27\xa9\x32\xd0\x0a\x28\x47\x48\x8a\xda\x12\x1a\x38\xa0\xb1\x52\x9f\xb7\x04\xd2\x20\xaa\xaa\x38\x81\xa4\x38\x12\x95\xfc\xa9\x21\xd8\x82\xa8\x24\x7e\x26\x54\xfc\xcf\xc6\x9f\xbe\xb7\xb1\xd4\xdf\x1c\xee\x76\x48\xe2\x2e\xfa\x24\x55\xba\x85\x87\xda\x92\x2e\x3f\xa0\xd5\xb5\x49\xf0\x11\xf7\x52\x49\x92\x5a\x45\x25\x92\x48\x05\x89\x6d\x04\xa0\x44\x89\x5b\x48\x95\x35\x4e\x65\x1b\x4b\x95\x19\xb4\x36\x0e\x22\xf0\x0f\x65\x73\xb9\xa7\x58\xea\xc8\x56\x98\xf0\xb2\xcc\xe8\xba\xda\xc2\x3c\x71\xc3\xdd\x32\x3d\x40\x23\x53\x6b\x5b\x37\x56\x48\x4b\x7f\xee\x8f\xbf\x97\x96\xdc\x5c\x55\xd4\x46\x14\x5d\xd1\xdc\xb0\x95\x2a\xab\x0b\x61\x3a\x13\x11\x80\x4d\x74\x85\x5b\x78\x16\x25\xda\x4a\x24\x98\x46\x00\xef\xe0\x70\x07\xb9\xb0\xce\xc0\x56\x94\x08\x36\xc9\xb1\x14\x20\x2c\x1c\xee\x44\x51\xe5\xe2\xee\x16\xac\x76\xf3\xf7\x2f\x4f\x60\xd1\x1c\xd0\x40\xa2\xd5\x01\x0d\x59\xd8\x21\x1d\x11\x95\x63\x45\x39\x96\xb0\x3b\x41\x92\x0b\x95\x35\x8e\x2b\x4e\x6e\xe5\xd9\x29\x31\xb4\x7c\x39\x72\x2c\xc9\xa2\x68\x98\xa6\xb0\xd7\xc6\xf1\x49\x0a\x89\x8a\x58\x26\x41\x90\x8b\x03\x82\xd2\x04\xa5\x66\x1a\xd2\x70\xb8\x8b\x23\x08\x51\xe0\x0c\xb7\xf1\x1e\x3a\xdc\x35\xea\x3b\x76\x5b\x20\x53\x63\x33\x40\xda\x88\x0c\xdb\x91\x33\x7d\xa3\xe1\xc2\xaa\xbd\x28\x2c\x0f\x35\x4a\xf3\xae\x5b\x4f\x60\x04\x61\x76\xda\xc2\xb3\x56\x4c\x60\xeb\x9d\xf1\x91\xe4\x1d\x6a\x49\x50\x6d\xb7\xf0\xaf\x7f\x47\x00\x22\x4d\x5d\x6c\x89\xe2\xc5\x48\x45\x68\x1e\x74\x51\x97\x7d\x15\x1e\x9f\x5f\x9d\x87\xdc\x6a\x3a\xb1\xc3\x2c\x19\xa9\x32\x37\xf0\xa7\xd7\xbf\x3e\xbf\x08\xca\xb7\x10\x73\x90\xc5\xa9\xb2\x9e\x38\xac\x7f\x13\x26\x43\x5a\xb7\x9a\x1c\xad\xfd\xf9\xeb\x8f\x1d\x06\x7f\xd7\x6a\xc5\xe6\x4e\xad\xf8\x9f\x5a\x21\x2f\x67\x39\x78\x5d\x2c\xd3\x0e\xa7\x97\x7a\x57\x48\x9b\x63\x7a\x35\xbb\x44\xab\xc6\x52\xf6\xe7\xdf\xfd\xdf\xef\x63\x96\xe4\x87\x1f\x6e\x5a\x7e\x37\xff\xff\xd1\x2f\xe9\xec\x76\x9f\x75\xc5\x4e\x05\xe1\x70\x97\x90\xd1\x71\x62\x50\x30\xf7\x37\x59\xa2\x25\x51\x56\x1c\x4e\xa2\x90\xa9\x1b\x65\x6f\x00\x83\x8b\xba\x7f\x79\xfa\xe9\xd7\xaf\x2e\x1f\x9a\x41\x80\x14\x6d\x62\x64\xe5\xe8\xce\xf9\xc8\x91\x2c\xf8\x2f\x34\x00\x01\xa5\x50\x22\xc3\x14\x64\x83\x5c\x4e\x31\x48\x19\x5c\x30\x85\xdd\xc9\x33\x03\x4e\xcf\x38\xd1\x6a\x2f\xb3\x1e\x28\x7c\x13\xa0\xae\xf1\x72\xc5\x8a\x27\x6c\x60\x87\x9d\x7e\xd0\xc8\x83\x20\xe4\xd1\xd8\xf3\xab\x0c\x43\x0c\xc9\x80\x26\xfc\x74\xe0\xb0\x1d\x1b\x71\x46\x0b\x3e\x4b\x44\x5d\x58\x0c\x9f\x86\x9b\xde\xfd\x03\x13\x6a\xb9\x05\x10\x04\x18\xb1\x1c\x4f\xb2\xd1\xd8\x38\xfc\x5b\xee\x65\xe2\x8c\x0f\x7a\xef\x2c\x96\xa2\x95\x86\x6d\x85\xb9\x38\x48\x6d\x40\xef\x3b\xbc\xa0\xa1\x51\xb6\x31\x7f\xd0\x7f\xca\x06\xde\xd2\x9c\x29\xfd\xc1\x81\x54\x9e\x26\x08\x96\x6b\x4b\x1c\xc9\x41\xa6\xb3\x7f\xbb\x1b\xf2\x53\x4a\xf5\x1e\x55\xc6\x51\x76\x37\x98\x1a\x35\x22\x7f\x1b\x4e\x6f\x3c\x3d\x27\xd3\x99\x2c\x88\xd5\x09\x33\x66\x7e\x0b\xb6\x4e\x72\xc6\xeb\x9b\xfb\x1b\x70\x08\xda\x7f\x6e\x1e\x9e\xef\xff\xf2\x87\x9b\xa1\xcc\xa8\xea\x72\xb8\xf3\x06\xee\x2f\x46\xdc\xea\xb5\x4a\x79\x44\x99\xd5\xc8\xd3\x80\x30\xd8\xea\xd1\x0c\x0d\x45\x94\x84\xe5\xc0\x8d\xb3\xbb\x3b\x47\x3c\xb9\x45\x13\x7e\x10\xc6\x88\xd3\x98\x1b\xde\xde\xaf\xf1\xc2\xdb\xfb\xe0\x04\x2f\xb8\x1b\x51\x60\x91\x11\xeb\x42\xfe\xbd\x36\xa5\x20\x2e\x00\xe8\xb7\xbf\x19\xcc\x95\x52\xc9\xb2\x2e\xb7\xf0\xab\xc1\x44\xa3\x1d\x1f\x0f\x19\xf6\x9d\xc9\x28\xb2\x18\x2f\x81\x08\x0c\xb2\x85\x12\xea\xc9\x4b\xba\x5b\x49\x7d\x65\x07\x9c\x00\x1a\xa8\x01\x6d\xc0\xe3\x8b\x63\x18\x03\x3c\xed\x01\xcb\x8a\x4e\xb7\x5d\x76\xd2\x42\xd5\x03\xf9\xee\x43\x1a\x76\x9a\x72\xc7\xc0\xae\x8b\x3e\x87\xf0\xc9\xe5\x70\x23\x4a\xb4\x32\x0c\x8c\xae\x49\xaa\xec\x45\x17\x32\x39\xcd\x1a\xab\x47\x79\x0b\x72\x0f\x16\xe9\xb6\xd5\xa9\x67\x3a\x61\x21\x9c\x8c\xdd\x8f\xe6\x35\x07\x34\xa2\xf0\x84\x16\x8e\x92\xf2\x73\x3d\xe5\x10\x84\x61\x9b\x1d\x7b\x0b\xa2\xd4\x2a\x83\x63\x2e\x93\xfc\x82\x57\xc8\xee\xca\xe8\x83\x4c\xb9\xca\xca\xb5\xb6\x68\x41\x24\x2c\x02\x57\x54\xde\x81\x95\x93\x78\x68\xd4\x29\xec\x6b\xad\x75\x31\x3a\xb0\x07\x75\x60\xc6\xfd\xf6\xc8\xe7\xed\x34\xb1\xed\x94\x3f\xf9\xd9\xc0\xdf\x50\x66\x39\x8d\xc4\x07\x4f\xfe\x51\xc8\x42\x1f\xd0\x8c\x4e\xfe\x88\xba\xd0\xcd\xb1\x30\x32\x3f\x83\x02\xc0\x6e\x7c\x4a\x51\x91\xdc\x4b\x34\x8b\x6a\xf7\xa8\x21\x95\x96\xc3\xa7\xbe\x88\x80\xbd\xd1\xe5\x08\xab\xe6\x30\xd2\xee\xda\xb2\x22\x04\xc6\xac\x57\x8a\xcf\xed\xf9\xf1\xed\xf7\x63\x04\xd3\x07\xcc\xa2\x2d\x8e\xde\x01\x8b\x66\x08\x84\x21\x02\x1a\x6f\x73\x55\xee\x54\x09\x8e\x9c\xd4\x62\x2e\xfc\xce\x82\x8c\xcf\x8d\x8a\x12\x04\xb1\x39\x9f\x15\x7a\x0f\xbf\xd4\x68\x24\xfa\x8b\x01\xe5\x97\xd9\x18\x3e\x21\x67\x95\x3d\xa2\xb9\x00\x9f\x35\xf8\x1c\x3e\xa5\xf8\xdc\xe0\xf4\xb7\xdf\x7d\x37\x45\x32\x05\xe5\xcb\x90\x1e\xa4\xfd\xa5\xe6\x6a\x67\xcc\x34\x1b\x6f\x8b\x91\xa9\xd1\xb2\x2b\x3c\x7b\x9f\x59\xdb\x68\xc1\xd4\x81\x70\xc2\xeb\x21\x43\xbf\xd8\xeb\x46\x17\xb8\xca\xe7\x4c\x18\x84\x70\xbf\x03\xf8\x8c\x96\x5c\x4b\xc0\xd3\x9e\x1c\xa5\x30\xa7\x68\x64\xd2\xcd\xbf\xba\x83\x7b\x9a\x62\x36\xb1\xf8\x9b\xa3\x28\x28\x7f\xc8\x31\xf9\xf4\xf4\xb8\x4a\xcd\xde\x8a\xa0\xef\xd3\x23\x17\x99\xc2\xb3\x83\x84\xf9\x4d\x30\x83\x6e\x39\xda\x9e\x14\x2e\x23\x52\x24\x34\xa5\xe4\xbb\xc6\x31\x47\xdf\x47\x59\x4c\x12\x69\xfd\xb6\xa7\xf8\xcb\xac\x30\x1f\xbe\xec\xca\x6b\x83\x37\x3b\x23\xff\x36\x5a\xb0\x67\x87\x76\x22\x84\xe7\xcf\x11\xf8\xe2\xc8\x4e\xb4\x22\xa9\x50\xd1\x83\x4e\xd7\x85\x78\x6f\x45\x90\x96\x8e\x7a\x53\x20\xf1\x75\x2f\xe1\xe1\xc1\x4d\xa7\xfb\x30\x79\xcb\x03\x8e\xb9\xb6\xd8\xc1\x44\x5c\x09\x7b\x0b\xee\x64\xc5\x6a\x45\xe6\x74\x85\x5a\x2d\xfd\xb4\x52\xb3\xa1\xe8\x39\x2c\xaa\x74\xcb\x95\xe9\xcd\xd7\x17\x97\x99\xd5\xaa\xd9\x7a\x97\xca\x83\xe4\xfb\xf0\x6a\xf5\x06\x6b\x82\x8a\x6b\xf4\xea\x2c\xf5\xa4\x8b\xaa\x4e\xf2\xfa\xef\xbc\x3a\x93\x6f\x53\xf9\xbb\x71\x3c\x2f\x06\x7b\xd5\x52\xb4\x72\x97\xd4\x9c\x3e\xd4\x17\xc9\xdc\x33\x73\x43\xe2\xaa\x70\x6e\xd3\xdd\x42\x29\x3e\x79\xcb\xb4\x5d\x4c\xee\x3b\x92\xd1\x45\x31\x72\x8e\x1a\xac\xb4\x21\x47\xef\x1a\x90\xd8\x94\x09\xbe\x98\xe7\xf2\xb5\x13\x4f\x5e\x67\x0b\xf2\x12\x13\x7a\xdd\xa8\x8f\x71\x55\x08\xa5\x30\x7d\x68\x78\x72\x17\x1b\x45\xca\xde\x74\x3d\x67\xe6\xcb\x6d\xe7\x31\x3b\xec\xb4\x2e\x50\xa8\x68\xde\xd4\x9b\xd0\x9b\xe8\x8d\xf9\x1b\x67\xdf\x01\x9b\x70\x53\x8e\x16\x4c\xee\xdb\x8e\xd1\x84\xa9\x9b\xe9\x10\xc8\xa5\xb6\xc4\xc1\x87\x8a\x8a\x13\xe8\x9d\xef\xc6\x7a\xa2\x91\x08\xbf\xaa\xf1\x12\xf8\xfd\x88\x0a\xcd\x28\xa4\xf7\x44\xbb\x24\x9f\x17\x73\xc0\x8b\xcf\x8e\x76\xa1\xde\x4f\xf7\x89\x96\xea\xbf\xe9\x9a\xed\xdc\x9c\x9c\x55\xe4\x4c\xe6\x3a\x1d\x42\x9d\x3a\x2b\x41\x58\xab\x13\x29\x08\x53\x77\x5d\x1c\x30\x82\x6e\xa8\x0a\xee\x2d\x1e\x73\xae\x88\xba\x2f\x47\x78\x19\x08\xa8\x84\x21\x99\x70\xb3\xdf\x5d\xb4\x57\xb6\x50\xe6\x8f\xb6\xa9\xbb\xe2\x22\xc8\x5c\x86\xdd\xea\xa5\x85\xb0\xf4\x66\x84\xb2\x32\x74\x66\xb7\xd1\x5c\xcd\xce\x0d\xde\x0d\xc9\x5e\xd6\xac\xde\xcc\xa0\xb0\x5a\x7d\x91\x9c\x25\x5a\xcb\x2f\x05\xae\x5f\x3b\x96\xfb\x33\x40\xcb\xc3\x6d\x93\x7b\x25\xca\x4e\xb7\xb9\x38\x36\xe6\xe3\xd5\x51\xb8\x50\xe5\xd8\xeb\x25\x7f\x88\x44\xa9\x00\x45\x92\xff\xef\x02\xcd\xbf\x36\x18\x9b\x1a\x48\xe7\x29\x03\x16\xb0\x04\x70\xcc\xd1\x60\x4f\xbe\x4b\x53\x79\x29\x42\x93\x2a\x8e\x46\xa7\x67\x85\xe4\xaf\x1c\xf1\xda\xa8\x9c\xb2\xbd\x3d\xcb\x73\x47\xc1\x9d\x43\x89\x50\xb0\x43\xa8\xed\x68\x2b\x24\x3c\xa4\x61\x2f\x55\xda\x16\xf8\xdc\x83\xc6\x74\xd4\xe2\x2b\xc3\xce\x93\x88\x6c\x52\xb9\xfe\x8b\xa9\x25\x53\xac\xdc\x70\x60\x17\x12\x99\xed\x9a\x80\xdf\xd9\x72\xe5\x7a\x0a\x9a\xce\xec\x76\x85\x0d\x46\x73\x62\x15\xc1\x19\x9b\xb7\xd1\xa2\x36\x6b\xb1\x7d\x94\x13\x9c\xbb\x43\xe7\xbc\x0a\x41\x3d\xae\xe0\x44\x72\xad\x8d\x5e\xaf\xfc\xe4\xec\x4a\x87\xce\xa1\xfb\x15\x6c\xd6\x22\xfd\x35\x78\x7f\xc5\xf6\x73\xd8\x7f\x05\x9b\xd9\x73\x60\x35\x9f\xe9\x33\x61\xf6\x64\x58\x38\x1f\x56\x05\xfc\xf4\x59\xe1\xc3\xaa\x5b\xf6\xae\xc8\x89\x41\x99\x6c\x67\x0a\xf1\x51\x66\x30\x91\x11\xe1\xdc\xb4\xb7\x61\xb4\x09\x09\x7f\x36\x4d\xf0\xea\x36\x47\xbe\xb2\x70\xff\xf2\xe4\x2e\x16\xfc\xae\x31\x6e\x2e\x1a\x0e\xa7\x4d\x3d\x91\x71\xb3\xbe\x9b\xf6\x9a\xab\xe5\xdb\x37\xe8\x2b\xbd\x31\xee\x87\x91\x05\xc3\x7d\x37\x4e\x9f\x68\x84\xfe\x3f\x03\x00\x2d\x3b\xa8\x92\xa0\x23\x00\x00")
+
+func assetsCrdsDnsrecordYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsCrdsDnsrecordYaml,
+		"assets/crds/dnsrecord.yaml",
+	)
+}
+
+func assetsCrdsDnsrecordYaml() (*asset, error) {
+	bytes, err := assetsCrdsDnsrecordYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/crds/dnsrecord.yaml", size: 9120, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x88, 0xc9, 0x69, 0x48, 0x3e, 0x10, 0x48, 0x31, 0x3e, 0x87, 0xe9, 0xd, 0x69, 0x9c, 0x2c, 0xe2, 0x64, 0x75, 0x6e, 0xca, 0xd1, 0x97, 0xe9, 0xe2, 0xce, 0x6d, 0xcd, 0xbf, 0xe7, 0x6f, 0x11, 0x5e}}
+	return a, nil
+}
+
 var _assetsRouterClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x8f\x31\x4e\xc4\x40\x0c\x45\xfb\x39\x85\x25\xea\x0c\xa2\x43\xd3\x01\x37\x58\x24\x7a\xef\xc4\xbb\x31\x49\xec\xc8\xf6\xa4\xe0\xf4\x28\x4a\x44\xc3\x4a\x29\x2d\xf9\xbf\xff\xfe\x13\xbc\xb3\xf4\x0e\x31\x10\x98\xb6\x20\x03\xd3\x89\x20\x14\x38\x1c\x3e\xc9\x56\xae\x04\x6f\xb5\x6a\x93\xc8\x69\x64\xe9\x0b\x7c\x4c\xcd\x83\xec\xa2\x13\x6d\x71\x96\x7b\xc2\x85\xbf\xc8\x9c\x55\x0a\xd8\x15\x6b\xc6\x16\x83\x1a\xff\x60\xb0\x4a\x1e\x5f\x3d\xb3\x3e\xaf\x2f\x69\xa6\xc0\x1e\x03\x4b\x02\x10\x9c\xa9\x80\x2e\x24\x3e\xf0\x2d\x3a\x96\xbb\x91\x7b\xb7\x9b\x24\x6f\xd7\x6f\xaa\xe1\x25\x75\xb0\x17\x1f\x3e\x87\xce\x1f\xe1\xf8\xdf\x4f\x5f\xb0\x3e\xa2\xa6\x6d\xd8\x85\x6e\x5b\xf1\xbf\x19\xe7\x32\x27\xf0\xdf\x00\x00\x00\xff\xff\x83\x13\xa9\xa6\x49\x01\x00\x00")
 
 func assetsRouterClusterRoleBindingYamlBytes() ([]byte, error) {
@@ -99,7 +205,7 @@ func assetsRouterClusterRoleBindingYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\x31\x6f\xe3\x30\x0c\x85\x77\xfd\x0a\x22\x37\xdb\xc1\x6d\x07\xaf\x37\xdc\x76\x43\x51\x74\xa7\x65\xa6\x66\xed\x88\x02\x49\x39\x6d\x7f\x7d\x61\x3b\x29\x82\x24\x45\x9b\xcd\xcf\x22\xbf\x47\x3e\xe9\x17\xfc\x1d\x8b\x39\x29\x58\x94\x4c\x1d\xa8\x8c\x04\x3b\x51\x50\x29\x4e\x6a\x35\x3c\xf6\x6c\x60\xbd\x94\xb1\x83\x96\x00\x0d\x94\xcc\x95\xa3\xf3\xb4\xc8\x2c\x66\xdc\x8e\x54\x87\x81\x53\xd7\x9c\x88\x0f\x32\x52\xc0\xcc\x4f\xa4\xc6\x92\x1a\xd0\x16\x63\x8d\xc5\x7b\x51\x7e\x47\x67\x49\xf5\xf0\xc7\x6a\x96\xed\xf4\x3b\xec\xc9\xb1\x43\xc7\x26\x00\x24\xdc\x53\x03\x92\x29\x59\xcf\x3b\xaf\x38\x3d\x2b\x99\x55\xeb\x48\x41\xcb\x48\xd6\x84\x0a\x30\xf3\x3f\x95\x92\x6d\x6e\xaa\x60\xb3\x09\x30\xcf\x26\x45\x23\x1d\xff\x51\xea\xb2\x70\x72\x5b\xd4\x0c\xb6\x8c\x91\x56\x69\xa4\x13\xaf\x62\x22\x6d\x8f\x2d\x23\x9b\x2f\x1f\x07\xf4\xd8\x87\x6b\x9f\x79\x05\x4a\xce\xf1\x7c\x87\x6b\x6b\x97\x81\x92\xd2\xc4\x74\xb8\x70\x88\x4a\xe8\xf4\x05\xf9\x32\x9c\x6b\xb0\x95\xf6\x85\xa2\x63\x8c\x64\x76\x9f\xc1\x92\x60\xfd\x99\xec\x4d\xfc\x52\x73\x6f\x26\x3f\x07\x6f\xcd\xd1\xcb\x05\xbf\xe4\xee\xf6\xc0\x46\xb1\x28\xfb\xdb\x37\xe8\x53\x59\x94\xe4\xf4\xea\x51\x92\xb9\xe2\xf1\xde\xcf\x7d\x8c\xce\x9a\xff\xcf\xcf\x61\x3d\xe8\xc5\x3c\x91\x1f\x44\x87\xf0\x11\x00\x00\xff\xff\xad\x45\xb2\xc3\x14\x03\x00\x00")
+var _assetsRouterClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x92\xbd\x8e\xdb\x30\x0c\x80\x77\x3d\x05\x91\xce\x76\xd0\xad\xf0\xda\xa1\x7b\x51\x74\xa7\x65\x26\x66\xad\x88\x02\x49\x39\xc5\x3d\xfd\xc1\x4e\xee\x07\x71\x6e\xc8\x01\xb7\x99\x06\xf9\x7d\x24\xc5\x6f\xf0\x33\x55\x73\x52\xb0\x28\x85\x06\x50\x49\x04\x07\x51\x50\xa9\x4e\x6a\x2d\xfc\x19\xd9\xc0\x46\xa9\x69\x80\x9e\x00\x0d\x94\xcc\x95\xa3\xf3\xbc\x86\x45\xcc\xb8\x4f\xd4\x86\x89\xf3\xd0\xbd\x10\x7f\x4b\xa2\x80\x85\xff\x92\x1a\x4b\xee\x40\x7b\x8c\x2d\x56\x1f\x45\xf9\x09\x9d\x25\xb7\xd3\x0f\x6b\x59\xf6\xf3\xf7\x70\x22\xc7\x01\x1d\xbb\x00\x90\xf1\x44\x1d\x48\xa1\x6c\x23\x1f\xbc\xe1\x7c\x54\x32\x6b\x2e\x2d\x05\xad\x89\xac\x0b\x0d\x60\xe1\x5f\x2a\xb5\xd8\x52\xd4\xc0\x6e\x17\x60\xe9\x4d\xaa\x46\xba\xfe\xa3\x3c\x14\xe1\xec\xb6\x66\x2c\x60\x2b\x18\xe9\x12\x1a\xe9\xcc\x97\x60\x26\xed\xaf\x25\x89\xcd\xd7\x8f\x33\x7a\x1c\xc3\xd6\xb3\x8c\x40\xd9\x39\xbe\x9f\x61\xab\x76\x99\x28\x2b\xcd\x4c\xe7\x1b\x43\x54\x42\xa7\x0f\xc8\xb7\xcb\xd9\x82\xad\xf6\xff\x28\x3a\xc6\x48\x66\x8f\x09\x32\xf9\x59\x74\xe2\x7c\x7c\xa3\x37\x40\xff\x9d\xf2\xf2\x46\xb6\x95\x5d\x77\xff\xf0\x92\xee\x99\xee\xa3\x63\xc2\x4f\xf0\xd7\x5b\x68\x5f\x6f\xe4\xae\x60\xcd\xf9\x3a\xf0\xde\x1c\xbd\xde\xf0\x6b\x19\xd0\x29\x3c\x0f\x00\x66\x42\x96\x4e\x58\x03\x00\x00")
 
 func assetsRouterClusterRoleYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -114,8 +220,8 @@ func assetsRouterClusterRoleYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "assets/router/cluster-role.yaml", size: 788, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa, 0xc4, 0x4e, 0xa2, 0x9d, 0x1b, 0xd7, 0x35, 0xbf, 0x95, 0x95, 0xb4, 0x17, 0x87, 0x56, 0xb, 0x12, 0xf, 0xb6, 0x3e, 0x51, 0xe5, 0x5a, 0xd0, 0x66, 0x3a, 0x4d, 0x36, 0xc9, 0x93, 0x90, 0xb2}}
+	info := bindataFileInfo{name: "assets/router/cluster-role.yaml", size: 856, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa3, 0xba, 0x79, 0x2e, 0x8c, 0xd5, 0x47, 0x5b, 0xbf, 0x66, 0x72, 0xc2, 0x99, 0x92, 0xa4, 0xe3, 0xb3, 0x59, 0x3b, 0xca, 0x2d, 0x5f, 0xc, 0x9e, 0x9d, 0x34, 0x89, 0x3b, 0x49, 0x9f, 0x27, 0xbc}}
 	return a, nil
 }
 
@@ -279,7 +385,7 @@ func assetsRouterServiceCloudYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterServiceInternalYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xcf\x31\x6b\xfb\x30\x10\x05\xf0\x5d\x9f\xe2\x41\xd6\xff\xbf\x34\x24\x94\x56\xab\xa7\x6c\x86\x96\xee\x87\x7c\x49\x8e\xca\x92\xb8\x3b\xbb\xf4\xdb\x97\x38\x0d\xb8\x74\xc9\x22\x90\xee\xe9\xf7\xb8\x0d\xba\x3c\x99\xb3\xe2\x95\x75\x96\xc4\xf8\x14\x3f\x63\xe0\x23\x4d\xd9\x31\x53\x9e\xd8\xc2\x06\x87\x72\x52\x36\x43\x57\x8b\x6b\xcd\x99\x15\xd6\x38\xc9\x51\x12\xa8\x94\xea\xe4\x52\x8b\x81\x94\x41\xad\x65\xe1\x01\xe4\xd0\xa9\xb8\x8c\xfc\x10\x3e\xa4\x0c\xf1\xd6\x11\xa8\xc9\x3b\xab\x49\x2d\x11\xf3\x36\x6c\x50\x68\xe4\x7f\xcb\x69\x8d\x12\x83\xca\xf0\x87\x35\xf6\x5f\xe4\xa5\x3f\x06\xc0\xbf\x1a\xc7\xdb\x1a\x87\x3e\x00\xad\xaa\xdb\x65\xf4\x7f\x21\x23\xce\xee\x2d\x00\xd7\x49\xc4\xf3\xe3\xf5\xa2\xd5\x6b\xaa\x39\xe2\xad\xeb\x97\x17\x27\x3d\xb1\xf7\x4b\xe8\xe7\xcf\x9a\xb0\x95\xb1\xdf\xef\xee\x44\x6c\xa5\x8c\xec\x2a\x69\xed\x6c\x5f\x76\x4f\x77\x40\x4b\xec\x3b\x00\x00\xff\xff\x90\x5e\x33\xca\xad\x01\x00\x00")
+var _assetsRouterServiceInternalYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xd1\xc1\x6e\x22\x31\x0c\x06\xe0\x7b\x9e\xe2\x17\x73\xdd\x45\x8b\x40\xab\xdd\x5c\x39\x71\x43\x2a\xea\xdd\xcd\x18\xc6\x6a\x48\x22\xdb\x43\xd5\xb7\xaf\x80\xd2\x0e\xea\x85\x4b\xa4\xc8\xf1\xe7\x24\x7f\x87\x75\x1e\xcd\x59\xf1\xc4\x7a\x92\xc4\x78\x13\x1f\xd0\xf3\x9e\xc6\xec\x38\x51\x1e\xd9\x42\x87\x4d\x39\x28\x9b\x61\x5d\x8b\x6b\xcd\x99\x15\xd6\x38\xc9\x5e\x12\xa8\x94\xea\xe4\x52\x8b\x81\x94\x41\xad\x65\xe1\x1e\xe4\xd0\xb1\xb8\x1c\x79\x1e\x5e\xa5\xf4\xf1\x36\x23\x50\x93\x67\x56\x93\x5a\x22\x4e\x8b\xd0\xa1\xd0\x91\x7f\x5d\x56\x6b\x94\x18\x54\xfa\x1f\xac\xb1\xdf\x91\xe7\xf9\x31\x00\xfe\xde\x38\xde\x9e\xb1\xd9\x06\xa0\x55\x75\x3b\x97\x7e\x5f\xc8\x88\xc1\xbd\x05\xe0\x5a\x89\xf8\xf7\xe7\xba\xd1\xea\x35\xd5\x1c\xb1\x5b\x9f\xdb\x00\x27\x3d\xb0\x6f\xab\xfa\x57\xcf\x94\xb0\x89\xb1\x5a\x2d\x1f\x44\x6c\xa2\x1c\xd9\x55\xd2\xd4\x59\xfc\x5f\xfe\x7d\x00\xfa\x3c\xd6\x61\x37\x30\x66\xe6\xe4\x36\xbb\xdc\x04\x62\xa0\xbe\xbf\xfb\x6e\xc8\x1e\x3e\x30\xe4\x1a\x5a\xfa\xce\x8c\x0b\xbd\x64\x36\x88\xcf\xc3\xc7\x00\x60\x92\x70\xc4\xfb\x01\x00\x00")
 
 func assetsRouterServiceInternalYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -294,8 +400,28 @@ func assetsRouterServiceInternalYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "assets/router/service-internal.yaml", size: 429, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb8, 0x63, 0x52, 0x85, 0x8b, 0x99, 0xe6, 0xc7, 0xcb, 0x34, 0x3d, 0x8d, 0x43, 0x65, 0x10, 0x63, 0x51, 0x80, 0xc1, 0x29, 0x17, 0xb6, 0x8f, 0x84, 0xdc, 0xf8, 0x33, 0xa1, 0x21, 0xc2, 0x5a, 0x4f}}
+	info := bindataFileInfo{name: "assets/router/service-internal.yaml", size: 507, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xef, 0xf4, 0xf9, 0xb7, 0x3f, 0xa2, 0x9e, 0x24, 0x44, 0x7a, 0xbd, 0x57, 0x65, 0xfb, 0x27, 0x16, 0xba, 0x90, 0x7b, 0xe7, 0xd9, 0x39, 0xb9, 0xac, 0xb3, 0x11, 0x8, 0x9f, 0x38, 0x93, 0xda, 0xc5}}
+	return a, nil
+}
+
+var _assetsRouterServiceNodeportYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x92\xcf\x8a\x5b\x3d\x0c\xc5\xf7\x7e\x8a\x03\x59\x7c\x9b\x6f\x86\x96\xce\xa2\xdc\x5d\x99\xd5\x40\x49\x03\x1d\xba\x57\x6c\x25\xd7\xc4\xb1\x8c\xa4\x9b\x36\x6f\x5f\xec\xfc\x61\x86\x96\x2e\xaf\xae\xf4\x3b\x47\xc7\x5a\x61\x2d\x89\x37\xa2\x8e\xef\xac\xa7\x1c\x19\x2e\x68\xcb\xb6\x64\x9b\xe1\x33\x43\x65\x71\x56\xec\x44\xc7\xe7\xad\xfd\xd6\xcd\x35\x35\xc9\xd5\xc3\xea\x36\x95\xeb\x1e\xe6\x4a\xce\xfb\xf3\x63\x58\xe1\xa5\xee\x95\xcd\xf0\x2c\xd5\x55\x4a\x61\x85\x35\x8e\x79\x97\x23\x4e\x54\x16\x36\x90\x32\xa8\xb5\x92\x39\x81\x1c\xba\x54\xcf\x47\x7e\x0c\x87\x5c\xd3\x74\x33\x16\xa8\xe5\x1f\xac\x96\xa5\x4e\x38\x7d\x0c\x47\x76\x4a\xe4\x34\x05\x60\x85\x35\x1d\x19\xd9\x60\xec\xef\x10\x40\xa5\x23\x5b\xa3\xc8\x13\xa4\x71\xb5\x39\xef\xfc\x21\x5f\x4c\x05\xa0\xd0\x96\x8b\x75\x08\xba\x87\xe9\xba\x70\xe8\x1e\x7b\xd5\xcf\x8d\xa7\x7b\x4a\x01\x30\x2e\x1c\x5d\xf4\xcf\x91\xee\xe3\x5b\x2d\x67\x54\x49\x6c\xf8\x99\x7d\xbe\xfe\x42\x93\x64\xa0\x18\xb9\x39\x5c\x69\xd7\x97\x97\x3a\x12\xed\xcd\x68\xa2\x6e\xff\xc3\x04\x54\xc7\x3e\xfc\xcb\x59\x2b\x15\x14\xa1\x84\x2d\x15\xaa\x91\xf5\x3f\xc3\xcc\x54\x7c\x46\x9c\x39\x1e\x0c\x4e\x07\x7e\x23\x27\x8b\xbf\x53\x94\xc5\x07\x4d\x76\x50\x71\xf2\x2c\x15\x54\xd3\xd0\x8d\x25\x73\x75\x98\x2c\x1a\x19\x94\x52\x0f\xa4\x47\xd8\x94\x8d\xf5\xc4\xe9\x31\xe0\xee\xe3\xf5\x62\x7a\x23\x25\xc7\xf3\x84\xaf\x12\xa9\x0c\xf4\xfa\x6e\xff\xf2\x8c\xa5\x48\x24\xe7\x84\xed\x79\xc8\x7c\xd9\xbc\x60\xe0\x14\x4b\x2d\x5d\xa2\x57\xaf\xf9\xc7\xfb\x4d\x0c\xd6\xf5\x2e\x78\xf4\x1c\xbb\xfc\xc8\xa5\x27\xfd\x30\xde\x71\xc2\xec\xde\x46\xf0\x4d\xc5\x25\x4a\x99\xf0\xfa\xbc\xb9\x54\x44\x7d\xc2\xe7\x0f\xe3\xc3\x49\xf7\xec\xfd\x52\xef\x33\x6f\x11\xf6\x4f\xc6\xd3\xd3\xa7\xbf\x42\x2c\xfc\x1e\x00\x73\xc0\x1f\x63\x30\x03\x00\x00")
+
+func assetsRouterServiceNodeportYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsRouterServiceNodeportYaml,
+		"assets/router/service-nodeport.yaml",
+	)
+}
+
+func assetsRouterServiceNodeportYaml() (*asset, error) {
+	bytes, err := assetsRouterServiceNodeportYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/router/service-nodeport.yaml", size: 816, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdf, 0x45, 0x51, 0x2f, 0x6b, 0x4b, 0x30, 0x61, 0x2f, 0x71, 0x4b, 0xee, 0x51, 0x8a, 0xaa, 0xa2, 0x74, 0x5, 0x40, 0xe8, 0xae, 0xb7, 0x33, 0x81, 0x8c, 0x4b, 0x21, 0x24, 0xc0, 0x7f, 0xde, 0xd9}}
 	return a, nil
 }
 
@@ -390,6 +516,16 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
+	"assets/canary/daemonset.yaml": assetsCanaryDaemonsetYaml,
+
+	"assets/canary/namespace.yaml": assetsCanaryNamespaceYaml,
+
+	"assets/canary/route.yaml": assetsCanaryRouteYaml,
+
+	"assets/canary/service.yaml": assetsCanaryServiceYaml,
+
+	"assets/crds/dnsrecord.yaml": assetsCrdsDnsrecordYaml,
+
 	"assets/router/cluster-role-binding.yaml": assetsRouterClusterRoleBindingYaml,
 
 	"assets/router/cluster-role.yaml": assetsRouterClusterRoleYaml,
@@ -411,6 +547,8 @@ var _bindata = map[string]func() (*asset, error){
 	"assets/router/service-cloud.yaml": assetsRouterServiceCloudYaml,
 
 	"assets/router/service-internal.yaml": assetsRouterServiceInternalYaml,
+
+	"assets/router/service-nodeport.yaml": assetsRouterServiceNodeportYaml,
 }
 
 // AssetDir returns the file names below a certain
@@ -455,6 +593,15 @@ type bintree struct {
 
 var _bintree = &bintree{nil, map[string]*bintree{
 	"assets": {nil, map[string]*bintree{
+		"canary": {nil, map[string]*bintree{
+			"daemonset.yaml": {assetsCanaryDaemonsetYaml, map[string]*bintree{}},
+			"namespace.yaml": {assetsCanaryNamespaceYaml, map[string]*bintree{}},
+			"route.yaml":     {assetsCanaryRouteYaml, map[string]*bintree{}},
+			"service.yaml":   {assetsCanaryServiceYaml, map[string]*bintree{}},
+		}},
+		"crds": {nil, map[string]*bintree{
+			"dnsrecord.yaml": {assetsCrdsDnsrecordYaml, map[string]*bintree{}},
+		}},
 		"router": {nil, map[string]*bintree{
 			"cluster-role-binding.yaml": {assetsRouterClusterRoleBindingYaml, map[string]*bintree{}},
 			"cluster-role.yaml":         {assetsRouterClusterRoleYaml, map[string]*bintree{}},
@@ -469,6 +616,7 @@ var _bintree = &bintree{nil, map[string]*bintree{
 			"service-account.yaml":  {assetsRouterServiceAccountYaml, map[string]*bintree{}},
 			"service-cloud.yaml":    {assetsRouterServiceCloudYaml, map[string]*bintree{}},
 			"service-internal.yaml": {assetsRouterServiceInternalYaml, map[string]*bintree{}},
+			"service-nodeport.yaml": {assetsRouterServiceNodeportYaml, map[string]*bintree{}},
 		}},
 	}},
 }}
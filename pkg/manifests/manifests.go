@@ -2,19 +2,15 @@ package manifests
 
 import (
 	"bytes"
-	"encoding/base64"
-	"fmt"
 	"io"
 
-	operatorv1 "github.com/openshift/api/operator/v1"
-
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
 	"k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/apiserver/pkg/storage/names"
 
 	routev1 "github.com/openshift/api/route/v1"
 )
@@ -27,12 +23,20 @@ const (
 	RouterDeploymentAsset         = "assets/router/deployment.yaml"
 	RouterServiceInternalAsset    = "assets/router/service-internal.yaml"
 	RouterServiceCloudAsset       = "assets/router/service-cloud.yaml"
+	RouterServiceNodePortAsset    = "assets/router/service-nodeport.yaml"
 
 	MetricsClusterRoleAsset        = "assets/router/metrics/cluster-role.yaml"
 	MetricsClusterRoleBindingAsset = "assets/router/metrics/cluster-role-binding.yaml"
 	MetricsRoleAsset               = "assets/router/metrics/role.yaml"
 	MetricsRoleBindingAsset        = "assets/router/metrics/role-binding.yaml"
 
+	DNSRecordCRDAsset = "assets/crds/dnsrecord.yaml"
+
+	CanaryNamespaceAsset = "assets/canary/namespace.yaml"
+	CanaryDaemonSetAsset = "assets/canary/daemonset.yaml"
+	CanaryServiceAsset   = "assets/canary/service.yaml"
+	CanaryRouteAsset     = "assets/canary/route.yaml"
+
 	// Annotation used to inform the certificate generation service to
 	// generate a cluster-signed certificate and populate the secret.
 	ServingCertSecretAnnotation = "service.alpha.openshift.io/serving-cert-secret-name"
@@ -79,23 +83,6 @@ func RouterClusterRoleBinding() *rbacv1.ClusterRoleBinding {
 	return crb
 }
 
-func RouterStatsSecret(cr *operatorv1.IngressController) *corev1.Secret {
-	s := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("router-stats-%s", cr.Name),
-			Namespace: "openshift-ingress",
-		},
-		Type: corev1.SecretTypeOpaque,
-		Data: map[string][]byte{},
-	}
-
-	generatedUser := names.SimpleNameGenerator.GenerateName("user")
-	generatedPassword := names.SimpleNameGenerator.GenerateName("pass")
-	s.Data["statsUsername"] = []byte(base64.StdEncoding.EncodeToString([]byte(generatedUser)))
-	s.Data["statsPassword"] = []byte(base64.StdEncoding.EncodeToString([]byte(generatedPassword)))
-	return s
-}
-
 func RouterDeployment() *appsv1.Deployment {
 	deployment, err := NewDeployment(MustAssetReader(RouterDeploymentAsset))
 	if err != nil {
@@ -120,6 +107,14 @@ func LoadBalancerService() *corev1.Service {
 	return s
 }
 
+func NodePortService() *corev1.Service {
+	s, err := NewService(MustAssetReader(RouterServiceNodePortAsset))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
 func MetricsClusterRole() *rbacv1.ClusterRole {
 	cr, err := NewClusterRole(MustAssetReader(MetricsClusterRoleAsset))
 	if err != nil {
@@ -152,6 +147,52 @@ func MetricsRoleBinding() *rbacv1.RoleBinding {
 	return rb
 }
 
+func CanaryNamespace() *corev1.Namespace {
+	ns, err := NewNamespace(MustAssetReader(CanaryNamespaceAsset))
+	if err != nil {
+		panic(err)
+	}
+	return ns
+}
+
+func CanaryDaemonSet() *appsv1.DaemonSet {
+	ds, err := NewDaemonSet(MustAssetReader(CanaryDaemonSetAsset))
+	if err != nil {
+		panic(err)
+	}
+	return ds
+}
+
+func CanaryService() *corev1.Service {
+	s, err := NewService(MustAssetReader(CanaryServiceAsset))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func CanaryRoute() *routev1.Route {
+	r, err := NewRoute(MustAssetReader(CanaryRouteAsset))
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// CustomResourceDefinitions returns the CRDs that the operator owns and
+// installs itself.
+func CustomResourceDefinitions() []*apiextensionsv1beta1.CustomResourceDefinition {
+	crds := []*apiextensionsv1beta1.CustomResourceDefinition{}
+	for _, asset := range []string{DNSRecordCRDAsset} {
+		crd, err := NewCustomResourceDefinition(MustAssetReader(asset))
+		if err != nil {
+			panic(err)
+		}
+		crds = append(crds, crd)
+	}
+	return crds
+}
+
 func NewServiceAccount(manifest io.Reader) (*corev1.ServiceAccount, error) {
 	sa := corev1.ServiceAccount{}
 	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&sa); err != nil {
@@ -224,6 +265,15 @@ func NewDeployment(manifest io.Reader) (*appsv1.Deployment, error) {
 	return &o, nil
 }
 
+func NewDaemonSet(manifest io.Reader) (*appsv1.DaemonSet, error) {
+	o := appsv1.DaemonSet{}
+	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&o); err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
 func NewRoute(manifest io.Reader) (*routev1.Route, error) {
 	o := routev1.Route{}
 	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&o); err != nil {
@@ -232,3 +282,12 @@ func NewRoute(manifest io.Reader) (*routev1.Route, error) {
 
 	return &o, nil
 }
+
+func NewCustomResourceDefinition(manifest io.Reader) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	o := apiextensionsv1beta1.CustomResourceDefinition{}
+	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&o); err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
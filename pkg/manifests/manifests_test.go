@@ -3,36 +3,13 @@ package manifests
 import (
 	"testing"
 
-	operatorv1 "github.com/openshift/api/operator/v1"
-
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
 )
 
 func TestManifests(t *testing.T) {
-	var one int32 = 1
-	ci := &operatorv1.IngressController{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "default",
-		},
-		Spec: operatorv1.IngressControllerSpec{
-			NamespaceSelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"foo": "bar",
-				},
-			},
-			Replicas: &one,
-			RouteSelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"baz": "quux",
-				},
-			},
-		},
-	}
-
 	RouterServiceAccount()
 	RouterClusterRole()
 	RouterClusterRoleBinding()
-	RouterStatsSecret(ci)
 
 	MetricsClusterRole()
 	MetricsClusterRoleBinding()
@@ -43,4 +20,39 @@ func TestManifests(t *testing.T) {
 	RouterDeployment()
 	InternalIngressControllerService()
 	LoadBalancerService()
+	NodePortService()
+
+	CanaryNamespace()
+	CanaryDaemonSet()
+	CanaryService()
+	CanaryRoute()
+
+	CustomResourceDefinitions()
+}
+
+func TestDNSRecordCRD(t *testing.T) {
+	crd, err := NewCustomResourceDefinition(MustAssetReader(DNSRecordCRDAsset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crd.Spec.Group != ingressv1.GroupName {
+		t.Errorf("expected group %q, got %q", ingressv1.GroupName, crd.Spec.Group)
+	}
+	storage := ""
+	served := map[string]bool{}
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storage = v.Name
+		}
+		served[v.Name] = v.Served
+	}
+	if storage != ingressv1.GroupVersion.Version {
+		t.Errorf("expected storage version %q, got %q", ingressv1.GroupVersion.Version, storage)
+	}
+	if !served["v1alpha1"] {
+		t.Errorf("expected v1alpha1 to be served")
+	}
+	if crd.Spec.Subresources == nil || crd.Spec.Subresources.Status == nil {
+		t.Errorf("expected status subresource")
+	}
 }
@@ -1,15 +1,26 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
 
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"k8s.io/client-go/dynamic"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -33,6 +44,19 @@ func init() {
 	if err := configv1.Install(scheme); err != nil {
 		panic(err)
 	}
+	if err := apiextensionsv1beta1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	// Routes are served by the OpenShift API server, which is always
+	// present.
+	if err := routev1.Install(scheme); err != nil {
+		panic(err)
+	}
+	// The operator installs the CRDs for its own API group at startup,
+	// before any controller uses them.
+	if err := ingressv1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
 }
 
 func GetScheme() *runtime.Scheme {
@@ -54,3 +78,172 @@ func NewClient(kubeConfig *rest.Config) (client.Client, error) {
 	}
 	return kubeClient, nil
 }
+
+// Client is a kube client whose REST mapper can be refreshed to discover API
+// types that are registered after the client is created, such as
+// ServiceMonitor.  It is safe for concurrent use.
+type Client interface {
+	client.Client
+
+	// Refresh rebuilds the underlying client with the latest REST mapper.
+	Refresh() error
+
+	// PatchStatus applies the given patch to the status subresource of obj
+	// and updates obj with the result.  Unlike Status().Update, a patch
+	// does not require obj to have the latest resourceVersion, and it only
+	// overwrites the fields that the patch specifies, unless the patch is
+	// made with MergeFromWithOptimisticLock.
+	PatchStatus(ctx context.Context, obj runtime.Object, patch client.Patch) error
+}
+
+var _ Client = &refreshableClient{}
+
+type refreshableClient struct {
+	kubeConfig    *rest.Config
+	dynamicClient dynamic.Interface
+
+	lock   sync.RWMutex
+	client client.Client
+	mapper meta.RESTMapper
+}
+
+// NewRefreshableClient builds an operator-compatible kube client from the given
+// REST config that can later be refreshed.
+func NewRefreshableClient(kubeConfig *rest.Config) (Client, error) {
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+	c := &refreshableClient{kubeConfig: kubeConfig, dynamicClient: dynamicClient}
+	if err := c.Refresh(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *refreshableClient) Refresh() error {
+	mapper, err := apiutil.NewDiscoveryRESTMapper(c.kubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to discover api rest mapper: %v", err)
+	}
+	kubeClient, err := client.New(c.kubeConfig, client.Options{
+		Scheme: scheme,
+		Mapper: mapper,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create kube client: %v", err)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.client = kubeClient
+	c.mapper = mapper
+	return nil
+}
+
+func (c *refreshableClient) delegate() client.Client {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.client
+}
+
+func (c *refreshableClient) PatchStatus(ctx context.Context, obj runtime.Object, patch client.Patch) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return err
+	}
+	c.lock.RLock()
+	mapper := c.mapper
+	c.lock.RUnlock()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	var resource dynamic.ResourceInterface = c.dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		resource = c.dynamicClient.Resource(mapping.Resource).Namespace(accessor.GetNamespace())
+	}
+	result, err := resource.Patch(accessor.GetName(), patch.Type(), data, metav1.UpdateOptions{}, "status")
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(result.UnstructuredContent(), obj)
+}
+
+// mergeFromWithOptimisticLockPatch is a merge patch that also sets the
+// resourceVersion of the object that it is made from.
+type mergeFromWithOptimisticLockPatch struct {
+	from runtime.Object
+}
+
+// MergeFromWithOptimisticLock returns a merge patch from the given object that
+// the API server rejects with a conflict if the object has changed since it
+// was read.  A merge patch replaces lists, such as status conditions, in
+// whole, so a plain merge patch made from a stale object would overwrite the
+// changes that others have made to such a list in the meantime.
+func MergeFromWithOptimisticLock(obj runtime.Object) client.Patch {
+	return &mergeFromWithOptimisticLockPatch{from: obj}
+}
+
+// Type implements client.Patch.
+func (p *mergeFromWithOptimisticLockPatch) Type() types.PatchType {
+	return types.MergePatchType
+}
+
+// Data implements client.Patch.
+func (p *mergeFromWithOptimisticLockPatch) Data(obj runtime.Object) ([]byte, error) {
+	data, err := client.MergeFrom(p.from).Data(obj)
+	if err != nil {
+		return nil, err
+	}
+	accessor, err := meta.Accessor(p.from)
+	if err != nil {
+		return nil, err
+	}
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	metadata, ok := patch["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+	}
+	metadata["resourceVersion"] = accessor.GetResourceVersion()
+	patch["metadata"] = metadata
+	return json.Marshal(patch)
+}
+
+func (c *refreshableClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return c.delegate().Get(ctx, key, obj)
+}
+
+func (c *refreshableClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOptionFunc) error {
+	return c.delegate().List(ctx, list, opts...)
+}
+
+func (c *refreshableClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOptionFunc) error {
+	return c.delegate().Create(ctx, obj, opts...)
+}
+
+func (c *refreshableClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	return c.delegate().Delete(ctx, obj, opts...)
+}
+
+func (c *refreshableClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOptionFunc) error {
+	return c.delegate().Update(ctx, obj, opts...)
+}
+
+func (c *refreshableClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOptionFunc) error {
+	return c.delegate().Patch(ctx, obj, patch, opts...)
+}
+
+func (c *refreshableClient) Status() client.StatusWriter {
+	return c.delegate().Status()
+}
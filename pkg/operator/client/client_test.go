@@ -0,0 +1,42 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMergeFromWithOptimisticLock(t *testing.T) {
+	original := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", ResourceVersion: "42"},
+		Status: operatorv1.IngressControllerStatus{
+			Conditions: []operatorv1.OperatorCondition{{Type: "Available", Status: operatorv1.ConditionTrue}},
+		},
+	}
+	updated := original.DeepCopy()
+	updated.Status.Conditions[0].Status = operatorv1.ConditionFalse
+
+	data, err := MergeFromWithOptimisticLock(original).Data(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual map[string]interface{}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"metadata": map[string]interface{}{"resourceVersion": "42"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "False", "lastTransitionTime": nil},
+			},
+		},
+	}
+	if !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expected %v, got %v", expect, actual)
+	}
+}
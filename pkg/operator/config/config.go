@@ -1,14 +1,109 @@
 package config
 
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
 // Config is configuration for the operator and should include things like
-// operated images, scheduling configuration, etc.
+// operated images, scheduling configuration, etc.  It is loaded by Load from a
+// configuration file and the environment.
 type Config struct {
 	// OperatorReleaseVersion is the current version of operator.
 	OperatorReleaseVersion string
 
-	// Namespace is the operator namespace.
-	Namespace string
+	// Images are the images that the operator manages.
+	Images Images
+
+	// Namespaces are the namespaces that the operator uses.
+	Namespaces Namespaces
+
+	// Reconcile configures how ingresscontrollers are reconciled.
+	Reconcile Reconcile
+
+	// Logging configures the operator's logs.
+	Logging Logging
+
+	// FeatureGates are the experimental features that the cluster's
+	// feature set enables.  They are not loaded from the configuration
+	// file or the environment.
+	FeatureGates FeatureGates
+}
+
+// Images are the images that the operator manages.
+type Images struct {
+	// IngressController is the ingress controller image to manage.
+	IngressController string
+
+	// Canary is the image of the canary application that the operator
+	// deploys to check the ingress data path end to end.  If empty, the
+	// operator does not deploy the canary.
+	Canary string
+}
+
+// Namespaces are the namespaces that the operator uses.
+type Namespaces struct {
+	// Operator is the operator namespace.
+	Operator string
+
+	// Operand is the namespace in which the operator manages routers and
+	// their supporting resources.  If empty, "openshift-ingress" is used.
+	Operand string
+}
+
+// Reconcile configures how ingresscontrollers are reconciled.
+type Reconcile struct {
+	// ResyncPeriod is the period after which each ingresscontroller is
+	// reconciled again if nothing else triggers a reconciliation.  If
+	// zero, a default is used.  If negative, ingresscontrollers are not
+	// resynced periodically.  A change is applied without restarting.
+	ResyncPeriod time.Duration
+
+	// MaxConcurrentReconciles is the number of ingresscontrollers that may
+	// be reconciled concurrently.  If zero, a default is used.
+	MaxConcurrentReconciles int
+}
+
+// Logging configures the operator's logs.
+type Logging struct {
+	// Level is the log level.  If empty, Normal is used, so removing the
+	// level resets it.  A change is applied without restarting.
+	Level operatorv1.LogLevel
+}
+
+// RequiresRestart returns true if the given configurations differ in any field
+// that the operator applies only when it starts.
+func RequiresRestart(current, desired Config) bool {
+	current.Reconcile.ResyncPeriod, desired.Reconcile.ResyncPeriod = 0, 0
+	current.Logging, desired.Logging = Logging{}, Logging{}
+	current.FeatureGates, desired.FeatureGates = nil, nil
+	return !reflect.DeepEqual(current, desired)
+}
+
+// Reloadable holds the fields of Config that the operator applies without
+// restarting, other than the log level, which the log package holds.  It is
+// safe for concurrent use.
+type Reloadable struct {
+	resyncPeriod int64
+}
+
+// NewReloadable returns a Reloadable with the values of the given
+// configuration.
+func NewReloadable(config Config) *Reloadable {
+	r := &Reloadable{}
+	r.Update(config)
+	return r
+}
+
+// Update sets the values of the given configuration.
+func (r *Reloadable) Update(config Config) {
+	atomic.StoreInt64(&r.resyncPeriod, int64(config.Reconcile.ResyncPeriod))
+}
 
-	// IngressControllerImage is the ingress controller image to manage.
-	IngressControllerImage string
+// ResyncPeriod returns the current Reconcile.ResyncPeriod.
+func (r *Reloadable) ResyncPeriod() time.Duration {
+	return time.Duration(atomic.LoadInt64(&r.resyncPeriod))
 }
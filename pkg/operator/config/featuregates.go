@@ -0,0 +1,66 @@
+package config
+
+import (
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// GatewayAPIFeature enables the gateway controller, which manages a
+	// GatewayClass and reconciles Gateways of that class.
+	GatewayAPIFeature = "GatewayAPI"
+
+	// RouteExternalCertificateFeature allows routes to reference
+	// certificates in secrets that are managed outside of the route.  A
+	// router reads such a secret only if the route's owner grants the
+	// router's service account access to it.
+	RouteExternalCertificateFeature = "RouteExternalCertificate"
+)
+
+// featureSets maps each cluster feature set to the operator features that it
+// enables.  Features that are not listed for a feature set are disabled.
+var featureSets = map[configv1.FeatureSet][]string{
+	configv1.Default:              {},
+	configv1.TechPreviewNoUpgrade: {GatewayAPIFeature, RouteExternalCertificateFeature},
+}
+
+// FeatureGates is the sorted list of operator features that are enabled.
+type FeatureGates []string
+
+// FeatureGatesFor returns the operator features that the given cluster
+// featuregate config enables.  A nil featuregate enables the default feature
+// set.  An unknown feature set enables no features.
+func FeatureGatesFor(featureGate *configv1.FeatureGate) FeatureGates {
+	featureSet := configv1.Default
+	if featureGate != nil {
+		featureSet = featureGate.Spec.FeatureSet
+	}
+	enabled := FeatureGates{}
+	enabled = append(enabled, featureSets[featureSet]...)
+	sort.Strings(enabled)
+	return enabled
+}
+
+// Enabled returns true if the given feature is enabled.
+func (f FeatureGates) Enabled(feature string) bool {
+	for _, name := range f {
+		if name == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal returns true if the given feature gates enable the same features.
+func (f FeatureGates) Equal(other FeatureGates) bool {
+	if len(f) != len(other) {
+		return false
+	}
+	for i := range f {
+		if f[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
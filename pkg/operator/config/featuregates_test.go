@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestFeatureGatesFor(t *testing.T) {
+	testCases := []struct {
+		description string
+		featureGate *configv1.FeatureGate
+		expect      FeatureGates
+	}{
+		{"no featuregate", nil, FeatureGates{}},
+		{"default feature set", &configv1.FeatureGate{}, FeatureGates{}},
+		{"tech preview", &configv1.FeatureGate{Spec: configv1.FeatureGateSpec{FeatureSet: configv1.TechPreviewNoUpgrade}}, FeatureGates{GatewayAPIFeature, RouteExternalCertificateFeature}},
+		{"unknown feature set", &configv1.FeatureGate{Spec: configv1.FeatureGateSpec{FeatureSet: "Unknown"}}, FeatureGates{}},
+	}
+	for _, tc := range testCases {
+		actual := FeatureGatesFor(tc.featureGate)
+		if !actual.Equal(tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, actual)
+		}
+		for _, feature := range []string{GatewayAPIFeature, RouteExternalCertificateFeature} {
+			if actual.Enabled(feature) != tc.expect.Enabled(feature) {
+				t.Errorf("%q: expected %s enabled to be %t", tc.description, feature, tc.expect.Enabled(feature))
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	// FileEnvVar is the environment variable with the path of the
+	// operator's configuration file, which is typically mounted from a
+	// configmap.  The file is optional, and the other environment
+	// variables override its values.
+	FileEnvVar = "CONFIG_FILE"
+
+	// ReleaseVersionEnvVar is the environment variable with the current
+	// version of the operator.
+	ReleaseVersionEnvVar = "RELEASE_VERSION"
+	// OperatorNamespaceEnvVar is the environment variable with the
+	// operator namespace.
+	OperatorNamespaceEnvVar = "WATCH_NAMESPACE"
+	// OperandNamespaceEnvVar is the environment variable with the operand
+	// namespace.
+	OperandNamespaceEnvVar = "OPERAND_NAMESPACE"
+	// IngressControllerImageEnvVar is the environment variable with the
+	// ingress controller image.
+	IngressControllerImageEnvVar = "IMAGE"
+	// CanaryImageEnvVar is the environment variable with the canary image.
+	CanaryImageEnvVar = "CANARY_IMAGE"
+	// ResyncPeriodEnvVar is the environment variable with the resync
+	// period, as a duration such as "10m".
+	ResyncPeriodEnvVar = "RESYNC_PERIOD"
+	// MaxConcurrentReconcilesEnvVar is the environment variable with the
+	// number of ingresscontrollers that may be reconciled concurrently.
+	MaxConcurrentReconcilesEnvVar = "MAX_CONCURRENT_RECONCILES"
+)
+
+// file is the format of the configuration file, for example:
+//
+//	images:
+//	  ingressController: quay.io/openshift/origin-haproxy-router:latest
+//	  canary: quay.io/openshift/origin-cluster-ingress-operator:latest
+//	namespaces:
+//	  operand: openshift-ingress
+//	reconcile:
+//	  resyncPeriod: 10m
+//	  maxConcurrentReconciles: 4
+//	logging:
+//	  level: Debug
+//
+// The operator's deployment always sets the IMAGE, CANARY_IMAGE, and
+// WATCH_NAMESPACE environment variables, which override
+// images.ingressController, images.canary, and namespaces.operator, so those
+// keys only take effect when the operator is run
+// without the environment variables, for example outside of the cluster.
+type file struct {
+	Images struct {
+		IngressController string `json:"ingressController,omitempty"`
+		Canary            string `json:"canary,omitempty"`
+	} `json:"images,omitempty"`
+	Namespaces struct {
+		Operator string `json:"operator,omitempty"`
+		Operand  string `json:"operand,omitempty"`
+	} `json:"namespaces,omitempty"`
+	Reconcile struct {
+		ResyncPeriod            string `json:"resyncPeriod,omitempty"`
+		MaxConcurrentReconciles int    `json:"maxConcurrentReconciles,omitempty"`
+	} `json:"reconcile,omitempty"`
+	Logging struct {
+		Level operatorv1.LogLevel `json:"level,omitempty"`
+	} `json:"logging,omitempty"`
+}
+
+// Load loads the operator's configuration from the configuration file named by
+// FileEnvVar, if it exists, and then from the environment variables, which
+// override the file, using the given function to look up environment
+// variables.  The operator namespace and the ingress controller image are
+// required.
+func Load(getenv func(string) string) (Config, error) {
+	config := Config{}
+	if path := getenv(FileEnvVar); len(path) != 0 {
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return Config{}, fmt.Errorf("failed to read configuration file %s: %v", path, err)
+		default:
+			if config, err = parseFile(data); err != nil {
+				return Config{}, fmt.Errorf("invalid configuration file %s: %v", path, err)
+			}
+		}
+	}
+	if err := applyEnv(&config, getenv); err != nil {
+		return Config{}, err
+	}
+	if err := validate(config); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// parseFile returns the configuration in the given configuration file contents.
+func parseFile(data []byte) (Config, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return Config{}, err
+	}
+	f := file{}
+	if len(bytes.TrimSpace(data)) != 0 && string(bytes.TrimSpace(data)) != "null" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&f); err != nil {
+			return Config{}, err
+		}
+	}
+	config := Config{
+		Images: Images{
+			IngressController: f.Images.IngressController,
+			Canary:            f.Images.Canary,
+		},
+		Namespaces: Namespaces{
+			Operator: f.Namespaces.Operator,
+			Operand:  f.Namespaces.Operand,
+		},
+		Reconcile: Reconcile{
+			MaxConcurrentReconciles: f.Reconcile.MaxConcurrentReconciles,
+		},
+		Logging: Logging{
+			Level: f.Logging.Level,
+		},
+	}
+	if len(f.Reconcile.ResyncPeriod) != 0 {
+		if config.Reconcile.ResyncPeriod, err = time.ParseDuration(f.Reconcile.ResyncPeriod); err != nil {
+			return Config{}, fmt.Errorf("invalid reconcile.resyncPeriod: %v", err)
+		}
+	}
+	return config, nil
+}
+
+// applyEnv overrides the given configuration with the environment variables
+// that are set, using the given function to look up environment variables.
+func applyEnv(config *Config, getenv func(string) string) error {
+	for envVar, field := range map[string]*string{
+		ReleaseVersionEnvVar:         &config.OperatorReleaseVersion,
+		OperatorNamespaceEnvVar:      &config.Namespaces.Operator,
+		OperandNamespaceEnvVar:       &config.Namespaces.Operand,
+		IngressControllerImageEnvVar: &config.Images.IngressController,
+		CanaryImageEnvVar:            &config.Images.Canary,
+	} {
+		if value := getenv(envVar); len(value) != 0 {
+			*field = value
+		}
+	}
+	if value := getenv(ResyncPeriodEnvVar); len(value) != 0 {
+		period, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s environment variable: %v", ResyncPeriodEnvVar, err)
+		}
+		config.Reconcile.ResyncPeriod = period
+	}
+	if value := getenv(MaxConcurrentReconcilesEnvVar); len(value) != 0 {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s environment variable: %v", MaxConcurrentReconcilesEnvVar, err)
+		}
+		config.Reconcile.MaxConcurrentReconciles = n
+	}
+	if value := getenv(logf.LogLevelEnvVar); len(value) != 0 {
+		config.Logging.Level = operatorv1.LogLevel(value)
+	}
+	return nil
+}
+
+// validate returns an error if the given configuration is incomplete or has an
+// invalid value.
+func validate(config Config) error {
+	if len(config.Namespaces.Operator) == 0 {
+		return fmt.Errorf("the operator namespace must be set in the configuration file or the %s environment variable", OperatorNamespaceEnvVar)
+	}
+	if len(config.Images.IngressController) == 0 {
+		return fmt.Errorf("the ingress controller image must be set in the configuration file or the %s environment variable", IngressControllerImageEnvVar)
+	}
+	if config.Reconcile.MaxConcurrentReconciles < 0 {
+		return fmt.Errorf("invalid max concurrent reconciles %d: must not be negative", config.Reconcile.MaxConcurrentReconciles)
+	}
+	switch config.Logging.Level {
+	case "", operatorv1.Normal, operatorv1.Debug, operatorv1.Trace, operatorv1.TraceAll:
+	default:
+		return fmt.Errorf("invalid log level %q: must be %q, %q, %q, or %q", config.Logging.Level, operatorv1.Normal, operatorv1.Debug, operatorv1.Trace, operatorv1.TraceAll)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	requiredEnv := map[string]string{
+		OperatorNamespaceEnvVar:      "openshift-ingress-operator",
+		IngressControllerImageEnvVar: "router:env",
+	}
+	testCases := []struct {
+		description string
+		file        string
+		noFile      bool
+		env         map[string]string
+		expect      Config
+		expectErr   bool
+	}{
+		{
+			description: "environment only",
+			noFile:      true,
+			env: map[string]string{
+				ReleaseVersionEnvVar:          "4.6.0",
+				OperatorNamespaceEnvVar:       "openshift-ingress-operator",
+				OperandNamespaceEnvVar:        "openshift-ingress",
+				IngressControllerImageEnvVar:  "router:env",
+				CanaryImageEnvVar:             "canary:env",
+				ResyncPeriodEnvVar:            "5m",
+				MaxConcurrentReconcilesEnvVar: "2",
+				logf.LogLevelEnvVar:           "Debug",
+			},
+			expect: Config{
+				OperatorReleaseVersion: "4.6.0",
+				Images:                 Images{IngressController: "router:env", Canary: "canary:env"},
+				Namespaces:             Namespaces{Operator: "openshift-ingress-operator", Operand: "openshift-ingress"},
+				Reconcile:              Reconcile{ResyncPeriod: 5 * time.Minute, MaxConcurrentReconciles: 2},
+				Logging:                Logging{Level: operatorv1.Debug},
+			},
+		},
+		{
+			description: "file only",
+			file: `
+images:
+  ingressController: router:file
+  canary: canary:file
+namespaces:
+  operator: operator-file
+  operand: operand-file
+reconcile:
+  resyncPeriod: 1h
+  maxConcurrentReconciles: 8
+logging:
+  level: Trace
+`,
+			expect: Config{
+				Images:     Images{IngressController: "router:file", Canary: "canary:file"},
+				Namespaces: Namespaces{Operator: "operator-file", Operand: "operand-file"},
+				Reconcile:  Reconcile{ResyncPeriod: time.Hour, MaxConcurrentReconciles: 8},
+				Logging:    Logging{Level: operatorv1.Trace},
+			},
+		},
+		{
+			description: "environment overrides file",
+			file: `
+images:
+  ingressController: router:file
+reconcile:
+  resyncPeriod: 1h
+`,
+			env: requiredEnv,
+			expect: Config{
+				Images:     Images{IngressController: "router:env"},
+				Namespaces: Namespaces{Operator: "openshift-ingress-operator"},
+				Reconcile:  Reconcile{ResyncPeriod: time.Hour},
+			},
+		},
+		{
+			description: "empty file",
+			file:        "",
+			env:         requiredEnv,
+			expect: Config{
+				Images:     Images{IngressController: "router:env"},
+				Namespaces: Namespaces{Operator: "openshift-ingress-operator"},
+			},
+		},
+		{
+			description: "missing operator namespace",
+			noFile:      true,
+			env:         map[string]string{IngressControllerImageEnvVar: "router:env"},
+			expectErr:   true,
+		},
+		{
+			description: "missing ingress controller image",
+			noFile:      true,
+			env:         map[string]string{OperatorNamespaceEnvVar: "openshift-ingress-operator"},
+			expectErr:   true,
+		},
+		{
+			description: "unknown field in file",
+			file:        "reconcile:\n  resyncPerod: 1h\n",
+			env:         requiredEnv,
+			expectErr:   true,
+		},
+		{
+			description: "invalid resync period in file",
+			file:        "reconcile:\n  resyncPeriod: often\n",
+			env:         requiredEnv,
+			expectErr:   true,
+		},
+		{
+			description: "invalid max concurrent reconciles in environment",
+			noFile:      true,
+			env: map[string]string{
+				OperatorNamespaceEnvVar:       "openshift-ingress-operator",
+				IngressControllerImageEnvVar:  "router:env",
+				MaxConcurrentReconcilesEnvVar: "many",
+			},
+			expectErr: true,
+		},
+		{
+			description: "invalid log level",
+			file:        "logging:\n  level: Verbose\n",
+			env:         requiredEnv,
+			expectErr:   true,
+		},
+	}
+	for i, tc := range testCases {
+		path := filepath.Join(dir, "missing.yaml")
+		if !tc.noFile {
+			path = filepath.Join(dir, "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(tc.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		getenv := func(name string) string {
+			if name == FileEnvVar {
+				return path
+			}
+			return tc.env[name]
+		}
+		actual, err := Load(getenv)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%d %q: expected error %t, got %v", i, tc.description, tc.expectErr, err)
+			continue
+		}
+		if !tc.expectErr && !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%d %q: expected %+v, got %+v", i, tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestRequiresRestart(t *testing.T) {
+	current := Config{
+		Images:       Images{IngressController: "router:1"},
+		Namespaces:   Namespaces{Operator: "openshift-ingress-operator"},
+		Reconcile:    Reconcile{ResyncPeriod: time.Hour, MaxConcurrentReconciles: 4},
+		Logging:      Logging{Level: operatorv1.Normal},
+		FeatureGates: FeatureGates{GatewayAPIFeature},
+	}
+	testCases := []struct {
+		description string
+		mutate      func(*Config)
+		expect      bool
+	}{
+		{"nothing changes", func(_ *Config) {}, false},
+		{"the resync period changes", func(c *Config) { c.Reconcile.ResyncPeriod = time.Minute }, false},
+		{"the log level changes", func(c *Config) { c.Logging.Level = operatorv1.Debug }, false},
+		{"the router image changes", func(c *Config) { c.Images.IngressController = "router:2" }, true},
+		{"the operand namespace changes", func(c *Config) { c.Namespaces.Operand = "routers" }, true},
+		{"max concurrent reconciles changes", func(c *Config) { c.Reconcile.MaxConcurrentReconciles = 8 }, true},
+	}
+	for _, tc := range testCases {
+		desired := current
+		tc.mutate(&desired)
+		if actual := RequiresRestart(current, desired); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestReloadable(t *testing.T) {
+	r := NewReloadable(Config{Reconcile: Reconcile{ResyncPeriod: time.Hour}})
+	if period := r.ResyncPeriod(); period != time.Hour {
+		t.Errorf("expected resync period %v, got %v", time.Hour, period)
+	}
+	r.Update(Config{Reconcile: Reconcile{ResyncPeriod: time.Minute}})
+	if period := r.ResyncPeriod(); period != time.Minute {
+		t.Errorf("expected resync period %v, got %v", time.Minute, period)
+	}
+}
@@ -0,0 +1,78 @@
+package operator
+
+import (
+	"time"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// configPollInterval is how often the operator reloads its configuration.  The
+// kubelet updates a mounted configmap within about a minute of a change, so
+// polling is no slower than a file watch in practice.
+const configPollInterval = 30 * time.Second
+
+// watchConfig reloads the operator's configuration from the configuration file
+// and the environment, using the given function to look up environment
+// variables, until stop is closed.  A change to the log level or the resync
+// period is applied right away.  Any other change requires the operator to
+// restart, as a change to the feature gates does.
+func (o *Operator) watchConfig(getenv func(string) string, stop <-chan struct{}) {
+	current := o.config
+	wait.Until(func() {
+		desired, err := operatorconfig.Load(getenv)
+		if err != nil {
+			log.Error(err, "failed to reload configuration; keeping the current configuration")
+			return
+		}
+		SetConfigDefaults(&desired)
+		desired.FeatureGates = current.FeatureGates
+		if applyConfig(current, desired, o.reloadable, logf.SetLevel) {
+			log.Info("configuration changed; restarting to apply it")
+			o.requestRestart()
+			return
+		}
+		current = desired
+	}, configPollInterval, stop)
+}
+
+// SetConfigDefaults sets the defaults for the fields of the given operator
+// configuration that are not set.
+func SetConfigDefaults(config *operatorconfig.Config) {
+	if len(config.Namespaces.Operand) == 0 {
+		config.Namespaces.Operand = operatorcontroller.DefaultOperandNamespace
+	}
+	if len(config.OperatorReleaseVersion) == 0 {
+		config.OperatorReleaseVersion = operatorcontroller.UnknownVersionValue
+	}
+	if len(config.Logging.Level) == 0 {
+		config.Logging.Level = operatorv1.Normal
+	}
+}
+
+// applyConfig applies the given desired configuration in place of the given
+// current configuration, by updating the given reloadable configuration and
+// setting the log level with the given function.  Both configurations must have
+// their defaults set, so that removing the log level from the configuration
+// file resets the level to the default.  It returns true, and applies nothing,
+// if the change requires a restart.
+func applyConfig(current, desired operatorconfig.Config, reloadable *operatorconfig.Reloadable, setLevel func(operatorv1.LogLevel) error) bool {
+	if operatorconfig.RequiresRestart(current, desired) {
+		return true
+	}
+	if desired.Reconcile.ResyncPeriod != current.Reconcile.ResyncPeriod {
+		log.Info("changed resync period", "current", current.Reconcile.ResyncPeriod.String(), "desired", desired.Reconcile.ResyncPeriod.String())
+		reloadable.Update(desired)
+	}
+	if desired.Logging.Level != current.Logging.Level {
+		if err := setLevel(desired.Logging.Level); err != nil {
+			log.Error(err, "failed to set log level")
+		}
+	}
+	return false
+}
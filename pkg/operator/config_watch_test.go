@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestApplyConfig(t *testing.T) {
+	current := operatorconfig.Config{
+		Images:     operatorconfig.Images{IngressController: "router:1"},
+		Namespaces: operatorconfig.Namespaces{Operator: "openshift-ingress-operator"},
+		Reconcile:  operatorconfig.Reconcile{ResyncPeriod: time.Hour},
+		Logging:    operatorconfig.Logging{Level: operatorv1.Debug},
+	}
+	SetConfigDefaults(&current)
+	testCases := []struct {
+		description  string
+		mutate       func(*operatorconfig.Config)
+		expectResync time.Duration
+		expectLevel  operatorv1.LogLevel
+		expect       bool
+	}{
+		{
+			description:  "nothing changes",
+			mutate:       func(_ *operatorconfig.Config) {},
+			expectResync: time.Hour,
+		},
+		{
+			description: "the resync period and log level change",
+			mutate: func(c *operatorconfig.Config) {
+				c.Reconcile.ResyncPeriod = time.Minute
+				c.Logging.Level = operatorv1.Trace
+			},
+			expectResync: time.Minute,
+			expectLevel:  operatorv1.Trace,
+		},
+		{
+			description: "the log level is removed",
+			mutate: func(c *operatorconfig.Config) {
+				c.Logging.Level = ""
+			},
+			expectResync: time.Hour,
+			expectLevel:  operatorv1.Normal,
+		},
+		{
+			description: "the router image changes along with the resync period",
+			mutate: func(c *operatorconfig.Config) {
+				c.Images.IngressController = "router:2"
+				c.Reconcile.ResyncPeriod = time.Minute
+			},
+			expectResync: time.Hour,
+			expect:       true,
+		},
+	}
+	for _, tc := range testCases {
+		desired := current
+		tc.mutate(&desired)
+		SetConfigDefaults(&desired)
+		reloadable := operatorconfig.NewReloadable(current)
+		var level operatorv1.LogLevel
+		setLevel := func(l operatorv1.LogLevel) error {
+			level = l
+			return nil
+		}
+		if actual := applyConfig(current, desired, reloadable, setLevel); actual != tc.expect {
+			t.Errorf("%q: expected restart to be %t, got %t", tc.description, tc.expect, actual)
+		}
+		if period := reloadable.ResyncPeriod(); period != tc.expectResync {
+			t.Errorf("%q: expected resync period %v, got %v", tc.description, tc.expectResync, period)
+		}
+		if level != tc.expectLevel {
+			t.Errorf("%q: expected log level %q to be set, got %q", tc.description, tc.expectLevel, level)
+		}
+	}
+}
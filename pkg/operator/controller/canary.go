@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// CanaryPort is the port on which the canary application serves.
+	CanaryPort = 8080
+
+	// CanaryPortHeader is the response header in which the canary
+	// application echoes the port that received the request.
+	CanaryPortHeader = "x-request-port"
+
+	// CanaryResponse is the body of the canary application's responses.
+	CanaryResponse = "Healthcheck requested"
+)
+
+// canaryRolledOut returns true if the canary daemonset runs the configured
+// canary image on every node that it is scheduled to.  It returns false if the
+// operator does not deploy the canary.
+func (r *reconciler) canaryRolledOut() (bool, error) {
+	if len(r.CanaryImage) == 0 {
+		return false, nil
+	}
+	daemonset := &appsv1.DaemonSet{}
+	if err := r.client.Get(context.TODO(), CanaryDaemonSetName(), daemonset); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get canary daemonset: %v", err)
+	}
+	return isCanaryRolledOut(daemonset, r.CanaryImage), nil
+}
+
+// isCanaryRolledOut returns true if the given canary daemonset has rolled out
+// the given image: the daemonset controller has observed the current spec,
+// the spec has the image, and every scheduled pod is updated and available.
+func isCanaryRolledOut(daemonset *appsv1.DaemonSet, image string) bool {
+	if daemonset.Status.ObservedGeneration < daemonset.Generation {
+		return false
+	}
+	containers := daemonset.Spec.Template.Spec.Containers
+	if len(containers) == 0 || containers[0].Image != image {
+		return false
+	}
+	desired := daemonset.Status.DesiredNumberScheduled
+	return daemonset.Status.UpdatedNumberScheduled == desired && daemonset.Status.NumberAvailable == desired
+}
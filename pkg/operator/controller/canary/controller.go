@@ -0,0 +1,277 @@
+// The canary controller is responsible for the canary application: a
+// daemonset that serves a fixed response, a service, and a route that the
+// default ingresscontroller serves.  Requests through the route exercise the
+// ingress data path end to end.  The canary runs the configured canary image,
+// and a change to the image rolls out to the daemonset's pods.
+//
+// An ingresscontroller other than the default one may opt in to canary checks
+// with the canary-check annotation.  The controller then creates a canary
+// route that only the ingresscontroller's routers serve, periodically requests
+// it through the ingresscontroller's domain, exports the result as metrics,
+// and records it in CanaryResults, from which the operator controller
+// computes the ingresscontroller's CanaryChecksSucceeding condition.
+package canary
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "canary-controller"
+)
+
+var log = logf.Logger.WithName(controllerName)
+
+type reconciler struct {
+	// cache reads ingresscontrollers from the manager's cache.
+	cache             client.Reader
+	client            client.Client
+	canaryImage       string
+	canaryResults     *controller.CanaryResults
+	operatorNamespace string
+	httpClient        *http.Client
+
+	// checked is the names of the ingresscontrollers whose canary check
+	// results are recorded.  The controller reconciles one request at a
+	// time, so it needs no lock.
+	checked sets.String
+}
+
+// New creates the canary controller.  The controller watches the canary's
+// resources through the given cache of the canary namespace and records the
+// results of the ingresscontrollers' canary checks in the given canary
+// results.
+func New(mgr manager.Manager, canaryCache cache.Cache, cl client.Client, canaryImage string, canaryResults *controller.CanaryResults, operatorNamespace string) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		cache:             mgr.GetClient(),
+		client:            cl,
+		canaryImage:       canaryImage,
+		canaryResults:     canaryResults,
+		operatorNamespace: operatorNamespace,
+		httpClient:        newCanaryHTTPClient(),
+		checked:           sets.NewString(),
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+
+	// The controller reconciles all of the canary's resources for one
+	// request, so queue it once when the controller starts and whenever
+	// one of the resources or an ingresscontroller, which may opt in to
+	// canary checks, changes.
+	canary := controller.CanaryDaemonSetName()
+	initial := make(chan event.GenericEvent, 1)
+	initial <- event.GenericEvent{Meta: &metav1.ObjectMeta{Namespace: canary.Namespace, Name: canary.Name}}
+	if err := c.Watch(&source.Channel{Source: initial}, &handler.EnqueueRequestForObject{}); err != nil {
+		return nil, err
+	}
+	toCanary := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: canary}}
+		}),
+	}
+	for _, o := range []runtime.Object{
+		&appsv1.DaemonSet{},
+		&corev1.Service{},
+		&routev1.Route{},
+	} {
+		informer, err := canaryCache.GetInformer(o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get informer for %T: %v", o, err)
+		}
+		if err := c.Watch(&source.Informer{Informer: informer}, toCanary); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, toCanary); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reconcile ensures that the canary's namespace, daemonset, service, and
+// route exist and match their desired state, and checks the canary routes of
+// the ingresscontrollers that opt in to canary checks.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	if err := r.ensureCanaryNamespace(); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.ensureCanaryDaemonSet(); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.ensureCanaryService(); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.ensureCanaryRoute(); err != nil {
+		return reconcile.Result{}, err
+	}
+	checked, err := r.ensureShardCanaries()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if checked {
+		return reconcile.Result{RequeueAfter: canaryCheckInterval}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// ensureCanaryNamespace creates the canary namespace if it does not exist.
+func (r *reconciler) ensureCanaryNamespace() error {
+	desired := manifests.CanaryNamespace()
+	current := &corev1.Namespace{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get canary namespace %s: %v", desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create canary namespace %s: %v", desired.Name, err)
+		}
+		log.Info("created canary namespace", "name", desired.Name)
+	}
+	return nil
+}
+
+// desiredCanaryDaemonSet returns the canary daemonset with the given image.
+func desiredCanaryDaemonSet(image string) *appsv1.DaemonSet {
+	daemonset := manifests.CanaryDaemonSet()
+	name := controller.CanaryDaemonSetName()
+	daemonset.Namespace, daemonset.Name = name.Namespace, name.Name
+	daemonset.Spec.Template.Spec.Containers[0].Image = image
+	return daemonset
+}
+
+// ensureCanaryDaemonSet creates or updates the canary daemonset.  Updating the
+// image rolls the new image out to the daemonset's pods.
+func (r *reconciler) ensureCanaryDaemonSet() error {
+	desired := desiredCanaryDaemonSet(r.canaryImage)
+	current := &appsv1.DaemonSet{}
+	if err := r.client.Get(context.TODO(), controller.CanaryDaemonSetName(), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get canary daemonset: %v", err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create canary daemonset: %v", err)
+		}
+		log.Info("created canary daemonset", "image", r.canaryImage)
+		return nil
+	}
+	changed, updated := canaryDaemonSetChanged(current, desired)
+	if !changed {
+		return nil
+	}
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update canary daemonset: %v", err)
+	}
+	log.Info("updated canary daemonset", "image", r.canaryImage)
+	return nil
+}
+
+// canaryDaemonSetChanged returns true and an updated copy of the current
+// canary daemonset if its update strategy or the parts of its pod template that
+// the operator manages differ from the desired daemonset.  Fields that the API
+// server defaults are ignored.
+func canaryDaemonSetChanged(current, desired *appsv1.DaemonSet) (bool, *appsv1.DaemonSet) {
+	currentContainers := current.Spec.Template.Spec.Containers
+	desiredContainer := desired.Spec.Template.Spec.Containers[0]
+	if len(currentContainers) == 1 &&
+		currentContainers[0].Image == desiredContainer.Image &&
+		reflect.DeepEqual(currentContainers[0].Command, desiredContainer.Command) &&
+		reflect.DeepEqual(currentContainers[0].Ports, desiredContainer.Ports) &&
+		reflect.DeepEqual(currentContainers[0].Resources, desiredContainer.Resources) &&
+		reflect.DeepEqual(current.Spec.Template.Labels, desired.Spec.Template.Labels) &&
+		reflect.DeepEqual(current.Spec.Template.Spec.NodeSelector, desired.Spec.Template.Spec.NodeSelector) &&
+		reflect.DeepEqual(current.Spec.Template.Spec.Tolerations, desired.Spec.Template.Spec.Tolerations) &&
+		reflect.DeepEqual(current.Spec.UpdateStrategy.RollingUpdate, desired.Spec.UpdateStrategy.RollingUpdate) {
+		return false, nil
+	}
+	updated := current.DeepCopy()
+	updated.Spec.Template = desired.Spec.Template
+	updated.Spec.UpdateStrategy = desired.Spec.UpdateStrategy
+	return true, updated
+}
+
+// ensureCanaryService creates or updates the canary service.
+func (r *reconciler) ensureCanaryService() error {
+	desired := manifests.CanaryService()
+	current := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), controller.CanaryServiceName(), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get canary service: %v", err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create canary service: %v", err)
+		}
+		log.Info("created canary service")
+		return nil
+	}
+	if reflect.DeepEqual(current.Spec.Selector, desired.Spec.Selector) && reflect.DeepEqual(current.Spec.Ports, desired.Spec.Ports) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Spec.Selector = desired.Spec.Selector
+	updated.Spec.Ports = desired.Spec.Ports
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update canary service: %v", err)
+	}
+	log.Info("updated canary service")
+	return nil
+}
+
+// ensureCanaryRoute creates the canary route or updates its backend.  The
+// route's host is generated by the router that admits it, so it is left as is.
+func (r *reconciler) ensureCanaryRoute() error {
+	desired := manifests.CanaryRoute()
+	current := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), controller.CanaryRouteName(), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get canary route: %v", err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create canary route: %v", err)
+		}
+		log.Info("created canary route")
+		return nil
+	}
+	if reflect.DeepEqual(current.Spec.To, desired.Spec.To) && reflect.DeepEqual(current.Spec.Port, desired.Spec.Port) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Spec.To = desired.Spec.To
+	updated.Spec.Port = desired.Spec.Port
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update canary route: %v", err)
+	}
+	log.Info("updated canary route")
+	return nil
+}
@@ -0,0 +1,59 @@
+package canary
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCanaryDaemonSetChanged(t *testing.T) {
+	testCases := []struct {
+		description string
+		mutate      func(*corev1.PodSpec)
+		expect      bool
+	}{
+		{
+			description: "no change",
+			mutate:      func(*corev1.PodSpec) {},
+			expect:      false,
+		},
+		{
+			description: "new image",
+			mutate:      func(spec *corev1.PodSpec) { spec.Containers[0].Image = "canary:v1" },
+			expect:      true,
+		},
+		{
+			description: "different command",
+			mutate:      func(spec *corev1.PodSpec) { spec.Containers[0].Command = []string{"sleep"} },
+			expect:      true,
+		},
+		{
+			description: "removed toleration",
+			mutate:      func(spec *corev1.PodSpec) { spec.Tolerations = nil },
+			expect:      true,
+		},
+		{
+			description: "defaulted field",
+			mutate:      func(spec *corev1.PodSpec) { spec.DNSPolicy = corev1.DNSClusterFirst },
+			expect:      false,
+		},
+	}
+	for _, tc := range testCases {
+		desired := desiredCanaryDaemonSet("canary:v2")
+		current := desiredCanaryDaemonSet("canary:v2")
+		tc.mutate(&current.Spec.Template.Spec)
+		changed, updated := canaryDaemonSetChanged(current, desired)
+		if changed != tc.expect {
+			t.Errorf("%q: expected changed=%t, got %t", tc.description, tc.expect, changed)
+			continue
+		}
+		if changed {
+			if updated.Spec.Template.Spec.Containers[0].Image != "canary:v2" {
+				t.Errorf("%q: expected updated daemonset to have image canary:v2", tc.description)
+			}
+			if again, _ := canaryDaemonSetChanged(updated, desired); again {
+				t.Errorf("%q: expected updated daemonset to match the desired daemonset", tc.description)
+			}
+		}
+	}
+}
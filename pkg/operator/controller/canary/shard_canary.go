@@ -0,0 +1,276 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// canaryCheckInterval is how often the canary routes of the
+	// ingresscontrollers that opt in to canary checks are checked.
+	canaryCheckInterval = 1 * time.Minute
+
+	// canaryCheckTimeout is the timeout of a canary check's request.
+	canaryCheckTimeout = 10 * time.Second
+)
+
+var (
+	canaryCheckSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_canary_check_success",
+		Help: "Whether the most recent canary check of an ingresscontroller succeeded (1) or failed (0).",
+	}, []string{"name"})
+	canaryCheckDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_canary_check_duration_seconds",
+		Help: "Duration in seconds of the most recent request of an ingresscontroller's canary check.",
+	}, []string{"name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(canaryCheckSuccess, canaryCheckDuration)
+}
+
+// ensureShardCanaries ensures that each ingresscontroller that opts in to
+// canary checks has a canary route, checks the route through the
+// ingresscontroller's domain, and records the result.  Canary routes and
+// results of ingresscontrollers that no longer opt in are removed.  It returns
+// true if any ingresscontroller was checked, in which case the checks should be
+// repeated after canaryCheckInterval.
+func (r *reconciler) ensureShardCanaries() (bool, error) {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.operatorNamespace)); err != nil {
+		return false, fmt.Errorf("failed to list ingresscontrollers: %v", err)
+	}
+	namespace := &corev1.Namespace{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: controller.CanaryNamespace}, namespace); err != nil {
+		return false, fmt.Errorf("failed to get canary namespace %s: %v", controller.CanaryNamespace, err)
+	}
+
+	var errs []error
+	enabled := sets.NewString()
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		if !controller.CanaryCheckEnabled(ic) || ic.DeletionTimestamp != nil {
+			continue
+		}
+		enabled.Insert(ic.Name)
+		name := types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}
+		if len(ic.Status.Domain) == 0 {
+			r.recordResult(name, controller.CanaryResult{Message: "the ingresscontroller has no domain yet"})
+			continue
+		}
+		route, err := r.ensureShardCanaryRoute(ic)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.recordResult(name, r.checkShardCanary(ic, route, namespace.Labels))
+	}
+
+	if err := r.deleteStaleShardCanaryRoutes(enabled); err != nil {
+		errs = append(errs, err)
+	}
+	for _, name := range r.checked.List() {
+		if enabled.Has(name) {
+			continue
+		}
+		r.canaryResults.Delete(types.NamespacedName{Namespace: r.operatorNamespace, Name: name})
+		canaryCheckSuccess.DeleteLabelValues(name)
+		canaryCheckDuration.DeleteLabelValues(name)
+		r.checked.Delete(name)
+	}
+	return enabled.Len() != 0, utilerrors.NewAggregate(errs)
+}
+
+// recordResult records the given canary check result of the named
+// ingresscontroller in the canary results and the success metric.
+func (r *reconciler) recordResult(name types.NamespacedName, result controller.CanaryResult) {
+	if !result.Succeeded {
+		log.Info("canary check failed", "ingresscontroller", name.Name, "message", result.Message)
+	}
+	r.checked.Insert(name.Name)
+	r.canaryResults.Set(name, result)
+	success := 0.0
+	if result.Succeeded {
+		success = 1
+	}
+	canaryCheckSuccess.WithLabelValues(name.Name).Set(success)
+}
+
+// desiredShardCanaryRoute returns the canary route for the given
+// ingresscontroller.  The route has the labels that the ingresscontroller's
+// route selector requires and a host in the ingresscontroller's domain, so
+// that only the ingresscontroller's routers serve it.
+func desiredShardCanaryRoute(ic *operatorv1.IngressController) *routev1.Route {
+	route := manifests.CanaryRoute()
+	name := controller.ShardCanaryRouteName(ic)
+	route.Namespace, route.Name = name.Namespace, name.Name
+	route.Labels = map[string]string{}
+	if ic.Spec.RouteSelector != nil {
+		for k, v := range ic.Spec.RouteSelector.MatchLabels {
+			route.Labels[k] = v
+		}
+	}
+	route.Labels[manifests.OwningIngressControllerLabel] = ic.Name
+	route.Spec.Host = fmt.Sprintf("%s-%s.%s", name.Name, name.Namespace, ic.Status.Domain)
+	return route
+}
+
+// ensureShardCanaryRoute creates or updates the canary route of the given
+// ingresscontroller and returns the current route.
+func (r *reconciler) ensureShardCanaryRoute(ic *operatorv1.IngressController) (*routev1.Route, error) {
+	desired := desiredShardCanaryRoute(ic)
+	current := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), controller.ShardCanaryRouteName(ic), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get canary route for ingresscontroller %s: %v", ic.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create canary route for ingresscontroller %s: %v", ic.Name, err)
+		}
+		log.Info("created canary route", "ingresscontroller", ic.Name, "host", desired.Spec.Host)
+		return desired, nil
+	}
+	if reflect.DeepEqual(current.Labels, desired.Labels) &&
+		current.Spec.Host == desired.Spec.Host &&
+		reflect.DeepEqual(current.Spec.To, desired.Spec.To) &&
+		reflect.DeepEqual(current.Spec.Port, desired.Spec.Port) {
+		return current, nil
+	}
+	updated := current.DeepCopy()
+	updated.Labels = desired.Labels
+	updated.Spec.Host = desired.Spec.Host
+	updated.Spec.To = desired.Spec.To
+	updated.Spec.Port = desired.Spec.Port
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return nil, fmt.Errorf("failed to update canary route for ingresscontroller %s: %v", ic.Name, err)
+	}
+	log.Info("updated canary route", "ingresscontroller", ic.Name, "host", desired.Spec.Host)
+	return updated, nil
+}
+
+// deleteStaleShardCanaryRoutes deletes the canary routes of
+// ingresscontrollers other than the given ones.
+func (r *reconciler) deleteStaleShardCanaryRoutes(enabled sets.String) error {
+	routes := &routev1.RouteList{}
+	if err := r.client.List(context.TODO(), routes, client.InNamespace(controller.CanaryNamespace)); err != nil {
+		return fmt.Errorf("failed to list canary routes: %v", err)
+	}
+	var errs []error
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		owner, ok := route.Labels[manifests.OwningIngressControllerLabel]
+		if !ok || enabled.Has(owner) {
+			continue
+		}
+		if err := r.client.Delete(context.TODO(), route); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete canary route %s: %v", route.Name, err))
+			continue
+		}
+		log.Info("deleted canary route", "ingresscontroller", owner)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// checkShardCanary checks the given canary route of the given
+// ingresscontroller by requesting it through the host with which the
+// ingresscontroller's routers admitted it.  The route must be selected by the
+// ingresscontroller's selectors given the labels of the canary namespace.
+func (r *reconciler) checkShardCanary(ic *operatorv1.IngressController, route *routev1.Route, namespaceLabels labels.Set) controller.CanaryResult {
+	selected, err := controller.RouteSelected(ic, route.Labels, namespaceLabels)
+	if err != nil {
+		return controller.CanaryResult{Message: err.Error()}
+	}
+	if !selected {
+		return controller.CanaryResult{Message: fmt.Sprintf("the ingresscontroller's route and namespace selectors do not select canary route %s/%s", route.Namespace, route.Name)}
+	}
+	host := admittedHost(route, ic.Name)
+	if len(host) == 0 {
+		return controller.CanaryResult{Message: fmt.Sprintf("canary route %s/%s has not been admitted by the ingresscontroller", route.Namespace, route.Name)}
+	}
+	start := time.Now()
+	err = probeCanary(r.httpClient, "http://"+host+"/")
+	canaryCheckDuration.WithLabelValues(ic.Name).Set(time.Since(start).Seconds())
+	if err != nil {
+		return controller.CanaryResult{Message: err.Error()}
+	}
+	return controller.CanaryResult{Succeeded: true}
+}
+
+// admittedHost returns the host with which the named router admitted the given
+// route, or the empty string if the router has not admitted it.
+func admittedHost(route *routev1.Route, routerName string) string {
+	for _, ingress := range route.Status.Ingress {
+		if ingress.RouterName != routerName {
+			continue
+		}
+		for _, cond := range ingress.Conditions {
+			if cond.Type == routev1.RouteAdmitted && cond.Status == corev1.ConditionTrue {
+				return ingress.Host
+			}
+		}
+	}
+	return ""
+}
+
+// newCanaryHTTPClient returns the client with which canary routes are
+// requested.  Connections are not reused so that each check exercises the
+// whole data path, and redirects are not followed.
+func newCanaryHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   canaryCheckTimeout,
+		Transport: &http.Transport{DisableKeepAlives: true},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// probeCanary requests the given URL with the given client and returns an
+// error unless the canary application served the response.
+func probeCanary(httpClient *http.Client, url string) error {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %v", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+	if port := resp.Header.Get(controller.CanaryPortHeader); port != strconv.Itoa(controller.CanaryPort) {
+		return fmt.Errorf("unexpected %s header %q from %s", controller.CanaryPortHeader, port, url)
+	}
+	if strings.TrimSpace(string(body)) != controller.CanaryResponse {
+		return fmt.Errorf("unexpected response body from %s", url)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredShardCanaryRoute(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "sharded"},
+		Spec: operatorv1.IngressControllerSpec{
+			RouteSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"type": "sharded"},
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{Domain: "sharded.example.com"},
+	}
+	route := desiredShardCanaryRoute(ic)
+	if route.Namespace != controller.CanaryNamespace || route.Name != "canary-sharded" {
+		t.Errorf("unexpected name %s/%s", route.Namespace, route.Name)
+	}
+	if expected := "canary-sharded-openshift-ingress-canary.sharded.example.com"; route.Spec.Host != expected {
+		t.Errorf("expected host %q, got %q", expected, route.Spec.Host)
+	}
+	if route.Labels["type"] != "sharded" || route.Labels[manifests.OwningIngressControllerLabel] != "sharded" {
+		t.Errorf("unexpected labels %v", route.Labels)
+	}
+	selected, err := controller.RouteSelected(ic, route.Labels, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !selected {
+		t.Errorf("expected the ingresscontroller to select its canary route")
+	}
+}
+
+func TestAdmittedHost(t *testing.T) {
+	admitted := []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue}}
+	rejected := []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: corev1.ConditionFalse}}
+	testCases := []struct {
+		description string
+		ingress     []routev1.RouteIngress
+		expect      string
+	}{
+		{
+			description: "not admitted by any router",
+			expect:      "",
+		},
+		{
+			description: "admitted by another router",
+			ingress:     []routev1.RouteIngress{{RouterName: "default", Host: "a.example.com", Conditions: admitted}},
+			expect:      "",
+		},
+		{
+			description: "rejected by the router",
+			ingress:     []routev1.RouteIngress{{RouterName: "sharded", Host: "b.example.com", Conditions: rejected}},
+			expect:      "",
+		},
+		{
+			description: "admitted by the router",
+			ingress: []routev1.RouteIngress{
+				{RouterName: "default", Host: "a.example.com", Conditions: admitted},
+				{RouterName: "sharded", Host: "b.example.com", Conditions: admitted},
+			},
+			expect: "b.example.com",
+		},
+	}
+	for _, tc := range testCases {
+		route := &routev1.Route{Status: routev1.RouteStatus{Ingress: tc.ingress}}
+		if actual := admittedHost(route, "sharded"); actual != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestProbeCanary(t *testing.T) {
+	testCases := []struct {
+		description string
+		handler     http.HandlerFunc
+		expectErr   bool
+	}{
+		{
+			description: "canary response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(controller.CanaryPortHeader, fmt.Sprintf("%d", controller.CanaryPort))
+				fmt.Fprint(w, controller.CanaryResponse)
+			},
+			expectErr: false,
+		},
+		{
+			description: "missing port header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, controller.CanaryResponse)
+			},
+			expectErr: true,
+		},
+		{
+			description: "router error page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Application is not available", http.StatusServiceUnavailable)
+			},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		server := httptest.NewServer(tc.handler)
+		err := probeCanary(newCanaryHTTPClient(), server.URL)
+		server.Close()
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestIsCanaryRolledOut(t *testing.T) {
+	daemonset := func(image string, generation, observed int64, desired, updated, available int32) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		ds.Generation = generation
+		ds.Spec.Template.Spec.Containers = []corev1.Container{{Name: "serve-healthcheck-canary", Image: image}}
+		ds.Status = appsv1.DaemonSetStatus{
+			ObservedGeneration:     observed,
+			DesiredNumberScheduled: desired,
+			UpdatedNumberScheduled: updated,
+			NumberAvailable:        available,
+		}
+		return ds
+	}
+	testCases := []struct {
+		description string
+		daemonset   *appsv1.DaemonSet
+		expect      bool
+	}{
+		{
+			description: "rolled out",
+			daemonset:   daemonset("canary:v2", 2, 2, 3, 3, 3),
+			expect:      true,
+		},
+		{
+			description: "spec not observed yet",
+			daemonset:   daemonset("canary:v2", 2, 1, 3, 3, 3),
+			expect:      false,
+		},
+		{
+			description: "old image",
+			daemonset:   daemonset("canary:v1", 2, 2, 3, 3, 3),
+			expect:      false,
+		},
+		{
+			description: "pods still updating",
+			daemonset:   daemonset("canary:v2", 2, 2, 3, 2, 3),
+			expect:      false,
+		},
+		{
+			description: "updated pod not available yet",
+			daemonset:   daemonset("canary:v2", 2, 2, 3, 3, 2),
+			expect:      false,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := isCanaryRolledOut(tc.daemonset, "canary:v2"); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -37,11 +37,12 @@ const (
 
 var log = logf.Logger.WithName(controllerName)
 
-func New(mgr manager.Manager, client client.Client, operatorNamespace string) (runtimecontroller.Controller, error) {
+func New(mgr manager.Manager, client client.Client, operatorNamespace, operandNamespace string) (runtimecontroller.Controller, error) {
 	reconciler := &reconciler{
 		client:            client,
 		recorder:          mgr.GetEventRecorderFor(controllerName),
 		operatorNamespace: operatorNamespace,
+		operandNamespace:  operandNamespace,
 	}
 	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
 	if err != nil {
@@ -57,6 +58,7 @@ type reconciler struct {
 	client            client.Client
 	recorder          record.EventRecorder
 	operatorNamespace string
+	operandNamespace  string
 }
 
 func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -80,7 +82,7 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		log.Info("ingresscontroller domain not set; reconciliation will be skipped", "request", request)
 	} else {
 		deployment := &appsv1.Deployment{}
-		err = r.client.Get(context.TODO(), controller.RouterDeploymentName(ingress), deployment)
+		err = r.client.Get(context.TODO(), controller.RouterDeploymentName(ingress, r.operandNamespace), deployment)
 		if err != nil {
 			if errors.IsNotFound(err) {
 				// All ingresses should have a deployment, so this one may not have been
@@ -2,6 +2,8 @@ package certificate
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 
 	"github.com/openshift/library-go/pkg/crypto"
@@ -50,11 +52,49 @@ func (r *reconciler) ensureDefaultCertificateForIngress(caSecret *corev1.Secret,
 			return true, nil
 		}
 	case desired != nil && current != nil:
-		// TODO Update if CA certificate changed.
+		if !defaultCertificateNeedsUpdate(current, ca, ci.Status.Domain) {
+			break
+		}
+		if updated, err := r.updateRouterDefaultCertificate(current, desired); err != nil {
+			return false, fmt.Errorf("failed to update default certificate: %v", err)
+		} else if updated {
+			r.recorder.Eventf(ci, "Normal", "UpdatedDefaultCertificate", "Updated default wildcard certificate %q for domain %q", current.Name, ci.Status.Domain)
+			return true, nil
+		}
 	}
 	return false, nil
 }
 
+// defaultCertificateNeedsUpdate returns true if the given operator-generated
+// default certificate secret does not have a certificate for the given domain
+// that is signed by the given CA.  This happens if the ingresscontroller's
+// domain or the operator's CA has changed since the certificate was generated.
+func defaultCertificateNeedsUpdate(current *corev1.Secret, ca *crypto.CA, domain string) bool {
+	block, _ := pem.Decode(current.Data["tls.crt"])
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+	wildcard := fmt.Sprintf("*.%s", domain)
+	hasDomain := false
+	for _, name := range cert.DNSNames {
+		if name == wildcard {
+			hasDomain = true
+			break
+		}
+	}
+	if !hasDomain {
+		return true
+	}
+	if len(ca.Config.Certs) == 0 || cert.CheckSignatureFrom(ca.Config.Certs[0]) != nil {
+		return true
+	}
+	return false
+}
+
 // desiredRouterDefaultCertificateSecret returns the desired default certificate
 // secret.
 func desiredRouterDefaultCertificateSecret(ca *crypto.CA, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController) (*corev1.Secret, error) {
@@ -69,6 +109,12 @@ func desiredRouterDefaultCertificateSecret(ca *crypto.CA, namespace string, depl
 		return nil, nil
 	}
 
+	// If cert-manager issues the default certificate, the operator does
+	// not need to generate one either.
+	if controller.UsesCertManager(ci) {
+		return nil, nil
+	}
+
 	hostnames := sets.NewString(fmt.Sprintf("*.%s", ci.Status.Domain))
 	cert, err := ca.MakeServerCert(hostnames, 0)
 	if err != nil {
@@ -119,6 +165,21 @@ func (r *reconciler) createRouterDefaultCertificate(secret *corev1.Secret) (bool
 	return true, nil
 }
 
+// updateRouterDefaultCertificate updates the certificate and key in the router
+// default certificate secret.  Returns true if the secret was updated,
+// otherwise returns false.
+func (r *reconciler) updateRouterDefaultCertificate(current, desired *corev1.Secret) (bool, error) {
+	updated := current.DeepCopy()
+	updated.Data = desired.Data
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // deleteRouterDefaultCertificate deletes the router default certificate secret.
 // Returns true if the secret was deleted, otherwise returns false.
 func (r *reconciler) deleteRouterDefaultCertificate(secret *corev1.Secret) (bool, error) {
@@ -0,0 +1,51 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/crypto"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefaultCertificateNeedsUpdate(t *testing.T) {
+	newCA := func() *crypto.CA {
+		secret, err := desiredRouterCASecret("openshift-ingress-operator")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ca, err := crypto.GetCAFromBytes(secret.Data["tls.crt"], secret.Data["tls.key"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ca
+	}
+	ca, otherCA := newCA(), newCA()
+
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "shard"},
+		Status:     operatorv1.IngressControllerStatus{Domain: "shard.example.com"},
+	}
+	secret, err := desiredRouterDefaultCertificateSecret(ca, "openshift-ingress", metav1.OwnerReference{}, ic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		ca          *crypto.CA
+		domain      string
+		expect      bool
+	}{
+		{"same domain and CA", ca, "shard.example.com", false},
+		{"domain changed", ca, "apps.example.com", true},
+		{"CA changed", otherCA, "shard.example.com", true},
+	}
+	for _, tc := range testCases {
+		if actual := defaultCertificateNeedsUpdate(secret, tc.ca, tc.domain); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
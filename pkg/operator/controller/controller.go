@@ -3,15 +3,22 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/dns"
 	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
 	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/client-go/tools/record"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -20,14 +27,18 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -37,6 +48,36 @@ const (
 	// considered for processing; this ensures the operator has a chance to handle
 	// all states.
 	IngressControllerFinalizer = "ingresscontroller.operator.openshift.io/finalizer-ingresscontroller"
+
+	// SkipDNSFinalizationAnnotation may be set to "true" on an
+	// IngressController that is being deleted to skip deleting its DNS
+	// records.  This is an escape hatch for when the DNS provider is
+	// permanently unreachable and would otherwise block the finalizer.
+	SkipDNSFinalizationAnnotation = "ingress.operator.openshift.io/skip-dns-finalization"
+
+	// ingressControllerDeletionTimeout is how long finalization of an
+	// IngressController may fail before the operator considers the deletion
+	// stuck, reports Degraded, and stops retrying with backoff.
+	ingressControllerDeletionTimeout = 30 * time.Minute
+
+	// stuckDeletionRetryPeriod is how often finalization of an
+	// IngressController whose deletion is stuck is retried.
+	stuckDeletionRetryPeriod = 10 * time.Minute
+
+	// DefaultResyncPeriod is the default period after which an
+	// IngressController is reconciled again even if no watched resource
+	// changes.  This repairs drift that no watch event reports, such as a
+	// load balancer that is deleted on the cloud provider's side.
+	DefaultResyncPeriod = 10 * time.Minute
+
+	// resyncJitterFactor is the maximum fraction of the resync period that
+	// is added to it at random so that the periodic reconciliations of
+	// many IngressControllers are spread out.
+	resyncJitterFactor = 0.2
+
+	// DefaultMaxConcurrentReconciles is the default number of
+	// IngressControllers that are reconciled concurrently.
+	DefaultMaxConcurrentReconciles = 4
 )
 
 var log = logf.Logger.WithName("controller")
@@ -48,32 +89,176 @@ var log = logf.Logger.WithName("controller")
 // The controller will be pre-configured to watch for IngressController resources
 // in the manager namespace.
 func New(mgr manager.Manager, config Config) (controller.Controller, error) {
-	kubeClient, err := operatorclient.NewClient(config.KubeConfig)
+	kubeClient, err := operatorclient.NewRefreshableClient(config.KubeConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kube client: %v", err)
 	}
+	if len(config.OperandNamespace) == 0 {
+		config.OperandNamespace = DefaultOperandNamespace
+	}
 	reconciler := &reconciler{
 		Config:   config,
 		client:   kubeClient,
 		recorder: mgr.GetEventRecorderFor("operator-controller"),
+
+		hostNetworkProber: newHostNetworkProber(),
 	}
-	c, err := controller.New("operator-controller", mgr, controller.Options{Reconciler: reconciler})
+	maxConcurrentReconciles := config.MaxConcurrentReconciles
+	if maxConcurrentReconciles <= 0 {
+		maxConcurrentReconciles = DefaultMaxConcurrentReconciles
+	}
+	c, err := controller.New("operator-controller", mgr, controller.Options{
+		Reconciler:              reconciler,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}); err != nil {
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}, ingressControllerPredicate); err != nil {
 		return nil, err
 	}
+	// Index ingresscontrollers by the secrets and configmaps that they
+	// reference so that a change to one of them can be mapped back to the
+	// ingresscontrollers to reconcile.
+	if err := mgr.GetCache().IndexField(&operatorv1.IngressController{}, ReferencedObjectsIndex, func(o runtime.Object) []string {
+		return referencedObjectIndexKeys(o.(*operatorv1.IngressController), config.OperandNamespace)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create index for ingresscontroller: %v", err)
+	}
+	// Finalization of an ingresscontroller waits for its DNSRecords to be
+	// deleted, so queue the owning ingresscontroller when a DNSRecord
+	// changes.
+	if err := c.Watch(&source.Kind{Type: &ingressv1.DNSRecord{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &operatorv1.IngressController{},
+		IsController: true,
+	}); err != nil {
+		return nil, err
+	}
+	// Queue an ingresscontroller when a background probe of its host
+	// network router ports finishes so that the outcome is published.
+	if err := c.Watch(&source.Channel{Source: reconciler.hostNetworkProber.events}, &handler.EnqueueRequestForObject{}); err != nil {
+		return nil, err
+	}
+	// Queue an ingresscontroller when the load on its shard changes so
+	// that its ScaleLimitsExceeded condition is updated.
+	if config.ShardLoads != nil {
+		if err := c.Watch(&source.Channel{Source: config.ShardLoads.events}, &handler.EnqueueRequestForObject{}); err != nil {
+			return nil, err
+		}
+	}
+	if config.Heartbeat != nil {
+		if err := watchHeartbeat(mgr, c); err != nil {
+			return nil, err
+		}
+	}
+	// Queue an ingresscontroller when the result of its canary check
+	// changes so that its CanaryChecksSucceeding condition is updated.
+	if config.CanaryResults != nil {
+		if err := c.Watch(&source.Channel{Source: config.CanaryResults.events}, &handler.EnqueueRequestForObject{}); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
+// ingressControllerPredicate filters out update events for ingresscontrollers
+// that only change status that the operator itself wrote.  The reconciler
+// continues with the updated ingresscontroller after it publishes the effective
+// domain, endpoint publishing strategy, or admission result, so without
+// filtering, each of these status updates would reconcile a newly admitted
+// ingresscontroller once more, racing the reconciliation that wrote it.  A
+// change to the Admitted condition that the operator did not make, such as
+// clearing the status, still re-enqueues the ingresscontroller.
+var ingressControllerPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		old, ok := e.ObjectOld.(*operatorv1.IngressController)
+		if !ok {
+			return true
+		}
+		new, ok := e.ObjectNew.(*operatorv1.IngressController)
+		if !ok {
+			return true
+		}
+		return ingressControllerChanged(old, new)
+	},
+}
+
+// ingressControllerChanged returns true if old and new differ in their spec,
+// in metadata that configures or finalizes the ingresscontroller, or in their
+// Admitted condition.
+func ingressControllerChanged(old, new *operatorv1.IngressController) bool {
+	if old.Generation != new.Generation ||
+		!reflect.DeepEqual(old.Labels, new.Labels) ||
+		!reflect.DeepEqual(old.Annotations, new.Annotations) ||
+		!reflect.DeepEqual(old.Finalizers, new.Finalizers) ||
+		!reflect.DeepEqual(old.DeletionTimestamp, new.DeletionTimestamp) {
+		return true
+	}
+	oldAdmitted := findIngressStatusCondition(old.Status.Conditions, IngressControllerAdmittedConditionType)
+	newAdmitted := findIngressStatusCondition(new.Status.Conditions, IngressControllerAdmittedConditionType)
+	if oldAdmitted == nil || newAdmitted == nil {
+		return oldAdmitted != newAdmitted
+	}
+	return oldAdmitted.Status != newAdmitted.Status
+}
+
 // Config holds all the things necessary for the controller to run.
 type Config struct {
 	KubeConfig             *rest.Config
 	Namespace              string
-	DNSManager             dns.Manager
 	IngressControllerImage string
 	OperatorReleaseVersion string
+
+	// CanaryImage is the image of the canary application.  If it is empty,
+	// the operator does not deploy the canary or report its version.
+	CanaryImage string
+
+	// MaxConcurrentReconciles is the number of IngressControllers that may
+	// be reconciled concurrently.  If zero, DefaultMaxConcurrentReconciles is
+	// used.
+	MaxConcurrentReconciles int
+
+	// FeatureGates are the names of the enabled experimental features,
+	// which are reported in the clusteroperator's status.
+	FeatureGates []string
+
+	// OperandNamespace is the namespace of the routers and the other
+	// resources that the operator manages for ingresscontrollers.
+	OperandNamespace string
+
+	// LoadBalancerDeleter deletes cloud load balancers directly when the
+	// cleanup of an IngressController's cloud resources is forced.  It is
+	// optional.
+	LoadBalancerDeleter dns.LoadBalancerDeleter
+
+	// Reloadable holds the resync period, which is the period after which
+	// an IngressController is reconciled again if nothing else triggers a
+	// reconciliation and may change while the operator runs.  If it is nil
+	// or the period is zero, DefaultResyncPeriod is used.  If the period is
+	// negative, IngressControllers are not resynced periodically.
+	Reloadable *operatorconfig.Reloadable
+
+	// ShardLoads holds the loads of the ingresscontrollers' shards from
+	// which the ScaleLimitsExceeded condition is computed.  If it is nil,
+	// the condition is not computed.
+	ShardLoads *ShardLoads
+
+	// CanaryResults holds the results of the canary checks of the
+	// ingresscontrollers that opt in to them, from which the
+	// CanaryChecksSucceeding condition is computed.  If it is nil, the
+	// condition is not computed.
+	CanaryResults *CanaryResults
+
+	// Heartbeat, if set, is called each time the controller reconciles the
+	// heartbeat request that it queues every HeartbeatInterval, which shows
+	// that the controller is making progress.
+	Heartbeat func()
+
+	// OperandCache is a cache of the operand namespace from which router
+	// pods are read.
+	OperandCache cache.Cache
+	// ClusterCache is a cluster-scoped cache from which nodes are read.
+	ClusterCache cache.Cache
 }
 
 // reconciler handles the actual ingress reconciliation logic in response to
@@ -83,17 +268,36 @@ type reconciler struct {
 
 	// client is the kube Client and it will refresh scheme/mapper fields if needed
 	// to detect some resources like ServiceMonitor which could get registered after
-	// the client creation.
-	// Since this controller is running in single threaded mode,
-	// we do not need to synchronize when changing rest scheme/mapper fields.
-	client   kclient.Client
+	// the client creation.  The client is safe for concurrent use.
+	client   operatorclient.Client
 	recorder record.EventRecorder
+
+	// Distinct IngressControllers are reconciled concurrently, so any
+	// logic that reads and then writes state shared by all
+	// IngressControllers must be serialized.
+	//
+	// admissionLock serializes checking an IngressController's domain for
+	// uniqueness and publishing it to status.
+	admissionLock sync.Mutex
+	// operatorStatusLock serializes updates to the ClusterOperator.
+	operatorStatusLock sync.Mutex
+
+	// hostNetworkProber probes the router ports of ingresscontrollers that
+	// use the host network node contract.
+	hostNetworkProber *hostNetworkProber
 }
 
 // Reconcile expects request to refer to a ingresscontroller in the operator
 // namespace, and will do all the work to ensure the ingresscontroller is in the
-// desired state.
+// desired state.  The heartbeat request is only reported.
 func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	if request.NamespacedName == heartbeatName {
+		if r.Heartbeat != nil {
+			r.Heartbeat()
+		}
+		return reconcile.Result{}, nil
+	}
+
 	errs := []error{}
 	result := reconcile.Result{}
 
@@ -114,11 +318,14 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 	}
 
 	if ingress != nil {
-		dnsConfig := &configv1.DNS{}
-		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, dnsConfig); err != nil {
-			errs = append(errs, fmt.Errorf("failed to get dns 'cluster': %v", err))
-			dnsConfig = nil
+		if err := r.syncPausedCondition(ingress); err != nil {
+			errs = append(errs, err)
 		}
+	}
+
+	if ingress != nil && isPaused(ingress) {
+		log.Info("ingresscontroller is paused; reconciliation will be skipped", "namespace", ingress.Namespace, "name", ingress.Name, "annotation", PausedAnnotation)
+	} else if ingress != nil {
 		infraConfig := &configv1.Infrastructure{}
 		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig); err != nil {
 			errs = append(errs, fmt.Errorf("failed to get infrastructure 'cluster': %v", err))
@@ -134,7 +341,7 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		// because weaving conditionals everywhere to deal with various nil states
 		// is too complicated. It doesn't seem too risky to rely on the invariant
 		// of the cluster config being available.
-		if dnsConfig != nil && infraConfig != nil && ingressConfig != nil {
+		if infraConfig != nil && ingressConfig != nil {
 			// Ensure we have all the necessary scaffolding on which to place router instances.
 			if err := r.ensureRouterNamespace(); err != nil {
 				errs = append(errs, fmt.Errorf("failed to ensure router namespace: %v", err))
@@ -147,15 +354,46 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 					errs = append(errs, fmt.Errorf("failed to enforce the effective HA configuration for ingresscontroller %s: %v", ingress.Name, err))
 				} else if ingress.DeletionTimestamp != nil {
 					// Handle deletion.
-					if err := r.ensureIngressDeleted(ingress, dnsConfig, infraConfig); err != nil {
-						errs = append(errs, fmt.Errorf("failed to ensure ingress deletion: %v", err))
+					if err := r.ensureIngressDeleted(ingress); err != nil {
+						if isIngressDeletionStuck(ingress, time.Now()) {
+							// Stop retrying with backoff; the stuck
+							// deletion is reported in the operator's
+							// Degraded condition.
+							log.Error(err, "ingresscontroller deletion is stuck; set the override annotation to skip DNS finalization", "namespace", ingress.Namespace, "name", ingress.Name, "annotation", SkipDNSFinalizationAnnotation)
+							result.RequeueAfter = stuckDeletionRetryPeriod
+						} else {
+							errs = append(errs, fmt.Errorf("failed to ensure ingress deletion: %v", err))
+						}
 					}
 				} else if err := r.enforceIngressFinalizer(ingress); err != nil {
 					errs = append(errs, fmt.Errorf("failed to enforce ingress finalizer %s/%s: %v", ingress.Namespace, ingress.Name, err))
-				} else {
-					// Handle everything else.
-					if err := r.ensureIngressController(ingress, dnsConfig, infraConfig); err != nil {
-						errs = append(errs, fmt.Errorf("failed to ensure ingresscontroller: %v", err))
+				} else if admitted, err := r.admitIngressController(ingress, infraConfig); err != nil {
+					errs = append(errs, fmt.Errorf("failed to admit ingresscontroller %s: %v", ingress.Name, err))
+				} else if admitted {
+					// Handle everything else.  An ingresscontroller
+					// that is not admitted is reconciled again when
+					// its spec is corrected.
+					state, err := r.managementState(ingress)
+					if err != nil {
+						errs = append(errs, fmt.Errorf("failed to determine management state for ingresscontroller %s: %v", ingress.Name, err))
+					}
+					switch state {
+					case "":
+						// The state is unknown, so the
+						// operands are left as they are.
+					case operatorv1.Unmanaged:
+						log.Info("ingresscontroller is unmanaged; operands will not be reconciled", "namespace", ingress.Namespace, "name", ingress.Name)
+						if err := r.ensureIngressControllerUnmanaged(ingress); err != nil {
+							errs = append(errs, fmt.Errorf("failed to sync status of unmanaged ingresscontroller: %v", err))
+						}
+					case operatorv1.Removed:
+						if err := r.ensureIngressControllerRemoved(ingress); err != nil {
+							errs = append(errs, fmt.Errorf("failed to ensure ingresscontroller is removed: %v", err))
+						}
+					default:
+						if err := r.ensureIngressController(ingress, infraConfig); err != nil {
+							errs = append(errs, wrapError(err, "failed to ensure ingresscontroller"))
+						}
 					}
 				}
 			}
@@ -167,19 +405,73 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		errs = append(errs, fmt.Errorf("failed to sync operator status: %v", err))
 	}
 
-	return result, utilerrors.NewAggregate(errs)
+	// Retry transient errors with backoff, but do not retry errors that
+	// only a change to the ingresscontroller can resolve; report them in
+	// its status instead.  Throttled requests are retried after the delay
+	// that the throttling requires.
+	retryable, terminal, retryAfter := classifyErrors(errs)
+	// A paused ingresscontroller's status is left as it is apart from its
+	// Paused condition, so a TerminalError condition from before it was
+	// paused is kept until it is resumed.
+	if ingress != nil && ingress.DeletionTimestamp == nil && !isPaused(ingress) {
+		if len(terminal) != 0 {
+			log.Info("ingresscontroller has terminal errors; reconciliation will not be retried until it changes", "namespace", ingress.Namespace, "name", ingress.Name, "errors", utilerrors.NewAggregate(terminal).Error())
+		}
+		if err := r.syncTerminalErrorCondition(ingress, terminal); err != nil {
+			retryable = append(retryable, err)
+		}
+	}
+	if retryAfter > 0 && (result.RequeueAfter == 0 || retryAfter < result.RequeueAfter) {
+		log.Info("requests were throttled; retrying after delay", "request", request, "delay", retryAfter)
+		result.RequeueAfter = retryAfter
+	}
+
+	if ingress != nil && ingress.DeletionTimestamp == nil && result.RequeueAfter == 0 {
+		result.RequeueAfter = resyncAfter(r.resyncPeriod())
+	}
+
+	return result, utilerrors.NewAggregate(retryable)
+}
+
+// resyncPeriod returns the current resync period.
+func (r *reconciler) resyncPeriod() time.Duration {
+	if r.Config.Reloadable == nil {
+		return DefaultResyncPeriod
+	}
+	if period := r.Config.Reloadable.ResyncPeriod(); period != 0 {
+		return period
+	}
+	return DefaultResyncPeriod
+}
+
+// resyncAfter returns the jittered delay after which an IngressController is
+// reconciled again given the configured resync period, or zero if periodic
+// resyncs are disabled.
+func resyncAfter(period time.Duration) time.Duration {
+	if period <= 0 {
+		return 0
+	}
+	return wait.Jitter(period, resyncJitterFactor)
 }
 
 // enforceEffectiveIngressDomain determines the effective ingress domain for the
 // given ingresscontroller and ingress configuration and publishes it to the
 // ingresscontroller's status.
 func (r *reconciler) enforceEffectiveIngressDomain(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress) error {
-	// The ingresscontroller's ingress domain is immutable, so if we have
-	// published a domain to status, we must continue using it.
+	// Once a domain is published to status, it changes only if spec.domain
+	// is changed to a different domain, which starts a migration.
 	if len(ic.Status.Domain) > 0 {
-		return nil
+		if len(ic.Spec.Domain) == 0 || ic.Spec.Domain == ic.Status.Domain || ic.DeletionTimestamp != nil {
+			return nil
+		}
+		r.admissionLock.Lock()
+		defer r.admissionLock.Unlock()
+		return r.migrateIngressDomain(ic)
 	}
 
+	r.admissionLock.Lock()
+	defer r.admissionLock.Unlock()
+
 	updated := ic.DeepCopy()
 	var domain string
 	switch {
@@ -205,12 +497,10 @@ func (r *reconciler) enforceEffectiveIngressDomain(ic *operatorv1.IngressControl
 		updated.Status.Domain = domain
 	}
 
-	if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+	if err := r.client.PatchStatus(context.TODO(), updated, operatorclient.MergeFromWithOptimisticLock(ic)); err != nil {
 		return fmt.Errorf("failed to update status of IngressController %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ic); err != nil {
-		return fmt.Errorf("failed to get IngressController %s/%s: %v", updated.Namespace, updated.Name, err)
-	}
+	updated.DeepCopyInto(ic)
 	return nil
 }
 
@@ -235,9 +525,9 @@ func (r *reconciler) isDomainUnique(domain string) (bool, error) {
 	return true, nil
 }
 
-// publishingStrategyTypeForInfra returns the appropriate endpoint publishing
+// PublishingStrategyTypeForInfra returns the appropriate endpoint publishing
 // strategy type for the given infrastructure config.
-func publishingStrategyTypeForInfra(infraConfig *configv1.Infrastructure) operatorv1.EndpointPublishingStrategyType {
+func PublishingStrategyTypeForInfra(infraConfig *configv1.Infrastructure) operatorv1.EndpointPublishingStrategyType {
 	switch infraConfig.Status.Platform {
 	case configv1.AWSPlatformType:
 		return operatorv1.LoadBalancerServiceStrategyType
@@ -264,15 +554,13 @@ func (r *reconciler) enforceEffectiveEndpointPublishingStrategy(ci *operatorv1.I
 		updated.Status.EndpointPublishingStrategy = ci.Spec.EndpointPublishingStrategy.DeepCopy()
 	default:
 		updated.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
-			Type: publishingStrategyTypeForInfra(infraConfig),
+			Type: PublishingStrategyTypeForInfra(infraConfig),
 		}
 	}
-	if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+	if err := r.client.PatchStatus(context.TODO(), updated, client.MergeFrom(ci)); err != nil {
 		return fmt.Errorf("failed to update status of ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ci); err != nil {
-		return fmt.Errorf("failed to get ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
-	}
+	updated.DeepCopyInto(ci)
 	return nil
 }
 
@@ -290,16 +578,43 @@ func (r *reconciler) enforceIngressFinalizer(ingress *operatorv1.IngressControll
 
 // ensureIngressDeleted tries to delete ingress, and if successful, will remove
 // the finalizer.
-func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController, dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure) error {
-	if err := r.finalizeLoadBalancerService(ingress, dnsConfig); err != nil {
+func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController) error {
+	skipDNS := skipDNSFinalization(ingress)
+	if skipDNS {
+		log.Info("skipping DNS finalization for ingress", "namespace", ingress.Namespace, "name", ingress.Name, "annotation", SkipDNSFinalizationAnnotation)
+	}
+	if err := r.finalizeInternalLoadBalancer(ingress, skipDNS); err != nil {
+		return fmt.Errorf("failed to finalize internal load balancer for %s: %v", ingress.Name, err)
+	}
+	if err := r.finalizeLoadBalancerService(ingress, skipDNS); err != nil {
 		return fmt.Errorf("failed to finalize load balancer service for %s: %v", ingress.Name, err)
 	}
 	log.Info("finalized load balancer service for ingress", "namespace", ingress.Namespace, "name", ingress.Name)
+	if force, err := r.forceCloudCleanup(ingress); err != nil {
+		return err
+	} else if force {
+		if err := r.forceLoadBalancerServicesCleanup(ingress); err != nil {
+			return fmt.Errorf("failed to clean up load balancers for %s: %v", ingress.Name, err)
+		}
+	}
 
 	if err := r.ensureRouterDeleted(ingress); err != nil {
 		return fmt.Errorf("failed to delete deployment for ingress %s: %v", ingress.Name, err)
 	}
 	log.Info("deleted deployment for ingress", "namespace", ingress.Namespace, "name", ingress.Name)
+	if err := r.ensureRouterDaemonSetDeleted(ingress); err != nil {
+		return fmt.Errorf("failed to delete daemonset for ingress %s: %v", ingress.Name, err)
+	}
+	if err := r.ensureHostNetworkNodeContractDeleted(ingress); err != nil {
+		return fmt.Errorf("failed to remove host network router labels from nodes for ingress %s: %v", ingress.Name, err)
+	}
+	if err := r.ensureRouterRBACDeleted(ingress); err != nil {
+		return fmt.Errorf("failed to delete router RBAC for ingress %s: %v", ingress.Name, err)
+	}
+
+	if err := r.ensureIngressClassDeleted(ingress); err != nil {
+		return fmt.Errorf("failed to delete ingressclass for ingress %s: %v", ingress.Name, err)
+	}
 
 	// Clean up the finalizer to allow the ingresscontroller to be deleted.
 	if slice.ContainsString(ingress.Finalizers, IngressControllerFinalizer) {
@@ -312,87 +627,220 @@ func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController,
 	return nil
 }
 
+// skipDNSFinalization returns true if the given ingresscontroller is annotated
+// to skip deletion of its DNS records during finalization.
+func skipDNSFinalization(ingress *operatorv1.IngressController) bool {
+	skip, err := strconv.ParseBool(ingress.Annotations[SkipDNSFinalizationAnnotation])
+	return err == nil && skip
+}
+
+// isIngressDeletionStuck returns true if the given ingresscontroller has been
+// marked for deletion for longer than ingressControllerDeletionTimeout and
+// still has the ingresscontroller finalizer.
+func isIngressDeletionStuck(ingress *operatorv1.IngressController, now time.Time) bool {
+	if ingress.DeletionTimestamp == nil {
+		return false
+	}
+	if !slice.ContainsString(ingress.Finalizers, IngressControllerFinalizer) {
+		return false
+	}
+	return now.Sub(ingress.DeletionTimestamp.Time) > ingressControllerDeletionTimeout
+}
+
 // ensureRouterNamespace ensures all the necessary scaffolding exists for
-// routers generally, including a namespace and all RBAC setup.
+// routers generally, including a namespace and the router cluster role.  Each
+// ingresscontroller's service account and bindings are managed by
+// ensureRouterRBAC.
 func (r *reconciler) ensureRouterNamespace() error {
-	cr := manifests.RouterClusterRole()
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name}, cr); err != nil {
+	desiredCR := manifests.RouterClusterRole()
+	cr := &rbacv1.ClusterRole{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: desiredCR.Name}, cr); err != nil {
 		if !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to get router cluster role %s: %v", cr.Name, err)
+			return fmt.Errorf("failed to get router cluster role %s: %v", desiredCR.Name, err)
 		}
-		if err := r.client.Create(context.TODO(), cr); err != nil {
-			return fmt.Errorf("failed to create router cluster role %s: %v", cr.Name, err)
+		if err := r.client.Create(context.TODO(), desiredCR); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create router cluster role %s: %v", desiredCR.Name, err)
+			}
+		} else {
+			log.Info("created router cluster role", "name", desiredCR.Name)
 		}
-		log.Info("created router cluster role", "name", cr.Name)
+	} else if !reflect.DeepEqual(cr.Rules, desiredCR.Rules) {
+		// Earlier versions of the cluster role granted every router the
+		// hostnetwork SCC, which is now granted per ingresscontroller.
+		cr.Rules = desiredCR.Rules
+		if err := r.client.Update(context.TODO(), cr); err != nil {
+			return fmt.Errorf("failed to update router cluster role %s: %v", cr.Name, err)
+		}
+		log.Info("updated router cluster role", "name", cr.Name)
 	}
 
 	ns := manifests.RouterNamespace()
+	ns.Name = r.Config.OperandNamespace
+	ns.Labels["name"] = r.Config.OperandNamespace
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: ns.Name}, ns); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router namespace %q: %v", ns.Name, err)
 		}
 		if err := r.client.Create(context.TODO(), ns); err != nil {
-			return fmt.Errorf("failed to create router namespace %s: %v", ns.Name, err)
-		}
-		log.Info("created router namespace", "name", ns.Name)
-	}
-
-	sa := manifests.RouterServiceAccount()
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: sa.Namespace, Name: sa.Name}, sa); err != nil {
-		if !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to get router service account %s/%s: %v", sa.Namespace, sa.Name, err)
-		}
-		if err := r.client.Create(context.TODO(), sa); err != nil {
-			return fmt.Errorf("failed to create router service account %s/%s: %v", sa.Namespace, sa.Name, err)
-		}
-		log.Info("created router service account", "namespace", sa.Namespace, "name", sa.Name)
-	}
-
-	crb := manifests.RouterClusterRoleBinding()
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: crb.Name}, crb); err != nil {
-		if !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to get router cluster role binding %s: %v", crb.Name, err)
-		}
-		if err := r.client.Create(context.TODO(), crb); err != nil {
-			return fmt.Errorf("failed to create router cluster role binding %s: %v", crb.Name, err)
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create router namespace %s: %v", ns.Name, err)
+			}
+		} else {
+			log.Info("created router namespace", "name", ns.Name)
 		}
-		log.Info("created router cluster role binding", "name", crb.Name)
 	}
 
 	return nil
 }
 
 // ensureIngressController ensures all necessary router resources exist for a given ingresscontroller.
-func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure) error {
+func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, infraConfig *configv1.Infrastructure) error {
 	errs := []error{}
 
-	if deployment, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
-		errs = append(errs, fmt.Errorf("failed to ensure router deployment for %s: %v", ci.Name, err))
+	if err := r.ensureRelatedObjectsAnnotation(ci); err != nil {
+		errs = append(errs, fmt.Errorf("failed to ensure related objects annotation for %s: %v", ci.Name, err))
+	}
+
+	if err := r.ensureIngressClass(ci); err != nil {
+		errs = append(errs, fmt.Errorf("failed to ensure ingressclass for %s: %v", ci.Name, err))
+	}
+
+	if deployment, deploymentRef, err := r.ensureRouterWorkload(ci, infraConfig); err != nil {
+		errs = append(errs, wrapError(err, "failed to ensure router deployment for %s", ci.Name))
 	} else {
-		trueVar := true
-		deploymentRef := metav1.OwnerReference{
-			APIVersion: "apps/v1",
-			Kind:       "Deployment",
-			Name:       deployment.Name,
-			UID:        deployment.UID,
-			Controller: &trueVar,
+		if err := r.ensureRouterRBAC(ci); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure router RBAC for %s: %v", ci.Name, err))
 		}
 
-		if lbService, err := r.ensureLoadBalancerService(ci, deploymentRef, infraConfig); err != nil {
-			errs = append(errs, fmt.Errorf("failed to ensure load balancer service for %s: %v", ci.Name, err))
+		if err := r.ensureNetworkPolicy(ci, deploymentRef); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure network policy for %s: %v", ci.Name, err))
+		}
+
+		lbService, err := r.ensureLoadBalancerService(ci, deploymentRef, infraConfig)
+		if err != nil {
+			errs = append(errs, wrapError(err, "failed to ensure load balancer service for %s", ci.Name))
 		} else if lbService != nil {
-			if err := r.ensureDNS(ci, lbService, dnsConfig); err != nil {
+			// The internal load balancer's DNSRecord must be gone
+			// from the private zone before the wildcard DNSRecord
+			// may be published there again.
+			if err := r.ensureInternalLoadBalancer(ci, deploymentRef, infraConfig); err != nil {
+				errs = append(errs, wrapError(err, "failed to ensure internal load balancer for %s", ci.Name))
+			} else if err := r.ensureDNS(ci, lbService); err != nil {
 				errs = append(errs, fmt.Errorf("failed to ensure DNS for %s: %v", ci.Name, err))
+			} else if err := r.finishLoadBalancerServiceReplacement(ci, lbService); err != nil {
+				errs = append(errs, fmt.Errorf("failed to replace load balancer service for %s: %v", ci.Name, err))
 			}
 		}
 
+		if err := r.ensureRouterStatsSecret(ci, deploymentRef); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure router stats secret for %s: %v", ci.Name, err))
+		}
+
+		nodePortService, err := r.ensureNodePortService(ci, deploymentRef)
+		if err != nil {
+			errs = append(errs, wrapError(err, "failed to ensure NodePort service for %s", ci.Name))
+		}
+
 		if internalSvc, err := r.ensureInternalIngressControllerService(ci, deploymentRef); err != nil {
-			errs = append(errs, fmt.Errorf("failed to create internal router service for ingresscontroller %s: %v", ci.Name, err))
+			errs = append(errs, wrapError(err, "failed to create internal router service for ingresscontroller %s", ci.Name))
 		} else if err := r.ensureMetricsIntegration(ci, internalSvc, deploymentRef); err != nil {
 			errs = append(errs, fmt.Errorf("failed to integrate metrics with openshift-monitoring for ingresscontroller %s: %v", ci.Name, err))
 		}
 
-		if err := r.syncIngressControllerStatus(deployment, ci); err != nil {
+		if err := r.ensureRsyslogConfigMap(ci, deploymentRef); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure rsyslog configmap for %s: %v", ci.Name, err))
+		}
+
+		if err := r.ensureHostNetworkNodeContract(ci, deployment); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure host network router labels on nodes for %s: %v", ci.Name, err))
+		}
+
+		certManagerCondition, err := r.ensureCertManagerCertificate(ci, deploymentRef)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure cert-manager certificate for %s: %v", ci.Name, err))
+		}
+
+		lbReadyCondition, err := r.computeLoadBalancerReadyCondition(ci, lbService)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute load balancer status for %s: %v", ci.Name, err))
+			lbReadyCondition = findIngressStatusCondition(ci.Status.Conditions, operatorv1.LoadBalancerReadyIngressConditionType)
+		}
+
+		defaultCertificateCondition, err := r.computeDefaultCertificateServedCondition(ci, deployment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute default certificate status for %s: %v", ci.Name, err))
+			defaultCertificateCondition = findIngressStatusCondition(ci.Status.Conditions, DefaultCertificateServedConditionType)
+		}
+
+		endpointAddressesCondition, err := r.computeEndpointAddressesCondition(ci, lbService, nodePortService)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute endpoint addresses for %s: %v", ci.Name, err))
+			endpointAddressesCondition = findIngressStatusCondition(ci.Status.Conditions, EndpointAddressesConditionType)
+		}
+
+		domainMigrationCondition, err := r.computeDomainMigrationCondition(ci, deployment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute domain migration status for %s: %v", ci.Name, err))
+			domainMigrationCondition = findIngressStatusCondition(ci.Status.Conditions, DomainMigrationProgressingConditionType)
+		}
+
+		reloadFailingCondition, err := r.computeHAProxyReloadFailingCondition(ci)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute HAProxy reload status for %s: %v", ci.Name, err))
+			reloadFailingCondition = findIngressStatusCondition(ci.Status.Conditions, HAProxyReloadFailingConditionType)
+		}
+
+		zoneCondition, err := r.computeLoadBalancerZoneWithoutRouterCondition(ci, lbService)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute load balancer zone status for %s: %v", ci.Name, err))
+			zoneCondition = findIngressStatusCondition(ci.Status.Conditions, LoadBalancerZoneWithoutRouterConditionType)
+		}
+
+		imageCondition, err := r.computeRouterImageCondition(ci, deployment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute router image status for %s: %v", ci.Name, err))
+			imageCondition = findIngressStatusCondition(ci.Status.Conditions, RouterImageConditionType)
+		}
+
+		statusDeployment, err := r.routerDeploymentForStatus(ci, deployment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute router availability on nodes for %s: %v", ci.Name, err))
+			statusDeployment = deployment
+		}
+
+		progressingCondition, endpointPublishingCondition, err := r.computeProgressingConditions(ci, statusDeployment, lbService)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute progressing status for %s: %v", ci.Name, err))
+			progressingCondition = findIngressStatusCondition(ci.Status.Conditions, operatorv1.OperatorStatusTypeProgressing)
+			endpointPublishingCondition = findIngressStatusCondition(ci.Status.Conditions, EndpointPublishingDegradedConditionType)
+		}
+
+		hostNetworkNodesCondition, err := r.computeHostNetworkNodesUnreachableCondition(ci, deployment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute host network node reachability for %s: %v", ci.Name, err))
+			hostNetworkNodesCondition = findIngressStatusCondition(ci.Status.Conditions, HostNetworkNodesUnreachableConditionType)
+		}
+
+		scaleLimitsCondition := r.computeScaleLimitsExceededCondition(ci, deployment)
+		canaryCondition := r.computeCanaryChecksSucceedingCondition(ci)
+
+		conditions := []ingressStatusCondition{
+			{operatorv1.LoadBalancerReadyIngressConditionType, lbReadyCondition},
+			{CertManagerCertificateReadyConditionType, certManagerCondition},
+			{DefaultCertificateServedConditionType, defaultCertificateCondition},
+			{EndpointAddressesConditionType, endpointAddressesCondition},
+			{DomainMigrationProgressingConditionType, domainMigrationCondition},
+			{HAProxyReloadFailingConditionType, reloadFailingCondition},
+			{LoadBalancerZoneWithoutRouterConditionType, zoneCondition},
+			{RouterImageConditionType, imageCondition},
+			{operatorv1.OperatorStatusTypeProgressing, progressingCondition},
+			{EndpointPublishingDegradedConditionType, endpointPublishingCondition},
+			{HostNetworkNodesUnreachableConditionType, hostNetworkNodesCondition},
+			{ScaleLimitsExceededConditionType, scaleLimitsCondition},
+			{CanaryChecksSucceedingConditionType, canaryCondition},
+		}
+		if err := r.syncIngressControllerStatus(statusDeployment, ci, conditions); err != nil {
 			errs = append(errs, fmt.Errorf("failed to sync ingresscontroller status: %v", err))
 		}
 	}
@@ -402,28 +850,18 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 
 // ensureMetricsIntegration ensures that router prometheus metrics is integrated with openshift-monitoring for the given ingresscontroller.
 func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController, svc *corev1.Service, deploymentRef metav1.OwnerReference) error {
-	statsSecret := manifests.RouterStatsSecret(ci)
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: statsSecret.Namespace, Name: statsSecret.Name}, statsSecret); err != nil {
-		if !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to get router stats secret %s/%s, %v", statsSecret.Namespace, statsSecret.Name, err)
-		}
-
-		statsSecret.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
-		if err := r.client.Create(context.TODO(), statsSecret); err != nil {
-			return fmt.Errorf("failed to create router stats secret %s/%s: %v", statsSecret.Namespace, statsSecret.Name, err)
-		}
-		log.Info("created router stats secret", "namespace", statsSecret.Namespace, "name", statsSecret.Name)
-	}
-
 	cr := manifests.MetricsClusterRole()
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name}, cr); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router metrics cluster role %s: %v", cr.Name, err)
 		}
 		if err := r.client.Create(context.TODO(), cr); err != nil {
-			return fmt.Errorf("failed to create router metrics cluster role %s: %v", cr.Name, err)
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create router metrics cluster role %s: %v", cr.Name, err)
+			}
+		} else {
+			log.Info("created router metrics cluster role", "name", cr.Name)
 		}
-		log.Info("created router metrics cluster role", "name", cr.Name)
 	}
 
 	crb := manifests.MetricsClusterRoleBinding()
@@ -432,31 +870,42 @@ func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController,
 			return fmt.Errorf("failed to get router metrics cluster role binding %s: %v", crb.Name, err)
 		}
 		if err := r.client.Create(context.TODO(), crb); err != nil {
-			return fmt.Errorf("failed to create router metrics cluster role binding %s: %v", crb.Name, err)
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create router metrics cluster role binding %s: %v", crb.Name, err)
+			}
+		} else {
+			log.Info("created router metrics cluster role binding", "name", crb.Name)
 		}
-		log.Info("created router metrics cluster role binding", "name", crb.Name)
 	}
 
 	mr := manifests.MetricsRole()
+	mr.Namespace = r.Config.OperandNamespace
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: mr.Namespace, Name: mr.Name}, mr); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router metrics role %s: %v", mr.Name, err)
 		}
 		if err := r.client.Create(context.TODO(), mr); err != nil {
-			return fmt.Errorf("failed to create router metrics role %s: %v", mr.Name, err)
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create router metrics role %s: %v", mr.Name, err)
+			}
+		} else {
+			log.Info("created router metrics role", "name", mr.Name)
 		}
-		log.Info("created router metrics role", "name", mr.Name)
 	}
 
 	mrb := manifests.MetricsRoleBinding()
+	mrb.Namespace = r.Config.OperandNamespace
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: mrb.Namespace, Name: mrb.Name}, mrb); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router metrics role binding %s: %v", mrb.Name, err)
 		}
 		if err := r.client.Create(context.TODO(), mrb); err != nil {
-			return fmt.Errorf("failed to create router metrics role binding %s: %v", mrb.Name, err)
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create router metrics role binding %s: %v", mrb.Name, err)
+			}
+		} else {
+			log.Info("created router metrics role binding", "name", mrb.Name)
 		}
-		log.Info("created router metrics role binding", "name", mrb.Name)
 	}
 
 	if _, err := r.ensureServiceMonitor(ci, svc, deploymentRef); err != nil {
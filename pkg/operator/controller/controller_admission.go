@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// IngressControllerAdmittedConditionType is the type of the
+	// ingresscontroller condition that reports whether the operator has
+	// accepted the ingresscontroller's spec.  The operator does not
+	// reconcile the operands of an ingresscontroller that is not admitted.
+	IngressControllerAdmittedConditionType = "Admitted"
+)
+
+// validateIngressController returns an error describing every problem with the
+// given ingresscontroller's spec that would prevent the router from running.
+// If fipsEnabled is true, configuration that is not FIPS-compliant is also
+// rejected, as is configuration that the given platform does not support.
+func validateIngressController(ic *operatorv1.IngressController, fipsEnabled bool, platform configv1.PlatformType) error {
+	var errs []error
+	if ic.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(ic.Spec.NamespaceSelector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec.namespaceSelector: %v", err))
+		}
+	}
+	if ic.Spec.RouteSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(ic.Spec.RouteSelector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec.routeSelector: %v", err))
+		}
+	}
+	if _, err := routerUsesDaemonSet(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := routerUsesHostNetworkSCC(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := networkPolicyManagedFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, _, err := operandMetadata(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := awsLoadBalancerHealthCheckFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := loadBalancerHealthCheckNodePort(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := loadBalancerHealthCheckPath(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := dnsManagementFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := dnsRoutingPolicyFor(ic, platform); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateDNSDryRun(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := connectionLimitsFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := scaleLimitsFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := tlsCiphersFor(ic, fipsEnabled); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := minTLSVersionFor(ic, fipsEnabled); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := internalLoadBalancerEnabled(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := nodePortsFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := zoneAwareEnabled(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := probeAnnotationValue(ic, ProbeTimeoutSecondsAnnotation, probeTimeoutSecondsOption, 1); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := probeAnnotationValue(ic, StartupGracePeriodSecondsAnnotation, startupGracePeriodSecondsOption, defaultStartupGracePeriodSeconds); err != nil {
+		errs = append(errs, err)
+	}
+	if _, _, err := maxReplicasFromNodes(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := dynamicConfigManagerFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := shutdownDelaySecondsFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := routerPriorityClassFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := routerImagePolicyFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := hostNetworkNodeContractEnabled(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := topologyAwareHintsEnabled(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, _, err := serviceSessionAffinityFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := desiredRouterProfilingEnv(ic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := pausedFor(ic); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// domainOverlapWarnings returns a warning for each other ingresscontroller
+// whose domain is a subdomain of the given ingresscontroller's domain or vice
+// versa.  A route that specifies spec.subdomain is exposed under the domain of
+// each shard that admits it, so overlapping shard domains can expose different
+// routes under the same host name; for example, subdomain "foo.bar" on a shard
+// with domain "apps.example.com" and subdomain "foo" on a shard with domain
+// "bar.apps.example.com" both resolve to "foo.bar.apps.example.com".
+func domainOverlapWarnings(ic *operatorv1.IngressController, others []operatorv1.IngressController) []string {
+	domain := ic.Spec.Domain
+	if len(domain) == 0 {
+		domain = ic.Status.Domain
+	}
+	if len(domain) == 0 {
+		return nil
+	}
+	var warnings []string
+	for _, other := range others {
+		if other.Namespace == ic.Namespace && other.Name == ic.Name || len(other.Status.Domain) == 0 {
+			continue
+		}
+		if strings.HasSuffix(domain, "."+other.Status.Domain) || strings.HasSuffix(other.Status.Domain, "."+domain) {
+			warnings = append(warnings, fmt.Sprintf("domain %q overlaps with domain %q of ingresscontroller %s, so routes that specify spec.subdomain may resolve ambiguously", domain, other.Status.Domain, other.Name))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// computeAdmittedCondition returns the Admitted condition for the given
+// validation error, which is nil if the ingresscontroller is valid, and
+// warnings about valid but questionable configuration.
+func computeAdmittedCondition(validationErr error, warnings []string) *operatorv1.OperatorCondition {
+	if validationErr != nil {
+		return &operatorv1.OperatorCondition{
+			Type:    IngressControllerAdmittedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "Invalid",
+			Message: validationErr.Error(),
+		}
+	}
+	if len(warnings) != 0 {
+		return &operatorv1.OperatorCondition{
+			Type:    IngressControllerAdmittedConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "ValidWithWarnings",
+			Message: strings.Join(warnings, "; "),
+		}
+	}
+	return &operatorv1.OperatorCondition{
+		Type:   IngressControllerAdmittedConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "Valid",
+	}
+}
+
+// admissionEvent returns the event to record when an ingresscontroller's
+// Admitted condition changes from the given current condition, which is nil if
+// it has none, to the given admitted condition, or nil if it is unchanged.
+func admissionEvent(current, admitted *operatorv1.OperatorCondition) *ingressEvent {
+	if !ingressConditionChanged(current, admitted) {
+		return nil
+	}
+	switch admitted.Reason {
+	case "Invalid":
+		return &ingressEvent{"Warning", "Rejected", "The ingresscontroller is invalid: " + admitted.Message}
+	case "ValidWithWarnings":
+		return &ingressEvent{"Warning", "AdmittedWithWarnings", "The ingresscontroller is valid, but " + admitted.Message}
+	default:
+		return &ingressEvent{"Normal", "Admitted", "The ingresscontroller is valid"}
+	}
+}
+
+// admitIngressController validates the given ingresscontroller and checks that
+// it does not conflict with other ingresscontrollers, records the
+// result in its Admitted condition, and returns true if it is admitted on the
+// platform of the given infrastructure config.  The
+// given ingresscontroller's status is updated in place so that later status
+// updates in the same reconciliation preserve the condition.  An event is
+// recorded only when the condition changes so that reconciling an invalid
+// ingresscontroller repeatedly does not record an event each time.
+func (r *reconciler) admitIngressController(ic *operatorv1.IngressController, infraConfig *configv1.Infrastructure) (bool, error) {
+	fipsEnabled, err := r.fipsEnabled()
+	if err != nil {
+		return false, err
+	}
+	validationErr := validateIngressController(ic, fipsEnabled, infraConfig.Status.Platform)
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.client.List(context.TODO(), ingresses, client.InNamespace(r.Config.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list ingresscontrollers: %v", err)
+	}
+	if conflicts := nodePortConflicts(ic, ingresses.Items); len(conflicts) != 0 {
+		validationErr = utilerrors.Flatten(utilerrors.NewAggregate(append([]error{validationErr}, conflicts...)))
+	}
+	warnings := append(domainOverlapWarnings(ic, ingresses.Items), hostNetworkConflictWarnings(ic, ingresses.Items)...)
+	updated := ic.DeepCopy()
+	admittedCondition := computeAdmittedCondition(validationErr, warnings)
+	updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, admittedCondition)
+	if !ingressStatusesEqual(updated.Status, ic.Status) {
+		if err := r.client.PatchStatus(context.TODO(), updated, operatorclient.MergeFromWithOptimisticLock(ic)); err != nil {
+			return false, fmt.Errorf("failed to update ingresscontroller status: %v", err)
+		}
+		current := findIngressStatusCondition(ic.Status.Conditions, IngressControllerAdmittedConditionType)
+		if e := admissionEvent(current, admittedCondition); e != nil {
+			r.recorder.Event(updated, e.eventType, e.reason, e.message)
+		}
+		ic.Status = updated.Status
+	}
+	if validationErr != nil {
+		log.Info("ingresscontroller is not admitted; operands will not be reconciled", "namespace", ic.Namespace, "name", ic.Name, "reason", validationErr.Error())
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateIngressController(t *testing.T) {
+	testCases := []struct {
+		description       string
+		namespaceSelector *metav1.LabelSelector
+		routeSelector     *metav1.LabelSelector
+		expectErr         bool
+	}{
+		{
+			description: "no selectors",
+		},
+		{
+			description:       "valid selectors",
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			routeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "shard",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"a", "b"},
+			}}},
+		},
+		{
+			description: "invalid operator",
+			routeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "shard",
+				Operator: "Like",
+				Values:   []string{"a"},
+			}}},
+			expectErr: true,
+		},
+		{
+			description: "In without values",
+			namespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "env",
+				Operator: metav1.LabelSelectorOpIn,
+			}}},
+			expectErr: true,
+		},
+		{
+			description: "Exists with values",
+			routeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "shard",
+				Operator: metav1.LabelSelectorOpExists,
+				Values:   []string{"a"},
+			}}},
+			expectErr: true,
+		},
+		{
+			description:       "malformed label value",
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "not a valid value"}},
+			expectErr:         true,
+		},
+		{
+			description:   "malformed label key",
+			routeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"-shard": "a"}},
+			expectErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			Spec: operatorv1.IngressControllerSpec{
+				NamespaceSelector: tc.namespaceSelector,
+				RouteSelector:     tc.routeSelector,
+			},
+		}
+		err := validateIngressController(ic, false, configv1.AWSPlatformType)
+		checkError(t, tc.description, tc.expectErr, err)
+		condition := computeAdmittedCondition(err, nil)
+		if expected := (err == nil); (condition.Status == operatorv1.ConditionTrue) != expected {
+			t.Errorf("%q: unexpected Admitted condition %#v", tc.description, condition)
+		}
+	}
+}
+
+// TestDomainOverlapWarnings verifies that an ingresscontroller gets a warning
+// for each other ingresscontroller whose domain is a subdomain of its domain or
+// vice versa.
+func TestDomainOverlapWarnings(t *testing.T) {
+	icWithDomain := func(name, domain string) operatorv1.IngressController {
+		return operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: name},
+			Status:     operatorv1.IngressControllerStatus{Domain: domain},
+		}
+	}
+	ic := icWithDomain("default", "apps.example.com")
+	others := []operatorv1.IngressController{
+		ic,
+		icWithDomain("child", "bar.apps.example.com"),
+		icWithDomain("parent", "example.com"),
+		icWithDomain("sibling", "other.example.com"),
+		icWithDomain("suffix", "myapps.example.com"),
+		icWithDomain("new", ""),
+	}
+	warnings := domainOverlapWarnings(&ic, others)
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+	for i, name := range []string{"child", "parent"} {
+		if !strings.Contains(warnings[i], "ingresscontroller "+name) {
+			t.Errorf("expected a warning about ingresscontroller %s, got %q", name, warnings[i])
+		}
+	}
+	condition := computeAdmittedCondition(nil, warnings)
+	if condition.Status != operatorv1.ConditionTrue || condition.Reason != "ValidWithWarnings" {
+		t.Errorf("expected the ingresscontroller to be admitted with warnings, got %#v", condition)
+	}
+}
+
+// TestAdmissionEvent verifies that an admission event is recorded only when the
+// Admitted condition changes.
+func TestAdmissionEvent(t *testing.T) {
+	valid := computeAdmittedCondition(nil, nil)
+	invalid := computeAdmittedCondition(fmt.Errorf("bad annotation"), nil)
+	otherInvalid := computeAdmittedCondition(fmt.Errorf("other bad annotation"), nil)
+	warning := computeAdmittedCondition(nil, []string{"domain overlaps"})
+	testCases := []struct {
+		description string
+		current     *operatorv1.OperatorCondition
+		admitted    *operatorv1.OperatorCondition
+		expect      *ingressEvent
+	}{
+		{
+			description: "first admission",
+			admitted:    valid,
+			expect:      &ingressEvent{"Normal", "Admitted", "The ingresscontroller is valid"},
+		},
+		{
+			description: "still valid",
+			current:     valid,
+			admitted:    valid,
+		},
+		{
+			description: "rejected",
+			current:     valid,
+			admitted:    invalid,
+			expect:      &ingressEvent{"Warning", "Rejected", "The ingresscontroller is invalid: bad annotation"},
+		},
+		{
+			description: "still rejected for the same reason",
+			current:     invalid,
+			admitted:    invalid,
+		},
+		{
+			description: "rejected for another reason",
+			current:     invalid,
+			admitted:    otherInvalid,
+			expect:      &ingressEvent{"Warning", "Rejected", "The ingresscontroller is invalid: other bad annotation"},
+		},
+		{
+			description: "admitted with warnings",
+			current:     invalid,
+			admitted:    warning,
+			expect:      &ingressEvent{"Warning", "AdmittedWithWarnings", "The ingresscontroller is valid, but domain overlaps"},
+		},
+		{
+			description: "corrected",
+			current:     warning,
+			admitted:    valid,
+			expect:      &ingressEvent{"Normal", "Admitted", "The ingresscontroller is valid"},
+		},
+	}
+	for _, tc := range testCases {
+		actual := admissionEvent(tc.current, tc.admitted)
+		if !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%q: expected %+v, got %+v", tc.description, tc.expect, actual)
+		}
+	}
+}
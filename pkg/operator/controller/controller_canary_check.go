@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"sync"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// CanaryCheckAnnotation may be set to "Enabled" on an ingresscontroller
+	// other than the default one to opt it in to canary checks.  The canary
+	// controller then creates a canary route that the ingresscontroller's
+	// routers serve and periodically requests it through the
+	// ingresscontroller's domain, so that the data path of the shard is
+	// checked end to end.  The default ingresscontroller serves the
+	// canary's default route and is not checked separately.
+	CanaryCheckAnnotation = "ingress.operator.openshift.io/canary-check"
+
+	// CanaryChecksSucceedingConditionType is the type of the
+	// ingresscontroller condition that reports whether the most recent
+	// canary check of an ingresscontroller that opts in to canary checks
+	// succeeded.  The condition is computed from the results that the
+	// canary controller records in CanaryResults.
+	CanaryChecksSucceedingConditionType = "CanaryChecksSucceeding"
+)
+
+// CanaryCheckEnabled returns true if the given ingresscontroller opts in to
+// canary checks.
+func CanaryCheckEnabled(ic *operatorv1.IngressController) bool {
+	return ic.Name != DefaultIngressControllerName && ic.Annotations[CanaryCheckAnnotation] == "Enabled"
+}
+
+// CanaryResult is the outcome of a canary check of an ingresscontroller.
+// Message describes the failure if the check did not succeed.
+type CanaryResult struct {
+	Succeeded bool
+	Message   string
+}
+
+// CanaryResults holds the most recent canary check result of each
+// ingresscontroller that opts in to canary checks.  The canary controller
+// checks the canary routes, and the operator controller computes the
+// CanaryChecksSucceeding condition from the results so that the
+// ingresscontroller's status has one writer.  CanaryResults sends an event for
+// an ingresscontroller when its result changes so that the condition is
+// updated.  It is safe for concurrent use.
+type CanaryResults struct {
+	// events receives an event for each changed result.
+	events chan event.GenericEvent
+
+	lock    sync.Mutex
+	results map[types.NamespacedName]CanaryResult
+}
+
+// NewCanaryResults returns an empty CanaryResults.
+func NewCanaryResults() *CanaryResults {
+	return &CanaryResults{
+		events:  make(chan event.GenericEvent, 100),
+		results: map[types.NamespacedName]CanaryResult{},
+	}
+}
+
+// Set records the canary check result of the named ingresscontroller.
+func (c *CanaryResults) Set(name types.NamespacedName, result CanaryResult) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if current, ok := c.results[name]; ok && current == result {
+		return
+	}
+	c.results[name] = result
+	// The event is best effort: if the buffer is full, the
+	// ingresscontroller picks up the result when it is next reconciled.
+	select {
+	case c.events <- event.GenericEvent{Meta: &metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}}:
+	default:
+	}
+}
+
+// Delete forgets the canary check result of the named ingresscontroller.
+func (c *CanaryResults) Delete(name types.NamespacedName) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.results, name)
+}
+
+// get returns the canary check result of the named ingresscontroller and
+// whether it is known.
+func (c *CanaryResults) get(name types.NamespacedName) (CanaryResult, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	result, ok := c.results[name]
+	return result, ok
+}
+
+// computeCanaryChecksSucceedingCondition computes the CanaryChecksSucceeding
+// condition of the given ingresscontroller from the canary check result that
+// the canary controller last recorded for it.  The condition is removed if the
+// ingresscontroller does not opt in to canary checks and is left as it is
+// while the result is unknown.
+func (r *reconciler) computeCanaryChecksSucceedingCondition(ic *operatorv1.IngressController) *operatorv1.OperatorCondition {
+	if !CanaryCheckEnabled(ic) {
+		return nil
+	}
+	current := findIngressStatusCondition(ic.Status.Conditions, CanaryChecksSucceedingConditionType)
+	if r.CanaryResults == nil {
+		return current
+	}
+	result, ok := r.CanaryResults.get(types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name})
+	if !ok {
+		return current
+	}
+	return canaryChecksSucceedingCondition(result)
+}
+
+// canaryChecksSucceedingCondition returns the CanaryChecksSucceeding condition
+// for the given canary check result.
+func canaryChecksSucceedingCondition(result CanaryResult) *operatorv1.OperatorCondition {
+	if result.Succeeded {
+		return &operatorv1.OperatorCondition{
+			Type:    CanaryChecksSucceedingConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "CanaryChecksSucceeding",
+			Message: "The canary route of the ingresscontroller responds through the ingresscontroller's domain",
+		}
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    CanaryChecksSucceedingConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "CanaryChecksFailing",
+		Message: "Canary check failed: " + result.Message,
+	}
+}
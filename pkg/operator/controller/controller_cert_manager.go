@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// CertManagerIssuerAnnotation may be set on an IngressController to
+	// have cert-manager issue the default certificate for the
+	// ingresscontroller's domain.  The value is the issuer to use, in the
+	// form "<kind>/<name>" where kind is "Issuer" or "ClusterIssuer", or
+	// just "<name>" for a ClusterIssuer.  An Issuer must be in the router
+	// namespace.  The annotation is ignored if spec.defaultCertificate is
+	// set.
+	CertManagerIssuerAnnotation = "ingress.operator.openshift.io/cert-manager-issuer"
+
+	// CertManagerCertificateReadyConditionType is the type of the
+	// IngressController status condition that reports whether cert-manager
+	// has issued the ingresscontroller's default certificate.
+	CertManagerCertificateReadyConditionType = "CertManagerCertificateReady"
+
+	// certManagerGroup is the API group of cert-manager's resources.
+	certManagerGroup = "cert-manager.io"
+)
+
+// certManagerCertificateGVK is the group, version, and kind of cert-manager's
+// Certificate resource.
+var certManagerCertificateGVK = schema.GroupVersionKind{
+	Group:   certManagerGroup,
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// certManagerIssuerRef returns the kind and name of the cert-manager issuer
+// that the given ingresscontroller specifies, or empty strings if the
+// ingresscontroller does not use cert-manager.
+func certManagerIssuerRef(ci *operatorv1.IngressController) (string, string) {
+	if ci.Spec.DefaultCertificate != nil {
+		return "", ""
+	}
+	value := strings.TrimSpace(ci.Annotations[CertManagerIssuerAnnotation])
+	if len(value) == 0 {
+		return "", ""
+	}
+	if parts := strings.SplitN(value, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "ClusterIssuer", value
+}
+
+// UsesCertManager returns true if cert-manager issues the default certificate
+// for the given ingresscontroller.
+func UsesCertManager(ci *operatorv1.IngressController) bool {
+	_, name := certManagerIssuerRef(ci)
+	return len(name) != 0
+}
+
+// validateCertManagerIssuerRef returns an error if the given issuer kind and
+// name are not valid.
+func validateCertManagerIssuerRef(kind, name string) error {
+	if kind != "Issuer" && kind != "ClusterIssuer" {
+		return fmt.Errorf("invalid issuer kind %q in annotation %s: must be Issuer or ClusterIssuer", kind, CertManagerIssuerAnnotation)
+	}
+	if len(name) == 0 {
+		return fmt.Errorf("missing issuer name in annotation %s", CertManagerIssuerAnnotation)
+	}
+	return nil
+}
+
+// desiredCertManagerCertificate returns the desired cert-manager Certificate
+// for the default certificate of the given ingresscontroller.
+func desiredCertManagerCertificate(ci *operatorv1.IngressController, operandNamespace, issuerKind, issuerName string, deploymentRef metav1.OwnerReference) *unstructured.Unstructured {
+	name := RouterCertManagerCertificateName(ci, operandNamespace)
+	cert := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"secretName": name.Name,
+				"dnsNames": []interface{}{
+					fmt.Sprintf("*.%s", ci.Status.Domain),
+				},
+				"issuerRef": map[string]interface{}{
+					"group": certManagerGroup,
+					"kind":  issuerKind,
+					"name":  issuerName,
+				},
+			},
+		},
+	}
+	cert.SetGroupVersionKind(certManagerCertificateGVK)
+	cert.SetNamespace(name.Namespace)
+	cert.SetName(name.Name)
+	cert.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	return cert
+}
+
+// ensureCertManagerCertificate creates, updates, or deletes the cert-manager
+// Certificate for the given ingresscontroller as appropriate, and returns the
+// status condition that reports the state of the certificate, or nil if the
+// ingresscontroller does not use cert-manager.
+//
+// The operator does not watch Certificates because cert-manager may not be
+// installed.  Instead, the ingresscontroller is reconciled when cert-manager
+// writes the issued certificate to the secret that the router deployment
+// mounts.
+func (r *reconciler) ensureCertManagerCertificate(ci *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (*operatorv1.OperatorCondition, error) {
+	issuerKind, issuerName := certManagerIssuerRef(ci)
+
+	// Only refresh the client to discover a newly installed cert-manager
+	// if the ingresscontroller uses it, so that clusters without
+	// cert-manager do not incur API discovery on every reconciliation.
+	current, installed, err := r.currentCertManagerCertificate(ci, len(issuerName) != 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(issuerName) == 0 {
+		if current != nil {
+			if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to delete certificate %s/%s: %v", current.GetNamespace(), current.GetName(), err)
+			}
+			log.Info("deleted certificate", "namespace", current.GetNamespace(), "name", current.GetName())
+		}
+		return nil, nil
+	}
+
+	condition := &operatorv1.OperatorCondition{
+		Type:   CertManagerCertificateReadyConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+	if err := validateCertManagerIssuerRef(issuerKind, issuerName); err != nil {
+		condition.Reason = "InvalidIssuer"
+		condition.Message = err.Error()
+		return condition, nil
+	}
+	if !installed {
+		condition.Reason = "CertManagerNotInstalled"
+		condition.Message = fmt.Sprintf("The %s API is not available; install cert-manager to issue the default certificate", certManagerCertificateGVK.GroupKind())
+		return condition, nil
+	}
+
+	desired := desiredCertManagerCertificate(ci, r.Config.OperandNamespace, issuerKind, issuerName, deploymentRef)
+	switch {
+	case current == nil:
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create certificate %s/%s: %v", desired.GetNamespace(), desired.GetName(), err)
+		}
+		log.Info("created certificate", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		current = desired
+	case !reflect.DeepEqual(current.Object["spec"], desired.Object["spec"]):
+		updated := current.DeepCopy()
+		updated.Object["spec"] = desired.Object["spec"]
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return nil, fmt.Errorf("failed to update certificate %s/%s: %v", updated.GetNamespace(), updated.GetName(), err)
+		}
+		log.Info("updated certificate", "namespace", updated.GetNamespace(), "name", updated.GetName())
+		current = updated
+	}
+
+	return certManagerCertificateCondition(current), nil
+}
+
+// currentCertManagerCertificate returns the current cert-manager Certificate
+// for the given ingresscontroller, or nil if it does not exist.  It also
+// returns false if the Certificate API is not installed.  If refresh is true
+// and the API is not known to the client, the client is refreshed to discover
+// it.
+func (r *reconciler) currentCertManagerCertificate(ci *operatorv1.IngressController, refresh bool) (*unstructured.Unstructured, bool, error) {
+	name := RouterCertManagerCertificateName(ci, r.Config.OperandNamespace)
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certManagerCertificateGVK)
+	err := r.client.Get(context.TODO(), name, cert)
+	if meta.IsNoMatchError(err) && refresh {
+		// Refresh kube client with latest rest scheme/mapper in case
+		// cert-manager was installed after the client was created.
+		if err := r.client.Refresh(); err != nil {
+			return nil, false, fmt.Errorf("failed to refresh kube client: %v", err)
+		}
+		err = r.client.Get(context.TODO(), name, cert)
+	}
+	switch {
+	case err == nil:
+		return cert, true, nil
+	case meta.IsNoMatchError(err):
+		return nil, false, nil
+	case errors.IsNotFound(err):
+		return nil, true, nil
+	}
+	return nil, false, fmt.Errorf("failed to get certificate %s: %v", name, err)
+}
+
+// certManagerCertificateCondition returns the ingresscontroller status
+// condition for the given cert-manager Certificate based on the Certificate's
+// Ready condition.
+func certManagerCertificateCondition(cert *unstructured.Unstructured) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:    CertManagerCertificateReadyConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "Pending",
+		Message: fmt.Sprintf("Waiting for cert-manager to issue certificate %s/%s", cert.GetNamespace(), cert.GetName()),
+	}
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != "Ready" {
+			continue
+		}
+		status, _ := m["status"].(string)
+		reason, _ := m["reason"].(string)
+		message, _ := m["message"].(string)
+		switch status {
+		case "True":
+			condition.Status = operatorv1.ConditionTrue
+		case "False":
+			condition.Status = operatorv1.ConditionFalse
+		default:
+			condition.Status = operatorv1.ConditionUnknown
+		}
+		if len(reason) != 0 {
+			condition.Reason = reason
+		}
+		if len(message) != 0 {
+			condition.Message = message
+		}
+	}
+	return condition
+}
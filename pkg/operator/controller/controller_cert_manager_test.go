@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCertManagerIssuerRef(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotation  string
+		defaultCert *corev1.LocalObjectReference
+		expectKind  string
+		expectName  string
+	}{
+		{"no annotation", "", nil, "", ""},
+		{"cluster issuer by name", "letsencrypt", nil, "ClusterIssuer", "letsencrypt"},
+		{"namespaced issuer", "Issuer/internal-ca", nil, "Issuer", "internal-ca"},
+		{"default certificate takes precedence", "letsencrypt", &corev1.LocalObjectReference{Name: "custom"}, "", ""},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{},
+			},
+			Spec: operatorv1.IngressControllerSpec{DefaultCertificate: tc.defaultCert},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations[CertManagerIssuerAnnotation] = tc.annotation
+		}
+		kind, name := certManagerIssuerRef(ic)
+		if kind != tc.expectKind || name != tc.expectName {
+			t.Errorf("%q: expected %s/%s, got %s/%s", tc.description, tc.expectKind, tc.expectName, kind, name)
+		}
+		if UsesCertManager(ic) != (len(tc.expectName) != 0) {
+			t.Errorf("%q: unexpected UsesCertManager result", tc.description)
+		}
+	}
+}
+
+func TestRouterEffectiveDefaultCertificateSecretNameWithCertManager(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{CertManagerIssuerAnnotation: "letsencrypt"},
+		},
+	}
+	name := RouterEffectiveDefaultCertificateSecretName(ic, "openshift-ingress")
+	if expected := RouterCertManagerCertificateName(ic, DefaultOperandNamespace); name != expected {
+		t.Errorf("expected %s, got %s", expected, name)
+	}
+}
+
+func TestDesiredCertManagerCertificate(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Status:     operatorv1.IngressControllerStatus{Domain: "apps.example.com"},
+	}
+	cert := desiredCertManagerCertificate(ic, DefaultOperandNamespace, "ClusterIssuer", "letsencrypt", metav1.OwnerReference{})
+	if cert.GetNamespace() != "openshift-ingress" || cert.GetName() != "router-certs-cert-manager-default" {
+		t.Errorf("unexpected certificate name %s/%s", cert.GetNamespace(), cert.GetName())
+	}
+	dnsNames, _, _ := unstructured.NestedSlice(cert.Object, "spec", "dnsNames")
+	if len(dnsNames) != 1 || dnsNames[0] != "*.apps.example.com" {
+		t.Errorf("unexpected dnsNames %v", dnsNames)
+	}
+	secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+	if secretName != cert.GetName() {
+		t.Errorf("expected secret name %q, got %q", cert.GetName(), secretName)
+	}
+	kind, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "kind")
+	if kind != "ClusterIssuer" {
+		t.Errorf("expected issuer kind ClusterIssuer, got %q", kind)
+	}
+}
+
+func TestCertManagerCertificateCondition(t *testing.T) {
+	testCases := []struct {
+		description  string
+		conditions   []interface{}
+		expectStatus operatorv1.ConditionStatus
+		expectReason string
+	}{
+		{
+			description:  "no status",
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "Pending",
+		},
+		{
+			description: "issued",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "reason": "Ready"},
+			},
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "Ready",
+		},
+		{
+			description: "issuance failed",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "Failed", "message": "issuer not found"},
+			},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "Failed",
+		},
+	}
+	for _, tc := range testCases {
+		cert := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if tc.conditions != nil {
+			if err := unstructured.SetNestedSlice(cert.Object, tc.conditions, "status", "conditions"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		condition := certManagerCertificateCondition(cert)
+		if condition.Type != CertManagerCertificateReadyConditionType || condition.Status != tc.expectStatus || condition.Reason != tc.expectReason {
+			t.Errorf("%q: expected status %s and reason %s, got %#v", tc.description, tc.expectStatus, tc.expectReason, condition)
+		}
+	}
+}
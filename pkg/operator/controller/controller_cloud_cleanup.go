@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ForceCloudCleanupAnnotation may be set to "true" on an
+	// IngressController that is being deleted to have the operator delete
+	// its cloud load balancers itself instead of waiting for the cloud
+	// provider's service controller.  This keeps load balancers from
+	// leaking when the ingresscontroller is deleted while the cluster is
+	// torn down and the service controller is already gone.  The operator
+	// also cleans up this way, without the annotation, if the operand
+	// namespace is being deleted.
+	ForceCloudCleanupAnnotation = "ingress.operator.openshift.io/force-cloud-cleanup"
+
+	// serviceLoadBalancerCleanupFinalizer is the finalizer that the
+	// service controller adds to LB services so that it can delete their
+	// load balancers.
+	serviceLoadBalancerCleanupFinalizer = "service.kubernetes.io/load-balancer-cleanup"
+)
+
+// forceCloudCleanup returns true if the given ingresscontroller is annotated to
+// force the cleanup of its cloud resources or if the operand namespace is
+// being deleted.
+func (r *reconciler) forceCloudCleanup(ingress *operatorv1.IngressController) (bool, error) {
+	if force, err := strconv.ParseBool(ingress.Annotations[ForceCloudCleanupAnnotation]); err == nil && force {
+		return true, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: r.Config.OperandNamespace}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get namespace %s: %v", r.Config.OperandNamespace, err)
+	}
+	return ns.DeletionTimestamp != nil, nil
+}
+
+// forceLoadBalancerServicesCleanup deletes the cloud load balancers of the LB
+// services of the given ingresscontroller and then removes the services'
+// finalizers and deletes them, so that they are gone even if the service
+// controller is not running.  The wildcard DNS records must already have been
+// deleted.  If the operator cannot delete load balancers on the platform, the
+// services are deleted, but the service controller's finalizer is left for it
+// to delete the load balancers if it comes back.
+func (r *reconciler) forceLoadBalancerServicesCleanup(ci *operatorv1.IngressController) error {
+	for _, name := range []types.NamespacedName{
+		LoadBalancerServiceName(ci, r.Config.OperandNamespace),
+		InternalLoadBalancerServiceName(ci, r.Config.OperandNamespace),
+		AlternateLoadBalancerServiceName(ci, r.Config.OperandNamespace),
+	} {
+		service := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), name, service); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get service %s: %v", name, err)
+		}
+		if err := r.forceLoadBalancerServiceCleanup(service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forceLoadBalancerServiceCleanup deletes the cloud load balancer of the given
+// LB service if the operator can, and then deletes the service.  The service
+// controller's finalizer is removed only once the load balancer is confirmed to
+// be gone, so an error deleting it leaves the finalizer in place and the
+// cleanup is retried.
+func (r *reconciler) forceLoadBalancerServiceCleanup(service *corev1.Service) error {
+	finalizers := []string{LoadBalancerServiceFinalizer}
+	if r.Config.LoadBalancerDeleter == nil {
+		log.Info("cannot delete load balancers on this platform; leaving the load balancer to the service controller", "namespace", service.Namespace, "name", service.Name)
+	} else {
+		found, err := deleteServiceLoadBalancer(r.Config.LoadBalancerDeleter, service)
+		if err != nil {
+			return fmt.Errorf("failed to delete load balancer of service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+		if found {
+			return fmt.Errorf("waiting for load balancer of service %s/%s to be deleted", service.Namespace, service.Name)
+		}
+		finalizers = append(finalizers, serviceLoadBalancerCleanupFinalizer)
+	}
+
+	updated := service.DeepCopy()
+	for _, finalizer := range finalizers {
+		updated.Finalizers = slice.RemoveString(updated.Finalizers, finalizer)
+	}
+	if len(updated.Finalizers) != len(service.Finalizers) {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to remove finalizers from service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+	}
+	if service.DeletionTimestamp == nil {
+		if err := r.client.Delete(context.TODO(), updated); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+	}
+	log.Info("forced cleanup of load balancer service", "namespace", service.Namespace, "name", service.Name)
+	return nil
+}
+
+// deleteServiceLoadBalancer uses the given deleter to delete the cloud load
+// balancer of the given LB service, which it finds by the name that the cloud
+// provider gives it or by the hostnames in the service's status, and returns
+// true if it found one.  The status may be empty if the service controller
+// never published it, so the name is always used.
+func deleteServiceLoadBalancer(deleter dns.LoadBalancerDeleter, service *corev1.Service) (bool, error) {
+	var hostnames []string
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if len(ingress.Hostname) != 0 {
+			hostnames = append(hostnames, ingress.Hostname)
+		}
+	}
+	return deleter.DeleteLoadBalancer(cloudLoadBalancerName(service), hostnames)
+}
+
+// cloudLoadBalancerName returns the name that the cloud provider gives the load
+// balancer of the given LB service: "a" followed by the service's UID without
+// dashes, truncated to 32 characters.
+func cloudLoadBalancerName(service *corev1.Service) string {
+	if len(service.UID) == 0 {
+		return ""
+	}
+	name := "a" + strings.Replace(string(service.UID), "-", "", -1)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
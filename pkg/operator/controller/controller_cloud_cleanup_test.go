@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fakeLoadBalancerDeleter records the arguments of DeleteLoadBalancer and
+// returns the configured result.
+type fakeLoadBalancerDeleter struct {
+	found bool
+	err   error
+
+	name      string
+	hostnames []string
+}
+
+func (d *fakeLoadBalancerDeleter) DeleteLoadBalancer(name string, hostnames []string) (bool, error) {
+	d.name, d.hostnames = name, hostnames
+	return d.found, d.err
+}
+
+func TestCloudLoadBalancerName(t *testing.T) {
+	testCases := []struct {
+		description string
+		uid         string
+		expect      string
+	}{
+		{"no UID", "", ""},
+		{"UID", "4b0ba7d4-3d61-11e9-a7b4-0a580a800018", "a4b0ba7d43d6111e9a7b40a580a80001"},
+		{"short UID", "abc-def", "aabcdef"},
+	}
+	for _, tc := range testCases {
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{UID: types.UID(tc.uid)}}
+		if actual := cloudLoadBalancerName(service); actual != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestDeleteServiceLoadBalancer(t *testing.T) {
+	testCases := []struct {
+		description     string
+		ingress         []corev1.LoadBalancerIngress
+		deleter         *fakeLoadBalancerDeleter
+		expectHostnames []string
+		expectFound     bool
+		expectErr       bool
+	}{
+		{
+			description:     "load balancer with a hostname is found",
+			ingress:         []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			deleter:         &fakeLoadBalancerDeleter{found: true},
+			expectHostnames: []string{"lb.example.com"},
+			expectFound:     true,
+		},
+		{
+			description: "load balancer without a published status is looked up by name",
+			deleter:     &fakeLoadBalancerDeleter{found: true},
+			expectFound: true,
+		},
+		{
+			description:     "IP addresses are ignored",
+			ingress:         []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}, {Hostname: "lb.example.com"}},
+			deleter:         &fakeLoadBalancerDeleter{},
+			expectHostnames: []string{"lb.example.com"},
+		},
+		{
+			description:     "deletion fails",
+			ingress:         []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			deleter:         &fakeLoadBalancerDeleter{found: true, err: errors.New("AccessDenied")},
+			expectHostnames: []string{"lb.example.com"},
+			expectFound:     true,
+			expectErr:       true,
+		},
+	}
+	for _, tc := range testCases {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{UID: "abc-def"},
+			Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: tc.ingress}},
+		}
+		found, err := deleteServiceLoadBalancer(tc.deleter, service)
+		checkError(t, tc.description, tc.expectErr, err)
+		if found != tc.expectFound {
+			t.Errorf("%q: expected found %t, got %t", tc.description, tc.expectFound, found)
+		}
+		if tc.deleter.name != "aabcdef" {
+			t.Errorf("%q: expected name %q, got %q", tc.description, "aabcdef", tc.deleter.name)
+		}
+		if !reflect.DeepEqual(tc.deleter.hostnames, tc.expectHostnames) {
+			t.Errorf("%q: expected hostnames %v, got %v", tc.description, tc.expectHostnames, tc.deleter.hostnames)
+		}
+	}
+}
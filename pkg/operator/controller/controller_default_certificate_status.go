@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// DefaultCertificateServedConditionType is the type of the
+	// ingresscontroller condition that reports the fingerprint of the
+	// default certificate and whether the routers have rolled out the
+	// current contents of the certificate secret.  Administrators can use
+	// it to verify that a certificate rotation reached the routers.
+	DefaultCertificateServedConditionType = "DefaultCertificateServed"
+)
+
+// certificateFingerprint returns the SHA-256 fingerprint of the first
+// certificate in the given PEM data, which is the certificate that the router
+// serves, or of the raw data if it does not contain a PEM certificate.
+func certificateFingerprint(data []byte) string {
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			return fmt.Sprintf("%x", sha256.Sum256(block.Bytes))
+		}
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// computeDefaultCertificateServedCondition computes the DefaultCertificateServed
+// condition for the given ingresscontroller from its default certificate
+// secret and its current router deployment.
+func (r *reconciler) computeDefaultCertificateServedCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment) (*operatorv1.OperatorCondition, error) {
+	name := RouterEffectiveDefaultCertificateSecretName(ic, r.Config.OperandNamespace)
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), name, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get secret %s: %v", name, err)
+		}
+		secret = nil
+	}
+	// The router deployment's pod template has a hash of the mounted
+	// content, so recomputing the hash tells whether the template reflects
+	// the secret's current contents.
+	expected := deployment.DeepCopy()
+	if err := r.setMountedContentHash(expected); err != nil {
+		return nil, err
+	}
+	templateCurrent := expected.Spec.Template.Annotations[MountedContentHashAnnotation] == deployment.Spec.Template.Annotations[MountedContentHashAnnotation]
+	return defaultCertificateServedCondition(secret, templateCurrent, deployment), nil
+}
+
+// defaultCertificateServedCondition returns the DefaultCertificateServed
+// condition for the given default certificate secret, which may be nil if it
+// does not exist, and router deployment.  templateCurrent is true if the
+// deployment's pod template reflects the secret's current contents.
+func defaultCertificateServedCondition(secret *corev1.Secret, templateCurrent bool, deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type: DefaultCertificateServedConditionType,
+	}
+	if secret == nil {
+		condition.Status = operatorv1.ConditionUnknown
+		condition.Reason = "SecretNotFound"
+		condition.Message = "The default certificate secret does not exist"
+		return condition
+	}
+	certificate := fmt.Sprintf("certificate with SHA-256 fingerprint %s from secret %s/%s at resourceVersion %s", certificateFingerprint(secret.Data[corev1.TLSCertKey]), secret.Namespace, secret.Name, secret.ResourceVersion)
+	switch {
+	case !templateCurrent:
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "Pending"
+		condition.Message = "The router deployment has not been updated for the " + certificate
+	case computeDeploymentRollingOutCondition(deployment).Status != operatorv1.ConditionFalse:
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "RollingOut"
+		condition.Message = "The routers are rolling out the " + certificate
+	default:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "Served"
+		condition.Message = "The routers serve the " + certificate
+	}
+	return condition
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCertificateFingerprint(t *testing.T) {
+	der := []byte("not really DER")
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	if expected, actual := fmt.Sprintf("%x", sha256.Sum256(der)), certificateFingerprint(data); actual != expected {
+		t.Errorf("expected fingerprint %s, got %s", expected, actual)
+	}
+	raw := []byte("garbage")
+	if expected, actual := fmt.Sprintf("%x", sha256.Sum256(raw)), certificateFingerprint(raw); actual != expected {
+		t.Errorf("expected fingerprint of raw data %s, got %s", expected, actual)
+	}
+}
+
+func TestDefaultCertificateServedCondition(t *testing.T) {
+	one := int32(1)
+	rolledOut := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &one},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+	rollingOut := rolledOut.DeepCopy()
+	rollingOut.Status.Replicas = 2
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-certs-default", ResourceVersion: "42"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+
+	testCases := []struct {
+		description     string
+		secret          *corev1.Secret
+		templateCurrent bool
+		deployment      *appsv1.Deployment
+		expectStatus    operatorv1.ConditionStatus
+		expectReason    string
+	}{
+		{
+			description:     "secret missing",
+			templateCurrent: true,
+			deployment:      rolledOut,
+			expectStatus:    operatorv1.ConditionUnknown,
+			expectReason:    "SecretNotFound",
+		},
+		{
+			description:     "template not updated",
+			secret:          secret,
+			templateCurrent: false,
+			deployment:      rolledOut,
+			expectStatus:    operatorv1.ConditionFalse,
+			expectReason:    "Pending",
+		},
+		{
+			description:     "rolling out",
+			secret:          secret,
+			templateCurrent: true,
+			deployment:      rollingOut,
+			expectStatus:    operatorv1.ConditionFalse,
+			expectReason:    "RollingOut",
+		},
+		{
+			description:     "served",
+			secret:          secret,
+			templateCurrent: true,
+			deployment:      rolledOut,
+			expectStatus:    operatorv1.ConditionTrue,
+			expectReason:    "Served",
+		},
+	}
+	for _, tc := range testCases {
+		condition := defaultCertificateServedCondition(tc.secret, tc.templateCurrent, tc.deployment)
+		if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason {
+			t.Errorf("%q: expected %s/%s, got %s/%s", tc.description, tc.expectStatus, tc.expectReason, condition.Status, condition.Reason)
+		}
+		if tc.secret != nil && !strings.Contains(condition.Message, "resourceVersion 42") {
+			t.Errorf("%q: expected the message to report the secret's resourceVersion, got %q", tc.description, condition.Message)
+		}
+	}
+}
@@ -1,78 +1,234 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"reflect"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
-	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
 
 	corev1 "k8s.io/api/core/v1"
 
-	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-// ensureDNS will create DNS records for the given LB service. If service is
-// nil, nothing is done.
-func (r *reconciler) ensureDNS(ci *operatorv1.IngressController, service *corev1.Service, dnsConfig *configv1.DNS) error {
+const (
+	// DNSRecordFinalizer is applied to DNSRecords so that the dnsrecord
+	// controller can delete the published records from the DNS provider
+	// before the DNSRecord is deleted.
+	DNSRecordFinalizer = "operator.openshift.io/ingress-dns"
+)
+
+// ensureDNS ensures that the wildcard DNSRecord for the given ingresscontroller
+// points to the given LB service.  The dnsrecord controller publishes the
+// record to the cluster's DNS zones.  If the ingresscontroller uses
+// external-dns, which publishes the record from the LB service's annotations,
+// ensureDNS instead ensures that only the given LB service has the
+// annotations and that the DNSRecord is deleted.
+func (r *reconciler) ensureDNS(ci *operatorv1.IngressController, service *corev1.Service) error {
+	if usesExternalDNS(ci) {
+		if err := r.ensureExternalDNSTarget(ci, service, desiredExternalDNSAnnotations(ci)); err != nil {
+			return err
+		}
+		return r.ensureWildcardRecordDeleted(ci, false)
+	}
+	// external-dns deletes the records that it published when the
+	// annotations are removed, including one that the dnsrecord controller
+	// has since published under the same name, so the annotations must be
+	// gone before the DNSRecord is created.
+	if err := r.ensureExternalDNSTarget(ci, nil, nil); err != nil {
+		return err
+	}
+
 	// If no load balancer has been provisioned, we can't do anything with the
 	// configured DNS zones.
-	ingress := service.Status.LoadBalancer.Ingress
-	if len(ingress) == 0 || len(ingress[0].Hostname) == 0 {
+	if !isLoadBalancerProvisioned(service) {
 		return fmt.Errorf("no load balancer is assigned to service %s/%s", service.Namespace, service.Name)
 	}
 
-	dnsRecords, err := desiredDNSRecords(ci, ingress[0].Hostname, dnsConfig)
+	desired := desiredWildcardRecord(ci, loadBalancerAddress(service))
+	if desired == nil {
+		return nil
+	}
+	current, err := r.currentWildcardRecord(ci)
 	if err != nil {
 		return err
 	}
-	for _, record := range dnsRecords {
-		err := r.DNSManager.Ensure(record)
-		if err != nil {
-			return fmt.Errorf("failed to ensure DNS record %v for %s/%s: %v", record, ci.Namespace, ci.Name, err)
+	// The dnsrecord controller publishes only a DNSRecord's current name
+	// and routing policy, so if the ingresscontroller's domain changed, or
+	// its routing policy identifies a different record, the old DNSRecord
+	// must be finalized, which deletes the old record from the zones,
+	// before the new DNSRecord is created.
+	if current != nil && (current.Spec.DNSName != desired.Spec.DNSName || dnsRoutingPolicyIdentityChanged(current.Spec.RoutingPolicy, desired.Spec.RoutingPolicy)) {
+		if err := r.ensureWildcardRecordDeleted(ci, false); err != nil {
+			return err
 		}
-		log.Info("ensured DNS record for ingresscontroller", "namespace", ci.Namespace, "name", ci.Name, "record", record)
+		current = nil
 	}
-	return nil
+	return r.ensureDNSRecord(current, desired)
 }
 
-// desiredDNSRecords will return any necessary DNS records for the given inputs.
-// If an ingress domain is in use, records are desired in every specified zone
-// present in the cluster DNS configuration.
-func desiredDNSRecords(ci *operatorv1.IngressController, hostname string, dnsConfig *configv1.DNS) ([]*dns.Record, error) {
-	records := []*dns.Record{}
+// ensureDNSRecord creates the given desired DNSRecord if the given current one
+// is nil and otherwise updates the current one if its spec differs.
+func (r *reconciler) ensureDNSRecord(current, desired *ingressv1.DNSRecord) error {
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create dnsrecord %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created dnsrecord", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if changed, updated := dnsRecordChanged(current, desired); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update dnsrecord %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		log.Info("updated dnsrecord", "namespace", updated.Namespace, "name", updated.Name)
+	}
+	return nil
+}
 
+// desiredWildcardRecord returns the desired wildcard DNSRecord for the given
+// ingresscontroller and LB hostname or IP address, or nil if the
+// ingresscontroller does not need DNS records.
+func desiredWildcardRecord(ci *operatorv1.IngressController, target string) *ingressv1.DNSRecord {
 	// If the ingresscontroller has no ingress domain, we cannot configure any
 	// DNS records.
 	if len(ci.Status.Domain) == 0 {
-		return records, nil
+		return nil
 	}
 
 	// If the HA type is not cloud, then we don't manage DNS.
 	if ci.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
-		return records, nil
+		return nil
 	}
 
-	// If no zones are configured, there's nothing to do.
-	if dnsConfig.Spec.PrivateZone == nil && dnsConfig.Spec.PublicZone == nil {
-		return records, nil
+	// If external-dns publishes the record, there is no DNSRecord.
+	if usesExternalDNS(ci) {
+		return nil
 	}
 
-	domain := fmt.Sprintf("*.%s", ci.Status.Domain)
-	makeRecord := func(zone *configv1.DNSZone) *dns.Record {
-		return &dns.Record{
-			Zone: *zone,
-			Type: dns.ALIASRecord,
-			Alias: &dns.AliasRecord{
-				Domain: domain,
-				Target: hostname,
+	record := wildcardRecordFor(ci, WildcardDNSRecordName(ci), target)
+	// With an additional internal load balancer, the private zone resolves
+	// the domain to the internal load balancer instead.
+	if enabled, err := internalLoadBalancerEnabled(ci); err == nil && enabled {
+		record.Spec.ZoneType = ingressv1.PublicZoneType
+	}
+	// An invalid routing policy, or one on a platform that does not
+	// support it, is reported by admission, which keeps the operands from
+	// being reconciled.
+	if policy, err := parseDNSRoutingPolicy(ci); err == nil {
+		record.Spec.RoutingPolicy = policy
+	}
+	return record
+}
+
+// wildcardRecordFor returns a DNSRecord with the given name that resolves the
+// wildcard domain of the given ingresscontroller to the given LB hostname, with
+// a CNAME record, or IP address, with an A record.
+func wildcardRecordFor(ci *operatorv1.IngressController, name types.NamespacedName, target string) *ingressv1.DNSRecord {
+	trueVar := true
+	recordType := ingressv1.CNAMERecordType
+	if net.ParseIP(target) != nil {
+		recordType = ingressv1.ARecordType
+	}
+	return &ingressv1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ci.Name,
 			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: operatorv1.GroupVersion.String(),
+				Kind:       "IngressController",
+				Name:       ci.Name,
+				UID:        ci.UID,
+				Controller: &trueVar,
+			}},
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: ingressv1.DNSRecordSpec{
+			DNSName:    fmt.Sprintf("*.%s", ci.Status.Domain),
+			RecordType: recordType,
+			Targets:    []string{target},
+			DryRun:     dnsDryRunEnabled(ci),
+		},
+	}
+}
+
+// dnsRecordChanged checks whether the current DNSRecord's spec matches the
+// expected spec and if not returns an updated DNSRecord.
+func dnsRecordChanged(current, expected *ingressv1.DNSRecord) (bool, *ingressv1.DNSRecord) {
+	if reflect.DeepEqual(current.Spec, expected.Spec) {
+		return false, nil
+	}
+	updated := current.DeepCopy()
+	updated.Spec = expected.Spec
+	return true, updated
+}
+
+// currentWildcardRecord returns the current wildcard DNSRecord for the given
+// ingresscontroller, or nil if it does not exist.
+func (r *reconciler) currentWildcardRecord(ci *operatorv1.IngressController) (*ingressv1.DNSRecord, error) {
+	return r.currentDNSRecord(WildcardDNSRecordName(ci))
+}
+
+// currentDNSRecord returns the DNSRecord with the given name, or nil if it does
+// not exist.
+func (r *reconciler) currentDNSRecord(name types.NamespacedName) (*ingressv1.DNSRecord, error) {
+	record := &ingressv1.DNSRecord{}
+	if err := r.client.Get(context.TODO(), name, record); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to get dnsrecord %s: %v", name, err)
 	}
-	if dnsConfig.Spec.PrivateZone != nil {
-		records = append(records, makeRecord(dnsConfig.Spec.PrivateZone))
+	return record, nil
+}
+
+// ensureWildcardRecordDeleted deletes the wildcard DNSRecord for the given
+// ingresscontroller and returns an error until the dnsrecord controller has
+// finalized it.  If skipDNS is true, the DNSRecord's finalizer is removed so
+// that the published records are left as they are.
+func (r *reconciler) ensureWildcardRecordDeleted(ci *operatorv1.IngressController, skipDNS bool) error {
+	return r.ensureDNSRecordDeleted(WildcardDNSRecordName(ci), skipDNS)
+}
+
+// ensureDNSRecordDeleted deletes the DNSRecord with the given name as
+// ensureWildcardRecordDeleted does.
+func (r *reconciler) ensureDNSRecordDeleted(name types.NamespacedName, skipDNS bool) error {
+	record, err := r.currentDNSRecord(name)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return nil
+	}
+	if skipDNS && slice.ContainsString(record.Finalizers, DNSRecordFinalizer) {
+		updated := record.DeepCopy()
+		updated.Finalizers = slice.RemoveString(updated.Finalizers, DNSRecordFinalizer)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to remove finalizer from dnsrecord %s/%s: %v", record.Namespace, record.Name, err)
+		}
+		record = updated
+	}
+	if record.DeletionTimestamp == nil {
+		if err := r.client.Delete(context.TODO(), record); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to delete dnsrecord %s/%s: %v", record.Namespace, record.Name, err)
+		}
+		log.Info("deleted dnsrecord", "namespace", record.Namespace, "name", record.Name)
 	}
-	if dnsConfig.Spec.PublicZone != nil {
-		records = append(records, makeRecord(dnsConfig.Spec.PublicZone))
+	if len(record.Finalizers) == 0 {
+		return nil
 	}
-	return records, nil
+	return fmt.Errorf("waiting for dnsrecord %s/%s to be finalized", record.Namespace, record.Name)
 }
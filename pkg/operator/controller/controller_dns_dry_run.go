@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	// DNSDryRunAnnotation may be set on an ingresscontroller to "Enabled"
+	// to have the dnsrecord controller plan the changes to the cluster's
+	// DNS zones that the ingresscontroller's wildcard DNS records require
+	// instead of applying them.  The planned changes are reported in
+	// status.zones[].plannedChange of the ingresscontroller's DNSRecords
+	// in the operator namespace for an administrator to review and apply.
+	DNSDryRunAnnotation = "ingress.operator.openshift.io/dns-dry-run"
+
+	// dnsDryRunEnabledValue and dnsDryRunDisabledValue are the values of
+	// DNSDryRunAnnotation.
+	dnsDryRunEnabledValue  = "Enabled"
+	dnsDryRunDisabledValue = "Disabled"
+)
+
+// validateDNSDryRun returns an error if the given ingresscontroller has an
+// invalid value for DNSDryRunAnnotation.
+func validateDNSDryRun(ic *operatorv1.IngressController) error {
+	switch value := ic.Annotations[DNSDryRunAnnotation]; value {
+	case "", dnsDryRunEnabledValue, dnsDryRunDisabledValue:
+		return nil
+	default:
+		return fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", DNSDryRunAnnotation, value, dnsDryRunEnabledValue, dnsDryRunDisabledValue)
+	}
+}
+
+// dnsDryRunEnabled returns true if the given ingresscontroller requests that
+// its DNS records be planned rather than published.
+func dnsDryRunEnabled(ic *operatorv1.IngressController) bool {
+	return ic.Annotations[DNSDryRunAnnotation] == dnsDryRunEnabledValue
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// TestDNSDryRun verifies that DNSDryRunAnnotation is validated and makes the
+// wildcard DNSRecord a dry run.
+func TestDNSDryRun(t *testing.T) {
+	testCases := []struct {
+		description  string
+		annotations  map[string]string
+		expectDryRun bool
+		expectErr    bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description:  "enabled",
+			annotations:  map[string]string{DNSDryRunAnnotation: "Enabled"},
+			expectDryRun: true,
+		},
+		{
+			description: "disabled",
+			annotations: map[string]string{DNSDryRunAnnotation: "Disabled"},
+		},
+		{
+			description: "invalid value",
+			annotations: map[string]string{DNSDryRunAnnotation: "true"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Namespace = "openshift-ingress-operator"
+		ic.Name = "default"
+		ic.Annotations = tc.annotations
+		ic.Status.Domain = "apps.example.com"
+		ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+			Type: operatorv1.LoadBalancerServiceStrategyType,
+		}
+		err := validateDNSDryRun(ic)
+		checkError(t, tc.description, tc.expectErr, err)
+		if record := desiredWildcardRecord(ic, "lb.example.com"); record.Spec.DryRun != tc.expectDryRun {
+			t.Errorf("%q: expected dryRun %t, got %t", tc.description, tc.expectDryRun, record.Spec.DryRun)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+)
+
+const (
+	// DNSRoutingPolicyAnnotation may be set on an ingresscontroller that
+	// uses the LoadBalancerService endpoint publishing strategy to publish
+	// its wildcard DNS record with a routing policy, so that clusters that
+	// serve the same domain, such as an active-passive pair, can share the
+	// record.  The value is the JSON representation of the DNSRecord
+	// spec.routingPolicy field, for example:
+	//
+	//   {"type": "Failover", "setIdentifier": "east", "failover": {"role": "Primary"}}
+	//
+	// The routing policy is only supported on AWS, and the annotation is
+	// rejected on other platforms.
+	DNSRoutingPolicyAnnotation = "ingress.operator.openshift.io/dns-routing-policy"
+
+	// maxDNSRoutingPolicyWeight is the largest weight that Route 53
+	// accepts.
+	maxDNSRoutingPolicyWeight = 255
+	// maxSetIdentifierLength is the longest set identifier that Route 53
+	// accepts.
+	maxSetIdentifierLength = 128
+)
+
+// dnsRoutingPolicyFor returns the routing policy of the wildcard DNS record of
+// the given ingresscontroller, or nil if it does not specify one.  Only the AWS
+// DNS provider publishes routing policies; the others would publish a plain
+// record that replaces the records of the other clusters that share the name,
+// so the annotation is rejected on other platforms.
+func dnsRoutingPolicyFor(ic *operatorv1.IngressController, platform configv1.PlatformType) (*ingressv1.DNSRoutingPolicy, error) {
+	value, ok := ic.Annotations[DNSRoutingPolicyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	if platform != configv1.AWSPlatformType {
+		return nil, fmt.Errorf("invalid value for annotation %s: %q: routing policies are not supported on platform %q", DNSRoutingPolicyAnnotation, value, platform)
+	}
+	return parseDNSRoutingPolicy(ic)
+}
+
+// parseDNSRoutingPolicy returns the routing policy of the wildcard DNS record
+// of the given ingresscontroller, or nil if it does not specify one, regardless
+// of the platform.
+func parseDNSRoutingPolicy(ic *operatorv1.IngressController) (*ingressv1.DNSRoutingPolicy, error) {
+	value, ok := ic.Annotations[DNSRoutingPolicyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	if strategy := ic.Status.EndpointPublishingStrategy; strategy != nil && strategy.Type != operatorv1.LoadBalancerServiceStrategyType {
+		return nil, fmt.Errorf("invalid value for annotation %s: %q: a routing policy requires the %s endpoint publishing strategy", DNSRoutingPolicyAnnotation, value, operatorv1.LoadBalancerServiceStrategyType)
+	}
+	if usesExternalDNS(ic) {
+		return nil, fmt.Errorf("invalid value for annotation %s: %q: a routing policy cannot be used with annotation %s=%s", DNSRoutingPolicyAnnotation, value, DNSManagementAnnotation, ExternalDNSDNSManagement)
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	policy := &ingressv1.DNSRoutingPolicy{}
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", DNSRoutingPolicyAnnotation, err)
+	}
+	if err := ValidateDNSRoutingPolicy(policy); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", DNSRoutingPolicyAnnotation, err)
+	}
+	return policy, nil
+}
+
+// ValidateDNSRoutingPolicy returns an error if the given DNSRecord routing
+// policy is invalid.  A nil policy is valid.
+func ValidateDNSRoutingPolicy(policy *ingressv1.DNSRoutingPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if len(policy.SetIdentifier) == 0 || len(policy.SetIdentifier) > maxSetIdentifierLength {
+		return fmt.Errorf("setIdentifier must have from 1 to %d characters", maxSetIdentifierLength)
+	}
+	set := 0
+	for _, p := range []bool{policy.Weighted != nil, policy.Failover != nil, policy.Geolocation != nil} {
+		if p {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("only the field for type %q may be set", policy.Type)
+	}
+	switch policy.Type {
+	case ingressv1.WeightedRoutingPolicyType:
+		if policy.Weighted == nil {
+			return fmt.Errorf("weighted must be set for type %q", policy.Type)
+		}
+		if policy.Weighted.Weight < 0 || policy.Weighted.Weight > maxDNSRoutingPolicyWeight {
+			return fmt.Errorf("weight must be from 0 to %d, got %d", maxDNSRoutingPolicyWeight, policy.Weighted.Weight)
+		}
+	case ingressv1.FailoverRoutingPolicyType:
+		if policy.Failover == nil {
+			return fmt.Errorf("failover must be set for type %q", policy.Type)
+		}
+		if role := policy.Failover.Role; role != ingressv1.PrimaryFailoverRole && role != ingressv1.SecondaryFailoverRole {
+			return fmt.Errorf("role must be %q or %q, got %q", ingressv1.PrimaryFailoverRole, ingressv1.SecondaryFailoverRole, role)
+		}
+	case ingressv1.GeolocationRoutingPolicyType:
+		if policy.Geolocation == nil {
+			return fmt.Errorf("geolocation must be set for type %q", policy.Type)
+		}
+		location := policy.Geolocation
+		if (len(location.ContinentCode) == 0) == (len(location.CountryCode) == 0) {
+			return fmt.Errorf("exactly one of continentCode or countryCode must be set")
+		}
+		if len(location.SubdivisionCode) != 0 && len(location.CountryCode) == 0 {
+			return fmt.Errorf("subdivisionCode requires countryCode")
+		}
+	default:
+		return fmt.Errorf("type must be %q, %q, or %q, got %q", ingressv1.WeightedRoutingPolicyType, ingressv1.FailoverRoutingPolicyType, ingressv1.GeolocationRoutingPolicyType, policy.Type)
+	}
+	return nil
+}
+
+// dnsRoutingPolicyIdentityChanged returns true if the given routing policies
+// publish different records.  DNS providers identify a record that has a
+// routing policy by its set identifier and cannot change the type of its
+// policy, so such a change requires deleting the old record before the new
+// one is published, whereas other changes, such as a new weight, update the
+// record in place.
+func dnsRoutingPolicyIdentityChanged(current, desired *ingressv1.DNSRoutingPolicy) bool {
+	if current == nil || desired == nil {
+		return current != desired
+	}
+	return current.Type != desired.Type || current.SetIdentifier != desired.SetIdentifier
+}
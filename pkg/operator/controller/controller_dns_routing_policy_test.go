@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+)
+
+func TestDNSRoutingPolicyFor(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		platform    configv1.PlatformType
+		expect      *ingressv1.DNSRoutingPolicy
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+		},
+		{
+			description: "weighted",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Weighted", "setIdentifier": "east", "weighted": {"weight": 10}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect: &ingressv1.DNSRoutingPolicy{
+				Type:          ingressv1.WeightedRoutingPolicyType,
+				SetIdentifier: "east",
+				Weighted:      &ingressv1.WeightedDNSRoutingPolicy{Weight: 10},
+			},
+		},
+		{
+			description: "failover with a health check",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Failover", "setIdentifier": "west", "failover": {"role": "Secondary", "healthCheckID": "abc"}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect: &ingressv1.DNSRoutingPolicy{
+				Type:          ingressv1.FailoverRoutingPolicyType,
+				SetIdentifier: "west",
+				Failover:      &ingressv1.FailoverDNSRoutingPolicy{Role: ingressv1.SecondaryFailoverRole, HealthCheckID: "abc"},
+			},
+		},
+		{
+			description: "geolocation with a subdivision",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Geolocation", "setIdentifier": "us-wa", "geolocation": {"countryCode": "US", "subdivisionCode": "WA"}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect: &ingressv1.DNSRoutingPolicy{
+				Type:          ingressv1.GeolocationRoutingPolicyType,
+				SetIdentifier: "us-wa",
+				Geolocation:   &ingressv1.GeolocationDNSRoutingPolicy{CountryCode: "US", SubdivisionCode: "WA"},
+			},
+		},
+		{
+			description: "no annotation on Azure",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.AzurePlatformType,
+		},
+		{
+			description: "weighted on Azure",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Weighted", "setIdentifier": "east", "weighted": {"weight": 10}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.AzurePlatformType,
+			expectErr:   true,
+		},
+		{
+			description: "host network",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Weighted", "setIdentifier": "east", "weighted": {"weight": 10}}`},
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expectErr:   true,
+		},
+		{
+			description: "external-dns",
+			annotations: map[string]string{
+				DNSRoutingPolicyAnnotation: `{"type": "Weighted", "setIdentifier": "east", "weighted": {"weight": 10}}`,
+				DNSManagementAnnotation:    ExternalDNSDNSManagement,
+			},
+			strategy:  operatorv1.LoadBalancerServiceStrategyType,
+			expectErr: true,
+		},
+		{
+			description: "missing set identifier",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Weighted", "weighted": {"weight": 10}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+		{
+			description: "weight too large",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Weighted", "setIdentifier": "east", "weighted": {"weight": 256}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+		{
+			description: "missing policy for type",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Failover", "setIdentifier": "east", "weighted": {"weight": 1}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+		{
+			description: "invalid failover role",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Failover", "setIdentifier": "east", "failover": {"role": "Tertiary"}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+		{
+			description: "geolocation with continent and country",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Geolocation", "setIdentifier": "eu", "geolocation": {"continentCode": "EU", "countryCode": "DE"}}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+		{
+			description: "unknown type",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Latency", "setIdentifier": "east"}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+		{
+			description: "unknown field",
+			annotations: map[string]string{DNSRoutingPolicyAnnotation: `{"type": "Weighted", "setIdentifier": "east", "weight": 10}`},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{Type: tc.strategy}
+		platform := tc.platform
+		if len(platform) == 0 {
+			platform = configv1.AWSPlatformType
+		}
+		policy, err := dnsRoutingPolicyFor(ic, platform)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(policy, tc.expect) {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, policy)
+		}
+	}
+}
+
+func TestDNSRoutingPolicyIdentityChanged(t *testing.T) {
+	weighted := func(id string, weight int64) *ingressv1.DNSRoutingPolicy {
+		return &ingressv1.DNSRoutingPolicy{
+			Type:          ingressv1.WeightedRoutingPolicyType,
+			SetIdentifier: id,
+			Weighted:      &ingressv1.WeightedDNSRoutingPolicy{Weight: weight},
+		}
+	}
+	failover := &ingressv1.DNSRoutingPolicy{
+		Type:          ingressv1.FailoverRoutingPolicyType,
+		SetIdentifier: "east",
+		Failover:      &ingressv1.FailoverDNSRoutingPolicy{Role: ingressv1.PrimaryFailoverRole},
+	}
+	testCases := []struct {
+		description      string
+		current, desired *ingressv1.DNSRoutingPolicy
+		expect           bool
+	}{
+		{"no policies", nil, nil, false},
+		{"policy added", nil, weighted("east", 1), true},
+		{"policy removed", weighted("east", 1), nil, true},
+		{"weight changed", weighted("east", 1), weighted("east", 2), false},
+		{"set identifier changed", weighted("east", 1), weighted("west", 1), true},
+		{"type changed", weighted("east", 1), failover, true},
+	}
+	for _, tc := range testCases {
+		if actual := dnsRoutingPolicyIdentityChanged(tc.current, tc.desired); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+)
+
+func TestDesiredWildcardRecord(t *testing.T) {
+	ci := &operatorv1.IngressController{}
+	ci.Namespace = "openshift-ingress-operator"
+	ci.Name = "default"
+	ci.Status.Domain = "apps.example.com"
+	ci.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+		Type: operatorv1.LoadBalancerServiceStrategyType,
+	}
+
+	record := desiredWildcardRecord(ci, "lb.example.com")
+	if record == nil {
+		t.Fatal("expected a dnsrecord")
+	}
+	if record.Namespace != ci.Namespace || record.Name != "default-wildcard" {
+		t.Errorf("unexpected name %s/%s", record.Namespace, record.Name)
+	}
+	if record.Spec.DNSName != "*.apps.example.com" || len(record.Spec.Targets) != 1 || record.Spec.Targets[0] != "lb.example.com" {
+		t.Errorf("unexpected spec %v", record.Spec)
+	}
+	if changed, _ := dnsRecordChanged(record, record); changed {
+		t.Errorf("expected dnsRecordChanged to return false for identical records")
+	}
+	other := desiredWildcardRecord(ci, "lb2.example.com")
+	if changed, updated := dnsRecordChanged(record, other); !changed {
+		t.Errorf("expected dnsRecordChanged to return true for a new target")
+	} else if changed, _ := dnsRecordChanged(updated, other); changed {
+		t.Errorf("expected dnsRecordChanged to return false for the updated record")
+	}
+
+	if record.Spec.RecordType != ingressv1.CNAMERecordType {
+		t.Errorf("expected a CNAME record for an LB hostname, got %s", record.Spec.RecordType)
+	}
+	if record := desiredWildcardRecord(ci, "192.0.2.1"); record.Spec.RecordType != ingressv1.ARecordType || record.Spec.Targets[0] != "192.0.2.1" {
+		t.Errorf("expected an A record for an LB IP address, got %v", record.Spec)
+	}
+
+	ci.Status.EndpointPublishingStrategy.Type = operatorv1.HostNetworkStrategyType
+	if record := desiredWildcardRecord(ci, "lb.example.com"); record != nil {
+		t.Errorf("expected no dnsrecord for the host network strategy, got %v", record)
+	}
+}
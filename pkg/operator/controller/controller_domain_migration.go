@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	// DomainMigrationProgressingConditionType is the type of the
+	// ingresscontroller condition that reports the migration of an
+	// admitted ingresscontroller to a new spec.domain.  While the
+	// migration progresses, the condition is true; it becomes false once
+	// the routers have rolled out with the new domain and the wildcard DNS
+	// record for the new domain is published, or if the new domain is
+	// rejected.  The operator-generated default certificate is regenerated
+	// for the new domain as part of the rollout.
+	DomainMigrationProgressingConditionType = "DomainMigrationProgressing"
+)
+
+// migrateIngressDomain publishes a changed spec.domain of the given admitted
+// ingresscontroller to its status if the new domain is not in use by another
+// ingresscontroller.  All operands derive from the domain in status, so
+// publishing it starts the migration: the router deployment rolls out with the
+// new canonical hostname, the wildcard DNSRecord is replaced, and the default
+// certificate is regenerated.  The caller must hold the admission lock.
+func (r *reconciler) migrateIngressDomain(ic *operatorv1.IngressController) error {
+	domain := ic.Spec.Domain
+	if len(domain) == 0 || domain == ic.Status.Domain {
+		return nil
+	}
+	updated := ic.DeepCopy()
+	unique, err := r.isDomainUnique(domain)
+	if err != nil {
+		return err
+	}
+	var condition *operatorv1.OperatorCondition
+	if unique {
+		condition = &operatorv1.OperatorCondition{
+			Type:    DomainMigrationProgressingConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "DomainChanged",
+			Message: fmt.Sprintf("The domain is changing from %q to %q", ic.Status.Domain, domain),
+		}
+		updated.Status.Domain = domain
+	} else {
+		condition = &operatorv1.OperatorCondition{
+			Type:    DomainMigrationProgressingConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "DomainConflict",
+			Message: fmt.Sprintf("The domain cannot change to %q because another ingresscontroller uses it; the domain remains %q", domain, ic.Status.Domain),
+		}
+	}
+	updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, condition)
+	if ingressStatusesEqual(updated.Status, ic.Status) {
+		return nil
+	}
+	if err := r.client.PatchStatus(context.TODO(), updated, operatorclient.MergeFromWithOptimisticLock(ic)); err != nil {
+		return fmt.Errorf("failed to update status of IngressController %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	if unique {
+		log.Info("migrating ingresscontroller to new domain", "namespace", ic.Namespace, "name", ic.Name, "old", ic.Status.Domain, "new", domain)
+		r.recorder.Eventf(ic, "Normal", "DomainChanged", "The domain is changing from %q to %q", ic.Status.Domain, domain)
+	} else {
+		r.recorder.Event(ic, "Warning", "DomainConflict", condition.Message)
+	}
+	updated.DeepCopyInto(ic)
+	return nil
+}
+
+// computeDomainMigrationCondition returns the DomainMigrationProgressing
+// condition for the given ingresscontroller and its router deployment, or nil
+// if the ingresscontroller's domain has never changed or a rejected change was
+// reverted.  A migration in progress completes once the router deployment has
+// rolled out and the wildcard DNS record, if the ingresscontroller has one, is
+// published with the new domain.
+func (r *reconciler) computeDomainMigrationCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment) (*operatorv1.OperatorCondition, error) {
+	current := findIngressStatusCondition(ic.Status.Conditions, DomainMigrationProgressingConditionType)
+	if current != nil && current.Reason == "DomainConflict" && (len(ic.Spec.Domain) == 0 || ic.Spec.Domain == ic.Status.Domain) {
+		// The conflicting domain change was reverted.
+		return nil, nil
+	}
+	if current == nil || current.Status != operatorv1.ConditionTrue {
+		return current, nil
+	}
+	var record *ingressv1.DNSRecord
+	if desiredWildcardRecord(ic, "") != nil {
+		var err error
+		if record, err = r.currentWildcardRecord(ic); err != nil {
+			return nil, err
+		}
+		if record == nil {
+			return domainMigrationCondition(ic, deployment, false), nil
+		}
+	}
+	return domainMigrationCondition(ic, deployment, record == nil || isRecordPublishedWithName(record, "*."+ic.Status.Domain)), nil
+}
+
+// domainMigrationCondition returns the DomainMigrationProgressing condition for
+// a migration to the given ingresscontroller's domain, given its router
+// deployment and whether its wildcard DNS record is published with the domain.
+func domainMigrationCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment, recordPublished bool) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   DomainMigrationProgressingConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "DomainChanged",
+	}
+	switch rollingOut := computeDeploymentRollingOutCondition(deployment); {
+	case rollingOut.Status == operatorv1.ConditionTrue:
+		condition.Message = fmt.Sprintf("The domain is changing to %q: waiting for the routers to roll out: %s", ic.Status.Domain, rollingOut.Message)
+	case !recordPublished:
+		condition.Message = fmt.Sprintf("The domain is changing to %q: waiting for the wildcard DNS record to be published", ic.Status.Domain)
+	default:
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "DomainMigrated"
+		condition.Message = fmt.Sprintf("The domain has changed to %q", ic.Status.Domain)
+	}
+	return condition
+}
+
+// isRecordPublishedWithName returns true if the given DNSRecord has the given
+// name and the dnsrecord controller has published its current generation to
+// every zone.
+func isRecordPublishedWithName(record *ingressv1.DNSRecord, name string) bool {
+	if record.Spec.DNSName != name || len(record.Spec.Targets) == 0 {
+		return false
+	}
+	return isRecordPublishedWithTarget(record, record.Spec.Targets[0])
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDomainMigrationCondition verifies that a domain migration is reported as
+// progressing until the routers have rolled out and the wildcard DNS record is
+// published with the new domain.
+func TestDomainMigrationCondition(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		Status: operatorv1.IngressControllerStatus{Domain: "apps.new.example.com"},
+	}
+	replicas := int32(2)
+	rolledOut := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+		},
+	}
+	rollingOut := rolledOut.DeepCopy()
+	rollingOut.Status.UpdatedReplicas = 1
+
+	testCases := []struct {
+		description     string
+		deployment      *appsv1.Deployment
+		recordPublished bool
+		expectStatus    operatorv1.ConditionStatus
+		expectReason    string
+	}{
+		{"routers rolling out", rollingOut, true, operatorv1.ConditionTrue, "DomainChanged"},
+		{"record not published", rolledOut, false, operatorv1.ConditionTrue, "DomainChanged"},
+		{"migration complete", rolledOut, true, operatorv1.ConditionFalse, "DomainMigrated"},
+	}
+	for _, tc := range testCases {
+		condition := domainMigrationCondition(ic, tc.deployment, tc.recordPublished)
+		if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason {
+			t.Errorf("%q: expected status %s and reason %s, got %s and %s", tc.description, tc.expectStatus, tc.expectReason, condition.Status, condition.Reason)
+		}
+	}
+}
+
+// TestIsRecordPublishedWithName verifies that a wildcard DNS record is only
+// considered published for the new domain once it has the new name and its
+// current generation is published.
+func TestIsRecordPublishedWithName(t *testing.T) {
+	recordFor := func(name string, observedGeneration int64, published string) *ingressv1.DNSRecord {
+		return &ingressv1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Spec: ingressv1.DNSRecordSpec{
+				DNSName: name,
+				Targets: []string{"lb.example.com"},
+			},
+			Status: ingressv1.DNSRecordStatus{
+				ObservedGeneration: observedGeneration,
+				Zones: []ingressv1.DNSZoneStatus{{
+					Conditions: []ingressv1.DNSZoneCondition{{
+						Type:   ingressv1.DNSRecordPublishedConditionType,
+						Status: published,
+					}},
+				}},
+			},
+		}
+	}
+	testCases := []struct {
+		description string
+		record      *ingressv1.DNSRecord
+		expect      bool
+	}{
+		{"old name", recordFor("*.apps.old.example.com", 1, "True"), false},
+		{"not yet observed", recordFor("*.apps.new.example.com", 0, "True"), false},
+		{"publishing failed", recordFor("*.apps.new.example.com", 1, "False"), false},
+		{"published", recordFor("*.apps.new.example.com", 1, "True"), true},
+	}
+	for _, tc := range testCases {
+		if actual := isRecordPublishedWithName(tc.record, "*.apps.new.example.com"); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EndpointAddressesConditionType is the type of the ingresscontroller
+	// condition that lists the external addresses at which the
+	// ingresscontroller receives traffic.  The message ends with the
+	// addresses, sorted and separated by commas: the hostnames and IP
+	// addresses of the load balancer for the LoadBalancerService endpoint
+	// publishing strategy, or the IP addresses of the nodes with ready
+	// router pods for the HostNetwork and NodePortService strategies.
+	EndpointAddressesConditionType = "EndpointAddresses"
+
+	// EndpointAddressesAnnotation is an annotation that the operator sets
+	// on each ingresscontroller to the addresses and ports at which the
+	// ingresscontroller receives traffic, so that automation such as
+	// external DNS tooling can discover where to point traffic without
+	// inspecting the operands.  The IngressController API has no status
+	// field for them, so they are kept in this annotation.  The value is
+	// the JSON encoding of EndpointAddresses.
+	EndpointAddressesAnnotation = "ingress.operator.openshift.io/endpoint-addresses"
+
+	// endpointAddressesMessagePrefix is the part of the EndpointAddresses
+	// condition message that precedes the addresses.
+	endpointAddressesMessagePrefix = "The ingresscontroller receives traffic at the following addresses: "
+)
+
+// EndpointAddresses are the external addresses and ports at which an
+// ingresscontroller receives traffic.
+type EndpointAddresses struct {
+	// Hostnames are the sorted hostnames of the load balancer.
+	Hostnames []string `json:"hostnames,omitempty"`
+	// IPs are the sorted IP addresses of the load balancer, or of the
+	// nodes with ready router pods if the ingresscontroller is not
+	// published with a load balancer.
+	IPs []string `json:"ips,omitempty"`
+	// Ports are the ports at which the addresses receive HTTP and HTTPS
+	// traffic: the load balancer's ports, the NodePort service's node
+	// ports, or the router pods' host ports.
+	Ports []EndpointPort `json:"ports,omitempty"`
+}
+
+// EndpointPort is a port at which an ingresscontroller receives traffic.
+type EndpointPort struct {
+	// Name is "http" or "https".
+	Name string `json:"name"`
+	// Port is the port number.
+	Port int32 `json:"port"`
+}
+
+// computeEndpointAddressesCondition computes the endpoint addresses of the
+// given ingresscontroller, records them in EndpointAddressesAnnotation, and
+// returns the EndpointAddresses condition for them.
+func (r *reconciler) computeEndpointAddressesCondition(ic *operatorv1.IngressController, lbService, nodePortService *corev1.Service) (*operatorv1.OperatorCondition, error) {
+	addresses, err := r.computeEndpointAddresses(ic, lbService, nodePortService)
+	if err != nil || addresses == nil {
+		return nil, err
+	}
+	if err := r.ensureEndpointAddressesAnnotation(ic, addresses); err != nil {
+		return nil, err
+	}
+	return endpointAddressesCondition(ic.Status.EndpointPublishingStrategy.Type, addresses), nil
+}
+
+// computeEndpointAddresses computes the endpoint addresses of the given
+// ingresscontroller from its LB service and NodePort service, either of which
+// is nil if it has none, or from its router pods.  It returns nil if the
+// ingresscontroller has no endpoint publishing strategy yet.
+func (r *reconciler) computeEndpointAddresses(ic *operatorv1.IngressController, lbService, nodePortService *corev1.Service) (*EndpointAddresses, error) {
+	if ic.Status.EndpointPublishingStrategy == nil {
+		return nil, nil
+	}
+	addresses := &EndpointAddresses{}
+	switch ic.Status.EndpointPublishingStrategy.Type {
+	case operatorv1.LoadBalancerServiceStrategyType:
+		if lbService != nil && isLoadBalancerProvisioned(lbService) {
+			addresses.Hostnames, addresses.IPs = loadBalancerAddresses(lbService)
+			addresses.Ports = serviceEndpointPorts(lbService, false)
+		}
+	case operatorv1.HostNetworkStrategyType, NodePortServiceStrategyType:
+		// The NodePort service uses the Local external traffic
+		// policy, so only nodes with ready router pods accept traffic.
+		pods := &corev1.PodList{}
+		if err := r.client.List(context.TODO(), pods, client.InNamespace(r.Config.OperandNamespace), client.MatchingLabels(IngressControllerDeploymentPodSelector(ic).MatchLabels)); err != nil {
+			return nil, fmt.Errorf("failed to list router pods: %v", err)
+		}
+		addresses.IPs = hostNetworkAddresses(pods.Items)
+		if ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType {
+			addresses.Ports = hostNetworkEndpointPorts(pods.Items)
+		} else if nodePortService != nil {
+			addresses.Ports = serviceEndpointPorts(nodePortService, true)
+		}
+	}
+	if len(addresses.Hostnames) == 0 && len(addresses.IPs) == 0 {
+		addresses.Ports = nil
+	}
+	return addresses, nil
+}
+
+// loadBalancerAddresses returns the sorted hostnames and IP addresses of the
+// load balancer of the given LB service.
+func loadBalancerAddresses(service *corev1.Service) ([]string, []string) {
+	var hostnames, ips []string
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if len(ingress.Hostname) != 0 {
+			hostnames = append(hostnames, ingress.Hostname)
+		}
+		if len(ingress.IP) != 0 {
+			ips = append(ips, ingress.IP)
+		}
+	}
+	sort.Strings(hostnames)
+	sort.Strings(ips)
+	return hostnames, ips
+}
+
+// hostNetworkAddresses returns the sorted IP addresses of the nodes on which
+// the given host-network router pods are ready.
+func hostNetworkAddresses(pods []corev1.Pod) []string {
+	var addresses []string
+	seen := map[string]bool{}
+	for _, pod := range pods {
+		if len(pod.Status.HostIP) == 0 || seen[pod.Status.HostIP] || !isRouterPodReady(pod) {
+			continue
+		}
+		seen[pod.Status.HostIP] = true
+		addresses = append(addresses, pod.Status.HostIP)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// isRouterPodReady returns true if the given router pod is ready and is not
+// being deleted.
+func isRouterPodReady(pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isEndpointPortName returns true if the given port name is the name of a
+// router port that receives HTTP or HTTPS traffic.
+func isEndpointPortName(name string) bool {
+	return name == "http" || name == "https"
+}
+
+// serviceEndpointPorts returns the HTTP and HTTPS ports of the given service,
+// or its node ports if nodePorts is true.
+func serviceEndpointPorts(service *corev1.Service, nodePorts bool) []EndpointPort {
+	var ports []EndpointPort
+	for _, port := range service.Spec.Ports {
+		if !isEndpointPortName(port.Name) {
+			continue
+		}
+		number := port.Port
+		if nodePorts {
+			number = port.NodePort
+		}
+		if number != 0 {
+			ports = append(ports, EndpointPort{Name: port.Name, Port: number})
+		}
+	}
+	return ports
+}
+
+// hostNetworkEndpointPorts returns the HTTP and HTTPS ports of the router
+// container of the first ready pod of the given host-network router pods.
+// With host networking, the container ports are the host ports.
+func hostNetworkEndpointPorts(pods []corev1.Pod) []EndpointPort {
+	for _, pod := range pods {
+		if !isRouterPodReady(pod) {
+			continue
+		}
+		var ports []EndpointPort
+		for _, container := range pod.Spec.Containers {
+			if container.Name != "router" {
+				continue
+			}
+			for _, port := range container.Ports {
+				if isEndpointPortName(port.Name) {
+					ports = append(ports, EndpointPort{Name: port.Name, Port: port.ContainerPort})
+				}
+			}
+		}
+		return ports
+	}
+	return nil
+}
+
+// endpointAddressesCondition returns the EndpointAddresses condition for the
+// given endpoint publishing strategy and addresses.
+func endpointAddressesCondition(strategy operatorv1.EndpointPublishingStrategyType, addresses *EndpointAddresses) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type: EndpointAddressesConditionType,
+	}
+	all := append(append([]string(nil), addresses.Hostnames...), addresses.IPs...)
+	switch {
+	case strategy == operatorv1.PrivateStrategyType:
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "Private"
+		condition.Message = "The ingresscontroller is not published outside the cluster"
+	case len(all) == 0:
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "NoAddresses"
+		condition.Message = "The ingresscontroller does not have any external addresses yet"
+	default:
+		sort.Strings(all)
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = string(strategy)
+		condition.Message = endpointAddressesMessagePrefix + strings.Join(all, ", ")
+	}
+	return condition
+}
+
+// ensureEndpointAddressesAnnotation sets EndpointAddressesAnnotation on the
+// given ingresscontroller to the given endpoint addresses.  The
+// ingresscontroller is updated in place so that later updates of its status
+// use the new resource version.
+func (r *reconciler) ensureEndpointAddressesAnnotation(ic *operatorv1.IngressController, addresses *EndpointAddresses) error {
+	value, err := json.Marshal(addresses)
+	if err != nil {
+		return fmt.Errorf("failed to marshal endpoint addresses: %v", err)
+	}
+	if current, ok := ic.Annotations[EndpointAddressesAnnotation]; ok && current == string(value) {
+		return nil
+	}
+	if ic.Annotations == nil {
+		ic.Annotations = map[string]string{}
+	}
+	ic.Annotations[EndpointAddressesAnnotation] = string(value)
+	if err := r.client.Update(context.TODO(), ic); err != nil {
+		return fmt.Errorf("failed to update endpoint addresses annotation: %v", err)
+	}
+	log.Info("updated endpoint addresses annotation", "namespace", ic.Namespace, "name", ic.Name, "addresses", string(value))
+	return nil
+}
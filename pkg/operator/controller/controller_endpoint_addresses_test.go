@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// routerPod returns a router pod on the node with the given IP address that
+// has the given readiness and the router's HTTP and HTTPS ports.
+func routerPod(hostIP string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "router",
+				Ports: []corev1.ContainerPort{
+					{Name: "http", ContainerPort: 80},
+					{Name: "https", ContainerPort: 443},
+					{Name: "metrics", ContainerPort: 1936},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{
+			HostIP:     hostIP,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+// TestEndpointAddresses verifies that the endpoint addresses list the load
+// balancer's addresses and ports, or the addresses of the nodes with ready
+// host-network router pods and their host ports or the NodePort service's
+// node ports.
+func TestEndpointAddresses(t *testing.T) {
+	ports := []corev1.ServicePort{
+		{Name: "http", Port: 80, NodePort: 30080},
+		{Name: "https", Port: 443, NodePort: 30443},
+		{Name: "metrics", Port: 1936, NodePort: 31936},
+	}
+	lbService := &corev1.Service{
+		Spec: corev1.ServiceSpec{Ports: ports},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{
+					{Hostname: "lb.example.com"},
+					{IP: "192.0.2.10"},
+				},
+			},
+		},
+	}
+	pods := []corev1.Pod{
+		routerPod("10.0.0.2", true),
+		routerPod("10.0.0.1", true),
+		routerPod("10.0.0.3", false),
+		routerPod("", false),
+	}
+	lbHostnames, lbIPs := loadBalancerAddresses(lbService)
+	unprovisionedHostnames, unprovisionedIPs := loadBalancerAddresses(&corev1.Service{})
+	testCases := []struct {
+		description string
+		actual      EndpointAddresses
+		expect      EndpointAddresses
+	}{
+		{
+			description: "load balancer with a hostname and an IP address",
+			actual:      EndpointAddresses{Hostnames: lbHostnames, IPs: lbIPs, Ports: serviceEndpointPorts(lbService, false)},
+			expect: EndpointAddresses{
+				Hostnames: []string{"lb.example.com"},
+				IPs:       []string{"192.0.2.10"},
+				Ports:     []EndpointPort{{Name: "http", Port: 80}, {Name: "https", Port: 443}},
+			},
+		},
+		{
+			description: "load balancer not yet provisioned",
+			actual:      EndpointAddresses{Hostnames: unprovisionedHostnames, IPs: unprovisionedIPs},
+		},
+		{
+			description: "host network with ready, unready, and unscheduled pods",
+			actual:      EndpointAddresses{IPs: hostNetworkAddresses(pods), Ports: hostNetworkEndpointPorts(pods)},
+			expect: EndpointAddresses{
+				IPs:   []string{"10.0.0.1", "10.0.0.2"},
+				Ports: []EndpointPort{{Name: "http", Port: 80}, {Name: "https", Port: 443}},
+			},
+		},
+		{
+			description: "NodePort service",
+			actual:      EndpointAddresses{IPs: hostNetworkAddresses(pods), Ports: serviceEndpointPorts(&corev1.Service{Spec: corev1.ServiceSpec{Ports: ports}}, true)},
+			expect: EndpointAddresses{
+				IPs:   []string{"10.0.0.1", "10.0.0.2"},
+				Ports: []EndpointPort{{Name: "http", Port: 30080}, {Name: "https", Port: 30443}},
+			},
+		},
+		{
+			description: "no ready pods",
+			actual:      EndpointAddresses{IPs: hostNetworkAddresses(pods[2:]), Ports: hostNetworkEndpointPorts(pods[2:])},
+		},
+	}
+	for _, tc := range testCases {
+		if !reflect.DeepEqual(tc.actual, tc.expect) {
+			t.Errorf("%q: expected %+v, got %+v", tc.description, tc.expect, tc.actual)
+		}
+	}
+}
+
+// TestEndpointAddressesCondition verifies that the EndpointAddresses condition
+// lists the hostnames and IP addresses of the endpoint addresses.
+func TestEndpointAddressesCondition(t *testing.T) {
+	testCases := []struct {
+		description   string
+		strategy      operatorv1.EndpointPublishingStrategyType
+		addresses     EndpointAddresses
+		expectStatus  operatorv1.ConditionStatus
+		expectMessage string
+	}{
+		{
+			description:   "load balancer with a hostname and an IP address",
+			strategy:      operatorv1.LoadBalancerServiceStrategyType,
+			addresses:     EndpointAddresses{Hostnames: []string{"lb.example.com"}, IPs: []string{"192.0.2.10"}},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: endpointAddressesMessagePrefix + "192.0.2.10, lb.example.com",
+		},
+		{
+			description:  "no addresses",
+			strategy:     operatorv1.LoadBalancerServiceStrategyType,
+			expectStatus: operatorv1.ConditionFalse,
+		},
+		{
+			description:   "host network",
+			strategy:      operatorv1.HostNetworkStrategyType,
+			addresses:     EndpointAddresses{IPs: []string{"10.0.0.1", "10.0.0.2"}},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: endpointAddressesMessagePrefix + "10.0.0.1, 10.0.0.2",
+		},
+		{
+			description:  "private",
+			strategy:     operatorv1.PrivateStrategyType,
+			expectStatus: operatorv1.ConditionFalse,
+		},
+	}
+	for _, tc := range testCases {
+		condition := endpointAddressesCondition(tc.strategy, &tc.addresses)
+		if condition.Status != tc.expectStatus {
+			t.Errorf("%q: expected status %s, got %s", tc.description, tc.expectStatus, condition.Status)
+		}
+		if len(tc.expectMessage) != 0 && condition.Message != tc.expectMessage {
+			t.Errorf("%q: expected message %q, got %q", tc.description, tc.expectMessage, condition.Message)
+		}
+	}
+}
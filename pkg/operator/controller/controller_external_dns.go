@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DNSManagementAnnotation may be set on an ingresscontroller to
+	// specify how the wildcard DNS record for its load balancer is
+	// published.  The value must be one of the following:
+	//
+	//  * "DNSRecord" (the default): the operator creates a DNSRecord, which
+	//    the dnsrecord controller publishes to the cluster's DNS zones using
+	//    the platform's DNS provider.
+	//  * "ExternalDNS": the operator annotates the load balancer service so
+	//    that an external-dns deployment in the cluster publishes the
+	//    record.  This allows wildcard records on platforms for which the
+	//    operator has no DNS provider, such as bare metal.
+	DNSManagementAnnotation = "ingress.operator.openshift.io/dns-management"
+
+	// DNSRecordDNSManagement is the default value of DNSManagementAnnotation.
+	DNSRecordDNSManagement = "DNSRecord"
+	// ExternalDNSDNSManagement is the value of DNSManagementAnnotation that
+	// delegates publishing the wildcard record to external-dns.
+	ExternalDNSDNSManagement = "ExternalDNS"
+
+	// externalDNSHostnameAnnotation and externalDNSTTLAnnotation are the
+	// service annotations from which external-dns publishes records.
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	externalDNSTTLAnnotation      = "external-dns.alpha.kubernetes.io/ttl"
+
+	// externalDNSRecordTTL is the TTL in seconds of the wildcard record
+	// that external-dns publishes.  It matches the default TTL of
+	// DNSRecords.
+	externalDNSRecordTTL = 30
+)
+
+// externalDNSAnnotations are the LB service annotations that the operator sets
+// for external-dns.  Unlike other service annotations, the operator removes
+// them when they are no longer desired so that external-dns and the dnsrecord
+// controller do not both publish the wildcard record.
+var externalDNSAnnotations = []string{
+	externalDNSHostnameAnnotation,
+	externalDNSTTLAnnotation,
+}
+
+// dnsManagementFor returns the DNS management mode of the given
+// ingresscontroller.
+func dnsManagementFor(ic *operatorv1.IngressController) (string, error) {
+	switch value := ic.Annotations[DNSManagementAnnotation]; value {
+	case "", DNSRecordDNSManagement:
+		return DNSRecordDNSManagement, nil
+	case ExternalDNSDNSManagement:
+		return ExternalDNSDNSManagement, nil
+	default:
+		return "", fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", DNSManagementAnnotation, value, DNSRecordDNSManagement, ExternalDNSDNSManagement)
+	}
+}
+
+// usesExternalDNS returns true if the wildcard DNS record of the given
+// ingresscontroller is published by external-dns.  An invalid DNS management
+// annotation is reported by admission, so it is treated as the default here.
+func usesExternalDNS(ic *operatorv1.IngressController) bool {
+	mode, err := dnsManagementFor(ic)
+	return err == nil && mode == ExternalDNSDNSManagement
+}
+
+// desiredExternalDNSAnnotations returns the annotations from which external-dns
+// publishes the wildcard DNS record of the given ingresscontroller, or nil if
+// it does not use external-dns or has no domain.
+func desiredExternalDNSAnnotations(ic *operatorv1.IngressController) map[string]string {
+	if !usesExternalDNS(ic) || len(ic.Status.Domain) == 0 {
+		return nil
+	}
+	return map[string]string{
+		externalDNSHostnameAnnotation: "*." + ic.Status.Domain,
+		externalDNSTTLAnnotation:      strconv.Itoa(externalDNSRecordTTL),
+	}
+}
+
+// externalDNSAnnotationsChanged checks if the external-dns annotations of the
+// current service match the desired ones and if not returns the updated
+// service, which has exactly the desired external-dns annotations.
+func externalDNSAnnotationsChanged(current *corev1.Service, desired map[string]string) (bool, *corev1.Service) {
+	changed := false
+	for _, key := range externalDNSAnnotations {
+		currentValue, currentOK := current.Annotations[key]
+		desiredValue, desiredOK := desired[key]
+		if currentOK != desiredOK || currentValue != desiredValue {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	updated := current.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	for _, key := range externalDNSAnnotations {
+		if value, ok := desired[key]; ok {
+			updated.Annotations[key] = value
+		} else {
+			delete(updated.Annotations, key)
+		}
+	}
+	return true, updated
+}
+
+// ensureExternalDNSAnnotations ensures that the given LB service has exactly
+// the given external-dns annotations.
+func (r *reconciler) ensureExternalDNSAnnotations(service *corev1.Service, desired map[string]string) error {
+	changed, updated := externalDNSAnnotationsChanged(service, desired)
+	if !changed {
+		return nil
+	}
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update service %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated external-dns annotations", "namespace", updated.Namespace, "name", updated.Name, "annotations", desired)
+	return nil
+}
+
+// ensureExternalDNSTarget ensures that the given LB service, to which the
+// wildcard DNS record of the given ingresscontroller should point, is the only
+// LB service of the ingresscontroller that has the external-dns annotations.
+// It annotates the target before it removes the annotations from the other LB
+// services so that external-dns updates the record in place instead of
+// deleting it and publishing it again.  If desired is nil, the annotations are
+// removed from every LB service.
+func (r *reconciler) ensureExternalDNSTarget(ci *operatorv1.IngressController, target *corev1.Service, desired map[string]string) error {
+	if target != nil {
+		if err := r.ensureExternalDNSAnnotations(target, desired); err != nil {
+			return err
+		}
+	}
+	current, other, err := r.currentLoadBalancerServices(ci)
+	if err != nil {
+		return err
+	}
+	for _, service := range []*corev1.Service{current, other} {
+		if service == nil || (target != nil && service.Name == target.Name) {
+			continue
+		}
+		if err := r.ensureExternalDNSAnnotations(service, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestExternalDNSManagement verifies that an ingresscontroller that uses
+// external-dns gets external-dns annotations instead of a wildcard DNSRecord,
+// that the LB service is built without them so that updating it does not
+// interfere with ensureExternalDNSTarget, and that the annotations are not
+// stripped from a service that has them.
+func TestExternalDNSManagement(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{Platform: configv1.BareMetalPlatformType},
+	}
+	icFor := func(mode string) *operatorv1.IngressController {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		}
+		if len(mode) != 0 {
+			ic.Annotations = map[string]string{DNSManagementAnnotation: mode}
+		}
+		return ic
+	}
+
+	externalDNS := icFor(ExternalDNSDNSManagement)
+	expected := map[string]string{
+		externalDNSHostnameAnnotation: "*.apps.example.com",
+		externalDNSTTLAnnotation:      "30",
+	}
+	if annotations := desiredExternalDNSAnnotations(externalDNS); !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("expected annotations %v, got %v", expected, annotations)
+	}
+	if record := desiredWildcardRecord(externalDNS, "lb.example.com"); record != nil {
+		t.Errorf("expected no wildcard DNSRecord, got %v", record)
+	}
+
+	service, err := desiredLoadBalancerService(externalDNS, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, key := range externalDNSAnnotations {
+		if _, ok := service.Annotations[key]; ok {
+			t.Errorf("expected no annotation %s on the desired LB service, got %v", key, service.Annotations)
+		}
+	}
+	annotated := service.DeepCopy()
+	if annotated.Annotations == nil {
+		annotated.Annotations = map[string]string{}
+	}
+	for k, v := range expected {
+		annotated.Annotations[k] = v
+	}
+	if changed, updated := serviceChanged(annotated, service); changed {
+		t.Errorf("expected the external-dns annotations to be left alone, got %v", updated.Annotations)
+	}
+
+	dnsRecord := icFor("")
+	if annotations := desiredExternalDNSAnnotations(dnsRecord); annotations != nil {
+		t.Errorf("expected no annotations, got %v", annotations)
+	}
+	if record := desiredWildcardRecord(dnsRecord, "lb.example.com"); record == nil {
+		t.Error("expected a wildcard DNSRecord, got nil")
+	}
+
+	if _, err := dnsManagementFor(icFor("Manual")); err == nil {
+		t.Error("expected an error for an invalid DNS management mode, got nil")
+	}
+}
+
+// TestExternalDNSAnnotationsChanged verifies that externalDNSAnnotationsChanged
+// sets or removes exactly the external-dns annotations and leaves the others
+// alone.
+func TestExternalDNSAnnotationsChanged(t *testing.T) {
+	desired := map[string]string{
+		externalDNSHostnameAnnotation: "*.apps.example.com",
+		externalDNSTTLAnnotation:      "30",
+	}
+	testCases := []struct {
+		description string
+		current     map[string]string
+		desired     map[string]string
+		expect      map[string]string
+	}{
+		{
+			description: "no annotations and none desired",
+			current:     nil,
+			desired:     nil,
+			expect:      nil,
+		},
+		{
+			description: "annotations added",
+			current:     map[string]string{"foo": "bar"},
+			desired:     desired,
+			expect: map[string]string{
+				"foo":                         "bar",
+				externalDNSHostnameAnnotation: "*.apps.example.com",
+				externalDNSTTLAnnotation:      "30",
+			},
+		},
+		{
+			description: "annotations already set",
+			current: map[string]string{
+				"foo":                         "bar",
+				externalDNSHostnameAnnotation: "*.apps.example.com",
+				externalDNSTTLAnnotation:      "30",
+			},
+			desired: desired,
+			expect:  nil,
+		},
+		{
+			description: "hostname changed",
+			current: map[string]string{
+				externalDNSHostnameAnnotation: "*.old.example.com",
+				externalDNSTTLAnnotation:      "30",
+			},
+			desired: desired,
+			expect:  desired,
+		},
+		{
+			description: "annotations removed",
+			current: map[string]string{
+				"foo":                         "bar",
+				externalDNSHostnameAnnotation: "*.apps.example.com",
+				externalDNSTTLAnnotation:      "30",
+			},
+			desired: nil,
+			expect:  map[string]string{"foo": "bar"},
+		},
+	}
+	for _, tc := range testCases {
+		current := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: tc.current}}
+		changed, updated := externalDNSAnnotationsChanged(current, tc.desired)
+		if tc.expect == nil {
+			if changed {
+				t.Errorf("%q: expected no change, got %v", tc.description, updated.Annotations)
+			}
+			continue
+		}
+		if !changed {
+			t.Errorf("%q: expected a change, got none", tc.description)
+			continue
+		}
+		if !reflect.DeepEqual(updated.Annotations, tc.expect) {
+			t.Errorf("%q: expected annotations %v, got %v", tc.description, tc.expect, updated.Annotations)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// HeartbeatInterval is how often the operator controller queues a heartbeat
+// request while this replica is the leader.
+const HeartbeatInterval = 1 * time.Minute
+
+// heartbeatName is the name of the heartbeat request.  Its namespace is empty,
+// so it never names an ingresscontroller.
+var heartbeatName = types.NamespacedName{Name: "heartbeat"}
+
+// watchHeartbeat makes the given controller reconcile a heartbeat request every
+// HeartbeatInterval while the manager runs, which is only while this replica is
+// the leader.  The reconciler reports the heartbeat through Config.Heartbeat,
+// so a missing heartbeat means that the controller's work queue or all of its
+// workers are wedged.
+func watchHeartbeat(mgr manager.Manager, c controller.Controller) error {
+	heartbeats := make(chan event.GenericEvent, 1)
+	if err := c.Watch(&source.Channel{Source: heartbeats}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	return mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		ticker := time.NewTicker(HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return nil
+			case <-ticker.C:
+				// The send is best effort: if a heartbeat is
+				// still buffered, the next one is not needed.
+				select {
+				case heartbeats <- event.GenericEvent{Meta: &metav1.ObjectMeta{Namespace: heartbeatName.Namespace, Name: heartbeatName.Name}}:
+				default:
+				}
+			}
+		}
+	}))
+}
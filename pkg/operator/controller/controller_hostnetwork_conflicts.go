@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// hostNetworkConflictWarnings returns a warning for each older, admitted
+// ingresscontroller that uses the HostNetwork endpoint publishing strategy and
+// whose routers may be scheduled to the same nodes as the routers of the given
+// ingresscontroller, if it also uses the HostNetwork strategy.  Such routers
+// bind the same ports on the node, so only one router can run on each node
+// that both node selectors select, and the other router's pod stays pending.
+// The older ingresscontroller is not warned so that admitting a new
+// ingresscontroller never changes the condition of an existing one.
+func hostNetworkConflictWarnings(ic *operatorv1.IngressController, others []operatorv1.IngressController) []string {
+	if !usesHostNetwork(ic) {
+		return nil
+	}
+	nodeSelector, err := routerNodeSelector(ic)
+	if err != nil {
+		return nil
+	}
+	var warnings []string
+	for i := range others {
+		other := &others[i]
+		if other.Namespace == ic.Namespace && other.Name == ic.Name || !usesHostNetwork(other) || !isOlderIngressController(other, ic) {
+			continue
+		}
+		if admitted := findIngressStatusCondition(other.Status.Conditions, IngressControllerAdmittedConditionType); admitted == nil || admitted.Status != operatorv1.ConditionTrue {
+			continue
+		}
+		otherNodeSelector, err := routerNodeSelector(other)
+		if err != nil || !nodeSelectorsMayOverlap(nodeSelector, otherNodeSelector) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("routers may be scheduled to the same nodes as the routers of ingresscontroller %s, which also use host ports 80, 443, and 1936, so routers that conflict on a node cannot be scheduled", other.Name))
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// usesHostNetwork returns true if the given ingresscontroller uses the
+// HostNetwork endpoint publishing strategy.
+func usesHostNetwork(ic *operatorv1.IngressController) bool {
+	return ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType
+}
+
+// nodeSelectorsMayOverlap returns true if a node can have labels that match
+// both of the given node selectors, which is the case unless the selectors
+// require different values for the same label.
+func nodeSelectorsMayOverlap(a, b map[string]string) bool {
+	for key, value := range a {
+		if other, ok := b[key]; ok && other != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHostNetworkConflictWarnings(t *testing.T) {
+	created := metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	ingressController := func(name string, strategy operatorv1.EndpointPublishingStrategyType, nodeSelector map[string]string, admitted bool) operatorv1.IngressController {
+		ic := operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "openshift-ingress-operator",
+				Name:              name,
+				CreationTimestamp: created,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+			},
+		}
+		if nodeSelector != nil {
+			ic.Spec.NodePlacement = &operatorv1.NodePlacement{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: nodeSelector},
+			}
+		}
+		if admitted {
+			ic.Status.Conditions = []operatorv1.OperatorCondition{{
+				Type:   IngressControllerAdmittedConditionType,
+				Status: operatorv1.ConditionTrue,
+			}}
+		}
+		return ic
+	}
+	infra := map[string]string{"node-role.kubernetes.io/infra": ""}
+	shardA := map[string]string{"shard": "a"}
+	shardB := map[string]string{"shard": "b"}
+	testCases := []struct {
+		description string
+		ic          operatorv1.IngressController
+		others      []operatorv1.IngressController
+		expect      []string
+	}{
+		{
+			description: "default node selectors",
+			ic:          ingressController("new", operatorv1.HostNetworkStrategyType, nil, false),
+			others:      []operatorv1.IngressController{ingressController("default", operatorv1.HostNetworkStrategyType, nil, true)},
+			expect:      []string{"default"},
+		},
+		{
+			description: "node selectors with different labels",
+			ic:          ingressController("new", operatorv1.HostNetworkStrategyType, infra, false),
+			others:      []operatorv1.IngressController{ingressController("default", operatorv1.HostNetworkStrategyType, nil, true)},
+			expect:      []string{"default"},
+		},
+		{
+			description: "disjoint node selectors",
+			ic:          ingressController("new", operatorv1.HostNetworkStrategyType, shardA, false),
+			others:      []operatorv1.IngressController{ingressController("other", operatorv1.HostNetworkStrategyType, shardB, true)},
+		},
+		{
+			description: "other ingresscontroller is not admitted",
+			ic:          ingressController("new", operatorv1.HostNetworkStrategyType, nil, false),
+			others:      []operatorv1.IngressController{ingressController("default", operatorv1.HostNetworkStrategyType, nil, false)},
+		},
+		{
+			description: "other ingresscontroller uses a load balancer",
+			ic:          ingressController("new", operatorv1.HostNetworkStrategyType, nil, false),
+			others:      []operatorv1.IngressController{ingressController("default", operatorv1.LoadBalancerServiceStrategyType, nil, true)},
+		},
+		{
+			description: "ingresscontroller uses a load balancer",
+			ic:          ingressController("new", operatorv1.LoadBalancerServiceStrategyType, nil, false),
+			others:      []operatorv1.IngressController{ingressController("default", operatorv1.HostNetworkStrategyType, nil, true)},
+		},
+		{
+			description: "other ingresscontroller is newer",
+			ic:          ingressController("a", operatorv1.HostNetworkStrategyType, nil, true),
+			others:      []operatorv1.IngressController{ingressController("b", operatorv1.HostNetworkStrategyType, nil, true)},
+		},
+		{
+			description: "several conflicting ingresscontrollers",
+			ic:          ingressController("new", operatorv1.HostNetworkStrategyType, shardA, false),
+			others: []operatorv1.IngressController{
+				ingressController("new", operatorv1.HostNetworkStrategyType, shardA, true),
+				ingressController("default", operatorv1.HostNetworkStrategyType, nil, true),
+				ingressController("b", operatorv1.HostNetworkStrategyType, shardB, true),
+				ingressController("a", operatorv1.HostNetworkStrategyType, shardA, true),
+			},
+			expect: []string{"a", "default"},
+		},
+	}
+	for _, tc := range testCases {
+		warnings := hostNetworkConflictWarnings(&tc.ic, tc.others)
+		if len(warnings) != len(tc.expect) {
+			t.Errorf("%q: expected warnings about %v, got %v", tc.description, tc.expect, warnings)
+			continue
+		}
+		for i, name := range tc.expect {
+			if !strings.Contains(warnings[i], "ingresscontroller "+name+",") {
+				t.Errorf("%q: expected a warning about ingresscontroller %s, got %q", tc.description, name, warnings[i])
+			}
+		}
+	}
+}
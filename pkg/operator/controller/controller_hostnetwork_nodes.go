@@ -0,0 +1,490 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// HostNetworkNodeContractAnnotation may be set to "Enabled" on an
+	// ingresscontroller that uses the HostNetwork endpoint publishing
+	// strategy to have the operator label each node that runs one of its
+	// routers with HostNetworkRouterNodeLabel and annotate it with
+	// HostNetworkPortsNodeAnnotation, and check that the router ports on
+	// these nodes are reachable.  Firewall and load balancer automation
+	// can select the nodes by the label to open the ports only on the
+	// nodes that need them.  The default is "Disabled".
+	HostNetworkNodeContractAnnotation = "ingress.operator.openshift.io/host-network-node-contract"
+
+	// HostNetworkRouterNodeLabel is the label that the operator sets on
+	// the nodes that run a router of an ingresscontroller that enables
+	// HostNetworkNodeContractAnnotation.  Its value is the name of the
+	// ingresscontroller.  Only one HostNetwork router can run on a node,
+	// so a node has at most one such ingresscontroller.
+	HostNetworkRouterNodeLabel = "ingress.operator.openshift.io/host-network-router"
+
+	// HostNetworkPortsNodeAnnotation is the annotation that the operator
+	// sets on the nodes with HostNetworkRouterNodeLabel.  Its value is the
+	// comma-separated list of the ports on which the router accepts
+	// traffic on the node's addresses.
+	HostNetworkPortsNodeAnnotation = "ingress.operator.openshift.io/host-network-ports"
+
+	// HostNetworkNodesUnreachableConditionType is the type of the
+	// ingresscontroller condition that reports the nodes with a ready
+	// router on which the operator could not connect to a router port.
+	// The operator connects from its own pod, so the condition reports
+	// whether the ports are reachable from the cluster's pod network, not
+	// whether a firewall outside the cluster lets clients reach them.
+	HostNetworkNodesUnreachableConditionType = "HostNetworkNodesUnreachable"
+
+	hostNetworkNodeContractEnabledValue  = "Enabled"
+	hostNetworkNodeContractDisabledValue = "Disabled"
+
+	// hostNetworkPortTimeout bounds the time to connect to one router
+	// port on a node.
+	hostNetworkPortTimeout = 3 * time.Second
+
+	// maxConcurrentHostNetworkDials is the maximum number of router ports
+	// to which one probe connects at the same time.
+	maxConcurrentHostNetworkDials = 16
+)
+
+// hostNetworkPorts returns the ports on which the routers of the given router
+// deployment accept traffic.  HostNetwork routers listen on their container
+// ports on the node's addresses.  The metrics port is left out because it is
+// meant to be reachable only from within the cluster.
+func hostNetworkPorts(deployment *appsv1.Deployment) []string {
+	var ports []string
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != "router" {
+			continue
+		}
+		for _, port := range container.Ports {
+			if port.Name == "metrics" || port.Protocol != corev1.ProtocolTCP {
+				continue
+			}
+			ports = append(ports, strconv.Itoa(int(port.ContainerPort)))
+		}
+	}
+	return ports
+}
+
+// hostNetworkNodeContractEnabled returns true if the given ingresscontroller
+// enables HostNetworkNodeContractAnnotation.
+func hostNetworkNodeContractEnabled(ic *operatorv1.IngressController) (bool, error) {
+	switch value := ic.Annotations[HostNetworkNodeContractAnnotation]; value {
+	case "", hostNetworkNodeContractDisabledValue:
+		return false, nil
+	case hostNetworkNodeContractEnabledValue:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", HostNetworkNodeContractAnnotation, value, hostNetworkNodeContractEnabledValue, hostNetworkNodeContractDisabledValue)
+	}
+}
+
+// usesHostNetworkNodeContract returns true if the given ingresscontroller uses
+// the HostNetwork endpoint publishing strategy and enables
+// HostNetworkNodeContractAnnotation.
+func usesHostNetworkNodeContract(ic *operatorv1.IngressController) (bool, error) {
+	enabled, err := hostNetworkNodeContractEnabled(ic)
+	if err != nil || !enabled {
+		return false, err
+	}
+	return usesHostNetwork(ic), nil
+}
+
+// ensureHostNetworkNodeContract labels and annotates the nodes that run a
+// router of the given ingresscontroller and router deployment if the
+// ingresscontroller uses the node contract, and removes the label and
+// annotation from the nodes that it set them on and that no longer run one of
+// its routers.
+func (r *reconciler) ensureHostNetworkNodeContract(ic *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	desired := sets.NewString()
+	if enabled, err := usesHostNetworkNodeContract(ic); err != nil {
+		return err
+	} else if enabled {
+		pods, err := r.cachedRouterPods(ic)
+		if err != nil {
+			return err
+		}
+		desired = routerNodeNames(pods)
+	}
+	return r.updateHostNetworkNodes(ic.Name, desired, hostNetworkPorts(deployment))
+}
+
+// ensureHostNetworkNodeContractDeleted removes the node contract label and
+// annotation of the given ingresscontroller from all nodes and forgets its
+// port probes.
+func (r *reconciler) ensureHostNetworkNodeContractDeleted(ic *operatorv1.IngressController) error {
+	r.hostNetworkProber.forget(types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name})
+	return r.updateHostNetworkNodes(ic.Name, sets.NewString(), nil)
+}
+
+// updateHostNetworkNodes patches the nodes so that exactly the given nodes have
+// the node contract label and annotation with the given ports for the named
+// ingresscontroller.  The operator only patches nodes' metadata, so it needs
+// no more than the patch verb on nodes.  Only the nodes that have the label
+// and the desired nodes are read, from the cluster cache.
+func (r *reconciler) updateHostNetworkNodes(icName string, desired sets.String, ports []string) error {
+	labeled := &corev1.NodeList{}
+	if err := r.ClusterCache.List(context.TODO(), labeled, client.MatchingLabels(map[string]string{HostNetworkRouterNodeLabel: icName})); err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	nodes := labeled.Items
+	for _, name := range desired.Difference(nodeNames(labeled.Items)).List() {
+		node, err := r.cachedNode(name)
+		if err != nil {
+			return err
+		}
+		if node != nil {
+			nodes = append(nodes, *node)
+		}
+	}
+	current := map[string]*corev1.Node{}
+	for i := range nodes {
+		current[nodes[i].Name] = &nodes[i]
+	}
+	for _, updated := range hostNetworkNodeUpdates(icName, nodes, desired, ports) {
+		if err := r.client.Patch(context.TODO(), &updated, client.MergeFrom(current[updated.Name])); err != nil {
+			return fmt.Errorf("failed to patch node %s: %v", updated.Name, err)
+		}
+		log.Info("updated host network router label of node", "node", updated.Name, "ingresscontroller", icName, "label", updated.Labels[HostNetworkRouterNodeLabel])
+	}
+	return nil
+}
+
+// hostNetworkNodeUpdates returns updated copies of the given nodes that need a
+// change so that exactly the desired nodes have the node contract label and
+// the annotation with the given ports for the named ingresscontroller.  The
+// label of another ingresscontroller is left alone.
+func hostNetworkNodeUpdates(icName string, nodes []corev1.Node, desired sets.String, hostPorts []string) []corev1.Node {
+	ports := strings.Join(hostPorts, ",")
+	var updates []corev1.Node
+	for i := range nodes {
+		node := &nodes[i]
+		value, labeled := node.Labels[HostNetworkRouterNodeLabel]
+		switch {
+		case desired.Has(node.Name):
+			if labeled && value == icName && node.Annotations[HostNetworkPortsNodeAnnotation] == ports {
+				continue
+			}
+			updated := node.DeepCopy()
+			if updated.Labels == nil {
+				updated.Labels = map[string]string{}
+			}
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			updated.Labels[HostNetworkRouterNodeLabel] = icName
+			updated.Annotations[HostNetworkPortsNodeAnnotation] = ports
+			updates = append(updates, *updated)
+		case labeled && value == icName:
+			updated := node.DeepCopy()
+			delete(updated.Labels, HostNetworkRouterNodeLabel)
+			delete(updated.Annotations, HostNetworkPortsNodeAnnotation)
+			updates = append(updates, *updated)
+		}
+	}
+	return updates
+}
+
+// nodeNames returns the names of the given nodes.
+func nodeNames(nodes []corev1.Node) sets.String {
+	names := sets.NewString()
+	for i := range nodes {
+		names.Insert(nodes[i].Name)
+	}
+	return names
+}
+
+// cachedRouterPods returns the given ingresscontroller's router pods from the
+// operand cache.
+func (r *reconciler) cachedRouterPods(ic *operatorv1.IngressController) ([]corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.OperandCache.List(context.TODO(), pods, client.InNamespace(r.Config.OperandNamespace), client.MatchingLabels(IngressControllerDeploymentPodSelector(ic).MatchLabels)); err != nil {
+		return nil, fmt.Errorf("failed to list router pods: %v", err)
+	}
+	return pods.Items, nil
+}
+
+// cachedNode returns the node with the given name from the cluster cache, or
+// nil if it does not exist.
+func (r *reconciler) cachedNode(name string) (*corev1.Node, error) {
+	node := &corev1.Node{}
+	if err := r.ClusterCache.Get(context.TODO(), types.NamespacedName{Name: name}, node); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get node %s: %v", name, err)
+	}
+	return node, nil
+}
+
+// cachedRouterNodes returns the nodes to which the given router pods are
+// scheduled from the cluster cache.
+func (r *reconciler) cachedRouterNodes(pods []corev1.Pod) ([]corev1.Node, error) {
+	var nodes []corev1.Node
+	names := sets.NewString()
+	for i := range pods {
+		if len(pods[i].Spec.NodeName) != 0 {
+			names.Insert(pods[i].Spec.NodeName)
+		}
+	}
+	for _, name := range names.List() {
+		node, err := r.cachedNode(name)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, *node)
+		}
+	}
+	return nodes, nil
+}
+
+// routerNodeNames returns the names of the nodes to which the given router pods
+// are scheduled, other than pods that are being deleted.
+func routerNodeNames(pods []corev1.Pod) sets.String {
+	names := sets.NewString()
+	for i := range pods {
+		if len(pods[i].Spec.NodeName) != 0 && pods[i].DeletionTimestamp == nil {
+			names.Insert(pods[i].Spec.NodeName)
+		}
+	}
+	return names
+}
+
+// computeHostNetworkNodesUnreachableCondition computes the
+// HostNetworkNodesUnreachable condition for the given ingresscontroller and
+// router deployment from the most recent probe of the router ports on the
+// internal address of each node with a ready router, and starts a new probe in
+// the background.  The condition is nil unless the ingresscontroller uses the
+// node contract, and it is unchanged until the first probe finishes.
+func (r *reconciler) computeHostNetworkNodesUnreachableCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment) (*operatorv1.OperatorCondition, error) {
+	if enabled, err := usesHostNetworkNodeContract(ic); err != nil || !enabled {
+		return nil, err
+	}
+	pods, err := r.cachedRouterPods(ic)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := r.cachedRouterNodes(pods)
+	if err != nil {
+		return nil, err
+	}
+	targets, unreachable := hostNetworkProbeTargets(pods, nodes, hostNetworkPorts(deployment))
+	probed, ok := r.hostNetworkProber.probe(types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, targets)
+	if !ok {
+		return findIngressStatusCondition(ic.Status.Conditions, HostNetworkNodesUnreachableConditionType), nil
+	}
+	return hostNetworkNodesUnreachableCondition(append(unreachable, probed...)), nil
+}
+
+// hostNetworkProbeTarget is a node with a ready router and the router ports to
+// which to connect on the node's internal address.
+type hostNetworkProbeTarget struct {
+	node    string
+	address string
+	ports   []string
+}
+
+// hostNetworkProbeTargets returns the probe targets for the nodes with a ready
+// router among the given pods and the given router ports, and a description of
+// each of these nodes that cannot be probed because it has no internal address.
+func hostNetworkProbeTargets(pods []corev1.Pod, nodes []corev1.Node, ports []string) ([]hostNetworkProbeTarget, []string) {
+	ready := sets.NewString()
+	for i := range pods {
+		if isPodReady(&pods[i]) && len(pods[i].Spec.NodeName) != 0 {
+			ready.Insert(pods[i].Spec.NodeName)
+		}
+	}
+	var (
+		targets   []hostNetworkProbeTarget
+		noAddress []string
+	)
+	for i := range nodes {
+		node := &nodes[i]
+		if !ready.Has(node.Name) {
+			continue
+		}
+		address := nodeInternalAddress(node)
+		if len(address) == 0 {
+			noAddress = append(noAddress, fmt.Sprintf("%s (no internal address)", node.Name))
+			continue
+		}
+		targets = append(targets, hostNetworkProbeTarget{node: node.Name, address: address, ports: ports})
+	}
+	return targets, noAddress
+}
+
+// unreachableHostNetworkNodes connects with the given dial function to each
+// port of each of the given targets, at most maxConcurrentHostNetworkDials at a
+// time, and returns a description of each node on which a port is unreachable,
+// sorted by node name.
+func unreachableHostNetworkNodes(targets []hostNetworkProbeTarget, dial func(address string) error) []string {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed = map[string][]string{}
+	)
+	sem := make(chan struct{}, maxConcurrentHostNetworkDials)
+	for i := range targets {
+		for _, port := range targets[i].ports {
+			wg.Add(1)
+			go func(target *hostNetworkProbeTarget, port string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				err := dial(net.JoinHostPort(target.address, port))
+				<-sem
+				if err == nil {
+					return
+				}
+				log.Info("failed to connect to host network router port", "node", target.node, "address", target.address, "port", port, "error", err.Error())
+				mu.Lock()
+				defer mu.Unlock()
+				failed[target.node] = append(failed[target.node], port)
+			}(&targets[i], port)
+		}
+	}
+	wg.Wait()
+	var unreachable []string
+	for _, target := range targets {
+		ports, ok := failed[target.node]
+		if !ok {
+			continue
+		}
+		sort.Strings(ports)
+		unreachable = append(unreachable, fmt.Sprintf("%s (%s, ports %s)", target.node, target.address, strings.Join(ports, ", ")))
+	}
+	sort.Strings(unreachable)
+	return unreachable
+}
+
+// hostNetworkProber probes the router ports of host network routers in the
+// background so that reconciliation does not wait for the connections.  It
+// keeps the outcome of the most recent probe of each ingresscontroller and
+// sends an event for the ingresscontroller when a probe finishes so that the
+// outcome is published.
+type hostNetworkProber struct {
+	// dial connects to the given address and closes the connection.
+	dial func(address string) error
+	// events receives an event for each finished probe.  It may be nil.
+	events chan event.GenericEvent
+
+	lock   sync.Mutex
+	probes map[types.NamespacedName]*hostNetworkProbe
+}
+
+// hostNetworkProbe is the state of the probes of one ingresscontroller.
+type hostNetworkProbe struct {
+	// running is true while a probe is in progress.
+	running bool
+	// finished is true once a probe has finished.
+	finished bool
+	// unreachable is the outcome of the most recent finished probe.
+	unreachable []string
+}
+
+// newHostNetworkProber returns a hostNetworkProber that connects over TCP.
+func newHostNetworkProber() *hostNetworkProber {
+	return &hostNetworkProber{
+		dial: func(address string) error {
+			conn, err := net.DialTimeout("tcp", address, hostNetworkPortTimeout)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+		events: make(chan event.GenericEvent, 100),
+		probes: map[types.NamespacedName]*hostNetworkProbe{},
+	}
+}
+
+// probe starts probing the given targets for the named ingresscontroller unless
+// a probe of it is already running, and returns the outcome of its most recent
+// finished probe and whether any probe of it has finished.
+func (p *hostNetworkProber) probe(name types.NamespacedName, targets []hostNetworkProbeTarget) ([]string, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	state, ok := p.probes[name]
+	if !ok {
+		state = &hostNetworkProbe{}
+		p.probes[name] = state
+	}
+	if !state.running {
+		state.running = true
+		go func() {
+			unreachable := unreachableHostNetworkNodes(targets, p.dial)
+			p.lock.Lock()
+			state.running, state.finished, state.unreachable = false, true, unreachable
+			p.lock.Unlock()
+			if p.events == nil {
+				return
+			}
+			select {
+			case p.events <- event.GenericEvent{Meta: &metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}}:
+			default:
+				// The ingresscontroller is already queued or
+				// picks up the outcome on its next resync.
+			}
+		}()
+	}
+	return state.unreachable, state.finished
+}
+
+// forget discards the probe outcomes of the named ingresscontroller.  A probe
+// that is still running finishes without effect.
+func (p *hostNetworkProber) forget(name types.NamespacedName) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.probes, name)
+}
+
+// nodeInternalAddress returns the first internal IP address of the given node,
+// or the empty string if it has none.
+func nodeInternalAddress(node *corev1.Node) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			return address.Address
+		}
+	}
+	return ""
+}
+
+// hostNetworkNodesUnreachableCondition returns the HostNetworkNodesUnreachable
+// condition for the given descriptions of unreachable nodes.
+func hostNetworkNodesUnreachableCondition(unreachable []string) *operatorv1.OperatorCondition {
+	if len(unreachable) == 0 {
+		return &operatorv1.OperatorCondition{
+			Type:    HostNetworkNodesUnreachableConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "AllNodesReachable",
+			Message: "The router ports are reachable from the cluster network on each node with a ready router pod.",
+		}
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    HostNetworkNodesUnreachableConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "NodesUnreachable",
+		Message: fmt.Sprintf("The router ports are unreachable from the cluster network on some nodes with a ready router pod, which may be blocked by a firewall on the node: %s", strings.Join(unreachable, "; ")),
+	}
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestUsesHostNetworkNodeContract(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		expect      bool
+		expectErr   bool
+	}{
+		{"no annotation", "", operatorv1.HostNetworkStrategyType, false, false},
+		{"enabled with host network", "Enabled", operatorv1.HostNetworkStrategyType, true, false},
+		{"enabled with a load balancer", "Enabled", operatorv1.LoadBalancerServiceStrategyType, false, false},
+		{"disabled", "Disabled", operatorv1.HostNetworkStrategyType, false, false},
+		{"invalid value", "true", operatorv1.HostNetworkStrategyType, false, true},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(HostNetworkNodeContractAnnotation, tc.value)
+		ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{Type: tc.strategy}
+		actual, err := usesHostNetworkNodeContract(ic)
+		checkError(t, tc.description, tc.expectErr, err)
+		if actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestHostNetworkNodeUpdates(t *testing.T) {
+	node := func(name, router, ports string) corev1.Node {
+		node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(router) != 0 {
+			node.Labels = map[string]string{HostNetworkRouterNodeLabel: router}
+			node.Annotations = map[string]string{HostNetworkPortsNodeAnnotation: ports}
+		}
+		return node
+	}
+	nodes := []corev1.Node{
+		node("unlabeled", "", ""),
+		node("labeled", "default", "80,443"),
+		node("stale-ports", "default", "80"),
+		node("no-longer-a-router", "default", "80,443"),
+		node("other", "sharded", "80,443"),
+		node("idle", "", ""),
+	}
+	updates := hostNetworkNodeUpdates("default", nodes, sets.NewString("unlabeled", "labeled", "stale-ports"), []string{"80", "443"})
+	actual := map[string]string{}
+	for _, updated := range updates {
+		actual[updated.Name] = updated.Labels[HostNetworkRouterNodeLabel] + "/" + updated.Annotations[HostNetworkPortsNodeAnnotation]
+	}
+	expect := map[string]string{
+		"unlabeled":          "default/80,443",
+		"stale-ports":        "default/80,443",
+		"no-longer-a-router": "/",
+	}
+	if !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expected updates %v, got %v", expect, actual)
+	}
+	if nodes[0].Labels != nil {
+		t.Errorf("expected the given nodes not to be mutated, got labels %v", nodes[0].Labels)
+	}
+}
+
+func TestUnreachableHostNetworkNodes(t *testing.T) {
+	node := func(name, address string) corev1.Node {
+		node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(address) != 0 {
+			node.Status.Addresses = []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: name},
+				{Type: corev1.NodeInternalIP, Address: address},
+			}
+		}
+		return node
+	}
+	pod := func(node string, ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	nodes := []corev1.Node{
+		node("a", "10.0.0.1"),
+		node("b", "10.0.0.2"),
+		node("c", "10.0.0.3"),
+		node("no-address", ""),
+		node("no-router", "10.0.0.5"),
+	}
+	pods := []corev1.Pod{
+		pod("a", corev1.ConditionTrue),
+		pod("b", corev1.ConditionTrue),
+		pod("c", corev1.ConditionFalse),
+		pod("no-address", corev1.ConditionTrue),
+	}
+	targets, noAddress := hostNetworkProbeTargets(pods, nodes, []string{"80", "8443"})
+	if expect := []string{"no-address (no internal address)"}; !reflect.DeepEqual(noAddress, expect) {
+		t.Errorf("expected nodes without an address %v, got %v", expect, noAddress)
+	}
+	var (
+		lock   sync.Mutex
+		dialed []string
+	)
+	dial := func(address string) error {
+		lock.Lock()
+		defer lock.Unlock()
+		dialed = append(dialed, address)
+		if address == "10.0.0.2:8443" {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	}
+	unreachable := unreachableHostNetworkNodes(targets, dial)
+	if expect := []string{"b (10.0.0.2, ports 8443)"}; !reflect.DeepEqual(unreachable, expect) {
+		t.Errorf("expected %v, got %v", expect, unreachable)
+	}
+	sort.Strings(dialed)
+	expectDialed := []string{"10.0.0.1:80", "10.0.0.1:8443", "10.0.0.2:80", "10.0.0.2:8443"}
+	if !reflect.DeepEqual(dialed, expectDialed) {
+		t.Errorf("expected to dial %v, got %v", expectDialed, dialed)
+	}
+
+	condition := hostNetworkNodesUnreachableCondition(unreachable)
+	if condition.Status != operatorv1.ConditionTrue || condition.Reason != "NodesUnreachable" {
+		t.Errorf("expected True/NodesUnreachable, got %s/%s", condition.Status, condition.Reason)
+	}
+	if condition := hostNetworkNodesUnreachableCondition(nil); condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected False with no unreachable nodes, got %s", condition.Status)
+	}
+}
+
+func TestHostNetworkPorts(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{
+		{
+			Name: "router",
+			Ports: []corev1.ContainerPort{
+				{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
+				{Name: "https", ContainerPort: 8443, Protocol: corev1.ProtocolTCP},
+				{Name: "metrics", ContainerPort: 1936, Protocol: corev1.ProtocolTCP},
+			},
+		},
+		{
+			Name:  "logs",
+			Ports: []corev1.ContainerPort{{Name: "syslog", ContainerPort: 514, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+	if actual, expect := hostNetworkPorts(deployment), []string{"8080", "8443"}; !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expected %v, got %v", expect, actual)
+	}
+}
+
+func TestHostNetworkProber(t *testing.T) {
+	release := make(chan struct{})
+	prober := &hostNetworkProber{
+		dial: func(address string) error {
+			<-release
+			return fmt.Errorf("connection refused")
+		},
+		events: make(chan event.GenericEvent, 1),
+		probes: map[types.NamespacedName]*hostNetworkProbe{},
+	}
+	name := types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}
+	targets := []hostNetworkProbeTarget{{node: "a", address: "10.0.0.1", ports: []string{"80"}}}
+	// The first probe runs in the background, so there is no outcome yet,
+	// and no second probe starts while it runs.
+	if _, ok := prober.probe(name, targets); ok {
+		t.Fatal("expected no outcome before the first probe finishes")
+	}
+	if _, ok := prober.probe(name, targets); ok {
+		t.Fatal("expected no outcome while the first probe runs")
+	}
+	close(release)
+	select {
+	case e := <-prober.events:
+		if e.Meta.GetNamespace() != name.Namespace || e.Meta.GetName() != name.Name {
+			t.Errorf("expected an event for %s, got %s/%s", name, e.Meta.GetNamespace(), e.Meta.GetName())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected an event when the probe finished")
+	}
+	unreachable, ok := prober.probe(name, targets)
+	if expect := []string{"a (10.0.0.1, ports 80)"}; !ok || !reflect.DeepEqual(unreachable, expect) {
+		t.Errorf("expected outcome %v, got %v (finished: %t)", expect, unreachable, ok)
+	}
+	<-prober.events
+	prober.forget(name)
+	if _, ok := prober.probe(name, targets); ok {
+		t.Error("expected no outcome after the ingresscontroller was forgotten")
+	}
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// IngressClassControllerName is the controller name that is specified
+	// on the IngressClasses that the operator manages.  Ingresses that
+	// specify one of these classes are translated into routes that the
+	// corresponding ingresscontroller admits.
+	IngressClassControllerName = "openshift.io/ingress-to-route"
+
+	// IngressClassDomainAnnotation is an annotation on the IngressClasses
+	// that the operator manages with the domain of the corresponding
+	// ingresscontroller, under which the shard exposes routes that specify
+	// spec.subdomain.
+	IngressClassDomainAnnotation = "ingress.operator.openshift.io/domain"
+)
+
+// ingressClassGVK is the group, version, and kind of the IngressClass
+// resource.
+var ingressClassGVK = schema.GroupVersionKind{
+	Group:   "networking.k8s.io",
+	Version: "v1",
+	Kind:    "IngressClass",
+}
+
+// desiredIngressClass returns the desired IngressClass for the given
+// ingresscontroller.  The IngressClass refers to the ingresscontroller through
+// its parameters.
+func desiredIngressClass(ci *operatorv1.IngressController) *unstructured.Unstructured {
+	class := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"controller": IngressClassControllerName,
+				"parameters": map[string]interface{}{
+					"apiGroup":  operatorv1.GroupName,
+					"kind":      "IngressController",
+					"name":      ci.Name,
+					"scope":     "Namespace",
+					"namespace": ci.Namespace,
+				},
+			},
+		},
+	}
+	class.SetGroupVersionKind(ingressClassGVK)
+	class.SetName(IngressClassName(ci.Name).Name)
+	// IngressClasses are cluster-scoped and so cannot have an owner
+	// reference to the namespaced ingresscontroller.  The operator deletes
+	// the IngressClass when it finalizes the ingresscontroller.
+	class.SetLabels(map[string]string{
+		manifests.OwningIngressControllerLabel: ci.Name,
+	})
+	if len(ci.Status.Domain) != 0 {
+		class.SetAnnotations(map[string]string{
+			IngressClassDomainAnnotation: ci.Status.Domain,
+		})
+	}
+	return class
+}
+
+// ensureIngressClass creates or updates the IngressClass for the given
+// ingresscontroller.  If the cluster does not serve the IngressClass API, the
+// IngressClass is skipped.
+func (r *reconciler) ensureIngressClass(ci *operatorv1.IngressController) error {
+	current, installed, err := r.currentIngressClass(ci.Name)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		log.Info("the IngressClass API is not available; skipping ingressclass", "name", IngressClassName(ci.Name).Name)
+		return nil
+	}
+
+	desired := desiredIngressClass(ci)
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create ingressclass %s: %v", desired.GetName(), err)
+		}
+		log.Info("created ingressclass", "name", desired.GetName())
+		return nil
+	}
+	if changed, updated := ingressClassChanged(current, desired); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update ingressclass %s: %v", updated.GetName(), err)
+		}
+		log.Info("updated ingressclass", "name", updated.GetName())
+	}
+	return nil
+}
+
+// ingressClassChanged checks whether the current IngressClass matches the
+// expected IngressClass and if not returns an updated one.
+func ingressClassChanged(current, expected *unstructured.Unstructured) (bool, *unstructured.Unstructured) {
+	currentLabels := current.GetLabels()
+	labelsMatch := true
+	for k, v := range expected.GetLabels() {
+		if currentLabels[k] != v {
+			labelsMatch = false
+		}
+	}
+	currentAnnotations := current.GetAnnotations()
+	for k, v := range expected.GetAnnotations() {
+		if currentAnnotations[k] != v {
+			labelsMatch = false
+		}
+	}
+	if labelsMatch && reflect.DeepEqual(current.Object["spec"], expected.Object["spec"]) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Object["spec"] = expected.Object["spec"]
+	labels := updated.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range expected.GetLabels() {
+		labels[k] = v
+	}
+	updated.SetLabels(labels)
+	annotations := updated.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range expected.GetAnnotations() {
+		annotations[k] = v
+	}
+	updated.SetAnnotations(annotations)
+	return true, updated
+}
+
+// ensureIngressClassDeleted deletes the IngressClass for the given
+// ingresscontroller if it exists.
+func (r *reconciler) ensureIngressClassDeleted(ci *operatorv1.IngressController) error {
+	current, _, err := r.currentIngressClass(ci.Name)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ingressclass %s: %v", current.GetName(), err)
+	}
+	log.Info("deleted ingressclass", "name", current.GetName())
+	return nil
+}
+
+// currentIngressClass returns the current IngressClass for the
+// ingresscontroller with the given name, or nil if it does not exist.  It also
+// returns false if the cluster does not serve the IngressClass API.
+func (r *reconciler) currentIngressClass(icName string) (*unstructured.Unstructured, bool, error) {
+	name := IngressClassName(icName)
+	class := &unstructured.Unstructured{}
+	class.SetGroupVersionKind(ingressClassGVK)
+	switch err := r.client.Get(context.TODO(), name, class); {
+	case err == nil:
+		return class, true, nil
+	case meta.IsNoMatchError(err):
+		return nil, false, nil
+	case errors.IsNotFound(err):
+		return nil, true, nil
+	default:
+		return nil, false, fmt.Errorf("failed to get ingressclass %s: %v", name.Name, err)
+	}
+}
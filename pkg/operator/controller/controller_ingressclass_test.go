@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDesiredIngressClass(t *testing.T) {
+	ci := &operatorv1.IngressController{}
+	ci.Namespace = "openshift-ingress-operator"
+	ci.Name = "sharded"
+	ci.Status.Domain = "sharded.apps.example.com"
+
+	class := desiredIngressClass(ci)
+	if class.GetName() != "openshift-sharded" {
+		t.Errorf("expected name openshift-sharded, got %s", class.GetName())
+	}
+	if controller, _, _ := unstructured.NestedString(class.Object, "spec", "controller"); controller != IngressClassControllerName {
+		t.Errorf("expected controller %s, got %s", IngressClassControllerName, controller)
+	}
+	if name, _, _ := unstructured.NestedString(class.Object, "spec", "parameters", "name"); name != ci.Name {
+		t.Errorf("expected parameters to refer to ingresscontroller %s, got %s", ci.Name, name)
+	}
+	if class.GetLabels()[manifests.OwningIngressControllerLabel] != ci.Name {
+		t.Errorf("expected owning ingresscontroller label, got %v", class.GetLabels())
+	}
+	if class.GetAnnotations()[IngressClassDomainAnnotation] != ci.Status.Domain {
+		t.Errorf("expected domain annotation %q, got %v", ci.Status.Domain, class.GetAnnotations())
+	}
+}
+
+func TestIngressClassChanged(t *testing.T) {
+	ci := &operatorv1.IngressController{}
+	ci.Namespace = "openshift-ingress-operator"
+	ci.Name = "default"
+	ci.Status.Domain = "apps.example.com"
+
+	testCases := []struct {
+		description string
+		mutate      func(*unstructured.Unstructured)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(_ *unstructured.Unstructured) {},
+			expect:      false,
+		},
+		{
+			description: "if an unrelated label is added",
+			mutate: func(class *unstructured.Unstructured) {
+				labels := class.GetLabels()
+				labels["foo"] = "bar"
+				class.SetLabels(labels)
+			},
+			expect: false,
+		},
+		{
+			description: "if the owning ingresscontroller label is removed",
+			mutate: func(class *unstructured.Unstructured) {
+				class.SetLabels(nil)
+			},
+			expect: true,
+		},
+		{
+			description: "if the domain annotation changes",
+			mutate: func(class *unstructured.Unstructured) {
+				class.SetAnnotations(map[string]string{IngressClassDomainAnnotation: "apps.old.example.com"})
+			},
+			expect: true,
+		},
+		{
+			description: "if the controller changes",
+			mutate: func(class *unstructured.Unstructured) {
+				unstructured.SetNestedField(class.Object, "example.com/other", "spec", "controller")
+			},
+			expect: true,
+		},
+		{
+			description: "if the parameters are removed",
+			mutate: func(class *unstructured.Unstructured) {
+				unstructured.RemoveNestedField(class.Object, "spec", "parameters")
+			},
+			expect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		original := desiredIngressClass(ci)
+		mutated := original.DeepCopy()
+		tc.mutate(mutated)
+		if changed, updated := ingressClassChanged(mutated, original); changed != tc.expect {
+			t.Errorf("%s, expect ingressClassChanged to be %t, got %t", tc.description, tc.expect, changed)
+		} else if changed {
+			if changedAgain, _ := ingressClassChanged(updated, original); changedAgain {
+				t.Errorf("%s, ingressClassChanged does not behave as a fixed point function", tc.description)
+			}
+		}
+	}
+}
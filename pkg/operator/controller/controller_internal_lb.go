@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// InternalLoadBalancerAnnotation may be set on an ingresscontroller
+	// that uses the LoadBalancerService endpoint publishing strategy with
+	// an external load balancer to publish it additionally through an
+	// internal load balancer.  The value is "Enabled" or "Disabled" (the
+	// default).  When enabled, the operator creates a second LB service
+	// that requests an internal load balancer, and the wildcard DNS record
+	// resolves to the external load balancer in the cluster's public zone
+	// and to the internal load balancer in the cluster's private zone, so
+	// that clients in the cluster's network reach the routers without
+	// leaving it.
+	InternalLoadBalancerAnnotation = "ingress.operator.openshift.io/internal-load-balancer"
+
+	// internalLoadBalancerEnabledValue and internalLoadBalancerDisabledValue are
+	// the values of InternalLoadBalancerAnnotation.
+	internalLoadBalancerEnabledValue  = "Enabled"
+	internalLoadBalancerDisabledValue = "Disabled"
+)
+
+// internalLoadBalancerEnabled returns true if the given ingresscontroller
+// requests an additional internal load balancer.
+func internalLoadBalancerEnabled(ic *operatorv1.IngressController) (bool, error) {
+	switch value := ic.Annotations[InternalLoadBalancerAnnotation]; value {
+	case "", internalLoadBalancerDisabledValue:
+		return false, nil
+	case internalLoadBalancerEnabledValue:
+		if ic.Annotations[LoadBalancerScopeAnnotation] == internalLoadBalancerScope {
+			return false, fmt.Errorf("invalid value for annotation %s: %q: the load balancer is already internal", InternalLoadBalancerAnnotation, value)
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", InternalLoadBalancerAnnotation, value, internalLoadBalancerEnabledValue, internalLoadBalancerDisabledValue)
+	}
+}
+
+// desiredInternalLoadBalancerService returns the desired additional internal
+// LB service for the given ingresscontroller, or nil if it does not request
+// one.  The service is the same as the ingresscontroller's LB service except
+// that it requests an internal load balancer, leaves the health check node
+// port to be allocated, and does not carry external-dns annotations.
+func desiredInternalLoadBalancerService(ci *operatorv1.IngressController, operandNamespace string, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) (*corev1.Service, error) {
+	if ci.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
+		return nil, nil
+	}
+	enabled, err := internalLoadBalancerEnabled(ci)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	if _, ok := internalLBAnnotations[infraConfig.Status.Platform]; !ok {
+		return nil, fmt.Errorf("invalid value for annotation %s: %q: internal load balancers are not supported on platform %q", InternalLoadBalancerAnnotation, internalLoadBalancerEnabledValue, infraConfig.Status.Platform)
+	}
+	internal := ci.DeepCopy()
+	internal.Annotations[LoadBalancerScopeAnnotation] = internalLoadBalancerScope
+	service, err := desiredLoadBalancerService(internal, operandNamespace, deploymentRef, infraConfig)
+	if err != nil {
+		return nil, err
+	}
+	name := InternalLoadBalancerServiceName(ci, operandNamespace)
+	service.Name = name.Name
+	service.Labels["router"] = name.Name
+	// Two services cannot share a health check node port.
+	service.Spec.HealthCheckNodePort = 0
+	// The operator deletes the internal DNSRecord itself before it deletes
+	// the service, so the service needs no finalizer.
+	service.Finalizers = nil
+	return service, nil
+}
+
+// desiredInternalWildcardRecord returns the desired DNSRecord that resolves
+// the wildcard domain of the given ingresscontroller to the given internal LB
+// hostname or IP address in the cluster's private zone, or nil if no record is
+// needed.
+func desiredInternalWildcardRecord(ci *operatorv1.IngressController, target string) *ingressv1.DNSRecord {
+	if len(ci.Status.Domain) == 0 || usesExternalDNS(ci) {
+		return nil
+	}
+	record := wildcardRecordFor(ci, InternalWildcardDNSRecordName(ci), target)
+	record.Spec.ZoneType = ingressv1.PrivateZoneType
+	return record
+}
+
+// ensureInternalLoadBalancer ensures that the additional internal LB service
+// and its DNSRecord exist if the given ingresscontroller requests them, and
+// that they do not exist otherwise.  The DNSRecord is deleted, and the
+// deletion is waited for, before the service so that the published record
+// never points to a deleted load balancer and so that the public wildcard
+// record is not published to the private zone until the internal record is
+// gone from it.
+func (r *reconciler) ensureInternalLoadBalancer(ci *operatorv1.IngressController, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) error {
+	desired, err := desiredInternalLoadBalancerService(ci, r.Config.OperandNamespace, deploymentRef, infraConfig)
+	if err != nil {
+		return newTerminalError(err)
+	}
+	if err := r.setLoadBalancerResourceTags(desired, infraConfig); err != nil {
+		return err
+	}
+	name := InternalLoadBalancerServiceName(ci, r.Config.OperandNamespace)
+	current := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get service %s: %v", name, err)
+		}
+		current = nil
+	}
+	if desired == nil {
+		return r.ensureInternalLoadBalancerDeleted(ci, current, false)
+	}
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create internal load balancer service %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created internal load balancer service", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if current, err = r.updateService(current, desired); err != nil {
+		return err
+	}
+	if !isLoadBalancerProvisioned(current) {
+		return nil
+	}
+	record := desiredInternalWildcardRecord(ci, loadBalancerAddress(current))
+	if record == nil {
+		return nil
+	}
+	currentRecord, err := r.currentDNSRecord(InternalWildcardDNSRecordName(ci))
+	if err != nil {
+		return err
+	}
+	return r.ensureDNSRecord(currentRecord, record)
+}
+
+// ensureInternalLoadBalancerDeleted deletes the internal DNSRecord of the given
+// ingresscontroller, waits for it to be finalized, and then deletes the given
+// internal LB service if it is not nil.  If skipDNS is true, the published
+// records are left as they are.
+func (r *reconciler) ensureInternalLoadBalancerDeleted(ci *operatorv1.IngressController, service *corev1.Service, skipDNS bool) error {
+	if err := r.ensureDNSRecordDeleted(InternalWildcardDNSRecordName(ci), skipDNS); err != nil {
+		return err
+	}
+	if service == nil {
+		return nil
+	}
+	if err := r.client.Delete(context.TODO(), service); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete internal load balancer service %s/%s: %v", service.Namespace, service.Name, err)
+	}
+	log.Info("deleted internal load balancer service", "namespace", service.Namespace, "name", service.Name)
+	return nil
+}
+
+// finalizeInternalLoadBalancer deletes the additional internal LB service and
+// its DNSRecord of the given ingresscontroller, if they exist.
+func (r *reconciler) finalizeInternalLoadBalancer(ci *operatorv1.IngressController, skipDNS bool) error {
+	name := InternalLoadBalancerServiceName(ci, r.Config.OperandNamespace)
+	service := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), name, service); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get service %s: %v", name, err)
+		}
+		service = nil
+	}
+	return r.ensureInternalLoadBalancerDeleted(ci, service, skipDNS)
+}
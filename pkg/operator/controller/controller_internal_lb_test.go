@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredInternalLoadBalancerService verifies that
+// desiredInternalLoadBalancerService renders an internal LB service only if
+// the ingresscontroller requests one and rejects invalid requests.
+func TestDesiredInternalLoadBalancerService(t *testing.T) {
+	testCases := []struct {
+		description   string
+		annotations   map[string]string
+		strategy      operatorv1.EndpointPublishingStrategyType
+		platform      configv1.PlatformType
+		expectService bool
+		expectError   bool
+	}{
+		{
+			description: "no annotation",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.AWSPlatformType,
+		},
+		{
+			description: "disabled",
+			annotations: map[string]string{InternalLoadBalancerAnnotation: "Disabled"},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.AWSPlatformType,
+		},
+		{
+			description:   "enabled",
+			annotations:   map[string]string{InternalLoadBalancerAnnotation: "Enabled"},
+			strategy:      operatorv1.LoadBalancerServiceStrategyType,
+			platform:      configv1.AWSPlatformType,
+			expectService: true,
+		},
+		{
+			description: "enabled with host network",
+			annotations: map[string]string{InternalLoadBalancerAnnotation: "Enabled"},
+			strategy:    operatorv1.HostNetworkStrategyType,
+			platform:    configv1.AWSPlatformType,
+		},
+		{
+			description: "enabled with an internal load balancer",
+			annotations: map[string]string{
+				InternalLoadBalancerAnnotation: "Enabled",
+				LoadBalancerScopeAnnotation:    "Internal",
+			},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.AWSPlatformType,
+			expectError: true,
+		},
+		{
+			description: "invalid value",
+			annotations: map[string]string{InternalLoadBalancerAnnotation: "true"},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.AWSPlatformType,
+			expectError: true,
+		},
+		{
+			description: "unsupported platform",
+			annotations: map[string]string{InternalLoadBalancerAnnotation: "Enabled"},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.NonePlatformType,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: tc.annotations},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: tc.strategy,
+				},
+			},
+		}
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{Platform: tc.platform},
+		}
+		service, err := desiredInternalLoadBalancerService(ic, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+		checkError(t, tc.description, tc.expectError, err)
+		switch {
+		case !tc.expectService && service != nil:
+			t.Errorf("%q: expected no service, got %#v", tc.description, service)
+		case tc.expectService && service == nil:
+			t.Errorf("%q: expected a service, got nil", tc.description)
+		case tc.expectService:
+			if expected := InternalLoadBalancerServiceName(ic, DefaultOperandNamespace).Name; service.Name != expected {
+				t.Errorf("%q: expected service name %s, got %s", tc.description, expected, service.Name)
+			}
+			annotation := internalLBAnnotations[tc.platform]
+			if service.Annotations[annotation.key] != annotation.value {
+				t.Errorf("%q: expected annotation %s=%s, got %v", tc.description, annotation.key, annotation.value, service.Annotations)
+			}
+			if ic.Annotations[LoadBalancerScopeAnnotation] != "" {
+				t.Errorf("%q: the ingresscontroller was mutated", tc.description)
+			}
+			if service.Spec.HealthCheckNodePort != 0 || len(service.Finalizers) != 0 {
+				t.Errorf("%q: expected no health check node port and no finalizers, got %#v", tc.description, service)
+			}
+		}
+	}
+}
+
+// TestDesiredInternalWildcardRecord verifies that the internal wildcard
+// DNSRecord is published only to the private zone and that the primary
+// wildcard DNSRecord is published only to the public zone when the
+// ingresscontroller has an internal load balancer.
+func TestDesiredInternalWildcardRecord(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress-operator",
+			Name:        "default",
+			Annotations: map[string]string{InternalLoadBalancerAnnotation: "Enabled"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	internal := desiredInternalWildcardRecord(ic, "internal.example.com")
+	if internal == nil {
+		t.Fatal("expected an internal wildcard record")
+	}
+	if internal.Name != InternalWildcardDNSRecordName(ic).Name || internal.Spec.ZoneType != ingressv1.PrivateZoneType {
+		t.Errorf("unexpected internal wildcard record %#v", internal)
+	}
+	if internal.Spec.DNSName != "*.apps.example.com" || internal.Spec.Targets[0] != "internal.example.com" {
+		t.Errorf("unexpected internal wildcard record spec %#v", internal.Spec)
+	}
+	if primary := desiredWildcardRecord(ic, "external.example.com"); primary == nil || primary.Spec.ZoneType != ingressv1.PublicZoneType {
+		t.Errorf("expected the wildcard record to be public, got %#v", primary)
+	}
+	delete(ic.Annotations, InternalLoadBalancerAnnotation)
+	if primary := desiredWildcardRecord(ic, "external.example.com"); primary == nil || primary.Spec.ZoneType != "" {
+		t.Errorf("expected the wildcard record to be published to every zone, got %#v", primary)
+	}
+}
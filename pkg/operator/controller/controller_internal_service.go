@@ -17,18 +17,49 @@ const (
 	// Annotation used to inform the certificate generation service to
 	// generate a cluster-signed certificate and populate the secret.
 	ServingCertSecretAnnotation = "service.alpha.openshift.io/serving-cert-secret-name"
+
+	// TopologyAwareHintsAnnotation may be set to "Enabled" on an
+	// IngressController to enable topology-aware hints on its internal
+	// router service.  The endpointslice controller then hints clients in
+	// each zone to use the routers in the same zone, if the zones have
+	// enough routers, which keeps in-cluster traffic to the routers from
+	// crossing zones.  The default is "Disabled".
+	TopologyAwareHintsAnnotation = "ingress.operator.openshift.io/topology-aware-hints"
+
+	// serviceTopologyAwareHintsAnnotation is the service annotation that
+	// enables topology-aware hints for the service's endpoints.
+	serviceTopologyAwareHintsAnnotation = "service.kubernetes.io/topology-aware-hints"
+
+	topologyAwareHintsEnabledValue  = "Enabled"
+	topologyAwareHintsDisabledValue = "Disabled"
 )
 
+// topologyAwareHintsEnabled returns true if the given ingresscontroller
+// enables TopologyAwareHintsAnnotation.
+func topologyAwareHintsEnabled(ic *operatorv1.IngressController) (bool, error) {
+	switch value := ic.Annotations[TopologyAwareHintsAnnotation]; value {
+	case "", topologyAwareHintsDisabledValue:
+		return false, nil
+	case topologyAwareHintsEnabledValue:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", TopologyAwareHintsAnnotation, value, topologyAwareHintsEnabledValue, topologyAwareHintsDisabledValue)
+	}
+}
+
 // ensureInternalRouterServiceForIngress ensures that an internal service exists
 // for a given IngressController.
 func (r *reconciler) ensureInternalIngressControllerService(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (*corev1.Service, error) {
-	desired := desiredInternalIngressControllerService(ic, deploymentRef)
+	desired, err := desiredInternalIngressControllerService(ic, r.Config.OperandNamespace, deploymentRef)
+	if err != nil {
+		return nil, newTerminalError(err)
+	}
 	current, err := r.currentInternalIngressControllerService(ic)
 	if err != nil {
 		return nil, err
 	}
 	if current != nil {
-		return current, nil
+		return r.updateService(current, desired)
 	}
 
 	if err := r.client.Create(context.TODO(), desired); err != nil {
@@ -40,7 +71,7 @@ func (r *reconciler) ensureInternalIngressControllerService(ic *operatorv1.Ingre
 
 func (r *reconciler) currentInternalIngressControllerService(ic *operatorv1.IngressController) (*corev1.Service, error) {
 	current := &corev1.Service{}
-	err := r.client.Get(context.TODO(), InternalIngressControllerServiceName(ic), current)
+	err := r.client.Get(context.TODO(), InternalIngressControllerServiceName(ic, r.Config.OperandNamespace), current)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil, nil
@@ -50,10 +81,10 @@ func (r *reconciler) currentInternalIngressControllerService(ic *operatorv1.Ingr
 	return current, nil
 }
 
-func desiredInternalIngressControllerService(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) *corev1.Service {
+func desiredInternalIngressControllerService(ic *operatorv1.IngressController, operandNamespace string, deploymentRef metav1.OwnerReference) (*corev1.Service, error) {
 	s := manifests.InternalIngressControllerService()
 
-	name := InternalIngressControllerServiceName(ic)
+	name := InternalIngressControllerServiceName(ic, operandNamespace)
 
 	s.Namespace = name.Namespace
 	s.Name = name.Name
@@ -67,9 +98,30 @@ func desiredInternalIngressControllerService(ic *operatorv1.IngressController, d
 		ServingCertSecretAnnotation: fmt.Sprintf("router-metrics-certs-%s", ic.Name),
 	}
 
+	hints, err := topologyAwareHintsEnabled(ic)
+	if err != nil {
+		return nil, err
+	}
+	if hints {
+		s.Annotations[serviceTopologyAwareHintsAnnotation] = "Auto"
+	}
+
 	s.Spec.Selector = IngressControllerDeploymentPodSelector(ic).MatchLabels
+	if err := configureServiceSessionAffinity(s, ic); err != nil {
+		return nil, err
+	}
+	if err := configureStatsServicePort(s, ic); err != nil {
+		return nil, err
+	}
+
+	operandLabels, operandAnnotations, err := operandMetadata(ic)
+	if err != nil {
+		return nil, err
+	}
+	addOperandMetadata(&s.ObjectMeta, operandLabels, operandAnnotations)
+	setManagedServiceKeys(s)
 
 	s.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
 
-	return s
+	return s, nil
 }
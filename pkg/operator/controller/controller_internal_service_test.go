@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredInternalIngressControllerServiceTopologyAwareHints(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       string
+		expectHints bool
+		expectErr   bool
+	}{
+		{description: "no annotation"},
+		{description: "enabled", value: "Enabled", expectHints: true},
+		{description: "disabled", value: "Disabled"},
+		{description: "invalid value", value: "Auto", expectErr: true},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(TopologyAwareHintsAnnotation, tc.value)
+		service, err := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		value, ok := service.Annotations[serviceTopologyAwareHintsAnnotation]
+		switch {
+		case tc.expectHints && value != "Auto":
+			t.Errorf("%q: expected annotation %s=Auto, got %q", tc.description, serviceTopologyAwareHintsAnnotation, value)
+		case !tc.expectHints && ok:
+			t.Errorf("%q: expected no annotation %s, got %q", tc.description, serviceTopologyAwareHintsAnnotation, value)
+		}
+	}
+}
+
+// TestServiceChangedRemovesTopologyAwareHints verifies that disabling
+// topology-aware hints removes the annotation from the internal service.
+func TestServiceChangedRemovesTopologyAwareHints(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{TopologyAwareHintsAnnotation: "Enabled"},
+		},
+	}
+	current, err := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ic.Annotations = nil
+	expected, err := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed, updated := serviceChanged(current, expected)
+	if !changed {
+		t.Fatal("expected the service to change")
+	}
+	if _, ok := updated.Annotations[serviceTopologyAwareHintsAnnotation]; ok {
+		t.Errorf("expected annotation %s to be removed", serviceTopologyAwareHintsAnnotation)
+	}
+	if changed, _ := serviceChanged(updated, expected); changed {
+		t.Error("expected the updated service not to change again")
+	}
+}
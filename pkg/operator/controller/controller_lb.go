@@ -12,62 +12,98 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
 const (
-	// loadBalancerServiceFinalizer is applied to load balancer services to ensure
+	// LoadBalancerServiceFinalizer is applied to load balancer services to ensure
 	// we can manage deletion of associated DNS records.
-	loadBalancerServiceFinalizer = "ingress.openshift.io/operator"
+	LoadBalancerServiceFinalizer = "ingress.openshift.io/operator"
 
 	// awsLBProxyProtocolAnnotation is used to enable the PROXY protocol on any
 	// AWS load balancer services created.
 	awsLBProxyProtocolAnnotation = "service.beta.kubernetes.io/aws-load-balancer-proxy-protocol"
+
+	// LoadBalancerScopeAnnotation may be set on an IngressController to
+	// select whether its load balancer is reachable from the internet
+	// ("External", the default) or only from the cluster's network
+	// ("Internal").  Cloud providers do not change the scope of an
+	// existing load balancer, so changing the scope replaces the load
+	// balancer service.
+	LoadBalancerScopeAnnotation = "ingress.operator.openshift.io/load-balancer-scope"
+
+	// externalLoadBalancerScope and internalLoadBalancerScope are the
+	// values of LoadBalancerScopeAnnotation.
+	externalLoadBalancerScope = "External"
+	internalLoadBalancerScope = "Internal"
 )
 
-// ensureLoadBalancerService creates an LB service if one is desired but absent.
-// Always returns the current LB service if one exists (whether it already
-// existed or was created during the course of the function).
+// internalLBAnnotations maps platforms to the service annotation and value
+// that request an internal load balancer on the platform.
+var internalLBAnnotations = map[configv1.PlatformType]struct{ key, value string }{
+	configv1.AWSPlatformType:       {"service.beta.kubernetes.io/aws-load-balancer-internal", "0.0.0.0/0"},
+	configv1.AzurePlatformType:     {"service.beta.kubernetes.io/azure-load-balancer-internal", "true"},
+	configv1.GCPPlatformType:       {"cloud.google.com/load-balancer-type", "Internal"},
+	configv1.OpenStackPlatformType: {"service.beta.kubernetes.io/openstack-internal-load-balancer", "true"},
+}
+
+// ensureLoadBalancerService creates an LB service if one is desired but absent,
+// and updates it if the fields that the operator manages have changed.  If a
+// changed setting requires a new load balancer, the LB service is replaced
+// (see ensureReplacementLoadBalancerService).  Always returns the LB service
+// that the wildcard DNS record should point to if one exists (whether it
+// already existed or was created during the course of the function).
 func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) (*corev1.Service, error) {
-	desiredLBService, err := desiredLoadBalancerService(ci, deploymentRef, infraConfig)
+	desiredLBService, err := desiredLoadBalancerService(ci, r.Config.OperandNamespace, deploymentRef, infraConfig)
 	if err != nil {
+		return nil, newTerminalError(err)
+	}
+	if err := r.setLoadBalancerResourceTags(desiredLBService, infraConfig); err != nil {
 		return nil, err
 	}
 
-	currentLBService, err := r.currentLoadBalancerService(ci)
+	currentLBService, otherLBService, err := r.currentLoadBalancerServices(ci)
 	if err != nil {
 		return nil, err
 	}
-	if desiredLBService != nil && currentLBService == nil {
+	if desiredLBService == nil {
+		return currentLBService, nil
+	}
+	if currentLBService == nil {
 		if err := r.client.Create(context.TODO(), desiredLBService); err != nil {
 			return nil, fmt.Errorf("failed to create load balancer service %s/%s: %v", desiredLBService.Namespace, desiredLBService.Name, err)
 		}
 		log.Info("created load balancer service", "namespace", desiredLBService.Namespace, "name", desiredLBService.Name)
 		return desiredLBService, nil
 	}
-	return currentLBService, nil
-}
-
-// TODO: This should take operator config into account so that the operand
-// namespace isn't hard-coded.
-func loadBalancerServiceName(ci *operatorv1.IngressController) types.NamespacedName {
-	return types.NamespacedName{Namespace: "openshift-ingress", Name: "router-" + ci.Name}
+	// The current LB service may have the alternate name if it replaced
+	// another one.
+	setLoadBalancerServiceName(desiredLBService, currentLBService.Name)
+	// The old LB service of a promoted replacement must be deleted before
+	// the LB service can be replaced again.
+	if otherLBService != nil && slice.ContainsString(otherLBService.Finalizers, LoadBalancerServiceFinalizer) {
+		return currentLBService, nil
+	}
+	needsReplacement := loadBalancerServiceNeedsRecreation(currentLBService, desiredLBService)
+	if !needsReplacement {
+		if currentLBService, err = r.updateService(currentLBService, desiredLBService); err != nil {
+			return nil, err
+		}
+	}
+	return r.ensureReplacementLoadBalancerService(ci, currentLBService, otherLBService, desiredLBService, needsReplacement)
 }
 
 // desiredLoadBalancerService returns the desired LB service for a
 // ingresscontroller, or nil if an LB service isn't desired. An LB service is
 // desired if the high availability type is Cloud. An LB service will declare an
 // owner reference to the given deployment.
-func desiredLoadBalancerService(ci *operatorv1.IngressController, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) (*corev1.Service, error) {
+func desiredLoadBalancerService(ci *operatorv1.IngressController, operandNamespace string, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) (*corev1.Service, error) {
 	if ci.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
 		return nil, nil
 	}
 	service := manifests.LoadBalancerService()
 
-	name := loadBalancerServiceName(ci)
+	name := LoadBalancerServiceName(ci, operandNamespace)
 
 	service.Namespace = name.Namespace
 	service.Name = name.Name
@@ -86,68 +122,73 @@ func desiredLoadBalancerService(ci *operatorv1.IngressController, deploymentRef
 		}
 		service.Annotations[awsLBProxyProtocolAnnotation] = "*"
 	}
+	switch scope := ci.Annotations[LoadBalancerScopeAnnotation]; scope {
+	case "", externalLoadBalancerScope:
+	case internalLoadBalancerScope:
+		annotation, ok := internalLBAnnotations[infraConfig.Status.Platform]
+		if !ok {
+			return nil, fmt.Errorf("invalid value for annotation %s: %q: internal load balancers are not supported on platform %q", LoadBalancerScopeAnnotation, scope, infraConfig.Status.Platform)
+		}
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		service.Annotations[annotation.key] = annotation.value
+	default:
+		return nil, fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", LoadBalancerScopeAnnotation, scope, externalLoadBalancerScope, internalLoadBalancerScope)
+	}
+	if err := configureAWSLoadBalancerHealthCheck(service, ci, infraConfig); err != nil {
+		return nil, err
+	}
+	if err := configureLoadBalancerHealthCheckPath(service, ci, infraConfig); err != nil {
+		return nil, err
+	}
+	healthCheckNodePort, err := loadBalancerHealthCheckNodePort(ci)
+	if err != nil {
+		return nil, err
+	}
+	service.Spec.HealthCheckNodePort = healthCheckNodePort
+	if err := configureServiceSessionAffinity(service, ci); err != nil {
+		return nil, err
+	}
+	operandLabels, operandAnnotations, err := operandMetadata(ci)
+	if err != nil {
+		return nil, err
+	}
+	addOperandMetadata(&service.ObjectMeta, operandLabels, operandAnnotations)
+	setManagedServiceKeys(service)
 	service.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
-	service.Finalizers = []string{loadBalancerServiceFinalizer}
+	service.Finalizers = []string{LoadBalancerServiceFinalizer}
 	return service, nil
 }
 
 // currentLoadBalancerService returns any existing LB service for the
-// ingresscontroller.
+// ingresscontroller.  While the LB service is being replaced, this is the LB
+// service that is being replaced.
 func (r *reconciler) currentLoadBalancerService(ci *operatorv1.IngressController) (*corev1.Service, error) {
-	service := &corev1.Service{}
-	if err := r.client.Get(context.TODO(), loadBalancerServiceName(ci), service); err != nil {
-		if errors.IsNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return service, nil
+	current, _, err := r.currentLoadBalancerServices(ci)
+	return current, err
 }
 
-// finalizeLoadBalancerService deletes any DNS entries associated with any
-// current LB service associated with the ingresscontroller and then finalizes the
-// service.
-func (r *reconciler) finalizeLoadBalancerService(ci *operatorv1.IngressController, dnsConfig *configv1.DNS) error {
-	service, err := r.currentLoadBalancerService(ci)
+// finalizeLoadBalancerService deletes the wildcard DNSRecord for the
+// ingresscontroller, waits for the dnsrecord controller to delete the
+// associated DNS entries, and then finalizes the LB services.  If skipDNS is
+// true, DNS entries are left as they are.
+func (r *reconciler) finalizeLoadBalancerService(ci *operatorv1.IngressController, skipDNS bool) error {
+	if err := r.ensureWildcardRecordDeleted(ci, skipDNS); err != nil {
+		return err
+	}
+	current, other, err := r.currentLoadBalancerServices(ci)
 	if err != nil {
 		return err
 	}
-	if service == nil {
-		return nil
-	}
-	// We cannot published DNS records for a load balancer till it has been
-	// provisioned.  Thus if the service's status does not _currently_
-	// indicate that a load balancer has been provisioned, that means we
-	// _probably_ have not published any DNS records (and if we have, then
-	// we have lost track of them).
-	//
-	// TODO: Instead of trying to infer whether DNS records exist by looking
-	// at the service, we should be maintaining state with any DNS records
-	// that we have created for the ingresscontroller, for example by using
-	// an annotation on the ingresscontroller.
-	ingress := service.Status.LoadBalancer.Ingress
-	if len(ingress) > 0 && len(ingress[0].Hostname) > 0 {
-		records, err := desiredDNSRecords(ci, ingress[0].Hostname, dnsConfig)
-		if err != nil {
-			return err
-		}
-		dnsErrors := []error{}
-		for _, record := range records {
-			if err := r.DNSManager.Delete(record); err != nil {
-				dnsErrors = append(dnsErrors, fmt.Errorf("failed to delete DNS record %v for ingress %s/%s: %v", record, ci.Namespace, ci.Name, err))
-			} else {
-				log.Info("deleted DNS record for ingress", "namespace", ci.Namespace, "name", ci.Name, "record", record)
-			}
+	for _, service := range []*corev1.Service{current, other} {
+		if service == nil || !slice.ContainsString(service.Finalizers, LoadBalancerServiceFinalizer) {
+			continue
 		}
-		if err := utilerrors.NewAggregate(dnsErrors); err != nil {
-			return err
-		}
-	}
-	// Mutate a copy to avoid assuming we know where the current one came from
-	// (i.e. it could have been from a cache).
-	updated := service.DeepCopy()
-	if slice.ContainsString(updated.Finalizers, loadBalancerServiceFinalizer) {
-		updated.Finalizers = slice.RemoveString(updated.Finalizers, loadBalancerServiceFinalizer)
+		// Mutate a copy to avoid assuming we know where the current one
+		// came from (i.e. it could have been from a cache).
+		updated := service.DeepCopy()
+		updated.Finalizers = slice.RemoveString(updated.Finalizers, LoadBalancerServiceFinalizer)
 		if err := r.client.Update(context.TODO(), updated); err != nil {
 			return fmt.Errorf("failed to remove finalizer from service %s for ingress %s/%s: %v", service.Namespace, service.Name, ci.Name, err)
 		}
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AWSLoadBalancerHealthCheckAnnotation may be set on an
+	// ingresscontroller that uses the LoadBalancerService endpoint
+	// publishing strategy on AWS to tune the health check of its load
+	// balancer, which determines how quickly traffic fails over from a
+	// router that stops responding.  The value is a JSON object with any
+	// of the following fields, for example:
+	//
+	//   {"intervalSeconds": 5, "timeoutSeconds": 2, "healthyThreshold": 2, "unhealthyThreshold": 2}
+	//
+	// Fields that are not specified keep the cloud provider's defaults.
+	// Removing a field removes the corresponding annotation from the load
+	// balancer service, which restores the default.
+	AWSLoadBalancerHealthCheckAnnotation = "ingress.operator.openshift.io/aws-load-balancer-health-check"
+
+	// LoadBalancerHealthCheckNodePortAnnotation may be set on an
+	// ingresscontroller that uses the LoadBalancerService endpoint
+	// publishing strategy to choose the node port on which cloud load
+	// balancers probe the health of the router, for example so that
+	// firewall rules can allow the probes to a known port.  Because the
+	// load balancer service uses the Local external traffic policy, Azure
+	// and GCP load balancers probe the kube-proxy health check on this
+	// port rather than the router itself.  The value is a port number
+	// within the cluster's default node port range.  The health check node
+	// port of an existing service cannot be changed, so changing this
+	// annotation recreates the load balancer.
+	LoadBalancerHealthCheckNodePortAnnotation = "ingress.operator.openshift.io/load-balancer-health-check-node-port"
+
+	// LoadBalancerHealthCheckPathAnnotation may be set on an
+	// ingresscontroller that uses the LoadBalancerService endpoint
+	// publishing strategy on Azure to choose the HTTP request path of its
+	// load balancer's health probe, for example when a security policy
+	// only allows probes to a known path.  The value is an absolute path
+	// such as "/healthz".  GCP load balancers always probe the kube-proxy
+	// health check on the health check node port, so on GCP only the port
+	// can be chosen, with LoadBalancerHealthCheckNodePortAnnotation.  The
+	// cloud provider updates the probe of an existing load balancer in
+	// place.
+	LoadBalancerHealthCheckPathAnnotation = "ingress.operator.openshift.io/load-balancer-health-check-path"
+
+	awsLBHealthCheckIntervalAnnotation           = "service.beta.kubernetes.io/aws-load-balancer-healthcheck-interval"
+	awsLBHealthCheckTimeoutAnnotation            = "service.beta.kubernetes.io/aws-load-balancer-healthcheck-timeout"
+	awsLBHealthCheckHealthyThresholdAnnotation   = "service.beta.kubernetes.io/aws-load-balancer-healthcheck-healthy-threshold"
+	awsLBHealthCheckUnhealthyThresholdAnnotation = "service.beta.kubernetes.io/aws-load-balancer-healthcheck-unhealthy-threshold"
+
+	azureLBHealthProbeProtocolAnnotation    = "service.beta.kubernetes.io/azure-load-balancer-health-probe-protocol"
+	azureLBHealthProbeRequestPathAnnotation = "service.beta.kubernetes.io/azure-load-balancer-health-probe-request-path"
+)
+
+// awsLBHealthCheckAnnotations are the LB service annotations that
+// configureAWSLoadBalancerHealthCheck sets.  They are removable service
+// annotations so that removing a health check parameter restores its default.
+var awsLBHealthCheckAnnotations = []string{
+	awsLBHealthCheckIntervalAnnotation,
+	awsLBHealthCheckTimeoutAnnotation,
+	awsLBHealthCheckHealthyThresholdAnnotation,
+	awsLBHealthCheckUnhealthyThresholdAnnotation,
+}
+
+// awsLoadBalancerHealthCheck is the value of
+// AWSLoadBalancerHealthCheckAnnotation.  Zero values are unspecified.
+type awsLoadBalancerHealthCheck struct {
+	IntervalSeconds    int32 `json:"intervalSeconds,omitempty"`
+	TimeoutSeconds     int32 `json:"timeoutSeconds,omitempty"`
+	HealthyThreshold   int32 `json:"healthyThreshold,omitempty"`
+	UnhealthyThreshold int32 `json:"unhealthyThreshold,omitempty"`
+}
+
+// awsLoadBalancerHealthCheckFor returns the load balancer health check
+// parameters that the given ingresscontroller specifies, or nil if it does not
+// specify any.  The ranges are the ones that AWS accepts.
+func awsLoadBalancerHealthCheckFor(ic *operatorv1.IngressController) (*awsLoadBalancerHealthCheck, error) {
+	value, ok := ic.Annotations[AWSLoadBalancerHealthCheckAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	healthCheck := &awsLoadBalancerHealthCheck{}
+	if err := decoder.Decode(healthCheck); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", AWSLoadBalancerHealthCheckAnnotation, err)
+	}
+	for _, field := range []struct {
+		option tuningOption
+		value  int32
+	}{
+		{healthCheckIntervalSecondsOption, healthCheck.IntervalSeconds},
+		{healthCheckTimeoutSecondsOption, healthCheck.TimeoutSeconds},
+		{healthCheckHealthyThresholdOption, healthCheck.HealthyThreshold},
+		{healthCheckUnhealthyThresholdOption, healthCheck.UnhealthyThreshold},
+	} {
+		if field.value == 0 {
+			continue
+		}
+		if err := validateTuningOption(field.option, int64(field.value)); err != nil {
+			return nil, fmt.Errorf("invalid value for annotation %s: %v", AWSLoadBalancerHealthCheckAnnotation, err)
+		}
+	}
+	if healthCheck.IntervalSeconds != 0 && healthCheck.TimeoutSeconds != 0 && healthCheck.TimeoutSeconds >= healthCheck.IntervalSeconds {
+		return nil, fmt.Errorf("invalid value for annotation %s: timeoutSeconds must be less than intervalSeconds", AWSLoadBalancerHealthCheckAnnotation)
+	}
+	return healthCheck, nil
+}
+
+// configureAWSLoadBalancerHealthCheck sets the annotations on the given load
+// balancer service that tune its health check as the given ingresscontroller
+// specifies.  The cloud provider updates the health check of an existing load
+// balancer in place, so changing the parameters does not recreate it.
+func configureAWSLoadBalancerHealthCheck(service *corev1.Service, ic *operatorv1.IngressController, infraConfig *configv1.Infrastructure) error {
+	healthCheck, err := awsLoadBalancerHealthCheckFor(ic)
+	if err != nil || healthCheck == nil {
+		return err
+	}
+	if infraConfig.Status.Platform != configv1.AWSPlatformType {
+		return fmt.Errorf("invalid value for annotation %s: load balancer health checks cannot be configured on platform %q", AWSLoadBalancerHealthCheckAnnotation, infraConfig.Status.Platform)
+	}
+	for _, annotation := range []struct {
+		key   string
+		value int32
+	}{
+		{awsLBHealthCheckIntervalAnnotation, healthCheck.IntervalSeconds},
+		{awsLBHealthCheckTimeoutAnnotation, healthCheck.TimeoutSeconds},
+		{awsLBHealthCheckHealthyThresholdAnnotation, healthCheck.HealthyThreshold},
+		{awsLBHealthCheckUnhealthyThresholdAnnotation, healthCheck.UnhealthyThreshold},
+	} {
+		if annotation.value == 0 {
+			continue
+		}
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		service.Annotations[annotation.key] = strconv.Itoa(int(annotation.value))
+	}
+	return nil
+}
+
+// loadBalancerHealthCheckNodePort returns the health check node port that the
+// given ingresscontroller specifies, or 0 if it does not specify one.
+func loadBalancerHealthCheckNodePort(ic *operatorv1.IngressController) (int32, error) {
+	value, ok := ic.Annotations[LoadBalancerHealthCheckNodePortAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	port, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for annotation %s: %q: %v", LoadBalancerHealthCheckNodePortAnnotation, value, err)
+	}
+	if port < minNodePort || port > maxNodePort {
+		return 0, fmt.Errorf("invalid value for annotation %s: %q: must be from %d to %d", LoadBalancerHealthCheckNodePortAnnotation, value, minNodePort, maxNodePort)
+	}
+	return int32(port), nil
+}
+
+// loadBalancerHealthCheckPath returns the health probe request path that the
+// given ingresscontroller specifies, or the empty string if it does not specify
+// one.
+func loadBalancerHealthCheckPath(ic *operatorv1.IngressController) (string, error) {
+	value, ok := ic.Annotations[LoadBalancerHealthCheckPathAnnotation]
+	if !ok {
+		return "", nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || !path.IsAbs(value) || u.Path != value {
+		return "", fmt.Errorf("invalid value for annotation %s: %q: must be an absolute path without a query or fragment", LoadBalancerHealthCheckPathAnnotation, value)
+	}
+	return value, nil
+}
+
+// configureLoadBalancerHealthCheckPath sets the annotations on the given load
+// balancer service that choose the request path of its health probe as the
+// given ingresscontroller specifies.
+func configureLoadBalancerHealthCheckPath(service *corev1.Service, ic *operatorv1.IngressController, infraConfig *configv1.Infrastructure) error {
+	requestPath, err := loadBalancerHealthCheckPath(ic)
+	if err != nil || len(requestPath) == 0 {
+		return err
+	}
+	if infraConfig.Status.Platform != configv1.AzurePlatformType {
+		return fmt.Errorf("invalid value for annotation %s: the health probe path cannot be configured on platform %q", LoadBalancerHealthCheckPathAnnotation, infraConfig.Status.Platform)
+	}
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[azureLBHealthProbeProtocolAnnotation] = "http"
+	service.Annotations[azureLBHealthProbeRequestPathAnnotation] = requestPath
+	return nil
+}
@@ -0,0 +1,306 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredLoadBalancerServiceHealthCheck verifies that
+// desiredLoadBalancerService renders the AWS load balancer health check
+// annotations from the ingresscontroller's health check annotation and rejects
+// invalid parameters.
+func TestDesiredLoadBalancerServiceHealthCheck(t *testing.T) {
+	testCases := []struct {
+		description       string
+		platform          configv1.PlatformType
+		healthCheck       string
+		expectAnnotations map[string]string
+		expectError       bool
+	}{
+		{
+			description:       "no health check",
+			platform:          configv1.AWSPlatformType,
+			expectAnnotations: map[string]string{},
+		},
+		{
+			description: "all parameters",
+			platform:    configv1.AWSPlatformType,
+			healthCheck: `{"intervalSeconds": 5, "timeoutSeconds": 2, "healthyThreshold": 2, "unhealthyThreshold": 3}`,
+			expectAnnotations: map[string]string{
+				awsLBHealthCheckIntervalAnnotation:           "5",
+				awsLBHealthCheckTimeoutAnnotation:            "2",
+				awsLBHealthCheckHealthyThresholdAnnotation:   "2",
+				awsLBHealthCheckUnhealthyThresholdAnnotation: "3",
+			},
+		},
+		{
+			description: "some parameters",
+			platform:    configv1.AWSPlatformType,
+			healthCheck: `{"unhealthyThreshold": 2}`,
+			expectAnnotations: map[string]string{
+				awsLBHealthCheckUnhealthyThresholdAnnotation: "2",
+			},
+		},
+		{
+			description: "interval out of range",
+			platform:    configv1.AWSPlatformType,
+			healthCheck: `{"intervalSeconds": 1}`,
+			expectError: true,
+		},
+		{
+			description: "threshold out of range",
+			platform:    configv1.AWSPlatformType,
+			healthCheck: `{"healthyThreshold": 11}`,
+			expectError: true,
+		},
+		{
+			description: "timeout not less than interval",
+			platform:    configv1.AWSPlatformType,
+			healthCheck: `{"intervalSeconds": 10, "timeoutSeconds": 10}`,
+			expectError: true,
+		},
+		{
+			description: "unknown field",
+			platform:    configv1.AWSPlatformType,
+			healthCheck: `{"interval": 10}`,
+			expectError: true,
+		},
+		{
+			description: "unsupported platform",
+			platform:    configv1.GCPPlatformType,
+			healthCheck: `{"intervalSeconds": 10}`,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(AWSLoadBalancerHealthCheckAnnotation, tc.healthCheck)
+		ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+			Type: operatorv1.LoadBalancerServiceStrategyType,
+		}
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{Platform: tc.platform},
+		}
+		service, err := desiredLoadBalancerService(ic, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+		if !checkError(t, tc.description, tc.expectError, err) {
+			continue
+		}
+		actual := map[string]string{}
+		for _, key := range []string{
+			awsLBHealthCheckIntervalAnnotation,
+			awsLBHealthCheckTimeoutAnnotation,
+			awsLBHealthCheckHealthyThresholdAnnotation,
+			awsLBHealthCheckUnhealthyThresholdAnnotation,
+		} {
+			if value, ok := service.Annotations[key]; ok {
+				actual[key] = value
+			}
+		}
+		if !reflect.DeepEqual(actual, tc.expectAnnotations) {
+			t.Errorf("%q: expected annotations %v, got %v", tc.description, tc.expectAnnotations, actual)
+		}
+	}
+}
+
+// TestLoadBalancerHealthCheckNodePort verifies that desiredLoadBalancerService
+// sets the health check node port that the ingresscontroller specifies and that
+// changing it requires recreating the LB service.
+func TestLoadBalancerHealthCheckNodePort(t *testing.T) {
+	testCases := []struct {
+		description      string
+		current          string
+		expected         string
+		expectRecreation bool
+		expectError      bool
+	}{
+		{
+			description: "no port",
+		},
+		{
+			description:      "port specified for an allocated port",
+			expected:         "32000",
+			expectRecreation: true,
+		},
+		{
+			description: "port unchanged",
+			current:     "32000",
+			expected:    "32000",
+		},
+		{
+			description:      "port changed",
+			current:          "32000",
+			expected:         "32001",
+			expectRecreation: true,
+		},
+		{
+			description: "port removed",
+			current:     "32000",
+		},
+		{
+			description: "port not a number",
+			expected:    "http",
+			expectError: true,
+		},
+		{
+			description: "port out of range",
+			expected:    "70000",
+			expectError: true,
+		},
+		{
+			description: "port outside the node port range",
+			expected:    "8080",
+			expectError: true,
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType},
+	}
+	for _, tc := range testCases {
+		serviceFor := func(port string) (*corev1.Service, error) {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Status: operatorv1.IngressControllerStatus{
+					EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+						Type: operatorv1.LoadBalancerServiceStrategyType,
+					},
+				},
+			}
+			if len(port) != 0 {
+				ic.Annotations = map[string]string{LoadBalancerHealthCheckNodePortAnnotation: port}
+			}
+			return desiredLoadBalancerService(ic, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+		}
+		current, err := serviceFor(tc.current)
+		if err != nil {
+			t.Errorf("%q: unexpected error for current service: %v", tc.description, err)
+			continue
+		}
+		// Simulate the port that the API allocates if none is specified.
+		if current.Spec.HealthCheckNodePort == 0 {
+			current.Spec.HealthCheckNodePort = 31000
+		}
+		expected, err := serviceFor(tc.expected)
+		if !checkError(t, tc.description, tc.expectError, err) {
+			continue
+		}
+		if actual := loadBalancerServiceNeedsRecreation(current, expected); actual != tc.expectRecreation {
+			t.Errorf("%q: expected recreation %t, got %t", tc.description, tc.expectRecreation, actual)
+		}
+	}
+}
+
+// TestDesiredLoadBalancerServiceHealthCheckPath verifies that
+// desiredLoadBalancerService renders the Azure health probe annotations from
+// the ingresscontroller's health check path annotation and rejects invalid
+// paths and unsupported platforms.
+func TestDesiredLoadBalancerServiceHealthCheckPath(t *testing.T) {
+	testCases := []struct {
+		description       string
+		platform          configv1.PlatformType
+		path              string
+		expectAnnotations map[string]string
+		expectError       bool
+	}{
+		{
+			description:       "no path",
+			platform:          configv1.AzurePlatformType,
+			expectAnnotations: map[string]string{},
+		},
+		{
+			description: "path on Azure",
+			platform:    configv1.AzurePlatformType,
+			path:        "/healthz/ready",
+			expectAnnotations: map[string]string{
+				azureLBHealthProbeProtocolAnnotation:    "http",
+				azureLBHealthProbeRequestPathAnnotation: "/healthz/ready",
+			},
+		},
+		{
+			description: "relative path",
+			platform:    configv1.AzurePlatformType,
+			path:        "healthz",
+			expectError: true,
+		},
+		{
+			description: "path with a query",
+			platform:    configv1.AzurePlatformType,
+			path:        "/healthz?verbose",
+			expectError: true,
+		},
+		{
+			description: "unsupported platform",
+			platform:    configv1.GCPPlatformType,
+			path:        "/healthz",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(LoadBalancerHealthCheckPathAnnotation, tc.path)
+		ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+			Type: operatorv1.LoadBalancerServiceStrategyType,
+		}
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{Platform: tc.platform},
+		}
+		service, err := desiredLoadBalancerService(ic, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+		if !checkError(t, tc.description, tc.expectError, err) {
+			continue
+		}
+		actual := map[string]string{}
+		for _, key := range []string{azureLBHealthProbeProtocolAnnotation, azureLBHealthProbeRequestPathAnnotation} {
+			if value, ok := service.Annotations[key]; ok {
+				actual[key] = value
+			}
+		}
+		if !reflect.DeepEqual(actual, tc.expectAnnotations) {
+			t.Errorf("%q: expected annotations %v, got %v", tc.description, tc.expectAnnotations, actual)
+		}
+	}
+}
+
+// TestServiceChangedRemovesHealthCheckAnnotations verifies that removing a
+// health check parameter removes its annotation from the LB service.
+func TestServiceChangedRemovesHealthCheckAnnotations(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{AWSLoadBalancerHealthCheckAnnotation: `{"intervalSeconds": 5, "unhealthyThreshold": 2}`},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+	}
+	current, err := desiredLoadBalancerService(ic, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ic.Annotations[AWSLoadBalancerHealthCheckAnnotation] = `{"intervalSeconds": 5}`
+	expected, err := desiredLoadBalancerService(ic, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed, updated := serviceChanged(current, expected)
+	if !changed {
+		t.Fatal("expected the service to change")
+	}
+	if _, ok := updated.Annotations[awsLBHealthCheckUnhealthyThresholdAnnotation]; ok {
+		t.Errorf("expected annotation %s to be removed", awsLBHealthCheckUnhealthyThresholdAnnotation)
+	}
+	if value := updated.Annotations[awsLBHealthCheckIntervalAnnotation]; value != "5" {
+		t.Errorf("expected annotation %s=5, got %q", awsLBHealthCheckIntervalAnnotation, value)
+	}
+	if changed, _ := serviceChanged(updated, expected); changed {
+		t.Error("expected the updated service not to change again")
+	}
+}
@@ -0,0 +1,374 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Some load balancer settings cannot be changed on an existing load balancer,
+// so changing them requires a new LB service.  Deleting the LB service and
+// creating a new one would drop traffic until the new load balancer is
+// provisioned and the wildcard DNS record is updated, which can take several
+// minutes.  Instead, the operator replaces the LB service in the following
+// steps, each of which waits for the previous one to complete:
+//
+//  1. Create a replacement LB service with the new settings.
+//  2. Once its load balancer is provisioned, point the wildcard DNS record
+//     at it.
+//  3. Once the DNS record is published and clients have had time to pick up
+//     the change, promote the replacement to be the ingresscontroller's LB
+//     service and delete the old one.
+//
+// Service names cannot be changed, so an ingresscontroller's LB service
+// alternates between the names that LoadBalancerServiceName and
+// AlternateLoadBalancerServiceName return, and the replacement takes whichever
+// name the current LB service does not have.  The current LB service is the
+// one with LoadBalancerServiceFinalizer, which guards the wildcard DNS record;
+// a replacement gets the finalizer when it is promoted.  The state of the
+// replacement is derived from the LB services that exist, so that it survives
+// operator restarts.
+//
+// The API does not allow changing the health check node port of a service,
+// and the current LB service holds its port until it is deleted.  If the
+// desired health check node port is the current LB service's port, the
+// replacement has one allocated instead, and once the old LB service is gone,
+// the replacement is itself replaced to move to the desired port.
+
+const (
+	// loadBalancerDrainPeriod is how long the operator waits after the
+	// wildcard DNS record is published with a new load balancer before it
+	// deletes the superseded load balancer.  This gives clients and
+	// resolvers that cached the old record time to pick up the new one.
+	loadBalancerDrainPeriod = 5 * time.Minute
+
+	// LoadBalancerSupersededAnnotation is set on an LB service that is
+	// being replaced to record when the wildcard DNS record was published
+	// with the replacement load balancer.
+	LoadBalancerSupersededAnnotation = "ingress.operator.openshift.io/superseded-time"
+)
+
+// recreateLBAnnotations are the LB service annotations that cloud providers
+// only honor when the load balancer is created.
+var recreateLBAnnotations = func() []string {
+	keys := []string{}
+	for _, annotation := range internalLBAnnotations {
+		keys = append(keys, annotation.key)
+	}
+	return keys
+}()
+
+// loadBalancerServiceNeedsRecreation returns true if the current LB service
+// differs from the expected service in any setting that requires a new load
+// balancer.  The absence of an annotation is significant: for example,
+// removing the internal annotation makes the load balancer external.  The
+// health check node port, which the API forbids changing on an existing
+// service, is significant only if the expected service specifies one; if it
+// does not, the current service keeps its allocated port.
+func loadBalancerServiceNeedsRecreation(current, expected *corev1.Service) bool {
+	if expected.Spec.HealthCheckNodePort != 0 && current.Spec.HealthCheckNodePort != expected.Spec.HealthCheckNodePort {
+		return true
+	}
+	for _, key := range recreateLBAnnotations {
+		currentValue, currentOK := current.Annotations[key]
+		expectedValue, expectedOK := expected.Annotations[key]
+		if currentOK != expectedOK || currentValue != expectedValue {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoadBalancerProvisioned returns true if the cloud provider has assigned
+// a load balancer hostname or IP address to the given LB service.  Some cloud
+// providers, such as AWS, assign a hostname, and others, such as GCP and
+// Azure, assign only an IP address.
+func isLoadBalancerProvisioned(service *corev1.Service) bool {
+	return len(loadBalancerAddress(service)) != 0
+}
+
+// loadBalancerAddress returns the hostname of the load balancer of the given
+// LB service, or its IP address if it has no hostname, or the empty string if
+// no load balancer is assigned.
+func loadBalancerAddress(service *corev1.Service) string {
+	ingress := service.Status.LoadBalancer.Ingress
+	if len(ingress) == 0 {
+		return ""
+	}
+	if len(ingress[0].Hostname) != 0 {
+		return ingress[0].Hostname
+	}
+	return ingress[0].IP
+}
+
+// CurrentLoadBalancerService takes the services, if any, that have the names
+// that LoadBalancerServiceName and AlternateLoadBalancerServiceName return for
+// an ingresscontroller, and returns the ingresscontroller's current LB service
+// and the other LB service.  The other LB service is either a replacement,
+// which does not have LoadBalancerServiceFinalizer, or the old LB service that
+// a promoted replacement superseded, which keeps the finalizer until it is
+// deleted.  If both services have the finalizer, the current one is the one
+// that is not superseded; if neither has it, the current one is the primary
+// one.
+func CurrentLoadBalancerService(primary, alternate *corev1.Service) (current, other *corev1.Service) {
+	switch {
+	case primary == nil:
+		return alternate, nil
+	case alternate == nil:
+		return primary, nil
+	}
+	primaryFinalized := slice.ContainsString(primary.Finalizers, LoadBalancerServiceFinalizer)
+	alternateFinalized := slice.ContainsString(alternate.Finalizers, LoadBalancerServiceFinalizer)
+	if primaryFinalized == alternateFinalized {
+		_, primarySuperseded := primary.Annotations[LoadBalancerSupersededAnnotation]
+		_, alternateSuperseded := alternate.Annotations[LoadBalancerSupersededAnnotation]
+		if primarySuperseded && !alternateSuperseded {
+			return alternate, primary
+		}
+		return primary, alternate
+	}
+	if alternateFinalized {
+		return alternate, primary
+	}
+	return primary, alternate
+}
+
+// currentLoadBalancerServices returns the current LB service of the given
+// ingresscontroller and its other LB service, as CurrentLoadBalancerService
+// determines them, or nil for either if it does not exist.
+func (r *reconciler) currentLoadBalancerServices(ci *operatorv1.IngressController) (*corev1.Service, *corev1.Service, error) {
+	var services []*corev1.Service
+	for _, name := range []types.NamespacedName{
+		LoadBalancerServiceName(ci, r.Config.OperandNamespace),
+		AlternateLoadBalancerServiceName(ci, r.Config.OperandNamespace),
+	} {
+		service := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), name, service); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, nil, fmt.Errorf("failed to get load balancer service %s: %v", name, err)
+			}
+			service = nil
+		}
+		services = append(services, service)
+	}
+	current, other := CurrentLoadBalancerService(services[0], services[1])
+	return current, other, nil
+}
+
+// setLoadBalancerServiceName sets the name of the given LB service and the
+// router label that matches it.
+func setLoadBalancerServiceName(service *corev1.Service, name string) {
+	service.Name = name
+	service.Labels["router"] = name
+}
+
+// desiredReplacementLoadBalancerService returns the replacement for the given
+// current LB service of the given ingresscontroller, given the desired LB
+// service.  The replacement has the name that the current LB service does not
+// have, and it does not have the finalizer until it is promoted.
+func desiredReplacementLoadBalancerService(ci *operatorv1.IngressController, current, desired *corev1.Service) *corev1.Service {
+	replacement := desired.DeepCopy()
+	name := AlternateLoadBalancerServiceName(ci, current.Namespace)
+	if current.Name == name.Name {
+		name = LoadBalancerServiceName(ci, current.Namespace)
+	}
+	setLoadBalancerServiceName(replacement, name.Name)
+	replacement.Finalizers = nil
+	// The current LB service holds its health check node port until it
+	// is deleted, so let the replacement have one allocated.
+	if replacement.Spec.HealthCheckNodePort == current.Spec.HealthCheckNodePort {
+		replacement.Spec.HealthCheckNodePort = 0
+	}
+	return replacement
+}
+
+// ensureReplacementLoadBalancerService ensures that the given ingresscontroller
+// has a replacement for its current LB service with the settings of the given
+// desired LB service if wanted is true, and that it has no replacement
+// otherwise.  A replacement that is no longer wanted or whose settings changed
+// again is deleted right away if its load balancer is not provisioned, and
+// otherwise once the wildcard DNS record points back at the current LB service
+// (see finishLoadBalancerServiceReplacement).  It returns the LB service that
+// the wildcard DNS record should point to: the replacement once its load
+// balancer is provisioned, and the current one otherwise.
+func (r *reconciler) ensureReplacementLoadBalancerService(ci *operatorv1.IngressController, current, replacement, desired *corev1.Service, wanted bool) (*corev1.Service, error) {
+	desiredReplacement := desiredReplacementLoadBalancerService(ci, current, desired)
+	if replacement != nil && (!wanted || loadBalancerServiceNeedsRecreation(replacement, desiredReplacement)) {
+		if isLoadBalancerProvisioned(replacement) {
+			return current, nil
+		}
+		if err := r.client.Delete(context.TODO(), replacement); err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete load balancer service %s/%s: %v", replacement.Namespace, replacement.Name, err)
+		}
+		log.Info("deleted outdated replacement load balancer service", "namespace", replacement.Namespace, "name", replacement.Name)
+		replacement = nil
+	}
+	if !wanted {
+		return current, nil
+	}
+	if replacement == nil {
+		if err := r.client.Create(context.TODO(), desiredReplacement); err != nil {
+			return nil, fmt.Errorf("failed to create load balancer service %s/%s: %v", desiredReplacement.Namespace, desiredReplacement.Name, err)
+		}
+		log.Info("created replacement load balancer service", "namespace", desiredReplacement.Namespace, "name", desiredReplacement.Name, "replacing", current.Name)
+		return current, nil
+	}
+	updated, err := r.updateService(replacement, desiredReplacement)
+	if err != nil {
+		return nil, err
+	}
+	if isLoadBalancerProvisioned(updated) {
+		return updated, nil
+	}
+	return current, nil
+}
+
+// finishLoadBalancerServiceReplacement completes the replacement of the given
+// ingresscontroller's LB service, if one is in progress, once the wildcard DNS
+// record has been published with the given target LB service's load balancer
+// and the drain period has elapsed.  If the target is the replacement, it is
+// promoted to be the current LB service and the old one is deleted; if the
+// target is the current LB service, the replacement, which is no longer
+// wanted, is deleted.  It returns an error while it is waiting so that the
+// ingresscontroller is reconciled again.
+func (r *reconciler) finishLoadBalancerServiceReplacement(ci *operatorv1.IngressController, target *corev1.Service) error {
+	if !isLoadBalancerProvisioned(target) {
+		return nil
+	}
+	current, other, err := r.currentLoadBalancerServices(ci)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if _, ok := current.Annotations[LoadBalancerSupersededAnnotation]; ok && target.Name == current.Name {
+		// The wildcard DNS record points back at the current LB service
+		// because its replacement is no longer wanted, so the current LB
+		// service is not superseded anymore.
+		updated := current.DeepCopy()
+		delete(updated.Annotations, LoadBalancerSupersededAnnotation)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update load balancer service %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+	}
+	if other == nil {
+		return nil
+	}
+	promote := target.Name == other.Name
+	superseded := other
+	if promote {
+		superseded = current
+	} else if !slice.ContainsString(other.Finalizers, LoadBalancerServiceFinalizer) && !isLoadBalancerProvisioned(other) {
+		// The replacement is still being provisioned.
+		return nil
+	}
+
+	published, err := r.isWildcardRecordPublished(ci, target)
+	if err != nil {
+		return err
+	}
+	if !published {
+		if dnsDryRunEnabled(ci) {
+			return fmt.Errorf("not deleting load balancer service %s/%s while the %s annotation is %q; apply the change planned in the wildcard DNS record and remove the annotation to continue", superseded.Namespace, superseded.Name, DNSDryRunAnnotation, dnsDryRunEnabledValue)
+		}
+		return fmt.Errorf("waiting for the wildcard DNS record to be published with load balancer service %s/%s before deleting %s", target.Namespace, target.Name, superseded.Name)
+	}
+
+	supersededTime, err := time.Parse(time.RFC3339, superseded.Annotations[LoadBalancerSupersededAnnotation])
+	if err != nil {
+		updated := superseded.DeepCopy()
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[LoadBalancerSupersededAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update load balancer service %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		return fmt.Errorf("waiting %s for clients to stop using load balancer service %s/%s", loadBalancerDrainPeriod, superseded.Namespace, superseded.Name)
+	}
+	if remaining := loadBalancerDrainPeriod - time.Since(supersededTime); remaining > 0 {
+		return fmt.Errorf("waiting %s for clients to stop using load balancer service %s/%s", remaining.Round(time.Second), superseded.Namespace, superseded.Name)
+	}
+
+	if promote {
+		// Promote the replacement before the old LB service loses the
+		// finalizer so that the wildcard DNS record stays guarded.
+		promoted := other.DeepCopy()
+		promoted.Finalizers = append(promoted.Finalizers, LoadBalancerServiceFinalizer)
+		delete(promoted.Annotations, LoadBalancerSupersededAnnotation)
+		if err := r.client.Update(context.TODO(), promoted); err != nil {
+			return fmt.Errorf("failed to promote load balancer service %s/%s: %v", promoted.Namespace, promoted.Name, err)
+		}
+		log.Info("promoted replacement load balancer service", "namespace", promoted.Namespace, "name", promoted.Name, "replacing", current.Name)
+	}
+	if slice.ContainsString(superseded.Finalizers, LoadBalancerServiceFinalizer) {
+		updated := superseded.DeepCopy()
+		updated.Finalizers = slice.RemoveString(updated.Finalizers, LoadBalancerServiceFinalizer)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to remove finalizer from service %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		superseded = updated
+	}
+	if err := r.client.Delete(context.TODO(), superseded); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete load balancer service %s/%s: %v", superseded.Namespace, superseded.Name, err)
+	}
+	log.Info("deleted superseded load balancer service", "namespace", superseded.Namespace, "name", superseded.Name, "replacement", target.Name)
+	return nil
+}
+
+// isWildcardRecordPublished returns true if the wildcard DNS record for the
+// given ingresscontroller points to the given LB service's load balancer and
+// has been published to every zone.  If external-dns publishes the record, the
+// operator cannot observe when it does, so the record counts as published once
+// the LB service has the external-dns annotations, and the drain period covers
+// external-dns's sync interval.  If the ingresscontroller has no wildcard DNS
+// record, there is nothing to wait for.
+func (r *reconciler) isWildcardRecordPublished(ci *operatorv1.IngressController, service *corev1.Service) (bool, error) {
+	if desired := desiredExternalDNSAnnotations(ci); desired != nil {
+		current := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: service.Namespace, Name: service.Name}, current); err != nil {
+			return false, fmt.Errorf("failed to get service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+		changed, _ := externalDNSAnnotationsChanged(current, desired)
+		return !changed, nil
+	}
+	if desiredWildcardRecord(ci, loadBalancerAddress(service)) == nil {
+		return true, nil
+	}
+	record, err := r.currentWildcardRecord(ci)
+	if err != nil {
+		return false, err
+	}
+	return record != nil && isRecordPublishedWithTarget(record, loadBalancerAddress(service)), nil
+}
+
+// isRecordPublishedWithTarget returns true if the given DNSRecord has the given
+// target and the dnsrecord controller has published its current generation to
+// every zone.  A dry-run DNSRecord never counts as published because the
+// operator cannot observe when an administrator applies the planned change.
+func isRecordPublishedWithTarget(record *ingressv1.DNSRecord, target string) bool {
+	if record.Spec.DryRun || !slice.ContainsString(record.Spec.Targets, target) || record.Status.ObservedGeneration != record.Generation {
+		return false
+	}
+	for _, zone := range record.Status.Zones {
+		for _, c := range zone.Conditions {
+			if c.Type != ingressv1.DNSRecordPublishedConditionType {
+				continue
+			}
+			if c.Status != string(operatorv1.ConditionTrue) {
+				return false
+			}
+		}
+	}
+	return true
+}
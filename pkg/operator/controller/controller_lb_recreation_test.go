@@ -0,0 +1,357 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestLoadBalancerServiceNeedsRecreation(t *testing.T) {
+	testCases := []struct {
+		description string
+		platform    configv1.PlatformType
+		current     string
+		expected    string
+		expect      bool
+		expectError bool
+	}{
+		{
+			description: "no scope",
+			platform:    configv1.AWSPlatformType,
+		},
+		{
+			description: "explicit external scope",
+			platform:    configv1.AWSPlatformType,
+			expected:    "External",
+		},
+		{
+			description: "external to internal on AWS",
+			platform:    configv1.AWSPlatformType,
+			expected:    "Internal",
+			expect:      true,
+		},
+		{
+			description: "internal to external on GCP",
+			platform:    configv1.GCPPlatformType,
+			current:     "Internal",
+			expected:    "External",
+			expect:      true,
+		},
+		{
+			description: "internal unchanged on Azure",
+			platform:    configv1.AzurePlatformType,
+			current:     "Internal",
+			expected:    "Internal",
+		},
+		{
+			description: "internal on an unsupported platform",
+			platform:    configv1.LibvirtPlatformType,
+			expected:    "Internal",
+			expectError: true,
+		},
+		{
+			description: "invalid scope",
+			platform:    configv1.AWSPlatformType,
+			expected:    "Private",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{Platform: tc.platform},
+		}
+		serviceFor := func(scope string) (*corev1.Service, error) {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "default",
+					Annotations: map[string]string{LoadBalancerScopeAnnotation: scope},
+				},
+				Status: operatorv1.IngressControllerStatus{
+					EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+						Type: operatorv1.LoadBalancerServiceStrategyType,
+					},
+				},
+			}
+			return desiredLoadBalancerService(ic, DefaultOperandNamespace, metav1.OwnerReference{}, infraConfig)
+		}
+		current, err := serviceFor(tc.current)
+		if err != nil {
+			t.Errorf("%q: unexpected error for current service: %v", tc.description, err)
+			continue
+		}
+		expected, err := serviceFor(tc.expected)
+		if !checkError(t, tc.description, tc.expectError, err) {
+			continue
+		}
+		if actual := loadBalancerServiceNeedsRecreation(current, expected); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestIsRecordPublishedWithTarget(t *testing.T) {
+	published := ingressv1.DNSZoneCondition{
+		Type:   ingressv1.DNSRecordPublishedConditionType,
+		Status: string(operatorv1.ConditionTrue),
+	}
+	failed := ingressv1.DNSZoneCondition{
+		Type:   ingressv1.DNSRecordPublishedConditionType,
+		Status: string(operatorv1.ConditionFalse),
+	}
+	planned := ingressv1.DNSZoneCondition{
+		Type:   ingressv1.DNSRecordPublishedConditionType,
+		Status: string(operatorv1.ConditionFalse),
+		Reason: "DryRun",
+	}
+	testCases := []struct {
+		description        string
+		target             string
+		generation         int64
+		observedGeneration int64
+		dryRun             bool
+		conditions         []ingressv1.DNSZoneCondition
+		expect             bool
+	}{
+		{
+			description:        "published with the target",
+			target:             "new.example.com",
+			generation:         2,
+			observedGeneration: 2,
+			conditions:         []ingressv1.DNSZoneCondition{published, published},
+			expect:             true,
+		},
+		{
+			description:        "different target",
+			target:             "old.example.com",
+			generation:         2,
+			observedGeneration: 2,
+			conditions:         []ingressv1.DNSZoneCondition{published, published},
+		},
+		{
+			description:        "update not yet observed",
+			target:             "new.example.com",
+			generation:         2,
+			observedGeneration: 1,
+			conditions:         []ingressv1.DNSZoneCondition{published, published},
+		},
+		{
+			description:        "failed in one zone",
+			target:             "new.example.com",
+			generation:         2,
+			observedGeneration: 2,
+			conditions:         []ingressv1.DNSZoneCondition{published, failed},
+		},
+		{
+			description:        "dry run planned in every zone",
+			target:             "new.example.com",
+			generation:         2,
+			observedGeneration: 2,
+			dryRun:             true,
+			conditions:         []ingressv1.DNSZoneCondition{planned, planned},
+		},
+		{
+			description:        "dry run of a record published earlier",
+			target:             "new.example.com",
+			generation:         2,
+			observedGeneration: 2,
+			dryRun:             true,
+			conditions:         []ingressv1.DNSZoneCondition{published, published},
+		},
+		{
+			description:        "dry run failed in one zone",
+			target:             "new.example.com",
+			generation:         2,
+			observedGeneration: 2,
+			dryRun:             true,
+			conditions:         []ingressv1.DNSZoneCondition{planned, failed},
+		},
+	}
+
+	for _, tc := range testCases {
+		record := &ingressv1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Generation: tc.generation},
+			Spec: ingressv1.DNSRecordSpec{
+				Targets: []string{tc.target},
+				DryRun:  tc.dryRun,
+			},
+			Status: ingressv1.DNSRecordStatus{ObservedGeneration: tc.observedGeneration},
+		}
+		for _, c := range tc.conditions {
+			record.Status.Zones = append(record.Status.Zones, ingressv1.DNSZoneStatus{
+				Conditions: []ingressv1.DNSZoneCondition{c},
+			})
+		}
+		if actual := isRecordPublishedWithTarget(record, "new.example.com"); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestLoadBalancerAddress(t *testing.T) {
+	testCases := []struct {
+		description       string
+		ingress           []corev1.LoadBalancerIngress
+		expectAddress     string
+		expectProvisioned bool
+	}{
+		{"no load balancer", nil, "", false},
+		{"hostname", []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}, "lb.example.com", true},
+		{"IP address", []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}, "192.0.2.1", true},
+		{"hostname and IP address", []corev1.LoadBalancerIngress{{Hostname: "lb.example.com", IP: "192.0.2.1"}}, "lb.example.com", true},
+	}
+	for _, tc := range testCases {
+		service := &corev1.Service{}
+		service.Status.LoadBalancer.Ingress = tc.ingress
+		if actual := loadBalancerAddress(service); actual != tc.expectAddress {
+			t.Errorf("%q: expected address %q, got %q", tc.description, tc.expectAddress, actual)
+		}
+		if actual := isLoadBalancerProvisioned(service); actual != tc.expectProvisioned {
+			t.Errorf("%q: expected provisioned %t, got %t", tc.description, tc.expectProvisioned, actual)
+		}
+	}
+}
+
+func TestCurrentLoadBalancerService(t *testing.T) {
+	service := func(name string, finalized, superseded bool) *corev1.Service {
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if finalized {
+			service.Finalizers = []string{LoadBalancerServiceFinalizer}
+		}
+		if superseded {
+			service.Annotations = map[string]string{LoadBalancerSupersededAnnotation: "2020-01-01T00:00:00Z"}
+		}
+		return service
+	}
+	testCases := []struct {
+		description   string
+		primary       *corev1.Service
+		alternate     *corev1.Service
+		expectCurrent string
+		expectOther   string
+	}{
+		{
+			description: "no services",
+		},
+		{
+			description:   "primary only",
+			primary:       service("primary", true, false),
+			expectCurrent: "primary",
+		},
+		{
+			description:   "promoted alternate only",
+			alternate:     service("alternate", true, false),
+			expectCurrent: "alternate",
+		},
+		{
+			description:   "alternate replacing primary",
+			primary:       service("primary", true, true),
+			alternate:     service("alternate", false, false),
+			expectCurrent: "primary",
+			expectOther:   "alternate",
+		},
+		{
+			description:   "primary replacing alternate",
+			primary:       service("primary", false, false),
+			alternate:     service("alternate", true, false),
+			expectCurrent: "alternate",
+			expectOther:   "primary",
+		},
+		{
+			description:   "alternate promoted before primary is deleted",
+			primary:       service("primary", true, true),
+			alternate:     service("alternate", true, false),
+			expectCurrent: "alternate",
+			expectOther:   "primary",
+		},
+		{
+			description:   "neither finalized",
+			primary:       service("primary", false, false),
+			alternate:     service("alternate", false, false),
+			expectCurrent: "primary",
+			expectOther:   "alternate",
+		},
+	}
+	nameOf := func(service *corev1.Service) string {
+		if service == nil {
+			return ""
+		}
+		return service.Name
+	}
+	for _, tc := range testCases {
+		current, other := CurrentLoadBalancerService(tc.primary, tc.alternate)
+		if nameOf(current) != tc.expectCurrent || nameOf(other) != tc.expectOther {
+			t.Errorf("%q: expected current %q and other %q, got %q and %q", tc.description, tc.expectCurrent, tc.expectOther, nameOf(current), nameOf(other))
+		}
+	}
+}
+
+func TestDesiredReplacementLoadBalancerService(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	primary := LoadBalancerServiceName(ic, "openshift-ingress")
+	alternate := AlternateLoadBalancerServiceName(ic, "openshift-ingress")
+	testCases := []struct {
+		description           string
+		current               string
+		currentNodePort       int32
+		desiredNodePort       int32
+		expectName            string
+		expectHealthCheckPort int32
+	}{
+		{
+			description: "primary is replaced by alternate",
+			current:     primary.Name,
+			expectName:  alternate.Name,
+		},
+		{
+			description: "alternate is replaced by primary",
+			current:     alternate.Name,
+			expectName:  primary.Name,
+		},
+		{
+			description:           "new health check node port",
+			current:               primary.Name,
+			currentNodePort:       31000,
+			desiredNodePort:       32000,
+			expectName:            alternate.Name,
+			expectHealthCheckPort: 32000,
+		},
+		{
+			description:     "health check node port held by the current service",
+			current:         primary.Name,
+			currentNodePort: 31000,
+			desiredNodePort: 31000,
+			expectName:      alternate.Name,
+		},
+	}
+	for _, tc := range testCases {
+		current := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: tc.current}}
+		current.Spec.HealthCheckNodePort = tc.currentNodePort
+		desired := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "openshift-ingress",
+				Name:       tc.current,
+				Labels:     map[string]string{"router": tc.current},
+				Finalizers: []string{LoadBalancerServiceFinalizer},
+			},
+		}
+		desired.Spec.HealthCheckNodePort = tc.desiredNodePort
+		replacement := desiredReplacementLoadBalancerService(ic, current, desired)
+		if replacement.Name != tc.expectName || replacement.Labels["router"] != tc.expectName {
+			t.Errorf("%q: expected name and router label %q, got %q and %q", tc.description, tc.expectName, replacement.Name, replacement.Labels["router"])
+		}
+		if len(replacement.Finalizers) != 0 {
+			t.Errorf("%q: expected no finalizers, got %v", tc.description, replacement.Finalizers)
+		}
+		if replacement.Spec.HealthCheckNodePort != tc.expectHealthCheckPort {
+			t.Errorf("%q: expected health check node port %d, got %d", tc.description, tc.expectHealthCheckPort, replacement.Spec.HealthCheckNodePort)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// awsLBAdditionalResourceTagsAnnotation is the service annotation
+	// with which the AWS cloud provider tags the load balancer and the
+	// resources that it creates for it.  The value is a comma-separated
+	// list of key=value pairs.
+	awsLBAdditionalResourceTagsAnnotation = "service.beta.kubernetes.io/aws-load-balancer-additional-resource-tags"
+)
+
+// InfrastructureGVK is the group, version, and kind of the cluster
+// infrastructure config.  The vendored config API does not have the
+// infrastructure's platform status, so the operator reads the user-defined AWS
+// resource tags from the unstructured object.
+var InfrastructureGVK = schema.GroupVersionKind{
+	Group:   configv1.GroupName,
+	Version: "v1",
+	Kind:    "Infrastructure",
+}
+
+// awsResourceTags returns the user-defined AWS resource tags in the given
+// unstructured infrastructure config, from
+// status.platformStatus.aws.resourceTags, formatted as the value of the
+// additional resource tags annotation, or the empty string if there are none.
+// Tags whose key or value the annotation cannot represent are rejected.
+func awsResourceTags(infra *unstructured.Unstructured) (string, error) {
+	resourceTags, _, err := unstructured.NestedSlice(infra.Object, "status", "platformStatus", "aws", "resourceTags")
+	if err != nil {
+		return "", fmt.Errorf("invalid status.platformStatus.aws.resourceTags in infrastructure %s: %v", infra.GetName(), err)
+	}
+	var tags []string
+	for _, tag := range resourceTags {
+		fields, ok := tag.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("invalid resource tag in infrastructure %s: %v", infra.GetName(), tag)
+		}
+		key, _, _ := unstructured.NestedString(fields, "key")
+		value, _, _ := unstructured.NestedString(fields, "value")
+		if len(key) == 0 || strings.ContainsAny(key, ",=") || strings.Contains(value, ",") {
+			return "", fmt.Errorf("invalid resource tag in infrastructure %s: %q=%q: the key must be non-empty and must not contain ',' or '=', and the value must not contain ','", infra.GetName(), key, value)
+		}
+		tags = append(tags, key+"="+value)
+	}
+	return strings.Join(tags, ","), nil
+}
+
+// setLoadBalancerResourceTags sets the additional resource tags annotation on
+// the given LB service to the user-defined resource tags of the cluster's AWS
+// infrastructure, so that the load balancer is tagged like the cluster's
+// other resources.  The annotation is not set on other platforms or if the
+// infrastructure has no resource tags, in which case updating the service
+// removes it.  The cloud provider adds and updates tags on an existing load
+// balancer but does not remove tags that are no longer specified.
+func (r *reconciler) setLoadBalancerResourceTags(service *corev1.Service, infraConfig *configv1.Infrastructure) error {
+	if service == nil || infraConfig.Status.Platform != configv1.AWSPlatformType {
+		return nil
+	}
+	infra := &unstructured.Unstructured{}
+	infra.SetGroupVersionKind(InfrastructureGVK)
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infra); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get infrastructure 'cluster': %v", err)
+	}
+	tags, err := awsResourceTags(infra)
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[awsLBAdditionalResourceTagsAnnotation] = tags
+	return nil
+}
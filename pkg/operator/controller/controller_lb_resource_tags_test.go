@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestAWSResourceTags verifies that awsResourceTags formats the
+// infrastructure's user-defined resource tags as the value of the additional
+// resource tags annotation and rejects tags that the annotation cannot
+// represent.
+func TestAWSResourceTags(t *testing.T) {
+	tag := func(key, value string) interface{} {
+		return map[string]interface{}{"key": key, "value": value}
+	}
+	testCases := []struct {
+		description string
+		status      map[string]interface{}
+		expect      string
+		expectError bool
+	}{
+		{
+			description: "no platform status",
+			status:      map[string]interface{}{"platform": "AWS"},
+		},
+		{
+			description: "no resource tags",
+			status: map[string]interface{}{
+				"platformStatus": map[string]interface{}{"aws": map[string]interface{}{"region": "us-east-1"}},
+			},
+		},
+		{
+			description: "resource tags",
+			status: map[string]interface{}{
+				"platformStatus": map[string]interface{}{"aws": map[string]interface{}{
+					"resourceTags": []interface{}{tag("team", "network"), tag("cost-center", "a=b"), tag("empty", "")},
+				}},
+			},
+			expect: "team=network,cost-center=a=b,empty=",
+		},
+		{
+			description: "comma in value",
+			status: map[string]interface{}{
+				"platformStatus": map[string]interface{}{"aws": map[string]interface{}{
+					"resourceTags": []interface{}{tag("team", "network,storage")},
+				}},
+			},
+			expectError: true,
+		},
+		{
+			description: "equals sign in key",
+			status: map[string]interface{}{
+				"platformStatus": map[string]interface{}{"aws": map[string]interface{}{
+					"resourceTags": []interface{}{tag("team=a", "network")},
+				}},
+			},
+			expectError: true,
+		},
+		{
+			description: "malformed resource tags",
+			status: map[string]interface{}{
+				"platformStatus": map[string]interface{}{"aws": map[string]interface{}{
+					"resourceTags": "team=network",
+				}},
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		infra := &unstructured.Unstructured{Object: map[string]interface{}{"status": tc.status}}
+		infra.SetGroupVersionKind(InfrastructureGVK)
+		infra.SetName("cluster")
+		actual, err := awsResourceTags(infra)
+		checkError(t, tc.description, tc.expectError, err)
+		if actual != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
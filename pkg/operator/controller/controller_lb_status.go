@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// loadBalancerFailureEventReasons are the reasons of the events that the
+// service controller records on a service when the cloud provider fails to
+// provision or update its load balancer.
+var loadBalancerFailureEventReasons = map[string]bool{
+	"SyncLoadBalancerFailed":     true,
+	"CreatingLoadBalancerFailed": true,
+	"UpdateLoadBalancerFailed":   true,
+	"DeletingLoadBalancerFailed": true,
+}
+
+// IsLoadBalancerFailureEvent returns true if the given event reports that the
+// cloud provider failed to provision or update a service's load balancer.
+func IsLoadBalancerFailureEvent(event *corev1.Event) bool {
+	return event.InvolvedObject.Kind == "Service" && loadBalancerFailureEventReasons[event.Reason]
+}
+
+// computeLoadBalancerReadyCondition returns the LoadBalancerReady condition for
+// the given ingresscontroller and LB service, or nil if the ingresscontroller
+// does not use a load balancer.  If the load balancer is not provisioned, the
+// condition reports the most recent failure that the service controller
+// recorded for the service, if any.
+func (r *reconciler) computeLoadBalancerReadyCondition(ci *operatorv1.IngressController, service *corev1.Service) (*operatorv1.OperatorCondition, error) {
+	if ci.Status.EndpointPublishingStrategy == nil || ci.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
+		return nil, nil
+	}
+	if service == nil {
+		current, err := r.currentLoadBalancerService(ci)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get load balancer service: %v", err)
+		}
+		service = current
+	}
+	var events []corev1.Event
+	if service != nil && len(service.Status.LoadBalancer.Ingress) == 0 {
+		eventList := &corev1.EventList{}
+		if err := r.client.List(context.TODO(), eventList, client.InNamespace(service.Namespace), client.MatchingField("involvedObject.name", service.Name)); err != nil {
+			return nil, fmt.Errorf("failed to list events for service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+		events = eventList.Items
+	}
+	return loadBalancerReadyCondition(service, events), nil
+}
+
+// loadBalancerReadyCondition returns the LoadBalancerReady condition for the
+// given LB service and the events recorded for it.
+func loadBalancerReadyCondition(service *corev1.Service, events []corev1.Event) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.LoadBalancerReadyIngressConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+	switch {
+	case service == nil:
+		condition.Reason = "ServiceNotFound"
+		condition.Message = "The LoadBalancer service resource is missing"
+	case len(service.Status.LoadBalancer.Ingress) != 0:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "LoadBalancerProvisioned"
+		condition.Message = "The LoadBalancer service is provisioned"
+	default:
+		condition.Reason = "LoadBalancerPending"
+		condition.Message = "The LoadBalancer service is pending"
+		if event := latestLoadBalancerFailureEvent(service, events); event != nil {
+			condition.Reason = event.Reason
+			condition.Message = fmt.Sprintf("The service-controller component is reporting %s events like: %s\nThe kube-controller-manager logs may contain more details.", event.Reason, event.Message)
+		}
+	}
+	return condition
+}
+
+// latestLoadBalancerFailureEvent returns the most recent load balancer failure
+// event among the given events for the given service, or nil if there is none.
+// Events for an earlier service with the same name are ignored.
+func latestLoadBalancerFailureEvent(service *corev1.Service, events []corev1.Event) *corev1.Event {
+	var latest *corev1.Event
+	for i := range events {
+		event := &events[i]
+		if event.InvolvedObject.UID != service.UID || !IsLoadBalancerFailureEvent(event) {
+			continue
+		}
+		if latest == nil || latest.LastTimestamp.Before(&event.LastTimestamp) {
+			latest = event
+		}
+	}
+	return latest
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestLoadBalancerReadyCondition(t *testing.T) {
+	pending := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-default",
+			UID:       "2",
+		},
+	}
+	provisioned := pending.DeepCopy()
+	provisioned.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+	eventFor := func(uid, reason, message string, age time.Duration) corev1.Event {
+		return corev1.Event{
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      "Service",
+				Namespace: "openshift-ingress",
+				Name:      "router-default",
+				UID:       types.UID(uid),
+			},
+			Reason:        reason,
+			Message:       message,
+			LastTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		}
+	}
+	quota := eventFor("2", "SyncLoadBalancerFailed", "TooManyLoadBalancers: Exceeded quota of account", time.Minute)
+	permission := eventFor("2", "SyncLoadBalancerFailed", "AccessDenied: not authorized", 10*time.Minute)
+	ensuring := eventFor("2", "EnsuringLoadBalancer", "Ensuring load balancer", 0)
+	stale := eventFor("1", "SyncLoadBalancerFailed", "from a deleted service", 0)
+
+	testCases := []struct {
+		description   string
+		service       *corev1.Service
+		events        []corev1.Event
+		expectStatus  operatorv1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			description:  "missing service",
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "ServiceNotFound",
+		},
+		{
+			description:  "provisioned",
+			service:      provisioned,
+			events:       []corev1.Event{quota},
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "LoadBalancerProvisioned",
+		},
+		{
+			description:  "pending without failures",
+			service:      pending,
+			events:       []corev1.Event{ensuring, stale},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "LoadBalancerPending",
+		},
+		{
+			description:   "pending with failures reports the latest",
+			service:       pending,
+			events:        []corev1.Event{permission, ensuring, quota},
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "SyncLoadBalancerFailed",
+			expectMessage: "Exceeded quota",
+		},
+	}
+
+	for _, tc := range testCases {
+		condition := loadBalancerReadyCondition(tc.service, tc.events)
+		if condition.Type != operatorv1.LoadBalancerReadyIngressConditionType {
+			t.Errorf("%q: expected condition type %s, got %s", tc.description, operatorv1.LoadBalancerReadyIngressConditionType, condition.Type)
+		}
+		if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason {
+			t.Errorf("%q: expected status %s and reason %s, got %s and %s", tc.description, tc.expectStatus, tc.expectReason, condition.Status, condition.Reason)
+		}
+		if !strings.Contains(condition.Message, tc.expectMessage) {
+			t.Errorf("%q: expected message to contain %q, got %q", tc.description, tc.expectMessage, condition.Message)
+		}
+	}
+}
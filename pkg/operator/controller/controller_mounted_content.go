@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// MountedContentHashAnnotation is an annotation on the router pod
+	// template with a hash of the contents of the secrets and configmaps
+	// that the router pods mount or reference in environment variables.
+	// Updating the annotation when the
+	// contents change causes the deployment to roll out new pods that use
+	// the new contents.
+	MountedContentHashAnnotation = "ingresscontroller.operator.openshift.io/mounted-content-hash"
+)
+
+// setMountedContentHash computes the hash of the contents of the secrets and
+// configmaps that the given deployment's pods mount or reference in
+// environment variables and sets it as an annotation on the deployment's pod
+// template.
+func (r *reconciler) setMountedContentHash(deployment *appsv1.Deployment) error {
+	secrets := map[string]*corev1.Secret{}
+	configmaps := map[string]*corev1.ConfigMap{}
+	envSecretNames := envSecretNames(deployment.Spec.Template.Spec.Containers)
+	for _, secretName := range envSecretNames {
+		secret := &corev1.Secret{}
+		name := types.NamespacedName{Namespace: deployment.Namespace, Name: secretName}
+		if err := r.client.Get(context.TODO(), name, secret); err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to get secret %s: %v", name, err)
+			}
+			continue
+		}
+		secrets[secret.Name] = secret
+	}
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		switch {
+		case volume.Secret != nil:
+			secret := &corev1.Secret{}
+			name := types.NamespacedName{Namespace: deployment.Namespace, Name: volume.Secret.SecretName}
+			if err := r.client.Get(context.TODO(), name, secret); err != nil {
+				if !errors.IsNotFound(err) {
+					return fmt.Errorf("failed to get secret %s: %v", name, err)
+				}
+				continue
+			}
+			secrets[secret.Name] = secret
+		case volume.ConfigMap != nil:
+			configmap := &corev1.ConfigMap{}
+			name := types.NamespacedName{Namespace: deployment.Namespace, Name: volume.ConfigMap.Name}
+			if err := r.client.Get(context.TODO(), name, configmap); err != nil {
+				if !errors.IsNotFound(err) {
+					return fmt.Errorf("failed to get configmap %s: %v", name, err)
+				}
+				continue
+			}
+			configmaps[configmap.Name] = configmap
+		}
+	}
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[MountedContentHashAnnotation] = hashMountedContent(deployment.Spec.Template.Spec.Volumes, envSecretNames, secrets, configmaps)
+	return nil
+}
+
+// envSecretNames returns the sorted names of the secrets that the given
+// containers reference in environment variables.
+func envSecretNames(containers []corev1.Container) []string {
+	names := map[string]struct{}{}
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names[env.ValueFrom.SecretKeyRef.Name] = struct{}{}
+			}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// hashMountedContent returns a hash of the contents of the secrets and
+// configmaps that the given volumes reference and of the secrets with the
+// given names, which environment variables reference.  A volume or secret
+// name whose secret or configmap is missing from the given maps contributes
+// only its name, so the hash changes once the secret or configmap is created.
+func hashMountedContent(volumes []corev1.Volume, envSecretNames []string, secrets map[string]*corev1.Secret, configmaps map[string]*corev1.ConfigMap) string {
+	sorted := make([]corev1.Volume, len(volumes))
+	copy(sorted, volumes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	hash := sha256.New()
+	for _, volume := range sorted {
+		switch {
+		case volume.Secret != nil:
+			fmt.Fprintf(hash, "secret/%s/%s\n", volume.Name, volume.Secret.SecretName)
+			if secret, ok := secrets[volume.Secret.SecretName]; ok {
+				hashData(hash, secret.Data)
+			}
+		case volume.ConfigMap != nil:
+			fmt.Fprintf(hash, "configmap/%s/%s\n", volume.Name, volume.ConfigMap.Name)
+			if configmap, ok := configmaps[volume.ConfigMap.Name]; ok {
+				data := map[string][]byte{}
+				for k, v := range configmap.Data {
+					data[k] = []byte(v)
+				}
+				for k, v := range configmap.BinaryData {
+					data[k] = v
+				}
+				hashData(hash, data)
+			}
+		}
+	}
+	for _, name := range envSecretNames {
+		fmt.Fprintf(hash, "env-secret/%s\n", name)
+		if secret, ok := secrets[name]; ok {
+			hashData(hash, secret.Data)
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// hashData writes the given data to the given hash in key order.
+func hashData(hash io.Writer, data map[string][]byte) {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s=%d:", k, len(data[k]))
+		hash.Write(data[k])
+		hash.Write([]byte{'\n'})
+	}
+}
+
+// DeploymentMountsObject returns true if the given deployment's pods mount the
+// secret or configmap with the given name or, for a secret, reference it in
+// environment variables.
+func DeploymentMountsObject(deployment *appsv1.Deployment, obj runtime.Object, name string) bool {
+	if _, ok := obj.(*corev1.Secret); ok {
+		for _, secretName := range envSecretNames(deployment.Spec.Template.Spec.Containers) {
+			if secretName == name {
+				return true
+			}
+		}
+	}
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		switch obj.(type) {
+		case *corev1.Secret:
+			if volume.Secret != nil && volume.Secret.SecretName == name {
+				return true
+			}
+		case *corev1.ConfigMap:
+			if volume.ConfigMap != nil && volume.ConfigMap.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
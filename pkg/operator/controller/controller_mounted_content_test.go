@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHashMountedContent(t *testing.T) {
+	volumes := []corev1.Volume{
+		{Name: "default-certificate", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "router-certs-default"}}},
+		{Name: "error-pages", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "error-pages"}}}},
+	}
+	secret := &corev1.Secret{Data: map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}}
+	configmap := &corev1.ConfigMap{Data: map[string]string{"error-page-503.http": "503"}}
+	secrets := map[string]*corev1.Secret{"router-certs-default": secret}
+	configmaps := map[string]*corev1.ConfigMap{"error-pages": configmap}
+
+	original := hashMountedContent(volumes, nil, secrets, configmaps)
+
+	reversed := []corev1.Volume{volumes[1], volumes[0]}
+	if hash := hashMountedContent(reversed, nil, secrets, configmaps); hash != original {
+		t.Errorf("expected hash to be independent of volume order")
+	}
+
+	updatedSecret := secret.DeepCopy()
+	updatedSecret.Data["tls.crt"] = []byte("new cert")
+	if hash := hashMountedContent(volumes, nil, map[string]*corev1.Secret{"router-certs-default": updatedSecret}, configmaps); hash == original {
+		t.Errorf("expected hash to change when secret data changes")
+	}
+
+	updatedConfigMap := configmap.DeepCopy()
+	updatedConfigMap.Data["error-page-404.http"] = "404"
+	if hash := hashMountedContent(volumes, nil, secrets, map[string]*corev1.ConfigMap{"error-pages": updatedConfigMap}); hash == original {
+		t.Errorf("expected hash to change when configmap data changes")
+	}
+
+	if hash := hashMountedContent(volumes, nil, secrets, nil); hash == original {
+		t.Errorf("expected hash to change when a configmap is missing")
+	}
+
+	statsSecret := &corev1.Secret{Data: map[string][]byte{"statsUsername": []byte("user"), "statsPassword": []byte("pass")}}
+	secrets["router-stats-default"] = statsSecret
+	withEnvSecret := hashMountedContent(volumes, []string{"router-stats-default"}, secrets, configmaps)
+	if withEnvSecret == original {
+		t.Errorf("expected hash to change when a secret is referenced in an environment variable")
+	}
+	rotatedSecret := statsSecret.DeepCopy()
+	rotatedSecret.Data["statsPassword"] = []byte("rotated")
+	secrets["router-stats-default"] = rotatedSecret
+	if hash := hashMountedContent(volumes, []string{"router-stats-default"}, secrets, configmaps); hash == withEnvSecret {
+		t.Errorf("expected hash to change when a secret that is referenced in an environment variable changes")
+	}
+}
+
+func TestDeploymentMountsObject(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "default-certificate", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "router-certs-default"}}},
+	}
+	if !DeploymentMountsObject(deployment, &corev1.Secret{}, "router-certs-default") {
+		t.Errorf("expected deployment to mount secret router-certs-default")
+	}
+	if DeploymentMountsObject(deployment, &corev1.ConfigMap{}, "router-certs-default") {
+		t.Errorf("expected deployment not to mount configmap router-certs-default")
+	}
+	if DeploymentMountsObject(deployment, &corev1.Secret{}, "other") {
+		t.Errorf("expected deployment not to mount secret other")
+	}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+		Env: []corev1.EnvVar{{
+			Name: "STATS_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "router-stats-default"},
+				Key:                  "statsPassword",
+			}},
+		}},
+	}}
+	if !DeploymentMountsObject(deployment, &corev1.Secret{}, "router-stats-default") {
+		t.Errorf("expected deployment to reference secret router-stats-default")
+	}
+}
+
+// TestSharedDefaultCertificateSecret verifies that ingresscontrollers that
+// reference the same default certificate secret all mount it, so that a change
+// to the secret queues and rolls out every one of them.
+func TestSharedDefaultCertificateSecret(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}}
+	var deployments []*appsv1.Deployment
+	for _, name := range []string{"shard-a", "shard-b"} {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: operatorv1.IngressControllerSpec{
+				DefaultCertificate: &corev1.LocalObjectReference{Name: "shared-wildcard"},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ic, DefaultOperandNamespace, "quay.io/openshift/router:latest", &configv1.Infrastructure{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !DeploymentMountsObject(deployment, &corev1.Secret{}, "shared-wildcard") {
+			t.Errorf("expected the router deployment for %s to mount the shared secret", name)
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	hash := func(deployment *appsv1.Deployment, secret *corev1.Secret) string {
+		return hashMountedContent(deployment.Spec.Template.Spec.Volumes, nil, map[string]*corev1.Secret{"shared-wildcard": secret}, nil)
+	}
+	rotated := secret.DeepCopy()
+	rotated.Data["tls.crt"] = []byte("rotated cert")
+	for _, deployment := range deployments {
+		if hash(deployment, secret) == hash(deployment, rotated) {
+			t.Errorf("expected the mounted content hash of %s to change when the shared secret changes", deployment.Name)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// NetworkPolicyAnnotation is an annotation on an ingresscontroller that
+	// specifies whether the operator manages a network policy that allows
+	// traffic to the ingresscontroller's routers.  The value may be
+	// "Managed", which is the default, or "Unmanaged", in which case the
+	// operator deletes its network policy and the cluster administrator is
+	// responsible for allowing traffic to the routers.
+	NetworkPolicyAnnotation = "ingress.operator.openshift.io/network-policy"
+
+	networkPolicyManaged   = "Managed"
+	networkPolicyUnmanaged = "Unmanaged"
+
+	// monitoringPolicyGroupLabel is the label that identifies the
+	// namespace of the cluster monitoring stack to network policies.
+	monitoringPolicyGroupLabel = "network.openshift.io/policy-group"
+
+	// namespaceNameLabel is the label with which OpenShift namespaces are
+	// labeled with their own names.
+	namespaceNameLabel = "name"
+)
+
+// networkPolicyManagedFor returns true if the operator should manage a network
+// policy for the given ingresscontroller.  Network policies do not apply to
+// pods that use host networking, so no policy is managed for the HostNetwork
+// endpoint publishing strategy.
+func networkPolicyManagedFor(ic *operatorv1.IngressController) (bool, error) {
+	managed := true
+	if value, ok := ic.Annotations[NetworkPolicyAnnotation]; ok {
+		switch value {
+		case networkPolicyManaged:
+		case networkPolicyUnmanaged:
+			managed = false
+		default:
+			return false, fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", NetworkPolicyAnnotation, value, networkPolicyManaged, networkPolicyUnmanaged)
+		}
+	}
+	if ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType {
+		return false, nil
+	}
+	return managed, nil
+}
+
+// desiredNetworkPolicy returns the desired network policy for the routers of
+// the given ingresscontroller.  The policy allows HTTP and HTTPS traffic from
+// anywhere, which includes clients, load balancers, and their health checks,
+// and allows the cluster monitoring stack and the operator to scrape the
+// metrics port.  This keeps ingress working in clusters whose namespaces deny
+// traffic by default.  If the ingresscontroller publishes the stats port on a
+// node port, the policy allows the metrics port from anywhere as well.
+func desiredNetworkPolicy(ic *operatorv1.IngressController, operatorNamespace, operandNamespace string, deploymentRef metav1.OwnerReference) *networkingv1.NetworkPolicy {
+	name := RouterDeploymentName(ic, operandNamespace)
+	tcp := corev1.ProtocolTCP
+	port := func(name string) networkingv1.NetworkPolicyPort {
+		p := intstr.FromString(name)
+		return networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &p}
+	}
+	publicPorts := []networkingv1.NetworkPolicyPort{port("http"), port("https")}
+	if ports, err := nodePortsFor(ic); err == nil && ports.Stats != 0 {
+		publicPorts = append(publicPorts, port("metrics"))
+	}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{deploymentRef},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *IngressControllerDeploymentPodSelector(ic),
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: publicPorts,
+				},
+				{
+					Ports: []networkingv1.NetworkPolicyPort{port("metrics")},
+					From: []networkingv1.NetworkPolicyPeer{{
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								monitoringPolicyGroupLabel: "monitoring",
+							},
+						},
+					}, {
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								namespaceNameLabel: operatorNamespace,
+							},
+						},
+					}},
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// ensureNetworkPolicy ensures that the network policy for the routers of the
+// given ingresscontroller exists if the operator manages it, and that it does
+// not exist otherwise.
+func (r *reconciler) ensureNetworkPolicy(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) error {
+	managed, err := networkPolicyManagedFor(ic)
+	if err != nil {
+		return err
+	}
+	name := RouterDeploymentName(ic, r.Config.OperandNamespace)
+	current := &networkingv1.NetworkPolicy{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get network policy %s: %v", name, err)
+		}
+		current = nil
+	}
+	if !managed {
+		if current == nil {
+			return nil
+		}
+		if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete network policy %s: %v", name, err)
+		}
+		log.Info("deleted network policy", "namespace", name.Namespace, "name", name.Name)
+		return nil
+	}
+	desired := desiredNetworkPolicy(ic, r.Config.Namespace, r.Config.OperandNamespace, deploymentRef)
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create network policy %s: %v", name, err)
+		}
+		log.Info("created network policy", "namespace", name.Namespace, "name", name.Name)
+		return nil
+	}
+	if changed, updated := networkPolicyChanged(current, desired); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update network policy %s: %v", name, err)
+		}
+		log.Info("updated network policy", "namespace", name.Namespace, "name", name.Name)
+	}
+	return nil
+}
+
+// networkPolicyChanged checks whether the current network policy's spec
+// matches the expected spec and if not returns an updated network policy.
+func networkPolicyChanged(current, expected *networkingv1.NetworkPolicy) (bool, *networkingv1.NetworkPolicy) {
+	if reflect.DeepEqual(current.Spec, expected.Spec) {
+		return false, nil
+	}
+	updated := current.DeepCopy()
+	updated.Spec = expected.Spec
+	return true, updated
+}
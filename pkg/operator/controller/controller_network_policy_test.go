@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNetworkPolicyManagedFor(t *testing.T) {
+	testCases := []struct {
+		description string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		annotation  *string
+		expect      bool
+		expectError bool
+	}{
+		{
+			description: "default",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect:      true,
+		},
+		{
+			description: "explicitly managed",
+			strategy:    operatorv1.PrivateStrategyType,
+			annotation:  pointerToString("Managed"),
+			expect:      true,
+		},
+		{
+			description: "unmanaged",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotation:  pointerToString("Unmanaged"),
+			expect:      false,
+		},
+		{
+			description: "host networking",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expect:      false,
+		},
+		{
+			description: "invalid annotation",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotation:  pointerToString("managed"),
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+			},
+		}
+		if tc.annotation != nil {
+			ic.Annotations = map[string]string{NetworkPolicyAnnotation: *tc.annotation}
+		}
+		actual, err := networkPolicyManagedFor(ic)
+		checkError(t, tc.description, tc.expectError, err)
+		if actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestDesiredNetworkPolicy(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	policy := desiredNetworkPolicy(ic, "openshift-ingress-operator", DefaultOperandNamespace, metav1.OwnerReference{})
+	if policy.Namespace != DefaultOperandNamespace || policy.Name != "router-default" {
+		t.Errorf("unexpected name %s/%s", policy.Namespace, policy.Name)
+	}
+	if policy.Spec.PodSelector.MatchLabels[controllerDeploymentLabel] != "default" {
+		t.Errorf("expected the policy to select the router pods, got %v", policy.Spec.PodSelector)
+	}
+	if len(policy.Spec.Ingress) != 2 {
+		t.Fatalf("expected 2 ingress rules, got %d", len(policy.Spec.Ingress))
+	}
+	if len(policy.Spec.Ingress[0].From) != 0 {
+		t.Errorf("expected HTTP and HTTPS to be allowed from anywhere, got %v", policy.Spec.Ingress[0].From)
+	}
+	if len(policy.Spec.Ingress[1].From) != 2 || policy.Spec.Ingress[1].Ports[0].Port.StrVal != "metrics" {
+		t.Errorf("expected metrics to be allowed only from monitoring and the operator, got %v", policy.Spec.Ingress[1])
+	} else if policy.Spec.Ingress[1].From[1].NamespaceSelector.MatchLabels[namespaceNameLabel] != "openshift-ingress-operator" {
+		t.Errorf("expected metrics to be allowed from the operator namespace, got %v", policy.Spec.Ingress[1].From[1])
+	}
+
+	if changed, _ := networkPolicyChanged(policy, policy.DeepCopy()); changed {
+		t.Error("expected an identical policy not to be changed")
+	}
+	mutated := policy.DeepCopy()
+	mutated.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+	if changed, updated := networkPolicyChanged(mutated, policy); !changed || updated.Spec.PolicyTypes[0] != networkingv1.PolicyTypeIngress {
+		t.Error("expected a modified policy to be updated")
+	}
+}
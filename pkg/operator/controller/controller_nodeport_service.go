@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// NodePortServiceStrategyType is an endpoint publishing strategy that
+	// publishes the ingresscontroller on node ports through a NodePort
+	// service.  In this configuration, the router deployment uses
+	// container networking, and the user is responsible for configuring an
+	// external load balancer to forward traffic to the node ports.  The
+	// vendored API does not define this strategy yet, so it is specified as
+	// "NodePortService" in spec.endpointPublishingStrategy.type.
+	NodePortServiceStrategyType operatorv1.EndpointPublishingStrategyType = "NodePortService"
+
+	// NodePortsAnnotation may be set on an ingresscontroller that uses the
+	// NodePortService endpoint publishing strategy to choose the node
+	// ports of the NodePort service so that an external load balancer can
+	// be configured once instead of following the ports that the API
+	// server allocates.  The value is a JSON object with the optional
+	// fields "http", "https", and "stats", each a port within the
+	// cluster's default node port range.  Ports that are not specified are
+	// allocated by the API server.  The stats port is published only if it
+	// is specified.
+	NodePortsAnnotation = "ingress.operator.openshift.io/node-ports"
+
+	// minNodePort and maxNodePort are the bounds of the default node port
+	// range of the API server.
+	minNodePort = 30000
+	maxNodePort = 32767
+)
+
+// nodePorts is the value of NodePortsAnnotation.
+type nodePorts struct {
+	HTTP  int32 `json:"http,omitempty"`
+	HTTPS int32 `json:"https,omitempty"`
+	Stats int32 `json:"stats,omitempty"`
+}
+
+// nodePortsFor returns the node ports that the given ingresscontroller
+// specifies.  A port that is not specified is 0.
+func nodePortsFor(ic *operatorv1.IngressController) (nodePorts, error) {
+	var ports nodePorts
+	value, ok := ic.Annotations[NodePortsAnnotation]
+	if !ok {
+		return ports, nil
+	}
+	if strategy := ic.Status.EndpointPublishingStrategy; strategy != nil && strategy.Type != NodePortServiceStrategyType {
+		return ports, fmt.Errorf("invalid value for annotation %s: %q: node ports require the %s endpoint publishing strategy", NodePortsAnnotation, value, NodePortServiceStrategyType)
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&ports); err != nil {
+		return ports, fmt.Errorf("invalid value for annotation %s: %v", NodePortsAnnotation, err)
+	}
+	seen := map[int32]string{}
+	for _, port := range []struct {
+		name  string
+		value int32
+	}{
+		{"http", ports.HTTP},
+		{"https", ports.HTTPS},
+		{"stats", ports.Stats},
+	} {
+		if port.value == 0 {
+			continue
+		}
+		if port.value < minNodePort || port.value > maxNodePort {
+			return ports, fmt.Errorf("invalid value for annotation %s: %s must be from %d to %d, got %d", NodePortsAnnotation, port.name, minNodePort, maxNodePort, port.value)
+		}
+		if other, ok := seen[port.value]; ok {
+			return ports, fmt.Errorf("invalid value for annotation %s: %s and %s must be different, got %d", NodePortsAnnotation, other, port.name, port.value)
+		}
+		seen[port.value] = port.name
+	}
+	return ports, nil
+}
+
+// requestedNodePorts returns the node ports that the given ingresscontroller
+// requests through annotations, including its load balancer health check node
+// port.  Invalid annotations are ignored.
+func requestedNodePorts(ic *operatorv1.IngressController) []int32 {
+	var requested []int32
+	if ports, err := nodePortsFor(ic); err == nil {
+		for _, port := range []int32{ports.HTTP, ports.HTTPS, ports.Stats} {
+			if port != 0 {
+				requested = append(requested, port)
+			}
+		}
+	}
+	if port, err := loadBalancerHealthCheckNodePort(ic); err == nil && port != 0 {
+		requested = append(requested, port)
+	}
+	return requested
+}
+
+// nodePortConflicts returns an error for each node port that the given
+// ingresscontroller requests and that an older one of the given other
+// ingresscontrollers also requests.  Because node ports are allocated
+// cluster-wide, only one of them can get the port; the older ingresscontroller
+// keeps it so that admitting a new ingresscontroller never disrupts an
+// existing one.
+func nodePortConflicts(ic *operatorv1.IngressController, others []operatorv1.IngressController) []error {
+	requested := requestedNodePorts(ic)
+	if len(requested) == 0 {
+		return nil
+	}
+	var errs []error
+	sort.Slice(requested, func(i, j int) bool { return requested[i] < requested[j] })
+	for _, port := range requested {
+		for i := range others {
+			other := &others[i]
+			if other.Namespace == ic.Namespace && other.Name == ic.Name || !isOlderIngressController(other, ic) {
+				continue
+			}
+			for _, otherPort := range requestedNodePorts(other) {
+				if otherPort == port {
+					errs = append(errs, fmt.Errorf("node port %d is already requested by ingresscontroller %s", port, other.Name))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// isOlderIngressController returns true if a was created before b, breaking
+// ties by name.
+func isOlderIngressController(a, b *operatorv1.IngressController) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// desiredNodePortService returns the desired NodePort service for the given
+// ingresscontroller, or nil if the ingresscontroller does not use the
+// NodePortService endpoint publishing strategy.  The service declares an owner
+// reference to the given deployment.
+func desiredNodePortService(ic *operatorv1.IngressController, operandNamespace string, deploymentRef metav1.OwnerReference) (*corev1.Service, error) {
+	if ic.Status.EndpointPublishingStrategy == nil || ic.Status.EndpointPublishingStrategy.Type != NodePortServiceStrategyType {
+		return nil, nil
+	}
+	ports, err := nodePortsFor(ic)
+	if err != nil {
+		return nil, err
+	}
+	service := manifests.NodePortService()
+	name := NodePortServiceName(ic, operandNamespace)
+	service.Namespace = name.Namespace
+	service.Name = name.Name
+	if service.Labels == nil {
+		service.Labels = map[string]string{}
+	}
+	service.Labels["router"] = name.Name
+	service.Labels[manifests.OwningIngressControllerLabel] = ic.Name
+	service.Spec.Selector = IngressControllerDeploymentPodSelector(ic).MatchLabels
+	for i := range service.Spec.Ports {
+		switch service.Spec.Ports[i].Name {
+		case "http":
+			service.Spec.Ports[i].NodePort = ports.HTTP
+		case "https":
+			service.Spec.Ports[i].NodePort = ports.HTTPS
+		}
+	}
+	if ports.Stats != 0 {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name:       "metrics",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       1936,
+			TargetPort: intstr.FromString("metrics"),
+			NodePort:   ports.Stats,
+		})
+	}
+	if err := configureServiceSessionAffinity(service, ic); err != nil {
+		return nil, err
+	}
+	operandLabels, operandAnnotations, err := operandMetadata(ic)
+	if err != nil {
+		return nil, err
+	}
+	addOperandMetadata(&service.ObjectMeta, operandLabels, operandAnnotations)
+	setManagedServiceKeys(service)
+	service.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	return service, nil
+}
+
+// ensureNodePortService ensures that the NodePort service for the given
+// ingresscontroller exists and has the desired node ports if the
+// ingresscontroller uses the NodePortService endpoint publishing strategy.
+// Because the strategy cannot change, an existing service is never deleted;
+// it is garbage-collected with the router deployment.
+func (r *reconciler) ensureNodePortService(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (*corev1.Service, error) {
+	desired, err := desiredNodePortService(ic, r.Config.OperandNamespace, deploymentRef)
+	if err != nil {
+		return nil, newTerminalError(err)
+	}
+	if desired == nil {
+		return nil, nil
+	}
+	current := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), NodePortServiceName(ic, r.Config.OperandNamespace), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get NodePort service %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create NodePort service %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created NodePort service", "namespace", desired.Namespace, "name", desired.Name)
+		return desired, nil
+	}
+	return r.updateService(current, desired)
+}
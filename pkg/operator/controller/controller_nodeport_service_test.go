@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredNodePortService verifies that desiredNodePortService renders the
+// node ports that the ingresscontroller specifies and rejects invalid ones.
+func TestDesiredNodePortService(t *testing.T) {
+	testCases := []struct {
+		description   string
+		strategy      operatorv1.EndpointPublishingStrategyType
+		nodePorts     string
+		expectService bool
+		expectPorts   map[string]int32
+		expectError   bool
+	}{
+		{
+			description: "load balancer strategy",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+		},
+		{
+			description:   "no node ports",
+			strategy:      NodePortServiceStrategyType,
+			expectService: true,
+			expectPorts:   map[string]int32{"http": 0, "https": 0},
+		},
+		{
+			description:   "some node ports",
+			strategy:      NodePortServiceStrategyType,
+			nodePorts:     `{"https": 30443}`,
+			expectService: true,
+			expectPorts:   map[string]int32{"http": 0, "https": 30443},
+		},
+		{
+			description:   "all node ports",
+			strategy:      NodePortServiceStrategyType,
+			nodePorts:     `{"http": 30080, "https": 30443, "stats": 30936}`,
+			expectService: true,
+			expectPorts:   map[string]int32{"http": 30080, "https": 30443, "metrics": 30936},
+		},
+		{
+			description: "port below the node port range",
+			strategy:    NodePortServiceStrategyType,
+			nodePorts:   `{"http": 80}`,
+			expectError: true,
+		},
+		{
+			description: "port above the node port range",
+			strategy:    NodePortServiceStrategyType,
+			nodePorts:   `{"stats": 32768}`,
+			expectError: true,
+		},
+		{
+			description: "duplicate ports",
+			strategy:    NodePortServiceStrategyType,
+			nodePorts:   `{"http": 30080, "https": 30080}`,
+			expectError: true,
+		},
+		{
+			description: "unknown field",
+			strategy:    NodePortServiceStrategyType,
+			nodePorts:   `{"metrics": 30936}`,
+			expectError: true,
+		},
+		{
+			description: "node ports with another strategy",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			nodePorts:   `{"http": 30080}`,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(NodePortsAnnotation, tc.nodePorts)
+		ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{Type: tc.strategy}
+		if err := validateIngressController(ic, false, configv1.AWSPlatformType); (err != nil) != tc.expectError {
+			t.Errorf("%q: expected validation error to be %t, got %v", tc.description, tc.expectError, err)
+		}
+		service, err := desiredNodePortService(ic, DefaultOperandNamespace, metav1.OwnerReference{})
+		switch {
+		case tc.expectError && tc.strategy == NodePortServiceStrategyType && err == nil:
+			t.Errorf("%q: expected error, got nil", tc.description)
+		case !tc.expectError && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case !tc.expectService && service != nil:
+			t.Errorf("%q: expected no service, got %#v", tc.description, service)
+		case tc.expectService && service == nil:
+			t.Errorf("%q: expected a service, got nil", tc.description)
+		case tc.expectService:
+			if service.Name != NodePortServiceName(ic, DefaultOperandNamespace).Name {
+				t.Errorf("%q: unexpected service name %s", tc.description, service.Name)
+			}
+			ports := map[string]int32{}
+			for _, port := range service.Spec.Ports {
+				ports[port.Name] = port.NodePort
+			}
+			if len(ports) != len(tc.expectPorts) {
+				t.Errorf("%q: expected ports %v, got %v", tc.description, tc.expectPorts, ports)
+			}
+			for name, expected := range tc.expectPorts {
+				if actual, ok := ports[name]; !ok || actual != expected {
+					t.Errorf("%q: expected ports %v, got %v", tc.description, tc.expectPorts, ports)
+					break
+				}
+			}
+		}
+	}
+}
+
+// TestNodePortConflicts verifies that an ingresscontroller is rejected if it
+// requests a node port that an older ingresscontroller requests.
+func TestNodePortConflicts(t *testing.T) {
+	then := metav1.NewTime(time.Unix(0, 0))
+	now := metav1.NewTime(time.Unix(60, 0))
+	icWithPorts := func(name string, created metav1.Time, annotations map[string]string) operatorv1.IngressController {
+		return operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "openshift-ingress-operator",
+				Name:              name,
+				CreationTimestamp: created,
+				Annotations:       annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: NodePortServiceStrategyType},
+			},
+		}
+	}
+	old := icWithPorts("old", then, map[string]string{NodePortsAnnotation: `{"http": 30080, "https": 30443}`})
+	health := icWithPorts("health", then, map[string]string{LoadBalancerHealthCheckNodePortAnnotation: "30936"})
+	ic := icWithPorts("new", now, map[string]string{NodePortsAnnotation: `{"http": 30080, "https": 30444, "stats": 30936}`})
+	others := []operatorv1.IngressController{old, health, ic}
+
+	if errs := nodePortConflicts(&ic, others); len(errs) != 2 {
+		t.Errorf("expected 2 conflicts for the new ingresscontroller, got %v", errs)
+	}
+	if errs := nodePortConflicts(&old, others); len(errs) != 0 {
+		t.Errorf("expected no conflicts for the old ingresscontroller, got %v", errs)
+	}
+	ic.Annotations[NodePortsAnnotation] = `{"http": 30081}`
+	if errs := nodePortConflicts(&ic, others); len(errs) != 0 {
+		t.Errorf("expected no conflicts after changing the port, got %v", errs)
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// OperandLabelsAnnotation may be set on an ingresscontroller to specify
+	// additional labels for the router deployment, its pods, and its
+	// services, such as cost-center labels.  The value is a JSON object
+	// that maps label keys to values, for example:
+	//
+	//   {"cost-center": "1234"}
+	//
+	// Labels that the operator sets take precedence.  Removing a label from
+	// the annotation does not remove it from the operands.
+	OperandLabelsAnnotation = "ingress.operator.openshift.io/operand-labels"
+
+	// OperandAnnotationsAnnotation may be set on an ingresscontroller to
+	// specify additional annotations for the router deployment, its pods,
+	// and its services, such as service mesh injection annotations.  The
+	// value is a JSON object that maps annotation keys to values, for
+	// example:
+	//
+	//   {"sidecar.istio.io/inject": "false"}
+	//
+	// Annotations that the operator sets take precedence.  Removing an
+	// annotation from the annotation does not remove it from the operands.
+	OperandAnnotationsAnnotation = "ingress.operator.openshift.io/operand-annotations"
+)
+
+// operandMetadata returns the additional labels and annotations that the given
+// ingresscontroller specifies for its operands.
+func operandMetadata(ic *operatorv1.IngressController) (map[string]string, map[string]string, error) {
+	labels, err := parseOperandMetadataAnnotation(ic, OperandLabelsAnnotation, validateOperandLabel)
+	if err != nil {
+		return nil, nil, err
+	}
+	annotations, err := parseOperandMetadataAnnotation(ic, OperandAnnotationsAnnotation, validateOperandAnnotation)
+	if err != nil {
+		return nil, nil, err
+	}
+	return labels, annotations, nil
+}
+
+// parseOperandMetadataAnnotation parses the JSON map in the given annotation
+// of the given ingresscontroller and validates each of its entries.
+func parseOperandMetadataAnnotation(ic *operatorv1.IngressController, annotation string, validate func(k, v string) []string) (map[string]string, error) {
+	value, ok := ic.Annotations[annotation]
+	if !ok {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	m := map[string]string{}
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", annotation, err)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if errs := validate(k, m[k]); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid value for annotation %s: key %q: %s", annotation, k, strings.Join(errs, "; "))
+		}
+	}
+	return m, nil
+}
+
+// validateOperandLabel returns the problems with the given label key and value.
+func validateOperandLabel(k, v string) []string {
+	return append(validation.IsQualifiedName(k), validation.IsValidLabelValue(v)...)
+}
+
+// validateOperandAnnotation returns the problems with the given annotation key.
+// The operator's own annotations may not be set this way.
+func validateOperandAnnotation(k, _ string) []string {
+	errs := validation.IsQualifiedName(strings.ToLower(k))
+	if strings.HasPrefix(k, "ingress.operator.openshift.io/") {
+		errs = append(errs, "annotations with the prefix ingress.operator.openshift.io/ are reserved for the operator")
+	}
+	return errs
+}
+
+// addOperandMetadata adds the given labels and annotations to the given object
+// metadata, leaving any labels and annotations that the operator already set.
+func addOperandMetadata(meta *metav1.ObjectMeta, labels, annotations map[string]string) {
+	meta.Labels = mergeMissing(meta.Labels, labels)
+	meta.Annotations = mergeMissing(meta.Annotations, annotations)
+}
+
+// mergeMissing returns a copy of dst with the entries of src whose keys dst
+// does not have.
+func mergeMissing(dst, src map[string]string) map[string]string {
+	if len(dst) == 0 && len(src) == 0 {
+		return dst
+	}
+	merged := make(map[string]string, len(dst)+len(src))
+	for k, v := range src {
+		merged[k] = v
+	}
+	for k, v := range dst {
+		merged[k] = v
+	}
+	return merged
+}
+
+// metadataContains returns true if the current object metadata has every label
+// and annotation of the expected object metadata.
+func metadataContains(current, expected metav1.ObjectMeta) bool {
+	return mapContains(current.Labels, expected.Labels) && mapContains(current.Annotations, expected.Annotations)
+}
+
+// updateMetadata sets the labels and annotations of the expected object
+// metadata on the updated object metadata, keeping any other labels and
+// annotations so that those that other actors add are not removed.
+func updateMetadata(updated *metav1.ObjectMeta, expected metav1.ObjectMeta) {
+	updated.Labels = mergeInto(updated.Labels, expected.Labels)
+	updated.Annotations = mergeInto(updated.Annotations, expected.Annotations)
+}
+
+// mergeInto returns a copy of dst with the entries of src, which replace any
+// entries of dst with the same keys.
+func mergeInto(dst, src map[string]string) map[string]string {
+	return mergeMissing(src, dst)
+}
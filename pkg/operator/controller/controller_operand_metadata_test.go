@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestOperandMetadata verifies that operandMetadata parses and validates the
+// operand labels and annotations annotations.
+func TestOperandMetadata(t *testing.T) {
+	testCases := []struct {
+		description       string
+		annotations       map[string]string
+		expectLabels      map[string]string
+		expectAnnotations map[string]string
+		expectError       bool
+	}{
+		{
+			description: "no annotations",
+		},
+		{
+			description: "valid labels and annotations",
+			annotations: map[string]string{
+				OperandLabelsAnnotation:      `{"cost-center": "1234"}`,
+				OperandAnnotationsAnnotation: `{"sidecar.istio.io/inject": "false"}`,
+			},
+			expectLabels:      map[string]string{"cost-center": "1234"},
+			expectAnnotations: map[string]string{"sidecar.istio.io/inject": "false"},
+		},
+		{
+			description: "malformed JSON",
+			annotations: map[string]string{OperandLabelsAnnotation: `{"cost-center": 1234}`},
+			expectError: true,
+		},
+		{
+			description: "invalid label key",
+			annotations: map[string]string{OperandLabelsAnnotation: `{"cost center": "1234"}`},
+			expectError: true,
+		},
+		{
+			description: "invalid label value",
+			annotations: map[string]string{OperandLabelsAnnotation: `{"cost-center": "12/34"}`},
+			expectError: true,
+		},
+		{
+			description: "reserved annotation",
+			annotations: map[string]string{OperandAnnotationsAnnotation: `{"ingress.operator.openshift.io/foo": "bar"}`},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+		}
+		labels, annotations, err := operandMetadata(ic)
+		if !checkError(t, tc.description, tc.expectError, err) {
+			continue
+		}
+		if !reflect.DeepEqual(labels, tc.expectLabels) {
+			t.Errorf("%q: expected labels %v, got %v", tc.description, tc.expectLabels, labels)
+		}
+		if !reflect.DeepEqual(annotations, tc.expectAnnotations) {
+			t.Errorf("%q: expected annotations %v, got %v", tc.description, tc.expectAnnotations, annotations)
+		}
+	}
+}
+
+// TestDesiredRouterDeploymentOperandMetadata verifies that the router
+// deployment and its pods get the operand labels and annotations, and that the
+// operator's own labels take precedence.
+func TestDesiredRouterDeploymentOperandMetadata(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				OperandLabelsAnnotation:      `{"cost-center": "1234", "ingresscontroller.operator.openshift.io/owning-ingresscontroller": "other"}`,
+				OperandAnnotationsAnnotation: `{"sidecar.istio.io/inject": "false"}`,
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	deployment, err := desiredRouterDeployment(ic, DefaultOperandNamespace, "quay.io/openshift/router:latest", &configv1.Infrastructure{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, meta := range []metav1.ObjectMeta{deployment.ObjectMeta, deployment.Spec.Template.ObjectMeta} {
+		if meta.Labels["cost-center"] != "1234" {
+			t.Errorf("expected label cost-center=1234, got %v", meta.Labels)
+		}
+		if meta.Annotations["sidecar.istio.io/inject"] != "false" {
+			t.Errorf("expected annotation sidecar.istio.io/inject=false, got %v", meta.Annotations)
+		}
+	}
+	if deployment.Labels["ingresscontroller.operator.openshift.io/owning-ingresscontroller"] != "default" {
+		t.Errorf("expected the operator's owning ingresscontroller label to take precedence, got %v", deployment.Labels)
+	}
+	if _, ok := deployment.Spec.Selector.MatchLabels["cost-center"]; ok {
+		t.Errorf("expected the selector not to include operand labels, got %v", deployment.Spec.Selector.MatchLabels)
+	}
+}
+
+// TestDeploymentConfigChangedKeepsOtherMetadata verifies that labels and
+// annotations that other actors add to the router deployment are neither
+// reported as a change nor removed by an update.
+func TestDeploymentConfigChangedKeepsOtherMetadata(t *testing.T) {
+	replicas := int32(2)
+	expected := newTestRouterDeployment()
+	expected.Spec.Replicas = &replicas
+	expected.Labels = map[string]string{"cost-center": "1234"}
+	current := expected.DeepCopy()
+	current.Labels["other"] = "value"
+	current.Spec.Template.Annotations = map[string]string{"kubectl.kubernetes.io/restartedAt": "now"}
+	if changed, _ := deploymentConfigChanged(current, expected); changed {
+		t.Fatal("expected no change for labels and annotations that other actors add")
+	}
+	current.Labels["cost-center"] = "5678"
+	changed, updated := deploymentConfigChanged(current, expected)
+	if !changed {
+		t.Fatal("expected a change for a changed operand label")
+	}
+	expectedLabels := map[string]string{"cost-center": "1234", "other": "value"}
+	if !reflect.DeepEqual(updated.Labels, expectedLabels) {
+		t.Errorf("expected labels %v, got %v", expectedLabels, updated.Labels)
+	}
+	if updated.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] != "now" {
+		t.Errorf("expected the pod template annotation to be kept, got %v", updated.Spec.Template.Annotations)
+	}
+}
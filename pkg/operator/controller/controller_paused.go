@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+)
+
+const (
+	// PausedAnnotation may be set to "true" on an ingresscontroller to
+	// pause its reconciliation, for example during incident response,
+	// without making every ingresscontroller unmanaged.  While the
+	// ingresscontroller is paused, the operator leaves its operands and
+	// its status as they are, except for the Paused condition, and does
+	// not finalize it if it is deleted.  Setting the annotation to "false"
+	// or removing it resumes reconciliation.
+	PausedAnnotation = "ingress.operator.openshift.io/paused"
+
+	// PausedConditionType is the type of the ingresscontroller condition
+	// that reports that the ingresscontroller's reconciliation is paused.
+	// The condition is present only while it is paused.
+	PausedConditionType = "Paused"
+)
+
+// isPaused returns true if the given ingresscontroller's reconciliation is
+// paused.  An invalid annotation value does not pause the ingresscontroller,
+// and admission rejects it.
+func isPaused(ic *operatorv1.IngressController) bool {
+	paused, _ := pausedFor(ic)
+	return paused
+}
+
+// pausedFor returns the value of the given ingresscontroller's paused
+// annotation.
+func pausedFor(ic *operatorv1.IngressController) (bool, error) {
+	switch value := ic.Annotations[PausedAnnotation]; value {
+	case "", "false":
+		return false, nil
+	case "true":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid value for annotation %s: %q: must be \"true\" or \"false\"", PausedAnnotation, value)
+	}
+}
+
+// computePausedCondition returns the Paused condition for the given
+// ingresscontroller, or nil if it is not paused.
+func computePausedCondition(ic *operatorv1.IngressController) *operatorv1.OperatorCondition {
+	if !isPaused(ic) {
+		return nil
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    PausedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "PausedByAnnotation",
+		Message: fmt.Sprintf("Reconciliation is paused by the %s annotation; the operands are left as they are", PausedAnnotation),
+	}
+}
+
+// syncPausedCondition publishes the Paused condition of the given
+// ingresscontroller to its status, or removes it if the ingresscontroller is
+// not paused.  The given ingresscontroller's status is updated in place.
+func (r *reconciler) syncPausedCondition(ic *operatorv1.IngressController) error {
+	updated := ic.DeepCopy()
+	if condition := computePausedCondition(ic); condition != nil {
+		updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, condition)
+	} else {
+		updated.Status.Conditions = removeIngressStatusCondition(updated.Status.Conditions, PausedConditionType)
+	}
+	if ingressStatusesEqual(updated.Status, ic.Status) {
+		return nil
+	}
+	if err := r.client.PatchStatus(context.TODO(), updated, operatorclient.MergeFromWithOptimisticLock(ic)); err != nil {
+		return fmt.Errorf("failed to update status of ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	if isPaused(ic) {
+		r.recorder.Event(ic, "Normal", "Paused", "Reconciliation is paused")
+	} else {
+		r.recorder.Event(ic, "Normal", "Resumed", "Reconciliation is resumed")
+	}
+	ic.Status = updated.Status
+	return nil
+}
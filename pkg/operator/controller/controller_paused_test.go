@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestComputePausedCondition(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expectPause bool
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "not paused",
+			annotations: map[string]string{PausedAnnotation: "false"},
+		},
+		{
+			description: "paused",
+			annotations: map[string]string{PausedAnnotation: "true"},
+			expectPause: true,
+		},
+		{
+			description: "invalid value",
+			annotations: map[string]string{PausedAnnotation: "yes"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: tc.annotations}}
+		if err := validateIngressController(ic, false, configv1.AWSPlatformType); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected validation error to be %t, got %v", tc.description, tc.expectErr, err)
+		}
+		condition := computePausedCondition(ic)
+		switch {
+		case tc.expectPause && (condition == nil || condition.Status != operatorv1.ConditionTrue):
+			t.Errorf("%q: expected a true Paused condition, got %#v", tc.description, condition)
+		case !tc.expectPause && condition != nil:
+			t.Errorf("%q: expected no Paused condition, got %#v", tc.description, condition)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// EndpointPublishingDegradedConditionType is the type of the
+	// ingresscontroller condition that reports whether its load balancer
+	// or wildcard DNS record has failed to converge for longer than
+	// endpointPublishingGracePeriod.
+	EndpointPublishingDegradedConditionType = "EndpointPublishingDegraded"
+
+	// endpointPublishingGracePeriod is how long provisioning a load
+	// balancer or publishing the wildcard DNS record may take before the
+	// wait is no longer considered part of a rollout and the
+	// ingresscontroller is reported as degraded instead of progressing.
+	endpointPublishingGracePeriod = 10 * time.Minute
+)
+
+// computeProgressingConditions computes the Progressing and
+// EndpointPublishingDegraded conditions of the given ingresscontroller from its
+// router workload, its LB service, which is nil if it is unknown, and its
+// wildcard DNSRecord.
+func (r *reconciler) computeProgressingConditions(ic *operatorv1.IngressController, deployment *appsv1.Deployment, service *corev1.Service) (*operatorv1.OperatorCondition, *operatorv1.OperatorCondition, error) {
+	usesLoadBalancer := ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.LoadBalancerServiceStrategyType
+	if usesLoadBalancer && service == nil {
+		current, err := r.currentLoadBalancerService(ic)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get load balancer service: %v", err)
+		}
+		service = current
+	}
+	var record *ingressv1.DNSRecord
+	if usesLoadBalancer && !usesExternalDNS(ic) && len(ic.Status.Domain) != 0 {
+		current, err := r.currentWildcardRecord(ic)
+		if err != nil {
+			return nil, nil, err
+		}
+		record = current
+	}
+	now := time.Now()
+	return progressingCondition(ic, deployment, service, record, now), endpointPublishingDegradedCondition(ic, service, record, now), nil
+}
+
+// progressingCondition returns the Progressing condition for the given
+// ingresscontroller, router workload, LB service, and wildcard DNSRecord.  The
+// ingresscontroller is progressing while a change is being rolled out: while
+// the router workload is rolling out, and while its load balancer or wildcard
+// DNS record is being created or updated, for at most
+// endpointPublishingGracePeriod.  The condition's message lists each resource
+// that is still converging, along with what it is waiting for.  A load
+// balancer or DNS record that takes longer is reported by
+// endpointPublishingDegradedCondition instead so that a steady-state failure,
+// such as a cloud provider that cannot provision the load balancer, does not
+// leave the ingresscontroller progressing indefinitely.
+func progressingCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment, service *corev1.Service, record *ingressv1.DNSRecord, now time.Time) *operatorv1.OperatorCondition {
+	var converging []string
+
+	workload := "router deployment"
+	if deployment.Kind == daemonSetKind {
+		workload = "router daemonset"
+	}
+	if rollingOut := computeDeploymentRollingOutCondition(deployment); rollingOut.Status == operatorv1.ConditionTrue {
+		converging = append(converging, fmt.Sprintf("%s %s/%s: %s", workload, deployment.Namespace, deployment.Name, rollingOut.Message))
+	}
+	rollingOut, _ := endpointPublishingWaits(ic, service, record, now)
+	converging = append(converging, rollingOut...)
+
+	if len(converging) == 0 {
+		return &operatorv1.OperatorCondition{
+			Type:    operatorv1.OperatorStatusTypeProgressing,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "AsExpected",
+			Message: "No changes to the router workload, load balancer, or DNS are being rolled out",
+		}
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    operatorv1.OperatorStatusTypeProgressing,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Converging",
+		Message: "The following resources are still converging: " + strings.Join(converging, "; "),
+	}
+}
+
+// endpointPublishingDegradedCondition returns the EndpointPublishingDegraded
+// condition for the given ingresscontroller, LB service, and wildcard
+// DNSRecord.  The ingresscontroller is degraded while its load balancer has
+// not been provisioned, or its wildcard DNS record has not been published to
+// some zone, for longer than endpointPublishingGracePeriod.
+func endpointPublishingDegradedCondition(ic *operatorv1.IngressController, service *corev1.Service, record *ingressv1.DNSRecord, now time.Time) *operatorv1.OperatorCondition {
+	_, stalled := endpointPublishingWaits(ic, service, record, now)
+	if len(stalled) == 0 {
+		return &operatorv1.OperatorCondition{
+			Type:   EndpointPublishingDegradedConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    EndpointPublishingDegradedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "EndpointPublishingStalled",
+		Message: "The following resources have not converged: " + strings.Join(stalled, "; "),
+	}
+}
+
+// endpointPublishingWaits returns a description of each way in which the given
+// ingresscontroller's LB service and wildcard DNSRecord have not converged.
+// The waits that are part of a rollout are returned in rollingOut, and those
+// that have lasted longer than endpointPublishingGracePeriod in stalled.  The
+// service and record are expected only if the ingresscontroller uses a load
+// balancer and the operator manages its DNS; if they are expected but nil,
+// they have not been created yet.
+func endpointPublishingWaits(ic *operatorv1.IngressController, service *corev1.Service, record *ingressv1.DNSRecord, now time.Time) ([]string, []string) {
+	usesLoadBalancer := ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.LoadBalancerServiceStrategyType
+	if !usesLoadBalancer {
+		return nil, nil
+	}
+	var rollingOut, stalled []string
+	switch {
+	case service == nil:
+		rollingOut = append(rollingOut, "load balancer service: not yet created")
+	case len(service.Status.LoadBalancer.Ingress) != 0:
+	case now.Sub(service.CreationTimestamp.Time) > endpointPublishingGracePeriod:
+		stalled = append(stalled, fmt.Sprintf("load balancer service %s/%s: the load balancer has not been provisioned for more than %s", service.Namespace, service.Name, endpointPublishingGracePeriod))
+	default:
+		rollingOut = append(rollingOut, fmt.Sprintf("load balancer service %s/%s: waiting for the load balancer to be provisioned", service.Namespace, service.Name))
+	}
+	if !usesExternalDNS(ic) && len(ic.Status.Domain) != 0 {
+		recordRollingOut, recordStalled := dnsRecordWaits(record, now)
+		rollingOut = append(rollingOut, recordRollingOut...)
+		stalled = append(stalled, recordStalled...)
+	}
+	return rollingOut, stalled
+}
+
+// dnsRecordWaits returns a description of each way in which the given wildcard
+// DNSRecord has not converged, split as in endpointPublishingWaits.  A zone
+// has been waiting since its Published condition last changed, or since the
+// record was created if the zone has no such condition yet.  A dry-run record
+// is not published by design, so it converges once its status is up to date.
+func dnsRecordWaits(record *ingressv1.DNSRecord, now time.Time) ([]string, []string) {
+	if record == nil {
+		return []string{"wildcard DNS record: not yet created"}, nil
+	}
+	name := fmt.Sprintf("wildcard DNS record %s/%s", record.Namespace, record.Name)
+	if record.Status.ObservedGeneration < record.Generation {
+		return []string{fmt.Sprintf("%s: waiting for generation %d to be observed, observed generation is %d", name, record.Generation, record.Status.ObservedGeneration)}, nil
+	}
+	if record.Spec.DryRun {
+		return nil, nil
+	}
+	var pendingZones, stalledZones []string
+	for _, zone := range record.Status.Zones {
+		published := false
+		since := record.CreationTimestamp.Time
+		for _, condition := range zone.Conditions {
+			if condition.Type != ingressv1.DNSRecordPublishedConditionType {
+				continue
+			}
+			if condition.Status == string(configv1.ConditionTrue) {
+				published = true
+			}
+			if !condition.LastTransitionTime.IsZero() {
+				since = condition.LastTransitionTime.Time
+			}
+		}
+		switch {
+		case published:
+		case now.Sub(since) > endpointPublishingGracePeriod:
+			stalledZones = append(stalledZones, dnsZoneDescription(zone.DNSZone))
+		default:
+			pendingZones = append(pendingZones, dnsZoneDescription(zone.DNSZone))
+		}
+	}
+	var rollingOut, stalled []string
+	if len(pendingZones) != 0 {
+		sort.Strings(pendingZones)
+		rollingOut = append(rollingOut, fmt.Sprintf("%s: not yet published to zones %s", name, strings.Join(pendingZones, ", ")))
+	}
+	if len(stalledZones) != 0 {
+		sort.Strings(stalledZones)
+		stalled = append(stalled, fmt.Sprintf("%s: not published to zones %s for more than %s", name, strings.Join(stalledZones, ", "), endpointPublishingGracePeriod))
+	}
+	return rollingOut, stalled
+}
+
+// dnsZoneDescription returns the ID of the given zone, or its tags if it has no
+// ID.
+func dnsZoneDescription(zone configv1.DNSZone) string {
+	if len(zone.ID) != 0 {
+		return zone.ID
+	}
+	var tags []string
+	for k, v := range zone.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+	return "{" + strings.Join(tags, ",") + "}"
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProgressingCondition(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	var replicas int32 = 2
+	deployment := func(generation, observedGeneration int64, updated int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default", Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observedGeneration,
+				Replicas:           2,
+				UpdatedReplicas:    updated,
+				AvailableReplicas:  2,
+			},
+		}
+	}
+	now := time.Now()
+	recent := metav1.NewTime(now.Add(-time.Minute))
+	stale := metav1.NewTime(now.Add(-time.Hour))
+	service := func(provisioned bool) *corev1.Service {
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default", CreationTimestamp: recent}}
+		if provisioned {
+			service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+		}
+		return service
+	}
+	staleService := service(false)
+	staleService.CreationTimestamp = stale
+	zone := func(id string, published configv1.ConditionStatus) ingressv1.DNSZoneStatus {
+		return ingressv1.DNSZoneStatus{
+			DNSZone: configv1.DNSZone{ID: id},
+			Conditions: []ingressv1.DNSZoneCondition{{
+				Type:               ingressv1.DNSRecordPublishedConditionType,
+				Status:             string(published),
+				LastTransitionTime: recent,
+			}},
+		}
+	}
+	staleZone := func(id string) ingressv1.DNSZoneStatus {
+		zone := zone(id, configv1.ConditionFalse)
+		zone.Conditions[0].LastTransitionTime = stale
+		return zone
+	}
+	record := func(generation, observedGeneration int64, zones ...ingressv1.DNSZoneStatus) *ingressv1.DNSRecord {
+		return &ingressv1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default-wildcard", Generation: generation},
+			Status:     ingressv1.DNSRecordStatus{ObservedGeneration: observedGeneration, Zones: zones},
+		}
+	}
+	testCases := []struct {
+		description   string
+		deployment    *appsv1.Deployment
+		service       *corev1.Service
+		record        *ingressv1.DNSRecord
+		expectStatus  operatorv1.ConditionStatus
+		expectMessage string
+		// expectDegraded is the expected message of the
+		// EndpointPublishingDegraded condition, which is empty if it is
+		// expected to be False.
+		expectDegraded string
+	}{
+		{
+			description:   "everything converged",
+			deployment:    deployment(1, 1, 2),
+			service:       service(true),
+			record:        record(1, 1, zone("public", configv1.ConditionTrue), zone("private", configv1.ConditionTrue)),
+			expectStatus:  operatorv1.ConditionFalse,
+			expectMessage: "No changes to the router workload, load balancer, or DNS are being rolled out",
+		},
+		{
+			description:   "deployment update not observed",
+			deployment:    deployment(2, 1, 2),
+			service:       service(true),
+			record:        record(1, 1, zone("public", configv1.ConditionTrue)),
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: "The following resources are still converging: router deployment openshift-ingress/router-default: Waiting for the router deployment spec update to be observed",
+		},
+		{
+			description:   "load balancer pending and record not created",
+			deployment:    deployment(1, 1, 2),
+			service:       service(false),
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: "The following resources are still converging: load balancer service openshift-ingress/router-default: waiting for the load balancer to be provisioned; wildcard DNS record: not yet created",
+		},
+		{
+			description:   "rollout and record update in progress",
+			deployment:    deployment(1, 1, 1),
+			service:       service(true),
+			record:        record(3, 2),
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: "The following resources are still converging: router deployment openshift-ingress/router-default: 1 of 2 new replica(s) have been updated; wildcard DNS record openshift-ingress-operator/default-wildcard: waiting for generation 3 to be observed, observed generation is 2",
+		},
+		{
+			description:   "record not published to a zone",
+			deployment:    deployment(1, 1, 2),
+			service:       service(true),
+			record:        record(1, 1, zone("public", configv1.ConditionTrue), zone("private", configv1.ConditionFalse)),
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: "The following resources are still converging: wildcard DNS record openshift-ingress-operator/default-wildcard: not yet published to zones private",
+		},
+		{
+			description:    "load balancer not provisioned after the grace period",
+			deployment:     deployment(1, 1, 2),
+			service:        staleService,
+			record:         record(1, 1),
+			expectStatus:   operatorv1.ConditionFalse,
+			expectMessage:  "No changes to the router workload, load balancer, or DNS are being rolled out",
+			expectDegraded: "The following resources have not converged: load balancer service openshift-ingress/router-default: the load balancer has not been provisioned for more than 10m0s",
+		},
+		{
+			description:    "record not published to a zone after the grace period during a rollout",
+			deployment:     deployment(1, 1, 1),
+			service:        service(true),
+			record:         record(1, 1, zone("public", configv1.ConditionFalse), staleZone("private")),
+			expectStatus:   operatorv1.ConditionTrue,
+			expectMessage:  "The following resources are still converging: router deployment openshift-ingress/router-default: 1 of 2 new replica(s) have been updated; wildcard DNS record openshift-ingress-operator/default-wildcard: not yet published to zones public",
+			expectDegraded: "The following resources have not converged: wildcard DNS record openshift-ingress-operator/default-wildcard: not published to zones private for more than 10m0s",
+		},
+	}
+	for _, tc := range testCases {
+		actual := progressingCondition(ic, tc.deployment, tc.service, tc.record, now)
+		if actual.Type != operatorv1.OperatorStatusTypeProgressing || actual.Status != tc.expectStatus {
+			t.Errorf("%q: expected Progressing=%s, got %s=%s", tc.description, tc.expectStatus, actual.Type, actual.Status)
+			continue
+		}
+		if actual.Message != tc.expectMessage {
+			t.Errorf("%q: expected message %q, got %q", tc.description, tc.expectMessage, actual.Message)
+		}
+		degraded := endpointPublishingDegradedCondition(ic, tc.service, tc.record, now)
+		switch {
+		case len(tc.expectDegraded) == 0 && degraded.Status != operatorv1.ConditionFalse:
+			t.Errorf("%q: expected %s=False, got %s: %s", tc.description, EndpointPublishingDegradedConditionType, degraded.Status, degraded.Message)
+		case len(tc.expectDegraded) != 0 && (degraded.Status != operatorv1.ConditionTrue || degraded.Message != tc.expectDegraded):
+			t.Errorf("%q: expected %s=True with message %q, got %s with message %q", tc.description, EndpointPublishingDegradedConditionType, tc.expectDegraded, degraded.Status, degraded.Message)
+		}
+	}
+}
+
+func TestDNSZoneDescription(t *testing.T) {
+	if actual := dnsZoneDescription(configv1.DNSZone{ID: "Z123"}); actual != "Z123" {
+		t.Errorf("expected Z123, got %s", actual)
+	}
+	zone := configv1.DNSZone{Tags: map[string]string{"Name": "foo-int", "kubernetes.io/cluster/foo": "owned"}}
+	if expect, actual := "{Name=foo-int,kubernetes.io/cluster/foo=owned}", dnsZoneDescription(zone); actual != expect {
+		t.Errorf("expected %s, got %s", expect, actual)
+	}
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// RelatedObjectsAnnotation is an annotation that the operator sets on
+	// each ingresscontroller to reference the objects that it manages for
+	// the ingresscontroller.  The IngressController API has no status
+	// field for these references, so they are kept in this annotation.
+	// The value is a JSON array of objects with the "group", "resource",
+	// "namespace", and "name" fields of the clusteroperator's
+	// relatedObjects, which also lists them, so that tools that inspect
+	// one ingresscontroller can collect its router workload, services,
+	// and DNSRecords.  The annotation is not meant to be set by users; the
+	// operator overwrites it.
+	RelatedObjectsAnnotation = "ingress.operator.openshift.io/related-objects"
+)
+
+// ingressControllerRelatedObjects returns the related objects of the given
+// ingresscontroller: the ingresscontroller itself and the names of its router
+// workload, services, and wildcard DNSRecords.
+func ingressControllerRelatedObjects(ic *operatorv1.IngressController, operandNamespace string) []configv1.ObjectReference {
+	relatedObjects := []configv1.ObjectReference{{
+		Group:     operatorv1.GroupName,
+		Resource:  "ingresscontrollers",
+		Namespace: ic.Namespace,
+		Name:      ic.Name,
+	}}
+	workload := "deployments"
+	if useDaemonSet, err := routerUsesDaemonSet(ic); err == nil && useDaemonSet {
+		workload = "daemonsets"
+	}
+	name := RouterDeploymentName(ic, operandNamespace)
+	relatedObjects = append(relatedObjects, configv1.ObjectReference{
+		Group:     "apps",
+		Resource:  workload,
+		Namespace: name.Namespace,
+		Name:      name.Name,
+	})
+	name = InternalIngressControllerServiceName(ic, operandNamespace)
+	relatedObjects = append(relatedObjects, configv1.ObjectReference{
+		Resource:  "services",
+		Namespace: name.Namespace,
+		Name:      name.Name,
+	})
+	if ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == NodePortServiceStrategyType {
+		name = NodePortServiceName(ic, operandNamespace)
+		relatedObjects = append(relatedObjects, configv1.ObjectReference{
+			Resource:  "services",
+			Namespace: name.Namespace,
+			Name:      name.Name,
+		})
+	}
+	if ic.Status.EndpointPublishingStrategy == nil || ic.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
+		return relatedObjects
+	}
+	for _, name := range []types.NamespacedName{
+		LoadBalancerServiceName(ic, operandNamespace),
+		AlternateLoadBalancerServiceName(ic, operandNamespace),
+	} {
+		relatedObjects = append(relatedObjects, configv1.ObjectReference{
+			Resource:  "services",
+			Namespace: name.Namespace,
+			Name:      name.Name,
+		})
+	}
+	name = WildcardDNSRecordName(ic)
+	relatedObjects = append(relatedObjects, configv1.ObjectReference{
+		Group:     ingressv1.GroupName,
+		Resource:  "dnsrecords",
+		Namespace: name.Namespace,
+		Name:      name.Name,
+	})
+	if enabled, err := internalLoadBalancerEnabled(ic); err != nil || !enabled {
+		return relatedObjects
+	}
+	name = InternalLoadBalancerServiceName(ic, operandNamespace)
+	relatedObjects = append(relatedObjects, configv1.ObjectReference{
+		Resource:  "services",
+		Namespace: name.Namespace,
+		Name:      name.Name,
+	})
+	name = InternalWildcardDNSRecordName(ic)
+	relatedObjects = append(relatedObjects, configv1.ObjectReference{
+		Group:     ingressv1.GroupName,
+		Resource:  "dnsrecords",
+		Namespace: name.Namespace,
+		Name:      name.Name,
+	})
+	return relatedObjects
+}
+
+// ensureRelatedObjectsAnnotation sets RelatedObjectsAnnotation on the given
+// ingresscontroller to its current related objects.  The ingresscontroller is
+// updated in place so that later updates of its status use the new resource
+// version.
+func (r *reconciler) ensureRelatedObjectsAnnotation(ic *operatorv1.IngressController) error {
+	value, err := json.Marshal(ingressControllerRelatedObjects(ic, r.Config.OperandNamespace))
+	if err != nil {
+		return fmt.Errorf("failed to marshal related objects: %v", err)
+	}
+	if current, ok := ic.Annotations[RelatedObjectsAnnotation]; ok && current == string(value) {
+		return nil
+	}
+	if ic.Annotations == nil {
+		ic.Annotations = map[string]string{}
+	}
+	ic.Annotations[RelatedObjectsAnnotation] = string(value)
+	if err := r.client.Update(context.TODO(), ic); err != nil {
+		return fmt.Errorf("failed to update related objects annotation: %v", err)
+	}
+	log.Info("updated related objects annotation", "namespace", ic.Namespace, "name", ic.Name)
+	return nil
+}
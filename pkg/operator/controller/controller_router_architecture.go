@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	// NodeArchitectureAnnotation may be set on an IngressController to
+	// place its router pods only on nodes with the given CPU architecture,
+	// for example "amd64" or "arm64".  This is useful in clusters with
+	// mixed architectures if the router image is not built for all of
+	// them.  By default, router pods may run on nodes of any architecture.
+	NodeArchitectureAnnotation = "ingress.operator.openshift.io/node-architecture"
+
+	// nodeArchitectureLabel is the well-known node label with the node's
+	// CPU architecture.
+	nodeArchitectureLabel = "kubernetes.io/arch"
+	// betaNodeArchitectureLabel is the deprecated form of
+	// nodeArchitectureLabel.
+	betaNodeArchitectureLabel = "beta.kubernetes.io/arch"
+)
+
+// supportedArchitectures are the CPU architectures for which OpenShift
+// releases images.
+var supportedArchitectures = map[string]bool{
+	"amd64":   true,
+	"arm64":   true,
+	"ppc64le": true,
+	"s390x":   true,
+}
+
+// applyNodeArchitecture adds the node architecture that the given
+// ingresscontroller requests, if any, to the given node selector.
+func applyNodeArchitecture(ic *operatorv1.IngressController, nodeSelector map[string]string) error {
+	arch, ok := ic.Annotations[NodeArchitectureAnnotation]
+	if !ok {
+		return nil
+	}
+	if !supportedArchitectures[arch] {
+		supported := make([]string, 0, len(supportedArchitectures))
+		for a := range supportedArchitectures {
+			supported = append(supported, a)
+		}
+		sort.Strings(supported)
+		return fmt.Errorf("invalid value for annotation %s: %q: must be one of %s", NodeArchitectureAnnotation, arch, strings.Join(supported, ", "))
+	}
+	for _, label := range []string{nodeArchitectureLabel, betaNodeArchitectureLabel} {
+		if value, ok := nodeSelector[label]; ok && value != arch {
+			return fmt.Errorf("annotation %s requests architecture %q, but the node selector requires %s=%s", NodeArchitectureAnnotation, arch, label, value)
+		}
+	}
+	nodeSelector[nodeArchitectureLabel] = arch
+	return nil
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestApplyNodeArchitecture(t *testing.T) {
+	testCases := []struct {
+		description  string
+		annotations  map[string]string
+		nodeSelector map[string]string
+		expect       map[string]string
+		expectErr    bool
+	}{
+		{
+			description:  "no annotation",
+			nodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+			expect:       map[string]string{"node-role.kubernetes.io/worker": ""},
+		},
+		{
+			description:  "arm64",
+			annotations:  map[string]string{NodeArchitectureAnnotation: "arm64"},
+			nodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+			expect:       map[string]string{"node-role.kubernetes.io/worker": "", "kubernetes.io/arch": "arm64"},
+		},
+		{
+			description:  "matching architecture in node selector",
+			annotations:  map[string]string{NodeArchitectureAnnotation: "amd64"},
+			nodeSelector: map[string]string{"kubernetes.io/arch": "amd64"},
+			expect:       map[string]string{"kubernetes.io/arch": "amd64"},
+		},
+		{
+			description:  "conflicting architecture in node selector",
+			annotations:  map[string]string{NodeArchitectureAnnotation: "amd64"},
+			nodeSelector: map[string]string{"beta.kubernetes.io/arch": "arm64"},
+			expectErr:    true,
+		},
+		{
+			description:  "unsupported architecture",
+			annotations:  map[string]string{NodeArchitectureAnnotation: "riscv64"},
+			nodeSelector: map[string]string{},
+			expectErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		err := applyNodeArchitecture(ic, tc.nodeSelector)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(tc.nodeSelector, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, tc.nodeSelector)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ConnectionLimitsAnnotation may be set on an ingresscontroller to
+	// bound the number of concurrent connections that its routers accept,
+	// so that a noisy tenant cannot exhaust a shard that it shares with
+	// others.  The value is a JSON object with any of the following
+	// fields, for example:
+	//
+	//   {"maxConnections": 50000, "maxConnectionsPerRoute": 5000}
+	//
+	// maxConnections is the hard limit on concurrent connections per
+	// router (HAProxy's global maxconn); connections beyond it wait in the
+	// kernel's accept queue.  maxConnectionsPerRoute is the default limit
+	// on concurrent connections to each route's backend; requests beyond
+	// it are queued by the router.  Individual routes can override the
+	// per-route default with the
+	// haproxy.router.openshift.io/pod-concurrent-connections annotation.
+	ConnectionLimitsAnnotation = "ingress.operator.openshift.io/connection-limits"
+)
+
+// connectionLimits is the value of ConnectionLimitsAnnotation.  Zero values
+// are unspecified and keep the router's defaults.
+type connectionLimits struct {
+	MaxConnections         int32 `json:"maxConnections,omitempty"`
+	MaxConnectionsPerRoute int32 `json:"maxConnectionsPerRoute,omitempty"`
+}
+
+// connectionLimitsFor returns the connection limits that the given
+// ingresscontroller specifies, or nil if it does not specify any.
+func connectionLimitsFor(ic *operatorv1.IngressController) (*connectionLimits, error) {
+	value, ok := ic.Annotations[ConnectionLimitsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	limits := &connectionLimits{}
+	if err := decoder.Decode(limits); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", ConnectionLimitsAnnotation, err)
+	}
+	if limits.MaxConnections != 0 {
+		if err := validateTuningOption(maxConnectionsOption, int64(limits.MaxConnections)); err != nil {
+			return nil, fmt.Errorf("invalid value for annotation %s: %v", ConnectionLimitsAnnotation, err)
+		}
+	}
+	if err := validateTuningOption(maxConnectionsPerRouteOption, int64(limits.MaxConnectionsPerRoute)); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", ConnectionLimitsAnnotation, err)
+	}
+	if limits.MaxConnections != 0 && limits.MaxConnectionsPerRoute > limits.MaxConnections {
+		return nil, fmt.Errorf("invalid value for annotation %s: maxConnectionsPerRoute must not exceed maxConnections", ConnectionLimitsAnnotation)
+	}
+	return limits, nil
+}
+
+// desiredConnectionLimitsEnv returns the router environment variables that
+// configure the connection limits of the given ingresscontroller.
+func desiredConnectionLimitsEnv(ic *operatorv1.IngressController) ([]corev1.EnvVar, error) {
+	limits, err := connectionLimitsFor(ic)
+	if err != nil || limits == nil {
+		return nil, err
+	}
+	var env []corev1.EnvVar
+	if limits.MaxConnections != 0 {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_MAX_CONNECTIONS", Value: strconv.Itoa(int(limits.MaxConnections))})
+	}
+	if limits.MaxConnectionsPerRoute != 0 {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_MAX_CONNECTIONS", Value: strconv.Itoa(int(limits.MaxConnectionsPerRoute))})
+	}
+	return env, nil
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDesiredConnectionLimitsEnv(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expect      []corev1.EnvVar
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "empty object",
+			annotations: map[string]string{ConnectionLimitsAnnotation: "{}"},
+		},
+		{
+			description: "both limits",
+			annotations: map[string]string{ConnectionLimitsAnnotation: `{"maxConnections": 50000, "maxConnectionsPerRoute": 5000}`},
+			expect: []corev1.EnvVar{
+				{Name: "ROUTER_MAX_CONNECTIONS", Value: "50000"},
+				{Name: "ROUTER_DEFAULT_MAX_CONNECTIONS", Value: "5000"},
+			},
+		},
+		{
+			description: "per-route limit only",
+			annotations: map[string]string{ConnectionLimitsAnnotation: `{"maxConnectionsPerRoute": 100}`},
+			expect:      []corev1.EnvVar{{Name: "ROUTER_DEFAULT_MAX_CONNECTIONS", Value: "100"}},
+		},
+		{
+			description: "maxConnections too low",
+			annotations: map[string]string{ConnectionLimitsAnnotation: `{"maxConnections": 100}`},
+			expectErr:   true,
+		},
+		{
+			description: "negative per-route limit",
+			annotations: map[string]string{ConnectionLimitsAnnotation: `{"maxConnectionsPerRoute": -1}`},
+			expectErr:   true,
+		},
+		{
+			description: "per-route limit exceeds maxConnections",
+			annotations: map[string]string{ConnectionLimitsAnnotation: `{"maxConnections": 2000, "maxConnectionsPerRoute": 3000}`},
+			expectErr:   true,
+		},
+		{
+			description: "unknown field",
+			annotations: map[string]string{ConnectionLimitsAnnotation: `{"maxConn": 2000}`},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		env, err := desiredConnectionLimitsEnv(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(env, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, env)
+		}
+	}
+}
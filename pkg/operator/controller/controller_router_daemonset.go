@@ -0,0 +1,407 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// RouterWorkloadKindAnnotation may be set on an IngressController to
+	// select the kind of workload that runs the router pods.  The value
+	// must be "Deployment", which is the default, or "DaemonSet".  A
+	// DaemonSet runs exactly one router on each node that matches the
+	// ingresscontroller's node placement, and spec.replicas is ignored.
+	// A DaemonSet is only allowed with the HostNetwork endpoint publishing
+	// strategy, which already limits the router to one pod per node.
+	RouterWorkloadKindAnnotation = "ingress.operator.openshift.io/router-workload-kind"
+
+	// daemonSetKind is the kind of the router daemonset.
+	daemonSetKind = "DaemonSet"
+)
+
+// routerUsesDaemonSet returns true if the given ingresscontroller requests a
+// router daemonset rather than a deployment.
+func routerUsesDaemonSet(ic *operatorv1.IngressController) (bool, error) {
+	value, ok := ic.Annotations[RouterWorkloadKindAnnotation]
+	if !ok {
+		return false, nil
+	}
+	switch value {
+	case "Deployment":
+		return false, nil
+	case daemonSetKind:
+		if ic.Status.EndpointPublishingStrategy == nil || ic.Status.EndpointPublishingStrategy.Type != operatorv1.HostNetworkStrategyType {
+			return false, fmt.Errorf("invalid value for annotation %s: %q: a DaemonSet requires the %s endpoint publishing strategy", RouterWorkloadKindAnnotation, value, operatorv1.HostNetworkStrategyType)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid value for annotation %s: %q: must be \"Deployment\" or %q", RouterWorkloadKindAnnotation, value, daemonSetKind)
+}
+
+// ensureRouterWorkload ensures that the router deployment or daemonset exists
+// for the given ingresscontroller, whichever it requests, and that the other
+// does not.  It returns a reference to the workload, which owns the router's
+// other operands, and the workload's status as a deployment.
+func (r *reconciler) ensureRouterWorkload(ci *operatorv1.IngressController, infraConfig *configv1.Infrastructure) (*appsv1.Deployment, metav1.OwnerReference, error) {
+	trueVar := true
+	useDaemonSet, err := routerUsesDaemonSet(ci)
+	if err != nil {
+		return nil, metav1.OwnerReference{}, newTerminalError(err)
+	}
+	if !useDaemonSet {
+		deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+		if err != nil {
+			return nil, metav1.OwnerReference{}, err
+		}
+		deploymentRef := metav1.OwnerReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       deployment.Name,
+			UID:        deployment.UID,
+			Controller: &trueVar,
+		}
+		if err := r.ensureReplacedRouterDaemonSetDeleted(ci, deploymentRef); err != nil {
+			return nil, metav1.OwnerReference{}, err
+		}
+		return deployment, deploymentRef, nil
+	}
+	daemonset, err := r.ensureRouterDaemonSet(ci, infraConfig)
+	if err != nil {
+		return nil, metav1.OwnerReference{}, err
+	}
+	daemonsetRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       daemonSetKind,
+		Name:       daemonset.Name,
+		UID:        daemonset.UID,
+		Controller: &trueVar,
+	}
+	if err := r.ensureReplacedRouterDeploymentDeleted(ci, daemonsetRef); err != nil {
+		return nil, metav1.OwnerReference{}, err
+	}
+	return daemonSetAsDeployment(daemonset), daemonsetRef, nil
+}
+
+// ensureReplacedRouterDeploymentDeleted deletes the router deployment for the
+// given ingresscontroller, if it exists, after the router daemonset with the
+// given reference has replaced it.  The router's other operands are re-parented
+// to the daemonset, and the deployment is deleted with orphan propagation so
+// that the garbage collector cannot delete them, which would recreate the
+// router's services and, with them, its load balancers and DNS records.  The
+// deployment's replicasets are then deleted explicitly.
+func (r *reconciler) ensureReplacedRouterDeploymentDeleted(ci *operatorv1.IngressController, workloadRef metav1.OwnerReference) error {
+	deployment := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), RouterDeploymentName(ci, r.Config.OperandNamespace), deployment); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get router deployment %s: %v", RouterDeploymentName(ci, r.Config.OperandNamespace), err)
+	}
+	if err := r.reparentRouterOperands(ci, deployment.UID, workloadRef); err != nil {
+		return err
+	}
+	if err := r.client.Delete(context.TODO(), deployment, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete router deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+	}
+	log.Info("deleted replaced router deployment", "namespace", deployment.Namespace, "name", deployment.Name)
+	replicaSets := &appsv1.ReplicaSetList{}
+	if err := r.client.List(context.TODO(), replicaSets, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		return fmt.Errorf("failed to list replicasets of router deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+	}
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if !isOrphanedBy(rs.OwnerReferences, deployment.UID) {
+			continue
+		}
+		if err := r.client.Delete(context.TODO(), rs); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete replicaset %s/%s: %v", rs.Namespace, rs.Name, err)
+		}
+		log.Info("deleted replicaset of replaced router deployment", "namespace", rs.Namespace, "name", rs.Name)
+	}
+	return nil
+}
+
+// ensureReplacedRouterDaemonSetDeleted deletes the router daemonset for the
+// given ingresscontroller, if it exists, after the router deployment with the
+// given reference has replaced it, as ensureReplacedRouterDeploymentDeleted
+// does for the deployment.  The daemonset's pods are deleted explicitly.
+func (r *reconciler) ensureReplacedRouterDaemonSetDeleted(ci *operatorv1.IngressController, workloadRef metav1.OwnerReference) error {
+	daemonset, err := r.currentRouterDaemonSet(ci)
+	if err != nil || daemonset == nil {
+		return err
+	}
+	if err := r.reparentRouterOperands(ci, daemonset.UID, workloadRef); err != nil {
+		return err
+	}
+	if err := r.client.Delete(context.TODO(), daemonset, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete router daemonset %s/%s: %v", daemonset.Namespace, daemonset.Name, err)
+	}
+	log.Info("deleted replaced router daemonset", "namespace", daemonset.Namespace, "name", daemonset.Name)
+	pods := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), pods, client.InNamespace(daemonset.Namespace), client.MatchingLabels(daemonset.Spec.Selector.MatchLabels)); err != nil {
+		return fmt.Errorf("failed to list pods of router daemonset %s/%s: %v", daemonset.Namespace, daemonset.Name, err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !isOrphanedBy(pod.OwnerReferences, daemonset.UID) {
+			continue
+		}
+		if err := r.client.Delete(context.TODO(), pod); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		log.Info("deleted pod of replaced router daemonset", "namespace", pod.Namespace, "name", pod.Name)
+	}
+	return nil
+}
+
+// isOrphanedBy returns true if the given owner references have no controller
+// other than the given deleted owner, whose reference the garbage collector
+// removes when it orphans the owner's dependents.
+func isOrphanedBy(refs []metav1.OwnerReference, owner types.UID) bool {
+	controllerRef := metav1.GetControllerOf(&metav1.ObjectMeta{OwnerReferences: refs})
+	return controllerRef == nil || controllerRef.UID == owner
+}
+
+// reparentRouterOperands replaces the router workload with the given UID by the
+// workload with the given reference in the owner references of the given
+// ingresscontroller's operands that the workload owns.
+func (r *reconciler) reparentRouterOperands(ci *operatorv1.IngressController, previous types.UID, workloadRef metav1.OwnerReference) error {
+	for _, operand := range r.routerOperands(ci) {
+		obj, name := operand.obj, operand.name
+		if err := r.client.Get(context.TODO(), name, obj); err != nil {
+			if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %T %s: %v", obj, name, err)
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		refs := accessor.GetOwnerReferences()
+		changed := false
+		for i := range refs {
+			if refs[i].UID == previous {
+				refs[i] = workloadRef
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		accessor.SetOwnerReferences(refs)
+		if err := r.client.Update(context.TODO(), obj); err != nil {
+			return fmt.Errorf("failed to update owner of %T %s: %v", obj, name, err)
+		}
+		log.Info("re-parented router operand", "namespace", name.Namespace, "name", name.Name, "owner", workloadRef.Kind)
+	}
+	return nil
+}
+
+// routerOperand is an operand of an ingresscontroller that the router workload
+// owns.
+type routerOperand struct {
+	obj  runtime.Object
+	name types.NamespacedName
+}
+
+// routerOperands returns the operands of the given ingresscontroller that the
+// router workload may own.
+func (r *reconciler) routerOperands(ci *operatorv1.IngressController) []routerOperand {
+	ns := r.Config.OperandNamespace
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Kind:    "ServiceMonitor",
+		Version: "v1",
+	})
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certManagerCertificateGVK)
+	return []routerOperand{
+		{&corev1.Service{}, LoadBalancerServiceName(ci, ns)},
+		{&corev1.Service{}, InternalLoadBalancerServiceName(ci, ns)},
+		{&corev1.Service{}, AlternateLoadBalancerServiceName(ci, ns)},
+		{&corev1.Service{}, NodePortServiceName(ci, ns)},
+		{&corev1.Service{}, InternalIngressControllerServiceName(ci, ns)},
+		{&corev1.Secret{}, RouterStatsSecretName(ci, ns)},
+		{&corev1.ConfigMap{}, RsyslogConfigMapName(ci, ns)},
+		{&networkingv1.NetworkPolicy{}, RouterDeploymentName(ci, ns)},
+		{serviceMonitor, IngressControllerServiceMonitorName(ci, ns)},
+		{certificate, RouterCertManagerCertificateName(ci, ns)},
+	}
+}
+
+// ensureRouterDaemonSet ensures the router daemonset exists for the given
+// ingresscontroller.
+func (r *reconciler) ensureRouterDaemonSet(ci *operatorv1.IngressController, infraConfig *configv1.Infrastructure) (*appsv1.DaemonSet, error) {
+	current, err := r.currentRouterDaemonSet(ci)
+	if err != nil {
+		return nil, err
+	}
+	var currentPodSpec *corev1.PodSpec
+	if current != nil {
+		currentPodSpec = &current.Spec.Template.Spec
+	}
+	deployment, err := r.buildRouterDeployment(ci, infraConfig, true, currentPodSpec)
+	if err != nil {
+		return nil, err
+	}
+	desired := desiredRouterDaemonSet(deployment)
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create router daemonset %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created router daemonset", "namespace", desired.Namespace, "name", desired.Name)
+	} else if changed, updated := daemonSetConfigChanged(current, desired); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return nil, fmt.Errorf("failed to update router daemonset %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		log.Info("updated router daemonset", "namespace", updated.Namespace, "name", updated.Name)
+	}
+	return r.currentRouterDaemonSet(ci)
+}
+
+// desiredRouterDaemonSet returns the desired router daemonset, which runs the
+// pod template of the given desired router deployment.
+func desiredRouterDaemonSet(deployment *appsv1.Deployment) *appsv1.DaemonSet {
+	daemonset := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        deployment.Name,
+			Namespace:   deployment.Namespace,
+			Labels:      deployment.Labels,
+			Annotations: deployment.Annotations,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: deployment.Spec.Selector,
+			Template: *deployment.Spec.Template.DeepCopy(),
+		},
+	}
+
+	// The daemonset controller places at most one pod on each node, so
+	// the anti-affinity rule that spreads the deployment's replicas is
+	// unnecessary.
+	daemonset.Spec.Template.Spec.Affinity = nil
+
+	// Match the deployment's rollout: replace at most 25% of the routers
+	// at a time.
+	maxUnavailable := intstr.FromString("25%")
+	daemonset.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+		Type: appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+
+	return daemonset
+}
+
+// currentRouterDaemonSet returns the current router daemonset, or nil if it
+// does not exist.
+func (r *reconciler) currentRouterDaemonSet(ci *operatorv1.IngressController) (*appsv1.DaemonSet, error) {
+	daemonset := &appsv1.DaemonSet{}
+	if err := r.client.Get(context.TODO(), RouterDeploymentName(ci, r.Config.OperandNamespace), daemonset); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get router daemonset %s: %v", RouterDeploymentName(ci, r.Config.OperandNamespace), err)
+	}
+	return daemonset, nil
+}
+
+// ensureRouterDaemonSetDeleted deletes the router daemonset for the given
+// ingresscontroller if it exists.
+func (r *reconciler) ensureRouterDaemonSetDeleted(ci *operatorv1.IngressController) error {
+	name := RouterDeploymentName(ci, r.Config.OperandNamespace)
+	daemonset := &appsv1.DaemonSet{}
+	daemonset.Name = name.Name
+	daemonset.Namespace = name.Namespace
+	if err := r.client.Delete(context.TODO(), daemonset); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete router daemonset %s: %v", name, err)
+	}
+	log.Info("deleted router daemonset", "namespace", name.Namespace, "name", name.Name)
+	return nil
+}
+
+// daemonSetConfigChanged checks whether the current router daemonset matches
+// the expected daemonset and if not returns the updated daemonset.  The pod
+// templates are compared the same way as the router deployment's.
+func daemonSetConfigChanged(current, expected *appsv1.DaemonSet) (bool, *appsv1.DaemonSet) {
+	asDeployment := func(daemonset *appsv1.DaemonSet) *appsv1.Deployment {
+		one := int32(1)
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &one,
+				Template: daemonset.Spec.Template,
+			},
+		}
+	}
+	currentDeployment, expectedDeployment := asDeployment(current), asDeployment(expected)
+	templateChanged, updatedDeployment := deploymentConfigChanged(currentDeployment, expectedDeployment)
+	if !templateChanged && metadataContains(current.ObjectMeta, expected.ObjectMeta) && cmp.Equal(current.Spec.UpdateStrategy, expected.Spec.UpdateStrategy, cmpopts.EquateEmpty()) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updateMetadata(&updated.ObjectMeta, expected.ObjectMeta)
+	updated.Spec.UpdateStrategy = expected.Spec.UpdateStrategy
+	if templateChanged {
+		updated.Spec.Template = updatedDeployment.Spec.Template
+		if routerSelectorsChanged(currentDeployment, updatedDeployment) {
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			updated.Annotations[SelectorsUpdatedGenerationAnnotation] = strconv.FormatInt(current.Generation+1, 10)
+		}
+	}
+	return true, updated
+}
+
+// daemonSetAsDeployment returns a deployment with the metadata, selector, and
+// rollout status of the given router daemonset so that the ingresscontroller
+// status can be computed the same way for either kind of workload.  The
+// desired number of scheduled pods stands in for the deployment's replicas.
+func daemonSetAsDeployment(daemonset *appsv1.DaemonSet) *appsv1.Deployment {
+	replicas := daemonset.Status.DesiredNumberScheduled
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: daemonSetKind},
+		ObjectMeta: daemonset.ObjectMeta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: daemonset.Spec.Selector,
+			Template: daemonset.Spec.Template,
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: daemonset.Status.ObservedGeneration,
+			Replicas:           daemonset.Status.CurrentNumberScheduled,
+			UpdatedReplicas:    daemonset.Status.UpdatedNumberScheduled,
+			ReadyReplicas:      daemonset.Status.NumberReady,
+			AvailableReplicas:  daemonset.Status.NumberAvailable,
+		},
+	}
+}
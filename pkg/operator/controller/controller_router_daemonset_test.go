@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestRouterUsesDaemonSet(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotation  *string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		expect      bool
+		expectError bool
+	}{
+		{
+			description: "no annotation",
+			strategy:    operatorv1.HostNetworkStrategyType,
+		},
+		{
+			description: "deployment",
+			annotation:  pointerToString("Deployment"),
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+		},
+		{
+			description: "daemonset with HostNetwork",
+			annotation:  pointerToString("DaemonSet"),
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expect:      true,
+		},
+		{
+			description: "daemonset with LoadBalancerService",
+			annotation:  pointerToString("DaemonSet"),
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectError: true,
+		},
+		{
+			description: "unknown kind",
+			annotation:  pointerToString("StatefulSet"),
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+			},
+		}
+		if tc.annotation != nil {
+			ic.Annotations = map[string]string{RouterWorkloadKindAnnotation: *tc.annotation}
+		}
+		actual, err := routerUsesDaemonSet(ic)
+		checkError(t, tc.description, tc.expectError, err)
+		if actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func pointerToString(s string) *string { return &s }
+
+func TestDesiredRouterDaemonSet(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{RouterWorkloadKindAnnotation: "DaemonSet"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.HostNetworkStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.BareMetalPlatformType,
+		},
+	}
+	deployment, err := desiredRouterDeployment(ic, DefaultOperandNamespace, "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router deployment: %v", err)
+	}
+	daemonset := desiredRouterDaemonSet(deployment)
+
+	if daemonset.Name != deployment.Name || daemonset.Namespace != deployment.Namespace {
+		t.Errorf("expected daemonset %s/%s, got %s/%s", deployment.Namespace, deployment.Name, daemonset.Namespace, daemonset.Name)
+	}
+	if daemonset.Labels[manifests.OwningIngressControllerLabel] != ic.Name {
+		t.Errorf("expected daemonset to be labelled with ingresscontroller %q, got %v", ic.Name, daemonset.Labels)
+	}
+	if !daemonset.Spec.Template.Spec.HostNetwork {
+		t.Error("expected daemonset pods to use the host network")
+	}
+	if daemonset.Spec.Template.Spec.Affinity != nil {
+		t.Errorf("expected no affinity, got %v", daemonset.Spec.Template.Spec.Affinity)
+	}
+	if deployment.Spec.Template.Spec.Affinity == nil {
+		t.Error("expected the desired deployment's affinity to be left as is")
+	}
+
+	// The desired daemonset must be a fixed point of daemonSetConfigChanged.
+	if changed, _ := daemonSetConfigChanged(daemonset, daemonset.DeepCopy()); changed {
+		t.Error("expected daemonSetConfigChanged to return false for the desired daemonset")
+	}
+
+	mutated := daemonset.DeepCopy()
+	mutated.Spec.Template.Spec.Containers[0].Image = "quay.io/openshift/router:old"
+	changed, updated := daemonSetConfigChanged(mutated, daemonset)
+	if !changed {
+		t.Fatal("expected daemonSetConfigChanged to detect the image change")
+	}
+	if updated.Spec.Template.Spec.Containers[0].Image != "quay.io/openshift/router:latest" {
+		t.Errorf("expected updated daemonset to have the desired image, got %q", updated.Spec.Template.Spec.Containers[0].Image)
+	}
+	if changed, _ := daemonSetConfigChanged(updated, daemonset); changed {
+		t.Error("expected daemonSetConfigChanged to return false for the updated daemonset")
+	}
+}
+
+func TestDaemonSetAsDeploymentConditions(t *testing.T) {
+	daemonset := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     2,
+			DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3,
+			UpdatedNumberScheduled: 2,
+			NumberAvailable:        2,
+		},
+	}
+	deployment := daemonSetAsDeployment(daemonset)
+	if c := computeDeploymentRollingOutCondition(deployment); c.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected DeploymentRollingOut to be True while 2 of 3 pods are updated, got %v", c)
+	}
+
+	// A daemonset that matches no nodes is unavailable, not dormant.
+	daemonset.Status = appsv1.DaemonSetStatus{ObservedGeneration: 2}
+	deployment = daemonSetAsDeployment(daemonset)
+	conditions := computeIngressStatusConditions(nil, deployment)
+	if c := findIngressStatusCondition(conditions, operatorv1.IngressControllerAvailableConditionType); c == nil || c.Status != operatorv1.ConditionFalse || c.Reason == ZeroReplicasReason {
+		t.Errorf("expected Available to be False and not %s, got %v", ZeroReplicasReason, c)
+	}
+	if c := computeIngressDegradedCondition(deployment); c.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected Degraded to be True, got %v", c)
+	}
+	if c := computeDeploymentRollingOutCondition(deployment); c.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected DeploymentRollingOut to be False, got %v", c)
+	}
+}
+
+// TestIsOrphanedBy verifies that the dependents of a replaced router workload
+// are recognized whether or not the garbage collector has already removed the
+// workload's owner reference.
+func TestIsOrphanedBy(t *testing.T) {
+	trueVar := true
+	ref := func(uid types.UID, controller bool) metav1.OwnerReference {
+		r := metav1.OwnerReference{UID: uid}
+		if controller {
+			r.Controller = &trueVar
+		}
+		return r
+	}
+	testCases := []struct {
+		description string
+		refs        []metav1.OwnerReference
+		expect      bool
+	}{
+		{"no owner", nil, true},
+		{"controlled by the replaced workload", []metav1.OwnerReference{ref("old", true)}, true},
+		{"controlled by another workload", []metav1.OwnerReference{ref("new", true)}, false},
+		{"owned but not controlled", []metav1.OwnerReference{ref("new", false)}, true},
+	}
+	for _, tc := range testCases {
+		if actual := isOrphanedBy(tc.refs, "old"); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -24,11 +25,15 @@ import (
 // ensureRouterDeployment ensures the router deployment exists for a given
 // ingresscontroller.
 func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, infraConfig *configv1.Infrastructure) (*appsv1.Deployment, error) {
-	desired, err := desiredRouterDeployment(ci, r.Config.IngressControllerImage, infraConfig)
+	current, err := r.currentRouterDeployment(ci)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build router deployment: %v", err)
+		return nil, err
 	}
-	current, err := r.currentRouterDeployment(ci)
+	var currentPodSpec *corev1.PodSpec
+	if current != nil {
+		currentPodSpec = &current.Spec.Template.Spec
+	}
+	desired, err := r.buildRouterDeployment(ci, infraConfig, false, currentPodSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +54,7 @@ func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, in
 // ingresscontroller are deleted.
 func (r *reconciler) ensureRouterDeleted(ci *operatorv1.IngressController) error {
 	deployment := &appsv1.Deployment{}
-	name := RouterDeploymentName(ci)
+	name := RouterDeploymentName(ci, r.Config.OperandNamespace)
 	deployment.Name = name.Name
 	deployment.Namespace = name.Namespace
 	if err := r.client.Delete(context.TODO(), deployment); err != nil {
@@ -60,10 +65,57 @@ func (r *reconciler) ensureRouterDeleted(ci *operatorv1.IngressController) error
 	return nil
 }
 
+// buildRouterDeployment returns the router deployment that the reconciler
+// applies for the given ingresscontroller, or, if useDaemonSet is true, the
+// deployment whose pod template the router daemonset runs, in which case the
+// replicas are left alone.  The router image policy is applied given the
+// current pod spec of the router workload, which is nil if it does not exist
+// yet.  Both the reconciler and DesiredOperands build the router workload with
+// this function so that they render it the same way.
+func (r *reconciler) buildRouterDeployment(ci *operatorv1.IngressController, infraConfig *configv1.Infrastructure, useDaemonSet bool, currentPodSpec *corev1.PodSpec) (*appsv1.Deployment, error) {
+	workload := "router deployment"
+	if useDaemonSet {
+		workload = "router daemonset"
+	}
+	deployment, err := desiredRouterDeployment(ci, r.Config.OperandNamespace, r.Config.IngressControllerImage, infraConfig)
+	if err != nil {
+		return nil, newTerminalError(fmt.Errorf("failed to build %s: %v", workload, err))
+	}
+	if !useDaemonSet {
+		if err := r.setReplicasFromNodes(ci, deployment); err != nil {
+			return nil, fmt.Errorf("failed to compute router replicas from nodes: %v", err)
+		}
+		if err := r.setZoneAwareReplicas(ci, deployment); err != nil {
+			return nil, fmt.Errorf("failed to compute router replicas from zones: %v", err)
+		}
+	}
+	r.setRouterExternalCertificate(deployment)
+	if err := r.setRouterProxy(deployment); err != nil {
+		return nil, fmt.Errorf("failed to configure proxy for %s: %v", workload, err)
+	}
+	if err := r.setRouterTLS(ci, deployment); err != nil {
+		return nil, fmt.Errorf("failed to configure TLS for %s: %v", workload, err)
+	}
+	if err := r.setDefaultDestinationCA(deployment); err != nil {
+		return nil, fmt.Errorf("failed to configure default destination CA for %s: %v", workload, err)
+	}
+	if err := r.setMountedContentHash(deployment); err != nil {
+		return nil, fmt.Errorf("failed to compute mounted content hash for %s: %v", workload, err)
+	}
+	signatures, err := r.routerImageSignatures(ci)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get router image signatures for %s: %v", workload, err)
+	}
+	if err := applyRouterImagePolicy(ci, r.Config.IngressControllerImage, &deployment.Spec.Template.Spec, currentPodSpec, signatures); err != nil {
+		return nil, newTerminalError(fmt.Errorf("failed to apply router image policy to %s: %v", workload, err))
+	}
+	return deployment, nil
+}
+
 // desiredRouterDeployment returns the desired router deployment.
-func desiredRouterDeployment(ci *operatorv1.IngressController, ingressControllerImage string, infraConfig *configv1.Infrastructure) (*appsv1.Deployment, error) {
+func desiredRouterDeployment(ci *operatorv1.IngressController, operandNamespace, ingressControllerImage string, infraConfig *configv1.Infrastructure) (*appsv1.Deployment, error) {
 	deployment := manifests.RouterDeployment()
-	name := RouterDeploymentName(ci)
+	name := RouterDeploymentName(ci, operandNamespace)
 	deployment.Name = name.Name
 	deployment.Namespace = name.Namespace
 
@@ -76,6 +128,10 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	deployment.Spec.Selector = IngressControllerDeploymentPodSelector(ci)
 	deployment.Spec.Template.Labels = deployment.Spec.Selector.MatchLabels
 
+	// Run the routers as the ingresscontroller's own service account so
+	// that each shard can be granted different permissions.
+	deployment.Spec.Template.Spec.ServiceAccountName = RouterServiceAccountName(ci, operandNamespace).Name
+
 	// Prevent colocation of controller pods to enable simple horizontal scaling
 	deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{
 		PodAntiAffinity: &corev1.PodAntiAffinity{
@@ -110,26 +166,10 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		},
 	}
 
-	statsSecretName := fmt.Sprintf("router-stats-%s", ci.Name)
 	env := []corev1.EnvVar{
 		{Name: "ROUTER_SERVICE_NAME", Value: ci.Name},
-		{Name: "STATS_USERNAME", ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: statsSecretName,
-				},
-				Key: "statsUsername",
-			},
-		}},
-		{Name: "STATS_PASSWORD", ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: statsSecretName,
-				},
-				Key: "statsPassword",
-			},
-		}},
 	}
+	env = append(env, desiredRouterStatsEnv(ci, operandNamespace)...)
 
 	// Enable prometheus metrics
 	certsSecretName := fmt.Sprintf("router-metrics-certs-%s", ci.Name)
@@ -159,6 +199,9 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 
 	if len(ci.Status.Domain) > 0 {
 		env = append(env, corev1.EnvVar{Name: "ROUTER_CANONICAL_HOSTNAME", Value: ci.Status.Domain})
+		// Routes that specify spec.subdomain instead of spec.host are
+		// exposed under the domain of each shard that admits them.
+		env = append(env, corev1.EnvVar{Name: "ROUTER_DOMAIN", Value: ci.Status.Domain})
 	}
 
 	if ci.Status.EndpointPublishingStrategy.Type == operatorv1.LoadBalancerServiceStrategyType {
@@ -169,25 +212,43 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		}
 	}
 
-	env = append(env, corev1.EnvVar{Name: "ROUTER_THREADS", Value: "4"})
+	env = append(env, corev1.EnvVar{Name: "ROUTER_THREADS", Value: strconv.Itoa(routerThreads)})
 
-	nodeSelector := map[string]string{
-		"beta.kubernetes.io/os":          "linux",
-		"node-role.kubernetes.io/worker": "",
+	ingressEnv, err := desiredIngressProcessingEnv(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid ingress processing configuration: %v", ci.Name, err)
 	}
-	if ci.Spec.NodePlacement != nil {
-		if ci.Spec.NodePlacement.NodeSelector != nil {
-			var err error
-			nodeSelector, err = metav1.LabelSelectorAsMap(ci.Spec.NodePlacement.NodeSelector)
-			if err != nil {
-				return nil, fmt.Errorf("ingresscontroller %q has invalid spec.nodePlacement.nodeSelector: %v",
-					ci.Name, err)
-			}
-		}
-		if ci.Spec.NodePlacement.Tolerations != nil {
-			deployment.Spec.Template.Spec.Tolerations = ci.Spec.NodePlacement.Tolerations
-		}
+	env = append(env, ingressEnv...)
+
+	emptyRequestsEnv, err := desiredEmptyRequestsEnv(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid empty requests configuration: %v", ci.Name, err)
+	}
+	env = append(env, emptyRequestsEnv...)
+
+	connectionLimitsEnv, err := desiredConnectionLimitsEnv(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid connection limits configuration: %v", ci.Name, err)
 	}
+	env = append(env, connectionLimitsEnv...)
+
+	dynamicConfigEnv, err := desiredDynamicConfigManagerEnv(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid dynamic configuration manager configuration: %v", ci.Name, err)
+	}
+	env = append(env, dynamicConfigEnv...)
+
+	profilingEnv, err := desiredRouterProfilingEnv(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid profiling configuration: %v", ci.Name, err)
+	}
+	env = append(env, profilingEnv...)
+
+	nodeSelector, err := routerNodeSelector(ci)
+	if err != nil {
+		return nil, err
+	}
+	deployment.Spec.Template.Spec.Tolerations = routerTolerations(ci, nodeSelector)
 	deployment.Spec.Template.Spec.NodeSelector = nodeSelector
 
 	if ci.Spec.NamespaceSelector != nil {
@@ -218,9 +279,20 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	}
 
 	deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, env...)
+	for i := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if deployment.Spec.Template.Spec.Containers[0].Env[i].Name == "ROUTER_SERVICE_NAMESPACE" {
+			deployment.Spec.Template.Spec.Containers[0].Env[i].Value = operandNamespace
+		}
+	}
 
 	deployment.Spec.Template.Spec.Containers[0].Image = ingressControllerImage
 
+	accessLogging, err := accessLoggingFor(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid access logging configuration: %v", ci.Name, err)
+	}
+	configureAccessLogging(deployment, ci, accessLogging, ingressControllerImage)
+
 	if ci.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType {
 		// Expose ports 80 and 443 on the host to provide endpoints for
 		// the user's HA solution.
@@ -234,6 +306,44 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		deployment.Spec.Template.Spec.Containers[0].ReadinessProbe.Handler.HTTPGet.Host = "localhost"
 	}
 
+	useHostNetworkSCC, err := routerUsesHostNetworkSCC(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid security configuration: %v", ci.Name, err)
+	}
+	configureRouterSecurityContext(deployment, useHostNetworkSCC)
+
+	dnsPolicy, dnsConfig, err := desiredRouterDNS(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid DNS configuration: %v", ci.Name, err)
+	}
+	deployment.Spec.Template.Spec.DNSPolicy = dnsPolicy
+	deployment.Spec.Template.Spec.DNSConfig = dnsConfig
+
+	priorityClassName, err := routerPriorityClassFor(ci)
+	if err != nil {
+		return nil, err
+	}
+	deployment.Spec.Template.Spec.PriorityClassName = priorityClassName
+
+	if err := configureRouterProbes(ci, &deployment.Spec.Template.Spec.Containers[0]); err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid probe configuration: %v", ci.Name, err)
+	}
+
+	if err := configureRouterShutdownDelay(ci, deployment); err != nil {
+		return nil, err
+	}
+
+	if err := configureRouterZoneSpread(ci, deployment); err != nil {
+		return nil, err
+	}
+
+	operandLabels, operandAnnotations, err := operandMetadata(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid operand metadata: %v", ci.Name, err)
+	}
+	addOperandMetadata(&deployment.ObjectMeta, operandLabels, operandAnnotations)
+	addOperandMetadata(&deployment.Spec.Template.ObjectMeta, operandLabels, operandAnnotations)
+
 	// Fill in the default certificate secret name.
 	secretName := RouterEffectiveDefaultCertificateSecretName(ci, deployment.Namespace)
 	deployment.Spec.Template.Spec.Volumes[0].Secret.SecretName = secretName.Name
@@ -241,10 +351,83 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	return deployment, nil
 }
 
+// routerNodeSelector returns the node selector for the routers of the given
+// ingresscontroller.
+func routerNodeSelector(ci *operatorv1.IngressController) (map[string]string, error) {
+	nodeSelector := map[string]string{
+		"beta.kubernetes.io/os":          "linux",
+		"node-role.kubernetes.io/worker": "",
+	}
+	if ci.Spec.NodePlacement != nil && ci.Spec.NodePlacement.NodeSelector != nil {
+		var err error
+		nodeSelector, err = metav1.LabelSelectorAsMap(ci.Spec.NodePlacement.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("ingresscontroller %q has invalid spec.nodePlacement.nodeSelector: %v",
+				ci.Name, err)
+		}
+	}
+	// The default node selector does not constrain the architecture, so
+	// that routers can run on the workers of any architecture, unless the
+	// ingresscontroller requests one.
+	if err := applyNodeArchitecture(ci, nodeSelector); err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid node placement: %v", ci.Name, err)
+	}
+	return nodeSelector, nil
+}
+
+// infraNodeRoleLabel is the node role label of infra nodes.
+const infraNodeRoleLabel = "node-role.kubernetes.io/infra"
+
+var (
+	// infraTaints are the taints that are recommended for infra nodes.
+	infraTaints = []corev1.Taint{
+		{Key: infraNodeRoleLabel, Value: "reserved", Effect: corev1.TaintEffectNoSchedule},
+		{Key: infraNodeRoleLabel, Value: "reserved", Effect: corev1.TaintEffectNoExecute},
+	}
+
+	// infraToleration tolerates any taint with the infra node role key.
+	infraToleration = corev1.Toleration{
+		Key:      infraNodeRoleLabel,
+		Operator: corev1.TolerationOpExists,
+	}
+)
+
+// routerTolerations returns the tolerations for the routers of the given
+// ingresscontroller, which have the given node selector.  Infra nodes are
+// usually tainted so that only infrastructure components run on them, so if
+// the node selector selects infra nodes, the routers tolerate the standard
+// infra taints unless the ingresscontroller's tolerations already do.
+func routerTolerations(ci *operatorv1.IngressController, nodeSelector map[string]string) []corev1.Toleration {
+	var tolerations []corev1.Toleration
+	if ci.Spec.NodePlacement != nil && ci.Spec.NodePlacement.Tolerations != nil {
+		tolerations = ci.Spec.NodePlacement.Tolerations
+	}
+	if _, ok := nodeSelector[infraNodeRoleLabel]; !ok {
+		return tolerations
+	}
+	for i := range infraTaints {
+		if !toleratesTaint(tolerations, &infraTaints[i]) {
+			return append(append([]corev1.Toleration{}, tolerations...), infraToleration)
+		}
+	}
+	return tolerations
+}
+
+// toleratesTaint returns true if any of the given tolerations tolerates the
+// given taint.
+func toleratesTaint(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
 // currentRouterDeployment returns the current router deployment.
 func (r *reconciler) currentRouterDeployment(ci *operatorv1.IngressController) (*appsv1.Deployment, error) {
 	deployment := &appsv1.Deployment{}
-	if err := r.client.Get(context.TODO(), RouterDeploymentName(ci), deployment); err != nil {
+	if err := r.client.Get(context.TODO(), RouterDeploymentName(ci, r.Config.OperandNamespace), deployment); err != nil {
 		if errors.IsNotFound(err) {
 			return nil, nil
 		}
@@ -268,6 +451,15 @@ func (r *reconciler) updateRouterDeployment(current, desired *appsv1.Deployment)
 	if !changed {
 		return nil
 	}
+	if routerSelectorsChanged(current, updated) {
+		// Updating the spec increments the generation.  Record the
+		// new generation so that the DeploymentRollingOut condition
+		// can report that the rollout is due to the selector change.
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[SelectorsUpdatedGenerationAnnotation] = strconv.FormatInt(current.Generation+1, 10)
+	}
 
 	if err := r.client.Update(context.TODO(), updated); err != nil {
 		return fmt.Errorf("failed to update router deployment %s/%s: %v", updated.Namespace, updated.Name, err)
@@ -276,13 +468,44 @@ func (r *reconciler) updateRouterDeployment(current, desired *appsv1.Deployment)
 	return nil
 }
 
+// routerSelectorsChanged returns true if the route or namespace selector of
+// the router in the given deployments differs.
+func routerSelectorsChanged(current, updated *appsv1.Deployment) bool {
+	valueOf := func(deployment *appsv1.Deployment, name string) string {
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+		return ""
+	}
+	for _, name := range []string{"ROUTE_LABELS", "NAMESPACE_LABELS"} {
+		if valueOf(current, name) != valueOf(updated, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // deploymentConfigChanged checks if current config matches the expected config
 // for the ingress controller deployment and if not returns the updated config.
 func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv1.Deployment) {
-	if cmp.Equal(current.Spec.Template.Spec.Volumes, expected.Spec.Template.Spec.Volumes, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpVolumes), cmp.Comparer(cmpSecretVolumeSource)) &&
+	if cmp.Equal(current.Spec.Template.Spec.Volumes, expected.Spec.Template.Spec.Volumes, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpVolumes), cmp.Comparer(cmpSecretVolumeSource), cmp.Comparer(cmpConfigMapVolumeSource)) &&
 		cmp.Equal(current.Spec.Template.Spec.NodeSelector, expected.Spec.Template.Spec.NodeSelector, cmpopts.EquateEmpty()) &&
+		current.Spec.Template.Spec.DNSPolicy == expected.Spec.Template.Spec.DNSPolicy &&
+		current.Spec.Template.Spec.ServiceAccountName == expected.Spec.Template.Spec.ServiceAccountName &&
+		current.Spec.Template.Spec.PriorityClassName == expected.Spec.Template.Spec.PriorityClassName &&
+		cmp.Equal(current.Spec.Template.Spec.DNSConfig, expected.Spec.Template.Spec.DNSConfig, cmpopts.EquateEmpty()) &&
 		cmp.Equal(current.Spec.Template.Spec.Containers[0].Env, expected.Spec.Template.Spec.Containers[0].Env, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpEnvs)) &&
+		cmp.Equal(current.Spec.Template.Spec.Containers[0].VolumeMounts, expected.Spec.Template.Spec.Containers[0].VolumeMounts, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpVolumeMounts)) &&
+		sidecarsEqual(current.Spec.Template.Spec.Containers[1:], expected.Spec.Template.Spec.Containers[1:]) &&
 		current.Spec.Template.Spec.Containers[0].Image == expected.Spec.Template.Spec.Containers[0].Image &&
+		current.Spec.Template.Annotations[MountedContentHashAnnotation] == expected.Spec.Template.Annotations[MountedContentHashAnnotation] &&
+		metadataContains(current.ObjectMeta, expected.ObjectMeta) &&
+		metadataContains(current.Spec.Template.ObjectMeta, expected.Spec.Template.ObjectMeta) &&
+		!routerProbesChanged(current, expected) &&
+		!routerShutdownDelayChanged(current, expected) &&
+		!routerSecurityContextChanged(current, expected) &&
 		cmp.Equal(current.Spec.Template.Spec.Tolerations, expected.Spec.Template.Spec.Tolerations, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpTolerations)) &&
 		cmp.Equal(current.Spec.Template.Spec.Affinity, expected.Spec.Template.Spec.Affinity, cmpopts.EquateEmpty()) &&
 		cmp.Equal(current.Spec.Strategy, expected.Spec.Strategy, cmpopts.EquateEmpty()) &&
@@ -292,6 +515,8 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	}
 
 	updated := current.DeepCopy()
+	updateMetadata(&updated.ObjectMeta, expected.ObjectMeta)
+	updateMetadata(&updated.Spec.Template.ObjectMeta, expected.Spec.Template.ObjectMeta)
 	updated.Spec.Strategy = expected.Spec.Strategy
 	volumes := make([]corev1.Volume, len(expected.Spec.Template.Spec.Volumes))
 	for i, vol := range expected.Spec.Template.Spec.Volumes {
@@ -299,10 +524,37 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	}
 	updated.Spec.Template.Spec.Volumes = volumes
 	updated.Spec.Template.Spec.NodeSelector = expected.Spec.Template.Spec.NodeSelector
+	updated.Spec.Template.Spec.DNSPolicy = expected.Spec.Template.Spec.DNSPolicy
+	updated.Spec.Template.Spec.ServiceAccountName = expected.Spec.Template.Spec.ServiceAccountName
+	// Keep the deprecated field, which the API server sets from
+	// serviceAccountName, from referring to the old service account.
+	updated.Spec.Template.Spec.DeprecatedServiceAccount = expected.Spec.Template.Spec.ServiceAccountName
+	updated.Spec.Template.Spec.DNSConfig = expected.Spec.Template.Spec.DNSConfig
+	updated.Spec.Template.Spec.PriorityClassName = expected.Spec.Template.Spec.PriorityClassName
 	updated.Spec.Template.Spec.Containers[0].Env = expected.Spec.Template.Spec.Containers[0].Env
+	updated.Spec.Template.Spec.Containers[0].VolumeMounts = expected.Spec.Template.Spec.Containers[0].VolumeMounts
+	containers := []corev1.Container{updated.Spec.Template.Spec.Containers[0]}
+	for _, c := range expected.Spec.Template.Spec.Containers[1:] {
+		containers = append(containers, *c.DeepCopy())
+	}
+	updated.Spec.Template.Spec.Containers = containers
 	updated.Spec.Template.Spec.Containers[0].Image = expected.Spec.Template.Spec.Containers[0].Image
+	updated.Spec.Template.Spec.Containers[0].LivenessProbe = expected.Spec.Template.Spec.Containers[0].LivenessProbe
+	updated.Spec.Template.Spec.Containers[0].ReadinessProbe = expected.Spec.Template.Spec.Containers[0].ReadinessProbe
+	updated.Spec.Template.Spec.Containers[0].Lifecycle = expected.Spec.Template.Spec.Containers[0].Lifecycle
+	updated.Spec.Template.Spec.TerminationGracePeriodSeconds = expected.Spec.Template.Spec.TerminationGracePeriodSeconds
+	updated.Spec.Template.Spec.SecurityContext = expected.Spec.Template.Spec.SecurityContext
+	updated.Spec.Template.Spec.Containers[0].SecurityContext = expected.Spec.Template.Spec.Containers[0].SecurityContext
 	updated.Spec.Template.Spec.Tolerations = expected.Spec.Template.Spec.Tolerations
 	updated.Spec.Template.Spec.Affinity = expected.Spec.Template.Spec.Affinity
+	if hash, ok := expected.Spec.Template.Annotations[MountedContentHashAnnotation]; ok {
+		if updated.Spec.Template.Annotations == nil {
+			updated.Spec.Template.Annotations = map[string]string{}
+		}
+		updated.Spec.Template.Annotations[MountedContentHashAnnotation] = hash
+	} else {
+		delete(updated.Spec.Template.Annotations, MountedContentHashAnnotation)
+	}
 	replicas := int32(1)
 	if expected.Spec.Replicas != nil {
 		replicas = *expected.Spec.Replicas
@@ -311,8 +563,30 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	return true, updated
 }
 
-func cmpEnvs(a, b corev1.EnvVar) bool    { return a.Name < b.Name }
-func cmpVolumes(a, b corev1.Volume) bool { return a.Name < b.Name }
+func cmpEnvs(a, b corev1.EnvVar) bool              { return a.Name < b.Name }
+func cmpVolumes(a, b corev1.Volume) bool           { return a.Name < b.Name }
+func cmpVolumeMounts(a, b corev1.VolumeMount) bool { return a.Name < b.Name }
+
+// sidecarsEqual compares the sidecar containers of two router deployments,
+// ignoring fields that the operator does not set.
+func sidecarsEqual(a, b []corev1.Container) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name ||
+			a[i].Image != b[i].Image ||
+			!cmp.Equal(a[i].Command, b[i].Command, cmpopts.EquateEmpty()) ||
+			!cmp.Equal(a[i].Args, b[i].Args, cmpopts.EquateEmpty()) ||
+			!cmp.Equal(a[i].Env, b[i].Env, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpEnvs)) ||
+			!cmp.Equal(a[i].VolumeMounts, b[i].VolumeMounts, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpVolumeMounts)) ||
+			!cmp.Equal(a[i].Resources, b[i].Resources, cmpopts.EquateEmpty()) {
+			return false
+		}
+	}
+	return true
+}
+
 func cmpSecretVolumeSource(a, b corev1.SecretVolumeSource) bool {
 	if a.SecretName != b.SecretName {
 		return false
@@ -337,6 +611,30 @@ func cmpSecretVolumeSource(a, b corev1.SecretVolumeSource) bool {
 	return true
 }
 
+func cmpConfigMapVolumeSource(a, b corev1.ConfigMapVolumeSource) bool {
+	if a.Name != b.Name {
+		return false
+	}
+	if !cmp.Equal(a.Items, b.Items, cmpopts.EquateEmpty()) {
+		return false
+	}
+	aDefaultMode := int32(420)
+	if a.DefaultMode != nil {
+		aDefaultMode = *a.DefaultMode
+	}
+	bDefaultMode := int32(420)
+	if b.DefaultMode != nil {
+		bDefaultMode = *b.DefaultMode
+	}
+	if aDefaultMode != bDefaultMode {
+		return false
+	}
+	if !cmp.Equal(a.Optional, b.Optional, cmpopts.EquateEmpty()) {
+		return false
+	}
+	return true
+}
+
 func cmpTolerations(a, b corev1.Toleration) bool {
 	if a.Key != b.Key {
 		return false
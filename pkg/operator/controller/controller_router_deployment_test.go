@@ -56,11 +56,19 @@ func TestDesiredRouterDeployment(t *testing.T) {
 		},
 	}
 
-	deployment, err := desiredRouterDeployment(ci, ingressControllerImage, infraConfig)
+	deployment, err := desiredRouterDeployment(ci, DefaultOperandNamespace, ingressControllerImage, infraConfig)
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
 
+	if deployment.Spec.Template.Spec.ServiceAccountName != "router-default" {
+		t.Errorf("router Deployment has unexpected service account: %q", deployment.Spec.Template.Spec.ServiceAccountName)
+	}
+
+	if deployment.Spec.Template.Spec.PriorityClassName != "system-cluster-critical" {
+		t.Errorf("router Deployment has unexpected priority class: %q", deployment.Spec.Template.Spec.PriorityClassName)
+	}
+
 	namespaceSelector := ""
 	for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
 		if envVar.Name == "NAMESPACE_LABELS" {
@@ -99,6 +107,11 @@ func TestDesiredRouterDeployment(t *testing.T) {
 	if len(deployment.Spec.Template.Spec.NodeSelector) == 0 {
 		t.Error("router Deployment has no default node selector")
 	}
+	for _, label := range []string{"kubernetes.io/arch", "beta.kubernetes.io/arch"} {
+		if _, ok := deployment.Spec.Template.Spec.NodeSelector[label]; ok {
+			t.Errorf("router Deployment's default node selector constrains the architecture: %v", deployment.Spec.Template.Spec.NodeSelector)
+		}
+	}
 	if len(deployment.Spec.Template.Spec.Tolerations) != 0 {
 		t.Errorf("router Deployment has unexpected toleration: %#v",
 			deployment.Spec.Template.Spec.Tolerations)
@@ -151,7 +164,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 
 	ci.Status.Domain = "example.com"
 	ci.Status.EndpointPublishingStrategy.Type = operatorv1.LoadBalancerServiceStrategyType
-	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, infraConfig)
+	deployment, err = desiredRouterDeployment(ci, DefaultOperandNamespace, ingressControllerImage, infraConfig)
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -191,6 +204,17 @@ func TestDesiredRouterDeployment(t *testing.T) {
 		t.Errorf("router Deployment has unexpected canonical hostname: %q, expected %q", canonicalHostname, ci.Status.Domain)
 	}
 
+	routerDomain := ""
+	for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if envVar.Name == "ROUTER_DOMAIN" {
+			routerDomain = envVar.Value
+			break
+		}
+	}
+	if routerDomain != ci.Status.Domain {
+		t.Errorf("router Deployment has unexpected router domain: %q, expected %q", routerDomain, ci.Status.Domain)
+	}
+
 	secretName := fmt.Sprintf("secret-%v", time.Now().UnixNano())
 	ci.Spec.DefaultCertificate = &corev1.LocalObjectReference{
 		Name: secretName,
@@ -206,7 +230,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 	var expectedReplicas int32 = 3
 	ci.Spec.Replicas = &expectedReplicas
 	ci.Status.EndpointPublishingStrategy.Type = operatorv1.HostNetworkStrategyType
-	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, infraConfig)
+	deployment, err = desiredRouterDeployment(ci, DefaultOperandNamespace, ingressControllerImage, infraConfig)
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -297,6 +321,34 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: false,
 		},
+		{
+			description: "if the rsyslog-config default mode value is omitted",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Volumes[2].ConfigMap.DefaultMode = nil
+			},
+			expect: false,
+		},
+		{
+			description: "if the router container's security context changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{}
+			},
+			expect: true,
+		},
+		{
+			description: "if .spec.template.spec.securityContext is defaulted",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+			},
+			expect: false,
+		},
+		{
+			description: "if .spec.template.spec.serviceAccountName changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.ServiceAccountName = "router"
+			},
+			expect: true,
+		},
 		{
 			description: "if .spec.template.spec.nodeSelector changes",
 			mutate: func(deployment *appsv1.Deployment) {
@@ -394,6 +446,107 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if the liveness probe's initial delay changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds = 300
+			},
+			expect: true,
+		},
+		{
+			description: "if the readiness probe's defaulted fields are filled in",
+			mutate: func(deployment *appsv1.Deployment) {
+				probe := deployment.Spec.Template.Spec.Containers[0].ReadinessProbe
+				probe.TimeoutSeconds = 1
+				probe.PeriodSeconds = 10
+				probe.SuccessThreshold = 1
+				probe.FailureThreshold = 3
+				probe.Handler.HTTPGet.Scheme = corev1.URISchemeHTTP
+			},
+			expect: false,
+		},
+		{
+			description: "if the mounted content hash is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Annotations = map[string]string{MountedContentHashAnnotation: "1"}
+			},
+			expect: true,
+		},
+		{
+			description: "if an operand pod template annotation is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Annotations = map[string]string{"foo": "bar"}
+			},
+			expect: true,
+		},
+		{
+			description: "if an operand deployment label is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Labels = map[string]string{"cost-center": "1234"}
+			},
+			expect: true,
+		},
+		{
+			description: "if the DNS policy changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+			},
+			expect: true,
+		},
+		{
+			description: "if the priority class changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.PriorityClassName = "system-node-critical"
+			},
+			expect: true,
+		},
+		{
+			description: "if the shutdown delay is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				grace := int64(75)
+				deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = &grace
+				deployment.Spec.Template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{
+					PreStop: &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"sleep", "45"}}},
+				}
+			},
+			expect: true,
+		},
+		{
+			description: "if the termination grace period is set to the default",
+			mutate: func(deployment *appsv1.Deployment) {
+				grace := int64(30)
+				deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = &grace
+			},
+			expect: false,
+		},
+		{
+			description: "if the DNS config is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.DNSConfig = &corev1.PodDNSConfig{Searches: []string{"example.com"}}
+			},
+			expect: true,
+		},
+		{
+			description: "if a sidecar container is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, corev1.Container{
+					Name:    "logs",
+					Image:   "openshift/origin-cluster-ingress-operator:v4.0",
+					Command: []string{"/sbin/rsyslogd"},
+				})
+			},
+			expect: true,
+		},
+		{
+			description: "if a router container volume mount is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].VolumeMounts = append(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+					Name:      "rsyslog-socket",
+					MountPath: "/var/lib/rsyslog",
+				})
+			},
+			expect: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -415,6 +568,7 @@ func TestDeploymentConfigChanged(t *testing.T) {
 				},
 				Template: corev1.PodTemplateSpec{
 					Spec: corev1.PodSpec{
+						ServiceAccountName: "router-original",
 						Volumes: []corev1.Volume{
 							{
 								Name: "default-certificate",
@@ -433,6 +587,15 @@ func TestDeploymentConfigChanged(t *testing.T) {
 									},
 								},
 							},
+							{
+								Name: "rsyslog-config",
+								VolumeSource: corev1.VolumeSource{
+									ConfigMap: &corev1.ConfigMapVolumeSource{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "rsyslog-conf-default"},
+										DefaultMode:          &fourTwenty,
+									},
+								},
+							},
 						},
 						Containers: []corev1.Container{
 							{
@@ -451,6 +614,24 @@ func TestDeploymentConfigChanged(t *testing.T) {
 									},
 								},
 								Image: "openshift/origin-cluster-ingress-operator:v4.0",
+								LivenessProbe: &corev1.Probe{
+									Handler: corev1.Handler{
+										HTTPGet: &corev1.HTTPGetAction{
+											Path: "/healthz",
+											Port: intstr.FromInt(1936),
+										},
+									},
+									InitialDelaySeconds: 10,
+								},
+								ReadinessProbe: &corev1.Probe{
+									Handler: corev1.Handler{
+										HTTPGet: &corev1.HTTPGetAction{
+											Path: "/healthz",
+											Port: intstr.FromInt(1936),
+										},
+									},
+									InitialDelaySeconds: 10,
+								},
 							},
 						},
 						Affinity: &corev1.Affinity{
@@ -487,3 +668,85 @@ func TestDeploymentConfigChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestRouterSelectorsChanged(t *testing.T) {
+	deploymentWithEnv := func(env ...corev1.EnvVar) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "router", Env: env}}
+		return deployment
+	}
+	current := deploymentWithEnv(corev1.EnvVar{Name: "ROUTE_LABELS", Value: "shard=a"}, corev1.EnvVar{Name: "ROUTER_THREADS", Value: "4"})
+	if routerSelectorsChanged(current, deploymentWithEnv(corev1.EnvVar{Name: "ROUTE_LABELS", Value: "shard=a"}, corev1.EnvVar{Name: "ROUTER_THREADS", Value: "8"})) {
+		t.Error("expected selectors not to change when only other variables change")
+	}
+	if !routerSelectorsChanged(current, deploymentWithEnv(corev1.EnvVar{Name: "ROUTE_LABELS", Value: "shard=b"})) {
+		t.Error("expected selectors to change when the route selector changes")
+	}
+	if !routerSelectorsChanged(current, deploymentWithEnv(corev1.EnvVar{Name: "ROUTE_LABELS", Value: "shard=a"}, corev1.EnvVar{Name: "NAMESPACE_LABELS", Value: "env=prod"})) {
+		t.Error("expected selectors to change when a namespace selector is added")
+	}
+}
+
+func TestRouterTolerations(t *testing.T) {
+	infraSelector := map[string]string{"node-role.kubernetes.io/infra": ""}
+	workerSelector := map[string]string{"node-role.kubernetes.io/worker": ""}
+	noScheduleOnly := corev1.Toleration{
+		Key:      "node-role.kubernetes.io/infra",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	tolerateAll := corev1.Toleration{Operator: corev1.TolerationOpExists}
+	testCases := []struct {
+		description  string
+		tolerations  []corev1.Toleration
+		nodeSelector map[string]string
+		expect       []corev1.Toleration
+	}{
+		{
+			description:  "worker nodes",
+			nodeSelector: workerSelector,
+		},
+		{
+			description:  "worker nodes with tolerations",
+			tolerations:  []corev1.Toleration{toleration},
+			nodeSelector: workerSelector,
+			expect:       []corev1.Toleration{toleration},
+		},
+		{
+			description:  "infra nodes",
+			nodeSelector: infraSelector,
+			expect:       []corev1.Toleration{infraToleration},
+		},
+		{
+			description:  "infra nodes with other tolerations",
+			tolerations:  []corev1.Toleration{toleration},
+			nodeSelector: infraSelector,
+			expect:       []corev1.Toleration{toleration, infraToleration},
+		},
+		{
+			description:  "infra nodes with a toleration for only one infra taint",
+			tolerations:  []corev1.Toleration{noScheduleOnly},
+			nodeSelector: infraSelector,
+			expect:       []corev1.Toleration{noScheduleOnly, infraToleration},
+		},
+		{
+			description:  "infra nodes with a toleration for all taints",
+			tolerations:  []corev1.Toleration{tolerateAll},
+			nodeSelector: infraSelector,
+			expect:       []corev1.Toleration{tolerateAll},
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{}
+		if tc.tolerations != nil {
+			ci.Spec.NodePlacement = &operatorv1.NodePlacement{Tolerations: tc.tolerations}
+		}
+		actual := routerTolerations(ci, tc.nodeSelector)
+		if !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
+		}
+		if len(tc.tolerations) != 0 && !reflect.DeepEqual(ci.Spec.NodePlacement.Tolerations, tc.tolerations) {
+			t.Errorf("%q: ingresscontroller tolerations were modified: %#v", tc.description, ci.Spec.NodePlacement.Tolerations)
+		}
+	}
+}
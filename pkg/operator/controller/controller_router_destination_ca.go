@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// serviceCABundleConfigMapName is the name of the configmap in the
+	// operand namespace into which the service-ca operator injects the
+	// service CA bundle.
+	serviceCABundleConfigMapName = "service-ca-bundle"
+
+	// InjectServiceCABundleAnnotation is the annotation that requests that
+	// the service-ca operator inject the service CA bundle into a
+	// configmap.
+	InjectServiceCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+
+	// serviceCABundleKey is the key of the injected service CA bundle.
+	serviceCABundleKey = "service-ca.crt"
+
+	// defaultDestinationCAConfigMapName is the name of the configmap that
+	// the operator manages in the operand namespace with the CA bundle
+	// that routers use to verify re-encrypt route destinations whose
+	// routes do not specify a destination CA certificate.
+	defaultDestinationCAConfigMapName = "router-default-destination-ca"
+
+	// defaultDestinationCAKey is the key of the default destination CA
+	// bundle.
+	defaultDestinationCAKey = "ca-bundle.crt"
+
+	defaultDestinationCAVolumeName = "default-destination-ca"
+	defaultDestinationCAMountPath  = "/etc/pki/tls/default-destination-ca"
+)
+
+// IsDefaultDestinationCASource returns true if the configmap with the given
+// name is one from which the operator builds the default destination CA
+// bundle.
+func IsDefaultDestinationCASource(name string) bool {
+	return name == serviceCABundleConfigMapName || name == trustedCABundleConfigMapName
+}
+
+// desiredDefaultDestinationCABundle returns the default destination CA bundle,
+// which combines the service CA bundle with the cluster's trusted CA bundle,
+// or false if the service CA bundle has not been injected yet.  Until it has,
+// routers keep using the service CA from their service account.
+func desiredDefaultDestinationCABundle(serviceCA, trustedCA *corev1.ConfigMap) (string, bool) {
+	if serviceCA == nil || len(serviceCA.Data[serviceCABundleKey]) == 0 {
+		return "", false
+	}
+	bundles := []string{strings.TrimSpace(serviceCA.Data[serviceCABundleKey])}
+	if trustedCA != nil && len(trustedCA.Data[trustedCABundleKey]) != 0 {
+		bundles = append(bundles, strings.TrimSpace(trustedCA.Data[trustedCABundleKey]))
+	}
+	return strings.Join(bundles, "\n") + "\n", true
+}
+
+// configureDefaultDestinationCA configures the given router deployment to use
+// the default destination CA bundle.  Because the deployment hashes the
+// mounted configmap, a rotation of either CA rolls out the routers.
+func configureDefaultDestinationCA(deployment *appsv1.Deployment) {
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: defaultDestinationCAVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: defaultDestinationCAConfigMapName},
+			},
+		},
+	})
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      defaultDestinationCAVolumeName,
+		MountPath: defaultDestinationCAMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "DEFAULT_DESTINATION_CA_PATH",
+		Value: filepath.Join(defaultDestinationCAMountPath, defaultDestinationCAKey),
+	})
+}
+
+// setDefaultDestinationCA ensures that the configmaps from which the default
+// destination CA bundle is built exist, updates the bundle, and configures the
+// given router deployment to use it once it is available.
+func (r *reconciler) setDefaultDestinationCA(deployment *appsv1.Deployment) error {
+	if err := r.ensureServiceCABundleConfigMap(deployment.Namespace); err != nil {
+		return err
+	}
+	if err := r.ensureTrustedCABundleConfigMap(deployment.Namespace); err != nil {
+		return err
+	}
+	serviceCA, err := r.currentConfigMap(types.NamespacedName{Namespace: deployment.Namespace, Name: serviceCABundleConfigMapName})
+	if err != nil {
+		return err
+	}
+	trustedCA, err := r.currentConfigMap(types.NamespacedName{Namespace: deployment.Namespace, Name: trustedCABundleConfigMapName})
+	if err != nil {
+		return err
+	}
+	bundle, ok := desiredDefaultDestinationCABundle(serviceCA, trustedCA)
+	if !ok {
+		return nil
+	}
+	if err := r.ensureDefaultDestinationCAConfigMap(deployment.Namespace, bundle); err != nil {
+		return err
+	}
+	configureDefaultDestinationCA(deployment)
+	return nil
+}
+
+// currentConfigMap returns the configmap with the given name, or nil if it
+// does not exist.
+func (r *reconciler) currentConfigMap(name types.NamespacedName) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), name, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get configmap %s: %v", name, err)
+	}
+	return cm, nil
+}
+
+// ensureServiceCABundleConfigMap ensures that the configmap into which the
+// service-ca operator injects the service CA bundle exists in the given
+// namespace.
+func (r *reconciler) ensureServiceCABundleConfigMap(namespace string) error {
+	name := types.NamespacedName{Namespace: namespace, Name: serviceCABundleConfigMapName}
+	current, err := r.currentConfigMap(name)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		desired := &corev1.ConfigMap{}
+		desired.Namespace = name.Namespace
+		desired.Name = name.Name
+		desired.Annotations = map[string]string{InjectServiceCABundleAnnotation: "true"}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to create configmap %s: %v", name, err)
+		}
+		log.Info("created configmap", "namespace", name.Namespace, "name", name.Name)
+		return nil
+	}
+	if current.Annotations[InjectServiceCABundleAnnotation] == "true" {
+		return nil
+	}
+	updated := current.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	updated.Annotations[InjectServiceCABundleAnnotation] = "true"
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update configmap %s: %v", name, err)
+	}
+	log.Info("updated configmap", "namespace", name.Namespace, "name", name.Name)
+	return nil
+}
+
+// ensureDefaultDestinationCAConfigMap ensures that the configmap with the
+// default destination CA bundle in the given namespace has the given bundle.
+func (r *reconciler) ensureDefaultDestinationCAConfigMap(namespace, bundle string) error {
+	name := types.NamespacedName{Namespace: namespace, Name: defaultDestinationCAConfigMapName}
+	data := map[string]string{defaultDestinationCAKey: bundle}
+	current, err := r.currentConfigMap(name)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		desired := &corev1.ConfigMap{}
+		desired.Namespace = name.Namespace
+		desired.Name = name.Name
+		desired.Data = data
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create configmap %s: %v", name, err)
+		}
+		log.Info("created configmap", "namespace", name.Namespace, "name", name.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Data, data) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Data = data
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update configmap %s: %v", name, err)
+	}
+	log.Info("updated configmap", "namespace", name.Namespace, "name", name.Name)
+	return nil
+}
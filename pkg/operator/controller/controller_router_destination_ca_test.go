@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDesiredDefaultDestinationCABundle(t *testing.T) {
+	configMap := func(key, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{Data: map[string]string{key: value}}
+	}
+	testCases := []struct {
+		description string
+		serviceCA   *corev1.ConfigMap
+		trustedCA   *corev1.ConfigMap
+		expect      string
+		expectOK    bool
+	}{
+		{
+			description: "service CA not created",
+			trustedCA:   configMap(trustedCABundleKey, "trusted"),
+		},
+		{
+			description: "service CA not injected",
+			serviceCA:   &corev1.ConfigMap{},
+			trustedCA:   configMap(trustedCABundleKey, "trusted"),
+		},
+		{
+			description: "trusted CA not injected",
+			serviceCA:   configMap(serviceCABundleKey, "service\n"),
+			trustedCA:   &corev1.ConfigMap{},
+			expect:      "service\n",
+			expectOK:    true,
+		},
+		{
+			description: "both injected",
+			serviceCA:   configMap(serviceCABundleKey, "service\n"),
+			trustedCA:   configMap(trustedCABundleKey, "trusted"),
+			expect:      "service\ntrusted\n",
+			expectOK:    true,
+		},
+	}
+	for _, tc := range testCases {
+		bundle, ok := desiredDefaultDestinationCABundle(tc.serviceCA, tc.trustedCA)
+		if ok != tc.expectOK || bundle != tc.expect {
+			t.Errorf("%q: expected (%q, %t), got (%q, %t)", tc.description, tc.expect, tc.expectOK, bundle, ok)
+		}
+	}
+}
+
+func TestConfigureDefaultDestinationCA(t *testing.T) {
+	deployment := newTestRouterDeployment()
+	configureDefaultDestinationCA(deployment)
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	if v, _ := envValue(container, "DEFAULT_DESTINATION_CA_PATH"); v != "/etc/pki/tls/default-destination-ca/ca-bundle.crt" {
+		t.Errorf("unexpected DEFAULT_DESTINATION_CA_PATH %q", v)
+	}
+	if !DeploymentMountsObject(deployment, &corev1.ConfigMap{}, defaultDestinationCAConfigMapName) {
+		t.Error("expected the deployment to mount the default destination CA configmap")
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DNSPolicyAnnotation may be set on an IngressController to override
+	// the DNS policy of the router pods.  The value is one of
+	// "ClusterFirst", "ClusterFirstWithHostNet", "Default", or "None".  By
+	// default, routers that use the HostNetwork endpoint publishing
+	// strategy use "ClusterFirstWithHostNet" so that they can resolve
+	// cluster services, and other routers use "ClusterFirst".
+	DNSPolicyAnnotation = "ingress.operator.openshift.io/dns-policy"
+
+	// DNSConfigAnnotation may be set on an IngressController to specify
+	// additional DNS parameters for the router pods.  The value is a JSON
+	// pod DNS config, for example:
+	//
+	//   {"nameservers": ["10.0.0.10"], "searches": ["example.com"], "options": [{"name": "ndots", "value": "2"}]}
+	//
+	// The annotation is required if the DNS policy is "None".
+	DNSConfigAnnotation = "ingress.operator.openshift.io/dns-config"
+
+	// maxDNSNameservers and maxDNSSearches are the limits that the API
+	// server enforces on a pod's DNS config.
+	maxDNSNameservers = 3
+	maxDNSSearches    = 6
+)
+
+// desiredRouterDNS returns the DNS policy and DNS config for the router pods
+// of the given ingresscontroller.
+func desiredRouterDNS(ic *operatorv1.IngressController) (corev1.DNSPolicy, *corev1.PodDNSConfig, error) {
+	policy := corev1.DNSClusterFirst
+	if ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType {
+		policy = corev1.DNSClusterFirstWithHostNet
+	}
+	if value, ok := ic.Annotations[DNSPolicyAnnotation]; ok {
+		switch p := corev1.DNSPolicy(value); p {
+		case corev1.DNSClusterFirst, corev1.DNSClusterFirstWithHostNet, corev1.DNSDefault, corev1.DNSNone:
+			policy = p
+		default:
+			return "", nil, fmt.Errorf("invalid value for annotation %s: %q: must be %s, %s, %s, or %s", DNSPolicyAnnotation, value, corev1.DNSClusterFirst, corev1.DNSClusterFirstWithHostNet, corev1.DNSDefault, corev1.DNSNone)
+		}
+	}
+
+	var config *corev1.PodDNSConfig
+	if value, ok := ic.Annotations[DNSConfigAnnotation]; ok {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+		decoder.DisallowUnknownFields()
+		config = &corev1.PodDNSConfig{}
+		if err := decoder.Decode(config); err != nil {
+			return "", nil, fmt.Errorf("invalid value for annotation %s: %v", DNSConfigAnnotation, err)
+		}
+		if err := validateDNSConfig(config); err != nil {
+			return "", nil, fmt.Errorf("invalid value for annotation %s: %v", DNSConfigAnnotation, err)
+		}
+	}
+	if policy == corev1.DNSNone && (config == nil || len(config.Nameservers) == 0) {
+		return "", nil, fmt.Errorf("annotation %s must specify at least one nameserver if the DNS policy is %s", DNSConfigAnnotation, corev1.DNSNone)
+	}
+	return policy, config, nil
+}
+
+// validateDNSConfig returns an error if the given pod DNS config is not valid.
+func validateDNSConfig(config *corev1.PodDNSConfig) error {
+	if len(config.Nameservers) > maxDNSNameservers {
+		return fmt.Errorf("nameservers may have at most %d items, got %d", maxDNSNameservers, len(config.Nameservers))
+	}
+	for _, nameserver := range config.Nameservers {
+		if net.ParseIP(nameserver) == nil {
+			return fmt.Errorf("nameserver %q is not an IP address", nameserver)
+		}
+	}
+	if len(config.Searches) > maxDNSSearches {
+		return fmt.Errorf("searches may have at most %d items, got %d", maxDNSSearches, len(config.Searches))
+	}
+	for _, option := range config.Options {
+		if len(option.Name) == 0 {
+			return fmt.Errorf("options must have a name")
+		}
+	}
+	return nil
+}
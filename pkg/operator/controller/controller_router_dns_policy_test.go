@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDesiredRouterDNS(t *testing.T) {
+	testCases := []struct {
+		description  string
+		strategy     operatorv1.EndpointPublishingStrategyType
+		annotations  map[string]string
+		expectPolicy corev1.DNSPolicy
+		expectConfig *corev1.PodDNSConfig
+		expectErr    bool
+	}{
+		{
+			description:  "load balancer default",
+			strategy:     operatorv1.LoadBalancerServiceStrategyType,
+			expectPolicy: corev1.DNSClusterFirst,
+		},
+		{
+			description:  "host network default",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			expectPolicy: corev1.DNSClusterFirstWithHostNet,
+		},
+		{
+			description:  "host network override",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			annotations:  map[string]string{DNSPolicyAnnotation: "Default"},
+			expectPolicy: corev1.DNSDefault,
+		},
+		{
+			description: "none with config",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotations: map[string]string{
+				DNSPolicyAnnotation: "None",
+				DNSConfigAnnotation: `{"nameservers":["10.0.0.10"],"searches":["example.com"]}`,
+			},
+			expectPolicy: corev1.DNSNone,
+			expectConfig: &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}, Searches: []string{"example.com"}},
+		},
+		{
+			description: "none without nameservers",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotations: map[string]string{DNSPolicyAnnotation: "None"},
+			expectErr:   true,
+		},
+		{
+			description: "invalid policy",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotations: map[string]string{DNSPolicyAnnotation: "HostFirst"},
+			expectErr:   true,
+		},
+		{
+			description: "invalid nameserver",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotations: map[string]string{DNSConfigAnnotation: `{"nameservers":["dns.example.com"]}`},
+			expectErr:   true,
+		},
+		{
+			description: "too many nameservers",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotations: map[string]string{DNSConfigAnnotation: `{"nameservers":["10.0.0.1","10.0.0.2","10.0.0.3","10.0.0.4"]}`},
+			expectErr:   true,
+		},
+		{
+			description: "unknown field",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotations: map[string]string{DNSConfigAnnotation: `{"servers":["10.0.0.1"]}`},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{Type: tc.strategy}
+		policy, config, err := desiredRouterDNS(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if policy != tc.expectPolicy || !reflect.DeepEqual(config, tc.expectConfig) {
+			t.Errorf("%q: expected %s and %v, got %s and %v", tc.description, tc.expectPolicy, tc.expectConfig, policy, config)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// DynamicConfigManagerAnnotation may be set on an IngressController to
+	// enable the router's dynamic configuration manager, which applies
+	// many route changes to the running HAProxy process without reloading
+	// it.  The value is a JSON object with the manager's optional
+	// parameters, for example:
+	//
+	//   {}
+	//   {"blueprintRouteNamespace": "openshift-ingress", "blueprintRouteLabels": "type=blueprint", "blueprintRoutePoolSize": 20}
+	//
+	// The dynamic configuration manager is disabled if the annotation is
+	// not set.
+	DynamicConfigManagerAnnotation = "ingress.operator.openshift.io/dynamic-config-manager"
+)
+
+// DynamicConfigManager describes the parameters of the router's dynamic
+// configuration manager.
+type DynamicConfigManager struct {
+	// BlueprintRouteNamespace is the namespace of the blueprint routes,
+	// which the router uses as templates for pre-allocated backends.  If
+	// it is empty, the router uses only its default blueprints.
+	BlueprintRouteNamespace string `json:"blueprintRouteNamespace,omitempty"`
+
+	// BlueprintRouteLabels is a label selector for the blueprint routes
+	// in the blueprint route namespace.  It may only be set with
+	// BlueprintRouteNamespace.
+	BlueprintRouteLabels string `json:"blueprintRouteLabels,omitempty"`
+
+	// BlueprintRoutePoolSize is the number of backends that the router
+	// pre-allocates for each blueprint route, from 0 to 1000.  If it is
+	// not set, the router's default is used.
+	BlueprintRoutePoolSize *int `json:"blueprintRoutePoolSize,omitempty"`
+
+	// MaxDynamicServers is the number of server slots that the router
+	// pre-allocates in each backend for endpoints that are added without
+	// a reload, from 1 to 100.  If it is not set, the router's default is
+	// used.
+	MaxDynamicServers *int `json:"maxDynamicServers,omitempty"`
+}
+
+// dynamicConfigManagerFor returns the dynamic configuration manager parameters
+// of the given ingresscontroller, or nil if the manager is disabled.
+func dynamicConfigManagerFor(ic *operatorv1.IngressController) (*DynamicConfigManager, error) {
+	value, ok := ic.Annotations[DynamicConfigManagerAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	manager := &DynamicConfigManager{}
+	if len(strings.TrimSpace(value)) != 0 {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(manager); err != nil {
+			return nil, fmt.Errorf("invalid value for annotation %s: %v", DynamicConfigManagerAnnotation, err)
+		}
+	}
+	if err := validateDynamicConfigManager(manager); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", DynamicConfigManagerAnnotation, err)
+	}
+	return manager, nil
+}
+
+// validateDynamicConfigManager returns an error if the given dynamic
+// configuration manager parameters are not valid.
+func validateDynamicConfigManager(manager *DynamicConfigManager) error {
+	if len(manager.BlueprintRouteNamespace) != 0 {
+		if errs := validation.IsDNS1123Label(manager.BlueprintRouteNamespace); len(errs) != 0 {
+			return fmt.Errorf("blueprintRouteNamespace %q is not a valid namespace name: %s", manager.BlueprintRouteNamespace, strings.Join(errs, ", "))
+		}
+	}
+	if len(manager.BlueprintRouteLabels) != 0 {
+		if len(manager.BlueprintRouteNamespace) == 0 {
+			return fmt.Errorf("blueprintRouteLabels may only be specified with blueprintRouteNamespace")
+		}
+		if _, err := labels.Parse(manager.BlueprintRouteLabels); err != nil {
+			return fmt.Errorf("blueprintRouteLabels %q is not a valid label selector: %v", manager.BlueprintRouteLabels, err)
+		}
+	}
+	if size := manager.BlueprintRoutePoolSize; size != nil {
+		if err := validateTuningOption(blueprintRoutePoolSizeOption, int64(*size)); err != nil {
+			return err
+		}
+	}
+	if servers := manager.MaxDynamicServers; servers != nil {
+		if err := validateTuningOption(maxDynamicServersOption, int64(*servers)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// desiredDynamicConfigManagerEnv returns the router environment variables
+// that configure the dynamic configuration manager for the given
+// ingresscontroller.
+func desiredDynamicConfigManagerEnv(ic *operatorv1.IngressController) ([]corev1.EnvVar, error) {
+	manager, err := dynamicConfigManagerFor(ic)
+	if err != nil || manager == nil {
+		return nil, err
+	}
+	env := []corev1.EnvVar{{Name: "ROUTER_HAPROXY_CONFIG_MANAGER", Value: "true"}}
+	if len(manager.BlueprintRouteNamespace) != 0 {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_BLUEPRINT_ROUTE_NAMESPACE", Value: manager.BlueprintRouteNamespace})
+	}
+	if len(manager.BlueprintRouteLabels) != 0 {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_BLUEPRINT_ROUTE_LABELS", Value: manager.BlueprintRouteLabels})
+	}
+	if manager.BlueprintRoutePoolSize != nil {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_BLUEPRINT_ROUTE_POOL_SIZE", Value: strconv.Itoa(*manager.BlueprintRoutePoolSize)})
+	}
+	if manager.MaxDynamicServers != nil {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_MAX_DYNAMIC_SERVERS", Value: strconv.Itoa(*manager.MaxDynamicServers)})
+	}
+	return env, nil
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDesiredDynamicConfigManagerEnv(t *testing.T) {
+	enabled := corev1.EnvVar{Name: "ROUTER_HAPROXY_CONFIG_MANAGER", Value: "true"}
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expect      []corev1.EnvVar
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "empty value",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: ""},
+			expect:      []corev1.EnvVar{enabled},
+		},
+		{
+			description: "empty object",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: "{}"},
+			expect:      []corev1.EnvVar{enabled},
+		},
+		{
+			description: "blueprint routes",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"blueprintRouteNamespace":"openshift-ingress","blueprintRouteLabels":"type=blueprint","blueprintRoutePoolSize":20,"maxDynamicServers":10}`},
+			expect: []corev1.EnvVar{
+				enabled,
+				{Name: "ROUTER_BLUEPRINT_ROUTE_NAMESPACE", Value: "openshift-ingress"},
+				{Name: "ROUTER_BLUEPRINT_ROUTE_LABELS", Value: "type=blueprint"},
+				{Name: "ROUTER_BLUEPRINT_ROUTE_POOL_SIZE", Value: "20"},
+				{Name: "ROUTER_MAX_DYNAMIC_SERVERS", Value: "10"},
+			},
+		},
+		{
+			description: "zero pool size",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"blueprintRoutePoolSize":0}`},
+			expect: []corev1.EnvVar{
+				enabled,
+				{Name: "ROUTER_BLUEPRINT_ROUTE_POOL_SIZE", Value: "0"},
+			},
+		},
+		{
+			description: "invalid blueprint namespace",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"blueprintRouteNamespace":"Not_A_Namespace"}`},
+			expectErr:   true,
+		},
+		{
+			description: "blueprint labels without namespace",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"blueprintRouteLabels":"type=blueprint"}`},
+			expectErr:   true,
+		},
+		{
+			description: "invalid blueprint labels",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"blueprintRouteNamespace":"openshift-ingress","blueprintRouteLabels":"type in ("}`},
+			expectErr:   true,
+		},
+		{
+			description: "too large pool size",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"blueprintRoutePoolSize":1001}`},
+			expectErr:   true,
+		},
+		{
+			description: "zero dynamic servers",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"maxDynamicServers":0}`},
+			expectErr:   true,
+		},
+		{
+			description: "unknown field",
+			annotations: map[string]string{DynamicConfigManagerAnnotation: `{"enabled":true}`},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		env, err := desiredDynamicConfigManagerEnv(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(env, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, env)
+		}
+	}
+}
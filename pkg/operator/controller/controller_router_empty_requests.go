@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// HTTPEmptyRequestsPolicyAnnotation may be set on an IngressController
+	// to specify how the router handles connections that are closed before
+	// any request is received, such as the health probes of cloud load
+	// balancers.  The value is "Respond" (the default), in which case the
+	// router responds with HTTP 400 and logs the request, or "Ignore", in
+	// which case the router silently closes the connection.
+	HTTPEmptyRequestsPolicyAnnotation = "ingress.operator.openshift.io/http-empty-requests-policy"
+
+	// HTTPEmptyRequestsPolicyRespond and HTTPEmptyRequestsPolicyIgnore
+	// are the valid values of HTTPEmptyRequestsPolicyAnnotation.
+	HTTPEmptyRequestsPolicyRespond = "Respond"
+	HTTPEmptyRequestsPolicyIgnore  = "Ignore"
+)
+
+// desiredEmptyRequestsEnv returns the router environment variables that
+// configure how the router handles empty requests for the given
+// ingresscontroller.
+func desiredEmptyRequestsEnv(ic *operatorv1.IngressController) ([]corev1.EnvVar, error) {
+	var env []corev1.EnvVar
+	switch policy := ic.Annotations[HTTPEmptyRequestsPolicyAnnotation]; policy {
+	case "", HTTPEmptyRequestsPolicyRespond:
+	case HTTPEmptyRequestsPolicyIgnore:
+		// Sets HAProxy's "option http-ignore-probes".
+		env = append(env, corev1.EnvVar{Name: "ROUTER_HTTP_IGNORE_PROBES", Value: "true"})
+	default:
+		return nil, fmt.Errorf("invalid value for annotation %s: %q: must be %s or %s", HTTPEmptyRequestsPolicyAnnotation, policy, HTTPEmptyRequestsPolicyRespond, HTTPEmptyRequestsPolicyIgnore)
+	}
+	return env, nil
+}
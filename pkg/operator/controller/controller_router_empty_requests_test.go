@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDesiredEmptyRequestsEnv(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expect      []corev1.EnvVar
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "respond",
+			annotations: map[string]string{HTTPEmptyRequestsPolicyAnnotation: HTTPEmptyRequestsPolicyRespond},
+		},
+		{
+			description: "ignore",
+			annotations: map[string]string{HTTPEmptyRequestsPolicyAnnotation: HTTPEmptyRequestsPolicyIgnore},
+			expect:      []corev1.EnvVar{{Name: "ROUTER_HTTP_IGNORE_PROBES", Value: "true"}},
+		},
+		{
+			description: "invalid policy",
+			annotations: map[string]string{HTTPEmptyRequestsPolicyAnnotation: "Drop"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		env, err := desiredEmptyRequestsEnv(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(env, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, env)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// routerExternalCertificateEnvName is the router environment variable
+	// that enables routes to reference certificates in secrets through
+	// spec.tls.externalCertificate.
+	routerExternalCertificateEnvName = "ROUTER_ENABLE_EXTERNAL_CERTIFICATE"
+
+	// RouterExternalCertificateRoleLabel is set to "true" on the roles and
+	// role bindings that the external-certificate controller manages in
+	// route namespaces, so that they can be found when they are no longer
+	// needed.
+	RouterExternalCertificateRoleLabel = "ingress.operator.openshift.io/router-external-certificate"
+)
+
+// routeExternalCertificateEnabled returns true if the RouteExternalCertificate
+// feature is enabled.
+func (r *reconciler) routeExternalCertificateEnabled() bool {
+	return operatorconfig.FeatureGates(r.Config.FeatureGates).Enabled(operatorconfig.RouteExternalCertificateFeature)
+}
+
+// configureRouterExternalCertificate enables external certificate references
+// in the given router deployment if enabled is true.
+//
+// The external-certificate controller grants the service account of each
+// ingresscontroller (see RouterServiceAccountName) get, list, and watch access
+// to exactly the secrets that the routes that the ingresscontroller selects
+// reference, with a role and role binding in each route namespace (see
+// RouterExternalCertificateRoleName).
+func configureRouterExternalCertificate(deployment *appsv1.Deployment, enabled bool) {
+	if !enabled {
+		return
+	}
+	deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  routerExternalCertificateEnvName,
+		Value: "true",
+	})
+}
+
+// setRouterExternalCertificate enables external certificate references in the
+// given router deployment if the RouteExternalCertificate feature is enabled.
+func (r *reconciler) setRouterExternalCertificate(deployment *appsv1.Deployment) {
+	configureRouterExternalCertificate(deployment, r.routeExternalCertificateEnabled())
+}
+
+// RouteSelected returns true if the route and namespace selectors of the given
+// ingresscontroller select a route with the given labels in a namespace with
+// the given labels.
+func RouteSelected(ic *operatorv1.IngressController, routeLabels, namespaceLabels labels.Set) (bool, error) {
+	if ic.Spec.RouteSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(ic.Spec.RouteSelector)
+		if err != nil {
+			return false, fmt.Errorf("ingresscontroller %s has an invalid route selector: %v", ic.Name, err)
+		}
+		if !selector.Matches(routeLabels) {
+			return false, nil
+		}
+	}
+	if ic.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(ic.Spec.NamespaceSelector)
+		if err != nil {
+			return false, fmt.Errorf("ingresscontroller %s has an invalid namespace selector: %v", ic.Name, err)
+		}
+		if !selector.Matches(namespaceLabels) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConfigureRouterExternalCertificate(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "router"}}
+		configureRouterExternalCertificate(deployment, enabled)
+		var value string
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == routerExternalCertificateEnvName {
+				value = env.Value
+			}
+		}
+		switch {
+		case enabled && value != "true":
+			t.Errorf("expected %s=true when enabled, got %q", routerExternalCertificateEnvName, value)
+		case !enabled && len(value) != 0:
+			t.Errorf("expected %s to be unset when disabled, got %q", routerExternalCertificateEnvName, value)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RouterImagePolicyAnnotation may be set on an ingresscontroller to
+	// restrict the router images that the operator rolls out to it.  The
+	// value is a JSON object with any of the following fields, for
+	// example:
+	//
+	//   {"requireDigest": true, "allowedDigests": ["sha256:0123...cdef"]}
+	//
+	// requireDigest requires the router image to be referenced by digest
+	// rather than by tag, so that the image cannot change under the same
+	// reference.  allowedDigests, if not empty, lists the only image
+	// digests that the routers may run.  publicKeys, if not empty, lists
+	// PEM-encoded ECDSA, Ed25519, or RSA public keys, and requires the
+	// router image's digest to be signed by one of them; see
+	// RouterImageSignaturesConfigMapName.  Both allowedDigests and
+	// publicKeys imply requireDigest.  If the operator's router image
+	// violates the policy, the operator keeps the routers on their
+	// current image, or does not create them if they do not exist yet,
+	// and reports the violation in the RouterImage condition.
+	RouterImagePolicyAnnotation = "ingress.operator.openshift.io/router-image-policy"
+
+	// RouterImageSignaturesConfigMapName is the name of the configmap in
+	// the operator's namespace that holds the signatures of router image
+	// digests for ingresscontrollers whose router image policy lists
+	// public keys.  Each key is a digest with the colon replaced by a
+	// dash, for example "sha256-0123...cdef", and each value lists the
+	// base64-encoded signatures of the digest string, for example
+	// "sha256:0123...cdef", one per line.  ECDSA and RSA (PKCS #1 v1.5)
+	// signatures are of the SHA-256 hash of the digest string.
+	RouterImageSignaturesConfigMapName = "router-image-signatures"
+
+	// RouterImageConditionType is the type of the ingresscontroller
+	// condition that reports the router image and the digests of the
+	// images that the router pods run.  The condition is false if the
+	// operator's router image violates the ingresscontroller's router
+	// image policy.
+	RouterImageConditionType = "RouterImage"
+
+	// RouterImagePinnedReason is the reason of the RouterImage condition
+	// while the routers are kept on an earlier image, or are not created,
+	// because the operator's router image violates the router image
+	// policy.
+	RouterImagePinnedReason = "Pinned"
+)
+
+// imageDigestPattern matches an image digest.
+var imageDigestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// routerImagePolicy is the value of RouterImagePolicyAnnotation.
+type routerImagePolicy struct {
+	RequireDigest  bool     `json:"requireDigest,omitempty"`
+	AllowedDigests []string `json:"allowedDigests,omitempty"`
+	PublicKeys     []string `json:"publicKeys,omitempty"`
+
+	// keys are the parsed PublicKeys.
+	keys []crypto.PublicKey
+}
+
+// routerImagePolicyFor returns the router image policy of the given
+// ingresscontroller, or nil if it does not specify one.
+func routerImagePolicyFor(ic *operatorv1.IngressController) (*routerImagePolicy, error) {
+	value, ok := ic.Annotations[RouterImagePolicyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	policy := &routerImagePolicy{}
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", RouterImagePolicyAnnotation, err)
+	}
+	for _, digest := range policy.AllowedDigests {
+		if !imageDigestPattern.MatchString(digest) {
+			return nil, fmt.Errorf("invalid value for annotation %s: allowedDigests: %q is not a sha256 image digest", RouterImagePolicyAnnotation, digest)
+		}
+	}
+	for i, publicKey := range policy.PublicKeys {
+		key, err := parsePublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for annotation %s: publicKeys[%d]: %v", RouterImagePolicyAnnotation, i, err)
+		}
+		policy.keys = append(policy.keys, key)
+	}
+	return policy, nil
+}
+
+// parsePublicKey parses the given PEM-encoded public key, which must be an
+// ECDSA, Ed25519, or RSA key.
+func parsePublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("not a PEM-encoded public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// verifySignature returns true if the given signature is a signature of the
+// given message by the given key.
+func verifySignature(key crypto.PublicKey, message, signature []byte) bool {
+	hash := sha256.Sum256(message)
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, hash[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil
+	}
+	return false
+}
+
+// imageDigest returns the digest of the given image reference or image ID, or
+// the empty string if it is not referenced by digest.
+func imageDigest(image string) string {
+	i := strings.LastIndex(image, "@")
+	if i < 0 || !imageDigestPattern.MatchString(image[i+1:]) {
+		return ""
+	}
+	return image[i+1:]
+}
+
+// checkRouterImage returns an error if the given image violates the given
+// router image policy.  The given signatures are the data of the
+// RouterImageSignaturesConfigMapName configmap.
+func checkRouterImage(policy *routerImagePolicy, image string, signatures map[string]string) error {
+	if policy == nil {
+		return nil
+	}
+	digest := imageDigest(image)
+	if len(digest) == 0 && (policy.RequireDigest || len(policy.AllowedDigests) != 0 || len(policy.keys) != 0) {
+		return fmt.Errorf("image %s is not referenced by digest", image)
+	}
+	if len(policy.AllowedDigests) != 0 && !sets.NewString(policy.AllowedDigests...).Has(digest) {
+		return fmt.Errorf("digest %s of image %s is not allowed", digest, image)
+	}
+	if len(policy.keys) != 0 && !isDigestSigned(policy.keys, digest, signatures) {
+		return fmt.Errorf("digest %s of image %s has no valid signature by the policy's public keys in configmap %s", digest, image, RouterImageSignaturesConfigMapName)
+	}
+	return nil
+}
+
+// isDigestSigned returns true if any of the given signatures of the given
+// digest is valid for any of the given keys.
+func isDigestSigned(keys []crypto.PublicKey, digest string, signatures map[string]string) bool {
+	for _, line := range strings.Split(signatures[strings.Replace(digest, ":", "-", 1)], "\n") {
+		signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+		if err != nil || len(signature) == 0 {
+			continue
+		}
+		for _, key := range keys {
+			if verifySignature(key, []byte(digest), signature) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routerImageSignatures returns the data of the
+// RouterImageSignaturesConfigMapName configmap if the router image policy of
+// the given ingresscontroller requires signatures, and nil otherwise.
+func (r *reconciler) routerImageSignatures(ic *operatorv1.IngressController) (map[string]string, error) {
+	policy, err := routerImagePolicyFor(ic)
+	if err != nil || policy == nil || len(policy.keys) == 0 {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: r.Config.Namespace, Name: RouterImageSignaturesConfigMapName}
+	if err := r.client.Get(context.TODO(), name, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to get configmap %s: %v", name, err)
+	}
+	return cm.Data, nil
+}
+
+// routerImageFor returns the router image to roll out to the given
+// ingresscontroller, whose routers currently run the given image, or the empty
+// string if they do not exist yet.  If the desired image violates the
+// ingresscontroller's router image policy, the current image is returned, or
+// an error if there is none.
+func routerImageFor(ic *operatorv1.IngressController, desiredImage, currentImage string, signatures map[string]string) (string, error) {
+	policy, err := routerImagePolicyFor(ic)
+	if err != nil {
+		return "", err
+	}
+	if err := checkRouterImage(policy, desiredImage, signatures); err != nil {
+		if len(currentImage) == 0 {
+			return "", fmt.Errorf("refusing to create routers with an image that violates the router image policy: %v", err)
+		}
+		if desiredImage != currentImage {
+			log.Info("keeping the current router image because the desired image violates the router image policy", "ingresscontroller", ic.Name, "current", currentImage, "desired", desiredImage, "error", err.Error())
+		}
+		return currentImage, nil
+	}
+	return desiredImage, nil
+}
+
+// applyRouterImagePolicy replaces the given desired router image in the given
+// desired pod spec with the image that the router image policy of the given
+// ingresscontroller allows, given the current pod spec, which is nil if the
+// routers do not exist yet, and the given image signatures.
+func applyRouterImagePolicy(ic *operatorv1.IngressController, desiredImage string, desired, current *corev1.PodSpec, signatures map[string]string) error {
+	image, err := routerImageFor(ic, desiredImage, routerContainerImage(current), signatures)
+	if err != nil {
+		return err
+	}
+	// The access logging sidecar runs the router image too.
+	for i := range desired.Containers {
+		if desired.Containers[i].Image == desiredImage {
+			desired.Containers[i].Image = image
+		}
+	}
+	return nil
+}
+
+// routerContainerImage returns the image of the router container of the given
+// pod spec, or the empty string if the pod spec is nil.
+func routerContainerImage(spec *corev1.PodSpec) string {
+	if spec == nil || len(spec.Containers) == 0 {
+		return ""
+	}
+	return spec.Containers[0].Image
+}
+
+// computeRouterImageCondition returns the RouterImage condition for the given
+// ingresscontroller and its router deployment.
+func (r *reconciler) computeRouterImageCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment) (*operatorv1.OperatorCondition, error) {
+	signatures, err := r.routerImageSignatures(ic)
+	if err != nil {
+		return nil, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), pods, client.InNamespace(r.Config.OperandNamespace), client.MatchingLabels(IngressControllerDeploymentPodSelector(ic).MatchLabels)); err != nil {
+		return nil, fmt.Errorf("failed to list router pods: %v", err)
+	}
+	currentImage := routerContainerImage(&deployment.Spec.Template.Spec)
+	return routerImageCondition(ic, r.Config.IngressControllerImage, currentImage, runningRouterImageDigests(pods.Items), signatures)
+}
+
+// routerImageCondition returns the RouterImage condition for the given
+// ingresscontroller given the operator's router image, the router image of
+// its router workload, the digests of the images that its router pods run,
+// and the given image signatures.
+func routerImageCondition(ic *operatorv1.IngressController, desiredImage, currentImage string, digests []string, signatures map[string]string) (*operatorv1.OperatorCondition, error) {
+	policy, err := routerImagePolicyFor(ic)
+	if err != nil {
+		return nil, err
+	}
+	running := "No router pod runs an image with a known digest."
+	if len(digests) != 0 {
+		running = fmt.Sprintf("The router pods run image digests: %s.", strings.Join(digests, ", "))
+	}
+	if err := checkRouterImage(policy, desiredImage, signatures); err != nil {
+		if currentImage == desiredImage {
+			return &operatorv1.OperatorCondition{
+				Type:    RouterImageConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "PolicyViolation",
+				Message: fmt.Sprintf("The routers use image %s, which violates the router image policy: %v.  %s", currentImage, err, running),
+			}, nil
+		}
+		return &operatorv1.OperatorCondition{
+			Type:    RouterImageConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  RouterImagePinnedReason,
+			Message: fmt.Sprintf("The router image %s violates the router image policy and is not rolled out; the routers are kept on image %s: %v.  %s", desiredImage, currentImage, err, running),
+		}, nil
+	}
+	reason := "PolicySatisfied"
+	if policy == nil {
+		reason = "NoPolicy"
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    RouterImageConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf("The routers use image %s.  %s", currentImage, running),
+	}, nil
+}
+
+// runningRouterImageDigests returns the sorted digests of the images that the
+// router containers of the given pods run.
+func runningRouterImageDigests(pods []corev1.Pod) []string {
+	running := sets.NewString()
+	for i := range pods {
+		for _, status := range pods[i].Status.ContainerStatuses {
+			if status.Name != "router" {
+				continue
+			}
+			if digest := imageDigest(status.ImageID); len(digest) != 0 {
+				running.Insert(digest)
+			}
+		}
+	}
+	return running.List()
+}
@@ -0,0 +1,305 @@
+package controller
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testDigestA = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	testDigestB = "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestApplyRouterImagePolicy(t *testing.T) {
+	const (
+		tagImage     = "quay.io/openshift/router:latest"
+		digestImageA = "quay.io/openshift/router@" + testDigestA
+		digestImageB = "quay.io/openshift/router@" + testDigestB
+	)
+	testCases := []struct {
+		description  string
+		policy       string
+		desiredImage string
+		currentImage string
+		expectImage  string
+		expectErr    bool
+	}{
+		{
+			description:  "no policy",
+			desiredImage: tagImage,
+			currentImage: digestImageA,
+			expectImage:  tagImage,
+		},
+		{
+			description:  "digest required and image referenced by digest",
+			policy:       `{"requireDigest": true}`,
+			desiredImage: digestImageB,
+			currentImage: digestImageA,
+			expectImage:  digestImageB,
+		},
+		{
+			description:  "digest required and image referenced by tag",
+			policy:       `{"requireDigest": true}`,
+			desiredImage: tagImage,
+			currentImage: digestImageA,
+			expectImage:  digestImageA,
+		},
+		{
+			description:  "digest allowed",
+			policy:       `{"allowedDigests": ["` + testDigestA + `", "` + testDigestB + `"]}`,
+			desiredImage: digestImageB,
+			currentImage: digestImageA,
+			expectImage:  digestImageB,
+		},
+		{
+			description:  "digest not allowed",
+			policy:       `{"allowedDigests": ["` + testDigestA + `"]}`,
+			desiredImage: digestImageB,
+			currentImage: digestImageA,
+			expectImage:  digestImageA,
+		},
+		{
+			description:  "digest not allowed and no current routers",
+			policy:       `{"allowedDigests": ["` + testDigestA + `"]}`,
+			desiredImage: digestImageB,
+			expectErr:    true,
+		},
+		{
+			description:  "invalid policy",
+			policy:       `{"requireSignature": true}`,
+			desiredImage: digestImageA,
+			expectErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(RouterImagePolicyAnnotation, tc.policy)
+		desired := &corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "router", Image: tc.desiredImage},
+				{Name: "logs", Image: tc.desiredImage},
+			},
+		}
+		var current *corev1.PodSpec
+		if len(tc.currentImage) != 0 {
+			current = &corev1.PodSpec{Containers: []corev1.Container{{Name: "router", Image: tc.currentImage}}}
+		}
+		err := applyRouterImagePolicy(ic, tc.desiredImage, desired, current, nil)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		for _, container := range desired.Containers {
+			if container.Image != tc.expectImage {
+				t.Errorf("%q: expected container %s to have image %s, got %s", tc.description, container.Name, tc.expectImage, container.Image)
+			}
+		}
+	}
+}
+
+func TestRouterImagePolicyForRejectsInvalidDigests(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{RouterImagePolicyAnnotation: `{"allowedDigests": ["sha256:abc"]}`},
+		},
+	}
+	if err := validateIngressController(ic, false, configv1.AWSPlatformType); err == nil {
+		t.Error("expected an error for a truncated digest")
+	}
+}
+
+func TestRouterImageCondition(t *testing.T) {
+	const (
+		tagImage     = "quay.io/openshift/router:latest"
+		digestImageA = "quay.io/openshift/router@" + testDigestA
+	)
+	testCases := []struct {
+		description   string
+		policy        string
+		desiredImage  string
+		currentImage  string
+		expectStatus  operatorv1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			description:   "no policy",
+			desiredImage:  tagImage,
+			currentImage:  tagImage,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "NoPolicy",
+			expectMessage: "The routers use image " + tagImage + ".  The router pods run image digests: " + testDigestA + ".",
+		},
+		{
+			description:   "policy satisfied",
+			policy:        `{"requireDigest": true}`,
+			desiredImage:  digestImageA,
+			currentImage:  digestImageA,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "PolicySatisfied",
+			expectMessage: "The routers use image " + digestImageA + ".  The router pods run image digests: " + testDigestA + ".",
+		},
+		{
+			description:   "policy violated and routers pinned",
+			policy:        `{"requireDigest": true}`,
+			desiredImage:  tagImage,
+			currentImage:  digestImageA,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  RouterImagePinnedReason,
+			expectMessage: "The router image " + tagImage + " violates the router image policy and is not rolled out; the routers are kept on image " + digestImageA + ": image " + tagImage + " is not referenced by digest.  The router pods run image digests: " + testDigestA + ".",
+		},
+		{
+			description:   "policy violated by the current image",
+			policy:        `{"requireDigest": true}`,
+			desiredImage:  tagImage,
+			currentImage:  tagImage,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "PolicyViolation",
+			expectMessage: "The routers use image " + tagImage + ", which violates the router image policy: image " + tagImage + " is not referenced by digest.  The router pods run image digests: " + testDigestA + ".",
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(RouterImagePolicyAnnotation, tc.policy)
+		condition, err := routerImageCondition(ic, tc.desiredImage, tc.currentImage, []string{testDigestA}, nil)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason || condition.Message != tc.expectMessage {
+			t.Errorf("%q: expected %s/%s with message %q, got %s/%s with message %q", tc.description, tc.expectStatus, tc.expectReason, tc.expectMessage, condition.Status, condition.Reason, condition.Message)
+		}
+	}
+}
+
+func TestCheckRouterImageSignature(t *testing.T) {
+	const digestImageA = "quay.io/openshift/router@" + testDigestA
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Public, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodePublicKey := func(key crypto.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	policy := func(keys ...crypto.PublicKey) *routerImagePolicy {
+		encoded := []string{}
+		for _, key := range keys {
+			encoded = append(encoded, encodePublicKey(key))
+		}
+		value, err := json.Marshal(map[string][]string{"publicKeys": encoded})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{RouterImagePolicyAnnotation: string(value)}}}
+		p, err := routerImagePolicyFor(ic)
+		if err != nil {
+			t.Fatalf("failed to parse policy: %v", err)
+		}
+		return p
+	}
+	hash := sha256.Sum256([]byte(testDigestA))
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Signature := ed25519.Sign(ed25519Key, []byte(testDigestA))
+	signatures := func(signatures ...[]byte) map[string]string {
+		encoded := []string{}
+		for _, signature := range signatures {
+			encoded = append(encoded, base64.StdEncoding.EncodeToString(signature))
+		}
+		return map[string]string{strings.Replace(testDigestA, ":", "-", 1): strings.Join(encoded, "\n")}
+	}
+	testCases := []struct {
+		description string
+		policy      *routerImagePolicy
+		image       string
+		signatures  map[string]string
+		expectErr   bool
+	}{
+		{
+			description: "ECDSA signature",
+			policy:      policy(&ecdsaKey.PublicKey),
+			image:       digestImageA,
+			signatures:  signatures(ecdsaSignature),
+		},
+		{
+			description: "Ed25519 signature among others",
+			policy:      policy(&ecdsaKey.PublicKey, ed25519Public),
+			image:       digestImageA,
+			signatures:  signatures([]byte("invalid"), ed25519Signature),
+		},
+		{
+			description: "signature by another key",
+			policy:      policy(ed25519Public),
+			image:       digestImageA,
+			signatures:  signatures(ecdsaSignature),
+			expectErr:   true,
+		},
+		{
+			description: "no signature",
+			policy:      policy(&ecdsaKey.PublicKey),
+			image:       digestImageA,
+			expectErr:   true,
+		},
+		{
+			description: "image referenced by tag",
+			policy:      policy(&ecdsaKey.PublicKey),
+			image:       "quay.io/openshift/router:latest",
+			signatures:  signatures(ecdsaSignature),
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		err := checkRouterImage(tc.policy, tc.image, tc.signatures)
+		checkError(t, tc.description, tc.expectErr, err)
+	}
+}
+
+func TestRunningRouterImageDigests(t *testing.T) {
+	pod := func(imageID string) corev1.Pod {
+		return corev1.Pod{
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "router", ImageID: imageID},
+					{Name: "logs", ImageID: "docker-pullable://quay.io/openshift/other@" + testDigestB},
+				},
+			},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("docker-pullable://quay.io/openshift/router@" + testDigestA),
+		pod("quay.io/openshift/router@" + testDigestA),
+		pod(""),
+	}
+	digests := runningRouterImageDigests(pods)
+	if len(digests) != 1 || digests[0] != testDigestA {
+		t.Errorf("expected [%s], got %v", testDigestA, digests)
+	}
+	if digests := runningRouterImageDigests(nil); len(digests) != 0 {
+		t.Errorf("expected no digests, got %v", digests)
+	}
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DisableIngressProcessingAnnotation may be set to "true" on an
+	// IngressController so that its router ignores Kubernetes Ingress
+	// objects and serves only Routes.  By default, routers translate the
+	// Ingresses that specify the ingresscontroller's IngressClass.
+	DisableIngressProcessingAnnotation = "ingress.operator.openshift.io/disable-ingress-processing"
+)
+
+// ingressProcessingDisabled returns true if the given ingresscontroller is
+// annotated to disable processing of Ingress objects.
+func ingressProcessingDisabled(ic *operatorv1.IngressController) (bool, error) {
+	value, ok := ic.Annotations[DisableIngressProcessingAnnotation]
+	if !ok || len(value) == 0 {
+		return false, nil
+	}
+	disabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for annotation %s: %q: must be a boolean", DisableIngressProcessingAnnotation, value)
+	}
+	return disabled, nil
+}
+
+// desiredIngressProcessingEnv returns the router environment variables that
+// scope the router's processing of Ingress objects for the given
+// ingresscontroller.  The router translates only the Ingresses that specify
+// the ingresscontroller's IngressClass, and the default ingresscontroller also
+// translates Ingresses that specify no class.
+func desiredIngressProcessingEnv(ic *operatorv1.IngressController) ([]corev1.EnvVar, error) {
+	disabled, err := ingressProcessingDisabled(ic)
+	if err != nil {
+		return nil, err
+	}
+	if disabled {
+		return []corev1.EnvVar{{Name: "ROUTER_DISABLE_INGRESS", Value: "true"}}, nil
+	}
+	env := []corev1.EnvVar{{Name: "ROUTER_INGRESS_CLASS", Value: IngressClassName(ic.Name).Name}}
+	if ic.Name == DefaultIngressControllerName {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_INGRESS_CLASS_DEFAULT", Value: "true"})
+	}
+	return env, nil
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDesiredIngressProcessingEnv(t *testing.T) {
+	testCases := []struct {
+		description string
+		name        string
+		annotation  string
+		expect      []corev1.EnvVar
+		expectErr   bool
+	}{
+		{
+			description: "default ingresscontroller",
+			name:        "default",
+			expect: []corev1.EnvVar{
+				{Name: "ROUTER_INGRESS_CLASS", Value: "openshift-default"},
+				{Name: "ROUTER_INGRESS_CLASS_DEFAULT", Value: "true"},
+			},
+		},
+		{
+			description: "sharded ingresscontroller",
+			name:        "sharded",
+			expect: []corev1.EnvVar{
+				{Name: "ROUTER_INGRESS_CLASS", Value: "openshift-sharded"},
+			},
+		},
+		{
+			description: "explicitly enabled",
+			name:        "sharded",
+			annotation:  "false",
+			expect: []corev1.EnvVar{
+				{Name: "ROUTER_INGRESS_CLASS", Value: "openshift-sharded"},
+			},
+		},
+		{
+			description: "disabled",
+			name:        "default",
+			annotation:  "true",
+			expect: []corev1.EnvVar{
+				{Name: "ROUTER_DISABLE_INGRESS", Value: "true"},
+			},
+		},
+		{
+			description: "invalid annotation",
+			name:        "default",
+			annotation:  "maybe",
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(DisableIngressProcessingAnnotation, tc.annotation)
+		ic.Name = tc.name
+		env, err := desiredIngressProcessingEnv(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(env, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, env)
+		}
+	}
+}
@@ -0,0 +1,568 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AccessLoggingAnnotation may be set on an IngressController to enable
+	// access logging.  The value is a JSON object that specifies where the
+	// router sends access logs and which requests it logs, for example:
+	//
+	//   {"destination": {"type": "Container"}, "logEmptyRequests": "Ignore"}
+	//   {"destination": {"type": "Syslog", "syslog": {"address": "1.2.3.4", "port": 514}}}
+	//
+	// Access logging is disabled if the annotation is not set.
+	AccessLoggingAnnotation = "ingress.operator.openshift.io/access-logging"
+
+	// ContainerLoggingDestinationType sends access logs to a sidecar
+	// container, which writes them to its standard output.
+	ContainerLoggingDestinationType = "Container"
+	// SyslogLoggingDestinationType sends access logs to a syslog endpoint.
+	SyslogLoggingDestinationType = "Syslog"
+
+	// LoggingPolicyLog and LoggingPolicyIgnore are the valid values of
+	// logEmptyRequests.
+	LoggingPolicyLog    = "Log"
+	LoggingPolicyIgnore = "Ignore"
+
+	// CookieMatchTypeExact and CookieMatchTypePrefix are the valid values
+	// of an HTTP cookie capture's matchType.
+	CookieMatchTypeExact  = "Exact"
+	CookieMatchTypePrefix = "Prefix"
+
+	// maxCaptureCookieLength is the maximum number of characters of a
+	// cookie's value that may be captured.
+	maxCaptureCookieLength = 1024
+
+	// defaultSyslogFacility is the syslog facility that is used if the
+	// syslog destination does not specify one.
+	defaultSyslogFacility = "local1"
+
+	// minSyslogMaxLength and maxSyslogMaxLength bound the maximum length
+	// of a syslog message.  If the syslog destination does not specify a
+	// maximum length, the router uses HAProxy's default of 1024 bytes.
+	minSyslogMaxLength = 480
+	maxSyslogMaxLength = 4096
+
+	// logsContainerName is the name of the sidecar container that receives
+	// access logs for the Container destination.
+	logsContainerName = "logs"
+	// rsyslogSocketVolumeName and rsyslogSocketDir are the volume and
+	// directory for the socket over which the router sends access logs to
+	// the sidecar.
+	rsyslogSocketVolumeName = "rsyslog-socket"
+	rsyslogSocketDir        = "/var/lib/rsyslog"
+	// rsyslogConfigVolumeName and rsyslogConfigDir are the volume and
+	// directory for the sidecar's rsyslog configuration.
+	rsyslogConfigVolumeName = "rsyslog-config"
+	rsyslogConfigDir        = "/etc/rsyslog"
+	// rsyslogConfigKey is the key of the rsyslog configuration in the
+	// rsyslog configmap.
+	rsyslogConfigKey = "rsyslog.conf"
+	// rsyslogRotateScriptKey is the key of the script that rotates the
+	// access log file in the rsyslog configmap.
+	rsyslogRotateScriptKey = "rotate.sh"
+	// accessLogsVolumeName and accessLogsDir are the volume and directory
+	// for the access log file if the Container destination limits the size
+	// of the access log.
+	accessLogsVolumeName = "access-logs"
+	accessLogsDir        = "/var/log/router"
+
+	// defaultMaxLogFiles is the number of rotated access log files that
+	// are kept if the Container destination does not specify a number.
+	defaultMaxLogFiles = 1
+	// maxMaxLogFiles is the maximum number of rotated access log files
+	// that may be kept.
+	maxMaxLogFiles = 10
+)
+
+// rsyslogConfigFor returns the configuration for the sidecar's rsyslog, which
+// reads access logs from the socket and writes them to standard output or, if
+// the size of the access log is limited, to a file that is rotated when it
+// reaches the limit.  The socket's input is bound to a ruleset with exactly
+// one action, so each access log message is written once, to the one sink,
+// and rsyslog's own messages never reach it.  The input is not rate limited,
+// so rsyslog does not drop access logs under load, and rsyslog does not open
+// the system log socket, which does not exist in the container.
+func rsyslogConfigFor(params *ContainerLoggingDestinationParameters) string {
+	action := `action(type="omstdout" template="RSYSLOG_FileFormat")`
+	if params != nil && len(params.MaxLogSize) != 0 {
+		// validateAccessLogging has already parsed the size.
+		maxLogSize := resource.MustParse(params.MaxLogSize)
+		action = fmt.Sprintf(`action(type="omfile" file="%s/access.log" template="RSYSLOG_FileFormat" rotation.sizeLimit="%d" rotation.sizeLimitCommand="/bin/sh %s/%s")`, accessLogsDir, maxLogSize.Value(), rsyslogConfigDir, rsyslogRotateScriptKey)
+	}
+	return fmt.Sprintf(`module(load="imuxsock" SysSock.Use="off")
+module(load="omstdout")
+input(type="imuxsock" Socket="%s/rsyslog.sock" RateLimit.Interval="0" Ruleset="access_log")
+ruleset(name="access_log") {
+  %s
+  stop
+}
+`, rsyslogSocketDir, action)
+}
+
+// rsyslogRotateScriptFor returns the script with which rsyslog rotates the
+// access log file, keeping the given number of rotated files.
+func rsyslogRotateScriptFor(maxLogFiles int) string {
+	return fmt.Sprintf(`#!/bin/sh
+log=%s/access.log
+i=%d
+while [ "$i" -gt 1 ]; do
+  prev=$((i - 1))
+  if [ -f "$log.$prev" ]; then mv -f "$log.$prev" "$log.$i"; fi
+  i=$prev
+done
+mv -f "$log" "$log.1"
+`, accessLogsDir, maxLogFiles)
+}
+
+// syslogFacilities are the valid syslog facilities.
+var syslogFacilities = map[string]bool{
+	"kern": true, "user": true, "mail": true, "daemon": true, "auth": true,
+	"syslog": true, "lpr": true, "news": true, "uucp": true, "cron": true,
+	"auth2": true, "ftp": true, "ntp": true, "audit": true, "alert": true,
+	"cron2": true, "local0": true, "local1": true, "local2": true,
+	"local3": true, "local4": true, "local5": true, "local6": true,
+	"local7": true,
+}
+
+// httpTokenRegexp matches a valid HTTP header or cookie name (an RFC 7230
+// token).
+var httpTokenRegexp = regexp.MustCompile("^[-!#$%&'*+.0-9A-Z^_`a-z|~]+$")
+
+// AccessLogging describes how an ingresscontroller logs requests.
+type AccessLogging struct {
+	// Destination is where access logs are sent.
+	Destination LoggingDestination `json:"destination"`
+
+	// LogEmptyRequests specifies whether connections that are closed
+	// before any request is received are logged.  These are typically
+	// load balancer health probes.  The value is "Log" (the default) or
+	// "Ignore".
+	LogEmptyRequests string `json:"logEmptyRequests,omitempty"`
+
+	// HTTPCaptureHeaders specifies which HTTP headers are captured in
+	// access logs.
+	HTTPCaptureHeaders CaptureHTTPHeaders `json:"httpCaptureHeaders,omitempty"`
+
+	// HTTPCaptureCookies specifies which HTTP cookie is captured in access
+	// logs.  At most one cookie may be captured.
+	HTTPCaptureCookies []CaptureHTTPCookie `json:"httpCaptureCookies,omitempty"`
+}
+
+// CaptureHTTPHeaders describes the request and response headers that are
+// captured in access logs.
+type CaptureHTTPHeaders struct {
+	// Request lists the request headers to capture.
+	Request []CaptureHTTPHeader `json:"request,omitempty"`
+
+	// Response lists the response headers to capture.
+	Response []CaptureHTTPHeader `json:"response,omitempty"`
+}
+
+// CaptureHTTPHeader describes an HTTP header to capture.
+type CaptureHTTPHeader struct {
+	// Name is the name of the header.
+	Name string `json:"name"`
+
+	// MaxLength is the maximum number of characters of the header's value
+	// to capture.
+	MaxLength int `json:"maxLength"`
+}
+
+// CaptureHTTPCookie describes an HTTP cookie to capture.
+type CaptureHTTPCookie struct {
+	// MatchType specifies whether the cookie's name must match Name
+	// exactly ("Exact") or start with NamePrefix ("Prefix").
+	MatchType string `json:"matchType"`
+
+	// Name is the name of the cookie for the Exact match type.
+	Name string `json:"name,omitempty"`
+
+	// NamePrefix is the prefix of the cookie's name for the Prefix match
+	// type.
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// MaxLength is the maximum number of characters of the cookie to
+	// capture, from 1 to 1024.
+	MaxLength int `json:"maxLength"`
+}
+
+// LoggingDestination describes a destination for access logs.
+type LoggingDestination struct {
+	// Type is the type of the destination, "Container" or "Syslog".
+	Type string `json:"type"`
+
+	// Syslog holds the parameters of a Syslog destination.
+	Syslog *SyslogLoggingDestinationParameters `json:"syslog,omitempty"`
+
+	// Container holds the parameters of a Container destination.
+	Container *ContainerLoggingDestinationParameters `json:"container,omitempty"`
+}
+
+// SyslogLoggingDestinationParameters describes a syslog endpoint.
+type SyslogLoggingDestinationParameters struct {
+	// Address is the IP address of the syslog endpoint.
+	Address string `json:"address"`
+
+	// Port is the UDP port of the syslog endpoint.
+	Port uint32 `json:"port"`
+
+	// Facility is the syslog facility of the log messages.  The default
+	// is "local1".
+	Facility string `json:"facility,omitempty"`
+
+	// MaxLength is the maximum length in bytes of a syslog message, from
+	// 480 to 4096.  Longer messages are truncated.  The default is 1024.
+	MaxLength uint32 `json:"maxLength,omitempty"`
+}
+
+// ContainerLoggingDestinationParameters describes a Container destination.
+type ContainerLoggingDestinationParameters struct {
+	// MaxLogSize is the maximum size of the access log, as a resource
+	// quantity such as "100Mi".  If it is set, the sidecar writes access
+	// logs to a file in the pod's ephemeral storage, which is rotated when
+	// it reaches this size, rather than to its standard output.
+	MaxLogSize string `json:"maxLogSize,omitempty"`
+
+	// MaxLogFiles is the number of rotated access log files to keep, from
+	// 1 to 10.  The default is 1.  It may only be set with MaxLogSize.
+	MaxLogFiles int `json:"maxLogFiles,omitempty"`
+
+	// Resources are the compute resource requirements of the sidecar.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// maxLogFiles returns the number of rotated access log files to keep.
+func (p *ContainerLoggingDestinationParameters) maxLogFiles() int {
+	if p.MaxLogFiles == 0 {
+		return defaultMaxLogFiles
+	}
+	return p.MaxLogFiles
+}
+
+// accessLoggingFor returns the access logging configuration of the given
+// ingresscontroller, or nil if access logging is disabled.
+func accessLoggingFor(ic *operatorv1.IngressController) (*AccessLogging, error) {
+	value, ok := ic.Annotations[AccessLoggingAnnotation]
+	if !ok || len(value) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	logging := &AccessLogging{}
+	if err := decoder.Decode(logging); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", AccessLoggingAnnotation, err)
+	}
+	if err := validateAccessLogging(logging); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", AccessLoggingAnnotation, err)
+	}
+	return logging, nil
+}
+
+// validateAccessLogging returns an error if the given access logging
+// configuration is not valid.
+func validateAccessLogging(logging *AccessLogging) error {
+	switch logging.LogEmptyRequests {
+	case "", LoggingPolicyLog, LoggingPolicyIgnore:
+	default:
+		return fmt.Errorf("logEmptyRequests must be %s or %s, got %q", LoggingPolicyLog, LoggingPolicyIgnore, logging.LogEmptyRequests)
+	}
+	if err := validateCaptureHTTPHeaders("httpCaptureHeaders.request", logging.HTTPCaptureHeaders.Request); err != nil {
+		return err
+	}
+	if err := validateCaptureHTTPHeaders("httpCaptureHeaders.response", logging.HTTPCaptureHeaders.Response); err != nil {
+		return err
+	}
+	if len(logging.HTTPCaptureCookies) > 1 {
+		return fmt.Errorf("httpCaptureCookies may have at most 1 item, got %d", len(logging.HTTPCaptureCookies))
+	}
+	for _, cookie := range logging.HTTPCaptureCookies {
+		if err := validateCaptureHTTPCookie(cookie); err != nil {
+			return err
+		}
+	}
+	switch logging.Destination.Type {
+	case ContainerLoggingDestinationType:
+		if logging.Destination.Syslog != nil {
+			return fmt.Errorf("destination.syslog may only be specified for the %s destination type", SyslogLoggingDestinationType)
+		}
+		if container := logging.Destination.Container; container != nil {
+			if len(container.MaxLogSize) != 0 {
+				size, err := resource.ParseQuantity(container.MaxLogSize)
+				if err != nil {
+					return fmt.Errorf("destination.container.maxLogSize %q is not a valid quantity: %v", container.MaxLogSize, err)
+				}
+				if size.Value() < 1 {
+					return fmt.Errorf("destination.container.maxLogSize must be positive, got %q", container.MaxLogSize)
+				}
+			} else if container.MaxLogFiles != 0 {
+				return fmt.Errorf("destination.container.maxLogFiles may only be specified with destination.container.maxLogSize")
+			}
+			if container.MaxLogFiles < 0 || container.MaxLogFiles > maxMaxLogFiles {
+				return fmt.Errorf("destination.container.maxLogFiles must be from 1 to %d, got %d", maxMaxLogFiles, container.MaxLogFiles)
+			}
+		}
+	case SyslogLoggingDestinationType:
+		syslog := logging.Destination.Syslog
+		if syslog == nil {
+			return fmt.Errorf("destination.syslog is required for the %s destination type", SyslogLoggingDestinationType)
+		}
+		if logging.Destination.Container != nil {
+			return fmt.Errorf("destination.container may only be specified for the %s destination type", ContainerLoggingDestinationType)
+		}
+		if net.ParseIP(syslog.Address) == nil {
+			return fmt.Errorf("destination.syslog.address must be an IP address, got %q", syslog.Address)
+		}
+		if syslog.Port < 1 || syslog.Port > 65535 {
+			return fmt.Errorf("destination.syslog.port must be from 1 to 65535, got %d", syslog.Port)
+		}
+		if len(syslog.Facility) != 0 && !syslogFacilities[syslog.Facility] {
+			return fmt.Errorf("destination.syslog.facility %q is not a valid syslog facility", syslog.Facility)
+		}
+		if syslog.MaxLength != 0 && (syslog.MaxLength < minSyslogMaxLength || syslog.MaxLength > maxSyslogMaxLength) {
+			return fmt.Errorf("destination.syslog.maxLength must be from %d to %d, got %d", minSyslogMaxLength, maxSyslogMaxLength, syslog.MaxLength)
+		}
+	default:
+		return fmt.Errorf("destination.type must be %s or %s, got %q", ContainerLoggingDestinationType, SyslogLoggingDestinationType, logging.Destination.Type)
+	}
+	return nil
+}
+
+// validateCaptureHTTPHeaders returns an error if any of the given header
+// captures, specified by the named field, is not valid.
+func validateCaptureHTTPHeaders(field string, headers []CaptureHTTPHeader) error {
+	for i, header := range headers {
+		if !httpTokenRegexp.MatchString(header.Name) {
+			return fmt.Errorf("%s[%d].name %q is not a valid HTTP header name", field, i, header.Name)
+		}
+		if header.MaxLength < 1 {
+			return fmt.Errorf("%s[%d].maxLength must be at least 1, got %d", field, i, header.MaxLength)
+		}
+	}
+	return nil
+}
+
+// validateCaptureHTTPCookie returns an error if the given cookie capture is
+// not valid.
+func validateCaptureHTTPCookie(cookie CaptureHTTPCookie) error {
+	switch cookie.MatchType {
+	case CookieMatchTypeExact:
+		if len(cookie.NamePrefix) != 0 {
+			return fmt.Errorf("httpCaptureCookies[0].namePrefix may only be specified for the %s match type", CookieMatchTypePrefix)
+		}
+		if !httpTokenRegexp.MatchString(cookie.Name) {
+			return fmt.Errorf("httpCaptureCookies[0].name %q is not a valid cookie name", cookie.Name)
+		}
+	case CookieMatchTypePrefix:
+		if len(cookie.Name) != 0 {
+			return fmt.Errorf("httpCaptureCookies[0].name may only be specified for the %s match type", CookieMatchTypeExact)
+		}
+		if !httpTokenRegexp.MatchString(cookie.NamePrefix) {
+			return fmt.Errorf("httpCaptureCookies[0].namePrefix %q is not a valid cookie name prefix", cookie.NamePrefix)
+		}
+	default:
+		return fmt.Errorf("httpCaptureCookies[0].matchType must be %s or %s, got %q", CookieMatchTypeExact, CookieMatchTypePrefix, cookie.MatchType)
+	}
+	if cookie.MaxLength < 1 || cookie.MaxLength > maxCaptureCookieLength {
+		return fmt.Errorf("httpCaptureCookies[0].maxLength must be from 1 to %d, got %d", maxCaptureCookieLength, cookie.MaxLength)
+	}
+	return nil
+}
+
+// serializeCaptureHTTPHeaders returns the given header captures in the format
+// that the router expects: a comma-separated list of name:maxLength pairs.
+func serializeCaptureHTTPHeaders(headers []CaptureHTTPHeader) string {
+	pairs := make([]string, len(headers))
+	for i, header := range headers {
+		pairs[i] = fmt.Sprintf("%s:%d", header.Name, header.MaxLength)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// configureAccessLogging configures the given router deployment to log
+// requests as specified by the given access logging configuration.  For the
+// Container destination, a sidecar container that receives the logs is added
+// to the deployment.
+func configureAccessLogging(deployment *appsv1.Deployment, ic *operatorv1.IngressController, logging *AccessLogging, ingressControllerImage string) {
+	if logging == nil {
+		return
+	}
+	podSpec := &deployment.Spec.Template.Spec
+	router := &podSpec.Containers[0]
+
+	switch logging.Destination.Type {
+	case ContainerLoggingDestinationType:
+		podSpec.Volumes = append(podSpec.Volumes,
+			corev1.Volume{
+				Name:         rsyslogSocketVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			},
+			corev1.Volume{
+				Name: rsyslogConfigVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: RsyslogConfigMapName(ic, deployment.Namespace).Name},
+					},
+				},
+			},
+		)
+		socketMount := corev1.VolumeMount{Name: rsyslogSocketVolumeName, MountPath: rsyslogSocketDir}
+		router.VolumeMounts = append(router.VolumeMounts, socketMount)
+		router.Env = append(router.Env, corev1.EnvVar{Name: "ROUTER_SYSLOG_ADDRESS", Value: rsyslogSocketDir + "/rsyslog.sock"})
+		sidecar := corev1.Container{
+			Name:    logsContainerName,
+			Image:   ingressControllerImage,
+			Command: []string{"/sbin/rsyslogd", "-n", "-i", "/tmp/rsyslog.pid", "-f", rsyslogConfigDir + "/" + rsyslogConfigKey},
+			VolumeMounts: []corev1.VolumeMount{
+				socketMount,
+				{Name: rsyslogConfigVolumeName, MountPath: rsyslogConfigDir},
+			},
+			// Set the fields that the API defaults so that
+			// comparing the sidecar with the current sidecar does
+			// not detect spurious changes.
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		}
+		if params := logging.Destination.Container; params != nil {
+			if len(params.MaxLogSize) != 0 {
+				// The volume must hold the current log file
+				// and the rotated files.
+				size := resource.MustParse(params.MaxLogSize)
+				limit := resource.NewQuantity(size.Value()*int64(params.maxLogFiles()+1), resource.BinarySI)
+				podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+					Name:         accessLogsVolumeName,
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: limit}},
+				})
+				sidecar.VolumeMounts = append(sidecar.VolumeMounts, corev1.VolumeMount{Name: accessLogsVolumeName, MountPath: accessLogsDir})
+			}
+			if params.Resources != nil {
+				sidecar.Resources = *params.Resources
+			}
+		}
+		podSpec.Containers = append(podSpec.Containers, sidecar)
+		// Appending the sidecar may have reallocated the containers.
+		router = &podSpec.Containers[0]
+	case SyslogLoggingDestinationType:
+		syslog := logging.Destination.Syslog
+		facility := syslog.Facility
+		if len(facility) == 0 {
+			facility = defaultSyslogFacility
+		}
+		router.Env = append(router.Env,
+			corev1.EnvVar{Name: "ROUTER_SYSLOG_ADDRESS", Value: net.JoinHostPort(syslog.Address, strconv.Itoa(int(syslog.Port)))},
+			corev1.EnvVar{Name: "ROUTER_LOG_FACILITY", Value: facility},
+		)
+		if syslog.MaxLength != 0 {
+			router.Env = append(router.Env, corev1.EnvVar{Name: "ROUTER_LOG_MAX_LENGTH", Value: strconv.Itoa(int(syslog.MaxLength))})
+		}
+	}
+
+	if logging.LogEmptyRequests == LoggingPolicyIgnore {
+		// Sets HAProxy's "option dontlognull".
+		router.Env = append(router.Env, corev1.EnvVar{Name: "ROUTER_DONT_LOG_NULL", Value: "true"})
+	}
+
+	if headers := logging.HTTPCaptureHeaders.Request; len(headers) != 0 {
+		router.Env = append(router.Env, corev1.EnvVar{Name: "ROUTER_CAPTURE_HTTP_REQUEST_HEADERS", Value: serializeCaptureHTTPHeaders(headers)})
+	}
+	if headers := logging.HTTPCaptureHeaders.Response; len(headers) != 0 {
+		router.Env = append(router.Env, corev1.EnvVar{Name: "ROUTER_CAPTURE_HTTP_RESPONSE_HEADERS", Value: serializeCaptureHTTPHeaders(headers)})
+	}
+	if len(logging.HTTPCaptureCookies) != 0 {
+		// The router matches the cookie by prefix, so an exact match
+		// is expressed as the name followed by "=".
+		cookie := logging.HTTPCaptureCookies[0]
+		name := cookie.NamePrefix
+		if cookie.MatchType == CookieMatchTypeExact {
+			name = cookie.Name + "="
+		}
+		router.Env = append(router.Env, corev1.EnvVar{Name: "ROUTER_CAPTURE_HTTP_COOKIE", Value: fmt.Sprintf("%s:%d", name, cookie.MaxLength)})
+	}
+}
+
+// desiredRsyslogConfigMap returns the desired configmap with the rsyslog
+// configuration for the access log sidecar of the given ingresscontroller, or
+// nil if the ingresscontroller does not log to a container.
+func desiredRsyslogConfigMap(ic *operatorv1.IngressController, operandNamespace string, logging *AccessLogging, deploymentRef metav1.OwnerReference) *corev1.ConfigMap {
+	if logging == nil || logging.Destination.Type != ContainerLoggingDestinationType {
+		return nil
+	}
+	params := logging.Destination.Container
+	data := map[string]string{
+		rsyslogConfigKey: rsyslogConfigFor(params),
+	}
+	if params != nil && len(params.MaxLogSize) != 0 {
+		data[rsyslogRotateScriptKey] = rsyslogRotateScriptFor(params.maxLogFiles())
+	}
+	name := RsyslogConfigMapName(ic, operandNamespace)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{deploymentRef},
+		},
+		Data: data,
+	}
+}
+
+// ensureRsyslogConfigMap creates, updates, or deletes the rsyslog configmap
+// for the given ingresscontroller as appropriate.
+func (r *reconciler) ensureRsyslogConfigMap(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) error {
+	logging, err := accessLoggingFor(ic)
+	if err != nil {
+		return err
+	}
+	desired := desiredRsyslogConfigMap(ic, r.Config.OperandNamespace, logging, deploymentRef)
+	name := RsyslogConfigMapName(ic, r.Config.OperandNamespace)
+	current := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get configmap %s: %v", name, err)
+		}
+		current = nil
+	}
+	switch {
+	case desired == nil && current != nil:
+		if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete configmap %s: %v", name, err)
+		}
+		log.Info("deleted configmap", "namespace", name.Namespace, "name", name.Name)
+	case desired != nil && current == nil:
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create configmap %s: %v", name, err)
+		}
+		log.Info("created configmap", "namespace", name.Namespace, "name", name.Name)
+	case desired != nil && current != nil && !reflect.DeepEqual(current.Data, desired.Data):
+		updated := current.DeepCopy()
+		updated.Data = desired.Data
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update configmap %s: %v", name, err)
+		}
+		log.Info("updated configmap", "namespace", name.Namespace, "name", name.Name)
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAccessLoggingFor(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       string
+		expectNil   bool
+		expectErr   bool
+	}{
+		{"no annotation", "", true, false},
+		{"container destination", `{"destination":{"type":"Container"}}`, false, false},
+		{"container destination ignoring empty requests", `{"destination":{"type":"Container"},"logEmptyRequests":"Ignore"}`, false, false},
+		{"container destination with log rotation", `{"destination":{"type":"Container","container":{"maxLogSize":"100Mi","maxLogFiles":3}}}`, false, false},
+		{"container destination with sidecar resources", `{"destination":{"type":"Container","container":{"resources":{"requests":{"cpu":"10m","memory":"32Mi"}}}}}`, false, false},
+		{"container destination with invalid maxLogSize", `{"destination":{"type":"Container","container":{"maxLogSize":"lots"}}}`, false, true},
+		{"container destination with zero maxLogSize", `{"destination":{"type":"Container","container":{"maxLogSize":"0"}}}`, false, true},
+		{"container destination with maxLogFiles but no maxLogSize", `{"destination":{"type":"Container","container":{"maxLogFiles":2}}}`, false, true},
+		{"container destination with too many maxLogFiles", `{"destination":{"type":"Container","container":{"maxLogSize":"1Mi","maxLogFiles":11}}}`, false, true},
+		{"syslog destination", `{"destination":{"type":"Syslog","syslog":{"address":"1.2.3.4","port":514,"facility":"local2"}}}`, false, false},
+		{"syslog destination with IPv6 address", `{"destination":{"type":"Syslog","syslog":{"address":"2001:db8::1","port":514}}}`, false, false},
+		{"syslog destination with maxLength", `{"destination":{"type":"Syslog","syslog":{"address":"1.2.3.4","port":514,"maxLength":4096}}}`, false, false},
+		{"syslog destination with too small maxLength", `{"destination":{"type":"Syslog","syslog":{"address":"1.2.3.4","port":514,"maxLength":479}}}`, false, true},
+		{"syslog destination with too large maxLength", `{"destination":{"type":"Syslog","syslog":{"address":"1.2.3.4","port":514,"maxLength":4097}}}`, false, true},
+		{"syslog destination without parameters", `{"destination":{"type":"Syslog"}}`, false, true},
+		{"syslog destination with hostname", `{"destination":{"type":"Syslog","syslog":{"address":"syslog.example.com","port":514}}}`, false, true},
+		{"syslog destination without port", `{"destination":{"type":"Syslog","syslog":{"address":"1.2.3.4"}}}`, false, true},
+		{"syslog destination with invalid facility", `{"destination":{"type":"Syslog","syslog":{"address":"1.2.3.4","port":514,"facility":"bogus"}}}`, false, true},
+		{"unknown destination type", `{"destination":{"type":"File"}}`, false, true},
+		{"invalid logEmptyRequests", `{"destination":{"type":"Container"},"logEmptyRequests":"Maybe"}`, false, true},
+		{"header capture", `{"destination":{"type":"Container"},"httpCaptureHeaders":{"request":[{"name":"Host","maxLength":90}],"response":[{"name":"Content-Type","maxLength":20}]}}`, false, false},
+		{"header capture with invalid name", `{"destination":{"type":"Container"},"httpCaptureHeaders":{"request":[{"name":"Bad Header","maxLength":90}]}}`, false, true},
+		{"header capture without maxLength", `{"destination":{"type":"Container"},"httpCaptureHeaders":{"response":[{"name":"Host"}]}}`, false, true},
+		{"exact cookie capture", `{"destination":{"type":"Container"},"httpCaptureCookies":[{"matchType":"Exact","name":"session","maxLength":100}]}`, false, false},
+		{"prefix cookie capture", `{"destination":{"type":"Container"},"httpCaptureCookies":[{"matchType":"Prefix","namePrefix":"sess","maxLength":1024}]}`, false, false},
+		{"exact cookie capture with prefix", `{"destination":{"type":"Container"},"httpCaptureCookies":[{"matchType":"Exact","name":"session","namePrefix":"sess","maxLength":100}]}`, false, true},
+		{"cookie capture without match type", `{"destination":{"type":"Container"},"httpCaptureCookies":[{"name":"session","maxLength":100}]}`, false, true},
+		{"cookie capture with too large maxLength", `{"destination":{"type":"Container"},"httpCaptureCookies":[{"matchType":"Exact","name":"session","maxLength":1025}]}`, false, true},
+		{"multiple cookie captures", `{"destination":{"type":"Container"},"httpCaptureCookies":[{"matchType":"Exact","name":"a","maxLength":1},{"matchType":"Exact","name":"b","maxLength":1}]}`, false, true},
+		{"unknown field", `{"destination":{"type":"Container"},"format":"json"}`, false, true},
+		{"invalid JSON", `{"destination":`, false, true},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(AccessLoggingAnnotation, tc.value)
+		logging, err := accessLoggingFor(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if tc.expectNil != (logging == nil) {
+			t.Errorf("%q: expected nil to be %t, got %+v", tc.description, tc.expectNil, logging)
+		}
+	}
+}
+
+func newTestRouterDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "router"}},
+				},
+			},
+		},
+	}
+}
+
+func envValue(container *corev1.Container, name string) (string, bool) {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestConfigureAccessLogging(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	image := "quay.io/openshift/router:latest"
+
+	deployment := newTestRouterDeployment()
+	configureAccessLogging(deployment, ic, nil, image)
+	if len(deployment.Spec.Template.Spec.Containers) != 1 || len(deployment.Spec.Template.Spec.Containers[0].Env) != 0 {
+		t.Errorf("expected no changes when access logging is disabled, got %+v", deployment.Spec.Template.Spec)
+	}
+
+	deployment = newTestRouterDeployment()
+	configureAccessLogging(deployment, ic, &AccessLogging{
+		Destination:      LoggingDestination{Type: ContainerLoggingDestinationType},
+		LogEmptyRequests: LoggingPolicyIgnore,
+	}, image)
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) != 2 || containers[1].Name != logsContainerName || containers[1].Image != image {
+		t.Fatalf("expected a logs sidecar, got %+v", containers)
+	}
+	if value, _ := envValue(&containers[0], "ROUTER_SYSLOG_ADDRESS"); value != "/var/lib/rsyslog/rsyslog.sock" {
+		t.Errorf("expected the router to log to the sidecar's socket, got %q", value)
+	}
+	if value, _ := envValue(&containers[0], "ROUTER_DONT_LOG_NULL"); value != "true" {
+		t.Errorf("expected ROUTER_DONT_LOG_NULL to be true, got %q", value)
+	}
+	if len(deployment.Spec.Template.Spec.Volumes) != 2 {
+		t.Errorf("expected socket and config volumes, got %+v", deployment.Spec.Template.Spec.Volumes)
+	}
+
+	deployment = newTestRouterDeployment()
+	configureAccessLogging(deployment, ic, &AccessLogging{
+		Destination: LoggingDestination{
+			Type:   SyslogLoggingDestinationType,
+			Syslog: &SyslogLoggingDestinationParameters{Address: "2001:db8::1", Port: 514, MaxLength: 2048},
+		},
+	}, image)
+	containers = deployment.Spec.Template.Spec.Containers
+	if len(containers) != 1 {
+		t.Errorf("expected no sidecar for the syslog destination, got %+v", containers)
+	}
+	if value, _ := envValue(&containers[0], "ROUTER_SYSLOG_ADDRESS"); value != "[2001:db8::1]:514" {
+		t.Errorf("unexpected ROUTER_SYSLOG_ADDRESS %q", value)
+	}
+	if value, _ := envValue(&containers[0], "ROUTER_LOG_FACILITY"); value != defaultSyslogFacility {
+		t.Errorf("expected the default facility, got %q", value)
+	}
+	if value, _ := envValue(&containers[0], "ROUTER_LOG_MAX_LENGTH"); value != "2048" {
+		t.Errorf("expected ROUTER_LOG_MAX_LENGTH to be 2048, got %q", value)
+	}
+	if _, ok := envValue(&containers[0], "ROUTER_DONT_LOG_NULL"); ok {
+		t.Error("expected ROUTER_DONT_LOG_NULL not to be set")
+	}
+
+	deployment = newTestRouterDeployment()
+	configureAccessLogging(deployment, ic, &AccessLogging{
+		Destination: LoggingDestination{Type: ContainerLoggingDestinationType},
+		HTTPCaptureHeaders: CaptureHTTPHeaders{
+			Request:  []CaptureHTTPHeader{{Name: "Host", MaxLength: 90}, {Name: "Referer", MaxLength: 20}},
+			Response: []CaptureHTTPHeader{{Name: "Location", MaxLength: 50}},
+		},
+		HTTPCaptureCookies: []CaptureHTTPCookie{{MatchType: CookieMatchTypeExact, Name: "session", MaxLength: 100}},
+	}, image)
+	router := &deployment.Spec.Template.Spec.Containers[0]
+	expectedEnv := map[string]string{
+		"ROUTER_CAPTURE_HTTP_REQUEST_HEADERS":  "Host:90,Referer:20",
+		"ROUTER_CAPTURE_HTTP_RESPONSE_HEADERS": "Location:50",
+		"ROUTER_CAPTURE_HTTP_COOKIE":           "session=:100",
+	}
+	for name, expected := range expectedEnv {
+		if value, _ := envValue(router, name); value != expected {
+			t.Errorf("expected %s to be %q, got %q", name, expected, value)
+		}
+	}
+}
+
+func TestConfigureAccessLoggingWithLogRotation(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	logging := &AccessLogging{
+		Destination: LoggingDestination{
+			Type: ContainerLoggingDestinationType,
+			Container: &ContainerLoggingDestinationParameters{
+				MaxLogSize:  "10Mi",
+				MaxLogFiles: 2,
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+				},
+			},
+		},
+	}
+
+	deployment := newTestRouterDeployment()
+	configureAccessLogging(deployment, ic, logging, "quay.io/openshift/router:latest")
+	var logsVolume *corev1.Volume
+	for i, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == accessLogsVolumeName {
+			logsVolume = &deployment.Spec.Template.Spec.Volumes[i]
+		}
+	}
+	if logsVolume == nil || logsVolume.EmptyDir == nil || logsVolume.EmptyDir.SizeLimit == nil {
+		t.Fatalf("expected an access logs volume with a size limit, got %+v", deployment.Spec.Template.Spec.Volumes)
+	}
+	if expected := resource.MustParse("30Mi"); logsVolume.EmptyDir.SizeLimit.Cmp(expected) != 0 {
+		t.Errorf("expected size limit %s, got %s", expected.String(), logsVolume.EmptyDir.SizeLimit.String())
+	}
+	sidecar := deployment.Spec.Template.Spec.Containers[1]
+	if len(sidecar.VolumeMounts) != 3 || sidecar.VolumeMounts[2].MountPath != accessLogsDir {
+		t.Errorf("expected the sidecar to mount the access logs volume, got %+v", sidecar.VolumeMounts)
+	}
+	if cpu := sidecar.Resources.Requests[corev1.ResourceCPU]; cpu.String() != "10m" {
+		t.Errorf("expected the sidecar to request 10m CPU, got %+v", sidecar.Resources)
+	}
+
+	cm := desiredRsyslogConfigMap(ic, DefaultOperandNamespace, logging, metav1.OwnerReference{})
+	if cm == nil {
+		t.Fatal("expected an rsyslog configmap")
+	}
+	if config := cm.Data[rsyslogConfigKey]; !strings.Contains(config, `rotation.sizeLimit="10485760"`) {
+		t.Errorf("expected rsyslog to rotate the access log at 10Mi, got %q", config)
+	}
+	if script := cm.Data[rsyslogRotateScriptKey]; !strings.Contains(script, "i=2\n") {
+		t.Errorf("expected the rotate script to keep 2 files, got %q", script)
+	}
+
+	logging.Destination.Container = nil
+	cm = desiredRsyslogConfigMap(ic, DefaultOperandNamespace, logging, metav1.OwnerReference{})
+	if _, ok := cm.Data[rsyslogRotateScriptKey]; ok {
+		t.Error("expected no rotate script without maxLogSize")
+	}
+	if config := cm.Data[rsyslogConfigKey]; !strings.Contains(config, `type="omstdout"`) {
+		t.Errorf("expected rsyslog to log to standard output, got %q", config)
+	}
+}
+
+func TestRsyslogConfigFor(t *testing.T) {
+	testCases := []struct {
+		description string
+		params      *ContainerLoggingDestinationParameters
+		expect      string
+	}{
+		{
+			description: "standard output",
+			expect: `module(load="imuxsock" SysSock.Use="off")
+module(load="omstdout")
+input(type="imuxsock" Socket="/var/lib/rsyslog/rsyslog.sock" RateLimit.Interval="0" Ruleset="access_log")
+ruleset(name="access_log") {
+  action(type="omstdout" template="RSYSLOG_FileFormat")
+  stop
+}
+`,
+		},
+		{
+			description: "standard output with resources",
+			params:      &ContainerLoggingDestinationParameters{Resources: &corev1.ResourceRequirements{}},
+			expect: `module(load="imuxsock" SysSock.Use="off")
+module(load="omstdout")
+input(type="imuxsock" Socket="/var/lib/rsyslog/rsyslog.sock" RateLimit.Interval="0" Ruleset="access_log")
+ruleset(name="access_log") {
+  action(type="omstdout" template="RSYSLOG_FileFormat")
+  stop
+}
+`,
+		},
+		{
+			description: "rotated file",
+			params:      &ContainerLoggingDestinationParameters{MaxLogSize: "1Mi"},
+			expect: `module(load="imuxsock" SysSock.Use="off")
+module(load="omstdout")
+input(type="imuxsock" Socket="/var/lib/rsyslog/rsyslog.sock" RateLimit.Interval="0" Ruleset="access_log")
+ruleset(name="access_log") {
+  action(type="omfile" file="/var/log/router/access.log" template="RSYSLOG_FileFormat" rotation.sizeLimit="1048576" rotation.sizeLimitCommand="/bin/sh /etc/rsyslog/rotate.sh")
+  stop
+}
+`,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := rsyslogConfigFor(tc.params); actual != tc.expect {
+			t.Errorf("%q: expected:\n%s\ngot:\n%s", tc.description, tc.expect, actual)
+		}
+	}
+}
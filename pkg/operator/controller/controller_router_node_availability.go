@@ -0,0 +1,82 @@
+package controller
+
+import (
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// RouterNodeSelectorMatches returns true if the given ingresscontroller uses
+// the HostNetwork endpoint publishing strategy and its router node selector
+// matches the given node.  A change to such a node's schedulability or
+// readiness affects the ingresscontroller's availability right away, because
+// each of its routers is bound to the ports of the node that runs it.
+func RouterNodeSelectorMatches(ic *operatorv1.IngressController, node *corev1.Node) bool {
+	if !usesHostNetwork(ic) {
+		return false
+	}
+	nodeSelector, err := routerNodeSelector(ic)
+	if err != nil {
+		return false
+	}
+	return labels.SelectorFromSet(nodeSelector).Matches(labels.Set(node.Labels))
+}
+
+// routerDeploymentForStatus returns the given router workload with its
+// available replicas adjusted for the nodes that run them, for use in
+// computing the status of the given ingresscontroller.  A router pod on a node
+// that is not ready stays ready and available in the workload's status until
+// the node lifecycle controller notices that the node is unreachable and then
+// evicts the pod, which takes minutes.  A HostNetwork router on such a node
+// cannot serve its node's ports, so it is not counted as available.  The given
+// workload is returned unchanged for other ingresscontrollers.
+func (r *reconciler) routerDeploymentForStatus(ic *operatorv1.IngressController, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+	if !usesHostNetwork(ic) {
+		return deployment, nil
+	}
+	pods, err := r.cachedRouterPods(ic)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := r.cachedRouterNodes(pods)
+	if err != nil {
+		return nil, err
+	}
+	return withAvailableReplicasOnReadyNodes(deployment, pods, nodes), nil
+}
+
+// withAvailableReplicasOnReadyNodes returns the given router workload, or a
+// copy of it with fewer available replicas if fewer of the given router pods
+// are ready on ready nodes among the given nodes than the workload reports.
+func withAvailableReplicasOnReadyNodes(deployment *appsv1.Deployment, pods []corev1.Pod, nodes []corev1.Node) *appsv1.Deployment {
+	available := readyRouterPodsOnReadyNodes(pods, nodes)
+	if available >= deployment.Status.AvailableReplicas {
+		return deployment
+	}
+	log.Info("router pods are on nodes that are not ready", "namespace", deployment.Namespace, "name", deployment.Name, "available", deployment.Status.AvailableReplicas, "availableOnReadyNodes", available)
+	adjusted := deployment.DeepCopy()
+	adjusted.Status.AvailableReplicas = available
+	return adjusted
+}
+
+// readyRouterPodsOnReadyNodes returns the number of the given router pods that
+// are ready, are not being deleted, and run on a node among the given nodes
+// that is ready.
+func readyRouterPodsOnReadyNodes(pods []corev1.Pod, nodes []corev1.Node) int32 {
+	ready := map[string]bool{}
+	for i := range nodes {
+		ready[nodes[i].Name] = IsNodeReady(&nodes[i])
+	}
+	var count int32
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || !isPodReady(pod) || !ready[pod.Spec.NodeName] {
+			continue
+		}
+		count++
+	}
+	return count
+}
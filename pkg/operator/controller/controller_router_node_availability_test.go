@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRouterNodeSelectorMatches(t *testing.T) {
+	worker := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "worker-0",
+			Labels: map[string]string{
+				"beta.kubernetes.io/os":          "linux",
+				"node-role.kubernetes.io/worker": "",
+			},
+		},
+	}
+	infraSelector := &operatorv1.NodePlacement{
+		NodeSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"node-role.kubernetes.io/infra": ""},
+		},
+	}
+	testCases := []struct {
+		description   string
+		strategy      operatorv1.EndpointPublishingStrategyType
+		nodePlacement *operatorv1.NodePlacement
+		expect        bool
+	}{
+		{"host network with the default node selector", operatorv1.HostNetworkStrategyType, nil, true},
+		{"host network with a node selector that does not match", operatorv1.HostNetworkStrategyType, infraSelector, false},
+		{"load balancer with the default node selector", operatorv1.LoadBalancerServiceStrategyType, nil, false},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			Spec: operatorv1.IngressControllerSpec{
+				NodePlacement: tc.nodePlacement,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+			},
+		}
+		if actual := RouterNodeSelectorMatches(ic, worker); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestWithAvailableReplicasOnReadyNodes(t *testing.T) {
+	node := func(name string, ready corev1.ConditionStatus, unschedulable bool) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	pod := func(name, nodeName string, ready bool) corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+	deleting := pod("router-2", "worker-2", true)
+	deleting.DeletionTimestamp = &metav1.Time{}
+
+	testCases := []struct {
+		description string
+		available   int32
+		pods        []corev1.Pod
+		nodes       []corev1.Node
+		expect      int32
+	}{
+		{
+			description: "all nodes ready",
+			available:   2,
+			pods:        []corev1.Pod{pod("router-0", "worker-0", true), pod("router-1", "worker-1", true)},
+			nodes:       []corev1.Node{node("worker-0", corev1.ConditionTrue, false), node("worker-1", corev1.ConditionTrue, false)},
+			expect:      2,
+		},
+		{
+			description: "one node not ready",
+			available:   2,
+			pods:        []corev1.Pod{pod("router-0", "worker-0", true), pod("router-1", "worker-1", true)},
+			nodes:       []corev1.Node{node("worker-0", corev1.ConditionTrue, false), node("worker-1", corev1.ConditionUnknown, false)},
+			expect:      1,
+		},
+		{
+			description: "all nodes not ready",
+			available:   2,
+			pods:        []corev1.Pod{pod("router-0", "worker-0", true), pod("router-1", "worker-1", true)},
+			nodes:       []corev1.Node{node("worker-0", corev1.ConditionFalse, false), node("worker-1", corev1.ConditionUnknown, false)},
+			expect:      0,
+		},
+		{
+			description: "a cordoned node still serves traffic",
+			available:   2,
+			pods:        []corev1.Pod{pod("router-0", "worker-0", true), pod("router-1", "worker-1", true)},
+			nodes:       []corev1.Node{node("worker-0", corev1.ConditionTrue, false), node("worker-1", corev1.ConditionTrue, true)},
+			expect:      2,
+		},
+		{
+			description: "a node that no longer exists",
+			available:   2,
+			pods:        []corev1.Pod{pod("router-0", "worker-0", true), pod("router-1", "worker-1", true)},
+			nodes:       []corev1.Node{node("worker-0", corev1.ConditionTrue, false)},
+			expect:      1,
+		},
+		{
+			description: "pods that are not ready or are being deleted",
+			available:   1,
+			pods:        []corev1.Pod{pod("router-0", "worker-0", true), pod("router-1", "worker-1", false), deleting},
+			nodes:       []corev1.Node{node("worker-0", corev1.ConditionTrue, false), node("worker-1", corev1.ConditionTrue, false), node("worker-2", corev1.ConditionTrue, false)},
+			expect:      1,
+		},
+		{
+			description: "the workload reports fewer available replicas than ready pods",
+			available:   1,
+			pods:        []corev1.Pod{pod("router-0", "worker-0", true), pod("router-1", "worker-1", true)},
+			nodes:       []corev1.Node{node("worker-0", corev1.ConditionTrue, false), node("worker-1", corev1.ConditionTrue, false)},
+			expect:      1,
+		},
+	}
+	for _, tc := range testCases {
+		deployment := &appsv1.Deployment{
+			Status: appsv1.DeploymentStatus{AvailableReplicas: tc.available},
+		}
+		actual := withAvailableReplicasOnReadyNodes(deployment, tc.pods, tc.nodes)
+		if actual.Status.AvailableReplicas != tc.expect {
+			t.Errorf("%q: expected %d available replicas, got %d", tc.description, tc.expect, actual.Status.AvailableReplicas)
+		}
+		if deployment.Status.AvailableReplicas != tc.available {
+			t.Errorf("%q: expected the given deployment to be left unchanged", tc.description)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// RouterPriorityClassAnnotation may be set on an IngressController to
+	// override the priority class of its router pods.  The value is the
+	// name of a priority class, for example "system-node-critical".  The
+	// priority class must exist; otherwise the API server rejects the
+	// router pods.
+	RouterPriorityClassAnnotation = "ingress.operator.openshift.io/router-priority-class"
+
+	// defaultRouterPriorityClassName is the priority class of router pods
+	// of ingresscontrollers that do not set RouterPriorityClassAnnotation.
+	// Ingress is critical to the cluster, so the kubelet should evict the
+	// routers under node pressure only after less critical workloads.
+	defaultRouterPriorityClassName = "system-cluster-critical"
+)
+
+// routerPriorityClassFor returns the name of the priority class for the router
+// pods of the given ingresscontroller.
+func routerPriorityClassFor(ic *operatorv1.IngressController) (string, error) {
+	value, ok := ic.Annotations[RouterPriorityClassAnnotation]
+	if !ok {
+		return defaultRouterPriorityClassName, nil
+	}
+	if errs := validation.IsDNS1123Subdomain(value); len(errs) != 0 {
+		return "", fmt.Errorf("invalid value for annotation %s: %q: %s", RouterPriorityClassAnnotation, value, strings.Join(errs, ", "))
+	}
+	return value, nil
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRouterPriorityClassFor(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expect      string
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+			expect:      "system-cluster-critical",
+		},
+		{
+			description: "override",
+			annotations: map[string]string{RouterPriorityClassAnnotation: "system-node-critical"},
+			expect:      "system-node-critical",
+		},
+		{
+			description: "empty value",
+			annotations: map[string]string{RouterPriorityClassAnnotation: ""},
+			expectErr:   true,
+		},
+		{
+			description: "invalid name",
+			annotations: map[string]string{RouterPriorityClassAnnotation: "Critical_Pods"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+		}
+		actual, err := routerPriorityClassFor(ic)
+		checkError(t, tc.description, tc.expectErr, err)
+		if actual != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
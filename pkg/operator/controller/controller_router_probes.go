@@ -0,0 +1,114 @@
+package controller
+
+import (
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ProbeTimeoutSecondsAnnotation overrides the timeout, in seconds, of
+	// the router's liveness and readiness probes.  The default is 1 second.
+	ProbeTimeoutSecondsAnnotation = "ingress.operator.openshift.io/probe-timeout-seconds"
+
+	// StartupGracePeriodSecondsAnnotation overrides the number of seconds
+	// that a router pod may take to generate its initial configuration
+	// before its liveness probe may fail.  Routers with very large numbers
+	// of routes may need longer than the default to start.
+	StartupGracePeriodSecondsAnnotation = "ingress.operator.openshift.io/startup-grace-period-seconds"
+
+	// defaultStartupGracePeriodSeconds is the default number of seconds
+	// that the liveness probe waits for a router pod to start.
+	defaultStartupGracePeriodSeconds = 10
+)
+
+// probeAnnotationValue returns the value of the given probe annotation on the
+// given ingresscontroller, or defaultValue if the annotation is not set.
+func probeAnnotationValue(ic *operatorv1.IngressController, annotation string, option tuningOption, defaultValue int32) (int32, error) {
+	value, ok := ic.Annotations[annotation]
+	if !ok || len(value) == 0 {
+		return defaultValue, nil
+	}
+	seconds, err := parseTuningAnnotation(annotation, value, option)
+	if err != nil {
+		return defaultValue, err
+	}
+	return int32(seconds), nil
+}
+
+// configureRouterProbes applies the ingresscontroller's probe tuning to the
+// router container.
+//
+// The router's readiness probe reports ready only once the router has
+// generated its initial configuration, but its liveness probe must not kill
+// the router while it is doing so.  Because the vendored pod API has no
+// startup probe, the liveness probe's initial delay is used to give the router
+// time to start; the readiness probe keeps its short initial delay so that a
+// router that starts quickly becomes ready quickly.
+func configureRouterProbes(ic *operatorv1.IngressController, container *corev1.Container) error {
+	timeout, err := probeAnnotationValue(ic, ProbeTimeoutSecondsAnnotation, probeTimeoutSecondsOption, 1)
+	if err != nil {
+		return err
+	}
+	grace, err := probeAnnotationValue(ic, StartupGracePeriodSecondsAnnotation, startupGracePeriodSecondsOption, defaultStartupGracePeriodSeconds)
+	if err != nil {
+		return err
+	}
+	for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+		if probe != nil {
+			probe.TimeoutSeconds = timeout
+		}
+	}
+	if container.LivenessProbe != nil {
+		container.LivenessProbe.InitialDelaySeconds = grace
+	}
+	return nil
+}
+
+// probesEqual compares two probes, taking into account the API's defaults.
+func probesEqual(a, b *corev1.Probe) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	a, b = withProbeDefaults(a), withProbeDefaults(b)
+	return a.InitialDelaySeconds == b.InitialDelaySeconds &&
+		a.TimeoutSeconds == b.TimeoutSeconds &&
+		a.PeriodSeconds == b.PeriodSeconds &&
+		a.SuccessThreshold == b.SuccessThreshold &&
+		a.FailureThreshold == b.FailureThreshold &&
+		(a.HTTPGet == nil) == (b.HTTPGet == nil) &&
+		(a.HTTPGet == nil || (a.HTTPGet.Path == b.HTTPGet.Path &&
+			a.HTTPGet.Port == b.HTTPGet.Port &&
+			a.HTTPGet.Host == b.HTTPGet.Host &&
+			a.HTTPGet.Scheme == b.HTTPGet.Scheme))
+}
+
+// withProbeDefaults returns a copy of the given probe with the API's defaults
+// filled in.
+func withProbeDefaults(probe *corev1.Probe) *corev1.Probe {
+	probe = probe.DeepCopy()
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = 1
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = 1
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = 3
+	}
+	if probe.HTTPGet != nil && len(probe.HTTPGet.Scheme) == 0 {
+		probe.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	return probe
+}
+
+// routerProbesChanged returns true if the router container's probes differ
+// between the given deployments.
+func routerProbesChanged(current, expected *appsv1.Deployment) bool {
+	c, e := current.Spec.Template.Spec.Containers[0], expected.Spec.Template.Spec.Containers[0]
+	return !probesEqual(c.LivenessProbe, e.LivenessProbe) || !probesEqual(c.ReadinessProbe, e.ReadinessProbe)
+}
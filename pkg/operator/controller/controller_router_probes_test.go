@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigureRouterProbes(t *testing.T) {
+	testCases := []struct {
+		description    string
+		annotations    map[string]string
+		expectErr      bool
+		expectTimeout  int32
+		expectLiveness int32
+	}{
+		{
+			description:    "no annotations",
+			expectTimeout:  1,
+			expectLiveness: defaultStartupGracePeriodSeconds,
+		},
+		{
+			description: "tuned probes",
+			annotations: map[string]string{
+				ProbeTimeoutSecondsAnnotation:       "5",
+				StartupGracePeriodSecondsAnnotation: "600",
+			},
+			expectTimeout:  5,
+			expectLiveness: 600,
+		},
+		{
+			description:    "invalid timeout",
+			annotations:    map[string]string{ProbeTimeoutSecondsAnnotation: "0"},
+			expectErr:      true,
+			expectTimeout:  0,
+			expectLiveness: 0,
+		},
+		{
+			description:    "invalid grace period",
+			annotations:    map[string]string{StartupGracePeriodSecondsAnnotation: "forever"},
+			expectErr:      true,
+			expectTimeout:  0,
+			expectLiveness: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		container := &corev1.Container{
+			LivenessProbe:  &corev1.Probe{},
+			ReadinessProbe: &corev1.Probe{InitialDelaySeconds: 10},
+		}
+		err := configureRouterProbes(ic, container)
+		if tc.expectErr != (err != nil) {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+		if tc.expectErr {
+			continue
+		}
+		if container.LivenessProbe.TimeoutSeconds != tc.expectTimeout || container.ReadinessProbe.TimeoutSeconds != tc.expectTimeout {
+			t.Errorf("%q: expected probe timeouts of %d, got %d and %d", tc.description, tc.expectTimeout, container.LivenessProbe.TimeoutSeconds, container.ReadinessProbe.TimeoutSeconds)
+		}
+		if container.LivenessProbe.InitialDelaySeconds != tc.expectLiveness {
+			t.Errorf("%q: expected liveness initial delay of %d, got %d", tc.description, tc.expectLiveness, container.LivenessProbe.InitialDelaySeconds)
+		}
+		if container.ReadinessProbe.InitialDelaySeconds != 10 {
+			t.Errorf("%q: expected readiness initial delay to be unchanged, got %d", tc.description, container.ReadinessProbe.InitialDelaySeconds)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RouterProfilingAnnotation may be set on an IngressController to
+	// enable the router's pprof endpoint for performance investigations.
+	// The value is "Enabled" or "Disabled" (the default).  The endpoint
+	// listens only on the loopback interface of the router pod, on
+	// routerProfilingPort, so it is reachable only through
+	// "oc port-forward", which requires the pods/portforward permission in
+	// the operand namespace.  Routers that use host networking share the
+	// node's loopback interface, so at most one such router per node can
+	// serve the endpoint.
+	RouterProfilingAnnotation = "ingress.operator.openshift.io/router-profiling"
+
+	// RouterProfilingEnabled and RouterProfilingDisabled are the valid
+	// values of RouterProfilingAnnotation.
+	RouterProfilingEnabled  = "Enabled"
+	RouterProfilingDisabled = "Disabled"
+
+	// routerProfilingHost and routerProfilingPort are the address on
+	// which the router serves pprof when profiling is enabled.
+	routerProfilingHost = "127.0.0.1"
+	routerProfilingPort = "6060"
+)
+
+// desiredRouterProfilingEnv returns the router environment variables that
+// enable the router's pprof endpoint if the given ingresscontroller requests
+// it.
+func desiredRouterProfilingEnv(ic *operatorv1.IngressController) ([]corev1.EnvVar, error) {
+	switch value := ic.Annotations[RouterProfilingAnnotation]; value {
+	case "", RouterProfilingDisabled:
+		return nil, nil
+	case RouterProfilingEnabled:
+		return []corev1.EnvVar{
+			{Name: "OPENSHIFT_PROFILE", Value: "web"},
+			{Name: "OPENSHIFT_PROFILE_HOST", Value: routerProfilingHost},
+			{Name: "OPENSHIFT_PROFILE_PORT", Value: routerProfilingPort},
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid value for annotation %s: %q: must be %s or %s", RouterProfilingAnnotation, value, RouterProfilingEnabled, RouterProfilingDisabled)
+	}
+}
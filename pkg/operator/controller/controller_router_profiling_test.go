@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDesiredRouterProfilingEnv(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expect      []corev1.EnvVar
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "disabled",
+			annotations: map[string]string{RouterProfilingAnnotation: RouterProfilingDisabled},
+		},
+		{
+			description: "enabled",
+			annotations: map[string]string{RouterProfilingAnnotation: RouterProfilingEnabled},
+			expect: []corev1.EnvVar{
+				{Name: "OPENSHIFT_PROFILE", Value: "web"},
+				{Name: "OPENSHIFT_PROFILE_HOST", Value: "127.0.0.1"},
+				{Name: "OPENSHIFT_PROFILE_PORT", Value: "6060"},
+			},
+		},
+		{
+			description: "invalid value",
+			annotations: map[string]string{RouterProfilingAnnotation: "true"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		env, err := desiredRouterProfilingEnv(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(env, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, env)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// trustedCABundleConfigMapName is the name of the configmap in the
+	// operand namespace into which the cluster network operator injects
+	// the cluster's trusted CA bundle, including any proxy CA.
+	trustedCABundleConfigMapName = "trusted-ca-bundle"
+
+	// InjectTrustedCABundleLabel is the label that requests that the
+	// cluster network operator inject the trusted CA bundle into a
+	// configmap.
+	InjectTrustedCABundleLabel = "config.openshift.io/inject-trusted-cabundle"
+
+	// trustedCABundleKey is the key of the injected trusted CA bundle.
+	trustedCABundleKey = "ca-bundle.crt"
+
+	trustedCABundleVolumeName = "trusted-ca"
+	trustedCABundleMountPath  = "/etc/pki/ca-trust/extracted/pem"
+	trustedCABundleFileName   = "tls-ca-bundle.pem"
+)
+
+// defaultRouterNoProxy are the destinations that routers always reach without
+// the proxy: the loopback interface and in-cluster service names.
+var defaultRouterNoProxy = []string{"localhost", "127.0.0.1", "::1", ".svc", ".cluster.local"}
+
+// proxyConfigured returns true if the given cluster proxy config specifies a
+// proxy.
+func proxyConfigured(proxy *configv1.Proxy) bool {
+	return proxy != nil && (len(proxy.Spec.HTTPProxy) != 0 || len(proxy.Spec.HTTPSProxy) != 0)
+}
+
+// routerNoProxy returns the NO_PROXY value for routers that use the given
+// cluster proxy.  Besides the proxy's own exceptions, routers must reach the
+// pod and service networks of the given cluster network config and the given
+// machine networks directly, so that connections to re-encrypt backends and to
+// services that HAProxy calls out to, which are in the cluster, never go
+// through the proxy.  The machine networks come from the install config rather
+// than the nodes' addresses so that adding or removing nodes does not change
+// the value, which would roll out the routers.
+func routerNoProxy(proxy *configv1.Proxy, network *configv1.Network, machineNetworks []string) string {
+	var entries []string
+	seen := map[string]bool{}
+	add := func(entry string) {
+		if entry = strings.TrimSpace(entry); len(entry) != 0 && !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	for _, entry := range strings.Split(proxy.Spec.NoProxy, ",") {
+		add(entry)
+	}
+	for _, entry := range defaultRouterNoProxy {
+		add(entry)
+	}
+	if network != nil {
+		for _, entry := range network.Status.ClusterNetwork {
+			add(entry.CIDR)
+		}
+		for _, cidr := range network.Status.ServiceNetwork {
+			add(cidr)
+		}
+	}
+	for _, cidr := range machineNetworks {
+		add(cidr)
+	}
+	return strings.Join(entries, ",")
+}
+
+// configureRouterProxy configures the containers of the given router
+// deployment to make egress connections, such as to external health check
+// targets or to syslog servers, through the given cluster proxy, except for
+// the destinations in the given NO_PROXY value, and to trust the cluster's
+// trusted CA bundle, which includes the proxy's CA.  Changing the proxy config
+// changes the environment of the pods, which causes a rollout.
+func configureRouterProxy(deployment *appsv1.Deployment, proxy *configv1.Proxy, noProxy string) {
+	if !proxyConfigured(proxy) {
+		return
+	}
+	env := []corev1.EnvVar{}
+	for _, v := range []struct{ name, value string }{
+		{"HTTP_PROXY", proxy.Spec.HTTPProxy},
+		{"HTTPS_PROXY", proxy.Spec.HTTPSProxy},
+		{"NO_PROXY", noProxy},
+	} {
+		if len(v.value) != 0 {
+			env = append(env, corev1.EnvVar{Name: v.name, Value: v.value})
+		}
+	}
+	// The configmap is optional so that the pods can start before the
+	// bundle is injected.  The mounted content hash causes a rollout once
+	// it is.
+	optional := true
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: trustedCABundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: trustedCABundleConfigMapName},
+				Items:                []corev1.KeyToPath{{Key: trustedCABundleKey, Path: trustedCABundleFileName}},
+				Optional:             &optional,
+			},
+		},
+	})
+	for i := range deployment.Spec.Template.Spec.Containers {
+		container := &deployment.Spec.Template.Spec.Containers[i]
+		container.Env = append(container.Env, env...)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      trustedCABundleVolumeName,
+			MountPath: trustedCABundleMountPath,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// setRouterProxy configures the given router deployment to use the cluster
+// proxy, if one is configured, and ensures that the configmap with the
+// trusted CA bundle exists for the deployment to mount.
+func (r *reconciler) setRouterProxy(deployment *appsv1.Deployment) error {
+	proxy := &configv1.Proxy{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, proxy); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get proxy 'cluster': %v", err)
+		}
+		return nil
+	}
+	if !proxyConfigured(proxy) {
+		return nil
+	}
+	if err := r.ensureTrustedCABundleConfigMap(deployment.Namespace); err != nil {
+		return err
+	}
+	network := &configv1.Network{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, network); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get network 'cluster': %v", err)
+		}
+		network = nil
+	}
+	installConfig, err := r.installConfig()
+	if err != nil {
+		return err
+	}
+	configureRouterProxy(deployment, proxy, routerNoProxy(proxy, network, installConfig.machineNetworks()))
+	return nil
+}
+
+// ensureTrustedCABundleConfigMap ensures that the configmap into which the
+// cluster network operator injects the trusted CA bundle exists in the given
+// namespace.  The configmap is shared by all routers in the namespace, and the
+// operator leaves its data to the cluster network operator.
+func (r *reconciler) ensureTrustedCABundleConfigMap(namespace string) error {
+	name := types.NamespacedName{Namespace: namespace, Name: trustedCABundleConfigMapName}
+	current := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get configmap %s: %v", name, err)
+		}
+		desired := &corev1.ConfigMap{}
+		desired.Namespace = name.Namespace
+		desired.Name = name.Name
+		desired.Labels = map[string]string{InjectTrustedCABundleLabel: "true"}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to create configmap %s: %v", name, err)
+		}
+		log.Info("created configmap", "namespace", name.Namespace, "name", name.Name)
+		return nil
+	}
+	if current.Labels[InjectTrustedCABundleLabel] == "true" {
+		return nil
+	}
+	updated := current.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	updated.Labels[InjectTrustedCABundleLabel] = "true"
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update configmap %s: %v", name, err)
+	}
+	log.Info("updated configmap", "namespace", name.Namespace, "name", name.Name)
+	return nil
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestConfigureRouterProxy(t *testing.T) {
+	deployment := newTestRouterDeployment()
+	configureRouterProxy(deployment, nil, "")
+	configureRouterProxy(deployment, &configv1.Proxy{Spec: configv1.ProxySpec{NoProxy: ".cluster.local"}}, ".cluster.local")
+	if len(deployment.Spec.Template.Spec.Volumes) != 0 || len(deployment.Spec.Template.Spec.Containers[0].Env) != 0 {
+		t.Fatalf("expected no proxy configuration without a proxy, got %v", deployment.Spec.Template.Spec)
+	}
+
+	proxy := &configv1.Proxy{
+		Spec: configv1.ProxySpec{
+			HTTPSProxy: "https://proxy.example.com:3128",
+			NoProxy:    ".cluster.local",
+		},
+	}
+	configureRouterProxy(deployment, proxy, proxy.Spec.NoProxy)
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	if v, ok := envValue(container, "HTTPS_PROXY"); !ok || v != proxy.Spec.HTTPSProxy {
+		t.Errorf("expected HTTPS_PROXY %q, got %q", proxy.Spec.HTTPSProxy, v)
+	}
+	if v, ok := envValue(container, "NO_PROXY"); !ok || v != proxy.Spec.NoProxy {
+		t.Errorf("expected NO_PROXY %q, got %q", proxy.Spec.NoProxy, v)
+	}
+	if _, ok := envValue(container, "HTTP_PROXY"); ok {
+		t.Error("expected HTTP_PROXY not to be set")
+	}
+	volumes := deployment.Spec.Template.Spec.Volumes
+	if len(volumes) != 1 || volumes[0].ConfigMap == nil || volumes[0].ConfigMap.Name != trustedCABundleConfigMapName {
+		t.Errorf("expected the trusted CA bundle to be mounted, got %v", volumes)
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != trustedCABundleMountPath {
+		t.Errorf("unexpected volume mounts %v", container.VolumeMounts)
+	}
+}
+
+func TestRouterNoProxy(t *testing.T) {
+	network := &configv1.Network{
+		Status: configv1.NetworkStatus{
+			ClusterNetwork: []configv1.ClusterNetworkEntry{{CIDR: "10.128.0.0/14"}},
+			ServiceNetwork: []string{"172.30.0.0/16"},
+		},
+	}
+	machineNetworks := []string{"10.0.0.0/16", "fd00::/48"}
+	testCases := []struct {
+		description string
+		noProxy     string
+		network     *configv1.Network
+		expect      string
+	}{
+		{
+			description: "no network config",
+			noProxy:     "example.com",
+			expect:      "example.com,localhost,127.0.0.1,::1,.svc,.cluster.local,10.0.0.0/16,fd00::/48",
+		},
+		{
+			description: "cluster and service networks",
+			noProxy:     "example.com, .cluster.local",
+			network:     network,
+			expect:      "example.com,.cluster.local,localhost,127.0.0.1,::1,.svc,10.128.0.0/14,172.30.0.0/16,10.0.0.0/16,fd00::/48",
+		},
+		{
+			description: "machine network in the proxy exceptions",
+			noProxy:     "10.0.0.0/16",
+			network:     network,
+			expect:      "10.0.0.0/16,localhost,127.0.0.1,::1,.svc,.cluster.local,10.128.0.0/14,172.30.0.0/16,fd00::/48",
+		},
+	}
+	for _, tc := range testCases {
+		proxy := &configv1.Proxy{Spec: configv1.ProxySpec{HTTPSProxy: "https://proxy.example.com:3128", NoProxy: tc.noProxy}}
+		if actual := routerNoProxy(proxy, tc.network, machineNetworks); actual != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ensureRouterRBAC ensures that the given ingresscontroller has its own
+// service account, a role and role binding that grant the service account
+// access to the ingresscontroller's own secrets, and a cluster role binding
+// that grants it the router cluster role.  Giving each ingresscontroller its
+// own service account allows cluster administrators to grant different SCCs or
+// other permissions to different shards, and the external-certificate
+// controller to grant each shard access to only the secrets of the external
+// certificates of the routes that it serves.
+//
+// The namespaced resources have no owner reference.  The router workload is
+// the only operand in the operand namespace that could own them, and it is
+// replaced when the ingresscontroller switches between a deployment and a
+// daemonset, which would have the garbage collector delete the service account
+// that the new workload's pods run as.  The ingresscontroller cannot own them
+// because it is in another namespace.  Instead, they are labeled with the
+// owning ingresscontroller and deleted by ensureRouterRBACDeleted when the
+// ingresscontroller is deleted, or by the operand-gc controller if that is
+// missed.
+func (r *reconciler) ensureRouterRBAC(ic *operatorv1.IngressController) error {
+	if err := r.ensureRouterServiceAccount(ic); err != nil {
+		return err
+	}
+	if err := r.ensureRouterRole(ic); err != nil {
+		return err
+	}
+	if err := r.ensureRouterRoleBinding(ic); err != nil {
+		return err
+	}
+	return r.ensureRouterClusterRoleBinding(ic)
+}
+
+// desiredRouterServiceAccount returns the desired service account for the
+// router pods of the given ingresscontroller.
+func desiredRouterServiceAccount(ic *operatorv1.IngressController, operandNamespace string) *corev1.ServiceAccount {
+	name := RouterServiceAccountName(ic, operandNamespace)
+	sa := manifests.RouterServiceAccount()
+	sa.Namespace = name.Namespace
+	sa.Name = name.Name
+	sa.Labels = map[string]string{
+		manifests.OwningIngressControllerLabel: ic.Name,
+	}
+	return sa
+}
+
+// ensureRouterServiceAccount ensures that the service account for the router
+// pods of the given ingresscontroller exists and has no owner reference.
+func (r *reconciler) ensureRouterServiceAccount(ic *operatorv1.IngressController) error {
+	desired := desiredRouterServiceAccount(ic, r.Config.OperandNamespace)
+	current := &corev1.ServiceAccount{}
+	if err := r.client.Get(context.TODO(), RouterServiceAccountName(ic, r.Config.OperandNamespace), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get router service account %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router service account %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created router service account", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if len(current.OwnerReferences) == 0 {
+		return nil
+	}
+	// Earlier versions of the operator made the router deployment own
+	// the service account.
+	updated := current.DeepCopy()
+	updated.OwnerReferences = nil
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update router service account %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("removed owner reference from router service account", "namespace", updated.Namespace, "name", updated.Name)
+	return nil
+}
+
+// desiredRouterRole returns the desired role for the router pods of the given
+// ingresscontroller.  The role grants access to the secrets that the
+// ingresscontroller's routers use and, if useHostNetworkSCC is true, the use of
+// the hostnetwork SCC.
+func desiredRouterRole(ic *operatorv1.IngressController, operandNamespace string, useHostNetworkSCC bool) *rbacv1.Role {
+	name := RouterServiceAccountName(ic, operandNamespace)
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get"},
+			ResourceNames: []string{
+				RouterEffectiveDefaultCertificateSecretName(ic, operandNamespace).Name,
+				RouterStatsSecretName(ic, operandNamespace).Name,
+			},
+		}},
+	}
+	if useHostNetworkSCC {
+		role.Rules = append(role.Rules, hostNetworkSCCPolicyRule())
+	}
+	return role
+}
+
+// ensureRouterRole ensures that the role for the router pods of the given
+// ingresscontroller exists and grants access to the ingresscontroller's
+// current secrets and, if needed, to the hostnetwork SCC, and that it has no
+// owner reference.
+func (r *reconciler) ensureRouterRole(ic *operatorv1.IngressController) error {
+	useHostNetworkSCC, err := routerUsesHostNetworkSCC(ic)
+	if err != nil {
+		return err
+	}
+	desired := desiredRouterRole(ic, r.Config.OperandNamespace, useHostNetworkSCC)
+	current := &rbacv1.Role{}
+	if err := r.client.Get(context.TODO(), RouterServiceAccountName(ic, r.Config.OperandNamespace), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get router role %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router role %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created router role", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Rules, desired.Rules) && len(current.OwnerReferences) == 0 {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Rules = desired.Rules
+	updated.OwnerReferences = nil
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update router role %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated router role", "namespace", updated.Namespace, "name", updated.Name)
+	return nil
+}
+
+// desiredRouterRoleBinding returns the desired role binding that binds the
+// router role of the given ingresscontroller to its service account.
+func desiredRouterRoleBinding(ic *operatorv1.IngressController, operandNamespace string) *rbacv1.RoleBinding {
+	name := RouterServiceAccountName(ic, operandNamespace)
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: name.Namespace,
+			Name:      name.Name,
+		}},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name.Name,
+		},
+	}
+}
+
+// ensureRouterRoleBinding ensures that the role binding for the router pods of
+// the given ingresscontroller exists and has no owner reference.
+func (r *reconciler) ensureRouterRoleBinding(ic *operatorv1.IngressController) error {
+	desired := desiredRouterRoleBinding(ic, r.Config.OperandNamespace)
+	current := &rbacv1.RoleBinding{}
+	if err := r.client.Get(context.TODO(), RouterServiceAccountName(ic, r.Config.OperandNamespace), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get router role binding %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router role binding %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created router role binding", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Subjects, desired.Subjects) && len(current.OwnerReferences) == 0 {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Subjects = desired.Subjects
+	updated.OwnerReferences = nil
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update router role binding %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated router role binding", "namespace", updated.Namespace, "name", updated.Name)
+	return nil
+}
+
+// desiredRouterClusterRoleBinding returns the desired cluster role binding that
+// binds the router cluster role to the service account of the given
+// ingresscontroller.  Cluster-scoped resources cannot be owned by the
+// namespaced router deployment, so the binding is labeled with the owning
+// ingresscontroller and deleted when the ingresscontroller is deleted.
+func desiredRouterClusterRoleBinding(ic *operatorv1.IngressController, operandNamespace string) *rbacv1.ClusterRoleBinding {
+	sa := RouterServiceAccountName(ic, operandNamespace)
+	crb := manifests.RouterClusterRoleBinding()
+	crb.Name = RouterClusterRoleBindingName(ic).Name
+	crb.Labels = map[string]string{
+		manifests.OwningIngressControllerLabel: ic.Name,
+	}
+	crb.Subjects = []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Namespace: sa.Namespace,
+		Name:      sa.Name,
+	}}
+	return crb
+}
+
+// ensureRouterClusterRoleBinding ensures that the cluster role binding for the
+// router pods of the given ingresscontroller exists.
+func (r *reconciler) ensureRouterClusterRoleBinding(ic *operatorv1.IngressController) error {
+	desired := desiredRouterClusterRoleBinding(ic, r.Config.OperandNamespace)
+	current := &rbacv1.ClusterRoleBinding{}
+	if err := r.client.Get(context.TODO(), RouterClusterRoleBindingName(ic), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get router cluster role binding %s: %v", desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router cluster role binding %s: %v", desired.Name, err)
+		}
+		log.Info("created router cluster role binding", "name", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Subjects, desired.Subjects) {
+		return nil
+	}
+	// The operand namespace changed, so the router service account that
+	// needs the binding is in a different namespace.
+	updated := current.DeepCopy()
+	updated.Subjects = desired.Subjects
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update router cluster role binding %s: %v", updated.Name, err)
+	}
+	log.Info("updated router cluster role binding", "name", updated.Name)
+	return nil
+}
+
+// ensureRouterRBACDeleted deletes the service account, role, role binding, and
+// cluster role binding of the router pods of the given ingresscontroller.
+func (r *reconciler) ensureRouterRBACDeleted(ic *operatorv1.IngressController) error {
+	name := RouterServiceAccountName(ic, r.Config.OperandNamespace)
+	for _, o := range []struct {
+		kind string
+		obj  runtime.Object
+	}{
+		{"role binding", &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}}},
+		{"role", &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}}},
+		{"service account", &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}}},
+	} {
+		if err := r.client.Delete(context.TODO(), o.obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to delete router %s %s: %v", o.kind, name, err)
+		}
+		log.Info("deleted router "+o.kind, "namespace", name.Namespace, "name", name.Name)
+	}
+	return r.ensureRouterClusterRoleBindingDeleted(ic)
+}
+
+// ensureRouterClusterRoleBindingDeleted deletes the cluster role binding for
+// the router pods of the given ingresscontroller.
+func (r *reconciler) ensureRouterClusterRoleBindingDeleted(ic *operatorv1.IngressController) error {
+	crb := &rbacv1.ClusterRoleBinding{}
+	crb.Name = RouterClusterRoleBindingName(ic).Name
+	if err := r.client.Delete(context.TODO(), crb); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete router cluster role binding %s: %v", crb.Name, err)
+	}
+	log.Info("deleted router cluster role binding", "name", crb.Name)
+	return nil
+}
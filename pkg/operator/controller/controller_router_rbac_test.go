@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredRouterRole(t *testing.T) {
+	testCases := []struct {
+		description string
+		ic          *operatorv1.IngressController
+		expect      []string
+	}{
+		{
+			description: "operator-generated default certificate",
+			ic: &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			},
+			expect: []string{"router-certs-default", "router-stats-default"},
+		},
+		{
+			description: "custom default certificate",
+			ic: &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "shard"},
+				Spec: operatorv1.IngressControllerSpec{
+					DefaultCertificate: &corev1.LocalObjectReference{Name: "custom-cert"},
+				},
+			},
+			expect: []string{"custom-cert", "router-stats-shard"},
+		},
+	}
+	for _, tc := range testCases {
+		role := desiredRouterRole(tc.ic, DefaultOperandNamespace, false)
+		if role.Namespace != DefaultOperandNamespace || role.Name != "router-"+tc.ic.Name {
+			t.Errorf("%q: unexpected role name %s/%s", tc.description, role.Namespace, role.Name)
+		}
+		if len(role.Rules) != 1 {
+			t.Fatalf("%q: expected 1 rule, got %d", tc.description, len(role.Rules))
+		}
+		if !reflect.DeepEqual(role.Rules[0].ResourceNames, tc.expect) {
+			t.Errorf("%q: expected resource names %v, got %v", tc.description, tc.expect, role.Rules[0].ResourceNames)
+		}
+	}
+}
+
+func TestDesiredRouterRoleHostNetworkSCC(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	role := desiredRouterRole(ic, DefaultOperandNamespace, true)
+	if len(role.Rules) != 2 || !reflect.DeepEqual(role.Rules[1], hostNetworkSCCPolicyRule()) {
+		t.Errorf("expected the role to grant the hostnetwork SCC, got %v", role.Rules)
+	}
+}
+
+func TestDesiredRouterClusterRoleBinding(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "shard"}}
+	crb := desiredRouterClusterRoleBinding(ic, "custom-ingress")
+	if crb.Name != "openshift-ingress-router-shard" {
+		t.Errorf("unexpected name %s", crb.Name)
+	}
+	if crb.RoleRef.Name != "openshift-ingress-router" {
+		t.Errorf("expected binding to the router cluster role, got %s", crb.RoleRef.Name)
+	}
+	if len(crb.Subjects) != 1 || crb.Subjects[0].Namespace != "custom-ingress" || crb.Subjects[0].Name != "router-shard" {
+		t.Errorf("unexpected subjects %v", crb.Subjects)
+	}
+}
+
+// TestDesiredRouterRBACHasNoOwner verifies that the router service account,
+// role, and role binding are not owned by the router workload, which is
+// replaced when an ingresscontroller switches between a deployment and a
+// daemonset.
+func TestDesiredRouterRBACHasNoOwner(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	for _, o := range []metav1.Object{
+		desiredRouterServiceAccount(ic, DefaultOperandNamespace),
+		desiredRouterRole(ic, DefaultOperandNamespace, false),
+		desiredRouterRoleBinding(ic, DefaultOperandNamespace),
+	} {
+		if refs := o.GetOwnerReferences(); len(refs) != 0 {
+			t.Errorf("expected %s to have no owner references, got %v", o.GetName(), refs)
+		}
+		if o.GetLabels()[manifests.OwningIngressControllerLabel] != ic.Name {
+			t.Errorf("expected %s to be labeled with the owning ingresscontroller, got %v", o.GetName(), o.GetLabels())
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// HAProxyReloadFailingConditionType is the type of the
+	// ingresscontroller condition that reports whether HAProxy failed to
+	// load the most recent configuration that a router generated, which
+	// usually means that a route has configuration that produces an
+	// invalid HAProxy configuration.  The router keeps serving the last
+	// configuration that loaded and retries with the next configuration
+	// change, so the condition clears once the offending route is fixed.
+	HAProxyReloadFailingConditionType = "HAProxyReloadFailing"
+
+	// reloadFailureMetric is the router metric that is 1 if the most
+	// recent HAProxy reload failed and 0 otherwise.
+	reloadFailureMetric = "template_router_reload_failure"
+
+	// routerMetricsPort is the port on which routers serve metrics.
+	routerMetricsPort = "1936"
+
+	// routerMetricsTimeout bounds the total time to scrape the metrics of
+	// all of an ingresscontroller's routers, so that scraping does not hold
+	// up the reconciliation however many router pods there are.
+	routerMetricsTimeout = 5 * time.Second
+
+	// maxConcurrentRouterScrapes is the maximum number of routers whose
+	// metrics are scraped at the same time.
+	maxConcurrentRouterScrapes = 16
+)
+
+// computeHAProxyReloadFailingCondition computes the HAProxyReloadFailing
+// condition for the given ingresscontroller by scraping the metrics of its
+// ready router pods.  The routers' metrics listener serves a certificate from
+// the service CA for the internal service and accepts the stats credentials,
+// so the condition is nil until both are available.
+func (r *reconciler) computeHAProxyReloadFailingCondition(ic *operatorv1.IngressController) (*operatorv1.OperatorCondition, error) {
+	serviceCA := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: r.Config.OperandNamespace, Name: serviceCABundleConfigMapName}, serviceCA); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service CA bundle: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(serviceCA.Data[serviceCABundleKey])) {
+		return nil, nil
+	}
+	statsSecret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), RouterStatsSecretName(ic, r.Config.OperandNamespace), statsSecret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get router stats secret: %v", err)
+	}
+	pods := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), pods, client.InNamespace(r.Config.OperandNamespace), client.MatchingLabels(IngressControllerDeploymentPodSelector(ic).MatchLabels)); err != nil {
+		return nil, fmt.Errorf("failed to list router pods: %v", err)
+	}
+
+	// Each router is scraped once per reconciliation, so connections are
+	// not kept alive; otherwise every reconciliation would leave idle
+	// connections, and their goroutines, behind in a transport that is
+	// never used again.
+	internalService := InternalIngressControllerServiceName(ic, r.Config.OperandNamespace)
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:    roots,
+			ServerName: fmt.Sprintf("%s.%s.svc", internalService.Name, internalService.Namespace),
+		},
+		DisableKeepAlives: true,
+	}
+	defer transport.CloseIdleConnections()
+	httpClient := &http.Client{Transport: transport}
+	var ready []*corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if len(pod.Status.PodIP) == 0 || !isPodReady(pod) {
+			continue
+		}
+		ready = append(ready, pod)
+	}
+	username, password := string(statsSecret.Data[statsUsernameKey]), string(statsSecret.Data[statsPasswordKey])
+	ctx, cancel := context.WithTimeout(context.Background(), routerMetricsTimeout)
+	defer cancel()
+	failing, unreachable, scraped := scrapeReloadFailures(ctx, ready, func(ctx context.Context, pod *corev1.Pod) (bool, error) {
+		return scrapeReloadFailure(ctx, httpClient, pod.Status.PodIP, username, password)
+	})
+	return haproxyReloadFailingCondition(failing, unreachable, scraped), nil
+}
+
+// scrapeReloadFailures calls scrape for each of the given pods, at most
+// maxConcurrentRouterScrapes at a time, and returns the names of the pods whose
+// most recent reload failed, the names of the pods that could not be scraped,
+// and the number of pods that were scraped.  All scrapes share the given
+// context, so its deadline bounds the time to scrape all of the pods; pods that
+// are not scraped by then count as unreachable.
+func scrapeReloadFailures(ctx context.Context, pods []*corev1.Pod, scrape func(context.Context, *corev1.Pod) (bool, error)) ([]string, []string, int) {
+	var (
+		mu                   sync.Mutex
+		wg                   sync.WaitGroup
+		failing, unreachable []string
+		scraped              int
+	)
+	sem := make(chan struct{}, maxConcurrentRouterScrapes)
+	for _, pod := range pods {
+		wg.Add(1)
+		go func(pod *corev1.Pod) {
+			defer wg.Done()
+			var (
+				failed bool
+				err    error
+			)
+			select {
+			case sem <- struct{}{}:
+				failed, err = scrape(ctx, pod)
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Info("failed to scrape router metrics", "namespace", pod.Namespace, "name", pod.Name, "error", err.Error())
+				unreachable = append(unreachable, pod.Name)
+				return
+			}
+			scraped++
+			if failed {
+				failing = append(failing, pod.Name)
+			}
+		}(pod)
+	}
+	wg.Wait()
+	return failing, unreachable, scraped
+}
+
+// isPodReady returns true if the given pod has the Ready condition.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// scrapeReloadFailure fetches the metrics of the router at the given address
+// and returns true if its most recent reload failed.  The request is canceled
+// when the given context is done.
+func scrapeReloadFailure(ctx context.Context, httpClient *http.Client, address, username, password string) (bool, error) {
+	url := "https://" + net.JoinHostPort(address, routerMetricsPort) + "/metrics"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth(username, password)
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status from %s: %s", url, resp.Status)
+	}
+	return parseReloadFailure(resp.Body)
+}
+
+// parseReloadFailure returns true if the given metrics in the Prometheus text
+// format report that the most recent reload failed.  Metrics without the reload
+// failure metric, such as those of routers that have not reloaded yet, report
+// no failure.
+func parseReloadFailure(metrics io.Reader) (bool, error) {
+	scanner := bufio.NewScanner(metrics)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || (fields[0] != reloadFailureMetric && !strings.HasPrefix(fields[0], reloadFailureMetric+"{")) {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for metric %s: %q", reloadFailureMetric, fields[1])
+		}
+		return value != 0, nil
+	}
+	return false, scanner.Err()
+}
+
+// haproxyReloadFailingCondition returns the HAProxyReloadFailing condition
+// given the names of the router pods whose most recent reload failed, the names
+// of the pods whose metrics could not be scraped, and the number of pods whose
+// metrics were scraped.
+func haproxyReloadFailingCondition(failing, unreachable []string, scraped int) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type: HAProxyReloadFailingConditionType,
+	}
+	sort.Strings(failing)
+	sort.Strings(unreachable)
+	switch {
+	case len(failing) != 0:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "ReloadFailed"
+		condition.Message = fmt.Sprintf("HAProxy failed to load the most recent configuration on router pods %s and is serving the previous configuration; the pods' logs identify the configuration error, which is usually caused by a route", strings.Join(failing, ", "))
+	case scraped == 0 && len(unreachable) != 0:
+		condition.Status = operatorv1.ConditionUnknown
+		condition.Reason = "MetricsUnavailable"
+		condition.Message = fmt.Sprintf("The metrics of router pods %s could not be scraped", strings.Join(unreachable, ", "))
+	case scraped == 0:
+		condition.Status = operatorv1.ConditionUnknown
+		condition.Reason = "NoReadyPods"
+		condition.Message = "No router pods are ready"
+	default:
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "ReloadSucceeded"
+		condition.Message = fmt.Sprintf("HAProxy loaded the most recent configuration on %d router pod(s)", scraped)
+	}
+	return condition
+}
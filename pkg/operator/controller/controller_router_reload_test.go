@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseReloadFailure(t *testing.T) {
+	testCases := []struct {
+		description string
+		metrics     string
+		expect      bool
+		expectErr   bool
+	}{
+		{
+			description: "no reload metric",
+			metrics:     "# TYPE haproxy_up gauge\nhaproxy_up 1\n",
+		},
+		{
+			description: "reload succeeded",
+			metrics:     "# HELP template_router_reload_failure Metric to track the status of the most recent HAProxy reload\n# TYPE template_router_reload_failure gauge\ntemplate_router_reload_failure 0\n",
+		},
+		{
+			description: "reload failed",
+			metrics:     "haproxy_up 1\ntemplate_router_reload_failure 1\n",
+			expect:      true,
+		},
+		{
+			description: "reload failed with labels",
+			metrics:     "template_router_reload_failure{shard=\"default\"} 1\n",
+			expect:      true,
+		},
+		{
+			description: "similarly named metric",
+			metrics:     "template_router_reload_failures_total 3\n",
+		},
+		{
+			description: "invalid value",
+			metrics:     "template_router_reload_failure yes\n",
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		actual, err := parseReloadFailure(strings.NewReader(tc.metrics))
+		checkError(t, tc.description, tc.expectErr, err)
+		if actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestHAProxyReloadFailingCondition(t *testing.T) {
+	testCases := []struct {
+		description  string
+		failing      []string
+		unreachable  []string
+		scraped      int
+		expectStatus operatorv1.ConditionStatus
+		expectReason string
+	}{
+		{"all routers reloaded", nil, nil, 2, operatorv1.ConditionFalse, "ReloadSucceeded"},
+		{"one router failed", []string{"router-default-b"}, nil, 2, operatorv1.ConditionTrue, "ReloadFailed"},
+		{"failure with unreachable router", []string{"router-default-b"}, []string{"router-default-a"}, 1, operatorv1.ConditionTrue, "ReloadFailed"},
+		{"some routers unreachable", nil, []string{"router-default-a"}, 1, operatorv1.ConditionFalse, "ReloadSucceeded"},
+		{"all routers unreachable", nil, []string{"router-default-a"}, 0, operatorv1.ConditionUnknown, "MetricsUnavailable"},
+		{"no ready routers", nil, nil, 0, operatorv1.ConditionUnknown, "NoReadyPods"},
+	}
+	for _, tc := range testCases {
+		condition := haproxyReloadFailingCondition(tc.failing, tc.unreachable, tc.scraped)
+		if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason {
+			t.Errorf("%q: expected status %s and reason %s, got %s and %s", tc.description, tc.expectStatus, tc.expectReason, condition.Status, condition.Reason)
+		}
+	}
+	condition := haproxyReloadFailingCondition([]string{"router-default-b", "router-default-a"}, nil, 2)
+	if !strings.Contains(condition.Message, "router-default-a, router-default-b") {
+		t.Errorf("expected the message to list the failing pods, got %q", condition.Message)
+	}
+}
+
+func TestScrapeReloadFailures(t *testing.T) {
+	// scrape reports a failure for pods named "failing", an error for pods
+	// named "error", and blocks until the context is done for pods named
+	// "hung".
+	scrape := func(ctx context.Context, pod *corev1.Pod) (bool, error) {
+		switch {
+		case strings.HasPrefix(pod.Name, "failing"):
+			return true, nil
+		case strings.HasPrefix(pod.Name, "error"):
+			return false, fmt.Errorf("connection refused")
+		case strings.HasPrefix(pod.Name, "hung"):
+			<-ctx.Done()
+			return false, ctx.Err()
+		}
+		return false, nil
+	}
+	pods := func(names ...string) []*corev1.Pod {
+		var pods []*corev1.Pod
+		for _, name := range names {
+			pods = append(pods, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		}
+		return pods
+	}
+	var many []string
+	for i := 0; i < 3*maxConcurrentRouterScrapes; i++ {
+		many = append(many, fmt.Sprintf("hung-%d", i))
+	}
+	testCases := []struct {
+		description       string
+		pods              []*corev1.Pod
+		expectFailing     []string
+		expectUnreachable []string
+		expectScraped     int
+	}{
+		{
+			description: "no pods",
+		},
+		{
+			description:       "mixed results",
+			pods:              pods("ok-a", "failing-a", "error-a", "ok-b"),
+			expectFailing:     []string{"failing-a"},
+			expectUnreachable: []string{"error-a"},
+			expectScraped:     3,
+		},
+		{
+			description:       "hung router",
+			pods:              pods("ok-a", "hung-a"),
+			expectUnreachable: []string{"hung-a"},
+			expectScraped:     1,
+		},
+		{
+			description:       "more hung routers than concurrent scrapes",
+			pods:              pods(many...),
+			expectUnreachable: many,
+		},
+	}
+	for _, tc := range testCases {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		start := time.Now()
+		failing, unreachable, scraped := scrapeReloadFailures(ctx, tc.pods, scrape)
+		elapsed := time.Since(start)
+		cancel()
+		sort.Strings(failing)
+		sort.Strings(unreachable)
+		sort.Strings(tc.expectUnreachable)
+		if elapsed > 5*time.Second {
+			t.Errorf("%q: scraping took %v, expected it to be bounded by the context", tc.description, elapsed)
+		}
+		if !reflect.DeepEqual(failing, tc.expectFailing) || !reflect.DeepEqual(unreachable, tc.expectUnreachable) || scraped != tc.expectScraped {
+			t.Errorf("%q: expected failing %v, unreachable %v, and %d scraped, got %v, %v, and %d", tc.description, tc.expectFailing, tc.expectUnreachable, tc.expectScraped, failing, unreachable, scraped)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// MaxReplicasFromNodesAnnotation may be set on an IngressController to
+	// have the operator scale the router to one replica per eligible node,
+	// up to the value of the annotation.  A node is eligible if it matches
+	// the router's node selector, is schedulable, and is ready.  This is
+	// useful for HostNetwork routers that must run on every eligible node.
+	// If the annotation is set, it overrides spec.replicas, and the
+	// operator adjusts the replicas as nodes are added or removed.
+	MaxReplicasFromNodesAnnotation = "ingress.operator.openshift.io/max-replicas-from-nodes"
+)
+
+// maxReplicasFromNodes returns the maximum number of replicas that the given
+// ingresscontroller requests to scale with the number of eligible nodes, and
+// false if the ingresscontroller does not scale with nodes.
+func maxReplicasFromNodes(ic *operatorv1.IngressController) (int32, bool, error) {
+	value, ok := ic.Annotations[MaxReplicasFromNodesAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+	max, err := parseTuningAnnotation(MaxReplicasFromNodesAnnotation, value, maxReplicasFromNodesOption)
+	if err != nil {
+		return 0, false, err
+	}
+	return int32(max), true, nil
+}
+
+// eligibleNodeCount returns the number of the given nodes that match the given
+// node selector and can run router pods.
+func eligibleNodeCount(nodes []corev1.Node, nodeSelector map[string]string) int32 {
+	selector := labels.SelectorFromSet(nodeSelector)
+	var count int32
+	for i := range nodes {
+		if !selector.Matches(labels.Set(nodes[i].Labels)) || nodes[i].Spec.Unschedulable || !IsNodeReady(&nodes[i]) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// IsNodeReady returns true if the given node's Ready condition is true.
+func IsNodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// replicasFromNodes returns the number of router replicas for the given number
+// of eligible nodes and maximum.  At least one replica is requested so that
+// the ingresscontroller reports that it is unavailable, rather than dormant,
+// if no node is eligible.
+func replicasFromNodes(eligible, max int32) int32 {
+	switch {
+	case eligible < 1:
+		return 1
+	case eligible > max:
+		return max
+	}
+	return eligible
+}
+
+// setReplicasFromNodes sets the replicas of the given router deployment from
+// the number of eligible nodes if the given ingresscontroller requests it.
+func (r *reconciler) setReplicasFromNodes(ic *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	max, ok, err := maxReplicasFromNodes(ic)
+	if err != nil || !ok {
+		return err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes); err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	replicas := replicasFromNodes(eligibleNodeCount(nodes.Items, deployment.Spec.Template.Spec.NodeSelector), max)
+	deployment.Spec.Replicas = &replicas
+	return nil
+}
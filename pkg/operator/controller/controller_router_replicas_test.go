@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaxReplicasFromNodes(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expectMax   int32
+		expectOK    bool
+		expectError bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "valid value",
+			annotations: map[string]string{MaxReplicasFromNodesAnnotation: "5"},
+			expectMax:   5,
+			expectOK:    true,
+		},
+		{
+			description: "zero",
+			annotations: map[string]string{MaxReplicasFromNodesAnnotation: "0"},
+			expectError: true,
+		},
+		{
+			description: "not a number",
+			annotations: map[string]string{MaxReplicasFromNodesAnnotation: "all"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+		}
+		max, ok, err := maxReplicasFromNodes(ic)
+		checkError(t, tc.description, tc.expectError, err)
+		if max != tc.expectMax || ok != tc.expectOK {
+			t.Errorf("%q: expected (%d, %t), got (%d, %t)", tc.description, tc.expectMax, tc.expectOK, max, ok)
+		}
+	}
+}
+
+func TestReplicasFromNodes(t *testing.T) {
+	node := func(name string, labels map[string]string, unschedulable bool, ready corev1.ConditionStatus) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{
+					Type:   corev1.NodeReady,
+					Status: ready,
+				}},
+			},
+		}
+	}
+	infra := map[string]string{"node-role.kubernetes.io/infra": ""}
+	worker := map[string]string{"node-role.kubernetes.io/worker": ""}
+	nodes := []corev1.Node{
+		node("infra-0", infra, false, corev1.ConditionTrue),
+		node("infra-1", infra, false, corev1.ConditionTrue),
+		node("infra-2", infra, true, corev1.ConditionTrue),
+		node("infra-3", infra, false, corev1.ConditionFalse),
+		node("worker-0", worker, false, corev1.ConditionTrue),
+	}
+
+	testCases := []struct {
+		description  string
+		nodeSelector map[string]string
+		max          int32
+		expect       int32
+	}{
+		{
+			description:  "infra nodes below the cap",
+			nodeSelector: infra,
+			max:          3,
+			expect:       2,
+		},
+		{
+			description:  "infra nodes above the cap",
+			nodeSelector: infra,
+			max:          1,
+			expect:       1,
+		},
+		{
+			description: "empty selector matches every node",
+			max:         10,
+			expect:      3,
+		},
+		{
+			description:  "no eligible nodes",
+			nodeSelector: map[string]string{"node-role.kubernetes.io/edge": ""},
+			max:          3,
+			expect:       1,
+		},
+	}
+
+	for _, tc := range testCases {
+		if actual := replicasFromNodes(eligibleNodeCount(nodes, tc.nodeSelector), tc.max); actual != tc.expect {
+			t.Errorf("%q: expected %d replicas, got %d", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+const (
+	// HostNetworkSCCAnnotation is an annotation on an ingresscontroller
+	// that, if set to "true", grants the ingresscontroller's routers the
+	// legacy hostnetwork SCC even though they do not use host networking.
+	// By default, only routers that use the HostNetwork endpoint
+	// publishing strategy are granted the hostnetwork SCC, and other
+	// routers run with a security context that the restricted SCC admits.
+	HostNetworkSCCAnnotation = "ingress.operator.openshift.io/hostnetwork-scc"
+
+	// hostNetworkSCCName is the name of the SCC that allows routers to use
+	// host networking.
+	hostNetworkSCCName = "hostnetwork"
+)
+
+// routerUsesHostNetworkSCC returns true if the routers of the given
+// ingresscontroller need the hostnetwork SCC, either because they use host
+// networking or because the ingresscontroller opts into the SCC.
+func routerUsesHostNetworkSCC(ic *operatorv1.IngressController) (bool, error) {
+	if ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType {
+		return true, nil
+	}
+	value, ok := ic.Annotations[HostNetworkSCCAnnotation]
+	if !ok {
+		return false, nil
+	}
+	use, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for annotation %s: %q: %v", HostNetworkSCCAnnotation, value, err)
+	}
+	return use, nil
+}
+
+// hostNetworkSCCPolicyRule returns the policy rule that allows the use of the
+// hostnetwork SCC.
+func hostNetworkSCCPolicyRule() rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{
+		APIGroups:     []string{"security.openshift.io"},
+		Resources:     []string{"securitycontextconstraints"},
+		Verbs:         []string{"use"},
+		ResourceNames: []string{hostNetworkSCCName},
+	}
+}
+
+// configureRouterSecurityContext sets a security context on the given router
+// deployment that the restricted SCC admits, unless the routers use the
+// hostnetwork SCC, in which case the SCC determines the security context as it
+// always has.
+func configureRouterSecurityContext(deployment *appsv1.Deployment, useHostNetworkSCC bool) {
+	if useHostNetworkSCC {
+		deployment.Spec.Template.Spec.SecurityContext = nil
+		deployment.Spec.Template.Spec.Containers[0].SecurityContext = nil
+		return
+	}
+	trueVar := true
+	deployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{
+		RunAsNonRoot: &trueVar,
+	}
+	deployment.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		// HAProxy binds ports 80 and 443 using a file capability,
+		// which requires privilege escalation to be allowed.
+		AllowPrivilegeEscalation: &trueVar,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"KILL", "MKNOD", "SETGID", "SETUID"},
+		},
+	}
+}
+
+// routerSecurityContextChanged returns true if the security context of the
+// current router deployment differs from the expected one.  The API server
+// defaults a nil pod security context to an empty one, so the two are treated
+// as equal.
+func routerSecurityContextChanged(current, expected *appsv1.Deployment) bool {
+	currentPod := current.Spec.Template.Spec.SecurityContext
+	if currentPod == nil {
+		currentPod = &corev1.PodSecurityContext{}
+	}
+	expectedPod := expected.Spec.Template.Spec.SecurityContext
+	if expectedPod == nil {
+		expectedPod = &corev1.PodSecurityContext{}
+	}
+	if !cmp.Equal(currentPod, expectedPod) {
+		return true
+	}
+	return !cmp.Equal(current.Spec.Template.Spec.Containers[0].SecurityContext, expected.Spec.Template.Spec.Containers[0].SecurityContext)
+}
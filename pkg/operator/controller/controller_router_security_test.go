@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRouterUsesHostNetworkSCC(t *testing.T) {
+	testCases := []struct {
+		description string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		annotation  *string
+		expect      bool
+		expectError bool
+	}{
+		{
+			description: "HostNetwork strategy",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expect:      true,
+		},
+		{
+			description: "LoadBalancerService strategy",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect:      false,
+		},
+		{
+			description: "LoadBalancerService strategy with opt-in",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotation:  pointerToString("true"),
+			expect:      true,
+		},
+		{
+			description: "HostNetwork strategy with opt-out",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			annotation:  pointerToString("false"),
+			expect:      true,
+		},
+		{
+			description: "invalid annotation",
+			strategy:    operatorv1.PrivateStrategyType,
+			annotation:  pointerToString("yes please"),
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+			},
+		}
+		if tc.annotation != nil {
+			ic.Annotations = map[string]string{HostNetworkSCCAnnotation: *tc.annotation}
+		}
+		actual, err := routerUsesHostNetworkSCC(ic)
+		checkError(t, tc.description, tc.expectError, err)
+		if actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestConfigureRouterSecurityContext(t *testing.T) {
+	deployment := newTestRouterDeployment()
+	configureRouterSecurityContext(deployment, false)
+	podContext := deployment.Spec.Template.Spec.SecurityContext
+	if podContext == nil || podContext.RunAsNonRoot == nil || !*podContext.RunAsNonRoot {
+		t.Errorf("expected the pod to run as non-root, got %v", podContext)
+	}
+	if deployment.Spec.Template.Spec.Containers[0].SecurityContext == nil {
+		t.Error("expected the router container to have a security context")
+	}
+
+	expected := deployment.DeepCopy()
+	configureRouterSecurityContext(expected, true)
+	if expected.Spec.Template.Spec.SecurityContext != nil || expected.Spec.Template.Spec.Containers[0].SecurityContext != nil {
+		t.Error("expected no security context when the hostnetwork SCC is used")
+	}
+	if !routerSecurityContextChanged(deployment, expected) {
+		t.Error("expected switching to the hostnetwork SCC to change the deployment")
+	}
+
+	// The API server defaults a nil pod security context to an empty one.
+	current := expected.DeepCopy()
+	current.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	if routerSecurityContextChanged(current, expected) {
+		t.Error("expected an empty pod security context to equal a nil one")
+	}
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ShutdownDelaySecondsAnnotation may be set on an ingresscontroller to
+	// delay the termination of its router pods by the given number of
+	// seconds.  A terminating router pod is removed from the endpoints of
+	// the router service right away, but cloud load balancers take a few
+	// health checks to notice and keep sending it new connections, which
+	// fail once the router exits.  With a delay, the router keeps serving
+	// while the load balancers drain it, which reduces 502 errors during
+	// rollouts and node drains.  The default is 0, which stops the router
+	// immediately.
+	ShutdownDelaySecondsAnnotation = "ingress.operator.openshift.io/shutdown-delay-seconds"
+
+	// defaultTerminationGracePeriodSeconds is the API's default grace
+	// period for a pod to stop after it has been sent SIGTERM.
+	defaultTerminationGracePeriodSeconds = 30
+)
+
+// shutdownDelaySecondsFor returns the number of seconds by which the router
+// pods of the given ingresscontroller delay their termination.
+func shutdownDelaySecondsFor(ic *operatorv1.IngressController) (int64, error) {
+	value, ok := ic.Annotations[ShutdownDelaySecondsAnnotation]
+	if !ok || len(value) == 0 {
+		return 0, nil
+	}
+	return parseTuningAnnotation(ShutdownDelaySecondsAnnotation, value, shutdownDelaySecondsOption)
+}
+
+// configureRouterShutdownDelay adds a preStop hook that sleeps for the
+// ingresscontroller's shutdown delay to the router container and extends the
+// pod's termination grace period by the delay, which the preStop hook
+// otherwise counts against.
+func configureRouterShutdownDelay(ic *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	delay, err := shutdownDelaySecondsFor(ic)
+	if err != nil || delay == 0 {
+		return err
+	}
+	deployment.Spec.Template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sleep", strconv.FormatInt(delay, 10)},
+			},
+		},
+	}
+	grace := delay + defaultTerminationGracePeriodSeconds
+	deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = &grace
+	return nil
+}
+
+// routerShutdownDelayChanged returns true if the router container's preStop
+// hook or the pod's termination grace period differ between the given
+// deployments.
+func routerShutdownDelayChanged(current, expected *appsv1.Deployment) bool {
+	gracePeriod := func(deployment *appsv1.Deployment) int64 {
+		if deployment.Spec.Template.Spec.TerminationGracePeriodSeconds == nil {
+			return defaultTerminationGracePeriodSeconds
+		}
+		return *deployment.Spec.Template.Spec.TerminationGracePeriodSeconds
+	}
+	preStop := func(deployment *appsv1.Deployment) *corev1.Handler {
+		if lifecycle := deployment.Spec.Template.Spec.Containers[0].Lifecycle; lifecycle != nil {
+			return lifecycle.PreStop
+		}
+		return nil
+	}
+	return gracePeriod(current) != gracePeriod(expected) || !cmp.Equal(preStop(current), preStop(expected), cmpopts.EquateEmpty())
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigureRouterShutdownDelay(t *testing.T) {
+	testCases := []struct {
+		description   string
+		annotations   map[string]string
+		expectErr     bool
+		expectPreStop []string
+		expectGrace   *int64
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "zero delay",
+			annotations: map[string]string{ShutdownDelaySecondsAnnotation: "0"},
+		},
+		{
+			description:   "45 second delay",
+			annotations:   map[string]string{ShutdownDelaySecondsAnnotation: "45"},
+			expectPreStop: []string{"sleep", "45"},
+			expectGrace:   func() *int64 { v := int64(75); return &v }(),
+		},
+		{
+			description: "negative delay",
+			annotations: map[string]string{ShutdownDelaySecondsAnnotation: "-1"},
+			expectErr:   true,
+		},
+		{
+			description: "delay too long",
+			annotations: map[string]string{ShutdownDelaySecondsAnnotation: "601"},
+			expectErr:   true,
+		},
+		{
+			description: "duration instead of seconds",
+			annotations: map[string]string{ShutdownDelaySecondsAnnotation: "45s"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "router"}}
+		err := configureRouterShutdownDelay(ic, deployment)
+		checkError(t, tc.description, tc.expectErr, err)
+		var preStop []string
+		if lifecycle := deployment.Spec.Template.Spec.Containers[0].Lifecycle; lifecycle != nil {
+			preStop = lifecycle.PreStop.Exec.Command
+		}
+		if !reflect.DeepEqual(preStop, tc.expectPreStop) {
+			t.Errorf("%q: expected preStop command %v, got %v", tc.description, tc.expectPreStop, preStop)
+		}
+		if !reflect.DeepEqual(deployment.Spec.Template.Spec.TerminationGracePeriodSeconds, tc.expectGrace) {
+			t.Errorf("%q: expected termination grace period %v, got %v", tc.description, tc.expectGrace, deployment.Spec.Template.Spec.TerminationGracePeriodSeconds)
+		}
+	}
+}
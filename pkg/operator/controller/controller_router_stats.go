@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// StatsCredentialsRotationAnnotation may be set on an IngressController
+	// to rotate the credentials for the router's stats listener.  Whenever
+	// the value of the annotation changes, the operator generates new
+	// credentials, which causes the router pods to be rolled out.  The
+	// value is arbitrary; a timestamp is a convenient choice.
+	StatsCredentialsRotationAnnotation = "ingress.operator.openshift.io/stats-credentials-rotation"
+
+	// StatsServicePortAnnotation may be set to "Enabled" on an
+	// IngressController to expose the router's stats listener on the
+	// internal router service as the "stats" port, so that in-cluster
+	// clients can read the stats with the credentials from the stats
+	// secret without addressing router pods.  The default is "Disabled".
+	StatsServicePortAnnotation = "ingress.operator.openshift.io/stats-service-port"
+
+	// statsServicePortName and statsServicePort are the name and number of
+	// the internal router service's stats port.  The listener's own port
+	// is exposed as the "metrics" port for monitoring, so the stats port
+	// uses the port that HAProxy conventionally uses for stats.
+	statsServicePortName = "stats"
+	statsServicePort     = 8404
+
+	statsServicePortEnabledValue  = "Enabled"
+	statsServicePortDisabledValue = "Disabled"
+
+	// statsUsernameKey and statsPasswordKey are the keys of the stats
+	// credentials in the stats secret.
+	statsUsernameKey = "statsUsername"
+	statsPasswordKey = "statsPassword"
+
+	// statsCredentialBytes is the number of random bytes in each generated
+	// stats credential.
+	statsCredentialBytes = 16
+)
+
+// desiredRouterStatsEnv returns the environment variables with which the
+// router reads the credentials for its stats listener from the stats secret.
+func desiredRouterStatsEnv(ic *operatorv1.IngressController, operandNamespace string) []corev1.EnvVar {
+	secretName := RouterStatsSecretName(ic, operandNamespace).Name
+	envFor := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+				Key: key,
+			},
+		}}
+	}
+	return []corev1.EnvVar{
+		envFor("STATS_USERNAME", statsUsernameKey),
+		envFor("STATS_PASSWORD", statsPasswordKey),
+	}
+}
+
+// statsServicePortEnabled returns true if the given ingresscontroller enables
+// StatsServicePortAnnotation.
+func statsServicePortEnabled(ic *operatorv1.IngressController) (bool, error) {
+	switch value := ic.Annotations[StatsServicePortAnnotation]; value {
+	case "", statsServicePortDisabledValue:
+		return false, nil
+	case statsServicePortEnabledValue:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", StatsServicePortAnnotation, value, statsServicePortEnabledValue, statsServicePortDisabledValue)
+	}
+}
+
+// configureStatsServicePort adds the stats port to the given internal router
+// service if the given ingresscontroller enables it.  The port targets the
+// router container's metrics port, on which the stats listener serves.
+func configureStatsServicePort(service *corev1.Service, ic *operatorv1.IngressController) error {
+	enabled, err := statsServicePortEnabled(ic)
+	if err != nil || !enabled {
+		return err
+	}
+	service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+		Name:       statsServicePortName,
+		Port:       statsServicePort,
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromString("metrics"),
+	})
+	return nil
+}
+
+// generateStatsCredential returns a new random stats credential with the given
+// prefix.
+func generateStatsCredential(prefix string) ([]byte, error) {
+	b := make([]byte, statsCredentialBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %v", err)
+	}
+	return []byte(prefix + hex.EncodeToString(b)), nil
+}
+
+// desiredRouterStatsSecret returns a stats secret with newly generated
+// credentials for the given ingresscontroller.
+func desiredRouterStatsSecret(ic *operatorv1.IngressController, operandNamespace string, deploymentRef metav1.OwnerReference) (*corev1.Secret, error) {
+	username, err := generateStatsCredential("user")
+	if err != nil {
+		return nil, err
+	}
+	password, err := generateStatsCredential("pass")
+	if err != nil {
+		return nil, err
+	}
+	name := RouterStatsSecretName(ic, operandNamespace)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{deploymentRef},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			statsUsernameKey: username,
+			statsPasswordKey: password,
+		},
+	}
+	if rotation, ok := ic.Annotations[StatsCredentialsRotationAnnotation]; ok {
+		secret.Annotations = map[string]string{StatsCredentialsRotationAnnotation: rotation}
+	}
+	return secret, nil
+}
+
+// routerStatsSecretNeedsCredentials returns true if the given stats secret is
+// missing credentials or if the given ingresscontroller requests a rotation of
+// the credentials that the secret does not yet reflect.
+func routerStatsSecretNeedsCredentials(ic *operatorv1.IngressController, current *corev1.Secret) bool {
+	if len(current.Data[statsUsernameKey]) == 0 || len(current.Data[statsPasswordKey]) == 0 {
+		return true
+	}
+	return ic.Annotations[StatsCredentialsRotationAnnotation] != current.Annotations[StatsCredentialsRotationAnnotation]
+}
+
+// ensureRouterStatsSecret ensures that the stats secret for the given
+// ingresscontroller exists and has credentials, generating new credentials if
+// the ingresscontroller requests a rotation.  Because the router deployment
+// hashes the secret, new credentials cause the router pods to be rolled out.
+func (r *reconciler) ensureRouterStatsSecret(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) error {
+	name := RouterStatsSecretName(ic, r.Config.OperandNamespace)
+	current := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get router stats secret %s: %v", name, err)
+		}
+		desired, err := desiredRouterStatsSecret(ic, r.Config.OperandNamespace, deploymentRef)
+		if err != nil {
+			return fmt.Errorf("failed to build router stats secret %s: %v", name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router stats secret %s: %v", name, err)
+		}
+		log.Info("created router stats secret", "namespace", name.Namespace, "name", name.Name)
+		return nil
+	}
+	if !routerStatsSecretNeedsCredentials(ic, current) {
+		return nil
+	}
+	desired, err := desiredRouterStatsSecret(ic, r.Config.OperandNamespace, deploymentRef)
+	if err != nil {
+		return fmt.Errorf("failed to build router stats secret %s: %v", name, err)
+	}
+	updated := current.DeepCopy()
+	updated.Data = desired.Data
+	if rotation, ok := desired.Annotations[StatsCredentialsRotationAnnotation]; ok {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[StatsCredentialsRotationAnnotation] = rotation
+	} else {
+		delete(updated.Annotations, StatsCredentialsRotationAnnotation)
+	}
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update router stats secret %s: %v", name, err)
+	}
+	log.Info("generated new router stats credentials", "namespace", name.Namespace, "name", name.Name)
+	return nil
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredRouterStatsSecret(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{StatsCredentialsRotationAnnotation: "1"},
+		},
+	}
+	secret, err := desiredRouterStatsSecret(ic, DefaultOperandNamespace, metav1.OwnerReference{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Name != "router-stats-default" || secret.Namespace != "openshift-ingress" {
+		t.Errorf("unexpected name %s/%s", secret.Namespace, secret.Name)
+	}
+	if routerStatsSecretNeedsCredentials(ic, secret) {
+		t.Error("expected a new secret not to need credentials")
+	}
+	other, err := desiredRouterStatsSecret(ic, DefaultOperandNamespace, metav1.OwnerReference{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data[statsPasswordKey]) == string(other.Data[statsPasswordKey]) {
+		t.Error("expected each secret to have a different password")
+	}
+}
+
+func TestDesiredRouterStatsSecretOperandNamespace(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	secret, err := desiredRouterStatsSecret(ic, "custom-ingress", metav1.OwnerReference{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Namespace != "custom-ingress" {
+		t.Errorf("expected namespace custom-ingress, got %s", secret.Namespace)
+	}
+	deployment, err := desiredRouterDeployment(ic, "custom-ingress", "quay.io/openshift/router:latest", &configv1.Infrastructure{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Namespace != "custom-ingress" {
+		t.Errorf("expected deployment namespace custom-ingress, got %s", deployment.Namespace)
+	}
+	if v, _ := envValue(&deployment.Spec.Template.Spec.Containers[0], "ROUTER_SERVICE_NAMESPACE"); v != "custom-ingress" {
+		t.Errorf("expected ROUTER_SERVICE_NAMESPACE custom-ingress, got %q", v)
+	}
+}
+
+func TestRouterStatsSecretNeedsCredentials(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	secret, err := desiredRouterStatsSecret(ic, DefaultOperandNamespace, metav1.OwnerReference{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		mutate      func(*operatorv1.IngressController, *corev1.Secret)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(*operatorv1.IngressController, *corev1.Secret) {},
+			expect:      false,
+		},
+		{
+			description: "if the password is missing",
+			mutate: func(_ *operatorv1.IngressController, s *corev1.Secret) {
+				delete(s.Data, statsPasswordKey)
+			},
+			expect: true,
+		},
+		{
+			description: "if the username is empty",
+			mutate: func(_ *operatorv1.IngressController, s *corev1.Secret) {
+				s.Data[statsUsernameKey] = nil
+			},
+			expect: true,
+		},
+		{
+			description: "if a rotation is requested",
+			mutate: func(ic *operatorv1.IngressController, _ *corev1.Secret) {
+				ic.Annotations = map[string]string{StatsCredentialsRotationAnnotation: "2020-01-01T00:00:00Z"}
+			},
+			expect: true,
+		},
+	}
+	for _, tc := range testCases {
+		mutatedIC := ic.DeepCopy()
+		mutatedSecret := secret.DeepCopy()
+		tc.mutate(mutatedIC, mutatedSecret)
+		if actual := routerStatsSecretNeedsCredentials(mutatedIC, mutatedSecret); actual != tc.expect {
+			t.Errorf("%s, expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestDesiredInternalIngressControllerServiceStatsPort(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       string
+		expectPort  bool
+		expectErr   bool
+	}{
+		{description: "no annotation"},
+		{description: "enabled", value: "Enabled", expectPort: true},
+		{description: "disabled", value: "Disabled"},
+		{description: "invalid value", value: "true", expectErr: true},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(StatsServicePortAnnotation, tc.value)
+		service, err := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		var port *corev1.ServicePort
+		for i := range service.Spec.Ports {
+			if service.Spec.Ports[i].Name == statsServicePortName {
+				port = &service.Spec.Ports[i]
+			}
+		}
+		switch {
+		case tc.expectPort && port == nil:
+			t.Errorf("%q: expected a %s port", tc.description, statsServicePortName)
+		case tc.expectPort && (port.Port != statsServicePort || port.TargetPort.StrVal != "metrics"):
+			t.Errorf("%q: unexpected %s port %v", tc.description, statsServicePortName, *port)
+		case !tc.expectPort && port != nil:
+			t.Errorf("%q: expected no %s port, got %v", tc.description, statsServicePortName, *port)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// TLSCiphersAnnotation may be set on an ingresscontroller to specify
+	// the cipher suites that its routers offer for TLS connections.  The
+	// value is a colon-separated list of OpenSSL cipher names, for
+	// example:
+	//
+	//   ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:TLS_AES_128_GCM_SHA256
+	//
+	// TLS 1.3 cipher suites, whose names begin with "TLS_", and TLS 1.2
+	// and older ciphers are configured separately in HAProxy, but may be
+	// mixed in the list.  If the annotation is not set, the router uses its
+	// default ciphers, or on FIPS clusters, fipsDefaultCiphers.  On FIPS
+	// clusters, ciphers that are not FIPS-approved are rejected.
+	TLSCiphersAnnotation = "ingress.operator.openshift.io/tls-ciphers"
+
+	// MinTLSVersionAnnotation may be set on an ingresscontroller to specify
+	// the minimum TLS version that its routers accept, independently of
+	// the ciphers.  The value is one of "VersionTLS10", "VersionTLS11",
+	// "VersionTLS12", or "VersionTLS13".  If the annotation is not set, the
+	// router uses its default minimum version.  TLS 1.0 and 1.1 are
+	// rejected on FIPS clusters, and TLS 1.3 is rejected if the
+	// ingresscontroller specifies ciphers but no TLS 1.3 cipher suites.
+	MinTLSVersionAnnotation = "ingress.operator.openshift.io/min-tls-version"
+
+	// TLSProfileConditionType is the type of the ingresscontroller
+	// condition that reports the minimum TLS version and the ciphers with
+	// which the routers are deployed, so that scanners can verify the
+	// effective settings.
+	TLSProfileConditionType = "TLSProfile"
+
+	// clusterConfigNamespace and clusterConfigName identify the configmap
+	// in which the installer records the install config.
+	clusterConfigNamespace = "kube-system"
+	clusterConfigName      = "cluster-config-v1"
+	// installConfigKey is the key of the install config in the configmap.
+	installConfigKey = "install-config"
+
+	// tls13CipherPrefix is the prefix of the names of TLS 1.3 cipher
+	// suites.
+	tls13CipherPrefix = "TLS_"
+)
+
+// fipsApprovedCiphers are the OpenSSL cipher names that are approved for use
+// in FIPS mode.
+var fipsApprovedCiphers = map[string]bool{
+	"ECDHE-ECDSA-AES128-GCM-SHA256": true,
+	"ECDHE-RSA-AES128-GCM-SHA256":   true,
+	"ECDHE-ECDSA-AES256-GCM-SHA384": true,
+	"ECDHE-RSA-AES256-GCM-SHA384":   true,
+	"ECDHE-ECDSA-AES128-SHA256":     true,
+	"ECDHE-RSA-AES128-SHA256":       true,
+	"ECDHE-ECDSA-AES256-SHA384":     true,
+	"ECDHE-RSA-AES256-SHA384":       true,
+	"DHE-RSA-AES128-GCM-SHA256":     true,
+	"DHE-RSA-AES256-GCM-SHA384":     true,
+	"AES128-GCM-SHA256":             true,
+	"AES256-GCM-SHA384":             true,
+	"TLS_AES_128_GCM_SHA256":        true,
+	"TLS_AES_256_GCM_SHA384":        true,
+}
+
+// fipsDefaultCiphers are the ciphers that routers on FIPS clusters offer if
+// the ingresscontroller does not specify any.
+var fipsDefaultCiphers = []string{
+	"ECDHE-ECDSA-AES128-GCM-SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256",
+	"ECDHE-ECDSA-AES256-GCM-SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384",
+	"TLS_AES_128_GCM_SHA256",
+	"TLS_AES_256_GCM_SHA384",
+}
+
+// haproxyTLSVersions maps the valid values of MinTLSVersionAnnotation to the
+// TLS versions that the router's HAProxy accepts.
+var haproxyTLSVersions = map[string]string{
+	"VersionTLS10": "TLSv1.0",
+	"VersionTLS11": "TLSv1.1",
+	"VersionTLS12": "TLSv1.2",
+	"VersionTLS13": "TLSv1.3",
+}
+
+// installConfig is the part of the install config that the operator reads.
+type installConfig struct {
+	FIPS       bool `json:"fips"`
+	Networking struct {
+		// MachineNetwork lists the networks of the cluster's
+		// machines.
+		MachineNetwork []struct {
+			CIDR string `json:"cidr"`
+		} `json:"machineNetwork"`
+		// MachineCIDR is the deprecated single machine network,
+		// which older install configs use instead of MachineNetwork.
+		MachineCIDR string `json:"machineCIDR"`
+	} `json:"networking"`
+}
+
+// machineNetworks returns the CIDRs of the machine networks in the given
+// install config.
+func (c *installConfig) machineNetworks() []string {
+	var cidrs []string
+	for _, network := range c.Networking.MachineNetwork {
+		if len(network.CIDR) != 0 {
+			cidrs = append(cidrs, network.CIDR)
+		}
+	}
+	if len(cidrs) == 0 && len(c.Networking.MachineCIDR) != 0 {
+		cidrs = append(cidrs, c.Networking.MachineCIDR)
+	}
+	return cidrs
+}
+
+// tlsCiphersFor returns the ciphers that the given ingresscontroller specifies,
+// or nil if it does not specify any.  If fipsEnabled is true, every cipher must
+// be FIPS-approved.
+func tlsCiphersFor(ic *operatorv1.IngressController, fipsEnabled bool) ([]string, error) {
+	value, ok := ic.Annotations[TLSCiphersAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	ciphers := strings.Split(value, ":")
+	var denied []string
+	for _, cipher := range ciphers {
+		if len(cipher) == 0 {
+			return nil, fmt.Errorf("invalid value for annotation %s: %q: must be a colon-separated list of cipher names", TLSCiphersAnnotation, value)
+		}
+		if fipsEnabled && !fipsApprovedCiphers[cipher] {
+			denied = append(denied, cipher)
+		}
+	}
+	if len(denied) != 0 {
+		return nil, fmt.Errorf("invalid value for annotation %s: the cluster is in FIPS mode, and the following ciphers are not FIPS-approved: %s", TLSCiphersAnnotation, strings.Join(denied, ", "))
+	}
+	return ciphers, nil
+}
+
+// minTLSVersionFor returns the minimum TLS version, as HAProxy names it, that
+// the given ingresscontroller specifies, or the empty string if it does not
+// specify one.
+func minTLSVersionFor(ic *operatorv1.IngressController, fipsEnabled bool) (string, error) {
+	value, ok := ic.Annotations[MinTLSVersionAnnotation]
+	if !ok {
+		return "", nil
+	}
+	version, ok := haproxyTLSVersions[value]
+	if !ok {
+		return "", fmt.Errorf("invalid value for annotation %s: %q: must be VersionTLS10, VersionTLS11, VersionTLS12, or VersionTLS13", MinTLSVersionAnnotation, value)
+	}
+	if fipsEnabled && (version == "TLSv1.0" || version == "TLSv1.1") {
+		return "", fmt.Errorf("invalid value for annotation %s: %q: the cluster is in FIPS mode, which requires TLS 1.2 or newer", MinTLSVersionAnnotation, value)
+	}
+	if version == "TLSv1.3" {
+		// An invalid cipher list is reported by tlsCiphersFor.
+		ciphers, _ := tlsCiphersFor(ic, fipsEnabled)
+		if ciphers != nil && !hasTLS13Cipher(ciphers) {
+			return "", fmt.Errorf("invalid value for annotation %s: %q: annotation %s specifies no TLS 1.3 cipher suites", MinTLSVersionAnnotation, value, TLSCiphersAnnotation)
+		}
+	}
+	return version, nil
+}
+
+// hasTLS13Cipher returns true if the given ciphers include a TLS 1.3 cipher
+// suite.
+func hasTLS13Cipher(ciphers []string) bool {
+	for _, cipher := range ciphers {
+		if strings.HasPrefix(cipher, tls13CipherPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// configureRouterTLS sets the environment variables of the given router
+// container that configure the minimum TLS version and the ciphers that the
+// router accepts.
+func configureRouterTLS(container *corev1.Container, ic *operatorv1.IngressController, fipsEnabled bool) error {
+	ciphers, err := tlsCiphersFor(ic, fipsEnabled)
+	if err != nil {
+		return err
+	}
+	minVersion, err := minTLSVersionFor(ic, fipsEnabled)
+	if err != nil {
+		return err
+	}
+	if len(minVersion) != 0 {
+		container.Env = append(container.Env, corev1.EnvVar{Name: "SSL_MIN_VERSION", Value: minVersion})
+	}
+	if ciphers == nil && fipsEnabled {
+		ciphers = fipsDefaultCiphers
+	}
+	var tls12, tls13 []string
+	for _, cipher := range ciphers {
+		if strings.HasPrefix(cipher, tls13CipherPrefix) {
+			tls13 = append(tls13, cipher)
+		} else {
+			tls12 = append(tls12, cipher)
+		}
+	}
+	if len(tls12) != 0 {
+		container.Env = append(container.Env, corev1.EnvVar{Name: "ROUTER_CIPHERS", Value: strings.Join(tls12, ":")})
+	}
+	if len(tls13) != 0 {
+		container.Env = append(container.Env, corev1.EnvVar{Name: "ROUTER_CIPHERSUITES", Value: strings.Join(tls13, ":")})
+	}
+	return nil
+}
+
+// setRouterTLS configures the minimum TLS version and the ciphers of the given
+// router deployment for the given ingresscontroller.
+func (r *reconciler) setRouterTLS(ic *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	fipsEnabled, err := r.fipsEnabled()
+	if err != nil {
+		return err
+	}
+	return configureRouterTLS(&deployment.Spec.Template.Spec.Containers[0], ic, fipsEnabled)
+}
+
+// fipsEnabled returns true if the cluster was installed in FIPS mode, as
+// recorded in the install config.  A cluster without an install config, such
+// as one that was not installed by the installer, is assumed not to be in FIPS
+// mode.
+func (r *reconciler) fipsEnabled() (bool, error) {
+	config, err := r.installConfig()
+	if err != nil {
+		return false, err
+	}
+	return config.FIPS, nil
+}
+
+// installConfig returns the cluster's install config, which is empty if the
+// cluster has none.
+func (r *reconciler) installConfig() (*installConfig, error) {
+	cm := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: clusterConfigNamespace, Name: clusterConfigName}
+	if err := r.client.Get(context.TODO(), name, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return &installConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to get configmap %s: %v", name, err)
+	}
+	return parseInstallConfig(cm)
+}
+
+// isFIPSEnabled returns true if the install config in the given configmap
+// enables FIPS mode.
+func isFIPSEnabled(cm *corev1.ConfigMap) (bool, error) {
+	config, err := parseInstallConfig(cm)
+	if err != nil {
+		return false, err
+	}
+	return config.FIPS, nil
+}
+
+// parseInstallConfig returns the install config in the given configmap, which
+// is empty if the configmap has none.
+func parseInstallConfig(cm *corev1.ConfigMap) (*installConfig, error) {
+	config := &installConfig{}
+	data, ok := cm.Data[installConfigKey]
+	if !ok {
+		return config, nil
+	}
+	if err := yaml.Unmarshal([]byte(data), config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s in configmap %s/%s: %v", installConfigKey, cm.Namespace, cm.Name, err)
+	}
+	return config, nil
+}
+
+// computeTLSProfileCondition returns the TLSProfile condition for the given
+// router deployment.  The condition reports the settings in the deployment's
+// pod template rather than the ingresscontroller's annotations so that it
+// reflects what the operator has deployed.
+func computeTLSProfileCondition(deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   TLSProfileConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "RouterDefaults",
+	}
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		condition.Status = operatorv1.ConditionUnknown
+		condition.Reason = "NoRouterContainer"
+		return condition
+	}
+	settings := []struct{ name, env, value string }{
+		{name: "minTLSVersion", env: "SSL_MIN_VERSION"},
+		{name: "ciphers", env: "ROUTER_CIPHERS"},
+		{name: "cipherSuites", env: "ROUTER_CIPHERSUITES"},
+	}
+	var parts []string
+	for _, setting := range settings {
+		value := "default"
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == setting.env {
+				value = env.Value
+				condition.Reason = "Configured"
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", setting.name, value))
+	}
+	condition.Message = strings.Join(parts, "; ")
+	return condition
+}
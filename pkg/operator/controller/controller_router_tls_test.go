@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConfigureRouterTLS(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		fipsEnabled bool
+		expect      []corev1.EnvVar
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "no annotation in FIPS mode",
+			fipsEnabled: true,
+			expect: []corev1.EnvVar{
+				{Name: "ROUTER_CIPHERS", Value: "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384"},
+				{Name: "ROUTER_CIPHERSUITES", Value: "TLS_AES_128_GCM_SHA256:TLS_AES_256_GCM_SHA384"},
+			},
+		},
+		{
+			description: "custom ciphers",
+			annotations: map[string]string{TLSCiphersAnnotation: "ECDHE-RSA-CHACHA20-POLY1305:TLS_CHACHA20_POLY1305_SHA256"},
+			expect: []corev1.EnvVar{
+				{Name: "ROUTER_CIPHERS", Value: "ECDHE-RSA-CHACHA20-POLY1305"},
+				{Name: "ROUTER_CIPHERSUITES", Value: "TLS_CHACHA20_POLY1305_SHA256"},
+			},
+		},
+		{
+			description: "FIPS-approved custom ciphers in FIPS mode",
+			annotations: map[string]string{TLSCiphersAnnotation: "ECDHE-RSA-AES256-GCM-SHA384"},
+			fipsEnabled: true,
+			expect:      []corev1.EnvVar{{Name: "ROUTER_CIPHERS", Value: "ECDHE-RSA-AES256-GCM-SHA384"}},
+		},
+		{
+			description: "non-FIPS custom ciphers in FIPS mode",
+			annotations: map[string]string{TLSCiphersAnnotation: "ECDHE-RSA-AES256-GCM-SHA384:ECDHE-RSA-CHACHA20-POLY1305"},
+			fipsEnabled: true,
+			expectErr:   true,
+		},
+		{
+			description: "empty cipher name",
+			annotations: map[string]string{TLSCiphersAnnotation: "ECDHE-RSA-AES256-GCM-SHA384::"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		container := &corev1.Container{}
+		err := configureRouterTLS(container, ic, tc.fipsEnabled)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if !reflect.DeepEqual(container.Env, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, container.Env)
+		}
+	}
+}
+
+func TestIsFIPSEnabled(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        map[string]string
+		expect      bool
+	}{
+		{"no install config", nil, false},
+		{"FIPS not set", map[string]string{installConfigKey: "apiVersion: v1\nbaseDomain: example.com\n"}, false},
+		{"FIPS disabled", map[string]string{installConfigKey: "apiVersion: v1\nfips: false\n"}, false},
+		{"FIPS enabled", map[string]string{installConfigKey: "apiVersion: v1\nfips: true\n"}, true},
+	}
+	for _, tc := range testCases {
+		cm := &corev1.ConfigMap{Data: tc.data}
+		actual, err := isFIPSEnabled(cm)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		} else if actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestMinTLSVersionFor(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		fipsEnabled bool
+		expect      string
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "TLS 1.3",
+			annotations: map[string]string{MinTLSVersionAnnotation: "VersionTLS13"},
+			expect:      "TLSv1.3",
+		},
+		{
+			description: "TLS 1.3 with TLS 1.3 cipher suites",
+			annotations: map[string]string{
+				MinTLSVersionAnnotation: "VersionTLS13",
+				TLSCiphersAnnotation:    "ECDHE-RSA-AES128-GCM-SHA256:TLS_AES_128_GCM_SHA256",
+			},
+			expect: "TLSv1.3",
+		},
+		{
+			description: "TLS 1.3 without TLS 1.3 cipher suites",
+			annotations: map[string]string{
+				MinTLSVersionAnnotation: "VersionTLS13",
+				TLSCiphersAnnotation:    "ECDHE-RSA-AES128-GCM-SHA256",
+			},
+			expectErr: true,
+		},
+		{
+			description: "TLS 1.0",
+			annotations: map[string]string{MinTLSVersionAnnotation: "VersionTLS10"},
+			expect:      "TLSv1.0",
+		},
+		{
+			description: "TLS 1.0 in FIPS mode",
+			annotations: map[string]string{MinTLSVersionAnnotation: "VersionTLS10"},
+			fipsEnabled: true,
+			expectErr:   true,
+		},
+		{
+			description: "invalid version",
+			annotations: map[string]string{MinTLSVersionAnnotation: "TLSv1.2"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		version, err := minTLSVersionFor(ic, tc.fipsEnabled)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if version != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, version)
+		}
+	}
+}
+
+func TestComputeTLSProfileCondition(t *testing.T) {
+	deploymentWithEnv := func(env ...corev1.EnvVar) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "router", Env: env}}
+		return deployment
+	}
+	testCases := []struct {
+		description   string
+		deployment    *appsv1.Deployment
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			description:   "router defaults",
+			deployment:    deploymentWithEnv(),
+			expectReason:  "RouterDefaults",
+			expectMessage: "minTLSVersion=default; ciphers=default; cipherSuites=default",
+		},
+		{
+			description:   "TLS 1.3 only",
+			deployment:    deploymentWithEnv(corev1.EnvVar{Name: "SSL_MIN_VERSION", Value: "TLSv1.3"}, corev1.EnvVar{Name: "ROUTER_CIPHERSUITES", Value: "TLS_AES_128_GCM_SHA256"}),
+			expectReason:  "Configured",
+			expectMessage: "minTLSVersion=TLSv1.3; ciphers=default; cipherSuites=TLS_AES_128_GCM_SHA256",
+		},
+	}
+	for _, tc := range testCases {
+		condition := computeTLSProfileCondition(tc.deployment)
+		if condition.Status != operatorv1.ConditionTrue || condition.Reason != tc.expectReason || condition.Message != tc.expectMessage {
+			t.Errorf("%q: expected reason %q and message %q, got %v", tc.description, tc.expectReason, tc.expectMessage, condition)
+		}
+	}
+}
+
+func TestInstallConfigMachineNetworks(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        map[string]string
+		expect      []string
+	}{
+		{"no install config", nil, nil},
+		{"machine networks", map[string]string{installConfigKey: "networking:\n  machineNetwork:\n  - cidr: 10.0.0.0/16\n  - cidr: fd00::/48\n"}, []string{"10.0.0.0/16", "fd00::/48"}},
+		{"deprecated machine CIDR", map[string]string{installConfigKey: "networking:\n  machineCIDR: 10.0.0.0/16\n"}, []string{"10.0.0.0/16"}},
+	}
+	for _, tc := range testCases {
+		config, err := parseInstallConfig(&corev1.ConfigMap{Data: tc.data})
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		} else if actual := config.machineNetworks(); !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// tuningOption names a numeric tuning option of the routers.  The name is the
+// option's field in the JSON value of the annotation that sets it, or the
+// annotation's name without its prefix if the annotation's value is a number.
+type tuningOption string
+
+const (
+	probeTimeoutSecondsOption       tuningOption = "probe-timeout-seconds"
+	startupGracePeriodSecondsOption tuningOption = "startup-grace-period-seconds"
+	shutdownDelaySecondsOption      tuningOption = "shutdown-delay-seconds"
+	maxReplicasFromNodesOption      tuningOption = "max-replicas-from-nodes"
+
+	maxConnectionsOption         tuningOption = "maxConnections"
+	maxConnectionsPerRouteOption tuningOption = "maxConnectionsPerRoute"
+
+	blueprintRoutePoolSizeOption tuningOption = "blueprintRoutePoolSize"
+	maxDynamicServersOption      tuningOption = "maxDynamicServers"
+
+	routesPerThreadOption       tuningOption = "routesPerThread"
+	serversPerGiBOption         tuningOption = "serversPerGiB"
+	connectionsPerBackendOption tuningOption = "connectionsPerBackend"
+
+	healthCheckIntervalSecondsOption    tuningOption = "intervalSeconds"
+	healthCheckTimeoutSecondsOption     tuningOption = "timeoutSeconds"
+	healthCheckHealthyThresholdOption   tuningOption = "healthyThreshold"
+	healthCheckUnhealthyThresholdOption tuningOption = "unhealthyThreshold"
+
+	clientIPTimeoutSecondsOption tuningOption = "clientIPTimeoutSeconds"
+)
+
+// tuningRange is the range of valid values of a tuning option, inclusive.
+type tuningRange struct {
+	min, max int64
+}
+
+// tuningRanges are the valid ranges of the numeric tuning options of the
+// routers.  Both admission and the rendering of the router deployment and
+// services validate the options against these ranges, so a value that is out
+// of range rejects the ingresscontroller before it reaches the routers.
+// Options whose JSON fields are unspecified when 0 are checked only when they
+// are specified.
+var tuningRanges = map[tuningOption]tuningRange{
+	probeTimeoutSecondsOption:       {1, 3600},
+	startupGracePeriodSecondsOption: {1, 3600},
+	// The kubelet waits for the termination grace period, which the
+	// shutdown delay extends, before it kills a router, so a long delay
+	// slows down rollouts and node drains.
+	shutdownDelaySecondsOption: {0, 600},
+	maxReplicasFromNodesOption: {1, math.MaxInt32},
+
+	// HAProxy needs some connections for its own use, so it cannot run
+	// with a very low global maxconn.
+	maxConnectionsOption:         {2000, 2000000},
+	maxConnectionsPerRouteOption: {0, 2000000},
+
+	// The router pre-allocates the blueprint backends and the dynamic
+	// server slots in each backend, which costs memory even when unused.
+	blueprintRoutePoolSizeOption: {0, 1000},
+	maxDynamicServersOption:      {1, 100},
+
+	routesPerThreadOption:       {0, 100000},
+	serversPerGiBOption:         {0, 1000000},
+	connectionsPerBackendOption: {0, 1000000},
+
+	// The ranges that AWS accepts for classic ELB health checks.
+	healthCheckIntervalSecondsOption:    {5, 300},
+	healthCheckTimeoutSecondsOption:     {2, 60},
+	healthCheckHealthyThresholdOption:   {2, 10},
+	healthCheckUnhealthyThresholdOption: {2, 10},
+
+	// The longest session affinity timeout that the API server accepts.
+	clientIPTimeoutSecondsOption: {1, 86400},
+}
+
+// validateTuningOption returns an error if the given value of the given tuning
+// option is out of range.
+func validateTuningOption(option tuningOption, value int64) error {
+	r, ok := tuningRanges[option]
+	if !ok {
+		return fmt.Errorf("unknown tuning option %s", option)
+	}
+	if value < r.min || value > r.max {
+		return fmt.Errorf("%s must be from %d to %d, got %d", option, r.min, r.max, value)
+	}
+	return nil
+}
+
+// parseTuningAnnotation parses the given value of the given annotation, which
+// sets the given tuning option, as an integer in the option's range.
+func parseTuningAnnotation(annotation, value string, option tuningOption) (int64, error) {
+	r := tuningRanges[option]
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < r.min || n > r.max {
+		return 0, fmt.Errorf("invalid value for annotation %s: %q: must be an integer from %d to %d", annotation, value, r.min, r.max)
+	}
+	return n, nil
+}
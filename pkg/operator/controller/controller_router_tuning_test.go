@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTuningRanges verifies that admission accepts the bounds of the range of
+// each tuning option and rejects the values just outside of it.
+func TestTuningRanges(t *testing.T) {
+	jsonField := func(annotation, field string) func(int64) map[string]string {
+		return func(value int64) map[string]string {
+			return map[string]string{annotation: fmt.Sprintf(`{%q: %d}`, field, value)}
+		}
+	}
+	annotationFor := map[tuningOption]func(int64) map[string]string{
+		probeTimeoutSecondsOption: func(value int64) map[string]string {
+			return map[string]string{ProbeTimeoutSecondsAnnotation: fmt.Sprint(value)}
+		},
+		startupGracePeriodSecondsOption: func(value int64) map[string]string {
+			return map[string]string{StartupGracePeriodSecondsAnnotation: fmt.Sprint(value)}
+		},
+		shutdownDelaySecondsOption: func(value int64) map[string]string {
+			return map[string]string{ShutdownDelaySecondsAnnotation: fmt.Sprint(value)}
+		},
+		maxReplicasFromNodesOption: func(value int64) map[string]string {
+			return map[string]string{MaxReplicasFromNodesAnnotation: fmt.Sprint(value)}
+		},
+		maxConnectionsOption: jsonField(ConnectionLimitsAnnotation, "maxConnections"),
+		maxConnectionsPerRouteOption: func(value int64) map[string]string {
+			return map[string]string{ConnectionLimitsAnnotation: fmt.Sprintf(`{"maxConnectionsPerRoute": %d}`, value)}
+		},
+		blueprintRoutePoolSizeOption:        jsonField(DynamicConfigManagerAnnotation, "blueprintRoutePoolSize"),
+		maxDynamicServersOption:             jsonField(DynamicConfigManagerAnnotation, "maxDynamicServers"),
+		routesPerThreadOption:               jsonField(ScaleLimitsAnnotation, "routesPerThread"),
+		serversPerGiBOption:                 jsonField(ScaleLimitsAnnotation, "serversPerGiB"),
+		connectionsPerBackendOption:         jsonField(ScaleLimitsAnnotation, "connectionsPerBackend"),
+		healthCheckIntervalSecondsOption:    jsonField(AWSLoadBalancerHealthCheckAnnotation, "intervalSeconds"),
+		healthCheckTimeoutSecondsOption:     jsonField(AWSLoadBalancerHealthCheckAnnotation, "timeoutSeconds"),
+		healthCheckHealthyThresholdOption:   jsonField(AWSLoadBalancerHealthCheckAnnotation, "healthyThreshold"),
+		healthCheckUnhealthyThresholdOption: jsonField(AWSLoadBalancerHealthCheckAnnotation, "unhealthyThreshold"),
+		clientIPTimeoutSecondsOption: func(value int64) map[string]string {
+			return map[string]string{ServiceSessionAffinityAnnotation: fmt.Sprintf(`{"type": "ClientIP", "clientIPTimeoutSeconds": %d}`, value)}
+		},
+	}
+	for option, r := range tuningRanges {
+		if r.min > r.max {
+			t.Errorf("%s: invalid range: min %d exceeds max %d", option, r.min, r.max)
+			continue
+		}
+		annotations, ok := annotationFor[option]
+		if !ok {
+			t.Errorf("%s: no test annotation", option)
+			continue
+		}
+		for _, tc := range []struct {
+			value     int64
+			expectErr bool
+		}{
+			{r.min, false},
+			{r.max, false},
+			{r.min - 1, true},
+			{r.max + 1, true},
+		} {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: annotations(tc.value)},
+			}
+			err := validateIngressController(ic, false, configv1.AWSPlatformType)
+			checkError(t, fmt.Sprintf("%s=%d", option, tc.value), tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateTuningOption(t *testing.T) {
+	if err := validateTuningOption(maxDynamicServersOption, 10); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expect := "maxDynamicServers must be from 1 to 100, got 101"
+	if err := validateTuningOption(maxDynamicServersOption, 101); err == nil || err.Error() != expect {
+		t.Errorf("expected error %q, got %v", expect, err)
+	}
+	if err := validateTuningOption("unknown", 1); err == nil {
+		t.Error("expected an error for an unknown option")
+	}
+}
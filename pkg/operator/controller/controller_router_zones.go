@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ZoneAwareAnnotation may be set on an ingresscontroller that uses the
+	// LoadBalancerService endpoint publishing strategy to keep a router in
+	// each availability zone that the load balancer sends connections to.
+	// The value is "Enabled" or "Disabled" (the default).  The router
+	// service uses the Local external traffic policy, so a load balancer
+	// that does not balance across zones, such as an AWS NLB, fails the
+	// connections that it receives in a zone without a ready router.  When
+	// enabled, the operator spreads the router replicas across zones,
+	// raises the replicas to at least the number of zones with nodes that
+	// can run a router, and reports the LoadBalancerZoneWithoutRouter
+	// condition.
+	ZoneAwareAnnotation = "ingress.operator.openshift.io/zone-aware"
+
+	// zoneAwareEnabledValue and zoneAwareDisabledValue are the values of
+	// ZoneAwareAnnotation.
+	zoneAwareEnabledValue  = "Enabled"
+	zoneAwareDisabledValue = "Disabled"
+
+	// LoadBalancerZoneWithoutRouterConditionType is the type of the
+	// ingresscontroller condition that reports whether the load balancer
+	// has targets in an availability zone in which no router pod is ready.
+	LoadBalancerZoneWithoutRouterConditionType = "LoadBalancerZoneWithoutRouter"
+
+	// zoneLabel is the well-known node label with the node's availability
+	// zone, and betaZoneLabel is its deprecated predecessor, which nodes
+	// of older clusters may have instead.
+	zoneLabel     = "topology.kubernetes.io/zone"
+	betaZoneLabel = "failure-domain.beta.kubernetes.io/zone"
+
+	// excludeFromLoadBalancersLabel and masterNodeRoleLabel mark nodes
+	// that the service controller does not register as load balancer
+	// targets.
+	excludeFromLoadBalancersLabel = "node.kubernetes.io/exclude-from-external-load-balancers"
+	masterNodeRoleLabel           = "node-role.kubernetes.io/master"
+)
+
+// zoneAwareEnabled returns true if the given ingresscontroller requests that
+// its routers cover each zone of its load balancer.
+func zoneAwareEnabled(ic *operatorv1.IngressController) (bool, error) {
+	switch value := ic.Annotations[ZoneAwareAnnotation]; value {
+	case "", zoneAwareDisabledValue:
+		return false, nil
+	case zoneAwareEnabledValue:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid value for annotation %s: %q: must be %q or %q", ZoneAwareAnnotation, value, zoneAwareEnabledValue, zoneAwareDisabledValue)
+	}
+}
+
+// usesZoneAwareLoadBalancer returns true if the given ingresscontroller
+// enables ZoneAwareAnnotation and publishes its routers through a load
+// balancer.
+func usesZoneAwareLoadBalancer(ic *operatorv1.IngressController) (bool, error) {
+	enabled, err := zoneAwareEnabled(ic)
+	if err != nil || !enabled {
+		return false, err
+	}
+	return ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.LoadBalancerServiceStrategyType, nil
+}
+
+// nodeZone returns the availability zone of the given node, or the empty
+// string if the node has no zone label.
+func nodeZone(node *corev1.Node) string {
+	if zone, ok := node.Labels[zoneLabel]; ok {
+		return zone
+	}
+	return node.Labels[betaZoneLabel]
+}
+
+// configureRouterZoneSpread adds a preferred anti-affinity rule that spreads
+// the router pods of the given ingresscontroller across zones to the given
+// router deployment if the ingresscontroller is zone aware.  The rule is
+// preferred rather than required so that routers still schedule if a zone
+// has no eligible node.
+func configureRouterZoneSpread(ic *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	zoneAware, err := usesZoneAwareLoadBalancer(ic)
+	if err != nil || !zoneAware {
+		return err
+	}
+	affinity := deployment.Spec.Template.Spec.Affinity
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+		deployment.Spec.Template.Spec.Affinity = affinity
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.WeightedPodAffinityTerm{
+		Weight: 100,
+		PodAffinityTerm: corev1.PodAffinityTerm{
+			TopologyKey: zoneLabel,
+			LabelSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      controllerDeploymentLabel,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{IngressControllerDeploymentLabel(ic)},
+					},
+				},
+			},
+		},
+	})
+	return nil
+}
+
+// eligibleZones returns the zones of the given nodes that match the given node
+// selector and can run router pods.
+func eligibleZones(nodes []corev1.Node, nodeSelector map[string]string) sets.String {
+	selector := labels.SelectorFromSet(nodeSelector)
+	zones := sets.NewString()
+	for i := range nodes {
+		if !selector.Matches(labels.Set(nodes[i].Labels)) || nodes[i].Spec.Unschedulable || !IsNodeReady(&nodes[i]) {
+			continue
+		}
+		if zone := nodeZone(&nodes[i]); len(zone) != 0 {
+			zones.Insert(zone)
+		}
+	}
+	return zones
+}
+
+// setZoneAwareReplicas raises the replicas of the given router deployment to
+// the number of zones in which routers can run if the given ingresscontroller
+// is zone aware.
+func (r *reconciler) setZoneAwareReplicas(ic *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	zoneAware, err := usesZoneAwareLoadBalancer(ic)
+	if err != nil || !zoneAware {
+		return err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes); err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	zones := int32(eligibleZones(nodes.Items, deployment.Spec.Template.Spec.NodeSelector).Len())
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas < zones {
+		deployment.Spec.Replicas = &zones
+	}
+	return nil
+}
+
+// loadBalancerTargetZones returns the zones of the given nodes that the
+// service controller registers as load balancer targets.  With the Local
+// external traffic policy, each ready node is a target, and the load
+// balancer's health check takes nodes without a ready router out of
+// rotation.
+func loadBalancerTargetZones(nodes []corev1.Node) sets.String {
+	zones := sets.NewString()
+	for i := range nodes {
+		if _, ok := nodes[i].Labels[excludeFromLoadBalancersLabel]; ok {
+			continue
+		}
+		if _, ok := nodes[i].Labels[masterNodeRoleLabel]; ok {
+			continue
+		}
+		if !IsNodeReady(&nodes[i]) {
+			continue
+		}
+		if zone := nodeZone(&nodes[i]); len(zone) != 0 {
+			zones.Insert(zone)
+		}
+	}
+	return zones
+}
+
+// routerZones returns the zones of the nodes on which the given pods are
+// ready.
+func routerZones(pods []corev1.Pod, nodes []corev1.Node) sets.String {
+	zoneOf := map[string]string{}
+	for i := range nodes {
+		zoneOf[nodes[i].Name] = nodeZone(&nodes[i])
+	}
+	zones := sets.NewString()
+	for i := range pods {
+		if !isPodReady(&pods[i]) {
+			continue
+		}
+		if zone := zoneOf[pods[i].Spec.NodeName]; len(zone) != 0 {
+			zones.Insert(zone)
+		}
+	}
+	return zones
+}
+
+// computeLoadBalancerZoneWithoutRouterCondition computes the
+// LoadBalancerZoneWithoutRouter condition for the given ingresscontroller and
+// its load balancer service.  The condition is nil unless the ingresscontroller
+// is zone aware and its service uses the Local external traffic policy.
+func (r *reconciler) computeLoadBalancerZoneWithoutRouterCondition(ic *operatorv1.IngressController, service *corev1.Service) (*operatorv1.OperatorCondition, error) {
+	zoneAware, err := usesZoneAwareLoadBalancer(ic)
+	if err != nil || !zoneAware || service == nil || service.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeLocal {
+		return nil, err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	pods := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), pods, client.InNamespace(r.Config.OperandNamespace), client.MatchingLabels(IngressControllerDeploymentPodSelector(ic).MatchLabels)); err != nil {
+		return nil, fmt.Errorf("failed to list router pods: %v", err)
+	}
+	return loadBalancerZoneWithoutRouterCondition(loadBalancerTargetZones(nodes.Items), routerZones(pods.Items, nodes.Items)), nil
+}
+
+// loadBalancerZoneWithoutRouterCondition returns the
+// LoadBalancerZoneWithoutRouter condition for the given zones with load
+// balancer targets and zones with ready routers.
+func loadBalancerZoneWithoutRouterCondition(targetZones, routerZones sets.String) *operatorv1.OperatorCondition {
+	missing := targetZones.Difference(routerZones).List()
+	if len(missing) == 0 {
+		return &operatorv1.OperatorCondition{
+			Type:    LoadBalancerZoneWithoutRouterConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "AllZonesHaveRouters",
+			Message: "Each zone with load balancer targets has a ready router pod.",
+		}
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    LoadBalancerZoneWithoutRouterConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ZoneWithoutRouter",
+		Message: fmt.Sprintf("The load balancer has targets in zones with no ready router pod, so connections that it sends to these zones fail: %s", strings.Join(missing, ", ")),
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func zoneTestNode(name, zone string, extraLabels map[string]string, ready corev1.ConditionStatus) corev1.Node {
+	labels := map[string]string{"node-role.kubernetes.io/worker": ""}
+	if len(zone) != 0 {
+		labels[zoneLabel] = zone
+	}
+	for k, v := range extraLabels {
+		labels[k] = v
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func TestConfigureRouterZoneSpread(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		expectTerm  bool
+		expectErr   bool
+	}{
+		{
+			description: "no annotation",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+		},
+		{
+			description: "enabled with a load balancer",
+			annotations: map[string]string{ZoneAwareAnnotation: "Enabled"},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectTerm:  true,
+		},
+		{
+			description: "enabled with host network",
+			annotations: map[string]string{ZoneAwareAnnotation: "Enabled"},
+			strategy:    operatorv1.HostNetworkStrategyType,
+		},
+		{
+			description: "invalid value",
+			annotations: map[string]string{ZoneAwareAnnotation: "true"},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: tc.annotations},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+			},
+		}
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: "kubernetes.io/hostname"}},
+			},
+		}
+		err := configureRouterZoneSpread(ic, deployment)
+		checkError(t, tc.description, tc.expectErr, err)
+		antiAffinity := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity
+		if len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 1 {
+			t.Errorf("%q: expected the required anti-affinity term to be kept, got %v", tc.description, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		}
+		terms := antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		switch {
+		case tc.expectTerm && (len(terms) != 1 || terms[0].PodAffinityTerm.TopologyKey != zoneLabel):
+			t.Errorf("%q: expected a preferred zone anti-affinity term, got %v", tc.description, terms)
+		case !tc.expectTerm && len(terms) != 0:
+			t.Errorf("%q: expected no preferred anti-affinity terms, got %v", tc.description, terms)
+		}
+	}
+}
+
+func TestEligibleZones(t *testing.T) {
+	unschedulable := zoneTestNode("unschedulable", "us-east-1c", nil, corev1.ConditionTrue)
+	unschedulable.Spec.Unschedulable = true
+	nodes := []corev1.Node{
+		zoneTestNode("a", "us-east-1a", nil, corev1.ConditionTrue),
+		zoneTestNode("b", "us-east-1b", nil, corev1.ConditionTrue),
+		zoneTestNode("b2", "us-east-1b", nil, corev1.ConditionTrue),
+		zoneTestNode("not-ready", "us-east-1d", nil, corev1.ConditionFalse),
+		zoneTestNode("no-zone", "", nil, corev1.ConditionTrue),
+		zoneTestNode("infra", "us-east-1e", map[string]string{"node-role.kubernetes.io/infra": ""}, corev1.ConditionTrue),
+		unschedulable,
+	}
+	expect := sets.NewString("us-east-1a", "us-east-1b", "us-east-1e")
+	if actual := eligibleZones(nodes, map[string]string{"node-role.kubernetes.io/worker": ""}); !actual.Equal(expect) {
+		t.Errorf("expected %v, got %v", expect.List(), actual.List())
+	}
+	expect = sets.NewString("us-east-1e")
+	if actual := eligibleZones(nodes, map[string]string{"node-role.kubernetes.io/infra": ""}); !actual.Equal(expect) {
+		t.Errorf("expected %v, got %v", expect.List(), actual.List())
+	}
+}
+
+func TestLoadBalancerZoneWithoutRouterCondition(t *testing.T) {
+	betaNode := zoneTestNode("beta", "", nil, corev1.ConditionTrue)
+	betaNode.Labels[betaZoneLabel] = "us-east-1c"
+	nodes := []corev1.Node{
+		zoneTestNode("a", "us-east-1a", nil, corev1.ConditionTrue),
+		zoneTestNode("b", "us-east-1b", nil, corev1.ConditionTrue),
+		betaNode,
+		zoneTestNode("master", "us-east-1d", map[string]string{masterNodeRoleLabel: ""}, corev1.ConditionTrue),
+		zoneTestNode("excluded", "us-east-1e", map[string]string{excludeFromLoadBalancersLabel: ""}, corev1.ConditionTrue),
+		zoneTestNode("not-ready", "us-east-1f", nil, corev1.ConditionFalse),
+	}
+	pod := func(node string, ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	testCases := []struct {
+		description   string
+		pods          []corev1.Pod
+		expectStatus  operatorv1.ConditionStatus
+		expectMessage string
+	}{
+		{
+			description:  "a ready router in each zone",
+			pods:         []corev1.Pod{pod("a", corev1.ConditionTrue), pod("b", corev1.ConditionTrue), pod("beta", corev1.ConditionTrue)},
+			expectStatus: operatorv1.ConditionFalse,
+		},
+		{
+			description:   "a router that is not ready",
+			pods:          []corev1.Pod{pod("a", corev1.ConditionTrue), pod("b", corev1.ConditionFalse), pod("beta", corev1.ConditionTrue)},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: "The load balancer has targets in zones with no ready router pod, so connections that it sends to these zones fail: us-east-1b",
+		},
+		{
+			description:   "no routers",
+			expectStatus:  operatorv1.ConditionTrue,
+			expectMessage: "The load balancer has targets in zones with no ready router pod, so connections that it sends to these zones fail: us-east-1a, us-east-1b, us-east-1c",
+		},
+	}
+	for _, tc := range testCases {
+		actual := loadBalancerZoneWithoutRouterCondition(loadBalancerTargetZones(nodes), routerZones(tc.pods, nodes))
+		if actual.Type != LoadBalancerZoneWithoutRouterConditionType || actual.Status != tc.expectStatus {
+			t.Errorf("%q: expected %s=%s, got %s=%s", tc.description, LoadBalancerZoneWithoutRouterConditionType, tc.expectStatus, actual.Type, actual.Status)
+			continue
+		}
+		if len(tc.expectMessage) != 0 && actual.Message != tc.expectMessage {
+			t.Errorf("%q: expected message %q, got %q", tc.description, tc.expectMessage, actual.Message)
+		}
+	}
+}
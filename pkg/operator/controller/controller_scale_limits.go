@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// ScaleLimitsExceededConditionType is the type of the ingresscontroller
+	// condition that reports whether the routes, backends, or servers that
+	// the ingresscontroller's routers serve exceed the recommended limits
+	// for the routers' tuning.  The condition is a warning that the shard
+	// should be split before its routers' performance degrades; it does
+	// not affect the ingresscontroller's availability.  The condition is
+	// computed from the load that the shard-metrics controller records in
+	// ShardLoads.
+	ScaleLimitsExceededConditionType = "ScaleLimitsExceeded"
+
+	// ScaleLimitsAnnotation may be set on an ingresscontroller to override
+	// the thresholds of the ScaleLimitsExceeded condition.  The value is a
+	// JSON object with any of the following fields, for example:
+	//
+	//   {"routesPerThread": 1000, "serversPerGiB": 16000, "connectionsPerBackend": 5}
+	//
+	// routesPerThread is the number of admitted routes per router thread,
+	// serversPerGiB is the number of servers per GiB of the router's
+	// memory request, and connectionsPerBackend is the number of
+	// connections of the router's maxConnections that each backend should
+	// be able to use.  Zero or omitted fields keep the defaults.
+	ScaleLimitsAnnotation = "ingress.operator.openshift.io/scale-limits"
+
+	// defaultRoutesPerThread, defaultServersPerGiB, and
+	// defaultConnectionsPerBackend are the default scale limits.  With the
+	// default tuning of 4 threads, a 256Mi memory request, and 20000
+	// connections, each allows 2000 routes, servers, or backends.
+	defaultRoutesPerThread       = 500
+	defaultServersPerGiB         = 8000
+	defaultConnectionsPerBackend = 10
+
+	// routerThreads is the number of threads of each router unless the
+	// router deployment's ROUTER_THREADS environment variable specifies
+	// otherwise.
+	routerThreads = 4
+	// defaultRouterMaxConnections is the router's maxConnections unless
+	// ConnectionLimitsAnnotation specifies one.
+	defaultRouterMaxConnections = 20000
+)
+
+// ShardLoad is the load on an ingresscontroller's shard: the routes that its
+// routers have admitted, the distinct services that they refer to, and the
+// ready endpoint addresses of those services.
+type ShardLoad struct {
+	Routes   int
+	Backends int
+	Servers  int
+}
+
+// ShardLoads holds the most recent load of each ingresscontroller's shard.  The
+// shard-metrics controller computes the loads, and the operator controller
+// computes the ScaleLimitsExceeded condition from them so that the
+// ingresscontroller's status has one writer and the operator controller need
+// not list every route in the cluster.  ShardLoads sends an event for an
+// ingresscontroller when its load changes so that the condition is updated.
+// It is safe for concurrent use.
+type ShardLoads struct {
+	// events receives an event for each changed load.
+	events chan event.GenericEvent
+
+	lock  sync.Mutex
+	loads map[types.NamespacedName]ShardLoad
+}
+
+// NewShardLoads returns an empty ShardLoads.
+func NewShardLoads() *ShardLoads {
+	return &ShardLoads{
+		events: make(chan event.GenericEvent, 100),
+		loads:  map[types.NamespacedName]ShardLoad{},
+	}
+}
+
+// Set records the load of the named ingresscontroller's shard.
+func (l *ShardLoads) Set(name types.NamespacedName, load ShardLoad) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if current, ok := l.loads[name]; ok && current == load {
+		return
+	}
+	l.loads[name] = load
+	// The event is best effort: if the buffer is full, the
+	// ingresscontroller picks up the load when it is next reconciled.
+	select {
+	case l.events <- event.GenericEvent{Meta: &metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}}:
+	default:
+	}
+}
+
+// Delete forgets the load of the named ingresscontroller's shard.
+func (l *ShardLoads) Delete(name types.NamespacedName) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.loads, name)
+}
+
+// get returns the load of the named ingresscontroller's shard and whether it
+// is known.
+func (l *ShardLoads) get(name types.NamespacedName) (ShardLoad, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	load, ok := l.loads[name]
+	return load, ok
+}
+
+// scaleLimits is the value of ScaleLimitsAnnotation.
+type scaleLimits struct {
+	RoutesPerThread       int `json:"routesPerThread,omitempty"`
+	ServersPerGiB         int `json:"serversPerGiB,omitempty"`
+	ConnectionsPerBackend int `json:"connectionsPerBackend,omitempty"`
+}
+
+// scaleLimitsFor returns the scale limits of the given ingresscontroller with
+// the defaults filled in.
+func scaleLimitsFor(ic *operatorv1.IngressController) (*scaleLimits, error) {
+	limits := &scaleLimits{}
+	if value, ok := ic.Annotations[ScaleLimitsAnnotation]; ok {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(limits); err != nil {
+			return nil, fmt.Errorf("invalid value for annotation %s: %v", ScaleLimitsAnnotation, err)
+		}
+		for _, field := range []struct {
+			option tuningOption
+			value  int
+		}{
+			{routesPerThreadOption, limits.RoutesPerThread},
+			{serversPerGiBOption, limits.ServersPerGiB},
+			{connectionsPerBackendOption, limits.ConnectionsPerBackend},
+		} {
+			if err := validateTuningOption(field.option, int64(field.value)); err != nil {
+				return nil, fmt.Errorf("invalid value for annotation %s: %v", ScaleLimitsAnnotation, err)
+			}
+		}
+	}
+	if limits.RoutesPerThread == 0 {
+		limits.RoutesPerThread = defaultRoutesPerThread
+	}
+	if limits.ServersPerGiB == 0 {
+		limits.ServersPerGiB = defaultServersPerGiB
+	}
+	if limits.ConnectionsPerBackend == 0 {
+		limits.ConnectionsPerBackend = defaultConnectionsPerBackend
+	}
+	return limits, nil
+}
+
+// computeScaleLimitsExceededCondition computes the ScaleLimitsExceeded
+// condition of the given ingresscontroller, whose router workload is given,
+// from the load that the shard-metrics controller last recorded for it.  The
+// condition is left as it is while the load is unknown or the scale limits
+// annotation is invalid, which admission reports.
+func (r *reconciler) computeScaleLimitsExceededCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	current := findIngressStatusCondition(ic.Status.Conditions, ScaleLimitsExceededConditionType)
+	if r.ShardLoads == nil {
+		return current
+	}
+	load, ok := r.ShardLoads.get(types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name})
+	if !ok {
+		return current
+	}
+	condition, err := scaleLimitsExceededCondition(ic, deployment, load)
+	if err != nil {
+		return current
+	}
+	return condition
+}
+
+// routerThreadsFor returns the number of threads of the routers of the given
+// router workload.
+func routerThreadsFor(deployment *appsv1.Deployment) int {
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return routerThreads
+	}
+	for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if env.Name != "ROUTER_THREADS" {
+			continue
+		}
+		if threads, err := strconv.Atoi(env.Value); err == nil && threads > 0 {
+			return threads
+		}
+	}
+	return routerThreads
+}
+
+// scaleLimitsExceededCondition returns the ScaleLimitsExceeded condition for
+// the given ingresscontroller, whose router deployment or daemonset is given,
+// if its shard has the given load.  The memory limit is not checked if the
+// router has no memory request.
+func scaleLimitsExceededCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment, load ShardLoad) (*operatorv1.OperatorCondition, error) {
+	limits, err := scaleLimitsFor(ic)
+	if err != nil {
+		return nil, err
+	}
+	maxConnections := defaultRouterMaxConnections
+	if connLimits, err := connectionLimitsFor(ic); err == nil && connLimits != nil && connLimits.MaxConnections != 0 {
+		maxConnections = int(connLimits.MaxConnections)
+	}
+
+	var exceeded []string
+	threads := routerThreadsFor(deployment)
+	if maxRoutes := threads * limits.RoutesPerThread; load.Routes > maxRoutes {
+		exceeded = append(exceeded, fmt.Sprintf("%d routes exceed the limit of %d for %d router threads", load.Routes, maxRoutes, threads))
+	}
+	if maxBackends := maxConnections / limits.ConnectionsPerBackend; load.Backends > maxBackends {
+		exceeded = append(exceeded, fmt.Sprintf("%d backends exceed the limit of %d for %d maximum connections", load.Backends, maxBackends, maxConnections))
+	}
+	if len(deployment.Spec.Template.Spec.Containers) != 0 {
+		if memory, ok := deployment.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory]; ok && !memory.IsZero() {
+			if maxServers := int(memory.Value() * int64(limits.ServersPerGiB) / (1 << 30)); load.Servers > maxServers {
+				exceeded = append(exceeded, fmt.Sprintf("%d servers exceed the limit of %d for a memory request of %s", load.Servers, maxServers, memory.String()))
+			}
+		}
+	}
+
+	if len(exceeded) == 0 {
+		return &operatorv1.OperatorCondition{
+			Type:    ScaleLimitsExceededConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "WithinScaleLimits",
+			Message: "The routes, backends, and servers that the routers serve are within the recommended limits",
+		}, nil
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    ScaleLimitsExceededConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ScaleLimitsExceeded",
+		Message: fmt.Sprintf("Consider splitting the shard: %s", strings.Join(exceeded, "; ")),
+	}, nil
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestScaleLimitsExceededCondition(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "router",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+	}}
+	eightThreads := deployment.DeepCopy()
+	eightThreads.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "ROUTER_THREADS", Value: "8"}}
+	daemonset := &appsv1.DaemonSet{}
+	daemonset.Spec.Template = deployment.Spec.Template
+	testCases := []struct {
+		description  string
+		annotations  map[string]string
+		deployment   *appsv1.Deployment
+		routes       int
+		backends     int
+		servers      int
+		expectStatus operatorv1.ConditionStatus
+		expectText   []string
+		expectErr    bool
+	}{
+		{
+			description:  "within the defaults",
+			deployment:   deployment,
+			routes:       2000,
+			backends:     2000,
+			servers:      2000,
+			expectStatus: operatorv1.ConditionFalse,
+		},
+		{
+			description:  "too many routes",
+			deployment:   deployment,
+			routes:       2001,
+			expectStatus: operatorv1.ConditionTrue,
+			expectText:   []string{"2001 routes exceed the limit of 2000"},
+		},
+		{
+			description:  "too many backends for the connection limit",
+			annotations:  map[string]string{ConnectionLimitsAnnotation: `{"maxConnections": 5000}`},
+			deployment:   deployment,
+			backends:     501,
+			expectStatus: operatorv1.ConditionTrue,
+			expectText:   []string{"501 backends exceed the limit of 500 for 5000 maximum connections"},
+		},
+		{
+			description:  "too many servers for the memory request",
+			deployment:   deployment,
+			servers:      2001,
+			expectStatus: operatorv1.ConditionTrue,
+			expectText:   []string{"2001 servers exceed the limit of 2000 for a memory request of 256Mi"},
+		},
+		{
+			description:  "servers unchecked without a memory request",
+			deployment:   &appsv1.Deployment{},
+			servers:      100000,
+			expectStatus: operatorv1.ConditionFalse,
+		},
+		{
+			description:  "too many servers for the memory request of a daemonset",
+			deployment:   daemonSetAsDeployment(daemonset),
+			servers:      2001,
+			expectStatus: operatorv1.ConditionTrue,
+			expectText:   []string{"2001 servers exceed the limit of 2000 for a memory request of 256Mi"},
+		},
+		{
+			description:  "routes within the limit for the router's threads",
+			deployment:   eightThreads,
+			routes:       4000,
+			expectStatus: operatorv1.ConditionFalse,
+		},
+		{
+			description:  "too many routes for the router's threads",
+			deployment:   eightThreads,
+			routes:       4001,
+			expectStatus: operatorv1.ConditionTrue,
+			expectText:   []string{"4001 routes exceed the limit of 4000 for 8 router threads"},
+		},
+		{
+			description:  "custom limits",
+			annotations:  map[string]string{ScaleLimitsAnnotation: `{"routesPerThread": 100, "serversPerGiB": 4000}`},
+			deployment:   deployment,
+			routes:       401,
+			servers:      1001,
+			expectStatus: operatorv1.ConditionTrue,
+			expectText:   []string{"401 routes exceed the limit of 400", "1001 servers exceed the limit of 1000"},
+		},
+		{
+			description: "negative limit",
+			annotations: map[string]string{ScaleLimitsAnnotation: `{"routesPerThread": -1}`},
+			deployment:  deployment,
+			expectErr:   true,
+		},
+		{
+			description: "unknown field",
+			annotations: map[string]string{ScaleLimitsAnnotation: `{"routes": 1}`},
+			deployment:  deployment,
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Annotations = tc.annotations
+		load := ShardLoad{Routes: tc.routes, Backends: tc.backends, Servers: tc.servers}
+		condition, err := scaleLimitsExceededCondition(ic, tc.deployment, load)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if condition.Status != tc.expectStatus {
+			t.Errorf("%q: expected status %s, got %#v", tc.description, tc.expectStatus, condition)
+		}
+		for _, text := range tc.expectText {
+			if !strings.Contains(condition.Message, text) {
+				t.Errorf("%q: expected message to contain %q, got %q", tc.description, text, condition.Message)
+			}
+		}
+	}
+}
+
+// TestShardLoads verifies that ShardLoads records loads and sends an event only
+// when a load changes.
+func TestShardLoads(t *testing.T) {
+	loads := NewShardLoads()
+	name := types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}
+	if _, ok := loads.get(name); ok {
+		t.Fatal("expected no load")
+	}
+	for i, load := range []ShardLoad{{Routes: 1}, {Routes: 1}, {Routes: 2}} {
+		loads.Set(name, load)
+		if actual, _ := loads.get(name); actual != load {
+			t.Errorf("expected load %+v, got %+v", load, actual)
+		}
+		select {
+		case e := <-loads.events:
+			if i == 1 {
+				t.Errorf("expected no event for an unchanged load, got %v", e)
+			} else if e.Meta.GetName() != name.Name || e.Meta.GetNamespace() != name.Namespace {
+				t.Errorf("expected an event for %s, got %v", name, e)
+			}
+		default:
+			if i != 1 {
+				t.Errorf("expected an event for load %+v", load)
+			}
+		}
+	}
+	loads.Delete(name)
+	if _, ok := loads.get(name); ok {
+		t.Error("expected the load to be deleted")
+	}
+}
@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// managedServiceLabelsAnnotation and managedServiceAnnotationsAnnotation
+	// record on each service the keys of the labels and annotations that
+	// the operator last set on it, separated by commas, so that keys that
+	// are no longer desired can be removed without removing labels and
+	// annotations that other actors add.  setManagedServiceKeys adds them
+	// to a desired service.
+	managedServiceLabelsAnnotation      = "ingress.operator.openshift.io/managed-labels"
+	managedServiceAnnotationsAnnotation = "ingress.operator.openshift.io/managed-annotations"
+)
+
+// updateService updates a service if the fields that the operator manages
+// have changed.  The managed keys of the desired service are recorded again
+// because callers may add labels or annotations after building it.
+func (r *reconciler) updateService(current, desired *corev1.Service) (*corev1.Service, error) {
+	setManagedServiceKeys(desired)
+	changed, updated := serviceChanged(current, desired)
+	if !changed {
+		return current, nil
+	}
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return nil, fmt.Errorf("failed to update service %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated service", "namespace", updated.Namespace, "name", updated.Name)
+	return updated, nil
+}
+
+// serviceChanged checks if the current service matches the expected service in
+// the fields that the operator manages and if not returns the updated service.
+//
+// The managed fields are the labels and annotations that the operator sets,
+// .spec.type, .spec.selector, .spec.ports (excluding allocated node ports),
+// .spec.externalTrafficPolicy, and the session affinity.  Labels and
+// annotations that the operator set before, as recorded in the managed keys
+// annotations, are removed if they are no longer expected.  Any other fields,
+// including labels and annotations added by other actors and fields that the
+// API server defaults or allocates, such as .spec.clusterIP, node ports, and
+// .spec.healthCheckNodePort, are left as they are, except that the removable
+// annotations are removed if they are not expected and allocated fields that
+// the new service type or external traffic policy does not allow are dropped.
+func serviceChanged(current, expected *corev1.Service) (bool, *corev1.Service) {
+	staleLabels := staleManagedKeys(current.Annotations[managedServiceLabelsAnnotation], current.Labels, expected.Labels)
+	staleAnnotations := staleManagedKeys(current.Annotations[managedServiceAnnotationsAnnotation], current.Annotations, expected.Annotations)
+	if mapContains(current.Labels, expected.Labels) &&
+		mapContains(current.Annotations, expected.Annotations) &&
+		len(staleLabels) == 0 && len(staleAnnotations) == 0 &&
+		current.Spec.Type == expected.Spec.Type &&
+		cmp.Equal(current.Spec.Selector, expected.Spec.Selector, cmpopts.EquateEmpty()) &&
+		servicePortsEqual(current.Spec.Ports, expected.Spec.Ports) &&
+		current.Spec.ExternalTrafficPolicy == expected.Spec.ExternalTrafficPolicy &&
+		sessionAffinityEqual(current, expected) &&
+		!hasUnexpectedRemovableAnnotations(current, expected) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	if updated.Labels == nil && len(expected.Labels) != 0 {
+		updated.Labels = map[string]string{}
+	}
+	for k, v := range expected.Labels {
+		updated.Labels[k] = v
+	}
+	if updated.Annotations == nil && len(expected.Annotations) != 0 {
+		updated.Annotations = map[string]string{}
+	}
+	for k, v := range expected.Annotations {
+		updated.Annotations[k] = v
+	}
+	for _, k := range removableServiceAnnotations {
+		if _, ok := expected.Annotations[k]; !ok {
+			delete(updated.Annotations, k)
+		}
+	}
+	for _, k := range staleLabels {
+		delete(updated.Labels, k)
+	}
+	for _, k := range staleAnnotations {
+		delete(updated.Annotations, k)
+	}
+	updated.Spec.Type = expected.Spec.Type
+	updated.Spec.Selector = expected.Spec.Selector
+	updated.Spec.ExternalTrafficPolicy = expected.Spec.ExternalTrafficPolicy
+	updated.Spec.SessionAffinity = expected.Spec.SessionAffinity
+	updated.Spec.SessionAffinityConfig = expected.Spec.SessionAffinityConfig
+
+	updated.Spec.Ports = mergeServicePorts(current.Spec.Ports, expected.Spec.Ports, updated.Spec.Type != corev1.ServiceTypeClusterIP)
+
+	// The API server allocates the cluster IP and the health check node
+	// port, and they are kept from the current service so that updating
+	// it does not interrupt traffic.  The API server rejects a health
+	// check node port on a service that does not use the Local external
+	// traffic policy for a load balancer, so it is dropped in that case.
+	if updated.Spec.Type != corev1.ServiceTypeLoadBalancer || updated.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeLocal {
+		updated.Spec.HealthCheckNodePort = 0
+	}
+
+	return true, updated
+}
+
+// mergeServicePorts returns the expected service ports with the node ports
+// that are allocated to the current service ports carried over, so that
+// updating a service does not cause new node ports to be allocated and
+// external load balancers and firewall rules that target the node ports keep
+// working.  An expected port that specifies a node port keeps it.  An expected
+// port takes the node port of the current port with the same name or, if it
+// was renamed, of the current port with the same port number and protocol.  If
+// keepNodePorts is false, the service type has no node ports, and none are
+// carried over.
+func mergeServicePorts(current, expected []corev1.ServicePort, keepNodePorts bool) []corev1.ServicePort {
+	byName := map[string]int32{}
+	byPort := map[string]int32{}
+	portKey := func(port corev1.ServicePort) string {
+		protocol := port.Protocol
+		if len(protocol) == 0 {
+			protocol = corev1.ProtocolTCP
+		}
+		return fmt.Sprintf("%d/%s", port.Port, protocol)
+	}
+	for _, port := range current {
+		byName[port.Name] = port.NodePort
+		byPort[portKey(port)] = port.NodePort
+	}
+	ports := make([]corev1.ServicePort, len(expected))
+	for i, port := range expected {
+		ports[i] = port
+		switch {
+		case !keepNodePorts:
+			ports[i].NodePort = 0
+		case port.NodePort != 0:
+		case byName[port.Name] != 0:
+			ports[i].NodePort = byName[port.Name]
+		default:
+			ports[i].NodePort = byPort[portKey(port)]
+		}
+	}
+	return ports
+}
+
+// removableServiceAnnotations are the service annotations that the operator
+// removes when they are no longer desired: the AWS additional resource tags
+// annotation, which is removed when the infrastructure no longer has resource
+// tags, the topology-aware hints annotation, which is removed when the
+// ingresscontroller disables the hints, the Azure health probe annotations,
+// which are removed when the ingresscontroller no longer chooses the probe
+// path, and the AWS health check annotations, which are removed when the
+// ingresscontroller no longer specifies the corresponding health check
+// parameter.  The external-dns annotations are
+// managed separately by ensureExternalDNSTarget because they must move between
+// LB services in a particular order.
+var removableServiceAnnotations = append([]string{
+	awsLBAdditionalResourceTagsAnnotation,
+	serviceTopologyAwareHintsAnnotation,
+	azureLBHealthProbeProtocolAnnotation,
+	azureLBHealthProbeRequestPathAnnotation,
+}, awsLBHealthCheckAnnotations...)
+
+// hasUnexpectedRemovableAnnotations returns true if the current service has a
+// removable annotation that the expected service does not have.
+func hasUnexpectedRemovableAnnotations(current, expected *corev1.Service) bool {
+	for _, k := range removableServiceAnnotations {
+		_, currentOK := current.Annotations[k]
+		_, expectedOK := expected.Annotations[k]
+		if currentOK && !expectedOK {
+			return true
+		}
+	}
+	return false
+}
+
+// setManagedServiceKeys records the keys of the given desired service's labels
+// and annotations in the managed keys annotations of the service.  It must be
+// called after the labels and annotations are set.
+func setManagedServiceKeys(service *corev1.Service) {
+	labelKeys := managedKeys(service.Labels)
+	annotationKeys := managedKeys(service.Annotations)
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[managedServiceLabelsAnnotation] = labelKeys
+	service.Annotations[managedServiceAnnotationsAnnotation] = annotationKeys
+}
+
+// managedKeys returns the sorted keys of the given labels or annotations,
+// excluding the managed keys annotations, separated by commas.
+func managedKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k == managedServiceLabelsAnnotation || k == managedServiceAnnotationsAnnotation {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// staleManagedKeys returns the keys in the given managed keys record that the
+// current labels or annotations have and the expected ones do not.
+func staleManagedKeys(record string, current, expected map[string]string) []string {
+	if len(record) == 0 {
+		return nil
+	}
+	var stale []string
+	for _, k := range strings.Split(record, ",") {
+		if _, ok := expected[k]; ok {
+			continue
+		}
+		if _, ok := current[k]; ok {
+			stale = append(stale, k)
+		}
+	}
+	return stale
+}
+
+// mapContains returns true if every key in expected is present in current with
+// the same value.
+func mapContains(current, expected map[string]string) bool {
+	for k, v := range expected {
+		if cv, ok := current[k]; !ok || cv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// servicePortsEqual compares two slices of service ports, ignoring ordering,
+// defaulted protocols, and node ports that have not been explicitly specified.
+func servicePortsEqual(current, expected []corev1.ServicePort) bool {
+	if len(current) != len(expected) {
+		return false
+	}
+	currentPorts := map[string]corev1.ServicePort{}
+	for _, port := range current {
+		currentPorts[port.Name] = port
+	}
+	for _, e := range expected {
+		c, ok := currentPorts[e.Name]
+		if !ok {
+			return false
+		}
+		if e.NodePort == 0 {
+			c.NodePort = 0
+		}
+		if len(c.Protocol) == 0 {
+			c.Protocol = corev1.ProtocolTCP
+		}
+		if len(e.Protocol) == 0 {
+			e.Protocol = corev1.ProtocolTCP
+		}
+		if e.TargetPort.IntVal == 0 && len(e.TargetPort.StrVal) == 0 {
+			// The API server defaults targetPort to port.
+			e.TargetPort = c.TargetPort
+		}
+		if c != e {
+			return false
+		}
+	}
+	return true
+}
@@ -8,8 +8,6 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 
-	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
-
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,7 +16,7 @@ import (
 )
 
 func (r *reconciler) ensureServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service, deploymentRef metav1.OwnerReference) (*unstructured.Unstructured, error) {
-	desired := desiredServiceMonitor(ic, svc, deploymentRef)
+	desired := desiredServiceMonitor(ic, r.Config.OperandNamespace, svc, deploymentRef)
 
 	current, err := r.currentServiceMonitor(ic)
 	if err != nil {
@@ -35,8 +33,8 @@ func (r *reconciler) ensureServiceMonitor(ic *operatorv1.IngressController, svc
 	return current, nil
 }
 
-func desiredServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service, deploymentRef metav1.OwnerReference) *unstructured.Unstructured {
-	name := IngressControllerServiceMonitorName(ic)
+func desiredServiceMonitor(ic *operatorv1.IngressController, operandNamespace string, svc *corev1.Service, deploymentRef metav1.OwnerReference) *unstructured.Unstructured {
+	name := IngressControllerServiceMonitorName(ic, operandNamespace)
 	sm := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"metadata": map[string]interface{}{
@@ -46,7 +44,7 @@ func desiredServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service
 			"spec": map[string]interface{}{
 				"namespaceSelector": map[string]interface{}{
 					"matchNames": []interface{}{
-						"openshift-ingress",
+						operandNamespace,
 					},
 				},
 				"selector": map[string]interface{}{},
@@ -82,16 +80,14 @@ func (r *reconciler) currentServiceMonitor(ic *operatorv1.IngressController) (*u
 		Kind:    "ServiceMonitor",
 		Version: "v1",
 	})
-	if err := r.client.Get(context.TODO(), IngressControllerServiceMonitorName(ic), sm); err != nil {
+	if err := r.client.Get(context.TODO(), IngressControllerServiceMonitorName(ic, r.Config.OperandNamespace), sm); err != nil {
 		if meta.IsNoMatchError(err) {
 			// Refresh kube client with latest rest scheme/mapper.
-			kClient, err := operatorclient.NewClient(r.KubeConfig)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create kube client: %v", err)
+			if err := r.client.Refresh(); err != nil {
+				return nil, fmt.Errorf("failed to refresh kube client: %v", err)
 			}
-			r.client = kClient
 
-			err = r.client.Get(context.TODO(), IngressControllerServiceMonitorName(ic), sm)
+			err = r.client.Get(context.TODO(), IngressControllerServiceMonitorName(ic, r.Config.OperandNamespace), sm)
 			if err == nil {
 				return sm, nil
 			}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ServiceSessionAffinityAnnotation may be set on an ingresscontroller
+	// to configure the session affinity of its load balancer services,
+	// its NodePort service, and its internal router service, for clients
+	// that need their connections to reach the same router.  The value is a JSON object,
+	// for example:
+	//
+	//   {"type": "ClientIP", "clientIPTimeoutSeconds": 3600}
+	//
+	// type is "None", which is the default, or "ClientIP", which sends
+	// the connections from each client address to the same router until
+	// the client has been idle for clientIPTimeoutSeconds, which defaults
+	// to 10800.
+	ServiceSessionAffinityAnnotation = "ingress.operator.openshift.io/service-session-affinity"
+
+	// defaultClientIPTimeoutSeconds is the ClientIP session affinity
+	// timeout that the API server defaults.
+	defaultClientIPTimeoutSeconds int32 = 10800
+)
+
+// serviceSessionAffinity is the value of ServiceSessionAffinityAnnotation.
+type serviceSessionAffinity struct {
+	Type                   corev1.ServiceAffinity `json:"type"`
+	ClientIPTimeoutSeconds *int64                 `json:"clientIPTimeoutSeconds,omitempty"`
+}
+
+// serviceSessionAffinityFor returns the session affinity and its config for
+// the services of the given ingresscontroller.
+func serviceSessionAffinityFor(ic *operatorv1.IngressController) (corev1.ServiceAffinity, *corev1.SessionAffinityConfig, error) {
+	value, ok := ic.Annotations[ServiceSessionAffinityAnnotation]
+	if !ok {
+		return corev1.ServiceAffinityNone, nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	affinity := serviceSessionAffinity{}
+	if err := decoder.Decode(&affinity); err != nil {
+		return "", nil, fmt.Errorf("invalid value for annotation %s: %v", ServiceSessionAffinityAnnotation, err)
+	}
+	switch affinity.Type {
+	case corev1.ServiceAffinityNone:
+		if affinity.ClientIPTimeoutSeconds != nil {
+			return "", nil, fmt.Errorf("invalid value for annotation %s: clientIPTimeoutSeconds requires type %q", ServiceSessionAffinityAnnotation, corev1.ServiceAffinityClientIP)
+		}
+		return corev1.ServiceAffinityNone, nil, nil
+	case corev1.ServiceAffinityClientIP:
+		timeout := defaultClientIPTimeoutSeconds
+		if affinity.ClientIPTimeoutSeconds != nil {
+			if err := validateTuningOption(clientIPTimeoutSecondsOption, *affinity.ClientIPTimeoutSeconds); err != nil {
+				return "", nil, fmt.Errorf("invalid value for annotation %s: %v", ServiceSessionAffinityAnnotation, err)
+			}
+			timeout = int32(*affinity.ClientIPTimeoutSeconds)
+		}
+		return corev1.ServiceAffinityClientIP, &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout},
+		}, nil
+	default:
+		return "", nil, fmt.Errorf("invalid value for annotation %s: type must be %q or %q, got %q", ServiceSessionAffinityAnnotation, corev1.ServiceAffinityNone, corev1.ServiceAffinityClientIP, affinity.Type)
+	}
+}
+
+// configureServiceSessionAffinity sets the session affinity of the given
+// service of the given ingresscontroller.
+func configureServiceSessionAffinity(service *corev1.Service, ic *operatorv1.IngressController) error {
+	affinity, config, err := serviceSessionAffinityFor(ic)
+	if err != nil {
+		return err
+	}
+	service.Spec.SessionAffinity = affinity
+	service.Spec.SessionAffinityConfig = config
+	return nil
+}
+
+// sessionAffinityEqual returns true if the given services have the same session
+// affinity, taking into account the values that the API server defaults.
+func sessionAffinityEqual(a, b *corev1.Service) bool {
+	affinity := func(service *corev1.Service) (corev1.ServiceAffinity, int32) {
+		if service.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+			return corev1.ServiceAffinityNone, 0
+		}
+		config := service.Spec.SessionAffinityConfig
+		if config == nil || config.ClientIP == nil || config.ClientIP.TimeoutSeconds == nil {
+			return corev1.ServiceAffinityClientIP, defaultClientIPTimeoutSeconds
+		}
+		return corev1.ServiceAffinityClientIP, *config.ClientIP.TimeoutSeconds
+	}
+	aType, aTimeout := affinity(a)
+	bType, bTimeout := affinity(b)
+	return aType == bType && aTimeout == bTimeout
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceSessionAffinityFor(t *testing.T) {
+	testCases := []struct {
+		description    string
+		value          string
+		expectAffinity corev1.ServiceAffinity
+		expectTimeout  int32
+		expectErr      bool
+	}{
+		{
+			description:    "no annotation",
+			expectAffinity: corev1.ServiceAffinityNone,
+		},
+		{
+			description:    "none",
+			value:          `{"type": "None"}`,
+			expectAffinity: corev1.ServiceAffinityNone,
+		},
+		{
+			description:    "client IP with the default timeout",
+			value:          `{"type": "ClientIP"}`,
+			expectAffinity: corev1.ServiceAffinityClientIP,
+			expectTimeout:  10800,
+		},
+		{
+			description:    "client IP with a timeout",
+			value:          `{"type": "ClientIP", "clientIPTimeoutSeconds": 600}`,
+			expectAffinity: corev1.ServiceAffinityClientIP,
+			expectTimeout:  600,
+		},
+		{
+			description: "timeout without client IP",
+			value:       `{"type": "None", "clientIPTimeoutSeconds": 600}`,
+			expectErr:   true,
+		},
+		{
+			description: "unknown type",
+			value:       `{"type": "Cookie"}`,
+			expectErr:   true,
+		},
+		{
+			description: "unknown field",
+			value:       `{"type": "ClientIP", "timeout": 600}`,
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressControllerWithAnnotation(ServiceSessionAffinityAnnotation, tc.value)
+		affinity, config, err := serviceSessionAffinityFor(ic)
+		if !checkError(t, tc.description, tc.expectErr, err) {
+			continue
+		}
+		if affinity != tc.expectAffinity {
+			t.Errorf("%q: expected session affinity %s, got %s", tc.description, tc.expectAffinity, affinity)
+		}
+		switch {
+		case tc.expectTimeout == 0 && config != nil:
+			t.Errorf("%q: expected no session affinity config, got %v", tc.description, config)
+		case tc.expectTimeout != 0 && (config == nil || config.ClientIP == nil || config.ClientIP.TimeoutSeconds == nil || *config.ClientIP.TimeoutSeconds != tc.expectTimeout):
+			t.Errorf("%q: expected a client IP timeout of %d, got %v", tc.description, tc.expectTimeout, config)
+		}
+	}
+}
+
+// TestServiceChangedSessionAffinity verifies that serviceChanged updates the
+// session affinity of a service and ignores the values that the API server
+// defaults.
+func TestServiceChangedSessionAffinity(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	expected, err := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current := expected.DeepCopy()
+	current.Spec.SessionAffinity = ""
+	if changed, _ := serviceChanged(current, expected); changed {
+		t.Error("expected an unset session affinity to equal None")
+	}
+
+	ic.Annotations = map[string]string{ServiceSessionAffinityAnnotation: `{"type": "ClientIP"}`}
+	expected, err = desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed, updated := serviceChanged(current, expected)
+	if !changed {
+		t.Fatal("expected the service to change")
+	}
+	if updated.Spec.SessionAffinity != corev1.ServiceAffinityClientIP || updated.Spec.SessionAffinityConfig == nil {
+		t.Errorf("expected ClientIP session affinity, got %s with config %v", updated.Spec.SessionAffinity, updated.Spec.SessionAffinityConfig)
+	}
+	defaulted := updated.DeepCopy()
+	defaulted.Spec.SessionAffinityConfig = nil
+	if changed, _ := serviceChanged(defaulted, expected); changed {
+		t.Error("expected the defaulted timeout to equal the default timeout")
+	}
+}
+
+// TestDesiredNodePortServiceSessionAffinity verifies that the session affinity
+// option applies to the NodePort service.
+func TestDesiredNodePortServiceSessionAffinity(t *testing.T) {
+	ic := ingressControllerWithAnnotation(ServiceSessionAffinityAnnotation, `{"type": "ClientIP"}`)
+	ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{Type: NodePortServiceStrategyType}
+	service, err := desiredNodePortService(ic, DefaultOperandNamespace, metav1.OwnerReference{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.Spec.SessionAffinity != corev1.ServiceAffinityClientIP || service.Spec.SessionAffinityConfig == nil {
+		t.Errorf("expected ClientIP session affinity, got %s with config %v", service.Spec.SessionAffinity, service.Spec.SessionAffinityConfig)
+	}
+}
@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestServiceChanged(t *testing.T) {
+	testCases := []struct {
+		description string
+		mutate      func(*corev1.Service)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(_ *corev1.Service) {},
+			expect:      false,
+		},
+		{
+			description: "if .spec.clusterIP is allocated",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.ClusterIP = "172.30.0.1"
+			},
+			expect: false,
+		},
+		{
+			description: "if node ports are allocated",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Ports[0].NodePort = 30080
+				svc.Spec.Ports[1].NodePort = 30443
+			},
+			expect: false,
+		},
+		{
+			description: "if the protocol is defaulted",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Ports[0].Protocol = ""
+			},
+			expect: false,
+		},
+		{
+			description: "if the ports change ordering",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Ports[0], svc.Spec.Ports[1] = svc.Spec.Ports[1], svc.Spec.Ports[0]
+			},
+			expect: false,
+		},
+		{
+			description: "if an unmanaged label is added",
+			mutate: func(svc *corev1.Service) {
+				svc.Labels["foo"] = "bar"
+			},
+			expect: false,
+		},
+		{
+			description: "if a managed label is removed",
+			mutate: func(svc *corev1.Service) {
+				delete(svc.Labels, "router")
+			},
+			expect: true,
+		},
+		{
+			description: "if a managed annotation changes",
+			mutate: func(svc *corev1.Service) {
+				svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-proxy-protocol"] = ""
+			},
+			expect: true,
+		},
+		{
+			description: "if the resource tags annotation is no longer expected",
+			mutate: func(svc *corev1.Service) {
+				svc.Annotations[awsLBAdditionalResourceTagsAnnotation] = "team=network"
+			},
+			expect: true,
+		},
+		{
+			description: "if .spec.selector changes",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Selector = map[string]string{"foo": "bar"}
+			},
+			expect: true,
+		},
+		{
+			description: "if a target port changes",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Ports[1].TargetPort = intstr.FromInt(8443)
+			},
+			expect: true,
+		},
+		{
+			description: "if .spec.externalTrafficPolicy changes",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+			},
+			expect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		original := corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "router-original",
+				Namespace: "openshift-ingress",
+				Labels: map[string]string{
+					"router": "router-original",
+				},
+				Annotations: map[string]string{
+					"service.beta.kubernetes.io/aws-load-balancer-proxy-protocol": "*",
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+				Selector: map[string]string{
+					"app": "router",
+				},
+				Ports: []corev1.ServicePort{
+					{
+						Name:       "http",
+						Protocol:   corev1.ProtocolTCP,
+						Port:       80,
+						TargetPort: intstr.FromString("http"),
+					},
+					{
+						Name:       "https",
+						Protocol:   corev1.ProtocolTCP,
+						Port:       443,
+						TargetPort: intstr.FromString("https"),
+					},
+				},
+			},
+		}
+		mutated := original.DeepCopy()
+		tc.mutate(mutated)
+		if changed, updated := serviceChanged(mutated, &original); changed != tc.expect {
+			t.Errorf("%s, expect serviceChanged to be %t, got %t", tc.description, tc.expect, changed)
+		} else if changed {
+			if changedAgain, _ := serviceChanged(updated, &original); changedAgain {
+				t.Errorf("%s, serviceChanged does not behave as a fixed point function", tc.description)
+			}
+		}
+	}
+}
+
+// TestServiceChangedPreservesAllocatedFields verifies that serviceChanged keeps
+// the fields that the API server allocates when it updates a service.
+func TestServiceChangedPreservesAllocatedFields(t *testing.T) {
+	current := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			ClusterIP:             "172.30.0.1",
+			HealthCheckNodePort:   32000,
+			Selector:              map[string]string{"app": "router"},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, NodePort: 30080},
+				{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, NodePort: 30443},
+			},
+		},
+	}
+	testCases := []struct {
+		description               string
+		mutate                    func(*corev1.Service)
+		expectNodePorts           map[string]int32
+		expectHealthCheckNodePort int32
+	}{
+		{
+			description:               "selector changes",
+			mutate:                    func(svc *corev1.Service) { svc.Spec.Selector = map[string]string{"app": "other"} },
+			expectNodePorts:           map[string]int32{"http": 30080, "https": 30443},
+			expectHealthCheckNodePort: 32000,
+		},
+		{
+			description:               "port is renamed",
+			mutate:                    func(svc *corev1.Service) { svc.Spec.Ports[1].Name = "tls" },
+			expectNodePorts:           map[string]int32{"http": 30080, "tls": 30443},
+			expectHealthCheckNodePort: 32000,
+		},
+		{
+			description:               "node port is specified",
+			mutate:                    func(svc *corev1.Service) { svc.Spec.Ports[0].NodePort = 31080 },
+			expectNodePorts:           map[string]int32{"http": 31080, "https": 30443},
+			expectHealthCheckNodePort: 32000,
+		},
+		{
+			description: "external traffic policy changes to Cluster",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+			},
+			expectNodePorts: map[string]int32{"http": 30080, "https": 30443},
+		},
+		{
+			description: "type changes to ClusterIP",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Type = corev1.ServiceTypeClusterIP
+				svc.Spec.ExternalTrafficPolicy = ""
+			},
+			expectNodePorts: map[string]int32{"http": 0, "https": 0},
+		},
+	}
+	for _, tc := range testCases {
+		expected := current.DeepCopy()
+		expected.Spec.ClusterIP = ""
+		expected.Spec.HealthCheckNodePort = 0
+		for i := range expected.Spec.Ports {
+			expected.Spec.Ports[i].NodePort = 0
+		}
+		tc.mutate(expected)
+		changed, updated := serviceChanged(current, expected)
+		if !changed {
+			t.Errorf("%q: expected the service to change", tc.description)
+			continue
+		}
+		if updated.Spec.ClusterIP != current.Spec.ClusterIP {
+			t.Errorf("%q: expected cluster IP %s, got %s", tc.description, current.Spec.ClusterIP, updated.Spec.ClusterIP)
+		}
+		if updated.Spec.HealthCheckNodePort != tc.expectHealthCheckNodePort {
+			t.Errorf("%q: expected health check node port %d, got %d", tc.description, tc.expectHealthCheckNodePort, updated.Spec.HealthCheckNodePort)
+		}
+		nodePorts := map[string]int32{}
+		for _, port := range updated.Spec.Ports {
+			nodePorts[port.Name] = port.NodePort
+		}
+		if !reflect.DeepEqual(nodePorts, tc.expectNodePorts) {
+			t.Errorf("%q: expected node ports %v, got %v", tc.description, tc.expectNodePorts, nodePorts)
+		}
+	}
+}
+
+// TestServiceChangedRemovesStaleManagedMetadata verifies that serviceChanged
+// removes the labels and annotations that the operator set before and no
+// longer expects, and keeps those that other actors set.
+func TestServiceChangedRemovesStaleManagedMetadata(t *testing.T) {
+	current := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"team": "a", "cost-center": "1", "other": "x"},
+			Annotations: map[string]string{"note": "a", "other": "x"},
+		},
+	}
+	setManagedServiceKeys(current)
+	// Labels and annotations added by other actors are not recorded.
+	current.Labels["added"] = "y"
+	current.Annotations["added"] = "y"
+	expected := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"team": "a", "other": "x"},
+		},
+	}
+	setManagedServiceKeys(expected)
+
+	changed, updated := serviceChanged(current, expected)
+	if !changed {
+		t.Fatal("expected the service to change")
+	}
+	expectedLabels := map[string]string{"team": "a", "other": "x", "added": "y"}
+	if !reflect.DeepEqual(updated.Labels, expectedLabels) {
+		t.Errorf("expected labels %v, got %v", expectedLabels, updated.Labels)
+	}
+	if _, ok := updated.Annotations["note"]; ok {
+		t.Error("expected the stale annotation to be removed")
+	}
+	if _, ok := updated.Annotations["added"]; !ok {
+		t.Error("expected the annotation added by another actor to be kept")
+	}
+	if changed, _ := serviceChanged(updated, expected); changed {
+		t.Error("expected the updated service not to change again")
+	}
+}
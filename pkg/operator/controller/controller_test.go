@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestResyncAfter verifies that resyncAfter returns a delay within the jitter
+// range of the resync period, or zero if periodic resyncs are disabled.
+func TestResyncAfter(t *testing.T) {
+	testCases := []struct {
+		description string
+		period      time.Duration
+		expectMin   time.Duration
+		expectMax   time.Duration
+	}{
+		{
+			description: "disabled",
+			period:      -1,
+		},
+		{
+			description: "default period",
+			period:      DefaultResyncPeriod,
+			expectMin:   DefaultResyncPeriod,
+			expectMax:   time.Duration(float64(DefaultResyncPeriod) * (1 + resyncJitterFactor)),
+		},
+		{
+			description: "custom period",
+			period:      time.Minute,
+			expectMin:   time.Minute,
+			expectMax:   time.Duration(float64(time.Minute) * (1 + resyncJitterFactor)),
+		},
+	}
+	for _, tc := range testCases {
+		for i := 0; i < 100; i++ {
+			actual := resyncAfter(tc.period)
+			if actual < tc.expectMin || actual > tc.expectMax {
+				t.Errorf("%q: expected delay in [%v, %v], got %v", tc.description, tc.expectMin, tc.expectMax, actual)
+				break
+			}
+		}
+	}
+}
+
+// TestIngressControllerChanged verifies that ingressControllerChanged ignores
+// status updates other than changes to the Admitted condition.
+func TestIngressControllerChanged(t *testing.T) {
+	now := metav1.Now()
+	testCases := []struct {
+		description string
+		mutate      func(*operatorv1.IngressController)
+		expect      bool
+	}{
+		{
+			description: "no change",
+			mutate:      func(*operatorv1.IngressController) {},
+			expect:      false,
+		},
+		{
+			description: "resourceVersion and status domain change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.ResourceVersion = "2"
+				ic.Status.Domain = "apps.example.com"
+			},
+			expect: false,
+		},
+		{
+			description: "unrelated condition change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Status.Conditions = append(ic.Status.Conditions, operatorv1.OperatorCondition{
+					Type:   "Available",
+					Status: operatorv1.ConditionTrue,
+				})
+			},
+			expect: false,
+		},
+		{
+			description: "Admitted condition message change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Status.Conditions[0].Message = "changed"
+			},
+			expect: false,
+		},
+		{
+			description: "Admitted condition status change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Status.Conditions[0].Status = operatorv1.ConditionFalse
+			},
+			expect: true,
+		},
+		{
+			description: "Admitted condition removed",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Status.Conditions = nil
+			},
+			expect: true,
+		},
+		{
+			description: "generation change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Generation = 2
+			},
+			expect: true,
+		},
+		{
+			description: "annotation change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Annotations = map[string]string{"foo": "bar"}
+			},
+			expect: true,
+		},
+		{
+			description: "finalizer change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Finalizers = []string{IngressControllerFinalizer}
+			},
+			expect: true,
+		},
+		{
+			description: "deletion",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.DeletionTimestamp = &now
+			},
+			expect: true,
+		},
+	}
+	for _, tc := range testCases {
+		old := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "default",
+				Generation:      1,
+				ResourceVersion: "1",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Conditions: []operatorv1.OperatorCondition{{
+					Type:   IngressControllerAdmittedConditionType,
+					Status: operatorv1.ConditionTrue,
+				}},
+			},
+		}
+		new := old.DeepCopy()
+		tc.mutate(new)
+		if actual := ingressControllerChanged(old, new); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
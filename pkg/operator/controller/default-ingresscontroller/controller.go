@@ -0,0 +1,133 @@
+// The default-ingresscontroller controller is responsible for ensuring that
+// the "default" ingresscontroller exists in the operator namespace so that the
+// cluster always has ingress, even if the default ingresscontroller is deleted.
+package defaultingresscontroller
+
+import (
+	"context"
+	"fmt"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "default-ingresscontroller-controller"
+)
+
+var log = logf.Logger.WithName(controllerName)
+
+type reconciler struct {
+	client            client.Client
+	recorder          record.EventRecorder
+	operatorNamespace string
+}
+
+// New returns a new controller that creates the default ingresscontroller if
+// it does not exist.
+func New(mgr manager.Manager, cl client.Client, operatorNamespace string) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		client:            cl,
+		recorder:          mgr.GetEventRecorderFor(controllerName),
+		operatorNamespace: operatorNamespace,
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+
+	// Queue the default ingresscontroller once when the controller starts
+	// so that it is created if it has never existed.
+	initial := make(chan event.GenericEvent, 1)
+	initial <- event.GenericEvent{Meta: &metav1.ObjectMeta{
+		Namespace: operatorNamespace,
+		Name:      controller.DefaultIngressControllerName,
+	}}
+	if err := c.Watch(&source.Channel{Source: initial}, &handler.EnqueueRequestForObject{}); err != nil {
+		return nil, err
+	}
+
+	// Queue the default ingresscontroller again whenever it is deleted.
+	isDefault := func(meta metav1.Object) bool {
+		return meta.GetNamespace() == operatorNamespace && meta.GetName() == controller.DefaultIngressControllerName
+	}
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isDefault(e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Reconcile creates the default ingresscontroller if it does not exist.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	current := &operatorv1.IngressController{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, current); err == nil {
+		return reconcile.Result{}, nil
+	} else if !errors.IsNotFound(err) {
+		return reconcile.Result{}, fmt.Errorf("failed to get ingresscontroller %q: %v", request, err)
+	}
+
+	ingressConfig := &configv1.Ingress{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, ingressConfig); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get ingress 'cluster': %v", err)
+	}
+	infraConfig := &configv1.Infrastructure{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get infrastructure 'cluster': %v", err)
+	}
+
+	desired := desiredDefaultIngressController(r.operatorNamespace, ingressConfig, infraConfig)
+	if err := r.client.Create(context.TODO(), desired); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to create default ingresscontroller: %v", err)
+	}
+	log.Info("created default ingresscontroller", "namespace", desired.Namespace, "name", desired.Name)
+	r.recorder.Eventf(desired, "Normal", "Created", "Created default ingresscontroller with domain %q", desired.Spec.Domain)
+
+	return reconcile.Result{}, nil
+}
+
+// desiredDefaultIngressController returns the default ingresscontroller, with
+// its domain taken from the cluster ingress config and its endpoint publishing
+// strategy chosen for the cluster's platform.
+func desiredDefaultIngressController(namespace string, ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure) *operatorv1.IngressController {
+	return &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      controller.DefaultIngressControllerName,
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			Domain: ingressConfig.Spec.Domain,
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: controller.PublishingStrategyTypeForInfra(infraConfig),
+			},
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package defaultingresscontroller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestDesiredDefaultIngressController(t *testing.T) {
+	testCases := []struct {
+		description    string
+		platform       configv1.PlatformType
+		expectStrategy operatorv1.EndpointPublishingStrategyType
+	}{
+		{
+			description:    "aws",
+			platform:       configv1.AWSPlatformType,
+			expectStrategy: operatorv1.LoadBalancerServiceStrategyType,
+		},
+		{
+			description:    "libvirt",
+			platform:       configv1.LibvirtPlatformType,
+			expectStrategy: operatorv1.HostNetworkStrategyType,
+		},
+		{
+			description:    "unknown platform",
+			platform:       configv1.NonePlatformType,
+			expectStrategy: operatorv1.HostNetworkStrategyType,
+		},
+	}
+
+	for _, tc := range testCases {
+		ingressConfig := &configv1.Ingress{Spec: configv1.IngressSpec{Domain: "apps.example.com"}}
+		infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: tc.platform}}
+		ic := desiredDefaultIngressController("openshift-ingress-operator", ingressConfig, infraConfig)
+		if ic.Name != "default" || ic.Namespace != "openshift-ingress-operator" {
+			t.Errorf("%q: unexpected name %s/%s", tc.description, ic.Namespace, ic.Name)
+		}
+		if ic.Spec.Domain != "apps.example.com" {
+			t.Errorf("%q: expected domain %q, got %q", tc.description, "apps.example.com", ic.Spec.Domain)
+		}
+		if ic.Spec.EndpointPublishingStrategy == nil || ic.Spec.EndpointPublishingStrategy.Type != tc.expectStrategy {
+			t.Errorf("%q: expected strategy %q, got %v", tc.description, tc.expectStrategy, ic.Spec.EndpointPublishingStrategy)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+// The dnsrecord controller is responsible for publishing DNSRecords to the
+// cluster's DNS zones and for deleting them from the zones when a DNSRecord is
+// deleted.  It runs with its own workqueue so that slow DNS provider APIs do
+// not block reconciliation of ingresscontrollers.
+package dnsrecord
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+
+	configv1 "github.com/openshift/api/config/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "dnsrecord-controller"
+
+	// defaultRecordTTL is the TTL in seconds of records whose DNSRecord
+	// does not specify one.
+	defaultRecordTTL = 30
+)
+
+var log = logf.Logger.WithName(controllerName)
+
+// New creates the dnsrecord controller.  The controller watches DNSRecords in
+// the operator namespace and the cluster DNS config, which is read from the
+// given cluster-scoped cache.
+func New(mgr manager.Manager, configCache cache.Cache, cl client.Client, dnsManager dns.Manager, operatorNamespace string) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		client:            cl,
+		cache:             mgr.GetCache(),
+		dnsManager:        dnsManager,
+		operatorNamespace: operatorNamespace,
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Watch(&source.Kind{Type: &ingressv1.DNSRecord{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return nil, err
+	}
+	dnsConfigInformer, err := configCache.GetInformer(&configv1.DNS{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for dns config: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: dnsConfigInformer}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(reconciler.allDNSRecords),
+	}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type reconciler struct {
+	client            client.Client
+	cache             cache.Cache
+	dnsManager        dns.Manager
+	operatorNamespace string
+}
+
+// allDNSRecords returns a reconcile request for every DNSRecord in the
+// operator namespace.  The zones in which records are published come from
+// the cluster DNS config, so a change to the config affects every record.
+func (r *reconciler) allDNSRecords(o handler.MapObject) []reconcile.Request {
+	requests := []reconcile.Request{}
+	records := &ingressv1.DNSRecordList{}
+	if err := r.cache.List(context.TODO(), records, client.InNamespace(r.operatorNamespace)); err != nil {
+		log.Error(err, "failed to list dnsrecords", "related", o.Meta.GetSelfLink())
+		return requests
+	}
+	for _, record := range records.Items {
+		log.Info("queueing dnsrecord", "name", record.Name, "related", o.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: record.Namespace,
+				Name:      record.Name,
+			},
+		})
+	}
+	return requests
+}
+
+// Reconcile publishes the requested DNSRecord to each zone in the cluster DNS
+// config and reports the result in the DNSRecord's status, or deletes the
+// published records if the DNSRecord is being deleted.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	record := &ingressv1.DNSRecord{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, record); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("dnsrecord not found; reconciliation will be skipped", "request", request)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get dnsrecord %q: %v", request, err)
+	}
+
+	dnsConfig := &configv1.DNS{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, dnsConfig); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get dns 'cluster': %v", err)
+	}
+
+	if record.DeletionTimestamp != nil {
+		return reconcile.Result{}, r.finalizeRecord(record, dnsConfig)
+	}
+
+	if !slice.ContainsString(record.Finalizers, controller.DNSRecordFinalizer) {
+		updated := record.DeepCopy()
+		updated.Finalizers = append(updated.Finalizers, controller.DNSRecordFinalizer)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to add finalizer to dnsrecord %s/%s: %v", record.Namespace, record.Name, err)
+		}
+		record = updated
+	}
+
+	statuses, errs := r.publishRecordToZones(record, zonesFor(dnsConfig, record.Spec.ZoneType))
+	updated := record.DeepCopy()
+	updated.Status.Zones = statuses
+	updated.Status.Conditions = recordConditions(record, dnsConfig, metav1.Now())
+	updated.Status.ObservedGeneration = record.Generation
+	if !reflect.DeepEqual(updated.Status, record.Status) {
+		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update status of dnsrecord %s/%s: %v", record.Namespace, record.Name, err))
+		}
+	}
+
+	return reconcile.Result{}, utilerrors.NewAggregate(errs)
+}
+
+// zonesFor returns the zones in the given DNS config in which records of the
+// given zone type are published.  Records without a zone type are published in
+// every zone.
+func zonesFor(dnsConfig *configv1.DNS, zoneType ingressv1.DNSZoneType) []configv1.DNSZone {
+	var zones []configv1.DNSZone
+	if dnsConfig.Spec.PrivateZone != nil && zoneType != ingressv1.PublicZoneType {
+		zones = append(zones, *dnsConfig.Spec.PrivateZone)
+	}
+	if dnsConfig.Spec.PublicZone != nil && zoneType != ingressv1.PrivateZoneType {
+		zones = append(zones, *dnsConfig.Spec.PublicZone)
+	}
+	return zones
+}
+
+// recordConditions returns the record-level conditions of the given DNSRecord
+// for the given DNS config.  If the config has only a private zone, a record
+// that would otherwise be published to the public zone gets the
+// PublishedPrivateOnly condition.  The last transition time is kept from the
+// DNSRecord's current condition if the status has not changed.
+func recordConditions(record *ingressv1.DNSRecord, dnsConfig *configv1.DNS, now metav1.Time) []ingressv1.DNSZoneCondition {
+	if record.Spec.ZoneType == ingressv1.PrivateZoneType || dnsConfig.Spec.PublicZone != nil || dnsConfig.Spec.PrivateZone == nil {
+		return nil
+	}
+	condition := ingressv1.DNSZoneCondition{
+		Type:               ingressv1.DNSRecordPublishedPrivateOnlyConditionType,
+		Status:             string(configv1.ConditionTrue),
+		Reason:             "NoPublicZone",
+		Message:            "The cluster DNS config has no public zone, so the record is published only to the private zone",
+		LastTransitionTime: now,
+	}
+	if record.Spec.ZoneType == ingressv1.PublicZoneType {
+		condition.Message = "The cluster DNS config has no public zone, so the record, which is only for the public zone, is not published"
+	}
+	for _, c := range record.Status.Conditions {
+		if c.Type == condition.Type && c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+	}
+	return []ingressv1.DNSZoneCondition{condition}
+}
+
+// publishRecordToZones publishes the given DNSRecord to each of the given zones
+// and returns the resulting zone statuses.  If the DNSRecord is a dry run, the
+// statuses report the planned change instead, and a record that the operator
+// published before the DNSRecord became a dry run is left as it is.
+func (r *reconciler) publishRecordToZones(record *ingressv1.DNSRecord, zones []configv1.DNSZone) ([]ingressv1.DNSZoneStatus, []error) {
+	var statuses []ingressv1.DNSZoneStatus
+	var errs []error
+	now := metav1.Now()
+	for i := range zones {
+		if record.Spec.DryRun {
+			status, err := r.planRecordInZone(record, zones[i])
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if _, ok := publishedZoneStatus(record.Status.Zones, zones[i]); ok && err == nil {
+				// Keep reporting the record as published so
+				// that finalizeRecord deletes it.
+				status.Conditions[0].Status = string(configv1.ConditionTrue)
+				status.Conditions[0].Message = "The record was published before spec.dryRun was set and is left as it is"
+				if len(status.PlannedChange) != 0 {
+					status.Conditions[0].Message += "; apply the change in plannedChange to update it"
+				}
+			}
+			status.Conditions[0] = zoneCondition(record.Status.Zones, zones[i], status.Conditions[0], now)
+			statuses = append(statuses, status)
+			continue
+		}
+		condition := ingressv1.DNSZoneCondition{
+			Type:   ingressv1.DNSRecordPublishedConditionType,
+			Status: string(configv1.ConditionTrue),
+			Reason: "ProviderSuccess",
+		}
+		if err := r.ensureRecordInZone(record, zones[i]); err != nil {
+			errs = append(errs, err)
+			condition.Status = string(configv1.ConditionFalse)
+			condition.Reason = "ProviderError"
+			condition.Message = err.Error()
+		}
+		statuses = append(statuses, ingressv1.DNSZoneStatus{
+			DNSZone:    zones[i],
+			Conditions: []ingressv1.DNSZoneCondition{zoneCondition(record.Status.Zones, zones[i], condition, now)},
+		})
+	}
+	return statuses, errs
+}
+
+// zoneCondition returns the given condition with its last transition time set
+// to now, or to the time from the matching condition in the given zone
+// statuses if the status has not changed.
+func zoneCondition(zoneStatuses []ingressv1.DNSZoneStatus, zone configv1.DNSZone, condition ingressv1.DNSZoneCondition, now metav1.Time) ingressv1.DNSZoneCondition {
+	condition.LastTransitionTime = now
+	for _, zs := range zoneStatuses {
+		if !reflect.DeepEqual(zs.DNSZone, zone) {
+			continue
+		}
+		for _, c := range zs.Conditions {
+			if c.Type == condition.Type && c.Status == condition.Status {
+				condition.LastTransitionTime = c.LastTransitionTime
+			}
+		}
+	}
+	return condition
+}
+
+// publishedZoneStatus returns the status for the given zone from the given zone
+// statuses if the status reports the record as published in the zone.
+func publishedZoneStatus(zoneStatuses []ingressv1.DNSZoneStatus, zone configv1.DNSZone) (ingressv1.DNSZoneStatus, bool) {
+	for _, zs := range zoneStatuses {
+		if !reflect.DeepEqual(zs.DNSZone, zone) {
+			continue
+		}
+		for _, c := range zs.Conditions {
+			if c.Type == ingressv1.DNSRecordPublishedConditionType && c.Status == string(configv1.ConditionTrue) {
+				return zs, true
+			}
+		}
+	}
+	return ingressv1.DNSZoneStatus{}, false
+}
+
+// ensureRecordInZone publishes the given DNSRecord to the given zone.
+func (r *reconciler) ensureRecordInZone(record *ingressv1.DNSRecord, zone configv1.DNSZone) error {
+	dnsRecord, err := dnsRecordFor(record, zone)
+	if err != nil {
+		return err
+	}
+	if err := r.dnsManager.Ensure(dnsRecord); err != nil {
+		return fmt.Errorf("failed to publish DNS record %v to zone %v: %v", dnsRecord, zone, err)
+	}
+	log.Info("published DNS record to zone", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+// planRecordInZone returns the status of the given dry-run DNSRecord in the
+// given zone, which has the change that publishing the record requires if the
+// DNS provider can plan it.
+func (r *reconciler) planRecordInZone(record *ingressv1.DNSRecord, zone configv1.DNSZone) (ingressv1.DNSZoneStatus, error) {
+	status := ingressv1.DNSZoneStatus{DNSZone: zone}
+	condition := ingressv1.DNSZoneCondition{
+		Type:   ingressv1.DNSRecordPublishedConditionType,
+		Status: string(configv1.ConditionFalse),
+	}
+	var err error
+	planner, ok := r.dnsManager.(dns.Planner)
+	switch dnsRecord, recordErr := dnsRecordFor(record, zone); {
+	case recordErr != nil:
+		err = recordErr
+		condition.Reason = "ProviderError"
+		condition.Message = err.Error()
+	case !ok:
+		condition.Reason = "DryRunUnsupported"
+		condition.Message = "The record is not published because spec.dryRun is true, and the DNS provider cannot plan the change"
+	default:
+		plan, planErr := planner.PlanEnsure(dnsRecord)
+		if planErr != nil {
+			err = fmt.Errorf("failed to plan DNS record %v in zone %v: %v", dnsRecord, zone, planErr)
+			condition.Reason = "ProviderError"
+			condition.Message = err.Error()
+			break
+		}
+		status.PlannedChange = plan
+		condition.Reason = "DryRun"
+		condition.Message = "The record is not published because spec.dryRun is true; apply the change in plannedChange to publish it"
+	}
+	status.Conditions = []ingressv1.DNSZoneCondition{condition}
+	return status, err
+}
+
+// finalizeRecord deletes the given DNSRecord from each zone in which it was
+// published and then removes the DNSRecord's finalizer.  If the DNSRecord is a
+// dry run, the record is deleted only from the zones in which the operator
+// published it before the DNSRecord became a dry run; elsewhere, the record was
+// applied by an administrator, who is responsible for deleting it.
+func (r *reconciler) finalizeRecord(record *ingressv1.DNSRecord, dnsConfig *configv1.DNS) error {
+	if !slice.ContainsString(record.Finalizers, controller.DNSRecordFinalizer) {
+		return nil
+	}
+
+	var zones []configv1.DNSZone
+	if record.Spec.DryRun {
+		for _, zs := range record.Status.Zones {
+			if _, ok := publishedZoneStatus(record.Status.Zones, zs.DNSZone); ok {
+				zones = append(zones, zs.DNSZone)
+			}
+		}
+	} else {
+		// Delete the record from the zones in which it was published
+		// as well as from the zones in the current config, in case the
+		// record was published but its status could not be updated.
+		// Zones of the other type are left alone because another
+		// record with the same name may be published there.
+		zones = zonesFor(dnsConfig, record.Spec.ZoneType)
+		for _, zs := range record.Status.Zones {
+			found := false
+			for i := range zones {
+				if reflect.DeepEqual(zones[i], zs.DNSZone) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				zones = append(zones, zs.DNSZone)
+			}
+		}
+	}
+	var errs []error
+	for _, zone := range zones {
+		if err := r.deleteRecordFromZone(record, zone); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return err
+	}
+	return r.removeFinalizer(record)
+}
+
+// deleteRecordFromZone deletes the given DNSRecord from the given zone.
+func (r *reconciler) deleteRecordFromZone(record *ingressv1.DNSRecord, zone configv1.DNSZone) error {
+	dnsRecord, err := dnsRecordFor(record, zone)
+	if err != nil {
+		return err
+	}
+	if err := r.dnsManager.Delete(dnsRecord); err != nil {
+		return fmt.Errorf("failed to delete DNS record %v from zone %v: %v", dnsRecord, zone, err)
+	}
+	log.Info("deleted DNS record from zone", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+// removeFinalizer removes the dnsrecord finalizer from the given DNSRecord.
+func (r *reconciler) removeFinalizer(record *ingressv1.DNSRecord) error {
+	updated := record.DeepCopy()
+	updated.Finalizers = slice.RemoveString(updated.Finalizers, controller.DNSRecordFinalizer)
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to remove finalizer from dnsrecord %s/%s: %v", record.Namespace, record.Name, err)
+	}
+	return nil
+}
+
+// dnsRecordFor returns the DNS provider record for the given DNSRecord in the
+// given zone.
+func dnsRecordFor(record *ingressv1.DNSRecord, zone configv1.DNSZone) (*dns.Record, error) {
+	if len(record.Spec.Targets) == 0 {
+		return nil, fmt.Errorf("dnsrecord %s/%s has no targets", record.Namespace, record.Name)
+	}
+	if err := controller.ValidateDNSRoutingPolicy(record.Spec.RoutingPolicy); err != nil {
+		return nil, fmt.Errorf("dnsrecord %s/%s has invalid routing policy: %v", record.Namespace, record.Name, err)
+	}
+	switch record.Spec.RecordType {
+	case ingressv1.CNAMERecordType:
+		if len(record.Spec.Targets) != 1 {
+			return nil, fmt.Errorf("dnsrecord %s/%s has %d targets, but a CNAME record must have exactly one", record.Namespace, record.Name, len(record.Spec.Targets))
+		}
+		return &dns.Record{
+			Zone: zone,
+			Type: dns.ALIASRecord,
+			Alias: &dns.AliasRecord{
+				Domain: record.Spec.DNSName,
+				Target: record.Spec.Targets[0],
+			},
+			RoutingPolicy: record.Spec.RoutingPolicy,
+		}, nil
+	case ingressv1.ARecordType:
+		targets := sets.NewString()
+		for _, target := range record.Spec.Targets {
+			if ip := net.ParseIP(target); ip == nil || ip.To4() == nil {
+				return nil, fmt.Errorf("dnsrecord %s/%s has invalid target %q: must be an IPv4 address", record.Namespace, record.Name, target)
+			}
+			targets.Insert(target)
+		}
+		ttl := record.Spec.RecordTTL
+		if ttl == 0 {
+			ttl = defaultRecordTTL
+		}
+		return &dns.Record{
+			Zone: zone,
+			Type: dns.ARecord,
+			A: &dns.AddressRecord{
+				Domain:  record.Spec.DNSName,
+				Targets: targets.List(),
+				TTL:     ttl,
+			},
+			RoutingPolicy: record.Spec.RoutingPolicy,
+		}, nil
+	}
+	return nil, fmt.Errorf("dnsrecord %s/%s has unsupported record type %q", record.Namespace, record.Name, record.Spec.RecordType)
+}
@@ -0,0 +1,395 @@
+package dnsrecord
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDNSRecordFor(t *testing.T) {
+	zone := configv1.DNSZone{ID: "zone"}
+	testCases := []struct {
+		description string
+		spec        ingressv1.DNSRecordSpec
+		expect      *dns.Record
+	}{
+		{
+			description: "CNAME record",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.CNAMERecordType,
+				Targets:    []string{"lb.example.com"},
+			},
+			expect: &dns.Record{
+				Zone: zone,
+				Type: dns.ALIASRecord,
+				Alias: &dns.AliasRecord{
+					Domain: "*.apps.example.com",
+					Target: "lb.example.com",
+				},
+			},
+		},
+		{
+			description: "CNAME record without targets",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.CNAMERecordType,
+			},
+		},
+		{
+			description: "CNAME record with multiple targets",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.CNAMERecordType,
+				Targets:    []string{"lb1.example.com", "lb2.example.com"},
+			},
+		},
+		{
+			description: "A record with multiple targets",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.ARecordType,
+				Targets:    []string{"192.0.2.2", "192.0.2.1", "192.0.2.2"},
+				RecordTTL:  60,
+			},
+			expect: &dns.Record{
+				Zone: zone,
+				Type: dns.ARecord,
+				A: &dns.AddressRecord{
+					Domain:  "*.apps.example.com",
+					Targets: []string{"192.0.2.1", "192.0.2.2"},
+					TTL:     60,
+				},
+			},
+		},
+		{
+			description: "A record with the default TTL",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.ARecordType,
+				Targets:    []string{"192.0.2.1"},
+			},
+			expect: &dns.Record{
+				Zone: zone,
+				Type: dns.ARecord,
+				A: &dns.AddressRecord{
+					Domain:  "*.apps.example.com",
+					Targets: []string{"192.0.2.1"},
+					TTL:     defaultRecordTTL,
+				},
+			},
+		},
+		{
+			description: "A record with a hostname target",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.ARecordType,
+				Targets:    []string{"lb.example.com"},
+			},
+		},
+		{
+			description: "CNAME record with a failover routing policy",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.CNAMERecordType,
+				Targets:    []string{"lb.example.com"},
+				RoutingPolicy: &ingressv1.DNSRoutingPolicy{
+					Type:          ingressv1.FailoverRoutingPolicyType,
+					SetIdentifier: "east",
+					Failover:      &ingressv1.FailoverDNSRoutingPolicy{Role: ingressv1.PrimaryFailoverRole},
+				},
+			},
+			expect: &dns.Record{
+				Zone: zone,
+				Type: dns.ALIASRecord,
+				Alias: &dns.AliasRecord{
+					Domain: "*.apps.example.com",
+					Target: "lb.example.com",
+				},
+				RoutingPolicy: &ingressv1.DNSRoutingPolicy{
+					Type:          ingressv1.FailoverRoutingPolicyType,
+					SetIdentifier: "east",
+					Failover:      &ingressv1.FailoverDNSRoutingPolicy{Role: ingressv1.PrimaryFailoverRole},
+				},
+			},
+		},
+		{
+			description: "A record with an invalid routing policy",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.ARecordType,
+				Targets:    []string{"192.0.2.1"},
+				RoutingPolicy: &ingressv1.DNSRoutingPolicy{
+					Type:          ingressv1.WeightedRoutingPolicyType,
+					SetIdentifier: "east",
+				},
+			},
+		},
+		{
+			description: "A record with an IPv6 target",
+			spec: ingressv1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com",
+				RecordType: ingressv1.ARecordType,
+				Targets:    []string{"2001:db8::1"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		record := &ingressv1.DNSRecord{Spec: tc.spec}
+		actual, err := dnsRecordFor(record, zone)
+		switch {
+		case tc.expect == nil && err == nil:
+			t.Errorf("%q: expected error, got %v", tc.description, actual)
+		case tc.expect != nil && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case tc.expect != nil && !reflect.DeepEqual(actual, tc.expect):
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestZoneCondition(t *testing.T) {
+	then := metav1.NewTime(time.Unix(0, 0))
+	now := metav1.NewTime(time.Unix(60, 0))
+	zone := configv1.DNSZone{ID: "zone"}
+	otherZone := configv1.DNSZone{ID: "other"}
+	published := ingressv1.DNSZoneCondition{
+		Type:   ingressv1.DNSRecordPublishedConditionType,
+		Status: string(configv1.ConditionTrue),
+		Reason: "ProviderSuccess",
+	}
+	failed := ingressv1.DNSZoneCondition{
+		Type:    ingressv1.DNSRecordPublishedConditionType,
+		Status:  string(configv1.ConditionFalse),
+		Reason:  "ProviderError",
+		Message: "boom",
+	}
+	current := []ingressv1.DNSZoneStatus{{
+		DNSZone: zone,
+		Conditions: []ingressv1.DNSZoneCondition{{
+			Type:               ingressv1.DNSRecordPublishedConditionType,
+			Status:             string(configv1.ConditionTrue),
+			Reason:             "ProviderSuccess",
+			LastTransitionTime: then,
+		}},
+	}}
+	testCases := []struct {
+		description string
+		zone        configv1.DNSZone
+		condition   ingressv1.DNSZoneCondition
+		expect      metav1.Time
+	}{
+		{"unchanged status", zone, published, then},
+		{"changed status", zone, failed, now},
+		{"new zone", otherZone, published, now},
+	}
+	for _, tc := range testCases {
+		actual := zoneCondition(current, tc.zone, tc.condition, now)
+		if !actual.LastTransitionTime.Equal(&tc.expect) {
+			t.Errorf("%q: expected last transition time %v, got %v", tc.description, tc.expect, actual.LastTransitionTime)
+		}
+		if actual.Status != tc.condition.Status || actual.Reason != tc.condition.Reason || actual.Message != tc.condition.Message {
+			t.Errorf("%q: expected condition %v, got %v", tc.description, tc.condition, actual)
+		}
+	}
+}
+
+func TestZonesFor(t *testing.T) {
+	private := configv1.DNSZone{ID: "private"}
+	public := configv1.DNSZone{ID: "public"}
+	dnsConfig := &configv1.DNS{
+		Spec: configv1.DNSSpec{
+			PrivateZone: &private,
+			PublicZone:  &public,
+		},
+	}
+	testCases := []struct {
+		description string
+		zoneType    ingressv1.DNSZoneType
+		expect      []configv1.DNSZone
+	}{
+		{"no zone type", "", []configv1.DNSZone{private, public}},
+		{"public zone type", ingressv1.PublicZoneType, []configv1.DNSZone{public}},
+		{"private zone type", ingressv1.PrivateZoneType, []configv1.DNSZone{private}},
+	}
+	for _, tc := range testCases {
+		if actual := zonesFor(dnsConfig, tc.zoneType); !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%q: expected zones %v, got %v", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// fakePlanner is a DNS manager that plans changes without making them.
+type fakePlanner struct {
+	dns.NoopManager
+}
+
+func (_ *fakePlanner) PlanEnsure(record *dns.Record) (string, error) {
+	return "UPSERT " + record.String(), nil
+}
+
+// TestPublishDryRunRecordToZones verifies that a dry-run DNSRecord is reported
+// as unpublished with the planned change, or without one if the DNS provider
+// cannot plan changes.
+func TestPublishDryRunRecordToZones(t *testing.T) {
+	zones := []configv1.DNSZone{{ID: "private"}, {ID: "public"}}
+	record := &ingressv1.DNSRecord{
+		Spec: ingressv1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com",
+			RecordType: ingressv1.CNAMERecordType,
+			Targets:    []string{"lb.example.com"},
+			DryRun:     true,
+		},
+	}
+	testCases := []struct {
+		description  string
+		dnsManager   dns.Manager
+		expectReason string
+		expectPlan   string
+	}{
+		{"planner", &fakePlanner{}, "DryRun", "UPSERT *.apps.example.com -> lb.example.com"},
+		{"no planner", &dns.NoopManager{}, "DryRunUnsupported", ""},
+	}
+	for _, tc := range testCases {
+		r := &reconciler{dnsManager: tc.dnsManager}
+		statuses, errs := r.publishRecordToZones(record, zones)
+		if len(errs) != 0 {
+			t.Errorf("%q: unexpected errors: %v", tc.description, errs)
+		}
+		if len(statuses) != len(zones) {
+			t.Fatalf("%q: expected %d zone statuses, got %v", tc.description, len(zones), statuses)
+		}
+		for _, status := range statuses {
+			condition := status.Conditions[0]
+			if condition.Status != string(configv1.ConditionFalse) || condition.Reason != tc.expectReason {
+				t.Errorf("%q: expected Published=False with reason %s, got %v", tc.description, tc.expectReason, condition)
+			}
+			if status.PlannedChange != tc.expectPlan {
+				t.Errorf("%q: expected planned change %q, got %q", tc.description, tc.expectPlan, status.PlannedChange)
+			}
+		}
+	}
+}
+
+// fakeDeleter is a DNS manager that plans changes as fakePlanner does and
+// records the zones from which records are deleted.
+type fakeDeleter struct {
+	fakePlanner
+
+	deleted []string
+}
+
+func (m *fakeDeleter) Delete(record *dns.Record) error {
+	m.deleted = append(m.deleted, record.Zone.ID)
+	return nil
+}
+
+// TestPublishDryRunRecordKeepsPublishedRecord verifies that switching a
+// DNSRecord to a dry run leaves the record in the zones in which the operator
+// published it and keeps reporting it as published there.
+func TestPublishDryRunRecordKeepsPublishedRecord(t *testing.T) {
+	zones := []configv1.DNSZone{{ID: "private"}, {ID: "public"}}
+	record := &ingressv1.DNSRecord{
+		Spec: ingressv1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com",
+			RecordType: ingressv1.CNAMERecordType,
+			Targets:    []string{"lb.example.com"},
+			DryRun:     true,
+		},
+		Status: ingressv1.DNSRecordStatus{
+			Zones: []ingressv1.DNSZoneStatus{{
+				DNSZone: zones[0],
+				Conditions: []ingressv1.DNSZoneCondition{{
+					Type:   ingressv1.DNSRecordPublishedConditionType,
+					Status: string(configv1.ConditionTrue),
+					Reason: "ProviderSuccess",
+				}},
+			}},
+		},
+	}
+	m := &fakeDeleter{}
+	r := &reconciler{dnsManager: m}
+	statuses, errs := r.publishRecordToZones(record, zones)
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if len(m.deleted) != 0 {
+		t.Errorf("expected no deletions, got deletions from zones %v", m.deleted)
+	}
+	if len(statuses) != len(zones) {
+		t.Fatalf("expected %d zone statuses, got %v", len(zones), statuses)
+	}
+	expectStatus := []configv1.ConditionStatus{configv1.ConditionTrue, configv1.ConditionFalse}
+	for i, status := range statuses {
+		condition := status.Conditions[0]
+		if condition.Status != string(expectStatus[i]) || condition.Reason != "DryRun" {
+			t.Errorf("zone %s: expected Published=%s with reason DryRun, got %v", zones[i].ID, expectStatus[i], condition)
+		}
+		if len(status.PlannedChange) == 0 {
+			t.Errorf("zone %s: expected a planned change", zones[i].ID)
+		}
+	}
+}
+
+// TestRecordConditions verifies that a DNSRecord that would be published to
+// the public zone gets the PublishedPrivateOnly condition if the DNS config has
+// only a private zone.
+func TestRecordConditions(t *testing.T) {
+	then := metav1.NewTime(time.Unix(0, 0))
+	now := metav1.NewTime(time.Unix(60, 0))
+	private := configv1.DNSZone{ID: "private"}
+	public := configv1.DNSZone{ID: "public"}
+	privateOnly := &configv1.DNS{Spec: configv1.DNSSpec{PrivateZone: &private}}
+	both := &configv1.DNS{Spec: configv1.DNSSpec{PrivateZone: &private, PublicZone: &public}}
+	testCases := []struct {
+		description string
+		dnsConfig   *configv1.DNS
+		zoneType    ingressv1.DNSZoneType
+		current     []ingressv1.DNSZoneCondition
+		expect      bool
+		expectTime  metav1.Time
+	}{
+		{"both zones", both, "", nil, false, now},
+		{"private zone only", privateOnly, "", nil, true, now},
+		{"private zone only, public record", privateOnly, ingressv1.PublicZoneType, nil, true, now},
+		{"private zone only, private record", privateOnly, ingressv1.PrivateZoneType, nil, false, now},
+		{"no zones", &configv1.DNS{}, "", nil, false, now},
+		{
+			description: "private zone only, unchanged",
+			dnsConfig:   privateOnly,
+			current: []ingressv1.DNSZoneCondition{{
+				Type:               ingressv1.DNSRecordPublishedPrivateOnlyConditionType,
+				Status:             string(configv1.ConditionTrue),
+				LastTransitionTime: then,
+			}},
+			expect:     true,
+			expectTime: then,
+		},
+	}
+	for _, tc := range testCases {
+		record := &ingressv1.DNSRecord{
+			Spec:   ingressv1.DNSRecordSpec{ZoneType: tc.zoneType},
+			Status: ingressv1.DNSRecordStatus{Conditions: tc.current},
+		}
+		conditions := recordConditions(record, tc.dnsConfig, now)
+		if !tc.expect {
+			if len(conditions) != 0 {
+				t.Errorf("%q: expected no conditions, got %v", tc.description, conditions)
+			}
+			continue
+		}
+		if len(conditions) != 1 || conditions[0].Type != ingressv1.DNSRecordPublishedPrivateOnlyConditionType || conditions[0].Status != string(configv1.ConditionTrue) {
+			t.Errorf("%q: expected PublishedPrivateOnly=True, got %v", tc.description, conditions)
+			continue
+		}
+		if !conditions[0].LastTransitionTime.Equal(&tc.expectTime) {
+			t.Errorf("%q: expected last transition time %v, got %v", tc.description, tc.expectTime, conditions[0].LastTransitionTime)
+		}
+	}
+}
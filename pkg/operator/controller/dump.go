@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DesiredOperands returns the router workload, services, and wildcard DNSRecords
+// that the operator would apply for the given ingresscontroller, rendered
+// exactly as the reconciler renders them, without applying them.  It only
+// reads from the API, so it can be used to debug why the operator generates a
+// particular configuration.  Each wildcard DNSRecord is included only if the
+// corresponding load balancer has been provisioned.
+func DesiredOperands(cl operatorclient.Client, config Config, ic *operatorv1.IngressController, infraConfig *configv1.Infrastructure) ([]runtime.Object, error) {
+	if ic.Status.EndpointPublishingStrategy == nil {
+		return nil, fmt.Errorf("ingresscontroller %s/%s has not been admitted", ic.Namespace, ic.Name)
+	}
+	r := &reconciler{Config: config, client: cl}
+	return r.desiredOperands(ic, infraConfig)
+}
+
+// desiredOperands renders the operands of the given ingresscontroller.
+func (r *reconciler) desiredOperands(ic *operatorv1.IngressController, infraConfig *configv1.Infrastructure) ([]runtime.Object, error) {
+	var objects []runtime.Object
+
+	useDaemonSet, err := routerUsesDaemonSet(ic)
+	if err != nil {
+		return nil, err
+	}
+	var currentPodSpec *corev1.PodSpec
+	if useDaemonSet {
+		current, err := r.currentRouterDaemonSet(ic)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil {
+			currentPodSpec = &current.Spec.Template.Spec
+		}
+	} else {
+		current, err := r.currentRouterDeployment(ic)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil {
+			currentPodSpec = &current.Spec.Template.Spec
+		}
+	}
+	deployment, err := r.buildRouterDeployment(ic, infraConfig, useDaemonSet, currentPodSpec)
+	if err != nil {
+		return nil, err
+	}
+	deploymentRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       deployment.Name,
+	}
+	if useDaemonSet {
+		daemonset := desiredRouterDaemonSet(deployment)
+		daemonset.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: daemonSetKind}
+		deploymentRef.Kind = daemonSetKind
+		objects = append(objects, daemonset)
+	} else {
+		deployment.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+		objects = append(objects, deployment)
+	}
+
+	lbService, err := desiredLoadBalancerService(ic, r.Config.OperandNamespace, deploymentRef, infraConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build load balancer service: %v", err)
+	}
+	if err := r.setLoadBalancerResourceTags(lbService, infraConfig); err != nil {
+		return nil, err
+	}
+	if lbService != nil {
+		lbService.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+		if _, annotated := externalDNSAnnotationsChanged(lbService, desiredExternalDNSAnnotations(ic)); annotated != nil {
+			lbService = annotated
+		}
+		objects = append(objects, lbService)
+	}
+	internalLBService, err := desiredInternalLoadBalancerService(ic, r.Config.OperandNamespace, deploymentRef, infraConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build internal load balancer service: %v", err)
+	}
+	if err := r.setLoadBalancerResourceTags(internalLBService, infraConfig); err != nil {
+		return nil, err
+	}
+	if internalLBService != nil {
+		internalLBService.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+		objects = append(objects, internalLBService)
+	}
+	nodePortService, err := desiredNodePortService(ic, r.Config.OperandNamespace, deploymentRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build NodePort service: %v", err)
+	}
+	if nodePortService != nil {
+		nodePortService.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+		objects = append(objects, nodePortService)
+	}
+	internalService, err := desiredInternalIngressControllerService(ic, r.Config.OperandNamespace, deploymentRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build internal service: %v", err)
+	}
+	internalService.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+	objects = append(objects, internalService)
+
+	if lbService != nil {
+		current, err := r.currentLoadBalancerService(ic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get load balancer service: %v", err)
+		}
+		if current != nil && isLoadBalancerProvisioned(current) {
+			if record := desiredWildcardRecord(ic, loadBalancerAddress(current)); record != nil {
+				record.TypeMeta = metav1.TypeMeta{APIVersion: ingressv1.GroupVersion.String(), Kind: "DNSRecord"}
+				objects = append(objects, record)
+			}
+		}
+	}
+	if internalLBService != nil {
+		current := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), InternalLoadBalancerServiceName(ic, r.Config.OperandNamespace), current); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get internal load balancer service: %v", err)
+			}
+		} else if isLoadBalancerProvisioned(current) {
+			if record := desiredInternalWildcardRecord(ic, loadBalancerAddress(current)); record != nil {
+				record.TypeMeta = metav1.TypeMeta{APIVersion: ingressv1.GroupVersion.String(), Kind: "DNSRecord"}
+				objects = append(objects, record)
+			}
+		}
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,462 @@
+// The external-certificate controller is responsible for granting routers
+// access to the secrets that routes reference through
+// spec.tls.externalCertificate.  For each namespace with such routes, it
+// manages a role and role binding per ingresscontroller that allow the
+// ingresscontroller's router service account to read exactly the secrets
+// that the routes that the ingresscontroller selects reference, and it
+// removes them once they are no longer needed.
+package externalcertificate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "external-certificate-controller"
+)
+
+var (
+	log = logf.Logger.WithName(controllerName)
+
+	// routeGVK is the group, version, and kind of routes.  The vendored
+	// route API does not have spec.tls.externalCertificate, so routes are
+	// read as unstructured objects.
+	routeGVK = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+
+	// managedLabels selects the roles and role bindings that the
+	// controller manages.
+	managedLabels = client.MatchingLabels(map[string]string{
+		controller.RouterExternalCertificateRoleLabel: "true",
+	})
+)
+
+type reconciler struct {
+	client            client.Client
+	cache             cache.Cache
+	operatorNamespace string
+	operandNamespace  string
+	enabled           bool
+}
+
+// New creates the external-certificate controller.  Requests are namespaces.
+// If enabled is true, the controller watches routes and namespaces through the
+// given cluster-scoped cache; otherwise, it only removes the roles and role
+// bindings that it created while the RouteExternalCertificate feature was
+// enabled.
+func New(mgr manager.Manager, clusterCache cache.Cache, cl client.Client, operatorNamespace, operandNamespace string, enabled bool) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		client:            cl,
+		cache:             clusterCache,
+		operatorNamespace: operatorNamespace,
+		operandNamespace:  operandNamespace,
+		enabled:           enabled,
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+
+	// Queue the affected namespaces when the controller starts, so that
+	// roles that became stale while the operator was not running are
+	// updated or removed, when an ingresscontroller is deleted, and when
+	// its selectors change.
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(reconciler.affectedNamespaces),
+	}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return true },
+		DeleteFunc: func(e event.DeleteEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*operatorv1.IngressController)
+			if !ok {
+				return false
+			}
+			new, ok := e.ObjectNew.(*operatorv1.IngressController)
+			if !ok {
+				return false
+			}
+			return !reflect.DeepEqual(old.Spec.RouteSelector, new.Spec.RouteSelector) ||
+				!reflect.DeepEqual(old.Spec.NamespaceSelector, new.Spec.NamespaceSelector) ||
+				old.DeletionTimestamp != new.DeletionTimestamp
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return c, nil
+	}
+
+	toNamespace := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: a.Meta.GetNamespace()}}}
+		}),
+	}
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(routeGVK)
+	routeInformer, err := clusterCache.GetInformer(route)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get informer for routes: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: routeInformer}, toNamespace, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return len(externalCertificateName(e.Object)) != 0 },
+		DeleteFunc: func(e event.DeleteEvent) bool { return len(externalCertificateName(e.Object)) != 0 },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, new := externalCertificateName(e.ObjectOld), externalCertificateName(e.ObjectNew)
+			if old != new {
+				return true
+			}
+			return len(new) != 0 && !reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create watch for routes: %v", err)
+	}
+
+	// Namespace selectors match namespace labels, so queue a namespace
+	// when its labels change.
+	namespaceInformer, err := clusterCache.GetInformer(&corev1.Namespace{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get informer for namespaces: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: namespaceInformer}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create watch for namespaces: %v", err)
+	}
+	return c, nil
+}
+
+// affectedNamespaces returns a request for each namespace that has roles that
+// the controller manages or, if the feature is enabled, routes that reference
+// external certificates.
+func (r *reconciler) affectedNamespaces(a handler.MapObject) []reconcile.Request {
+	namespaces := sets.NewString()
+	roles := &rbacv1.RoleList{}
+	if err := r.client.List(context.TODO(), roles, managedLabels); err != nil {
+		log.Error(err, "failed to list roles for ingresscontroller", "related", a.Meta.GetSelfLink())
+	}
+	for _, role := range roles.Items {
+		namespaces.Insert(role.Namespace)
+	}
+	if r.enabled {
+		routes, err := r.listRoutes()
+		if err != nil {
+			log.Error(err, "failed to list routes for ingresscontroller", "related", a.Meta.GetSelfLink())
+		}
+		for i := range routes {
+			if len(externalCertificateName(&routes[i])) != 0 {
+				namespaces.Insert(routes[i].GetNamespace())
+			}
+		}
+	}
+	var requests []reconcile.Request
+	for _, namespace := range namespaces.List() {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespace}})
+	}
+	return requests
+}
+
+// listRoutes returns the routes in the given namespaces, or in all namespaces
+// if none are given, from the cache.
+func (r *reconciler) listRoutes(opts ...client.ListOptionFunc) ([]unstructured.Unstructured, error) {
+	routes := &unstructured.UnstructuredList{}
+	routes.SetGroupVersionKind(routeGVK.GroupVersion().WithKind("RouteList"))
+	if err := r.cache.List(context.TODO(), routes, opts...); err != nil {
+		return nil, err
+	}
+	return routes.Items, nil
+}
+
+// Reconcile ensures that the requested namespace has a role and role binding
+// for each ingresscontroller that selects routes in the namespace that
+// reference external certificates, and no other roles or role bindings that
+// the controller manages.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	namespace := request.Name
+	ics := &operatorv1.IngressControllerList{}
+	var secrets map[string][]string
+	if r.enabled {
+		if err := r.client.List(context.TODO(), ics, client.InNamespace(r.operatorNamespace)); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to list ingresscontrollers: %v", err)
+		}
+		var err error
+		if secrets, err = r.externalCertificateSecrets(namespace, ics.Items); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	wantRoles := map[string]*rbacv1.Role{}
+	wantBindings := map[string]*rbacv1.RoleBinding{}
+	for i := range ics.Items {
+		ic := &ics.Items[i]
+		if names, ok := secrets[ic.Name]; ok {
+			role := desiredRole(ic, namespace, names)
+			wantRoles[role.Name] = role
+			binding := desiredRoleBinding(ic, namespace, r.operandNamespace)
+			wantBindings[binding.Name] = binding
+		}
+	}
+
+	var errs []error
+	for _, role := range wantRoles {
+		if err := r.ensureRole(role); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, binding := range wantBindings {
+		if err := r.ensureRoleBinding(binding); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	bindings := &rbacv1.RoleBindingList{}
+	if err := r.client.List(context.TODO(), bindings, client.InNamespace(namespace), managedLabels); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list role bindings in namespace %s: %v", namespace, err))
+	}
+	for i := range bindings.Items {
+		if _, ok := wantBindings[bindings.Items[i].Name]; !ok {
+			errs = append(errs, r.delete(&bindings.Items[i]))
+		}
+	}
+	roles := &rbacv1.RoleList{}
+	if err := r.client.List(context.TODO(), roles, client.InNamespace(namespace), managedLabels); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list roles in namespace %s: %v", namespace, err))
+	}
+	for i := range roles.Items {
+		if _, ok := wantRoles[roles.Items[i].Name]; !ok {
+			errs = append(errs, r.delete(&roles.Items[i]))
+		}
+	}
+	return reconcile.Result{}, utilerrors.NewAggregate(errs)
+}
+
+// externalCertificateSecrets returns the names of the secrets that the routes
+// in the given namespace reference as external certificates, keyed by the name
+// of each of the given ingresscontrollers that selects the routes.
+func (r *reconciler) externalCertificateSecrets(namespace string, ics []operatorv1.IngressController) (map[string][]string, error) {
+	routes, err := r.listRoutes(client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes in namespace %s: %v", namespace, err)
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.cache.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get namespace %s: %v", namespace, err)
+	}
+	return selectedExternalCertificates(ics, routes, labels.Set(ns.Labels))
+}
+
+// selectedExternalCertificates returns the sorted names of the secrets that the
+// given routes reference as external certificates, keyed by the name of each of
+// the given ingresscontrollers that selects the routes.  The routes must be in
+// one namespace, which has the given labels.  Ingresscontrollers that are being
+// deleted select no routes.
+func selectedExternalCertificates(ics []operatorv1.IngressController, routes []unstructured.Unstructured, namespaceLabels labels.Set) (map[string][]string, error) {
+	secrets := map[string]sets.String{}
+	for i := range ics {
+		ic := &ics[i]
+		if ic.DeletionTimestamp != nil {
+			continue
+		}
+		for j := range routes {
+			name := externalCertificateName(&routes[j])
+			if len(name) == 0 {
+				continue
+			}
+			selected, err := controller.RouteSelected(ic, labels.Set(routes[j].GetLabels()), namespaceLabels)
+			if err != nil {
+				return nil, err
+			}
+			if !selected {
+				continue
+			}
+			if _, ok := secrets[ic.Name]; !ok {
+				secrets[ic.Name] = sets.NewString()
+			}
+			secrets[ic.Name].Insert(name)
+		}
+	}
+	result := make(map[string][]string, len(secrets))
+	for ic, names := range secrets {
+		result[ic] = names.List()
+	}
+	return result, nil
+}
+
+// externalCertificateName returns the name of the secret that the given route
+// references as its external certificate, or the empty string if the route
+// does not reference one.
+func externalCertificateName(obj interface{}) string {
+	route, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return ""
+	}
+	name, _, _ := unstructured.NestedString(route.Object, "spec", "tls", "externalCertificate", "name")
+	return name
+}
+
+// managedObjectLabels returns the labels of the role and role binding for the
+// given ingresscontroller.
+func managedObjectLabels(ic *operatorv1.IngressController) map[string]string {
+	return map[string]string{
+		controller.RouterExternalCertificateRoleLabel: "true",
+		manifests.OwningIngressControllerLabel:        ic.Name,
+	}
+}
+
+// desiredRole returns the role that grants read access to the given secrets in
+// the given namespace.  Get, list, and watch are all restricted to the secrets'
+// names, so the router can only read those secrets.
+func desiredRole(ic *operatorv1.IngressController, namespace string, secrets []string) *rbacv1.Role {
+	name := controller.RouterExternalCertificateRoleName(ic, namespace)
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels:    managedObjectLabels(ic),
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: secrets,
+			Verbs:         []string{"get", "list", "watch"},
+		}},
+	}
+}
+
+// desiredRoleBinding returns the role binding that binds the role from
+// desiredRole to the given ingresscontroller's router service account.
+func desiredRoleBinding(ic *operatorv1.IngressController, namespace, operandNamespace string) *rbacv1.RoleBinding {
+	name := controller.RouterExternalCertificateRoleName(ic, namespace)
+	serviceAccount := controller.RouterServiceAccountName(ic, operandNamespace)
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels:    managedObjectLabels(ic),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name.Name,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: serviceAccount.Namespace,
+			Name:      serviceAccount.Name,
+		}},
+	}
+}
+
+// ensureRole creates or updates the given role.
+func (r *reconciler) ensureRole(desired *rbacv1.Role) error {
+	current := &rbacv1.Role{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get role %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create role %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created role", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Rules, desired.Rules) && reflect.DeepEqual(current.Labels, desired.Labels) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Rules = desired.Rules
+	updated.Labels = desired.Labels
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update role %s/%s: %v", desired.Namespace, desired.Name, err)
+	}
+	log.Info("updated role", "namespace", desired.Namespace, "name", desired.Name)
+	return nil
+}
+
+// ensureRoleBinding creates or updates the given role binding.  The role
+// reference of a role binding cannot be changed, but the controller always
+// binds the role with the binding's own name, so only the subjects and labels
+// are updated.
+func (r *reconciler) ensureRoleBinding(desired *rbacv1.RoleBinding) error {
+	current := &rbacv1.RoleBinding{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get role binding %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create role binding %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created role binding", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Subjects, desired.Subjects) && reflect.DeepEqual(current.Labels, desired.Labels) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Subjects = desired.Subjects
+	updated.Labels = desired.Labels
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update role binding %s/%s: %v", desired.Namespace, desired.Name, err)
+	}
+	log.Info("updated role binding", "namespace", desired.Namespace, "name", desired.Name)
+	return nil
+}
+
+// delete deletes the given role or role binding if it exists.
+func (r *reconciler) delete(obj interface {
+	metav1.Object
+	runtime.Object
+}) error {
+	if err := r.client.Delete(context.TODO(), obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+	}
+	log.Info("deleted stale external certificate RBAC", "namespace", obj.GetNamespace(), "name", obj.GetName())
+	return nil
+}
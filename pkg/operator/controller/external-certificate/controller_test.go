@@ -0,0 +1,81 @@
+package externalcertificate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSelectedExternalCertificates(t *testing.T) {
+	route := func(name, secret string, routeLabels map[string]string) unstructured.Unstructured {
+		u := unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetGroupVersionKind(routeGVK)
+		u.SetNamespace("app")
+		u.SetName(name)
+		u.SetLabels(routeLabels)
+		if len(secret) != 0 {
+			if err := unstructured.SetNestedField(u.Object, secret, "spec", "tls", "externalCertificate", "name"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return u
+	}
+	routes := []unstructured.Unstructured{
+		route("web", "web-cert", map[string]string{"shard": "a"}),
+		route("api", "api-cert", map[string]string{"shard": "b"}),
+		route("admin", "web-cert", map[string]string{"shard": "a"}),
+		route("plain", "", map[string]string{"shard": "a"}),
+	}
+	deleted := metav1.NewTime(time.Now())
+	ics := []operatorv1.IngressController{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "shard-a"},
+			Spec: operatorv1.IngressControllerSpec{
+				RouteSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"shard": "a"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+			Spec: operatorv1.IngressControllerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "deleted", DeletionTimestamp: &deleted}},
+	}
+	expected := map[string][]string{
+		"default": {"api-cert", "web-cert"},
+		"shard-a": {"web-cert"},
+	}
+	actual, err := selectedExternalCertificates(ics, routes, labels.Set{"env": "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestDesiredRoleAndBinding(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	role := desiredRole(ic, "app", []string{"api-cert", "web-cert"})
+	if role.Namespace != "app" {
+		t.Errorf("expected role in namespace app, got %q", role.Namespace)
+	}
+	if len(role.Rules) != 1 || !reflect.DeepEqual(role.Rules[0].ResourceNames, []string{"api-cert", "web-cert"}) {
+		t.Errorf("expected role to be restricted to the referenced secrets, got %v", role.Rules)
+	}
+	binding := desiredRoleBinding(ic, "app", "openshift-ingress")
+	if binding.RoleRef.Name != role.Name {
+		t.Errorf("expected binding to reference role %q, got %q", role.Name, binding.RoleRef.Name)
+	}
+	if len(binding.Subjects) != 1 || binding.Subjects[0].Namespace != "openshift-ingress" || binding.Subjects[0].Name != "router-default" {
+		t.Errorf("unexpected subjects %v", binding.Subjects)
+	}
+}
@@ -0,0 +1,771 @@
+// The gateway controller is responsible for implementing the Gateway API.  It
+// manages a GatewayClass for the operator and reconciles each Gateway of that
+// class into an ingresscontroller, so that the Gateway's data plane, load
+// balancer, and DNS records are managed by the operator's existing machinery.
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "gateway-controller"
+
+	// GatewayControllerName is the controller name that is specified on
+	// the GatewayClass that the operator manages.
+	GatewayControllerName = "openshift.io/gateway-controller"
+
+	// GatewayClassName is the name of the GatewayClass that the operator
+	// manages.  Gateways that specify this class are reconciled by the
+	// operator.
+	GatewayClassName = "openshift-default"
+
+	// GatewayNamespaceLabel and GatewayNameLabel are set on the
+	// ingresscontroller for a Gateway to associate the ingresscontroller
+	// with the Gateway.  Routes with the same labels are served by the
+	// Gateway's data plane.
+	GatewayNamespaceLabel = "ingress.operator.openshift.io/gateway-namespace"
+	GatewayNameLabel      = "ingress.operator.openshift.io/gateway-name"
+
+	// GatewayAllowedNamespacesAnnotation may be set on the operator's
+	// GatewayClass to a comma-separated list of the namespaces whose
+	// Gateways the operator accepts.  Each accepted Gateway gets a load
+	// balancer and a wildcard DNS record, so by default only Gateways in
+	// the operand namespace are accepted.
+	GatewayAllowedNamespacesAnnotation = "ingress.operator.openshift.io/gateway-allowed-namespaces"
+
+	// GatewayAllowedDomainsAnnotation may be set on the operator's
+	// GatewayClass to a comma-separated list of domains that Gateways may
+	// use in addition to the subdomains of the cluster ingress domain.  A
+	// Gateway's domain must be one of the domains or a subdomain of one.
+	GatewayAllowedDomainsAnnotation = "ingress.operator.openshift.io/gateway-allowed-domains"
+
+	// GatewayFinalizer is applied to Gateways so that the operator can
+	// delete the Gateway's ingresscontroller before the Gateway is deleted.
+	GatewayFinalizer = "ingress.operator.openshift.io/gateway"
+
+	// maxIngressControllerNameLength bounds the length of the names of
+	// the ingresscontrollers that the operator creates for Gateways so
+	// that the names of the resources that are derived from them are
+	// valid.
+	maxIngressControllerNameLength = 50
+)
+
+var (
+	log = logf.Logger.WithName(controllerName)
+
+	// GatewayClassGVK and GatewayGVK are the group, version, and kind of
+	// the Gateway API resources that the operator manages.
+	GatewayClassGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "GatewayClass"}
+	GatewayGVK      = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "Gateway"}
+)
+
+// New creates the gateway controller.  The controller watches Gateways, the
+// operator's GatewayClass, and the cluster ingress config through the given
+// cluster-scoped cache, and watches the ingresscontrollers that it creates for
+// Gateways in the operator namespace.
+func New(mgr manager.Manager, clusterCache cache.Cache, cl client.Client, operatorNamespace, operandNamespace string) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		client:            cl,
+		cache:             clusterCache,
+		operatorNamespace: operatorNamespace,
+		operandNamespace:  operandNamespace,
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+
+	// Queue the GatewayClass once when the controller starts so that it
+	// is created if it does not exist.
+	initial := make(chan event.GenericEvent, 1)
+	initial <- event.GenericEvent{Meta: &metav1.ObjectMeta{Name: GatewayClassName}}
+	if err := c.Watch(&source.Channel{Source: initial}, &handler.EnqueueRequestForObject{}); err != nil {
+		return nil, err
+	}
+
+	gatewayClass := &unstructured.Unstructured{}
+	gatewayClass.SetGroupVersionKind(GatewayClassGVK)
+	gatewayClassInformer, err := clusterCache.GetInformer(gatewayClass)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for gatewayclasses: %v", err)
+	}
+	isOwnClass := func(meta metav1.Object) bool { return meta.GetName() == GatewayClassName }
+	if err := c.Watch(&source.Informer{Informer: gatewayClassInformer}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isOwnClass(e.Meta) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isOwnClass(e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isOwnClass(e.MetaNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return isOwnClass(e.Meta) },
+	}); err != nil {
+		return nil, err
+	}
+
+	// The GatewayClass and the cluster ingress config determine which
+	// Gateways are accepted, so queue every Gateway when either changes.
+	allGateways := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.allGateways)}
+	if err := c.Watch(&source.Informer{Informer: gatewayClassInformer}, allGateways, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isOwnClass(e.Meta) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isOwnClass(e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isOwnClass(e.MetaNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return isOwnClass(e.Meta) },
+	}); err != nil {
+		return nil, err
+	}
+	ingressConfigInformer, err := clusterCache.GetInformer(&configv1.Ingress{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for ingress config: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: ingressConfigInformer}, allGateways); err != nil {
+		return nil, err
+	}
+
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(GatewayGVK)
+	gatewayInformer, err := clusterCache.GetInformer(gateway)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for gateways: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: gatewayInformer}, &handler.EnqueueRequestForObject{}); err != nil {
+		return nil, err
+	}
+
+	// Queue a Gateway when its ingresscontroller changes so that the
+	// Gateway's status reflects the state of its data plane.
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			labels := a.Meta.GetLabels()
+			namespace, name := labels[GatewayNamespaceLabel], labels[GatewayNameLabel]
+			if len(namespace) == 0 || len(name) == 0 {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+		}),
+	}); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// gatewayCondition is a status condition of a Gateway API resource.
+type gatewayCondition struct {
+	Type               string
+	Status             string
+	Reason             string
+	Message            string
+	ObservedGeneration int64
+}
+
+const (
+	conditionTrue  = "True"
+	conditionFalse = "False"
+)
+
+type reconciler struct {
+	client            client.Client
+	cache             cache.Cache
+	operatorNamespace string
+	operandNamespace  string
+}
+
+// allGateways returns a reconcile request for every Gateway of the operator's
+// GatewayClass.
+func (r *reconciler) allGateways(o handler.MapObject) []reconcile.Request {
+	requests := []reconcile.Request{}
+	gateways := &unstructured.UnstructuredList{}
+	gateways.SetGroupVersionKind(GatewayGVK.GroupVersion().WithKind(GatewayGVK.Kind + "List"))
+	if err := r.cache.List(context.TODO(), gateways); err != nil {
+		log.Error(err, "failed to list gateways", "related", o.Meta.GetSelfLink())
+		return requests
+	}
+	for _, gateway := range gateways.Items {
+		if className, _, _ := unstructured.NestedString(gateway.Object, "spec", "gatewayClassName"); className != GatewayClassName {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: gateway.GetNamespace(),
+				Name:      gateway.GetName(),
+			},
+		})
+	}
+	return requests
+}
+
+// Reconcile reconciles the operator's GatewayClass or a Gateway.  Gateways are
+// namespaced and the GatewayClass is cluster-scoped, so the request's
+// namespace tells them apart.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	if len(request.Namespace) == 0 {
+		if request.Name != GatewayClassName {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, r.ensureGatewayClass()
+	}
+	return reconcile.Result{}, r.reconcileGateway(request.NamespacedName)
+}
+
+// ensureGatewayClass creates the operator's GatewayClass if it does not exist
+// and reports that the operator has accepted it.
+func (r *reconciler) ensureGatewayClass() error {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(GatewayClassGVK)
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: GatewayClassName}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get gatewayclass %s: %v", GatewayClassName, err)
+		}
+		desired := desiredGatewayClass()
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to create gatewayclass %s: %v", GatewayClassName, err)
+		}
+		log.Info("created gatewayclass", "name", GatewayClassName)
+		// The watch on the gatewayclass queues it again to set its
+		// status.
+		return nil
+	}
+
+	// A user may have created a GatewayClass with the operator's class
+	// name for another controller; leave it alone.
+	if controllerName, _, _ := unstructured.NestedString(current.Object, "spec", "controllerName"); controllerName != GatewayControllerName {
+		log.Info("gatewayclass is not managed by the operator", "name", GatewayClassName, "controllerName", controllerName)
+		return nil
+	}
+
+	accepted := gatewayCondition{
+		Type:               "Accepted",
+		Status:             conditionTrue,
+		Reason:             "Accepted",
+		Message:            "The gatewayclass is managed by the ingress operator",
+		ObservedGeneration: current.GetGeneration(),
+	}
+	updated := current.DeepCopy()
+	if !setCondition(updated, accepted, time.Now()) {
+		return nil
+	}
+	if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update status of gatewayclass %s: %v", GatewayClassName, err)
+	}
+	return nil
+}
+
+// desiredGatewayClass returns the operator's GatewayClass.
+func desiredGatewayClass() *unstructured.Unstructured {
+	class := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"controllerName": GatewayControllerName,
+			},
+		},
+	}
+	class.SetGroupVersionKind(GatewayClassGVK)
+	class.SetName(GatewayClassName)
+	return class
+}
+
+// reconcileGateway creates, updates, or deletes the ingresscontroller for the
+// Gateway with the given name and updates the Gateway's status.
+func (r *reconciler) reconcileGateway(name types.NamespacedName) error {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(GatewayGVK)
+	if err := r.client.Get(context.TODO(), name, gateway); err != nil {
+		if errors.IsNotFound(err) {
+			return r.ensureIngressControllerDeleted(name)
+		}
+		return fmt.Errorf("failed to get gateway %s: %v", name, err)
+	}
+
+	className, _, _ := unstructured.NestedString(gateway.Object, "spec", "gatewayClassName")
+	if className != GatewayClassName || gateway.GetDeletionTimestamp() != nil {
+		if err := r.ensureIngressControllerDeleted(name); err != nil {
+			return err
+		}
+		return r.removeFinalizer(gateway)
+	}
+
+	if !slice.ContainsString(gateway.GetFinalizers(), GatewayFinalizer) {
+		gateway.SetFinalizers(append(gateway.GetFinalizers(), GatewayFinalizer))
+		if err := r.client.Update(context.TODO(), gateway); err != nil {
+			return fmt.Errorf("failed to add finalizer to gateway %s: %v", name, err)
+		}
+	}
+
+	accepted := gatewayCondition{
+		Type:               "Accepted",
+		Status:             conditionTrue,
+		Reason:             "Accepted",
+		ObservedGeneration: gateway.GetGeneration(),
+	}
+	programmed := gatewayCondition{
+		Type:               "Programmed",
+		Status:             conditionFalse,
+		Reason:             "Pending",
+		Message:            "Waiting for the gateway's data plane to become available",
+		ObservedGeneration: gateway.GetGeneration(),
+	}
+	var addresses []interface{}
+
+	policy, err := r.currentGatewayPolicy()
+	if err != nil {
+		return err
+	}
+	var desired *operatorv1.IngressController
+	reason := "NotAllowed"
+	if err = policy.admitNamespace(gateway.GetNamespace()); err == nil {
+		reason = "UnsupportedValue"
+		if desired, err = desiredIngressController(gateway, r.operatorNamespace); err == nil {
+			reason = "NotAllowed"
+			err = policy.admitDomain(desired.Spec.Domain)
+		}
+	}
+	if err != nil {
+		accepted.Status = conditionFalse
+		accepted.Reason = reason
+		accepted.Message = err.Error()
+		programmed.Reason = "Invalid"
+		programmed.Message = "The gateway was not accepted"
+		if err := r.ensureIngressControllerDeleted(name); err != nil {
+			return err
+		}
+	} else {
+		ic, err := r.ensureIngressController(desired)
+		if err != nil {
+			return err
+		}
+		if isIngressControllerAvailable(ic) {
+			programmed.Status = conditionTrue
+			programmed.Reason = "Programmed"
+			programmed.Message = ""
+		}
+		if addresses, err = r.gatewayAddresses(ic); err != nil {
+			return err
+		}
+	}
+
+	updated := gateway.DeepCopy()
+	now := time.Now()
+	changed := setCondition(updated, accepted, now)
+	changed = setCondition(updated, programmed, now) || changed
+	currentAddresses, _, _ := unstructured.NestedSlice(updated.Object, "status", "addresses")
+	if !addressesEqual(currentAddresses, addresses) {
+		if err := unstructured.SetNestedSlice(updated.Object, addresses, "status", "addresses"); err != nil {
+			return fmt.Errorf("failed to set addresses of gateway %s: %v", name, err)
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update status of gateway %s: %v", name, err)
+	}
+	return nil
+}
+
+// gatewayPolicy is the administrator's policy for which Gateways the operator
+// accepts.
+type gatewayPolicy struct {
+	// namespaces are the namespaces whose Gateways are accepted.
+	namespaces sets.String
+	// clusterDomain is the cluster ingress domain, whose subdomains
+	// Gateways may use.
+	clusterDomain string
+	// domains are the other domains that Gateways may use, along with
+	// their subdomains.
+	domains []string
+}
+
+// currentGatewayPolicy returns the policy for which Gateways the operator
+// accepts, which is read from the annotations of the operator's GatewayClass
+// and from the cluster ingress config.
+func (r *reconciler) currentGatewayPolicy() (gatewayPolicy, error) {
+	class := &unstructured.Unstructured{}
+	class.SetGroupVersionKind(GatewayClassGVK)
+	if err := r.cache.Get(context.TODO(), types.NamespacedName{Name: GatewayClassName}, class); err != nil && !errors.IsNotFound(err) {
+		return gatewayPolicy{}, fmt.Errorf("failed to get gatewayclass %s: %v", GatewayClassName, err)
+	}
+	ingressConfig := &configv1.Ingress{}
+	if err := r.cache.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, ingressConfig); err != nil {
+		return gatewayPolicy{}, fmt.Errorf("failed to get ingress 'cluster': %v", err)
+	}
+	return gatewayPolicyFor(class.GetAnnotations(), ingressConfig.Spec.Domain, r.operandNamespace), nil
+}
+
+// gatewayPolicyFor returns the policy for which Gateways the operator accepts
+// given the annotations of the operator's GatewayClass, the cluster ingress
+// domain, and the operand namespace.
+func gatewayPolicyFor(annotations map[string]string, clusterDomain, operandNamespace string) gatewayPolicy {
+	policy := gatewayPolicy{
+		namespaces:    sets.NewString(operandNamespace),
+		clusterDomain: clusterDomain,
+	}
+	if value, ok := annotations[GatewayAllowedNamespacesAnnotation]; ok {
+		policy.namespaces = sets.NewString(splitList(value)...)
+	}
+	policy.domains = splitList(annotations[GatewayAllowedDomainsAnnotation])
+	return policy
+}
+
+// splitList returns the non-empty elements of the given comma-separated list.
+func splitList(value string) []string {
+	var elements []string
+	for _, e := range strings.Split(value, ",") {
+		if e = strings.TrimSpace(e); len(e) != 0 {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+// admitNamespace returns an error if the policy does not allow Gateways in the
+// given namespace.
+func (p gatewayPolicy) admitNamespace(namespace string) error {
+	if p.namespaces.Has(namespace) {
+		return nil
+	}
+	return fmt.Errorf("gateways in namespace %s are not allowed; an administrator can allow them with the %s annotation on gatewayclass %s", namespace, GatewayAllowedNamespacesAnnotation, GatewayClassName)
+}
+
+// admitDomain returns an error if the policy does not allow Gateways to use the
+// given domain.  The cluster ingress domain itself is not allowed because the
+// default ingresscontroller serves it.
+func (p gatewayPolicy) admitDomain(domain string) error {
+	if len(p.clusterDomain) != 0 && strings.HasSuffix(domain, "."+p.clusterDomain) {
+		return nil
+	}
+	for _, allowed := range p.domains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("domain %q is not a subdomain of the cluster ingress domain %q; an administrator can allow other domains with the %s annotation on gatewayclass %s", domain, p.clusterDomain, GatewayAllowedDomainsAnnotation, GatewayClassName)
+}
+
+// desiredIngressController returns the ingresscontroller that implements the
+// given Gateway.  The ingresscontroller's domain is taken from the Gateway's
+// wildcard listener hostname, and the ingresscontroller serves only the Routes
+// that are labeled for the Gateway.
+func desiredIngressController(gateway *unstructured.Unstructured, operatorNamespace string) (*operatorv1.IngressController, error) {
+	domain, err := gatewayDomain(gateway)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{
+		GatewayNamespaceLabel: gateway.GetNamespace(),
+		GatewayNameLabel:      gateway.GetName(),
+	}
+	return &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorNamespace,
+			Name:      IngressControllerNameForGateway(gateway.GetNamespace(), gateway.GetName()),
+			Labels:    labels,
+			Annotations: map[string]string{
+				controller.DisableIngressProcessingAnnotation: "true",
+			},
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			Domain: domain,
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+			RouteSelector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}, nil
+}
+
+// gatewayDomain returns the domain of the given Gateway, which is taken from
+// the hostnames of its listeners.  Every listener must specify the same
+// wildcard hostname.
+func gatewayDomain(gateway *unstructured.Unstructured) (string, error) {
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	domain := ""
+	for _, l := range listeners {
+		listener, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		hostname, _ := listener["hostname"].(string)
+		if !strings.HasPrefix(hostname, "*.") {
+			return "", fmt.Errorf("listener hostname %q must be a wildcard hostname", hostname)
+		}
+		d := strings.TrimPrefix(hostname, "*.")
+		if len(domain) != 0 && d != domain {
+			return "", fmt.Errorf("listener hostnames must all be for the same domain, found %q and %q", domain, d)
+		}
+		domain = d
+	}
+	if len(domain) == 0 {
+		return "", fmt.Errorf("gateway must have at least one listener with a wildcard hostname")
+	}
+	return domain, nil
+}
+
+// IngressControllerNameForGateway returns the name of the ingresscontroller
+// for the Gateway with the given namespace and name.  Long names are replaced
+// with a hash so that the names of derived resources are valid.
+func IngressControllerNameForGateway(namespace, name string) string {
+	icName := fmt.Sprintf("gateway-%s-%s", namespace, name)
+	if len(icName) <= maxIngressControllerNameLength {
+		return icName
+	}
+	return fmt.Sprintf("gateway-%x", sha256.Sum256([]byte(namespace+"/"+name)))[:maxIngressControllerNameLength]
+}
+
+// ensureIngressController creates or updates the given ingresscontroller and
+// returns the current ingresscontroller.
+func (r *reconciler) ensureIngressController(desired *operatorv1.IngressController) (*operatorv1.IngressController, error) {
+	current := &operatorv1.IngressController{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get ingresscontroller %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create ingresscontroller %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created ingresscontroller", "namespace", desired.Namespace, "name", desired.Name)
+		return desired, nil
+	}
+	if changed, updated := ingressControllerChanged(current, desired); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return nil, fmt.Errorf("failed to update ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		log.Info("updated ingresscontroller", "namespace", updated.Namespace, "name", updated.Name)
+		return updated, nil
+	}
+	return current, nil
+}
+
+// ingressControllerChanged checks whether the current ingresscontroller
+// matches the expected ingresscontroller in the fields that the gateway
+// controller manages, and if not returns an updated ingresscontroller.
+func ingressControllerChanged(current, expected *operatorv1.IngressController) (bool, *operatorv1.IngressController) {
+	changed := false
+	updated := current.DeepCopy()
+	for _, m := range []struct{ current, expected *map[string]string }{
+		{&updated.Labels, &expected.Labels},
+		{&updated.Annotations, &expected.Annotations},
+	} {
+		for k, v := range *m.expected {
+			if (*m.current)[k] == v {
+				continue
+			}
+			if *m.current == nil {
+				*m.current = map[string]string{}
+			}
+			(*m.current)[k] = v
+			changed = true
+		}
+	}
+	if updated.Spec.Domain != expected.Spec.Domain {
+		updated.Spec.Domain = expected.Spec.Domain
+		changed = true
+	}
+	if !labelSelectorsEqual(updated.Spec.RouteSelector, expected.Spec.RouteSelector) {
+		updated.Spec.RouteSelector = expected.Spec.RouteSelector
+		changed = true
+	}
+	if updated.Spec.EndpointPublishingStrategy == nil || updated.Spec.EndpointPublishingStrategy.Type != expected.Spec.EndpointPublishingStrategy.Type {
+		updated.Spec.EndpointPublishingStrategy = expected.Spec.EndpointPublishingStrategy
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, updated
+}
+
+// ensureIngressControllerDeleted deletes the ingresscontroller for the Gateway
+// with the given name if it exists.
+func (r *reconciler) ensureIngressControllerDeleted(name types.NamespacedName) error {
+	ic := &operatorv1.IngressController{}
+	icName := types.NamespacedName{Namespace: r.operatorNamespace, Name: IngressControllerNameForGateway(name.Namespace, name.Name)}
+	if err := r.client.Get(context.TODO(), icName, ic); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get ingresscontroller %s: %v", icName, err)
+	}
+	if ic.Labels[GatewayNamespaceLabel] != name.Namespace || ic.Labels[GatewayNameLabel] != name.Name {
+		return nil
+	}
+	if ic.DeletionTimestamp == nil {
+		if err := r.client.Delete(context.TODO(), ic); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ingresscontroller %s: %v", icName, err)
+		}
+		log.Info("deleted ingresscontroller", "namespace", ic.Namespace, "name", ic.Name)
+	}
+	return nil
+}
+
+// removeFinalizer removes the gateway finalizer from the given Gateway.
+func (r *reconciler) removeFinalizer(gateway *unstructured.Unstructured) error {
+	if !slice.ContainsString(gateway.GetFinalizers(), GatewayFinalizer) {
+		return nil
+	}
+	updated := gateway.DeepCopy()
+	updated.SetFinalizers(slice.RemoveString(updated.GetFinalizers(), GatewayFinalizer))
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to remove finalizer from gateway %s/%s: %v", gateway.GetNamespace(), gateway.GetName(), err)
+	}
+	return nil
+}
+
+// gatewayAddresses returns the addresses of the load balancer of the given
+// ingresscontroller in the form of Gateway status addresses.
+func (r *reconciler) gatewayAddresses(ic *operatorv1.IngressController) ([]interface{}, error) {
+	var services []*corev1.Service
+	for _, name := range []types.NamespacedName{
+		controller.LoadBalancerServiceName(ic, r.operandNamespace),
+		controller.AlternateLoadBalancerServiceName(ic, r.operandNamespace),
+	} {
+		service := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), name, service); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get load balancer service for ingresscontroller %s: %v", ic.Name, err)
+			}
+			service = nil
+		}
+		services = append(services, service)
+	}
+	service, _ := controller.CurrentLoadBalancerService(services[0], services[1])
+	if service == nil {
+		return nil, nil
+	}
+	var addresses []interface{}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		switch {
+		case len(ingress.Hostname) != 0:
+			addresses = append(addresses, map[string]interface{}{"type": "Hostname", "value": ingress.Hostname})
+		case len(ingress.IP) != 0:
+			addresses = append(addresses, map[string]interface{}{"type": "IPAddress", "value": ingress.IP})
+		}
+	}
+	return addresses, nil
+}
+
+// isIngressControllerAvailable returns true if the given ingresscontroller
+// reports that it is available.
+func isIngressControllerAvailable(ic *operatorv1.IngressController) bool {
+	for _, c := range ic.Status.Conditions {
+		if c.Type == operatorv1.IngressControllerAvailableConditionType {
+			return c.Status == operatorv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// setCondition sets the given condition in the status of the given Gateway
+// API object, preserving the last transition time if the condition's status
+// has not changed, and returns true if the status changed.
+func setCondition(obj *unstructured.Unstructured, condition gatewayCondition, now time.Time) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	lastTransitionTime := now.UTC().Format(time.RFC3339)
+	var updated []interface{}
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != condition.Type {
+			updated = append(updated, c)
+			continue
+		}
+		if m["status"] == condition.Status {
+			if m["reason"] == condition.Reason && m["message"] == condition.Message && m["observedGeneration"] == condition.ObservedGeneration {
+				return false
+			}
+			if t, ok := m["lastTransitionTime"].(string); ok {
+				lastTransitionTime = t
+			}
+		}
+	}
+	updated = append(updated, map[string]interface{}{
+		"type":               condition.Type,
+		"status":             condition.Status,
+		"reason":             condition.Reason,
+		"message":            condition.Message,
+		"observedGeneration": condition.ObservedGeneration,
+		"lastTransitionTime": lastTransitionTime,
+	})
+	if err := unstructured.SetNestedSlice(obj.Object, updated, "status", "conditions"); err != nil {
+		log.Error(err, "failed to set condition", "type", condition.Type)
+		return false
+	}
+	return true
+}
+
+// addressesEqual compares two lists of Gateway status addresses.
+func addressesEqual(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		am, _ := a[i].(map[string]interface{})
+		bm, _ := b[i].(map[string]interface{})
+		if am["type"] != bm["type"] || am["value"] != bm["value"] {
+			return false
+		}
+	}
+	return true
+}
+
+// labelSelectorsEqual compares two label selectors.
+func labelSelectorsEqual(a, b *metav1.LabelSelector) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	as, err := metav1.LabelSelectorAsSelector(a)
+	if err != nil {
+		return false
+	}
+	bs, err := metav1.LabelSelectorAsSelector(b)
+	if err != nil {
+		return false
+	}
+	return as.String() == bs.String()
+}
+
+// IsGatewayAPIInstalled returns true if the given REST mapper knows the
+// Gateway API resources.
+func IsGatewayAPIInstalled(mapper meta.RESTMapper) (bool, error) {
+	for _, gvk := range []schema.GroupVersionKind{GatewayClassGVK, GatewayGVK} {
+		if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
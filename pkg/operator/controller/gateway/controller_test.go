@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newGateway(hostnames ...string) *unstructured.Unstructured {
+	var listeners []interface{}
+	for _, hostname := range hostnames {
+		listeners = append(listeners, map[string]interface{}{"hostname": hostname})
+	}
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"gatewayClassName": GatewayClassName,
+			"listeners":        listeners,
+		},
+	}}
+	gateway.SetGroupVersionKind(GatewayGVK)
+	gateway.SetNamespace("app")
+	gateway.SetName("gw")
+	return gateway
+}
+
+func TestGatewayDomain(t *testing.T) {
+	testCases := []struct {
+		description string
+		hostnames   []string
+		expect      string
+		expectErr   bool
+	}{
+		{"one wildcard listener", []string{"*.gw.example.com"}, "gw.example.com", false},
+		{"two listeners for the same domain", []string{"*.gw.example.com", "*.gw.example.com"}, "gw.example.com", false},
+		{"listeners for different domains", []string{"*.a.example.com", "*.b.example.com"}, "", true},
+		{"non-wildcard listener", []string{"www.example.com"}, "", true},
+		{"no listeners", nil, "", true},
+	}
+	for _, tc := range testCases {
+		domain, err := gatewayDomain(newGateway(tc.hostnames...))
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+			continue
+		}
+		if domain != tc.expect {
+			t.Errorf("%q: expected domain %q, got %q", tc.description, tc.expect, domain)
+		}
+	}
+}
+
+func TestIngressControllerNameForGateway(t *testing.T) {
+	if name := IngressControllerNameForGateway("app", "gw"); name != "gateway-app-gw" {
+		t.Errorf("expected gateway-app-gw, got %s", name)
+	}
+	long := IngressControllerNameForGateway("a-very-long-namespace-name-for-testing", "a-very-long-gateway-name")
+	if len(long) > maxIngressControllerNameLength {
+		t.Errorf("expected name of at most %d characters, got %q", maxIngressControllerNameLength, long)
+	}
+	if other := IngressControllerNameForGateway("a-very-long-namespace-name-for-testing", "another-very-long-gateway-name"); other == long {
+		t.Errorf("expected distinct names for distinct gateways, got %q", other)
+	}
+}
+
+func TestDesiredIngressController(t *testing.T) {
+	gateway := newGateway("*.gw.example.com")
+	ic, err := desiredIngressController(gateway, "openshift-ingress-operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ic.Spec.Domain != "gw.example.com" {
+		t.Errorf("expected domain gw.example.com, got %s", ic.Spec.Domain)
+	}
+	if ic.Labels[GatewayNamespaceLabel] != "app" || ic.Labels[GatewayNameLabel] != "gw" {
+		t.Errorf("unexpected labels %v", ic.Labels)
+	}
+	if changed, _ := ingressControllerChanged(ic, ic); changed {
+		t.Error("expected ingressControllerChanged to return false for identical ingresscontrollers")
+	}
+
+	mutated := ic.DeepCopy()
+	mutated.Spec.Domain = "other.example.com"
+	mutated.Spec.RouteSelector = nil
+	delete(mutated.Labels, GatewayNameLabel)
+	changed, updated := ingressControllerChanged(mutated, ic)
+	if !changed {
+		t.Fatal("expected ingressControllerChanged to return true")
+	}
+	if changedAgain, _ := ingressControllerChanged(updated, ic); changedAgain {
+		t.Error("ingressControllerChanged does not behave as a fixed point function")
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	gateway := newGateway("*.gw.example.com")
+	then := time.Unix(0, 0)
+	now := time.Unix(60, 0)
+	programmed := gatewayCondition{Type: "Programmed", Status: conditionTrue, Reason: "Programmed", ObservedGeneration: 1}
+
+	if !setCondition(gateway, programmed, then) {
+		t.Fatal("expected setting a new condition to change the status")
+	}
+	if setCondition(gateway, programmed, now) {
+		t.Error("expected setting an unchanged condition not to change the status")
+	}
+
+	programmed.ObservedGeneration = 2
+	if !setCondition(gateway, programmed, now) {
+		t.Fatal("expected a new observed generation to change the status")
+	}
+	conditions, _, _ := unstructured.NestedSlice(gateway.Object, "status", "conditions")
+	if len(conditions) != 1 {
+		t.Fatalf("expected 1 condition, got %v", conditions)
+	}
+	if ltt := conditions[0].(map[string]interface{})["lastTransitionTime"]; ltt != then.UTC().Format(time.RFC3339) {
+		t.Errorf("expected last transition time to be preserved, got %v", ltt)
+	}
+}
+
+func TestGatewayPolicy(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		namespace   string
+		domain      string
+		expectAdmit bool
+	}{
+		{"operand namespace and cluster subdomain", nil, "openshift-ingress", "gw.apps.example.com", true},
+		{"other namespace by default", nil, "app", "gw.apps.example.com", false},
+		{"allowed namespace", map[string]string{GatewayAllowedNamespacesAnnotation: "app, other"}, "app", "gw.apps.example.com", true},
+		{"operand namespace not in the allowed namespaces", map[string]string{GatewayAllowedNamespacesAnnotation: "app"}, "openshift-ingress", "gw.apps.example.com", false},
+		{"cluster ingress domain itself", nil, "openshift-ingress", "apps.example.com", false},
+		{"domain outside of the cluster ingress domain", nil, "openshift-ingress", "gw.example.org", false},
+		{"allowed domain", map[string]string{GatewayAllowedDomainsAnnotation: "example.org"}, "openshift-ingress", "example.org", true},
+		{"subdomain of an allowed domain", map[string]string{GatewayAllowedDomainsAnnotation: "example.net,example.org"}, "openshift-ingress", "gw.example.org", true},
+		{"suffix of an allowed domain that is not a subdomain", map[string]string{GatewayAllowedDomainsAnnotation: "example.org"}, "openshift-ingress", "badexample.org", false},
+	}
+	for _, tc := range testCases {
+		policy := gatewayPolicyFor(tc.annotations, "apps.example.com", "openshift-ingress")
+		err := policy.admitNamespace(tc.namespace)
+		if err == nil {
+			err = policy.admitDomain(tc.domain)
+		}
+		if admitted := err == nil; admitted != tc.expectAdmit {
+			t.Errorf("%q: expected admitted to be %t, got %t (error: %v)", tc.description, tc.expectAdmit, admitted, err)
+		}
+	}
+}
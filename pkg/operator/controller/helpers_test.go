@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ingressControllerWithAnnotation returns the default ingresscontroller with
+// the given annotation, or with no annotations if value is empty.
+func ingressControllerWithAnnotation(key, value string) *operatorv1.IngressController {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: DefaultIngressControllerName}}
+	if len(value) != 0 {
+		ic.Annotations = map[string]string{key: value}
+	}
+	return ic
+}
+
+// checkError reports an error for the described test case if err is nil but
+// an error is expected or err is not nil but no error is expected.  It returns
+// true if no error is expected and none occurred, which is when the caller
+// should go on to check the test case's result.
+func checkError(t *testing.T, description string, expectErr bool, err error) bool {
+	t.Helper()
+	switch {
+	case expectErr && err == nil:
+		t.Errorf("%q: expected error", description)
+	case !expectErr && err != nil:
+		t.Errorf("%q: unexpected error: %v", description, err)
+	}
+	return !expectErr && err == nil
+}
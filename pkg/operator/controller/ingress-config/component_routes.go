@@ -0,0 +1,229 @@
+package ingressconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// componentRouteLabel identifies the roles and rolebindings that the
+	// operator creates so that consumers of a component route can read the
+	// route's serving certificate secret.
+	componentRouteLabel = "ingress.operator.openshift.io/component-route"
+)
+
+// componentRouteRBACName returns the name of the role and rolebinding that
+// grant the consumers of the given component route access to its serving
+// certificate secret.
+func componentRouteRBACName(route ComponentRouteStatus) string {
+	return fmt.Sprintf("componentroute-%s-%s", route.Namespace, route.Name)
+}
+
+// validateServingCertSecret checks that the given secret is a TLS secret with
+// a valid certificate and key for the given hostname.
+func validateServingCertSecret(secret *corev1.Secret, hostname string, now time.Time) error {
+	if secret.Type != corev1.SecretTypeTLS {
+		return fmt.Errorf("secret %s/%s has type %q, expected %q", secret.Namespace, secret.Name, secret.Type, corev1.SecretTypeTLS)
+	}
+	crt, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(crt) == 0 || len(key) == 0 {
+		return fmt.Errorf("secret %s/%s must have %q and %q keys", secret.Namespace, secret.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+	pair, err := tls.X509KeyPair(crt, key)
+	if err != nil {
+		return fmt.Errorf("secret %s/%s has an invalid certificate and key: %v", secret.Namespace, secret.Name, err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("secret %s/%s has an invalid certificate: %v", secret.Namespace, secret.Name, err)
+	}
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate in secret %s/%s is not valid at %s", secret.Namespace, secret.Name, now.Format(time.RFC3339))
+	}
+	if err := leaf.VerifyHostname(hostname); err != nil {
+		return fmt.Errorf("certificate in secret %s/%s is not valid for hostname %q: %v", secret.Namespace, secret.Name, hostname, err)
+	}
+	return nil
+}
+
+// validateComponentRoute checks the cluster administrator's customization of
+// the given component route.
+func (r *reconciler) validateComponentRoute(spec *ComponentRouteSpec) error {
+	if spec == nil || len(spec.ServingCertKeyPairSecret.Name) == 0 {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	name := types.NamespacedName{Namespace: controller.GlobalUserSpecifiedConfigNamespace, Name: spec.ServingCertKeyPairSecret.Name}
+	if err := r.client.Get(context.TODO(), name, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("secret %s does not exist", name)
+		}
+		return fmt.Errorf("failed to get secret %s: %v", name, err)
+	}
+	return validateServingCertSecret(secret, spec.Hostname, time.Now())
+}
+
+// desiredComponentRouteRBAC returns the role and rolebinding that allow the
+// consuming users of the given component route to read its serving certificate
+// secret, or nil if none are needed.
+func desiredComponentRouteRBAC(route ComponentRouteStatus, spec *ComponentRouteSpec) (*rbacv1.Role, *rbacv1.RoleBinding) {
+	if spec == nil || len(spec.ServingCertKeyPairSecret.Name) == 0 || len(route.ConsumingUsers) == 0 {
+		return nil, nil
+	}
+	name := componentRouteRBACName(route)
+	labels := map[string]string{componentRouteLabel: "true"}
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: controller.GlobalUserSpecifiedConfigNamespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{spec.ServingCertKeyPairSecret.Name},
+			Verbs:         []string{"get", "list", "watch"},
+		}},
+	}
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: controller.GlobalUserSpecifiedConfigNamespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+	}
+	for _, user := range route.ConsumingUsers {
+		subject := rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: user}
+		if parts := strings.Split(user, ":"); len(parts) == 4 && parts[0] == "system" && parts[1] == "serviceaccount" {
+			subject = rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: parts[2], Name: parts[3]}
+		}
+		binding.Subjects = append(binding.Subjects, subject)
+	}
+	return role, binding
+}
+
+// ensureComponentRouteRBAC creates or updates the roles and rolebindings for
+// the given component routes and deletes any that are no longer needed.
+func (r *reconciler) ensureComponentRouteRBAC(config *ingressConfig) error {
+	var errs []error
+	desired := map[string]struct{}{}
+	for _, route := range config.Status.ComponentRoutes {
+		spec := findComponentRouteSpec(config.Spec.ComponentRoutes, route.Namespace, route.Name)
+		role, binding := desiredComponentRouteRBAC(route, spec)
+		if role == nil {
+			continue
+		}
+		desired[role.Name] = struct{}{}
+		if err := r.ensureRole(role); err != nil {
+			errs = append(errs, err)
+		}
+		if err := r.ensureRoleBinding(binding); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	roles := &rbacv1.RoleList{}
+	if err := r.client.List(context.TODO(), roles, client.InNamespace(controller.GlobalUserSpecifiedConfigNamespace), client.MatchingLabels(map[string]string{componentRouteLabel: "true"})); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list component route roles: %v", err))
+	} else {
+		for i := range roles.Items {
+			if _, ok := desired[roles.Items[i].Name]; ok {
+				continue
+			}
+			if err := r.client.Delete(context.TODO(), &roles.Items[i]); err != nil && !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete role %s/%s: %v", roles.Items[i].Namespace, roles.Items[i].Name, err))
+			} else {
+				log.Info("deleted component route role", "namespace", roles.Items[i].Namespace, "name", roles.Items[i].Name)
+			}
+		}
+	}
+	bindings := &rbacv1.RoleBindingList{}
+	if err := r.client.List(context.TODO(), bindings, client.InNamespace(controller.GlobalUserSpecifiedConfigNamespace), client.MatchingLabels(map[string]string{componentRouteLabel: "true"})); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list component route rolebindings: %v", err))
+	} else {
+		for i := range bindings.Items {
+			if _, ok := desired[bindings.Items[i].Name]; ok {
+				continue
+			}
+			if err := r.client.Delete(context.TODO(), &bindings.Items[i]); err != nil && !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete rolebinding %s/%s: %v", bindings.Items[i].Namespace, bindings.Items[i].Name, err))
+			} else {
+				log.Info("deleted component route rolebinding", "namespace", bindings.Items[i].Namespace, "name", bindings.Items[i].Name)
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// ensureRole creates the given role or updates its rules.
+func (r *reconciler) ensureRole(desired *rbacv1.Role) error {
+	current := &rbacv1.Role{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get role %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create role %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created role", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Rules, desired.Rules) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Rules = desired.Rules
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update role %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated role", "namespace", updated.Namespace, "name", updated.Name)
+	return nil
+}
+
+// ensureRoleBinding creates the given rolebinding or updates its subjects.
+func (r *reconciler) ensureRoleBinding(desired *rbacv1.RoleBinding) error {
+	current := &rbacv1.RoleBinding{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get rolebinding %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create rolebinding %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created rolebinding", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Subjects, desired.Subjects) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Subjects = desired.Subjects
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update rolebinding %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated rolebinding", "namespace", updated.Namespace, "name", updated.Name)
+	return nil
+}
@@ -0,0 +1,120 @@
+package ingressconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// newServingCertSecret returns a TLS secret with a self-signed certificate for
+// the given hostname that is valid from notBefore to notAfter.
+func newServingCertSecret(t *testing.T, hostname string, notBefore, notAfter time.Time) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hostname},
+		DNSNames:     []string{hostname},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	}
+}
+
+func TestValidateServingCertSecret(t *testing.T) {
+	now := time.Now()
+	valid := newServingCertSecret(t, "console.example.com", now.Add(-time.Hour), now.Add(time.Hour))
+	expired := newServingCertSecret(t, "console.example.com", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	opaque := valid.DeepCopy()
+	opaque.Type = corev1.SecretTypeOpaque
+	missingKey := valid.DeepCopy()
+	delete(missingKey.Data, corev1.TLSPrivateKeyKey)
+
+	testCases := []struct {
+		description string
+		secret      *corev1.Secret
+		hostname    string
+		expectErr   bool
+	}{
+		{"valid certificate", valid, "console.example.com", false},
+		{"wrong hostname", valid, "oauth.example.com", true},
+		{"expired certificate", expired, "console.example.com", true},
+		{"wrong secret type", opaque, "console.example.com", true},
+		{"missing key", missingKey, "console.example.com", true},
+	}
+
+	for _, tc := range testCases {
+		err := validateServingCertSecret(tc.secret, tc.hostname, now)
+		if tc.expectErr != (err != nil) {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestDesiredComponentRouteRBAC(t *testing.T) {
+	route := ComponentRouteStatus{
+		Namespace: "openshift-console",
+		Name:      "console",
+		ConsumingUsers: []string{
+			"system:serviceaccount:openshift-console-operator:console-operator",
+			"console-admin",
+		},
+	}
+	spec := &ComponentRouteSpec{
+		Namespace:                route.Namespace,
+		Name:                     route.Name,
+		Hostname:                 "console.example.com",
+		ServingCertKeyPairSecret: configv1.SecretNameReference{Name: "console-cert"},
+	}
+
+	if role, binding := desiredComponentRouteRBAC(route, nil); role != nil || binding != nil {
+		t.Errorf("expected no RBAC for a route that is not customized")
+	}
+
+	role, binding := desiredComponentRouteRBAC(route, spec)
+	if role == nil || binding == nil {
+		t.Fatalf("expected RBAC for a customized route")
+	}
+	if role.Namespace != "openshift-config" || len(role.Rules) != 1 || role.Rules[0].ResourceNames[0] != "console-cert" {
+		t.Errorf("unexpected role: %#v", role)
+	}
+	expectedSubjects := []rbacv1.Subject{
+		{Kind: rbacv1.ServiceAccountKind, Namespace: "openshift-console-operator", Name: "console-operator"},
+		{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "console-admin"},
+	}
+	if len(binding.Subjects) != len(expectedSubjects) {
+		t.Fatalf("expected subjects %v, got %v", expectedSubjects, binding.Subjects)
+	}
+	for i := range expectedSubjects {
+		if binding.Subjects[i] != expectedSubjects[i] {
+			t.Errorf("expected subject %v, got %v", expectedSubjects[i], binding.Subjects[i])
+		}
+	}
+}
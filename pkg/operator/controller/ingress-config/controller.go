@@ -0,0 +1,175 @@
+// The ingress-config controller is responsible for reporting the state of
+// ingress to the status of the cluster ingress config,
+// ingresses.config.openshift.io/cluster, which other operators use to find the
+// default domain and the state of their components' routes, and for
+// orchestrating the custom hostnames and serving certificates that the cluster
+// administrator specifies for component routes.
+package ingressconfig
+
+import (
+	"context"
+	"fmt"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ingress-config-controller"
+
+	// ingressConfigName is the name of the cluster ingress config.
+	ingressConfigName = "cluster"
+)
+
+var log = logf.Logger.WithName(controllerName)
+
+type reconciler struct {
+	client            client.Client
+	operatorNamespace string
+}
+
+// New returns a new controller that reports the state of ingress to the status
+// of the cluster ingress config and manages access to the serving certificates
+// of component routes.  The config cache must be a cluster-scoped cache, and
+// the user config cache must be a cache for the openshift-config namespace.
+func New(mgr manager.Manager, configCache, userConfigCache cache.Cache, kubeConfig *rest.Config, operatorNamespace string) (runtimecontroller.Controller, error) {
+	kubeClient, err := operatorclient.NewClient(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kube client: %v", err)
+	}
+	reconciler := &reconciler{
+		client:            kubeClient,
+		operatorNamespace: operatorNamespace,
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+
+	toIngressConfig := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(_ handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: ingressConfigName}}}
+		}),
+	}
+
+	ingressConfigInformer, err := configCache.GetInformer(&configv1.Ingress{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for ingress config: %v", err)
+	}
+	isIngressConfig := func(meta metav1.Object) bool { return meta.GetName() == ingressConfigName }
+	if err := c.Watch(&source.Informer{Informer: ingressConfigInformer}, toIngressConfig, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isIngressConfig(e.Meta) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isIngressConfig(e.MetaNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return isIngressConfig(e.Meta) },
+	}); err != nil {
+		return nil, err
+	}
+
+	// Component routes' serving certificate secrets are in the
+	// openshift-config namespace.  Revalidate component routes when any
+	// secret there changes.
+	secretsInformer, err := userConfigCache.GetInformer(&corev1.Secret{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for secrets: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: secretsInformer}, toIngressConfig); err != nil {
+		return nil, err
+	}
+
+	// The default ingresscontroller's domain is the effective default
+	// domain if the ingress config does not specify one.
+	isDefault := func(meta metav1.Object) bool { return meta.GetName() == controller.DefaultIngressControllerName }
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, toIngressConfig, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isDefault(e.Meta) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isDefault(e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isDefault(e.MetaNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return isDefault(e.Meta) },
+	}); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Reconcile updates the status of the cluster ingress config.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(ingressConfigGVK)
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: ingressConfigName}, current); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get ingress config %q: %v", ingressConfigName, err)
+	}
+	config, err := fromUnstructured(current)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var defaultIC *operatorv1.IngressController
+	ic := &operatorv1.IngressController{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: r.operatorNamespace, Name: controller.DefaultIngressControllerName}, ic); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("failed to get default ingresscontroller: %v", err)
+		}
+	} else {
+		defaultIC = ic
+	}
+
+	var errs []error
+	validationErrs := map[types.NamespacedName]error{}
+	for _, route := range config.Status.ComponentRoutes {
+		spec := findComponentRouteSpec(config.Spec.ComponentRoutes, route.Namespace, route.Name)
+		if err := r.validateComponentRoute(spec); err != nil {
+			validationErrs[types.NamespacedName{Namespace: route.Namespace, Name: route.Name}] = err
+		}
+	}
+	if err := r.ensureComponentRouteRBAC(config); err != nil {
+		errs = append(errs, err)
+	}
+
+	desired := computeIngressConfigStatus(config, defaultIC, validationErrs, metav1.Now())
+	if !ingressConfigStatusEqual(&config.Status, desired) {
+		updated := current.DeepCopy()
+		if err := setStatus(updated, desired); err != nil {
+			return reconcile.Result{}, err
+		}
+		// Component operators also write to status.componentRoutes, so
+		// use an update rather than a patch so that a concurrent write
+		// causes a conflict rather than being lost.
+		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update status of ingress config %q: %v", ingressConfigName, err))
+		} else {
+			log.Info("updated ingress config status", "name", ingressConfigName, "domain", desired.Domain)
+		}
+	}
+
+	return reconcile.Result{}, utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,128 @@
+package ingressconfig
+
+import (
+	"reflect"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// computeIngressConfigStatus returns the desired status for the given ingress
+// config.  The default ingresscontroller may be nil if it does not exist.
+// validationErrs has the errors from validating each component route's
+// customization, keyed by the route's namespace and name.
+func computeIngressConfigStatus(config *ingressConfig, defaultIC *operatorv1.IngressController, validationErrs map[types.NamespacedName]error, now metav1.Time) *ingressConfigStatus {
+	status := &ingressConfigStatus{
+		Domain: config.Spec.Domain,
+	}
+	if len(status.Domain) == 0 && defaultIC != nil {
+		status.Domain = defaultIC.Status.Domain
+	}
+	caConfigMap := controller.RouterCAConfigMapName()
+	status.DefaultCertificateAuthority = &configv1.ObjectReference{
+		Resource:  "configmaps",
+		Namespace: caConfigMap.Namespace,
+		Name:      caConfigMap.Name,
+	}
+
+	for _, route := range config.Status.ComponentRoutes {
+		spec := findComponentRouteSpec(config.Spec.ComponentRoutes, route.Namespace, route.Name)
+		validationErr := validationErrs[types.NamespacedName{Namespace: route.Namespace, Name: route.Name}]
+		status.ComponentRoutes = append(status.ComponentRoutes, computeComponentRouteStatus(route, spec, validationErr, now))
+	}
+
+	return status
+}
+
+// findComponentRouteSpec returns the spec for the component route with the
+// given namespace and name, or nil if the cluster administrator has not
+// customized the route.
+func findComponentRouteSpec(routes []ComponentRouteSpec, namespace, name string) *ComponentRouteSpec {
+	for i := range routes {
+		if routes[i].Namespace == namespace && routes[i].Name == name {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+// computeComponentRouteStatus returns the desired status for a component
+// route.  The route's DefaultHostname and ConsumingUsers are owned by the
+// component operator and are preserved as they are.  If validationErr is
+// non-nil, the route's customization is invalid and the route is reported as
+// degraded.
+func computeComponentRouteStatus(current ComponentRouteStatus, spec *ComponentRouteSpec, validationErr error, now metav1.Time) ComponentRouteStatus {
+	desired := ComponentRouteStatus{
+		Namespace:       current.Namespace,
+		Name:            current.Name,
+		DefaultHostname: current.DefaultHostname,
+		ConsumingUsers:  current.ConsumingUsers,
+	}
+
+	if spec == nil {
+		desired.CurrentHostnames = []string{current.DefaultHostname}
+		caConfigMap := controller.RouterCAConfigMapName()
+		desired.RelatedObjects = []configv1.ObjectReference{{
+			Resource:  "configmaps",
+			Namespace: caConfigMap.Namespace,
+			Name:      caConfigMap.Name,
+		}}
+	} else {
+		desired.CurrentHostnames = []string{spec.Hostname}
+		if len(spec.ServingCertKeyPairSecret.Name) != 0 {
+			desired.RelatedObjects = []configv1.ObjectReference{{
+				Resource:  "secrets",
+				Namespace: controller.GlobalUserSpecifiedConfigNamespace,
+				Name:      spec.ServingCertKeyPairSecret.Name,
+			}}
+		}
+	}
+
+	degraded := ComponentRouteCondition{
+		Type:   ComponentRouteDegraded,
+		Status: configv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if validationErr != nil {
+		degraded.Status = configv1.ConditionTrue
+		degraded.Reason = "InvalidServingCertKeyPairSecret"
+		degraded.Message = validationErr.Error()
+	}
+	conditions := []ComponentRouteCondition{
+		degraded,
+		{
+			Type:   ComponentRouteProgressing,
+			Status: configv1.ConditionFalse,
+			Reason: "AsExpected",
+		},
+	}
+	for _, condition := range conditions {
+		desired.Conditions = setComponentRouteCondition(desired.Conditions, current.Conditions, condition, now)
+	}
+
+	return desired
+}
+
+// setComponentRouteCondition appends the given condition to conditions,
+// keeping the last transition time from the matching condition in old if the
+// status has not changed.
+func setComponentRouteCondition(conditions, old []ComponentRouteCondition, condition ComponentRouteCondition, now metav1.Time) []ComponentRouteCondition {
+	condition.LastTransitionTime = now
+	for _, c := range old {
+		if c.Type == condition.Type && c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+			break
+		}
+	}
+	return append(conditions, condition)
+}
+
+// ingressConfigStatusEqual compares two ingress config statuses.
+func ingressConfigStatusEqual(a, b *ingressConfigStatus) bool {
+	return reflect.DeepEqual(a, b)
+}
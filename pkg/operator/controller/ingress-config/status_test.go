@@ -0,0 +1,132 @@
+package ingressconfig
+
+import (
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestComputeIngressConfigStatus(t *testing.T) {
+	defaultIC := &operatorv1.IngressController{
+		Status: operatorv1.IngressControllerStatus{Domain: "apps.ic.example.com"},
+	}
+	testCases := []struct {
+		description  string
+		config       *ingressConfig
+		defaultIC    *operatorv1.IngressController
+		expectDomain string
+	}{
+		{
+			description:  "domain from ingress config",
+			config:       &ingressConfig{Spec: ingressConfigSpec{Domain: "apps.example.com"}},
+			defaultIC:    defaultIC,
+			expectDomain: "apps.example.com",
+		},
+		{
+			description:  "domain from default ingresscontroller",
+			config:       &ingressConfig{},
+			defaultIC:    defaultIC,
+			expectDomain: "apps.ic.example.com",
+		},
+		{
+			description:  "no domain",
+			config:       &ingressConfig{},
+			expectDomain: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		status := computeIngressConfigStatus(tc.config, tc.defaultIC, nil, metav1.Now())
+		if status.Domain != tc.expectDomain {
+			t.Errorf("%q: expected domain %q, got %q", tc.description, tc.expectDomain, status.Domain)
+		}
+		if status.DefaultCertificateAuthority == nil || status.DefaultCertificateAuthority.Name != "router-ca" {
+			t.Errorf("%q: expected default CA reference, got %v", tc.description, status.DefaultCertificateAuthority)
+		}
+	}
+}
+
+func TestComputeComponentRouteStatus(t *testing.T) {
+	then := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	now := metav1.Now()
+	current := ComponentRouteStatus{
+		Namespace:       "openshift-console",
+		Name:            "console",
+		DefaultHostname: "console.apps.example.com",
+		ConsumingUsers:  []string{"system:serviceaccount:openshift-console-operator:console-operator"},
+		Conditions: []ComponentRouteCondition{
+			{Type: ComponentRouteDegraded, Status: configv1.ConditionFalse, LastTransitionTime: then},
+		},
+	}
+
+	status := computeComponentRouteStatus(current, nil, nil, now)
+	if len(status.CurrentHostnames) != 1 || status.CurrentHostnames[0] != current.DefaultHostname {
+		t.Errorf("expected current hostname %q, got %v", current.DefaultHostname, status.CurrentHostnames)
+	}
+	if len(status.RelatedObjects) != 1 || status.RelatedObjects[0].Resource != "configmaps" {
+		t.Errorf("expected the default CA configmap as related object, got %v", status.RelatedObjects)
+	}
+	if len(status.ConsumingUsers) != 1 {
+		t.Errorf("expected consuming users to be preserved, got %v", status.ConsumingUsers)
+	}
+	for _, c := range status.Conditions {
+		if c.Type == ComponentRouteDegraded && !c.LastTransitionTime.Equal(&then) {
+			t.Errorf("expected last transition time of unchanged condition to be preserved, got %v", c.LastTransitionTime)
+		}
+		if c.Type == ComponentRouteProgressing && !c.LastTransitionTime.Equal(&now) {
+			t.Errorf("expected last transition time of new condition to be now, got %v", c.LastTransitionTime)
+		}
+	}
+
+	spec := &ComponentRouteSpec{
+		Namespace:                current.Namespace,
+		Name:                     current.Name,
+		Hostname:                 "console.example.com",
+		ServingCertKeyPairSecret: configv1.SecretNameReference{Name: "console-cert"},
+	}
+	status = computeComponentRouteStatus(current, spec, nil, now)
+	if len(status.CurrentHostnames) != 1 || status.CurrentHostnames[0] != spec.Hostname {
+		t.Errorf("expected current hostname %q, got %v", spec.Hostname, status.CurrentHostnames)
+	}
+	if len(status.RelatedObjects) != 1 || status.RelatedObjects[0].Name != "console-cert" || status.RelatedObjects[0].Namespace != "openshift-config" {
+		t.Errorf("expected the serving cert secret as related object, got %v", status.RelatedObjects)
+	}
+}
+
+func TestIngressConfigUnstructuredRoundTrip(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "config.openshift.io/v1",
+		"kind":       "Ingress",
+		"metadata":   map[string]interface{}{"name": "cluster"},
+		"spec": map[string]interface{}{
+			"domain": "apps.example.com",
+			"componentRoutes": []interface{}{
+				map[string]interface{}{"namespace": "openshift-console", "name": "console", "hostname": "console.example.com"},
+			},
+		},
+	}}
+	config, err := fromUnstructured(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Spec.Domain != "apps.example.com" || len(config.Spec.ComponentRoutes) != 1 || config.Spec.ComponentRoutes[0].Hostname != "console.example.com" {
+		t.Fatalf("unexpected spec: %#v", config.Spec)
+	}
+
+	status := computeIngressConfigStatus(config, nil, nil, metav1.Now())
+	if err := setStatus(u, status); err != nil {
+		t.Fatal(err)
+	}
+	config, err = fromUnstructured(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ingressConfigStatusEqual(&config.Status, status) {
+		t.Fatalf("expected status %#v, got %#v", status, config.Status)
+	}
+}
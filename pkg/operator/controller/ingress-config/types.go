@@ -0,0 +1,141 @@
+package ingressconfig
+
+import (
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The types in this file mirror the fields of ingresses.config.openshift.io
+// that the operator reads and writes but that the vendored config API does not
+// yet define.  The operator reads and writes the ingress config as an
+// unstructured object and converts to and from these types.
+
+// ingressConfigGVK is the group, version, and kind of the ingress config.
+var ingressConfigGVK = schema.GroupVersionKind{
+	Group:   configv1.GroupName,
+	Version: "v1",
+	Kind:    "Ingress",
+}
+
+// ingressConfig is the subset of ingresses.config.openshift.io that the
+// operator uses.
+type ingressConfig struct {
+	Spec   ingressConfigSpec   `json:"spec"`
+	Status ingressConfigStatus `json:"status"`
+}
+
+type ingressConfigSpec struct {
+	// Domain is the default domain for routes.
+	Domain string `json:"domain"`
+
+	// ComponentRoutes is the list of routes of cluster components for
+	// which the cluster administrator has customized the hostname or
+	// serving certificate.
+	ComponentRoutes []ComponentRouteSpec `json:"componentRoutes,omitempty"`
+}
+
+type ingressConfigStatus struct {
+	// Domain is the effective default domain for routes.  It is
+	// spec.domain if that is set and otherwise the domain of the default
+	// ingresscontroller.
+	Domain string `json:"domain,omitempty"`
+
+	// DefaultCertificateAuthority references the configmap with the CA
+	// certificate that signs the default ingress certificates.  Clients of
+	// routes that use the default hostname can trust this CA.
+	DefaultCertificateAuthority *configv1.ObjectReference `json:"defaultCertificateAuthority,omitempty"`
+
+	// ComponentRoutes is the list of routes of cluster components.  Each
+	// component operator adds an entry for each of its routes, and the
+	// ingress operator reports the entry's current state.
+	ComponentRoutes []ComponentRouteStatus `json:"componentRoutes,omitempty"`
+}
+
+// ComponentRouteSpec allows the cluster administrator to customize the
+// hostname and serving certificate of a cluster component's route.
+type ComponentRouteSpec struct {
+	// Namespace is the namespace of the route.
+	Namespace string `json:"namespace"`
+	// Name is the logical name of the route.
+	Name string `json:"name"`
+	// Hostname is the custom hostname for the route.
+	Hostname string `json:"hostname"`
+	// ServingCertKeyPairSecret references a TLS secret in the
+	// openshift-config namespace with the serving certificate and key for
+	// the custom hostname.
+	ServingCertKeyPairSecret configv1.SecretNameReference `json:"servingCertKeyPairSecret,omitempty"`
+}
+
+// ComponentRouteStatus reports the state of a cluster component's route.
+type ComponentRouteStatus struct {
+	// Namespace is the namespace of the route.
+	Namespace string `json:"namespace"`
+	// Name is the logical name of the route.
+	Name string `json:"name"`
+	// DefaultHostname is the hostname that the route uses unless the
+	// cluster administrator customizes it.  It is set by the component
+	// operator.
+	DefaultHostname string `json:"defaultHostname"`
+	// ConsumingUsers are the service accounts that need to read the
+	// route's serving certificate secret.  It is set by the component
+	// operator.
+	ConsumingUsers []string `json:"consumingUsers,omitempty"`
+	// CurrentHostnames are the hostnames that the route should use.
+	CurrentHostnames []string `json:"currentHostnames,omitempty"`
+	// Conditions report the state of the route's customization.
+	Conditions []ComponentRouteCondition `json:"conditions,omitempty"`
+	// RelatedObjects are the resources that a consumer of the route needs,
+	// such as the CA configmap or serving certificate secret.
+	RelatedObjects []configv1.ObjectReference `json:"relatedObjects,omitempty"`
+}
+
+// ComponentRouteCondition is a condition of a component route.
+type ComponentRouteCondition struct {
+	Type               string                   `json:"type"`
+	Status             configv1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time              `json:"lastTransitionTime,omitempty"`
+	Reason             string                   `json:"reason,omitempty"`
+	Message            string                   `json:"message,omitempty"`
+}
+
+const (
+	// ComponentRouteDegraded is true if the route's customization is
+	// invalid.
+	ComponentRouteDegraded = "Degraded"
+	// ComponentRouteProgressing is true while the route's customization
+	// is being applied.
+	ComponentRouteProgressing = "Progressing"
+)
+
+// fromUnstructured converts the given unstructured ingress config.
+func fromUnstructured(u *unstructured.Unstructured) (*ingressConfig, error) {
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ingress config: %v", err)
+	}
+	config := &ingressConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ingress config: %v", err)
+	}
+	return config, nil
+}
+
+// setStatus sets the status of the given unstructured ingress config.
+func setStatus(u *unstructured.Unstructured, status *ingressConfigStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingress config status: %v", err)
+	}
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal ingress config status: %v", err)
+	}
+	u.Object["status"] = content
+	return nil
+}
@@ -3,19 +3,106 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
 
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// DeploymentRollingOutConditionType is the type of the
+	// ingresscontroller condition that reports whether the router
+	// deployment is rolling out new pods.
+	DeploymentRollingOutConditionType = "DeploymentRollingOut"
+
+	// RouteSelectorUpdatedReason is the reason of the DeploymentRollingOut
+	// condition while the router deployment rolls out because the
+	// ingresscontroller's route or namespace selector changed.  Routers
+	// only evaluate their selectors when they start, so the rollout is
+	// what makes the new shard membership take effect.
+	RouteSelectorUpdatedReason = "RouteSelectorUpdated"
+
+	// IngressControllerDegradedConditionType is the type of the
+	// ingresscontroller condition that reports whether the router is
+	// failing to serve traffic that it is expected to serve.
+	IngressControllerDegradedConditionType = "Degraded"
+
+	// ZeroReplicasReason is the reason of the Available and Degraded
+	// conditions of an ingresscontroller that is intentionally scaled to
+	// zero replicas.  Such an ingresscontroller is dormant, not failing.
+	ZeroReplicasReason = "ZeroReplicas"
+
+	// SelectorsUpdatedGenerationAnnotation is an annotation on the router
+	// deployment with the deployment generation in which the operator last
+	// changed the router's route or namespace selector.
+	SelectorsUpdatedGenerationAnnotation = "ingress.operator.openshift.io/selectors-updated-generation"
+)
+
+// ingressStatusCondition is a condition that syncIngressControllerStatus sets
+// on an ingresscontroller, or removes from it if condition is nil.
+type ingressStatusCondition struct {
+	conditionType string
+	condition     *operatorv1.OperatorCondition
+}
+
+// ingressStatusConditionTypes are the types of the conditions that the
+// reconciler computes for each ingresscontroller apart from those that
+// syncIngressControllerStatus computes from the router deployment.
+var ingressStatusConditionTypes = []string{
+	operatorv1.LoadBalancerReadyIngressConditionType,
+	CertManagerCertificateReadyConditionType,
+	DefaultCertificateServedConditionType,
+	EndpointAddressesConditionType,
+	DomainMigrationProgressingConditionType,
+	HAProxyReloadFailingConditionType,
+	LoadBalancerZoneWithoutRouterConditionType,
+	RouterImageConditionType,
+	operatorv1.OperatorStatusTypeProgressing,
+	EndpointPublishingDegradedConditionType,
+	HostNetworkNodesUnreachableConditionType,
+	ScaleLimitsExceededConditionType,
+	CanaryChecksSucceedingConditionType,
+}
+
+// currentIngressStatusConditions returns the given ingresscontroller's current
+// conditions of ingressStatusConditionTypes, so that syncing its status with
+// them leaves them unchanged.
+func currentIngressStatusConditions(ic *operatorv1.IngressController) []ingressStatusCondition {
+	conditions := make([]ingressStatusCondition, 0, len(ingressStatusConditionTypes))
+	for _, conditionType := range ingressStatusConditionTypes {
+		conditions = append(conditions, ingressStatusCondition{conditionType, findIngressStatusCondition(ic.Status.Conditions, conditionType)})
+	}
+	return conditions
+}
+
+// ingressEvent is an event to record for an ingresscontroller.
+type ingressEvent struct {
+	eventType string
+	reason    string
+	message   string
+}
+
+// ingressConditionChanged returns true if the given desired condition, which is
+// nil if the condition should be absent, differs from the given current
+// condition, which is nil if it is absent, in status, reason, or message.
+func ingressConditionChanged(current, desired *operatorv1.OperatorCondition) bool {
+	if current == nil || desired == nil {
+		return current != desired
+	}
+	return current.Status != desired.Status || current.Reason != desired.Reason || current.Message != desired.Message
+}
+
 // syncIngressControllerStatus computes the current status of ic and
-// updates status upon any changes since last sync.
-func (r *reconciler) syncIngressControllerStatus(deployment *appsv1.Deployment, ic *operatorv1.IngressController) error {
+// updates status upon any changes since last sync.  Each of the given
+// conditions is set, or removed if it is nil because it does not apply to ic
+// or is unknown.
+func (r *reconciler) syncIngressControllerStatus(deployment *appsv1.Deployment, ic *operatorv1.IngressController, conditions []ingressStatusCondition) error {
 	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
 	if err != nil {
 		return fmt.Errorf("deployment has invalid spec.selector: %v", err)
@@ -25,8 +112,18 @@ func (r *reconciler) syncIngressControllerStatus(deployment *appsv1.Deployment,
 	updated.Status.AvailableReplicas = deployment.Status.AvailableReplicas
 	updated.Status.Selector = selector.String()
 	updated.Status.Conditions = computeIngressStatusConditions(updated.Status.Conditions, deployment)
+	updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, computeIngressDegradedCondition(deployment))
+	updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, computeDeploymentRollingOutCondition(deployment))
+	updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, computeTLSProfileCondition(deployment))
+	for _, c := range conditions {
+		if c.condition != nil {
+			updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, c.condition)
+		} else {
+			updated.Status.Conditions = removeIngressStatusCondition(updated.Status.Conditions, c.conditionType)
+		}
+	}
 	if !ingressStatusesEqual(updated.Status, ic.Status) {
-		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+		if err := r.client.PatchStatus(context.TODO(), updated, operatorclient.MergeFromWithOptimisticLock(ic)); err != nil {
 			return fmt.Errorf("failed to update ingresscontroller status: %v", err)
 		}
 	}
@@ -42,6 +139,10 @@ func computeIngressStatusConditions(conditions []operatorv1.OperatorCondition, d
 	}
 	if deployment.Status.AvailableReplicas > 0 {
 		availableCondition.Status = operatorv1.ConditionTrue
+	} else if isScaledToZero(deployment) {
+		availableCondition.Status = operatorv1.ConditionFalse
+		availableCondition.Reason = ZeroReplicasReason
+		availableCondition.Message = "The ingresscontroller is scaled to zero replicas"
 	} else {
 		availableCondition.Status = operatorv1.ConditionFalse
 		availableCondition.Reason = "DeploymentUnavailable"
@@ -52,6 +153,73 @@ func computeIngressStatusConditions(conditions []operatorv1.OperatorCondition, d
 	return conditions
 }
 
+// isScaledToZero returns true if the given router deployment is scaled to zero
+// replicas.  A router daemonset that matches no nodes is unavailable rather
+// than scaled to zero.
+func isScaledToZero(deployment *appsv1.Deployment) bool {
+	return deployment.Kind != daemonSetKind && deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0
+}
+
+// computeIngressDegradedCondition computes the Degraded condition from the
+// given router deployment.  The ingresscontroller is degraded if none of the
+// replicas that it requests are available.
+func computeIngressDegradedCondition(deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	switch {
+	case isScaledToZero(deployment):
+		return &operatorv1.OperatorCondition{
+			Type:    IngressControllerDegradedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  ZeroReplicasReason,
+			Message: "The ingresscontroller is scaled to zero replicas",
+		}
+	case deployment.Status.AvailableReplicas == 0:
+		return &operatorv1.OperatorCondition{
+			Type:    IngressControllerDegradedConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "DeploymentUnavailable",
+			Message: "no Deployment replicas available",
+		}
+	}
+	return &operatorv1.OperatorCondition{
+		Type:   IngressControllerDegradedConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+}
+
+// computeDeploymentRollingOutCondition computes the DeploymentRollingOut
+// condition from the given router deployment's rollout status.
+func computeDeploymentRollingOutCondition(deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   DeploymentRollingOutConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "DeploymentRollingOut",
+	}
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	switch {
+	case deployment.Status.ObservedGeneration < deployment.Generation:
+		condition.Message = "Waiting for the router deployment spec update to be observed"
+	case deployment.Status.UpdatedReplicas < replicas:
+		condition.Message = fmt.Sprintf("%d of %d new replica(s) have been updated", deployment.Status.UpdatedReplicas, replicas)
+	case deployment.Status.Replicas > deployment.Status.UpdatedReplicas:
+		condition.Message = fmt.Sprintf("%d old replica(s) are pending termination", deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+	case deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas:
+		condition.Message = fmt.Sprintf("%d of %d updated replica(s) are available", deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+	default:
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "DeploymentNotRollingOut"
+		condition.Message = "The router deployment has finished rolling out"
+		return condition
+	}
+	if deployment.Annotations[SelectorsUpdatedGenerationAnnotation] == strconv.FormatInt(deployment.Generation, 10) {
+		condition.Reason = RouteSelectorUpdatedReason
+		condition.Message = "The route or namespace selector changed, and routers are being redeployed to re-evaluate which routes they admit: " + condition.Message
+	}
+	return condition
+}
+
 // setIngressStatusCondition returns the IngressController condition result
 // of setting the specified condition in the given slice of conditions.
 func setIngressStatusCondition(oldConditions []operatorv1.OperatorCondition, condition *operatorv1.OperatorCondition) []operatorv1.OperatorCondition {
@@ -82,6 +250,30 @@ func setIngressStatusCondition(oldConditions []operatorv1.OperatorCondition, con
 	return newConditions
 }
 
+// findIngressStatusCondition returns a copy of the condition of the given type
+// in the given slice of conditions, or nil if there is none.
+func findIngressStatusCondition(conditions []operatorv1.OperatorCondition, conditionType string) *operatorv1.OperatorCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			condition := conditions[i]
+			return &condition
+		}
+	}
+	return nil
+}
+
+// removeIngressStatusCondition returns the given slice of conditions without
+// any condition of the given type.
+func removeIngressStatusCondition(conditions []operatorv1.OperatorCondition, conditionType string) []operatorv1.OperatorCondition {
+	var newConditions []operatorv1.OperatorCondition
+	for _, c := range conditions {
+		if c.Type != conditionType {
+			newConditions = append(newConditions, c)
+		}
+	}
+	return newConditions
+}
+
 // ingressStatusesEqual compares two IngressControllerStatus values.  Returns true
 // if the provided values should be considered equal for the purpose of determining
 // whether an update is necessary, false otherwise.
@@ -256,3 +256,109 @@ func TestIngressStatusesEqual(t *testing.T) {
 		}
 	}
 }
+
+func TestComputeDeploymentRollingOutCondition(t *testing.T) {
+	var two int32 = 2
+	testCases := []struct {
+		description  string
+		generation   int64
+		annotations  map[string]string
+		status       appsv1.DeploymentStatus
+		expectStatus operatorv1.ConditionStatus
+		expectReason string
+	}{
+		{
+			description:  "rollout complete",
+			generation:   3,
+			status:       appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "DeploymentNotRollingOut",
+		},
+		{
+			description:  "spec update not observed",
+			generation:   4,
+			status:       appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "DeploymentRollingOut",
+		},
+		{
+			description:  "old replicas pending termination",
+			generation:   4,
+			status:       appsv1.DeploymentStatus{ObservedGeneration: 4, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "DeploymentRollingOut",
+		},
+		{
+			description:  "rollout after selector change",
+			generation:   4,
+			annotations:  map[string]string{SelectorsUpdatedGenerationAnnotation: "4"},
+			status:       appsv1.DeploymentStatus{ObservedGeneration: 4, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2},
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: RouteSelectorUpdatedReason,
+		},
+		{
+			description:  "rollout after a later change",
+			generation:   5,
+			annotations:  map[string]string{SelectorsUpdatedGenerationAnnotation: "4"},
+			status:       appsv1.DeploymentStatus{ObservedGeneration: 5, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "DeploymentRollingOut",
+		},
+		{
+			description:  "selector change rolled out",
+			generation:   4,
+			annotations:  map[string]string{SelectorsUpdatedGenerationAnnotation: "4"},
+			status:       appsv1.DeploymentStatus{ObservedGeneration: 4, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "DeploymentNotRollingOut",
+		},
+	}
+	for _, tc := range testCases {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: tc.generation, Annotations: tc.annotations},
+			Spec:       appsv1.DeploymentSpec{Replicas: &two},
+			Status:     tc.status,
+		}
+		condition := computeDeploymentRollingOutCondition(deployment)
+		if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason {
+			t.Errorf("%q: expected status %s and reason %s, got %#v", tc.description, tc.expectStatus, tc.expectReason, condition)
+		}
+	}
+}
+
+func TestZeroReplicasConditions(t *testing.T) {
+	var zero, two int32 = 0, 2
+	testCases := []struct {
+		description     string
+		replicas        *int32
+		available       int32
+		expectAvailable operatorv1.ConditionStatus
+		expectDegraded  operatorv1.ConditionStatus
+		expectReason    string
+	}{
+		{"scaled to zero", &zero, 0, operatorv1.ConditionFalse, operatorv1.ConditionFalse, ZeroReplicasReason},
+		{"no replicas available", &two, 0, operatorv1.ConditionFalse, operatorv1.ConditionTrue, "DeploymentUnavailable"},
+		{"replicas available", &two, 2, operatorv1.ConditionTrue, operatorv1.ConditionFalse, ""},
+	}
+	for _, tc := range testCases {
+		deployment := &appsv1.Deployment{
+			Spec:   appsv1.DeploymentSpec{Replicas: tc.replicas},
+			Status: appsv1.DeploymentStatus{AvailableReplicas: tc.available},
+		}
+		conditions := computeIngressStatusConditions(nil, deployment)
+		available := findIngressStatusCondition(conditions, operatorv1.IngressControllerAvailableConditionType)
+		if available == nil || available.Status != tc.expectAvailable || available.Reason != tc.expectReason {
+			t.Errorf("%q: expected Available=%s with reason %q, got %#v", tc.description, tc.expectAvailable, tc.expectReason, available)
+		}
+		degraded := computeIngressDegradedCondition(deployment)
+		if degraded.Status != tc.expectDegraded || degraded.Reason != tc.expectReason {
+			t.Errorf("%q: expected Degraded=%s with reason %q, got %#v", tc.description, tc.expectDegraded, tc.expectReason, degraded)
+		}
+
+		ic := operatorv1.IngressController{Status: operatorv1.IngressControllerStatus{Conditions: conditions}}
+		expectOperatorAvailable := tc.expectDegraded == operatorv1.ConditionFalse
+		if actual := checkAllIngressesAvailable([]operatorv1.IngressController{ic}); actual != expectOperatorAvailable {
+			t.Errorf("%q: expected checkAllIngressesAvailable to return %t, got %t", tc.description, expectOperatorAvailable, actual)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ManagementStateAnnotation overrides the operator's management of an
+	// ingresscontroller's operands.  Valid values are "Managed" (the
+	// default), "Unmanaged", and "Removed".  When set on the default
+	// ingresscontroller, the value applies to every ingresscontroller that
+	// does not set the annotation itself.
+	ManagementStateAnnotation = "ingress.operator.openshift.io/management-state"
+)
+
+// managementStateFor returns the management state specified by the given
+// ingresscontroller's annotations, or the empty string if none is specified.
+func managementStateFor(ic *operatorv1.IngressController) (operatorv1.ManagementState, error) {
+	value, ok := ic.Annotations[ManagementStateAnnotation]
+	if !ok || len(value) == 0 {
+		return "", nil
+	}
+	switch state := operatorv1.ManagementState(value); state {
+	case operatorv1.Managed, operatorv1.Unmanaged, operatorv1.Removed:
+		return state, nil
+	default:
+		return "", fmt.Errorf("invalid value for annotation %s: %q", ManagementStateAnnotation, value)
+	}
+}
+
+// effectiveManagementState returns the management state for ic, falling back
+// to the management state of the default ingresscontroller (if provided) and
+// then to Managed.  An invalid annotation value is treated as Managed and
+// reported as an error.
+func effectiveManagementState(ic, defaultIC *operatorv1.IngressController) (operatorv1.ManagementState, error) {
+	state, err := managementStateFor(ic)
+	if err != nil {
+		return operatorv1.Managed, err
+	}
+	if len(state) != 0 {
+		return state, nil
+	}
+	if defaultIC != nil && defaultIC.Name != ic.Name {
+		state, err := managementStateFor(defaultIC)
+		if err != nil {
+			return operatorv1.Managed, err
+		}
+		if len(state) != 0 {
+			return state, nil
+		}
+	}
+	return operatorv1.Managed, nil
+}
+
+// managementState returns the effective management state for the given
+// ingresscontroller.  If the default ingresscontroller cannot be read, the
+// returned state is empty because it is unknown, and the operands should be
+// left as they are rather than managed against the default ingresscontroller's
+// setting.
+func (r *reconciler) managementState(ic *operatorv1.IngressController) (operatorv1.ManagementState, error) {
+	defaultIC := &operatorv1.IngressController{}
+	name := types.NamespacedName{Namespace: ic.Namespace, Name: DefaultIngressControllerName}
+	if err := r.client.Get(context.TODO(), name, defaultIC); err != nil {
+		if !errors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get default ingresscontroller: %v", err)
+		}
+		defaultIC = nil
+	}
+	return effectiveManagementState(ic, defaultIC)
+}
+
+// unmanagedIngressControllers returns the names of the ingresscontrollers in
+// the given list that are not in the Managed state or whose reconciliation is
+// paused.
+func unmanagedIngressControllers(ingresses []operatorv1.IngressController) []string {
+	var defaultIC *operatorv1.IngressController
+	for i := range ingresses {
+		if ingresses[i].Name == DefaultIngressControllerName {
+			defaultIC = &ingresses[i]
+			break
+		}
+	}
+	names := []string{}
+	for i := range ingresses {
+		if state, _ := effectiveManagementState(&ingresses[i], defaultIC); state != operatorv1.Managed || isPaused(&ingresses[i]) {
+			names = append(names, ingresses[i].Name)
+		}
+	}
+	return names
+}
+
+// ensureIngressControllerUnmanaged leaves the operands of the given
+// ingresscontroller untouched but keeps the ingresscontroller's status up to
+// date.
+func (r *reconciler) ensureIngressControllerUnmanaged(ic *operatorv1.IngressController) error {
+	deployment, err := r.currentRouterDeployment(ic)
+	if err != nil {
+		return fmt.Errorf("failed to get router deployment for %s: %v", ic.Name, err)
+	}
+	if deployment == nil {
+		return nil
+	}
+	return r.syncIngressControllerStatus(deployment, ic, currentIngressStatusConditions(ic))
+}
+
+// ensureIngressControllerRemoved scales down the router deployment for the
+// given ingresscontroller and keeps the ingresscontroller's status up to date.
+func (r *reconciler) ensureIngressControllerRemoved(ic *operatorv1.IngressController) error {
+	deployment, err := r.currentRouterDeployment(ic)
+	if err != nil {
+		return fmt.Errorf("failed to get router deployment for %s: %v", ic.Name, err)
+	}
+	if deployment == nil {
+		return nil
+	}
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0 {
+		if err := r.scaleDownRouterDeployment(deployment); err != nil {
+			return err
+		}
+	}
+	return r.syncIngressControllerStatus(deployment, ic, currentIngressStatusConditions(ic))
+}
+
+// scaleDownRouterDeployment scales the given router deployment to zero
+// replicas.
+func (r *reconciler) scaleDownRouterDeployment(deployment *appsv1.Deployment) error {
+	updated := deployment.DeepCopy()
+	zero := int32(0)
+	updated.Spec.Replicas = &zero
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to scale down router deployment %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("scaled down router deployment", "namespace", updated.Namespace, "name", updated.Name)
+	return nil
+}
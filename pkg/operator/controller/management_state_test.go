@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEffectiveManagementState(t *testing.T) {
+	newIC := func(name, state string) *operatorv1.IngressController {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(state) != 0 {
+			ic.Annotations = map[string]string{ManagementStateAnnotation: state}
+		}
+		return ic
+	}
+	testCases := []struct {
+		description string
+		ic          *operatorv1.IngressController
+		defaultIC   *operatorv1.IngressController
+		expected    operatorv1.ManagementState
+		expectErr   bool
+	}{
+		{
+			description: "no annotations",
+			ic:          newIC("shard", ""),
+			defaultIC:   newIC("default", ""),
+			expected:    operatorv1.Managed,
+		},
+		{
+			description: "no default ingresscontroller",
+			ic:          newIC("shard", ""),
+			expected:    operatorv1.Managed,
+		},
+		{
+			description: "ingresscontroller is unmanaged",
+			ic:          newIC("shard", "Unmanaged"),
+			defaultIC:   newIC("default", ""),
+			expected:    operatorv1.Unmanaged,
+		},
+		{
+			description: "default ingresscontroller is removed",
+			ic:          newIC("shard", ""),
+			defaultIC:   newIC("default", "Removed"),
+			expected:    operatorv1.Removed,
+		},
+		{
+			description: "ingresscontroller overrides default ingresscontroller",
+			ic:          newIC("shard", "Managed"),
+			defaultIC:   newIC("default", "Unmanaged"),
+			expected:    operatorv1.Managed,
+		},
+		{
+			description: "invalid annotation value",
+			ic:          newIC("shard", "Force"),
+			expected:    operatorv1.Managed,
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		state, err := effectiveManagementState(tc.ic, tc.defaultIC)
+		if tc.expectErr != (err != nil) {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+		if state != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expected, state)
+		}
+	}
+}
+
+func TestUnmanagedIngressControllers(t *testing.T) {
+	ingresses := []operatorv1.IngressController{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: map[string]string{ManagementStateAnnotation: "Unmanaged"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "shard1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "shard2", Annotations: map[string]string{ManagementStateAnnotation: "Managed"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "shard3", Annotations: map[string]string{ManagementStateAnnotation: "Managed", PausedAnnotation: "true"}}},
+	}
+	names := unmanagedIngressControllers(ingresses)
+	if len(names) != 3 || names[0] != "default" || names[1] != "shard1" || names[2] != "shard3" {
+		t.Errorf("expected [default shard1 shard3], got %v", names)
+	}
+}
@@ -10,11 +10,29 @@ import (
 )
 
 const (
+	// DefaultIngressControllerName is the name of the default
+	// IngressController instance.
+	DefaultIngressControllerName = "default"
+
+	// DefaultOperandNamespace is the namespace of the routers and the other
+	// resources that the operator manages for ingresscontrollers, unless the
+	// operator config specifies another namespace.
+	DefaultOperandNamespace = "openshift-ingress"
+
+	// GlobalUserSpecifiedConfigNamespace is the namespace in which the
+	// cluster administrator puts secrets and configmaps that cluster config
+	// resources reference.
+	GlobalUserSpecifiedConfigNamespace = "openshift-config"
+
 	// GlobalMachineSpecifiedConfigNamespace is the location for global
 	// config.  In particular, the operator will put the configmap with the
 	// CA certificate in this namespace.
 	GlobalMachineSpecifiedConfigNamespace = "openshift-config-managed"
 
+	// CanaryNamespace is the namespace of the canary application, which
+	// the operator deploys to check the ingress data path end to end.
+	CanaryNamespace = "openshift-ingress-canary"
+
 	// caCertSecretName is the name of the secret that holds the CA certificate
 	// that the operator will use to create default certificates for
 	// ingresscontrollers.
@@ -36,13 +54,22 @@ const (
 )
 
 // RouterDeploymentName returns the namespaced name for the router deployment.
-func RouterDeploymentName(ci *operatorv1.IngressController) types.NamespacedName {
+func RouterDeploymentName(ci *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
 	return types.NamespacedName{
-		Namespace: "openshift-ingress",
+		Namespace: operandNamespace,
 		Name:      "router-" + ci.Name,
 	}
 }
 
+// RouterStatsSecretName returns the namespaced name for the secret with the
+// credentials for the router's stats listener.
+func RouterStatsSecretName(ci *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operandNamespace,
+		Name:      "router-stats-" + ci.Name,
+	}
+}
+
 // RouterCASecretName returns the namespaced name for the router CA secret.
 func RouterCASecretName(operatorNamespace string) types.NamespacedName {
 	return types.NamespacedName{
@@ -77,12 +104,25 @@ func RouterOperatorGeneratedDefaultCertificateSecretName(ci *operatorv1.IngressC
 	}
 }
 
+// RouterCertManagerCertificateName returns the namespaced name of the
+// cert-manager Certificate, and of the secret to which cert-manager writes the
+// issued default certificate, for the given ingresscontroller.
+func RouterCertManagerCertificateName(ci *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operandNamespace,
+		Name:      fmt.Sprintf("router-certs-cert-manager-%s", ci.Name),
+	}
+}
+
 // RouterEffectiveDefaultCertificateSecretName returns the namespaced name for
 // the in-use router default certificate secret.
 func RouterEffectiveDefaultCertificateSecretName(ci *operatorv1.IngressController, namespace string) types.NamespacedName {
 	if cert := ci.Spec.DefaultCertificate; cert != nil {
 		return types.NamespacedName{Namespace: namespace, Name: cert.Name}
 	}
+	if UsesCertManager(ci) {
+		return types.NamespacedName{Namespace: namespace, Name: RouterCertManagerCertificateName(ci, namespace).Name}
+	}
 	return RouterOperatorGeneratedDefaultCertificateSecretName(ci, namespace)
 }
 
@@ -98,14 +138,140 @@ func IngressControllerDeploymentPodSelector(ic *operatorv1.IngressController) *m
 	}
 }
 
-func InternalIngressControllerServiceName(ic *operatorv1.IngressController) types.NamespacedName {
-	// TODO: remove hard-coded namespace
-	return types.NamespacedName{Namespace: "openshift-ingress", Name: "router-internal-" + ic.Name}
+func InternalIngressControllerServiceName(ic *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{Namespace: operandNamespace, Name: "router-internal-" + ic.Name}
 }
 
-func IngressControllerServiceMonitorName(ic *operatorv1.IngressController) types.NamespacedName {
+func IngressControllerServiceMonitorName(ic *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
 	return types.NamespacedName{
-		Namespace: "openshift-ingress",
+		Namespace: operandNamespace,
 		Name:      "router-" + ic.Name,
 	}
 }
+
+// WildcardDNSRecordName returns the name of the DNSRecord for the wildcard
+// domain of the given ingresscontroller.  The DNSRecord is in the operator
+// namespace alongside the ingresscontroller.
+func WildcardDNSRecordName(ic *operatorv1.IngressController) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: ic.Namespace,
+		Name:      ic.Name + "-wildcard",
+	}
+}
+
+// InternalWildcardDNSRecordName returns the name of the DNSRecord that resolves
+// the wildcard domain of the given ingresscontroller to its additional internal
+// load balancer in the cluster's private zone.
+func InternalWildcardDNSRecordName(ic *operatorv1.IngressController) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: ic.Namespace,
+		Name:      ic.Name + "-wildcard-internal",
+	}
+}
+
+// IngressClassName returns the name of the IngressClass for the
+// ingresscontroller with the given name, for example "openshift-default" for
+// the default ingresscontroller.
+func IngressClassName(ingressControllerName string) types.NamespacedName {
+	return types.NamespacedName{Name: "openshift-" + ingressControllerName}
+}
+
+// LoadBalancerServiceName returns the namespaced name of the LB service for
+// the given ingresscontroller.
+func LoadBalancerServiceName(ci *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{Namespace: operandNamespace, Name: "router-" + ci.Name}
+}
+
+// InternalLoadBalancerServiceName returns the namespaced name of the additional
+// internal LB service of the given ingresscontroller.
+func InternalLoadBalancerServiceName(ci *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{Namespace: operandNamespace, Name: "router-" + ci.Name + "-internal-lb"}
+}
+
+// NodePortServiceName returns the namespaced name for the NodePort service of
+// an ingresscontroller that uses the NodePortService endpoint publishing
+// strategy.
+func NodePortServiceName(ci *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{Namespace: operandNamespace, Name: "router-nodeport-" + ci.Name}
+}
+
+// AlternateLoadBalancerServiceName returns the other namespaced name that the
+// LB service of the given ingresscontroller may have.  An LB service that
+// replaces one with the name that LoadBalancerServiceName returns gets this
+// name, and vice versa.
+func AlternateLoadBalancerServiceName(ci *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{Namespace: operandNamespace, Name: "router-" + ci.Name + "-alternate"}
+}
+
+// RsyslogConfigMapName returns the namespaced name of the configmap with the
+// rsyslog configuration for the access log sidecar of the given
+// ingresscontroller.
+func RsyslogConfigMapName(ic *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operandNamespace,
+		Name:      "rsyslog-conf-" + ic.Name,
+	}
+}
+
+// RouterServiceAccountName returns the namespaced name of the service account
+// that the router pods of the given ingresscontroller run as.  The role and
+// role binding that grant the router its namespaced permissions have the same
+// name.
+func RouterServiceAccountName(ic *operatorv1.IngressController, operandNamespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operandNamespace,
+		Name:      "router-" + ic.Name,
+	}
+}
+
+// RouterClusterRoleBindingName returns the name of the cluster role binding
+// that binds the router cluster role to the service account of the given
+// ingresscontroller.
+func RouterClusterRoleBindingName(ic *operatorv1.IngressController) types.NamespacedName {
+	return types.NamespacedName{Name: "openshift-ingress-router-" + ic.Name}
+}
+
+// RouterExternalCertificateRoleName returns the namespaced name of the role and
+// role binding in the given namespace that grant the router service account of
+// the given ingresscontroller read access to the secrets that routes in the
+// namespace reference as external certificates.
+func RouterExternalCertificateRoleName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      "openshift-ingress-router-external-certificate-" + ic.Name,
+	}
+}
+
+// CanaryDaemonSetName returns the namespaced name of the canary daemonset.
+func CanaryDaemonSetName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: CanaryNamespace,
+		Name:      "ingress-canary",
+	}
+}
+
+// CanaryServiceName returns the namespaced name of the canary service.
+func CanaryServiceName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: CanaryNamespace,
+		Name:      "ingress-canary",
+	}
+}
+
+// CanaryRouteName returns the namespaced name of the canary route that the
+// default ingresscontroller serves.
+func CanaryRouteName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: CanaryNamespace,
+		Name:      "canary",
+	}
+}
+
+// ShardCanaryRouteName returns the namespaced name of the canary route that
+// the given ingresscontroller serves if it opts in to canary checks.
+func ShardCanaryRouteName(ic *operatorv1.IngressController) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: CanaryNamespace,
+		Name:      "canary-" + ic.Name,
+	}
+}
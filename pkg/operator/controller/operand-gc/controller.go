@@ -0,0 +1,303 @@
+// The operand-gc controller is responsible for deleting operand resources that
+// are labeled for an ingresscontroller that no longer exists.  Such resources
+// are normally deleted when the ingresscontroller is finalized, but they can
+// be left behind if finalization fails or is bypassed, or reappear when etcd
+// is restored from a backup that predates the ingresscontroller's deletion.
+package operandgc
+
+import (
+	"context"
+	"fmt"
+
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "operand-gc-controller"
+)
+
+var log = logf.Logger.WithName(controllerName)
+
+// operandLists are the kinds of operand resources that the controller deletes
+// when the ingresscontroller that they are labeled for no longer exists.
+func operandLists() []runtime.Object {
+	return []runtime.Object{
+		&appsv1.DeploymentList{},
+		&appsv1.DaemonSetList{},
+		&corev1.ServiceList{},
+		&policyv1beta1.PodDisruptionBudgetList{},
+		&corev1.ServiceAccountList{},
+		&rbacv1.RoleList{},
+		&rbacv1.RoleBindingList{},
+	}
+}
+
+type reconciler struct {
+	client            client.Client
+	recorder          record.EventRecorder
+	operatorNamespace string
+	operandNamespace  string
+}
+
+// New returns a new controller that deletes orphaned operand resources.  The
+// given client should not be backed by a cache so that an ingresscontroller
+// that the cache has not observed yet is never mistaken for a deleted one.
+func New(mgr manager.Manager, operandCache cache.Cache, cl client.Client, operatorNamespace, operandNamespace string) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		client:            cl,
+		recorder:          mgr.GetEventRecorderFor(controllerName),
+		operatorNamespace: operatorNamespace,
+		operandNamespace:  operandNamespace,
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+
+	// Queue an ingresscontroller when it is deleted so that any operand
+	// resources that its finalization left behind are deleted.
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, err
+	}
+
+	// Queue the owning ingresscontroller of each labeled operand resource
+	// when the controller starts, so that resources that were orphaned
+	// while the operator was not running are swept, and whenever one is
+	// created, so that resources that reappear after an etcd restore are
+	// swept too.
+	for _, o := range []runtime.Object{
+		&appsv1.Deployment{},
+		&appsv1.DaemonSet{},
+		&corev1.Service{},
+		&policyv1beta1.PodDisruptionBudget{},
+	} {
+		informer, err := operandCache.GetInformer(o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get informer for %s: %v", kindOf(o), err)
+		}
+		if err := c.Watch(&source.Informer{Informer: informer}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				return owningIngressController(operatorNamespace, a.Meta)
+			}),
+		}, predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return true },
+			DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+			GenericFunc: func(e event.GenericEvent) bool { return false },
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create watch for %s: %v", kindOf(o), err)
+		}
+	}
+	return c, nil
+}
+
+// owningIngressController returns a request for the ingresscontroller that the
+// given operand resource is labeled for, or no requests if the resource is not
+// labeled for an ingresscontroller.
+func owningIngressController(operatorNamespace string, o metav1.Object) []reconcile.Request {
+	name, ok := o.GetLabels()[manifests.OwningIngressControllerLabel]
+	if !ok || len(name) == 0 {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: operatorNamespace,
+			Name:      name,
+		},
+	}}
+}
+
+// Reconcile deletes the operand resources that are labeled for the requested
+// ingresscontroller if the ingresscontroller does not exist.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	if request.Namespace != r.operatorNamespace {
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.client.Get(context.TODO(), request.NamespacedName, &operatorv1.IngressController{}); err == nil {
+		return reconcile.Result{}, nil
+	} else if !errors.IsNotFound(err) {
+		return reconcile.Result{}, fmt.Errorf("failed to get ingresscontroller %q: %v", request, err)
+	}
+
+	var errs []error
+	for _, list := range operandLists() {
+		if err := r.client.List(context.TODO(), list, client.InNamespace(r.operandNamespace), client.MatchingLabels(map[string]string{manifests.OwningIngressControllerLabel: request.Name})); err != nil {
+			errs = append(errs, fmt.Errorf("failed to list %s: %v", kindOf(list), err))
+			continue
+		}
+		orphans, err := orphanedOperands(list, request.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, o := range orphans {
+			if err := r.deleteOrphan(o, request.Name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		if err := r.finalizeLoadBalancerServices(request.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return reconcile.Result{}, utilerrors.NewAggregate(errs)
+}
+
+// finalizeLoadBalancerServices removes the operator's finalizer from the LB
+// services that are labeled for the named, nonexistent ingresscontroller, as
+// the operator controller does when it finalizes an ingresscontroller, so that
+// the services do not stay terminating forever.  Services that are already
+// being deleted are included so that the cleanup is retried.  The finalizer
+// ensures that the ingresscontroller's DNS records are deleted before its load
+// balancer, so the ingresscontroller's DNSRecords are deleted first, and an
+// error is returned until the dnsrecord controller has finalized them.
+func (r *reconciler) finalizeLoadBalancerServices(name string) error {
+	labels := client.MatchingLabels(map[string]string{manifests.OwningIngressControllerLabel: name})
+	services := &corev1.ServiceList{}
+	if err := r.client.List(context.TODO(), services, client.InNamespace(r.operandNamespace), labels); err != nil {
+		return fmt.Errorf("failed to list services: %v", err)
+	}
+	finalized := servicesWithFinalizer(services, controller.LoadBalancerServiceFinalizer)
+	if len(finalized) == 0 {
+		return nil
+	}
+
+	records := &ingressv1.DNSRecordList{}
+	if err := r.client.List(context.TODO(), records, client.InNamespace(r.operatorNamespace), labels); err != nil {
+		return fmt.Errorf("failed to list dnsrecords: %v", err)
+	}
+	if len(records.Items) != 0 {
+		for i := range records.Items {
+			if err := r.deleteOrphan(&records.Items[i], name); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("waiting for the dnsrecords of ingresscontroller %q to be deleted before finalizing its load balancer services", name)
+	}
+
+	for _, serviceName := range finalized {
+		service := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), serviceName, service); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get service %s: %v", serviceName, err)
+		}
+		if !slice.ContainsString(service.Finalizers, controller.LoadBalancerServiceFinalizer) {
+			continue
+		}
+		service.Finalizers = slice.RemoveString(service.Finalizers, controller.LoadBalancerServiceFinalizer)
+		if err := r.client.Update(context.TODO(), service); err != nil {
+			return fmt.Errorf("failed to remove finalizer from service %s: %v", serviceName, err)
+		}
+		log.Info("removed finalizer from orphaned load balancer service", "namespace", service.Namespace, "name", service.Name, "ingresscontroller", name)
+	}
+	return nil
+}
+
+// servicesWithFinalizer returns the names of the services in the given list
+// that have the given finalizer.
+func servicesWithFinalizer(services *corev1.ServiceList, finalizer string) []types.NamespacedName {
+	var names []types.NamespacedName
+	for _, service := range services.Items {
+		if slice.ContainsString(service.Finalizers, finalizer) {
+			names = append(names, types.NamespacedName{Namespace: service.Namespace, Name: service.Name})
+		}
+	}
+	return names
+}
+
+// orphanedOperands returns the items of the given list that are labeled for
+// the named ingresscontroller and are not already being deleted.  LB services
+// that are being deleted are finalized by finalizeLoadBalancerServices.
+func orphanedOperands(list runtime.Object, name string) ([]runtime.Object, error) {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract items from %s: %v", kindOf(list), err)
+	}
+	var orphans []runtime.Object
+	for _, item := range items {
+		o, err := meta.Accessor(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to access metadata of %s: %v", kindOf(item), err)
+		}
+		if o.GetLabels()[manifests.OwningIngressControllerLabel] != name {
+			continue
+		}
+		if o.GetDeletionTimestamp() != nil {
+			continue
+		}
+		orphans = append(orphans, item)
+	}
+	return orphans, nil
+}
+
+// deleteOrphan deletes the given operand resource of the named, nonexistent
+// ingresscontroller and records an event for the deletion.
+func (r *reconciler) deleteOrphan(o runtime.Object, name string) error {
+	m, err := meta.Accessor(o)
+	if err != nil {
+		return err
+	}
+	kind := kindOf(o)
+	if err := r.client.Delete(context.TODO(), o); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete orphaned %s %s/%s: %v", kind, m.GetNamespace(), m.GetName(), err)
+	}
+	log.Info("deleted orphaned operand", "kind", kind, "namespace", m.GetNamespace(), "name", m.GetName(), "ingresscontroller", name)
+	r.recorder.Eventf(o, "Normal", "DeletedOrphanedOperand", "Deleted %s %s/%s because ingresscontroller %q no longer exists", kind, m.GetNamespace(), m.GetName(), name)
+	return nil
+}
+
+// kindOf returns the kind of the given object for logging, or its Go type if
+// the kind is not registered.
+func kindOf(o runtime.Object) string {
+	gvk, err := apiutil.GVKForObject(o, operatorclient.GetScheme())
+	if err != nil {
+		return fmt.Sprintf("%T", o)
+	}
+	return gvk.Kind
+}
@@ -0,0 +1,125 @@
+package operandgc
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestOwningIngressController(t *testing.T) {
+	testCases := []struct {
+		description string
+		labels      map[string]string
+		expect      string
+	}{
+		{
+			description: "no labels",
+		},
+		{
+			description: "empty owning ingresscontroller label",
+			labels:      map[string]string{manifests.OwningIngressControllerLabel: ""},
+		},
+		{
+			description: "owning ingresscontroller label",
+			labels:      map[string]string{manifests.OwningIngressControllerLabel: "sharded"},
+			expect:      "sharded",
+		},
+	}
+	for _, tc := range testCases {
+		o := &metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-sharded", Labels: tc.labels}
+		requests := owningIngressController("openshift-ingress-operator", o)
+		switch {
+		case len(tc.expect) == 0 && len(requests) != 0:
+			t.Errorf("%q: expected no requests, got %v", tc.description, requests)
+		case len(tc.expect) != 0 && len(requests) != 1:
+			t.Errorf("%q: expected 1 request, got %v", tc.description, requests)
+		case len(tc.expect) != 0 && (requests[0].Namespace != "openshift-ingress-operator" || requests[0].Name != tc.expect):
+			t.Errorf("%q: expected a request for openshift-ingress-operator/%s, got %v", tc.description, tc.expect, requests[0])
+		}
+	}
+}
+
+func TestOrphanedOperands(t *testing.T) {
+	labeled := func(name, owner string, deleting bool) metav1.ObjectMeta {
+		m := metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      name,
+			Labels:    map[string]string{manifests.OwningIngressControllerLabel: owner},
+		}
+		if deleting {
+			now := metav1.Now()
+			m.DeletionTimestamp = &now
+		}
+		return m
+	}
+	testCases := []struct {
+		description string
+		list        runtime.Object
+		expect      []string
+	}{
+		{
+			description: "empty list",
+			list:        &appsv1.DeploymentList{},
+		},
+		{
+			description: "deployments of deleted and other ingresscontrollers",
+			list: &appsv1.DeploymentList{Items: []appsv1.Deployment{
+				{ObjectMeta: labeled("router-sharded", "sharded", false)},
+				{ObjectMeta: labeled("router-default", "default", false)},
+			}},
+			expect: []string{"router-sharded"},
+		},
+		{
+			description: "services that are already being deleted",
+			list: &corev1.ServiceList{Items: []corev1.Service{
+				{ObjectMeta: labeled("router-sharded", "sharded", true)},
+				{ObjectMeta: labeled("router-internal-sharded", "sharded", false)},
+			}},
+			expect: []string{"router-internal-sharded"},
+		},
+	}
+	for _, tc := range testCases {
+		orphans, err := orphanedOperands(tc.list, "sharded")
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		var names []string
+		for _, o := range orphans {
+			names = append(names, o.(metav1.Object).GetName())
+		}
+		if len(names) != len(tc.expect) {
+			t.Errorf("%q: expected orphans %v, got %v", tc.description, tc.expect, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tc.expect[i] {
+				t.Errorf("%q: expected orphans %v, got %v", tc.description, tc.expect, names)
+				break
+			}
+		}
+	}
+}
+
+func TestServicesWithFinalizer(t *testing.T) {
+	services := &corev1.ServiceList{Items: []corev1.Service{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-sharded", Finalizers: []string{"ingress.openshift.io/operator"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-sharded"}},
+	}}
+	names := servicesWithFinalizer(services, "ingress.openshift.io/operator")
+	if len(names) != 1 || names[0].Name != "router-sharded" {
+		t.Errorf("expected only router-sharded, got %v", names)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	if kind := kindOf(&appsv1.Deployment{}); kind != "Deployment" {
+		t.Errorf("expected kind Deployment, got %s", kind)
+	}
+}
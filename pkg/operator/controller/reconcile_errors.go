@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	// TerminalErrorConditionType is the type of the ingresscontroller
+	// condition that reports errors that retrying cannot resolve, such as
+	// a configuration that the platform does not support.  The condition
+	// is removed once the errors are resolved.
+	TerminalErrorConditionType = "TerminalError"
+
+	// defaultThrottledRetryPeriod is the period after which a throttled
+	// request is retried if the error does not suggest a delay.
+	defaultThrottledRetryPeriod = 30 * time.Second
+)
+
+// terminalError is an error that retrying the reconciliation cannot resolve
+// without a change to the ingresscontroller or to the cluster.
+type terminalError struct {
+	err error
+}
+
+func (e *terminalError) Error() string { return e.err.Error() }
+func (e *terminalError) Unwrap() error { return e.err }
+
+// newTerminalError marks the given error as terminal.  Reconcile reports
+// terminal errors in the ingresscontroller's TerminalError condition instead
+// of retrying them with backoff.
+func newTerminalError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &terminalError{err: err}
+}
+
+// throttledError is an error that is resolved by retrying after a delay, such
+// as an API request that was rejected because of rate limiting.
+type throttledError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string { return e.err.Error() }
+func (e *throttledError) Unwrap() error { return e.err }
+
+// wrappedError is an error with a message that describes the context in which
+// another error occurred.  It keeps the other error so that classifyErrors can
+// classify it.  The builder's Go version predates the %w verb of fmt.Errorf,
+// so errors that must stay classifiable are wrapped with wrapError instead.
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string { return e.msg + ": " + e.err.Error() }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+// wrapError returns an error whose message is the given format and arguments
+// followed by the given error's message, and which wraps the given error.
+func wrapError(err error, format string, args ...interface{}) error {
+	return &wrappedError{msg: fmt.Sprintf(format, args...), err: err}
+}
+
+// classifyErrors sorts the given errors from a reconciliation.  It returns the
+// errors that should be retried with backoff, the terminal errors, and the
+// delay after which throttled errors should be retried, which is zero if there
+// are none.  An error is retried with backoff if any error that it wraps or
+// aggregates is neither terminal nor throttled, because retrying reconciles
+// everything anyway.  Errors that are not classified are retryable.
+func classifyErrors(errs []error) ([]error, []error, time.Duration) {
+	var retryable, terminal []error
+	var retryAfter time.Duration
+	for _, err := range errs {
+		isRetryable := false
+		visitErrors(err, func(leaf error) {
+			switch e := leaf.(type) {
+			case *terminalError:
+				terminal = append(terminal, e)
+			case *throttledError:
+				if e.retryAfter > retryAfter {
+					retryAfter = e.retryAfter
+				}
+			default:
+				isRetryable = true
+			}
+		})
+		if isRetryable {
+			retryable = append(retryable, err)
+		}
+	}
+	return retryable, terminal, retryAfter
+}
+
+// visitErrors calls fn for each error that the given error aggregates.  Errors
+// that are marked as terminal or throttled, or that are API errors that
+// indicate throttling, are passed to fn as a *terminalError or
+// *throttledError; any other error is passed as is.
+func visitErrors(err error, fn func(error)) {
+	for e := err; e != nil; e = unwrapError(e) {
+		switch v := e.(type) {
+		case utilerrors.Aggregate:
+			for _, aggregated := range v.Errors() {
+				visitErrors(aggregated, fn)
+			}
+			return
+		case *terminalError, *throttledError:
+			fn(v)
+			return
+		}
+		if errors.IsTooManyRequests(e) {
+			retryAfter := defaultThrottledRetryPeriod
+			if seconds, ok := errors.SuggestsClientDelay(e); ok && seconds > 0 {
+				retryAfter = time.Duration(seconds) * time.Second
+			}
+			fn(&throttledError{err: err, retryAfter: retryAfter})
+			return
+		}
+	}
+	fn(err)
+}
+
+// unwrapError returns the error that the given error wraps, or nil if it does
+// not wrap one.
+func unwrapError(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// computeTerminalErrorCondition returns the TerminalError condition for the
+// given terminal errors, or nil if there are none.
+func computeTerminalErrorCondition(terminal []error) *operatorv1.OperatorCondition {
+	if len(terminal) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(terminal))
+	for _, err := range terminal {
+		messages = append(messages, err.Error())
+	}
+	return &operatorv1.OperatorCondition{
+		Type:    TerminalErrorConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ReconciliationFailed",
+		Message: fmt.Sprintf("The ingresscontroller cannot be reconciled until its configuration is corrected: %s", strings.Join(messages, "; ")),
+	}
+}
+
+// syncTerminalErrorCondition sets or removes the TerminalError condition of
+// the given ingresscontroller.  The ingresscontroller is fetched again because
+// the reconciliation may have updated its status.  An event is recorded only
+// when the condition changes, and it aggregates all of the terminal errors, so
+// that a broken ingresscontroller does not record an event per error on every
+// reconciliation.
+func (r *reconciler) syncTerminalErrorCondition(ic *operatorv1.IngressController, terminal []error) error {
+	if len(terminal) == 0 && findIngressStatusCondition(ic.Status.Conditions, TerminalErrorConditionType) == nil {
+		return nil
+	}
+	current := &operatorv1.IngressController{}
+	name := types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get ingresscontroller %s: %v", name, err)
+	}
+	updated := current.DeepCopy()
+	condition := computeTerminalErrorCondition(terminal)
+	if condition != nil {
+		updated.Status.Conditions = setIngressStatusCondition(updated.Status.Conditions, condition)
+	} else {
+		updated.Status.Conditions = removeIngressStatusCondition(updated.Status.Conditions, TerminalErrorConditionType)
+	}
+	if ingressStatusesEqual(updated.Status, current.Status) {
+		return nil
+	}
+	if err := r.client.PatchStatus(context.TODO(), updated, operatorclient.MergeFromWithOptimisticLock(current)); err != nil {
+		return fmt.Errorf("failed to update ingresscontroller status: %v", err)
+	}
+	if e := terminalErrorEvent(findIngressStatusCondition(current.Status.Conditions, TerminalErrorConditionType), condition); e != nil {
+		r.recorder.Event(updated, e.eventType, e.reason, e.message)
+	}
+	return nil
+}
+
+// terminalErrorEvent returns the event to record when an ingresscontroller's
+// TerminalError condition changes from the given current condition to the
+// given desired condition, either of which is nil if the condition is absent,
+// or nil if it is unchanged.
+func terminalErrorEvent(current, desired *operatorv1.OperatorCondition) *ingressEvent {
+	switch {
+	case !ingressConditionChanged(current, desired):
+		return nil
+	case desired != nil:
+		return &ingressEvent{"Warning", "TerminalError", desired.Message}
+	default:
+		return &ingressEvent{"Normal", "TerminalErrorResolved", "The ingresscontroller's terminal errors are resolved"}
+	}
+}
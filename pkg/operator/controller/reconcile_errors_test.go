@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// TestClassifyErrors verifies that classifyErrors sorts errors into retryable
+// errors, terminal errors, and a retry delay for throttled errors.
+func TestClassifyErrors(t *testing.T) {
+	terminal := newTerminalError(fmt.Errorf("invalid value"))
+	throttled := errors.NewTooManyRequests("slow down", 10)
+	retryable := fmt.Errorf("connection refused")
+	testCases := []struct {
+		description     string
+		errs            []error
+		expectRetryable int
+		expectTerminal  int
+		expectDelay     time.Duration
+	}{
+		{
+			description: "no errors",
+		},
+		{
+			description:     "unclassified error",
+			errs:            []error{retryable},
+			expectRetryable: 1,
+		},
+		{
+			description:    "wrapped terminal error",
+			errs:           []error{wrapError(terminal, "failed to ensure ingresscontroller")},
+			expectTerminal: 1,
+		},
+		{
+			description: "wrapped throttled API error",
+			errs:        []error{wrapError(throttled, "failed to get service")},
+			expectDelay: 10 * time.Second,
+		},
+		{
+			description:    "aggregate of terminal and throttled errors",
+			errs:           []error{wrapError(utilerrors.NewAggregate([]error{terminal, throttled}), "failed to ensure ingresscontroller")},
+			expectTerminal: 1,
+			expectDelay:    10 * time.Second,
+		},
+		{
+			description:     "aggregate with a retryable error",
+			errs:            []error{utilerrors.NewAggregate([]error{terminal, retryable})},
+			expectRetryable: 1,
+			expectTerminal:  1,
+		},
+		{
+			description:     "terminal error formatted into another error",
+			errs:            []error{fmt.Errorf("failed: %v", terminal)},
+			expectRetryable: 1,
+		},
+	}
+	for _, tc := range testCases {
+		actualRetryable, actualTerminal, actualDelay := classifyErrors(tc.errs)
+		if len(actualRetryable) != tc.expectRetryable {
+			t.Errorf("%q: expected %d retryable errors, got %v", tc.description, tc.expectRetryable, actualRetryable)
+		}
+		if len(actualTerminal) != tc.expectTerminal {
+			t.Errorf("%q: expected %d terminal errors, got %v", tc.description, tc.expectTerminal, actualTerminal)
+		}
+		if actualDelay != tc.expectDelay {
+			t.Errorf("%q: expected delay %v, got %v", tc.description, tc.expectDelay, actualDelay)
+		}
+	}
+}
+
+// TestWrapError verifies that wrapError prefixes the wrapped error's message.
+func TestWrapError(t *testing.T) {
+	err := wrapError(fmt.Errorf("connection refused"), "failed to ensure %s", "default")
+	if expected := "failed to ensure default: connection refused"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+// TestComputeTerminalErrorCondition verifies that the TerminalError condition
+// is set only if there are terminal errors.
+func TestComputeTerminalErrorCondition(t *testing.T) {
+	if condition := computeTerminalErrorCondition(nil); condition != nil {
+		t.Errorf("expected no condition, got %v", condition)
+	}
+	condition := computeTerminalErrorCondition([]error{newTerminalError(fmt.Errorf("foo")), newTerminalError(fmt.Errorf("bar"))})
+	if condition == nil {
+		t.Fatal("expected a condition, got nil")
+	}
+	if condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected status %s, got %s", operatorv1.ConditionTrue, condition.Status)
+	}
+	expectedMessage := "The ingresscontroller cannot be reconciled until its configuration is corrected: foo; bar"
+	if condition.Message != expectedMessage {
+		t.Errorf("expected message %q, got %q", expectedMessage, condition.Message)
+	}
+}
+
+// TestTerminalErrorEvent verifies that a terminal error event is recorded only
+// when the TerminalError condition changes.
+func TestTerminalErrorEvent(t *testing.T) {
+	foo := computeTerminalErrorCondition([]error{newTerminalError(fmt.Errorf("foo"))})
+	bar := computeTerminalErrorCondition([]error{newTerminalError(fmt.Errorf("foo")), newTerminalError(fmt.Errorf("bar"))})
+	testCases := []struct {
+		description string
+		current     *operatorv1.OperatorCondition
+		desired     *operatorv1.OperatorCondition
+		expect      *ingressEvent
+	}{
+		{
+			description: "no terminal errors",
+		},
+		{
+			description: "first terminal error",
+			desired:     foo,
+			expect:      &ingressEvent{"Warning", "TerminalError", foo.Message},
+		},
+		{
+			description: "same terminal error",
+			current:     foo,
+			desired:     foo,
+		},
+		{
+			description: "another terminal error",
+			current:     foo,
+			desired:     bar,
+			expect:      &ingressEvent{"Warning", "TerminalError", bar.Message},
+		},
+		{
+			description: "resolved",
+			current:     bar,
+			expect:      &ingressEvent{"Normal", "TerminalErrorResolved", "The ingresscontroller's terminal errors are resolved"},
+		},
+	}
+	for _, tc := range testCases {
+		actual := terminalErrorEvent(tc.current, tc.desired)
+		if !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%q: expected %+v, got %+v", tc.description, tc.expect, actual)
+		}
+	}
+}
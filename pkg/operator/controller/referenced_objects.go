@@ -0,0 +1,42 @@
+package controller
+
+import (
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ReferencedObjectsIndex is the name of the index over
+	// ingresscontrollers by the secrets and configmaps in the operand
+	// namespace that they reference.  The index keys are built by
+	// ReferencedObjectIndexKey.
+	ReferencedObjectsIndex = "referencedObjects"
+)
+
+// ReferencedObjectIndexKey returns the key in ReferencedObjectsIndex for the
+// secret or configmap with the given name, or the empty string if the object
+// is neither.
+func ReferencedObjectIndexKey(obj runtime.Object, name string) string {
+	switch obj.(type) {
+	case *corev1.Secret:
+		return "secret/" + name
+	case *corev1.ConfigMap:
+		return "configmap/" + name
+	}
+	return ""
+}
+
+// referencedObjectIndexKeys returns the ReferencedObjectsIndex keys of the
+// secrets and configmaps that the given ingresscontroller references.  Unlike
+// the objects that the router deployment mounts, these are known from the
+// ingresscontroller alone, so a change to one of them queues the
+// ingresscontroller even if its router deployment does not exist yet or does
+// not reflect the ingresscontroller's current spec.
+func referencedObjectIndexKeys(ic *operatorv1.IngressController, operandNamespace string) []string {
+	return []string{
+		ReferencedObjectIndexKey(&corev1.Secret{}, RouterEffectiveDefaultCertificateSecretName(ic, operandNamespace).Name),
+	}
+}
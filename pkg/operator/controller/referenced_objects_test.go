@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestReferencedObjectIndexKeys verifies that an ingresscontroller is indexed
+// by its effective default certificate secret.
+func TestReferencedObjectIndexKeys(t *testing.T) {
+	testCases := []struct {
+		description        string
+		defaultCertificate *corev1.LocalObjectReference
+		expect             []string
+	}{
+		{
+			description: "operator-generated default certificate",
+			expect:      []string{"secret/router-certs-default"},
+		},
+		{
+			description:        "custom default certificate",
+			defaultCertificate: &corev1.LocalObjectReference{Name: "custom-cert"},
+			expect:             []string{"secret/custom-cert"},
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: operatorv1.IngressControllerSpec{
+				DefaultCertificate: tc.defaultCertificate,
+			},
+		}
+		if actual := referencedObjectIndexKeys(ic, DefaultOperandNamespace); !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, actual)
+		}
+	}
+	if key := ReferencedObjectIndexKey(&corev1.ConfigMap{}, "foo"); key != "configmap/foo" {
+		t.Errorf("expected configmap/foo, got %q", key)
+	}
+	if key := ReferencedObjectIndexKey(&corev1.Service{}, "foo"); len(key) != 0 {
+		t.Errorf("expected no key for a service, got %q", key)
+	}
+}
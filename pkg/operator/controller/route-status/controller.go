@@ -0,0 +1,175 @@
+// The route-status controller is responsible for removing the status entries
+// that an ingresscontroller's router has added to routes once the router no
+// longer serves those routes, either because the ingresscontroller has been
+// deleted or because its route or namespace selector no longer selects them.
+package routestatus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "route-status-controller"
+)
+
+var log = logf.Logger.WithName(controllerName)
+
+type reconciler struct {
+	client            client.Client
+	operatorNamespace string
+}
+
+// New returns a new controller that removes stale route status entries.  The
+// given client should not be backed by a cache so that the operator does not
+// keep every route in the cluster in memory.
+func New(mgr manager.Manager, cl client.Client, operatorNamespace string) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		client:            cl,
+		operatorNamespace: operatorNamespace,
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+
+	// Queue an ingresscontroller when the controller starts, so that
+	// statuses from ingresscontrollers that were deleted or re-scoped while
+	// the operator was not running are removed, when it is deleted, and
+	// when its selectors change.
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return true },
+		DeleteFunc: func(e event.DeleteEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*operatorv1.IngressController)
+			if !ok {
+				return false
+			}
+			new, ok := e.ObjectNew.(*operatorv1.IngressController)
+			if !ok {
+				return false
+			}
+			return !reflect.DeepEqual(old.Spec.RouteSelector, new.Spec.RouteSelector) ||
+				!reflect.DeepEqual(old.Spec.NamespaceSelector, new.Spec.NamespaceSelector)
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reconcile removes the status entries of the requested ingresscontroller's
+// router from routes that the router no longer serves.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	if request.Namespace != r.operatorNamespace {
+		return reconcile.Result{}, nil
+	}
+
+	ic := &operatorv1.IngressController{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, ic); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("failed to get ingresscontroller %q: %v", request, err)
+		}
+		ic = nil
+	}
+
+	routes := &routev1.RouteList{}
+	if err := r.client.List(context.TODO(), routes); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to list routes: %v", err)
+	}
+
+	var namespaceLabels map[string]labels.Set
+	if ic != nil && ic.Spec.NamespaceSelector != nil {
+		namespaces := &corev1.NamespaceList{}
+		if err := r.client.List(context.TODO(), namespaces); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to list namespaces: %v", err)
+		}
+		namespaceLabels = make(map[string]labels.Set, len(namespaces.Items))
+		for _, ns := range namespaces.Items {
+			namespaceLabels[ns.Name] = labels.Set(ns.Labels)
+		}
+	}
+
+	var errs []error
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		if !hasRouterStatus(route, request.Name) {
+			continue
+		}
+		if ic != nil {
+			selected, err := routeSelected(ic, route, namespaceLabels)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if selected {
+				continue
+			}
+		}
+		updated := route.DeepCopy()
+		updated.Status.Ingress = removeRouterStatus(updated.Status.Ingress, request.Name)
+		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+			if !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to update status of route %s/%s: %v", route.Namespace, route.Name, err))
+			}
+			continue
+		}
+		log.Info("removed stale route status", "namespace", route.Namespace, "name", route.Name, "router", request.Name)
+	}
+	return reconcile.Result{}, utilerrors.NewAggregate(errs)
+}
+
+// hasRouterStatus returns true if the given route has a status entry from the
+// router with the given name.
+func hasRouterStatus(route *routev1.Route, routerName string) bool {
+	for _, ingress := range route.Status.Ingress {
+		if ingress.RouterName == routerName {
+			return true
+		}
+	}
+	return false
+}
+
+// removeRouterStatus returns the given route status entries without those
+// from the router with the given name.
+func removeRouterStatus(ingresses []routev1.RouteIngress, routerName string) []routev1.RouteIngress {
+	var result []routev1.RouteIngress
+	for _, ingress := range ingresses {
+		if ingress.RouterName != routerName {
+			result = append(result, ingress)
+		}
+	}
+	return result
+}
+
+// routeSelected returns true if the given ingresscontroller's route and
+// namespace selectors select the given route.  The given map holds the labels
+// of each namespace and is only needed if the ingresscontroller has a
+// namespace selector.
+func routeSelected(ic *operatorv1.IngressController, route *routev1.Route, namespaceLabels map[string]labels.Set) (bool, error) {
+	return controller.RouteSelected(ic, labels.Set(route.Labels), namespaceLabels[route.Namespace])
+}
@@ -0,0 +1,93 @@
+package routestatus
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestRemoveRouterStatus(t *testing.T) {
+	ingresses := []routev1.RouteIngress{
+		{RouterName: "default", Host: "a.apps.example.com"},
+		{RouterName: "sharded", Host: "a.sharded.example.com"},
+	}
+	route := &routev1.Route{Status: routev1.RouteStatus{Ingress: ingresses}}
+	if !hasRouterStatus(route, "sharded") {
+		t.Errorf("expected route to have a status from router sharded")
+	}
+	if hasRouterStatus(route, "other") {
+		t.Errorf("expected route not to have a status from router other")
+	}
+	expected := []routev1.RouteIngress{{RouterName: "default", Host: "a.apps.example.com"}}
+	if actual := removeRouterStatus(ingresses, "sharded"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := removeRouterStatus(ingresses, "other"); !reflect.DeepEqual(actual, ingresses) {
+		t.Errorf("expected %v, got %v", ingresses, actual)
+	}
+}
+
+func TestRouteSelected(t *testing.T) {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "app",
+			Name:      "web",
+			Labels:    map[string]string{"shard": "a"},
+		},
+	}
+	namespaceLabels := map[string]labels.Set{"app": {"env": "prod"}}
+	testCases := []struct {
+		description       string
+		routeSelector     *metav1.LabelSelector
+		namespaceSelector *metav1.LabelSelector
+		expect            bool
+	}{
+		{
+			description: "no selectors",
+			expect:      true,
+		},
+		{
+			description:   "matching route selector",
+			routeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"shard": "a"}},
+			expect:        true,
+		},
+		{
+			description:   "non-matching route selector",
+			routeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"shard": "b"}},
+			expect:        false,
+		},
+		{
+			description:       "matching namespace selector",
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			expect:            true,
+		},
+		{
+			description:       "non-matching namespace selector",
+			routeSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"shard": "a"}},
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			expect:            false,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "sharded"},
+			Spec: operatorv1.IngressControllerSpec{
+				RouteSelector:     tc.routeSelector,
+				NamespaceSelector: tc.namespaceSelector,
+			},
+		}
+		selected, err := routeSelected(ic, route, namespaceLabels)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if selected != tc.expect {
+			t.Errorf("%s: expected %t, got %t", tc.description, tc.expect, selected)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// The shard-metrics controller is responsible for exporting metrics that
+// describe the load on each ingresscontroller's shard: the routes that the
+// shard selects and admits, the backends and servers of the admitted routes,
+// and the namespaces that the shard selects.  Capacity planning dashboards can
+// use these metrics to show shard saturation without scraping every router.
+// The controller also records the counts in ShardLoads, from which the
+// operator controller computes the ingresscontroller's ScaleLimitsExceeded
+// condition.
+package shardmetrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "shard-metrics-controller"
+
+	// resyncPeriod is how often the metrics of all ingresscontrollers are
+	// recomputed.  Routes, namespaces, and endpoints are not watched so
+	// that the operator does not keep every one in the cluster in memory.
+	resyncPeriod = 5 * time.Minute
+)
+
+// shardsRequest is the only request that the controller reconciles.  Every
+// ingresscontroller event maps to it, so the work queue coalesces the events,
+// and the metrics of all shards are computed from one list each of the routes,
+// namespaces, and endpoints in the cluster.
+var shardsRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "shards"}}
+
+var (
+	log = logf.Logger.WithName(controllerName)
+
+	routesSelected = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_routes_selected",
+		Help: "Number of routes that an ingresscontroller's route and namespace selectors select.",
+	}, []string{"name"})
+	routesAdmitted = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_routes_admitted",
+		Help: "Number of routes that an ingresscontroller's router has admitted.",
+	}, []string{"name"})
+	backends = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_backends",
+		Help: "Number of distinct services that the routes admitted by an ingresscontroller's router refer to.",
+	}, []string{"name"})
+	servers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_servers",
+		Help: "Number of ready endpoint addresses of the services that the routes admitted by an ingresscontroller's router refer to.",
+	}, []string{"name"})
+	namespacesSelected = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_namespaces_selected",
+		Help: "Number of namespaces that an ingresscontroller's namespace selector selects.",
+	}, []string{"name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(routesSelected, routesAdmitted, backends, servers, namespacesSelected)
+}
+
+type reconciler struct {
+	// cache reads ingresscontrollers from the manager's cache.
+	cache client.Reader
+	// client is not backed by a cache.
+	client            client.Client
+	shardLoads        *controller.ShardLoads
+	operatorNamespace string
+
+	// exported is the names of the ingresscontrollers whose metrics are
+	// exported.  The controller reconciles one request at a time, so it
+	// needs no lock.
+	exported sets.String
+}
+
+// New returns a new controller that exports shard metrics for
+// ingresscontrollers.  The given client should not be backed by a cache so
+// that the operator does not keep every route in the cluster in memory.
+func New(mgr manager.Manager, cl client.Client, shardLoads *controller.ShardLoads, operatorNamespace string) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		cache:             mgr.GetClient(),
+		client:            cl,
+		shardLoads:        shardLoads,
+		operatorNamespace: operatorNamespace,
+		exported:          sets.NewString(),
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+	toShards := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			if a.Meta.GetNamespace() != operatorNamespace {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{shardsRequest}
+		}),
+	}
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, toShards, ingressControllerPredicate); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ingressControllerPredicate filters out update events for ingresscontrollers
+// that do not change the spec, which has the route and namespace selectors.  In
+// particular, it filters out the ingresscontrollers' frequent status updates.
+var ingressControllerPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration()
+	},
+}
+
+// shardMetrics are the metrics of an ingresscontroller's shard.
+type shardMetrics struct {
+	routesSelected     int
+	routesAdmitted     int
+	backends           int
+	servers            int
+	namespacesSelected int
+}
+
+// Reconcile computes and exports the metrics of every ingresscontroller and
+// records its shard's load, and removes the metrics and loads of
+// ingresscontrollers that have been deleted.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log.Info("reconciling", "request", request)
+
+	ingresscontrollers := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresscontrollers, client.InNamespace(r.operatorNamespace)); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to list ingresscontrollers: %v", err)
+	}
+	current := sets.NewString()
+	for i := range ingresscontrollers.Items {
+		current.Insert(ingresscontrollers.Items[i].Name)
+	}
+	for _, name := range r.exported.Difference(current).List() {
+		deleteShardMetrics(name)
+		r.shardLoads.Delete(types.NamespacedName{Namespace: r.operatorNamespace, Name: name})
+		r.exported.Delete(name)
+	}
+	if len(ingresscontrollers.Items) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	routes := &routev1.RouteList{}
+	if err := r.client.List(context.TODO(), routes); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to list routes: %v", err)
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := r.client.List(context.TODO(), namespaces); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	endpoints := &corev1.EndpointsList{}
+	if err := r.client.List(context.TODO(), endpoints); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to list endpoints: %v", err)
+	}
+
+	var errs []error
+	for i := range ingresscontrollers.Items {
+		ic := &ingresscontrollers.Items[i]
+		m, err := computeShardMetrics(ic, routes.Items, namespaces.Items, endpoints.Items)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		routesSelected.WithLabelValues(ic.Name).Set(float64(m.routesSelected))
+		routesAdmitted.WithLabelValues(ic.Name).Set(float64(m.routesAdmitted))
+		backends.WithLabelValues(ic.Name).Set(float64(m.backends))
+		servers.WithLabelValues(ic.Name).Set(float64(m.servers))
+		namespacesSelected.WithLabelValues(ic.Name).Set(float64(m.namespacesSelected))
+		r.exported.Insert(ic.Name)
+
+		r.shardLoads.Set(types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, controller.ShardLoad{
+			Routes:   m.routesAdmitted,
+			Backends: m.backends,
+			Servers:  m.servers,
+		})
+	}
+
+	return reconcile.Result{RequeueAfter: resyncPeriod}, utilerrors.NewAggregate(errs)
+}
+
+// deleteShardMetrics removes the metrics of the ingresscontroller with the
+// given name.
+func deleteShardMetrics(name string) {
+	for _, gauge := range []*prometheus.GaugeVec{routesSelected, routesAdmitted, backends, servers, namespacesSelected} {
+		gauge.DeleteLabelValues(name)
+	}
+}
+
+// computeShardMetrics returns the metrics of the given ingresscontroller's
+// shard given every route, namespace, and endpoints resource in the cluster.
+func computeShardMetrics(ic *operatorv1.IngressController, routes []routev1.Route, namespaces []corev1.Namespace, endpoints []corev1.Endpoints) (shardMetrics, error) {
+	var m shardMetrics
+
+	namespaceSelector := labels.Everything()
+	if ic.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(ic.Spec.NamespaceSelector)
+		if err != nil {
+			return m, fmt.Errorf("ingresscontroller %s has an invalid namespace selector: %v", ic.Name, err)
+		}
+		namespaceSelector = selector
+	}
+	routeSelector := labels.Everything()
+	if ic.Spec.RouteSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(ic.Spec.RouteSelector)
+		if err != nil {
+			return m, fmt.Errorf("ingresscontroller %s has an invalid route selector: %v", ic.Name, err)
+		}
+		routeSelector = selector
+	}
+
+	selectedNamespaces := sets.NewString()
+	for _, ns := range namespaces {
+		if namespaceSelector.Matches(labels.Set(ns.Labels)) {
+			selectedNamespaces.Insert(ns.Name)
+		}
+	}
+	m.namespacesSelected = selectedNamespaces.Len()
+
+	services := sets.NewString()
+	for i := range routes {
+		route := &routes[i]
+		if selectedNamespaces.Has(route.Namespace) && routeSelector.Matches(labels.Set(route.Labels)) {
+			m.routesSelected++
+		}
+		if !isAdmittedBy(route, ic.Name) {
+			continue
+		}
+		m.routesAdmitted++
+		for _, backend := range append([]routev1.RouteTargetReference{route.Spec.To}, route.Spec.AlternateBackends...) {
+			if backend.Kind == "Service" && len(backend.Name) != 0 {
+				services.Insert(route.Namespace + "/" + backend.Name)
+			}
+		}
+	}
+	m.backends = services.Len()
+
+	for _, ep := range endpoints {
+		if !services.Has(ep.Namespace + "/" + ep.Name) {
+			continue
+		}
+		addresses := sets.NewString()
+		for _, subset := range ep.Subsets {
+			for _, address := range subset.Addresses {
+				addresses.Insert(address.IP)
+			}
+		}
+		m.servers += addresses.Len()
+	}
+
+	return m, nil
+}
+
+// isAdmittedBy returns true if the router with the given name has admitted the
+// given route.
+func isAdmittedBy(route *routev1.Route, routerName string) bool {
+	for _, ingress := range route.Status.Ingress {
+		if ingress.RouterName != routerName {
+			continue
+		}
+		for _, c := range ingress.Conditions {
+			if c.Type == routev1.RouteAdmitted && c.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package shardmetrics
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestComputeShardMetrics(t *testing.T) {
+	admittedBy := func(routerName string) routev1.RouteStatus {
+		return routev1.RouteStatus{Ingress: []routev1.RouteIngress{{
+			RouterName: routerName,
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:   routev1.RouteAdmitted,
+				Status: corev1.ConditionTrue,
+			}},
+		}}}
+	}
+	route := func(namespace, name string, labels map[string]string, service string, status routev1.RouteStatus) routev1.Route {
+		return routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Spec: routev1.RouteSpec{
+				To: routev1.RouteTargetReference{Kind: "Service", Name: service},
+			},
+			Status: status,
+		}
+	}
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"env": "prod"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: map[string]string{"env": "dev"}}},
+	}
+	withAlternate := route("a", "canary", nil, "svc1", admittedBy("sharded"))
+	withAlternate.Spec.AlternateBackends = []routev1.RouteTargetReference{{Kind: "Service", Name: "svc2"}}
+	routes := []routev1.Route{
+		route("a", "selected", map[string]string{"shard": "x"}, "svc1", admittedBy("sharded")),
+		withAlternate,
+		route("a", "unlabeled", nil, "svc3", admittedBy("default")),
+		route("b", "other-namespace", map[string]string{"shard": "x"}, "svc1", admittedBy("default")),
+	}
+	endpoints := []corev1.Endpoints{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "svc1"},
+			Subsets: []corev1.EndpointSubset{
+				{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}}},
+				{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "svc2"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.3"}}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "svc3"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.4"}}}},
+		},
+	}
+
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "sharded"},
+		Spec: operatorv1.IngressControllerSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			RouteSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"shard": "x"}},
+		},
+	}
+	m, err := computeShardMetrics(ic, routes, namespaces, endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := shardMetrics{routesSelected: 1, routesAdmitted: 2, backends: 2, servers: 3, namespacesSelected: 1}
+	if m != expected {
+		t.Errorf("expected %+v, got %+v", expected, m)
+	}
+
+	ic = &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	m, err = computeShardMetrics(ic, routes, namespaces, endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = shardMetrics{routesSelected: 4, routesAdmitted: 2, backends: 2, servers: 1, namespacesSelected: 2}
+	if m != expected {
+		t.Errorf("expected %+v, got %+v", expected, m)
+	}
+}
+
+func TestIngressControllerPredicate(t *testing.T) {
+	ic := func(generation int64, annotations map[string]string, available operatorv1.ConditionStatus) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Generation: generation, Annotations: annotations},
+			Status: operatorv1.IngressControllerStatus{
+				Conditions: []operatorv1.OperatorCondition{{Type: "Available", Status: available}},
+			},
+		}
+	}
+	testCases := []struct {
+		description string
+		old, new    *operatorv1.IngressController
+		expect      bool
+	}{
+		{"status update", ic(1, nil, operatorv1.ConditionFalse), ic(1, nil, operatorv1.ConditionTrue), false},
+		{"spec update", ic(1, nil, operatorv1.ConditionTrue), ic(2, nil, operatorv1.ConditionTrue), true},
+		{"annotation update", ic(1, nil, operatorv1.ConditionTrue), ic(1, map[string]string{"a": "b"}, operatorv1.ConditionTrue), false},
+	}
+	for _, tc := range testCases {
+		e := event.UpdateEvent{MetaOld: tc.old, ObjectOld: tc.old, MetaNew: tc.new, ObjectNew: tc.new}
+		if actual := ingressControllerPredicate.Update(e); actual != tc.expect {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
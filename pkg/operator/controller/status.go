@@ -1,20 +1,24 @@
 package controller
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
-	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	ingressv1 "github.com/openshift/cluster-ingress-operator/pkg/api/v1"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -25,6 +29,7 @@ const (
 
 	OperatorVersionName          = "operator"
 	IngressControllerVersionName = "ingress-controller"
+	CanaryImageVersionName       = "canary-server"
 	UnknownVersionValue          = "unknown"
 
 	ingressesEqualConditionMessage = "desired and current number of IngressControllers are equal"
@@ -33,12 +38,13 @@ const (
 // syncOperatorStatus computes the operator's current status and therefrom
 // creates or updates the ClusterOperator resource for the operator.
 func (r *reconciler) syncOperatorStatus() error {
-	ns := manifests.RouterNamespace()
+	r.operatorStatusLock.Lock()
+	defer r.operatorStatusLock.Unlock()
 
 	co := &configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: IngressClusterOperatorName}}
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: co.Name}, co); err != nil {
 		if errors.IsNotFound(err) {
-			initializeClusterOperator(co, ns.Name)
+			initializeClusterOperator(co, r.Config.OperandNamespace)
 			if err := r.client.Create(context.TODO(), co); err != nil {
 				return fmt.Errorf("failed to create clusteroperator %s: %v", co.Name, err)
 			}
@@ -47,20 +53,37 @@ func (r *reconciler) syncOperatorStatus() error {
 			return fmt.Errorf("failed to get clusteroperator %s: %v", co.Name, err)
 		}
 	}
+	original := co.DeepCopy()
 	oldStatus := co.Status.DeepCopy()
 
-	ingresses, ns, err := r.getOperatorState(ns.Name)
+	ingresses, ns, err := r.getOperatorState(r.Config.OperandNamespace)
 	if err != nil {
 		return fmt.Errorf("failed to get operator state: %v", err)
 	}
 	allIngressesAvailable := checkAllIngressesAvailable(ingresses)
+	var upgradeBlockers []upgradeBlocker
+	if blocker, err := r.computeUnmanagedLoadBalancerAnnotationsBlocker(ingresses); err != nil {
+		return fmt.Errorf("failed to compute upgrade blockers: %v", err)
+	} else if blocker != nil {
+		upgradeBlockers = append(upgradeBlockers, *blocker)
+	}
 
-	co.Status.Versions = r.computeOperatorStatusVersions(oldStatus.Versions, allIngressesAvailable)
+	canaryRolledOut, err := r.canaryRolledOut()
+	if err != nil {
+		return err
+	}
+	co.Status.Versions = r.computeOperatorStatusVersions(oldStatus.Versions, allIngressesAvailable, ingresses, canaryRolledOut)
 	co.Status.Conditions = r.computeOperatorStatusConditions(oldStatus.Conditions,
-		ns, allIngressesAvailable, oldStatus.Versions, co.Status.Versions)
+		ns, allIngressesAvailable, ingresses, oldStatus.Versions, co.Status.Versions, upgradeBlockers)
+	extension, err := computeOperatorStatusExtension(r.FeatureGates)
+	if err != nil {
+		return err
+	}
+	co.Status.Extension = extension
+	co.Status.RelatedObjects = computeRelatedObjects(r.Config.Namespace, r.Config.OperandNamespace, ingresses)
 
 	if !operatorStatusesEqual(*oldStatus, co.Status) {
-		if err := r.client.Status().Update(context.TODO(), co); err != nil {
+		if err := r.client.PatchStatus(context.TODO(), co, client.MergeFrom(original)); err != nil {
 			return fmt.Errorf("failed to update clusteroperator %s: %v", co.Name, err)
 		}
 	}
@@ -93,6 +116,10 @@ func initializeClusterOperator(co *configv1.ClusterOperator, nsName string) {
 			Type:   configv1.OperatorAvailable,
 			Status: configv1.ConditionUnknown,
 		},
+		{
+			Type:   configv1.OperatorUpgradeable,
+			Status: configv1.ConditionUnknown,
+		},
 	}
 	co.Status.RelatedObjects = []configv1.ObjectReference{
 		{
@@ -106,6 +133,39 @@ func initializeClusterOperator(co *configv1.ClusterOperator, nsName string) {
 	}
 }
 
+// computeRelatedObjects returns the clusteroperator's related objects: the
+// operator and operand namespaces, the ingresscontrollers, and the DNSRecords,
+// and the related objects of each ingresscontroller.  must-gather and oc adm
+// inspect follow these references, so listing each ingresscontroller's
+// operands makes them collect the full chain from the ingresscontroller to its
+// load balancer and DNS record.
+func computeRelatedObjects(operatorNamespace, operandNamespace string, ingresses []operatorv1.IngressController) []configv1.ObjectReference {
+	relatedObjects := []configv1.ObjectReference{
+		{
+			Resource: "namespaces",
+			Name:     operatorNamespace,
+		},
+		{
+			Resource: "namespaces",
+			Name:     operandNamespace,
+		},
+		{
+			Group:     operatorv1.GroupName,
+			Resource:  "ingresscontrollers",
+			Namespace: operatorNamespace,
+		},
+		{
+			Group:     ingressv1.GroupName,
+			Resource:  "dnsrecords",
+			Namespace: operatorNamespace,
+		},
+	}
+	for i := range ingresses {
+		relatedObjects = append(relatedObjects, ingressControllerRelatedObjects(&ingresses[i], operandNamespace)...)
+	}
+	return relatedObjects
+}
+
 // getOperatorState gets and returns the resources necessary to compute the
 // operator's current state.
 func (r *reconciler) getOperatorState(nsName string) ([]operatorv1.IngressController, *corev1.Namespace, error) {
@@ -126,30 +186,115 @@ func (r *reconciler) getOperatorState(nsName string) ([]operatorv1.IngressContro
 }
 
 // computeOperatorStatusVersions computes the operator's current versions.
-func (r *reconciler) computeOperatorStatusVersions(oldVersions []configv1.OperandVersion, allIngressesAvailable bool) []configv1.OperandVersion {
+// While the routers of any of the given ingresscontrollers are kept on an
+// earlier image by a router image policy, the earlier ingress-controller
+// version is reported because the new one is not running.
+func (r *reconciler) computeOperatorStatusVersions(oldVersions []configv1.OperandVersion, allIngressesAvailable bool, ingresses []operatorv1.IngressController, canaryRolledOut bool) []configv1.OperandVersion {
 	// We need to report old version until the operator fully transitions to the new version.
 	// https://github.com/openshift/cluster-version-operator/blob/master/docs/dev/clusteroperator.md#version-reporting-during-an-upgrade
 	if !allIngressesAvailable {
 		return oldVersions
 	}
 
-	return []configv1.OperandVersion{
+	ingressControllerVersion := r.IngressControllerImage
+	if len(routerImagePinnedIngressControllers(ingresses)) != 0 {
+		ingressControllerVersion = UnknownVersionValue
+		for _, v := range oldVersions {
+			if v.Name == IngressControllerVersionName {
+				ingressControllerVersion = v.Version
+			}
+		}
+	}
+
+	versions := []configv1.OperandVersion{
 		{
 			Name:    OperatorVersionName,
 			Version: r.OperatorReleaseVersion,
 		},
 		{
 			Name:    IngressControllerVersionName,
-			Version: r.IngressControllerImage,
+			Version: ingressControllerVersion,
 		},
 	}
+	if len(r.CanaryImage) == 0 {
+		return versions
+	}
+	// Like the ingress-controller version, the canary version moves to
+	// the new image only once the canary daemonset has rolled it out.
+	canaryVersion := r.CanaryImage
+	if !canaryRolledOut {
+		canaryVersion = UnknownVersionValue
+		for _, v := range oldVersions {
+			if v.Name == CanaryImageVersionName {
+				canaryVersion = v.Version
+			}
+		}
+	}
+	return append(versions, configv1.OperandVersion{
+		Name:    CanaryImageVersionName,
+		Version: canaryVersion,
+	})
+}
+
+// routerImagePinnedIngressControllers returns the names of the
+// ingresscontrollers in the given list whose routers are kept on an earlier
+// image because the operator's router image violates their router image
+// policy.
+func routerImagePinnedIngressControllers(ingresses []operatorv1.IngressController) []string {
+	names := []string{}
+	for i := range ingresses {
+		condition := findIngressStatusCondition(ingresses[i].Status.Conditions, RouterImageConditionType)
+		if condition != nil && condition.Reason == RouterImagePinnedReason {
+			names = append(names, ingresses[i].Name)
+		}
+	}
+	return names
 }
 
-// computeOperatorStatusConditions computes the operator's current state.
+// routerImagePolicyViolations returns a description of each ingresscontroller
+// in the given list whose router image policy the operator's router image
+// violates.
+func routerImagePolicyViolations(ingresses []operatorv1.IngressController) []string {
+	messages := []string{}
+	for i := range ingresses {
+		condition := findIngressStatusCondition(ingresses[i].Status.Conditions, RouterImageConditionType)
+		if condition != nil && condition.Status == operatorv1.ConditionFalse {
+			messages = append(messages, fmt.Sprintf("ingresscontroller %q: %s", ingresses[i].Name, condition.Message))
+		}
+	}
+	return messages
+}
+
+// operatorStatusExtension is the operator-specific status that is reported in
+// the clusteroperator's status.extension field.
+type operatorStatusExtension struct {
+	// EnabledFeatureGates are the names of the enabled experimental
+	// features, so that differences in behavior between clusters can be
+	// explained.
+	EnabledFeatureGates []string `json:"enabledFeatureGates"`
+}
+
+// computeOperatorStatusExtension computes the clusteroperator's status
+// extension.
+func computeOperatorStatusExtension(featureGates []string) (runtime.RawExtension, error) {
+	extension := operatorStatusExtension{EnabledFeatureGates: featureGates}
+	if extension.EnabledFeatureGates == nil {
+		extension.EnabledFeatureGates = []string{}
+	}
+	raw, err := json.Marshal(extension)
+	if err != nil {
+		return runtime.RawExtension{}, fmt.Errorf("failed to marshal clusteroperator status extension: %v", err)
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+// computeOperatorStatusConditions computes the operator's current state.  The
+// given upgrade blockers are in addition to unmanaged ingresscontrollers, which
+// always block upgrades.
 func (r *reconciler) computeOperatorStatusConditions(oldConditions []configv1.ClusterOperatorStatusCondition,
-	ns *corev1.Namespace, allIngressesAvailable bool,
-	oldVersions, curVersions []configv1.OperandVersion) []configv1.ClusterOperatorStatusCondition {
-	var oldDegradedCondition, oldProgressingCondition, oldAvailableCondition *configv1.ClusterOperatorStatusCondition
+	ns *corev1.Namespace, allIngressesAvailable bool, ingresses []operatorv1.IngressController,
+	oldVersions, curVersions []configv1.OperandVersion, upgradeBlockers []upgradeBlocker) []configv1.ClusterOperatorStatusCondition {
+	var oldDegradedCondition, oldProgressingCondition, oldAvailableCondition, oldUpgradeableCondition *configv1.ClusterOperatorStatusCondition
 	for i := range oldConditions {
 		switch oldConditions[i].Type {
 		case configv1.OperatorDegraded:
@@ -158,24 +303,33 @@ func (r *reconciler) computeOperatorStatusConditions(oldConditions []configv1.Cl
 			oldProgressingCondition = &oldConditions[i]
 		case configv1.OperatorAvailable:
 			oldAvailableCondition = &oldConditions[i]
+		case configv1.OperatorUpgradeable:
+			oldUpgradeableCondition = &oldConditions[i]
 		}
 	}
 
+	if blocker := unmanagedIngressControllersBlocker(unmanagedIngressControllers(ingresses)); blocker != nil {
+		upgradeBlockers = append([]upgradeBlocker{*blocker}, upgradeBlockers...)
+	}
+
 	conditions := []configv1.ClusterOperatorStatusCondition{
-		computeOperatorDegradedCondition(oldDegradedCondition, ns),
-		r.computeOperatorProgressingCondition(oldProgressingCondition, allIngressesAvailable, oldVersions, curVersions),
+		computeOperatorDegradedCondition(oldDegradedCondition, ns, stuckIngressControllers(ingresses, time.Now()), endpointPublishingDegradedIngressControllers(ingresses), routerImagePolicyViolations(ingresses)),
+		r.computeOperatorProgressingCondition(oldProgressingCondition, allIngressesAvailable, ingresses, oldVersions, curVersions),
 		computeOperatorAvailableCondition(oldAvailableCondition, allIngressesAvailable),
+		computeOperatorUpgradeableCondition(oldUpgradeableCondition, upgradeBlockers),
 	}
 
 	return conditions
 }
 
-// checkAllIngressesAvailable checks if all the ingress controllers are available.
+// checkAllIngressesAvailable checks if all the ingress controllers are
+// available.  An ingress controller that is intentionally scaled to zero
+// replicas is dormant and does not make the operator unavailable.
 func checkAllIngressesAvailable(ingresses []operatorv1.IngressController) bool {
 	for _, ing := range ingresses {
 		available := false
 		for _, c := range ing.Status.Conditions {
-			if c.Type == operatorv1.IngressControllerAvailableConditionType && c.Status == operatorv1.ConditionTrue {
+			if c.Type == operatorv1.IngressControllerAvailableConditionType && (c.Status == operatorv1.ConditionTrue || c.Reason == ZeroReplicasReason) {
 				available = true
 				break
 			}
@@ -188,9 +342,36 @@ func checkAllIngressesAvailable(ingresses []operatorv1.IngressController) bool {
 	return (len(ingresses) != 0)
 }
 
-// computeOperatorDegradedCondition computes the operator's current Degraded status state.
+// stuckIngressControllers returns the names of the ingresscontrollers in the
+// given list whose deletion is stuck.
+func stuckIngressControllers(ingresses []operatorv1.IngressController, now time.Time) []string {
+	names := []string{}
+	for i := range ingresses {
+		if isIngressDeletionStuck(&ingresses[i], now) {
+			names = append(names, ingresses[i].Name)
+		}
+	}
+	return names
+}
+
+// endpointPublishingDegradedIngressControllers returns a description of each
+// ingresscontroller in the given list whose load balancer or wildcard DNS
+// record has failed to converge.
+func endpointPublishingDegradedIngressControllers(ingresses []operatorv1.IngressController) []string {
+	messages := []string{}
+	for i := range ingresses {
+		condition := findIngressStatusCondition(ingresses[i].Status.Conditions, EndpointPublishingDegradedConditionType)
+		if condition != nil && condition.Status == operatorv1.ConditionTrue {
+			messages = append(messages, fmt.Sprintf("ingresscontroller %q: %s.", ingresses[i].Name, condition.Message))
+		}
+	}
+	return messages
+}
+
+// computeOperatorDegradedCondition computes the operator's current Degraded
+// status state.
 func computeOperatorDegradedCondition(oldCondition *configv1.ClusterOperatorStatusCondition,
-	ns *corev1.Namespace) configv1.ClusterOperatorStatusCondition {
+	ns *corev1.Namespace, stuckIngresses, endpointPublishingDegraded, routerImagePolicyViolations []string) configv1.ClusterOperatorStatusCondition {
 	degradedCondition := configv1.ClusterOperatorStatusCondition{
 		Type: configv1.OperatorDegraded,
 	}
@@ -198,6 +379,19 @@ func computeOperatorDegradedCondition(oldCondition *configv1.ClusterOperatorStat
 		degradedCondition.Status = configv1.ConditionTrue
 		degradedCondition.Reason = "NoNamespace"
 		degradedCondition.Message = "operand namespace does not exist"
+	} else if len(stuckIngresses) > 0 {
+		degradedCondition.Status = configv1.ConditionTrue
+		degradedCondition.Reason = "IngressControllerDeletionStuck"
+		degradedCondition.Message = fmt.Sprintf("Finalization of some ingress controllers has been failing for more than %s: %s. If the DNS provider is permanently unreachable, annotate them with %s=true to skip DNS finalization.",
+			ingressControllerDeletionTimeout, strings.Join(stuckIngresses, ", "), SkipDNSFinalizationAnnotation)
+	} else if len(endpointPublishingDegraded) > 0 {
+		degradedCondition.Status = configv1.ConditionTrue
+		degradedCondition.Reason = "IngressControllerEndpointPublishingDegraded"
+		degradedCondition.Message = strings.Join(endpointPublishingDegraded, "\n")
+	} else if len(routerImagePolicyViolations) > 0 {
+		degradedCondition.Status = configv1.ConditionTrue
+		degradedCondition.Reason = "RouterImagePolicyViolation"
+		degradedCondition.Message = strings.Join(routerImagePolicyViolations, "\n")
 	} else {
 		degradedCondition.Status = configv1.ConditionFalse
 		degradedCondition.Message = "operand namespace exists"
@@ -207,12 +401,12 @@ func computeOperatorDegradedCondition(oldCondition *configv1.ClusterOperatorStat
 	return degradedCondition
 }
 
-// computeOperatorProgressingCondition computes the operator's current Progressing status state.
+// computeOperatorProgressingCondition computes the operator's current
+// Progressing status state.  The operator is progressing while any
+// ingresscontroller is progressing, and the message repeats what each such
+// ingresscontroller is waiting for.
 func (r *reconciler) computeOperatorProgressingCondition(oldCondition *configv1.ClusterOperatorStatusCondition,
-	allIngressesAvailable bool, oldVersions, curVersions []configv1.OperandVersion) configv1.ClusterOperatorStatusCondition {
-	// TODO: Update progressingCondition when an ingresscontroller
-	//       progressing condition is created. The Operator's condition
-	//       should be derived from the ingresscontroller's condition.
+	allIngressesAvailable bool, ingresses []operatorv1.IngressController, oldVersions, curVersions []configv1.OperandVersion) configv1.ClusterOperatorStatusCondition {
 	progressingCondition := configv1.ClusterOperatorStatusCondition{
 		Type: configv1.OperatorProgressing,
 	}
@@ -225,6 +419,14 @@ func (r *reconciler) computeOperatorProgressingCondition(oldCondition *configv1.
 		progressing = true
 	}
 
+	for i := range ingresses {
+		condition := findIngressStatusCondition(ingresses[i].Status.Conditions, operatorv1.OperatorStatusTypeProgressing)
+		if condition != nil && condition.Status == operatorv1.ConditionTrue {
+			messages = append(messages, fmt.Sprintf("ingresscontroller %q is progressing: %s.", ingresses[i].Name, condition.Message))
+			progressing = true
+		}
+	}
+
 	oldVersionsMap := make(map[string]string)
 	for _, opv := range oldVersions {
 		oldVersionsMap[opv.Name] = opv.Version
@@ -243,6 +445,14 @@ func (r *reconciler) computeOperatorProgressingCondition(oldCondition *configv1.
 		case IngressControllerVersionName:
 			if opv.Version != r.IngressControllerImage {
 				messages = append(messages, fmt.Sprintf("Moving to ingress-controller image version %q.", r.IngressControllerImage))
+				if pinned := routerImagePinnedIngressControllers(ingresses); len(pinned) != 0 {
+					messages = append(messages, fmt.Sprintf("The routers of ingresscontrollers %s are kept on an earlier image by their router image policy.", strings.Join(pinned, ", ")))
+				}
+				progressing = true
+			}
+		case CanaryImageVersionName:
+			if opv.Version != r.CanaryImage {
+				messages = append(messages, fmt.Sprintf("Moving to canary image version %q.", r.CanaryImage))
 				progressing = true
 			}
 		}
@@ -283,6 +493,37 @@ func computeOperatorAvailableCondition(oldCondition *configv1.ClusterOperatorSta
 	return availableCondition
 }
 
+// computeOperatorUpgradeableCondition computes the operator's current
+// Upgradeable status state.  The operator is not upgradeable while there is
+// any upgrade blocker.  The reason is the blocker's reason if there is one
+// blocker, and the message lists every blocker.
+func computeOperatorUpgradeableCondition(oldCondition *configv1.ClusterOperatorStatusCondition,
+	upgradeBlockers []upgradeBlocker) configv1.ClusterOperatorStatusCondition {
+	upgradeableCondition := configv1.ClusterOperatorStatusCondition{
+		Type: configv1.OperatorUpgradeable,
+	}
+
+	switch len(upgradeBlockers) {
+	case 0:
+		upgradeableCondition.Status = configv1.ConditionTrue
+	case 1:
+		upgradeableCondition.Status = configv1.ConditionFalse
+		upgradeableCondition.Reason = upgradeBlockers[0].reason
+		upgradeableCondition.Message = upgradeBlockers[0].message
+	default:
+		var messages []string
+		for _, blocker := range upgradeBlockers {
+			messages = append(messages, blocker.message)
+		}
+		upgradeableCondition.Status = configv1.ConditionFalse
+		upgradeableCondition.Reason = "MultipleUpgradeBlockers"
+		upgradeableCondition.Message = strings.Join(messages, "; ")
+	}
+
+	setLastTransitionTime(&upgradeableCondition, oldCondition)
+	return upgradeableCondition
+}
+
 // setLastTransitionTime sets LastTransitionTime for the given condition.
 // If the condition has changed, it will assign a new timestamp otherwise keeps the old timestamp.
 func setLastTransitionTime(condition, oldCondition *configv1.ClusterOperatorStatusCondition) {
@@ -309,7 +550,9 @@ func operatorStatusesEqual(a, b configv1.ClusterOperatorStatus) bool {
 
 	relatedCmpOpts := []cmp.Option{
 		cmpopts.EquateEmpty(),
-		cmpopts.SortSlices(func(a, b configv1.ObjectReference) bool { return a.Name < b.Name }),
+		cmpopts.SortSlices(func(a, b configv1.ObjectReference) bool {
+			return fmt.Sprintf("%s/%s/%s/%s", a.Group, a.Resource, a.Namespace, a.Name) < fmt.Sprintf("%s/%s/%s/%s", b.Group, b.Resource, b.Namespace, b.Name)
+		}),
 	}
 	if !cmp.Equal(a.RelatedObjects, b.RelatedObjects, relatedCmpOpts...) {
 		return false
@@ -323,5 +566,9 @@ func operatorStatusesEqual(a, b configv1.ClusterOperatorStatus) bool {
 		return false
 	}
 
+	if !bytes.Equal(a.Extension.Raw, b.Extension.Raw) {
+		return false
+	}
+
 	return true
 }
@@ -2,19 +2,22 @@ package controller
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func TestComputeOperatorStatusConditions(t *testing.T) {
 	type conditions struct {
-		degraded, progressing, available bool
+		degraded, progressing, available, upgradeable bool
 	}
 	type versions struct {
 		operator, operand string
@@ -24,6 +27,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 		description           string
 		noNamespace           bool
 		allIngressesAvailable bool
+		ingresses             []operatorv1.IngressController
 		reportedVersions      versions
 		oldVersions           versions
 		curVersions           versions
@@ -33,16 +37,36 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			description:           "no operand namespace",
 			noNamespace:           true,
 			allIngressesAvailable: true,
-			expectedConditions:    conditions{true, false, true},
+			expectedConditions:    conditions{true, false, true, true},
 		},
 		{
 			description:           "all ingress controllers are available",
 			allIngressesAvailable: true,
-			expectedConditions:    conditions{false, false, true},
+			expectedConditions:    conditions{false, false, true, true},
 		},
 		{
 			description:        "all ingress controllers are not available",
-			expectedConditions: conditions{false, true, false},
+			expectedConditions: conditions{false, true, false, true},
+		},
+		{
+			description:           "some ingress controllers are unmanaged",
+			allIngressesAvailable: true,
+			ingresses: []operatorv1.IngressController{
+				{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: map[string]string{ManagementStateAnnotation: "Unmanaged"}}},
+			},
+			expectedConditions: conditions{false, false, true, false},
+		},
+		{
+			description:           "ingress controller deletion is stuck",
+			allIngressesAvailable: true,
+			ingresses: []operatorv1.IngressController{
+				{ObjectMeta: metav1.ObjectMeta{
+					Name:              "shard",
+					DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-2 * ingressControllerDeletionTimeout)},
+					Finalizers:        []string{IngressControllerFinalizer},
+				}},
+			},
+			expectedConditions: conditions{true, false, true, true},
 		},
 		{
 			description:           "versions match",
@@ -50,7 +74,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v1", "ic-v1"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v1", "ic-v1"},
-			expectedConditions:    conditions{false, false, true},
+			expectedConditions:    conditions{false, false, true, true},
 		},
 		{
 			description:           "operator upgrade in progress",
@@ -58,7 +82,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v1", "ic-v1"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v2", "ic-v1"},
-			expectedConditions:    conditions{false, true, true},
+			expectedConditions:    conditions{false, true, true, true},
 		},
 		{
 			description:           "operand upgrade in progress",
@@ -66,7 +90,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v1", "ic-v1"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v1", "ic-v2"},
-			expectedConditions:    conditions{false, true, true},
+			expectedConditions:    conditions{false, true, true, true},
 		},
 		{
 			description:           "operator and operand upgrade in progress",
@@ -74,7 +98,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v1", "ic-v1"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v2", "ic-v2"},
-			expectedConditions:    conditions{false, true, true},
+			expectedConditions:    conditions{false, true, true, true},
 		},
 		{
 			description:           "operator upgrade done",
@@ -82,7 +106,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v2", "ic-v1"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v2", "ic-v1"},
-			expectedConditions:    conditions{false, false, true},
+			expectedConditions:    conditions{false, false, true, true},
 		},
 		{
 			description:           "operand upgrade done",
@@ -90,7 +114,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v1", "ic-v2"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v1", "ic-v2"},
-			expectedConditions:    conditions{false, false, true},
+			expectedConditions:    conditions{false, false, true, true},
 		},
 		{
 			description:           "operator and operand upgrade done",
@@ -98,7 +122,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v2", "ic-v2"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v2", "ic-v2"},
-			expectedConditions:    conditions{false, false, true},
+			expectedConditions:    conditions{false, false, true, true},
 		},
 		{
 			description:           "operator upgrade in progress, operand upgrade done",
@@ -106,7 +130,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 			reportedVersions:      versions{"v2", "ic-v1"},
 			oldVersions:           versions{"v1", "ic-v1"},
 			curVersions:           versions{"v2", "ic-v2"},
-			expectedConditions:    conditions{false, true, true},
+			expectedConditions:    conditions{false, true, true, true},
 		},
 	}
 
@@ -156,6 +180,10 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 				Type:   configv1.OperatorAvailable,
 				Status: configv1.ConditionFalse,
 			},
+			{
+				Type:   configv1.OperatorUpgradeable,
+				Status: configv1.ConditionFalse,
+			},
 		}
 		if tc.expectedConditions.degraded {
 			expectedConditions[0].Status = configv1.ConditionTrue
@@ -166,9 +194,12 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 		if tc.expectedConditions.available {
 			expectedConditions[2].Status = configv1.ConditionTrue
 		}
+		if tc.expectedConditions.upgradeable {
+			expectedConditions[3].Status = configv1.ConditionTrue
+		}
 
 		conditions := r.computeOperatorStatusConditions([]configv1.ClusterOperatorStatusCondition{},
-			namespace, tc.allIngressesAvailable, oldVersions, reportedVersions)
+			namespace, tc.allIngressesAvailable, tc.ingresses, oldVersions, reportedVersions, nil)
 		conditionsCmpOpts := []cmp.Option{
 			cmpopts.IgnoreFields(configv1.ClusterOperatorStatusCondition{}, "LastTransitionTime", "Reason", "Message"),
 			cmpopts.EquateEmpty(),
@@ -403,6 +434,16 @@ func TestOperatorStatusesEqual(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "extension changed",
+			expected:    false,
+			a: configv1.ClusterOperatorStatus{
+				Extension: runtime.RawExtension{Raw: []byte(`{"enabledFeatureGates":[]}`)},
+			},
+			b: configv1.ClusterOperatorStatus{
+				Extension: runtime.RawExtension{Raw: []byte(`{"enabledFeatureGates":["GatewayAPI"]}`)},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -423,6 +464,7 @@ func TestComputeOperatorStatusVersions(t *testing.T) {
 		oldVersions           versions
 		curVersions           versions
 		allIngressesAvailable bool
+		ingresses             []operatorv1.IngressController
 		expectedVersions      versions
 	}{
 		{
@@ -471,6 +513,23 @@ func TestComputeOperatorStatusVersions(t *testing.T) {
 			allIngressesAvailable: true,
 			expectedVersions:      versions{"v1", "ic-v2"},
 		},
+		{
+			description:           "update ingress controller image, routers pinned by the router image policy",
+			oldVersions:           versions{"v1", "ic-v1"},
+			curVersions:           versions{"v2", "ic-v2"},
+			allIngressesAvailable: true,
+			ingresses: []operatorv1.IngressController{{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Status: operatorv1.IngressControllerStatus{
+					Conditions: []operatorv1.OperatorCondition{{
+						Type:   RouterImageConditionType,
+						Status: operatorv1.ConditionFalse,
+						Reason: RouterImagePinnedReason,
+					}},
+				},
+			}},
+			expectedVersions: versions{"v2", "ic-v1"},
+		},
 		{
 			description:      "update operator and ingress controller image, operator is not available",
 			oldVersions:      versions{"v1", "ic-v1"},
@@ -519,7 +578,7 @@ func TestComputeOperatorStatusVersions(t *testing.T) {
 				IngressControllerImage: tc.curVersions.operand,
 			},
 		}
-		versions := r.computeOperatorStatusVersions(oldVersions, tc.allIngressesAvailable)
+		versions := r.computeOperatorStatusVersions(oldVersions, tc.allIngressesAvailable, tc.ingresses, false)
 		versionsCmpOpts := []cmp.Option{
 			cmpopts.EquateEmpty(),
 			cmpopts.SortSlices(func(a, b configv1.OperandVersion) bool { return a.Name < b.Name }),
@@ -529,3 +588,241 @@ func TestComputeOperatorStatusVersions(t *testing.T) {
 		}
 	}
 }
+
+// TestComputeOperatorStatusVersionsWithCanary verifies that the canary version
+// moves to the new canary image once the canary daemonset has rolled it out and
+// that the operator is progressing until then.
+func TestComputeOperatorStatusVersionsWithCanary(t *testing.T) {
+	r := &reconciler{
+		Config: Config{
+			OperatorReleaseVersion: "v2",
+			IngressControllerImage: "ic-v2",
+			CanaryImage:            "canary-v2",
+		},
+	}
+	oldVersions := []configv1.OperandVersion{
+		{Name: OperatorVersionName, Version: "v1"},
+		{Name: IngressControllerVersionName, Version: "ic-v1"},
+		{Name: CanaryImageVersionName, Version: "canary-v1"},
+	}
+	testCases := []struct {
+		description     string
+		canaryRolledOut bool
+		expectCanary    string
+		expectProgress  configv1.ConditionStatus
+	}{
+		{
+			description:     "canary rolling out",
+			canaryRolledOut: false,
+			expectCanary:    "canary-v1",
+			expectProgress:  configv1.ConditionTrue,
+		},
+		{
+			description:     "canary rolled out",
+			canaryRolledOut: true,
+			expectCanary:    "canary-v2",
+			expectProgress:  configv1.ConditionFalse,
+		},
+	}
+	for _, tc := range testCases {
+		versions := r.computeOperatorStatusVersions(oldVersions, true, nil, tc.canaryRolledOut)
+		expected := []configv1.OperandVersion{
+			{Name: OperatorVersionName, Version: "v2"},
+			{Name: IngressControllerVersionName, Version: "ic-v2"},
+			{Name: CanaryImageVersionName, Version: tc.expectCanary},
+		}
+		if !cmp.Equal(versions, expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, expected, versions)
+		}
+		condition := r.computeOperatorProgressingCondition(nil, true, nil, oldVersions, versions)
+		if condition.Status != tc.expectProgress {
+			t.Errorf("%q: expected Progressing=%s, got %s: %s", tc.description, tc.expectProgress, condition.Status, condition.Message)
+		}
+	}
+}
+
+// TestComputeOperatorProgressingConditionFromIngressControllers verifies that
+// the operator is progressing while an ingresscontroller is progressing.
+func TestComputeOperatorProgressingConditionFromIngressControllers(t *testing.T) {
+	r := &reconciler{Config: Config{OperatorReleaseVersion: "v1", IngressControllerImage: "ic-v1"}}
+	versions := []configv1.OperandVersion{
+		{Name: OperatorVersionName, Version: "v1"},
+		{Name: IngressControllerVersionName, Version: "ic-v1"},
+	}
+	ingresses := []operatorv1.IngressController{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				Conditions: []operatorv1.OperatorCondition{{
+					Type:   operatorv1.OperatorStatusTypeProgressing,
+					Status: operatorv1.ConditionFalse,
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "sharded"},
+			Status: operatorv1.IngressControllerStatus{
+				Conditions: []operatorv1.OperatorCondition{{
+					Type:    operatorv1.OperatorStatusTypeProgressing,
+					Status:  operatorv1.ConditionTrue,
+					Message: "The following resources are still converging: wildcard DNS record: not yet created",
+				}},
+			},
+		},
+	}
+	condition := r.computeOperatorProgressingCondition(nil, true, ingresses[:1], versions, versions)
+	if condition.Status != configv1.ConditionFalse {
+		t.Errorf("expected Progressing=False, got %v: %s", condition.Status, condition.Message)
+	}
+	condition = r.computeOperatorProgressingCondition(nil, true, ingresses, versions, versions)
+	expect := `ingresscontroller "sharded" is progressing: The following resources are still converging: wildcard DNS record: not yet created.`
+	if condition.Status != configv1.ConditionTrue || condition.Message != expect {
+		t.Errorf("expected Progressing=True with message %q, got %v with message %q", expect, condition.Status, condition.Message)
+	}
+}
+
+// TestComputeOperatorDegradedConditionFromIngressControllers verifies that the
+// operator is degraded while an ingresscontroller's load balancer or DNS has
+// failed to converge or its router image policy is violated.
+func TestComputeOperatorDegradedConditionFromIngressControllers(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}}
+	ingresses := []operatorv1.IngressController{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				Conditions: []operatorv1.OperatorCondition{{
+					Type:   EndpointPublishingDegradedConditionType,
+					Status: operatorv1.ConditionFalse,
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "sharded"},
+			Status: operatorv1.IngressControllerStatus{
+				Conditions: []operatorv1.OperatorCondition{{
+					Type:    EndpointPublishingDegradedConditionType,
+					Status:  operatorv1.ConditionTrue,
+					Message: "The following resources have not converged: wildcard DNS record: not published to zones public for more than 10m0s",
+				}},
+			},
+		},
+	}
+	condition := computeOperatorDegradedCondition(nil, namespace, nil, endpointPublishingDegradedIngressControllers(ingresses[:1]), nil)
+	if condition.Status != configv1.ConditionFalse {
+		t.Errorf("expected Degraded=False, got %v: %s", condition.Status, condition.Message)
+	}
+	condition = computeOperatorDegradedCondition(nil, namespace, nil, endpointPublishingDegradedIngressControllers(ingresses), nil)
+	expect := `ingresscontroller "sharded": The following resources have not converged: wildcard DNS record: not published to zones public for more than 10m0s.`
+	if condition.Status != configv1.ConditionTrue || condition.Message != expect {
+		t.Errorf("expected Degraded=True with message %q, got %v with message %q", expect, condition.Status, condition.Message)
+	}
+
+	ingresses[0].Status.Conditions = append(ingresses[0].Status.Conditions, operatorv1.OperatorCondition{
+		Type:    RouterImageConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  RouterImagePinnedReason,
+		Message: "The router image violates the router image policy",
+	})
+	condition = computeOperatorDegradedCondition(nil, namespace, nil, nil, routerImagePolicyViolations(ingresses))
+	if condition.Status != configv1.ConditionTrue || condition.Reason != "RouterImagePolicyViolation" {
+		t.Errorf("expected Degraded=True with reason RouterImagePolicyViolation, got %v with reason %q", condition.Status, condition.Reason)
+	}
+}
+
+func TestComputeOperatorStatusExtension(t *testing.T) {
+	testCases := []struct {
+		description  string
+		featureGates []string
+		expected     string
+	}{
+		{"no feature gates", nil, `{"enabledFeatureGates":[]}`},
+		{"gateway API", []string{"GatewayAPI"}, `{"enabledFeatureGates":["GatewayAPI"]}`},
+	}
+	for _, tc := range testCases {
+		extension, err := computeOperatorStatusExtension(tc.featureGates)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.description, err)
+		}
+		if string(extension.Raw) != tc.expected {
+			t.Errorf("%q: expected %s, got %s", tc.description, tc.expected, string(extension.Raw))
+		}
+	}
+}
+
+// TestComputeRelatedObjects verifies that the clusteroperator's related objects
+// include each ingresscontroller and its operands.
+func TestComputeRelatedObjects(t *testing.T) {
+	ingresses := []operatorv1.IngressController{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "openshift-ingress-operator",
+				Name:        "edge",
+				Annotations: map[string]string{RouterWorkloadKindAnnotation: "DaemonSet"},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.HostNetworkStrategyType,
+				},
+			},
+		},
+	}
+	expected := []configv1.ObjectReference{
+		{Resource: "namespaces", Name: "openshift-ingress-operator"},
+		{Resource: "namespaces", Name: "openshift-ingress"},
+		{Group: "operator.openshift.io", Resource: "ingresscontrollers", Namespace: "openshift-ingress-operator"},
+		{Group: "ingress.operator.openshift.io", Resource: "dnsrecords", Namespace: "openshift-ingress-operator"},
+		{Group: "operator.openshift.io", Resource: "ingresscontrollers", Namespace: "openshift-ingress-operator", Name: "default"},
+		{Group: "apps", Resource: "deployments", Namespace: "openshift-ingress", Name: "router-default"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: "router-internal-default"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: "router-default"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: "router-default-alternate"},
+		{Group: "ingress.operator.openshift.io", Resource: "dnsrecords", Namespace: "openshift-ingress-operator", Name: "default-wildcard"},
+		{Group: "operator.openshift.io", Resource: "ingresscontrollers", Namespace: "openshift-ingress-operator", Name: "edge"},
+		{Group: "apps", Resource: "daemonsets", Namespace: "openshift-ingress", Name: "router-edge"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: "router-internal-edge"},
+	}
+	actual := computeRelatedObjects("openshift-ingress-operator", "openshift-ingress", ingresses)
+	if diff := cmp.Diff(expected, actual); len(diff) != 0 {
+		t.Errorf("unexpected related objects (-want +got):\n%s", diff)
+	}
+}
+
+// TestIngressControllerRelatedObjects verifies that the related objects of an
+// ingresscontroller with an internal load balancer include both load balancer
+// services and both wildcard DNSRecords.
+func TestIngressControllerRelatedObjects(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress-operator",
+			Name:        "default",
+			Annotations: map[string]string{InternalLoadBalancerAnnotation: "Enabled"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	expected := []configv1.ObjectReference{
+		{Group: "operator.openshift.io", Resource: "ingresscontrollers", Namespace: "openshift-ingress-operator", Name: "default"},
+		{Group: "apps", Resource: "deployments", Namespace: "openshift-ingress", Name: "router-default"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: "router-internal-default"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: "router-default"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: "router-default-alternate"},
+		{Group: "ingress.operator.openshift.io", Resource: "dnsrecords", Namespace: "openshift-ingress-operator", Name: "default-wildcard"},
+		{Resource: "services", Namespace: "openshift-ingress", Name: InternalLoadBalancerServiceName(ic, "openshift-ingress").Name},
+		{Group: "ingress.operator.openshift.io", Resource: "dnsrecords", Namespace: "openshift-ingress-operator", Name: InternalWildcardDNSRecordName(ic).Name},
+	}
+	actual := ingressControllerRelatedObjects(ic, "openshift-ingress")
+	if diff := cmp.Diff(expected, actual); len(diff) != 0 {
+		t.Errorf("unexpected related objects (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// upgradeBlocker is a configuration that is known to break across upgrades and
+// that therefore makes the operator report Upgradeable=False.
+type upgradeBlocker struct {
+	reason  string
+	message string
+}
+
+// cloudLoadBalancerAnnotationPrefixes are the prefixes of the service
+// annotations that configure cloud load balancers.
+var cloudLoadBalancerAnnotationPrefixes = []string{
+	"service.beta.kubernetes.io/",
+	"service.kubernetes.io/",
+	"cloud.google.com/",
+}
+
+// operatorLoadBalancerAnnotations returns the keys of the cloud load balancer
+// annotations that the operator sets on LB services for some configuration of
+// the ingresscontroller or the platform.  Such an annotation that the desired
+// service does not have was set by the operator for an earlier configuration
+// and is not one that a user added.
+func operatorLoadBalancerAnnotations() sets.String {
+	keys := sets.NewString(awsLBProxyProtocolAnnotation, awsLBAdditionalResourceTagsAnnotation)
+	keys.Insert(awsLBHealthCheckAnnotations...)
+	for _, annotation := range internalLBAnnotations {
+		keys.Insert(annotation.key)
+	}
+	return keys
+}
+
+// unmanagedIngressControllersBlocker returns the upgrade blocker for the given
+// ingresscontrollers that are not in the Managed state, or nil if there are
+// none.  The operator does not update the operands of such ingresscontrollers,
+// so they would keep running the previous release's routers.
+func unmanagedIngressControllersBlocker(unmanagedIngresses []string) *upgradeBlocker {
+	if len(unmanagedIngresses) == 0 {
+		return nil
+	}
+	return &upgradeBlocker{
+		reason:  "IngressControllersUnmanaged",
+		message: fmt.Sprintf("Some ingress controllers are not managed: %s", strings.Join(unmanagedIngresses, ", ")),
+	}
+}
+
+// computeUnmanagedLoadBalancerAnnotationsBlocker returns the upgrade blocker
+// for the given ingresscontrollers whose load balancer services have cloud
+// load balancer annotations that the operator does not set, or nil if there
+// are none.  Such annotations are added to the services directly rather than
+// configured through the ingresscontroller, and they are lost when an upgrade
+// recreates a service, which changes or breaks its load balancer.
+func (r *reconciler) computeUnmanagedLoadBalancerAnnotationsBlocker(ingresses []operatorv1.IngressController) (*upgradeBlocker, error) {
+	infraConfig := &configv1.Infrastructure{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig); err != nil {
+		return nil, fmt.Errorf("failed to get infrastructure 'cluster': %v", err)
+	}
+	var affected []string
+	for i := range ingresses {
+		ic := &ingresses[i]
+		if ic.Status.EndpointPublishingStrategy == nil || ic.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
+			continue
+		}
+		current, err := r.currentLoadBalancerService(ic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get load balancer service for ingresscontroller %s: %v", ic.Name, err)
+		}
+		// An invalid configuration is reported by admission.
+		desired, err := desiredLoadBalancerService(ic, r.Config.OperandNamespace, metav1.OwnerReference{}, infraConfig)
+		if current == nil || desired == nil || err != nil {
+			continue
+		}
+		if err := r.setLoadBalancerResourceTags(desired, infraConfig); err != nil {
+			return nil, err
+		}
+		if keys := unmanagedLoadBalancerAnnotations(current, desired); len(keys) != 0 {
+			affected = append(affected, fmt.Sprintf("%s (%s)", ic.Name, strings.Join(keys, ", ")))
+		}
+	}
+	if len(affected) == 0 {
+		return nil, nil
+	}
+	return &upgradeBlocker{
+		reason:  "UnmanagedLoadBalancerAnnotations",
+		message: fmt.Sprintf("The load balancer services of some ingress controllers have annotations that are not configured through the ingress controllers and are lost if the services are recreated: %s", strings.Join(affected, "; ")),
+	}, nil
+}
+
+// unmanagedLoadBalancerAnnotations returns the sorted keys of the cloud load
+// balancer annotations that the given current service has but the given
+// desired service does not, other than those that the operator sets.
+func unmanagedLoadBalancerAnnotations(current, desired *corev1.Service) []string {
+	var keys []string
+	managed := operatorLoadBalancerAnnotations()
+	for k := range current.Annotations {
+		if _, ok := desired.Annotations[k]; ok || managed.Has(k) {
+			continue
+		}
+		for _, prefix := range cloudLoadBalancerAnnotationPrefixes {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestComputeOperatorUpgradeableCondition(t *testing.T) {
+	unmanaged := *unmanagedIngressControllersBlocker([]string{"a", "b"})
+	annotations := upgradeBlocker{reason: "UnmanagedLoadBalancerAnnotations", message: "annotations"}
+	testCases := []struct {
+		description   string
+		blockers      []upgradeBlocker
+		expectStatus  configv1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			description:  "no blockers",
+			expectStatus: configv1.ConditionTrue,
+		},
+		{
+			description:   "unmanaged ingresscontrollers",
+			blockers:      []upgradeBlocker{unmanaged},
+			expectStatus:  configv1.ConditionFalse,
+			expectReason:  "IngressControllersUnmanaged",
+			expectMessage: "Some ingress controllers are not managed: a, b",
+		},
+		{
+			description:   "multiple blockers",
+			blockers:      []upgradeBlocker{unmanaged, annotations},
+			expectStatus:  configv1.ConditionFalse,
+			expectReason:  "MultipleUpgradeBlockers",
+			expectMessage: "Some ingress controllers are not managed: a, b; annotations",
+		},
+	}
+	for _, tc := range testCases {
+		condition := computeOperatorUpgradeableCondition(nil, tc.blockers)
+		if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason || condition.Message != tc.expectMessage {
+			t.Errorf("%q: expected status %s, reason %q, and message %q, got %#v", tc.description, tc.expectStatus, tc.expectReason, tc.expectMessage, condition)
+		}
+	}
+}
+
+func TestUnmanagedLoadBalancerAnnotations(t *testing.T) {
+	current := &corev1.Service{}
+	current.Annotations = map[string]string{
+		"service.beta.kubernetes.io/aws-load-balancer-proxy-protocol": "*",
+		"service.beta.kubernetes.io/aws-load-balancer-type":           "nlb",
+		"service.kubernetes.io/topology-aware-hints":                  "auto",
+		"example.com/owner": "team-a",
+		// Set by the operator for an earlier configuration.
+		"service.beta.kubernetes.io/aws-load-balancer-healthcheck-interval": "10",
+		"service.beta.kubernetes.io/aws-load-balancer-internal":             "0.0.0.0/0",
+	}
+	desired := &corev1.Service{}
+	desired.Annotations = map[string]string{
+		"service.beta.kubernetes.io/aws-load-balancer-proxy-protocol": "*",
+	}
+	expected := []string{
+		"service.beta.kubernetes.io/aws-load-balancer-type",
+		"service.kubernetes.io/topology-aware-hints",
+	}
+	if actual := unmanagedLoadBalancerAnnotations(current, desired); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,180 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// crdEstablishedPollInterval is how often the operator checks whether
+	// the CRDs that it installs have been established.
+	crdEstablishedPollInterval = 2 * time.Second
+
+	// crdsNotEstablishedReason is the reason of the clusteroperator's
+	// Progressing condition while the operator waits for its CRDs to be
+	// established.
+	crdsNotEstablishedReason = "CustomResourceDefinitionsNotEstablished"
+)
+
+// ensureCRDs creates or updates the given CRDs.
+func ensureCRDs(kubeClient client.Client, crds []*apiextensionsv1beta1.CustomResourceDefinition) error {
+	for _, desired := range crds {
+		current := &apiextensionsv1beta1.CustomResourceDefinition{}
+		if err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: desired.Name}, current); err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to get CRD %s: %v", desired.Name, err)
+			}
+			if err := kubeClient.Create(context.TODO(), desired.DeepCopy()); err != nil {
+				if errors.IsAlreadyExists(err) {
+					// Another replica of the operator
+					// created it first.
+					continue
+				}
+				return fmt.Errorf("failed to create CRD %s: %v", desired.Name, err)
+			}
+			log.Info("created CRD", "name", desired.Name)
+			continue
+		}
+		if changed, updated := crdChanged(current, desired); changed {
+			if err := kubeClient.Update(context.TODO(), updated); err != nil {
+				return fmt.Errorf("failed to update CRD %s: %v", desired.Name, err)
+			}
+			log.Info("updated CRD", "name", desired.Name)
+		}
+	}
+	return nil
+}
+
+// crdChanged checks whether the current CRD matches the expected CRD and if
+// not returns an updated CRD.  Only the fields that the operator's manifests
+// specify are compared so that defaulting by the API server does not cause
+// spurious updates.
+func crdChanged(current, expected *apiextensionsv1beta1.CustomResourceDefinition) (bool, *apiextensionsv1beta1.CustomResourceDefinition) {
+	if reflect.DeepEqual(current.Spec.Names, expected.Spec.Names) &&
+		reflect.DeepEqual(current.Spec.Versions, expected.Spec.Versions) &&
+		reflect.DeepEqual(current.Spec.Validation, expected.Spec.Validation) &&
+		reflect.DeepEqual(current.Spec.Subresources, expected.Spec.Subresources) &&
+		(expected.Spec.Conversion == nil || reflect.DeepEqual(current.Spec.Conversion, expected.Spec.Conversion)) &&
+		(len(expected.Spec.AdditionalPrinterColumns) == 0 || reflect.DeepEqual(current.Spec.AdditionalPrinterColumns, expected.Spec.AdditionalPrinterColumns)) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Spec.Names = expected.Spec.Names
+	updated.Spec.Versions = expected.Spec.Versions
+	updated.Spec.Validation = expected.Spec.Validation
+	updated.Spec.Subresources = expected.Spec.Subresources
+	if expected.Spec.Conversion != nil {
+		updated.Spec.Conversion = expected.Spec.Conversion
+	}
+	if len(expected.Spec.AdditionalPrinterColumns) != 0 {
+		updated.Spec.AdditionalPrinterColumns = expected.Spec.AdditionalPrinterColumns
+	}
+	// The version field is deprecated in favor of versions, and the API
+	// server rejects a CRD whose version does not match the first version.
+	if len(updated.Spec.Versions) != 0 {
+		updated.Spec.Version = updated.Spec.Versions[0].Name
+	}
+
+	return true, updated
+}
+
+// isCRDEstablished returns true if the given CRD has been established.
+func isCRDEstablished(crd *apiextensionsv1beta1.CustomResourceDefinition) bool {
+	for _, c := range crd.Status.Conditions {
+		if c.Type == apiextensionsv1beta1.Established {
+			return c.Status == apiextensionsv1beta1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// waitForCRDsEstablished blocks until all of the given CRDs have been
+// established or the stop channel is closed.  While it waits, it reports the
+// CRDs that are not yet established in the clusteroperator's Progressing
+// condition.
+func waitForCRDsEstablished(kubeClient client.Client, crds []*apiextensionsv1beta1.CustomResourceDefinition, stop <-chan struct{}) error {
+	return wait.PollImmediateUntil(crdEstablishedPollInterval, func() (bool, error) {
+		var pending []string
+		for _, desired := range crds {
+			current := &apiextensionsv1beta1.CustomResourceDefinition{}
+			if err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: desired.Name}, current); err != nil {
+				log.Error(err, "failed to get CRD", "name", desired.Name)
+				pending = append(pending, desired.Name)
+				continue
+			}
+			if !isCRDEstablished(current) {
+				pending = append(pending, current.Name)
+			}
+		}
+		if len(pending) == 0 {
+			return true, nil
+		}
+		sort.Strings(pending)
+		log.Info("waiting for CRDs to be established", "names", pending)
+		if err := setCRDsNotEstablishedCondition(kubeClient, pending); err != nil {
+			log.Error(err, "failed to update clusteroperator status")
+		}
+		return false, nil
+	}, stop)
+}
+
+// setCRDsNotEstablishedCondition sets the clusteroperator's Progressing
+// condition to report that the operator is blocked on the given CRDs.  The
+// operator controller recomputes the condition once it starts reconciling.
+func setCRDsNotEstablishedCondition(kubeClient client.Client, pending []string) error {
+	condition := configv1.ClusterOperatorStatusCondition{
+		Type:               configv1.OperatorProgressing,
+		Status:             configv1.ConditionTrue,
+		Reason:             crdsNotEstablishedReason,
+		Message:            fmt.Sprintf("Waiting for CustomResourceDefinitions to be established: %s", strings.Join(pending, ", ")),
+		LastTransitionTime: metav1.Now(),
+	}
+
+	co := &configv1.ClusterOperator{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: operatorcontroller.IngressClusterOperatorName}, co); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get clusteroperator %s: %v", operatorcontroller.IngressClusterOperatorName, err)
+		}
+		co.Name = operatorcontroller.IngressClusterOperatorName
+		if err := kubeClient.Create(context.TODO(), co); err != nil {
+			return fmt.Errorf("failed to create clusteroperator %s: %v", co.Name, err)
+		}
+		log.Info("created clusteroperator", "name", co.Name)
+	}
+
+	conditions := []configv1.ClusterOperatorStatusCondition{}
+	for _, c := range co.Status.Conditions {
+		if c.Type != condition.Type {
+			conditions = append(conditions, c)
+			continue
+		}
+		if c.Status == condition.Status && c.Reason == condition.Reason && c.Message == condition.Message {
+			return nil
+		}
+		if c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+	}
+	co.Status.Conditions = append(conditions, condition)
+	if err := kubeClient.Status().Update(context.TODO(), co); err != nil {
+		return fmt.Errorf("failed to update clusteroperator %s: %v", co.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+func TestCRDChanged(t *testing.T) {
+	testCases := []struct {
+		description string
+		mutate      func(*apiextensionsv1beta1.CustomResourceDefinition)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(_ *apiextensionsv1beta1.CustomResourceDefinition) {},
+			expect:      false,
+		},
+		{
+			description: "if the API server defaults the deprecated version field",
+			mutate: func(crd *apiextensionsv1beta1.CustomResourceDefinition) {
+				crd.Spec.Version = crd.Spec.Versions[0].Name
+			},
+			expect: false,
+		},
+		{
+			description: "if printer columns are missing",
+			mutate: func(crd *apiextensionsv1beta1.CustomResourceDefinition) {
+				crd.Spec.AdditionalPrinterColumns = nil
+			},
+			expect: true,
+		},
+		{
+			description: "if a version is added",
+			mutate: func(crd *apiextensionsv1beta1.CustomResourceDefinition) {
+				crd.Spec.Versions = crd.Spec.Versions[1:]
+			},
+			expect: true,
+		},
+		{
+			description: "if the validation schema changes",
+			mutate: func(crd *apiextensionsv1beta1.CustomResourceDefinition) {
+				crd.Spec.Validation = nil
+			},
+			expect: true,
+		},
+		{
+			description: "if the status subresource is removed",
+			mutate: func(crd *apiextensionsv1beta1.CustomResourceDefinition) {
+				crd.Spec.Subresources = nil
+			},
+			expect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, expected := range manifests.CustomResourceDefinitions() {
+			current := expected.DeepCopy()
+			tc.mutate(current)
+			if changed, updated := crdChanged(current, expected); changed != tc.expect {
+				t.Errorf("%s, expected crdChanged to be %t, got %t", tc.description, tc.expect, changed)
+			} else if changed {
+				if changedAgain, _ := crdChanged(updated, expected); changedAgain {
+					t.Errorf("%s, crdChanged does not behave as a fixed point function", tc.description)
+				}
+			}
+		}
+	}
+}
+
+func TestIsCRDEstablished(t *testing.T) {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if isCRDEstablished(crd) {
+		t.Errorf("expected CRD without conditions not to be established")
+	}
+	crd.Status.Conditions = []apiextensionsv1beta1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1beta1.NamesAccepted, Status: apiextensionsv1beta1.ConditionTrue},
+		{Type: apiextensionsv1beta1.Established, Status: apiextensionsv1beta1.ConditionTrue},
+	}
+	if !isCRDEstablished(crd) {
+		t.Errorf("expected CRD to be established")
+	}
+}
@@ -0,0 +1,49 @@
+package operator
+
+import (
+	"fmt"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// watchFeatureGates calls restart once if the cluster featuregate config
+// changes so that it enables a different set of operator features than the
+// given current set.  The operator enables features when it is created, so it
+// must restart to apply a change.  The watch is set up on every replica, not
+// only on the leader, so that a replica that later becomes the leader does not
+// run with stale features.
+func watchFeatureGates(configCache cache.Cache, current operatorconfig.FeatureGates, restart func()) error {
+	informer, err := configCache.GetInformer(&configv1.FeatureGate{})
+	if err != nil {
+		return fmt.Errorf("failed to create informer for featuregates: %v", err)
+	}
+	var once sync.Once
+	check := func(obj interface{}) {
+		featureGate, ok := obj.(*configv1.FeatureGate)
+		if !ok || featureGate.Name != "cluster" {
+			return
+		}
+		if desired := operatorconfig.FeatureGatesFor(featureGate); !desired.Equal(current) {
+			once.Do(func() {
+				log.Info("feature gates changed; restarting to apply them", "current", current, "desired", desired)
+				restart()
+			})
+		}
+	}
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    check,
+		UpdateFunc: func(_, obj interface{}) { check(obj) },
+		DeleteFunc: func(obj interface{}) {
+			if featureGate, ok := obj.(*configv1.FeatureGate); ok && featureGate.Name == "cluster" {
+				check(&configv1.FeatureGate{ObjectMeta: featureGate.ObjectMeta})
+			}
+		},
+	})
+	return nil
+}
@@ -0,0 +1,118 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+)
+
+const (
+	// HealthProbeBindAddress is the address on which the operator serves
+	// its health and readiness endpoints.
+	HealthProbeBindAddress = ":9440"
+
+	// heartbeatTimeout is how long the leader may go without a heartbeat
+	// from the operator controller before it is reported unhealthy.  It
+	// allows several missed heartbeats so that a burst of slow
+	// reconciliations does not restart the operator.
+	heartbeatTimeout = 5 * operatorcontroller.HeartbeatInterval
+)
+
+// health tracks the state that the operator's health and readiness endpoints
+// report.  Every replica serves these endpoints whether or not it is the
+// leader.
+type health struct {
+	// failed is set to 1 if the manager or a secondary cache has failed.
+	failed int32
+	// cachesSynced is set to 1 once all secondary caches have synced.
+	cachesSynced int32
+	// leading is set to 1 once this replica has been elected leader.
+	leading int32
+	// lastHeartbeat is the time, in Unix nanoseconds, of the leader's
+	// most recent heartbeat from the operator controller.
+	lastHeartbeat int64
+}
+
+func (h *health) setFailed()       { atomic.StoreInt32(&h.failed, 1) }
+func (h *health) setCachesSynced() { atomic.StoreInt32(&h.cachesSynced, 1) }
+func (h *health) heartbeat()       { atomic.StoreInt64(&h.lastHeartbeat, time.Now().UnixNano()) }
+
+// setLeading records that this replica is the leader.  Election counts as a
+// heartbeat so that the heartbeat timeout starts when the controllers do.
+func (h *health) setLeading() {
+	h.heartbeat()
+	atomic.StoreInt32(&h.leading, 1)
+}
+
+func (h *health) isFailed() bool       { return atomic.LoadInt32(&h.failed) == 1 }
+func (h *health) isCachesSynced() bool { return atomic.LoadInt32(&h.cachesSynced) == 1 }
+func (h *health) isLeading() bool      { return atomic.LoadInt32(&h.leading) == 1 }
+
+// isWedged returns true if this replica is the leader and the operator
+// controller has not reported a heartbeat within heartbeatTimeout of the given
+// time.  Standby replicas run no controllers, so they are never wedged.
+func (h *health) isWedged(now time.Time) bool {
+	if !h.isLeading() {
+		return false
+	}
+	return now.Sub(time.Unix(0, atomic.LoadInt64(&h.lastHeartbeat))) > heartbeatTimeout
+}
+
+// healthz reports whether the operator process is alive.  A replica is
+// unhealthy if its manager or any of its caches has failed or, if it is the
+// leader, if its operator controller has stopped making progress, in which
+// case the kubelet should restart it.
+func (h *health) healthz(w http.ResponseWriter, _ *http.Request) {
+	if h.isFailed() {
+		http.Error(w, "operator manager or cache has failed", http.StatusInternalServerError)
+		return
+	}
+	if h.isWedged(time.Now()) {
+		http.Error(w, fmt.Sprintf("operator controller has not reported a heartbeat in %s", heartbeatTimeout), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "ok\nleader: %t\n", h.isLeading())
+}
+
+// readyz reports whether the operator is ready, that is, whether its caches
+// have synced.  A standby replica is ready once its caches have synced so
+// that it can take over quickly if the leader goes away.
+func (h *health) readyz(w http.ResponseWriter, _ *http.Request) {
+	if h.isFailed() {
+		http.Error(w, "operator manager or cache has failed", http.StatusServiceUnavailable)
+		return
+	}
+	if !h.isCachesSynced() {
+		http.Error(w, "caches have not synced", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "ok\nleader: %t\n", h.isLeading())
+}
+
+// serve serves the health and readiness endpoints on the given address until
+// the stop channel is closed.  The same server exposes /loglevel, which reports
+// the operator's log level.  The server is not authenticated, so /loglevel is
+// read-only; the level is changed through the operator's configuration.
+func (h *health) serve(addr string, stop <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", h.healthz)
+	mux.HandleFunc("/readyz", h.readyz)
+	mux.Handle("/loglevel", logf.LevelHandler())
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "failed to serve health probes", "address", addr)
+		}
+	}()
+	<-stop
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error(err, "failed to shut down health probe server")
+	}
+}
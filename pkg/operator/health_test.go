@@ -0,0 +1,85 @@
+package operator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	testCases := []struct {
+		description   string
+		mutate        func(*health)
+		expectHealthz int
+		expectReadyz  int
+	}{
+		{
+			description:   "caches not synced",
+			mutate:        func(_ *health) {},
+			expectHealthz: http.StatusOK,
+			expectReadyz:  http.StatusServiceUnavailable,
+		},
+		{
+			description:   "caches synced",
+			mutate:        func(h *health) { h.setCachesSynced() },
+			expectHealthz: http.StatusOK,
+			expectReadyz:  http.StatusOK,
+		},
+		{
+			description:   "caches synced and leading",
+			mutate:        func(h *health) { h.setCachesSynced(); h.setLeading() },
+			expectHealthz: http.StatusOK,
+			expectReadyz:  http.StatusOK,
+		},
+		{
+			description:   "failed",
+			mutate:        func(h *health) { h.setCachesSynced(); h.setFailed() },
+			expectHealthz: http.StatusInternalServerError,
+			expectReadyz:  http.StatusServiceUnavailable,
+		},
+		{
+			description: "leading without a recent heartbeat",
+			mutate: func(h *health) {
+				h.setCachesSynced()
+				h.setLeading()
+				h.lastHeartbeat = time.Now().Add(-2 * heartbeatTimeout).UnixNano()
+			},
+			expectHealthz: http.StatusInternalServerError,
+			expectReadyz:  http.StatusOK,
+		},
+		{
+			description: "standby without a heartbeat",
+			mutate: func(h *health) {
+				h.setCachesSynced()
+				h.lastHeartbeat = time.Now().Add(-2 * heartbeatTimeout).UnixNano()
+			},
+			expectHealthz: http.StatusOK,
+			expectReadyz:  http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		h := &health{}
+		tc.mutate(h)
+		w := httptest.NewRecorder()
+		h.healthz(w, httptest.NewRequest("GET", "/healthz", nil))
+		if w.Code != tc.expectHealthz {
+			t.Errorf("%s: expected healthz status %d, got %d", tc.description, tc.expectHealthz, w.Code)
+		}
+		w = httptest.NewRecorder()
+		h.readyz(w, httptest.NewRequest("GET", "/readyz", nil))
+		if w.Code != tc.expectReadyz {
+			t.Errorf("%s: expected readyz status %d, got %d", tc.description, tc.expectReadyz, w.Code)
+		}
+	}
+}
+
+// TestPprofHandler verifies that the pprof handler serves the pprof index.
+func TestPprofHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	pprofHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
@@ -3,28 +3,40 @@ package operator
 import (
 	"context"
 	"fmt"
-	"time"
+	"os"
+	"sync"
 
-	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/dns"
 	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
 	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
 	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	canarycontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/canary"
 	certcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/certificate"
 	certpublishercontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/certificate-publisher"
+	defaultingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/default-ingresscontroller"
+	dnsrecordcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/dnsrecord"
+	externalcertificatecontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/external-certificate"
+	gatewaycontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/gateway"
+	ingressconfigcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress-config"
+	operandgccontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/operand-gc"
+	routestatuscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/route-status"
+	shardmetricscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/shard-metrics"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 
 	"k8s.io/client-go/rest"
 
 	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 
@@ -44,7 +56,13 @@ var (
 const (
 	// DefaultIngressController is the name of the default IngressController
 	// instance.
-	DefaultIngressController = "default"
+	DefaultIngressController = operatorcontroller.DefaultIngressControllerName
+
+	// leaderElectionID is the name of the configmap in the operator
+	// namespace that replicas of the operator use as the leader election
+	// lock.  Only the leader runs controllers; every replica serves
+	// metrics.
+	leaderElectionID = "ingress-operator-lock"
 )
 
 func init() {
@@ -57,12 +75,20 @@ func init() {
 // them together. Operator knows what namespace the operator lives in, and what
 // specific resoure types in other namespaces should produce operator events.
 type Operator struct {
-	client client.Client
-
 	manager manager.Manager
 	caches  []cache.Cache
+	health  *health
 
-	namespace string
+	// config is the configuration with which the operator was created,
+	// and reloadable holds the parts of it that are applied without
+	// restarting.
+	config     operatorconfig.Config
+	reloadable *operatorconfig.Reloadable
+
+	// restart is closed when the operator must restart to apply a
+	// configuration change.
+	restart     chan struct{}
+	restartOnce sync.Once
 }
 
 // New creates (but does not start) a new operator from configuration.
@@ -72,23 +98,85 @@ func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.
 		return nil, fmt.Errorf("failed to create kube client: %v", err)
 	}
 
+	// Install the operator's own CRDs and wait for them to be established
+	// before the manager is created.  The manager's REST mapper is built
+	// from API discovery when it is created, so the resources that the CRDs
+	// define must be served by then for controllers to watch them.
+	crds := manifests.CustomResourceDefinitions()
+	if err := ensureCRDs(kubeClient, crds); err != nil {
+		return nil, fmt.Errorf("failed to ensure CRDs: %v", err)
+	}
+	if err := waitForCRDsEstablished(kubeClient, crds, nil); err != nil {
+		return nil, fmt.Errorf("failed to wait for CRDs to be established: %v", err)
+	}
+	// Rebuild the kube client so that its REST mapper knows about the
+	// resources that the CRDs define.
+	kubeClient, err = operatorclient.NewClient(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kube client: %v", err)
+	}
+
 	scheme := operatorclient.GetScheme()
 	// Set up an operator manager for the operator namespace.
 	operatorManager, err := manager.New(kubeConfig, manager.Options{
-		Namespace: config.Namespace,
-		Scheme:    scheme,
+		Namespace:               config.Namespaces.Operator,
+		Scheme:                  scheme,
+		LeaderElection:          true,
+		LeaderElectionNamespace: config.Namespaces.Operator,
+		LeaderElectionID:        leaderElectionID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create operator manager: %v", err)
 	}
 
+	operandNamespace := config.Namespaces.Operand
+	if len(operandNamespace) == 0 {
+		operandNamespace = operatorcontroller.DefaultOperandNamespace
+	}
+
+	loadBalancerDeleter, _ := dnsManager.(dns.LoadBalancerDeleter)
+	reloadable := operatorconfig.NewReloadable(config)
+	shardLoads := operatorcontroller.NewShardLoads()
+	canaryResults := operatorcontroller.NewCanaryResults()
+
+	// The operator controller reads router pods from a cache of the operand
+	// namespace and nodes from a cluster-scoped cache, which the other
+	// controllers use for cluster config resources, rather than listing
+	// them from the API on every reconciliation.
+	mapper, err := apiutil.NewDiscoveryRESTMapper(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API Group-Resources")
+	}
+	operandCache, err := cache.New(kubeConfig, cache.Options{Namespace: operandNamespace, Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create openshift-ingress cache: %v", err)
+	}
+	configCache, err := cache.New(kubeConfig, cache.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster config cache: %v", err)
+	}
+	if _, err := operandCache.GetInformer(&corev1.Pod{}); err != nil {
+		return nil, fmt.Errorf("failed to get informer for pods: %v", err)
+	}
+
 	// Create and register the operator controller with the operator manager.
+	operatorHealth := &health{}
 	operatorController, err := operatorcontroller.New(operatorManager, operatorcontroller.Config{
-		KubeConfig:             kubeConfig,
-		Namespace:              config.Namespace,
-		DNSManager:             dnsManager,
-		IngressControllerImage: config.IngressControllerImage,
-		OperatorReleaseVersion: config.OperatorReleaseVersion,
+		KubeConfig:              kubeConfig,
+		Namespace:               config.Namespaces.Operator,
+		OperandNamespace:        operandNamespace,
+		IngressControllerImage:  config.Images.IngressController,
+		OperatorReleaseVersion:  config.OperatorReleaseVersion,
+		CanaryImage:             config.Images.Canary,
+		MaxConcurrentReconciles: config.Reconcile.MaxConcurrentReconciles,
+		Reloadable:              reloadable,
+		FeatureGates:            config.FeatureGates,
+		LoadBalancerDeleter:     loadBalancerDeleter,
+		ShardLoads:              shardLoads,
+		CanaryResults:           canaryResults,
+		Heartbeat:               operatorHealth.heartbeat,
+		OperandCache:            operandCache,
+		ClusterCache:            configCache,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create operator controller: %v", err)
@@ -97,20 +185,14 @@ func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.
 	// Create additional controller event sources from informers in the managed
 	// namespace. Any new managed resources outside the operator's namespace
 	// should be added here.
-	mapper, err := apiutil.NewDiscoveryRESTMapper(kubeConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get API Group-Resources")
-	}
-	operandCache, err := cache.New(kubeConfig, cache.Options{Namespace: "openshift-ingress", Scheme: scheme, Mapper: mapper})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create openshift-ingress cache: %v", err)
-	}
 	// Any types added to the list here will only queue a ingresscontroller if the
 	// resource has the expected label associating the resource with a
 	// ingresscontroller.
 	for _, o := range []runtime.Object{
 		&appsv1.Deployment{},
+		&appsv1.DaemonSet{},
 		&corev1.Service{},
+		&networkingv1.NetworkPolicy{},
 	} {
 		// TODO: may not be necessary to copy, but erring on the side of caution for
 		// now given we're in a loop.
@@ -127,7 +209,7 @@ func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.
 					return []reconcile.Request{
 						{
 							NamespacedName: types.NamespacedName{
-								Namespace: config.Namespace,
+								Namespace: config.Namespaces.Operator,
 								Name:      ingressName,
 							},
 						},
@@ -136,47 +218,260 @@ func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.
 					return []reconcile.Request{}
 				}
 			}),
-		})
+		}, operandPredicate)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create watch for %v: %v", obj, err)
 		}
 	}
 
-	// Set up the certificate controller
-	if _, err := certcontroller.New(operatorManager, kubeClient, config.Namespace); err != nil {
+	// Router pods mount secrets and configmaps whose contents are hashed
+	// into the router deployment's pod template, so queue the owning
+	// ingresscontroller when any of them changes.  Several
+	// ingresscontrollers may reference the same default certificate secret;
+	// the secret is watched once, and every ingresscontroller whose routers
+	// mount it is queued and rolls out when it changes.
+	// Ingresscontrollers that reference the object in their spec are queued
+	// as well, even if their routers do not mount it yet.  The injected CA
+	// bundles are not mounted but are combined into the default destination
+	// CA bundle that all routers mount, so queue all ingresscontrollers
+	// when either changes.
+	for _, o := range []runtime.Object{
+		&corev1.Secret{},
+		&corev1.ConfigMap{},
+	} {
+		obj := o.DeepCopyObject()
+		informer, err := operandCache.GetInformer(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get informer for %v: %v", obj, err)
+		}
+		err = operatorController.Watch(&source.Informer{Informer: informer}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				if _, ok := a.Object.(*corev1.ConfigMap); ok && operatorcontroller.IsDefaultDestinationCASource(a.Meta.GetName()) {
+					return allIngressControllers(kubeClient, config.Namespaces.Operator, a)
+				}
+				return mergeRequests(
+					mountingIngressControllers(operandCache, config.Namespaces.Operator, a),
+					referencingIngressControllers(operatorManager.GetCache(), config.Namespaces.Operator, a),
+				)
+			}),
+		}, operandPredicate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create watch for %v: %v", obj, err)
+		}
+	}
+
+	// The service controller reports load balancer provisioning failures
+	// as events on the LB service, so queue the owning ingresscontroller
+	// when such an event is recorded.
+	eventInformer, err := operandCache.GetInformer(&corev1.Event{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get informer for events: %v", err)
+	}
+	if err := operatorController.Watch(&source.Informer{Informer: eventInformer}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			return eventIngressControllers(operandCache, config.Namespaces.Operator, a)
+		}),
+	}, loadBalancerEventPredicate); err != nil {
+		return nil, fmt.Errorf("failed to create watch for events: %v", err)
+	}
+
+	if _, err := certcontroller.New(operatorManager, kubeClient, config.Namespaces.Operator, operandNamespace); err != nil {
 		return nil, fmt.Errorf("failed to create cacert controller: %v", err)
 	}
 
+	// Set up the ingress-config controller with a cluster-scoped cache for
+	// cluster config resources.
+	userConfigCache, err := cache.New(kubeConfig, cache.Options{Namespace: operatorcontroller.GlobalUserSpecifiedConfigNamespace, Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s cache: %v", operatorcontroller.GlobalUserSpecifiedConfigNamespace, err)
+	}
+	if _, err := ingressconfigcontroller.New(operatorManager, configCache, userConfigCache, kubeConfig, config.Namespaces.Operator); err != nil {
+		return nil, fmt.Errorf("failed to create ingress-config controller: %v", err)
+	}
+
+	// Ingresscontrollers may scale with the number of eligible nodes, and
+	// the availability of HostNetwork routers follows that of their nodes,
+	// so queue them when a node is added, removed, or changes eligibility,
+	// for example when it is cordoned or becomes not ready.
+	nodeInformer, err := configCache.GetInformer(&corev1.Node{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get informer for nodes: %v", err)
+	}
+	if err := operatorController.Watch(&source.Informer{Informer: nodeInformer}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			return nodeIngressControllers(kubeClient, config.Namespaces.Operator, a)
+		}),
+	}, nodePredicate); err != nil {
+		return nil, fmt.Errorf("failed to create watch for nodes: %v", err)
+	}
+
+	// Routers use the cluster proxy, so queue all ingresscontrollers when
+	// the proxy config changes.
+	proxyInformer, err := configCache.GetInformer(&configv1.Proxy{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get informer for proxies: %v", err)
+	}
+	if err := operatorController.Watch(&source.Informer{Informer: proxyInformer}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			return allIngressControllers(kubeClient, config.Namespaces.Operator, a)
+		}),
+	}, operandPredicate); err != nil {
+		return nil, fmt.Errorf("failed to create watch for proxies: %v", err)
+	}
+
+	// LB services are tagged with the infrastructure's user-defined AWS
+	// resource tags, so queue all ingresscontrollers when the
+	// infrastructure config changes.  The vendored config API does not
+	// have the resource tags, so the infrastructure config is watched as
+	// an unstructured object so that a change to them is not filtered out.
+	infra := &unstructured.Unstructured{}
+	infra.SetGroupVersionKind(operatorcontroller.InfrastructureGVK)
+	infraInformer, err := configCache.GetInformer(infra)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get informer for infrastructures: %v", err)
+	}
+	if err := operatorController.Watch(&source.Informer{Informer: infraInformer}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			return allIngressControllers(kubeClient, config.Namespaces.Operator, a)
+		}),
+	}, operandPredicate); err != nil {
+		return nil, fmt.Errorf("failed to create watch for infrastructures: %v", err)
+	}
+
+	// Router image policies may require signatures of the router image's
+	// digest, so queue all ingresscontrollers when the signatures change.
+	if err := operatorController.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			if a.Meta.GetName() != operatorcontroller.RouterImageSignaturesConfigMapName {
+				return nil
+			}
+			return allIngressControllers(kubeClient, config.Namespaces.Operator, a)
+		}),
+	}, operandPredicate); err != nil {
+		return nil, fmt.Errorf("failed to create watch for router image signatures: %v", err)
+	}
+
+	// Set up the default-ingresscontroller controller
+	if _, err := defaultingresscontroller.New(operatorManager, kubeClient, config.Namespaces.Operator); err != nil {
+		return nil, fmt.Errorf("failed to create default-ingresscontroller controller: %v", err)
+	}
+
 	// Set up the certificate-publisher controller
-	if _, err := certpublishercontroller.New(operatorManager, operandCache, kubeClient, config.Namespace, "openshift-ingress"); err != nil {
+	if _, err := certpublishercontroller.New(operatorManager, operandCache, kubeClient, config.Namespaces.Operator, operandNamespace); err != nil {
 		return nil, fmt.Errorf("failed to create certificate-publisher controller: %v", err)
 	}
 
-	return &Operator{
-		manager: operatorManager,
-		caches:  []cache.Cache{operandCache},
+	// Set up the dnsrecord controller
+	if _, err := dnsrecordcontroller.New(operatorManager, configCache, kubeClient, dnsManager, config.Namespaces.Operator); err != nil {
+		return nil, fmt.Errorf("failed to create dnsrecord controller: %v", err)
+	}
 
-		// TODO: These are only needed for the default ingress controller stuff, which
-		// should be refactored away.
-		client:    kubeClient,
-		namespace: config.Namespace,
-	}, nil
-}
+	// Set up the route-status controller
+	if _, err := routestatuscontroller.New(operatorManager, kubeClient, config.Namespaces.Operator); err != nil {
+		return nil, fmt.Errorf("failed to create route-status controller: %v", err)
+	}
 
-// Start creates the default IngressController and then starts the operator
-// synchronously until a message is received on the stop channel.
-// TODO: Move the default IngressController logic elsewhere.
-func (o *Operator) Start(stop <-chan struct{}) error {
-	// Periodicaly ensure the default controller exists.
-	go wait.Until(func() {
-		err := o.ensureDefaultIngressController()
+	// Set up the external-certificate controller.  It is set up even if
+	// the RouteExternalCertificate feature is disabled so that it removes
+	// the RBAC that it created while the feature was enabled.
+	if _, err := externalcertificatecontroller.New(operatorManager, configCache, kubeClient, config.Namespaces.Operator, operandNamespace, config.FeatureGates.Enabled(operatorconfig.RouteExternalCertificateFeature)); err != nil {
+		return nil, fmt.Errorf("failed to create external-certificate controller: %v", err)
+	}
+
+	// Set up the canary controller if a canary image is configured.  The
+	// operator reports the canary's version once the canary daemonset has
+	// rolled out the image, so queue the default ingresscontroller, whose
+	// reconciliation updates the clusteroperator, when the daemonset
+	// changes.
+	var canaryCache cache.Cache
+	if len(config.Images.Canary) != 0 {
+		canaryCache, err = cache.New(kubeConfig, cache.Options{Namespace: operatorcontroller.CanaryNamespace, Scheme: scheme, Mapper: mapper})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s cache: %v", operatorcontroller.CanaryNamespace, err)
+		}
+		if _, err := canarycontroller.New(operatorManager, canaryCache, kubeClient, config.Images.Canary, canaryResults, config.Namespaces.Operator); err != nil {
+			return nil, fmt.Errorf("failed to create canary controller: %v", err)
+		}
+		canaryDaemonSetInformer, err := canaryCache.GetInformer(&appsv1.DaemonSet{})
 		if err != nil {
-			log.Error(err, "failed to ensure default ingresscontroller")
+			return nil, fmt.Errorf("failed to get informer for the canary daemonset: %v", err)
+		}
+		if err := operatorController.Watch(&source.Informer{Informer: canaryDaemonSetInformer}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: config.Namespaces.Operator, Name: operatorcontroller.DefaultIngressControllerName}}}
+			}),
+		}, operandPredicate); err != nil {
+			return nil, fmt.Errorf("failed to create watch for the canary daemonset: %v", err)
+		}
+	}
+
+	// Set up the shard-metrics controller
+	if _, err := shardmetricscontroller.New(operatorManager, kubeClient, shardLoads, config.Namespaces.Operator); err != nil {
+		return nil, fmt.Errorf("failed to create shard-metrics controller: %v", err)
+	}
+
+	// Set up the operand-gc controller
+	if _, err := operandgccontroller.New(operatorManager, operandCache, kubeClient, config.Namespaces.Operator, operandNamespace); err != nil {
+		return nil, fmt.Errorf("failed to create operand-gc controller: %v", err)
+	}
+
+	// Set up the gateway controller if the Gateway API is enabled and
+	// installed.
+	if config.FeatureGates.Enabled(operatorconfig.GatewayAPIFeature) {
+		if installed, err := gatewaycontroller.IsGatewayAPIInstalled(mapper); err != nil {
+			return nil, fmt.Errorf("failed to check for the Gateway API: %v", err)
+		} else if !installed {
+			log.Info("the Gateway API is enabled but not installed; gateways will not be reconciled")
+		} else if _, err := gatewaycontroller.New(operatorManager, configCache, kubeClient, config.Namespaces.Operator, operandNamespace); err != nil {
+			return nil, fmt.Errorf("failed to create gateway controller: %v", err)
 		}
-	}, 1*time.Minute, stop)
+	}
+
+	caches := []cache.Cache{operandCache, configCache, userConfigCache}
+	if canaryCache != nil {
+		caches = append(caches, canaryCache)
+	}
+	operator := &Operator{
+		manager:    operatorManager,
+		caches:     caches,
+		health:     operatorHealth,
+		config:     config,
+		reloadable: reloadable,
+		restart:    make(chan struct{}),
+	}
+
+	if err := watchFeatureGates(configCache, config.FeatureGates, operator.requestRestart); err != nil {
+		return nil, err
+	}
 
+	// Record leadership for the health endpoints.  Manager runnables only
+	// start once this replica has been elected leader.
+	if err := operatorManager.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		log.Info("elected leader")
+		operator.health.setLeading()
+		<-stop
+		return nil
+	})); err != nil {
+		return nil, fmt.Errorf("failed to add leader runnable: %v", err)
+	}
+
+	return operator, nil
+}
+
+// Start starts the operator synchronously until a message is received on the
+// stop channel or the operator must restart to apply a configuration change,
+// in which case Start returns nil so that the process exits and is restarted.
+// If multiple replicas of the operator are running, only the elected leader
+// runs controllers.
+func (o *Operator) Start(stop <-chan struct{}) error {
 	errChan := make(chan error)
 
+	// Serve health probes and reload the configuration file whether or
+	// not this replica is the leader.
+	go o.health.serve(HealthProbeBindAddress, stop)
+	go servePprof(PprofBindAddress, stop)
+	go o.watchConfig(os.Getenv, stop)
+
 	// Start secondary caches.
 	for _, cache := range o.caches {
 		go func() {
@@ -190,41 +485,186 @@ func (o *Operator) Start(stop <-chan struct{}) error {
 		}
 		log.Info("cache synced")
 	}
+	o.health.setCachesSynced()
 
 	// Secondary caches are all synced, so start the manager.
 	go func() {
 		errChan <- o.manager.Start(stop)
 	}()
 
-	// Wait for the manager to exit or a secondary cache to fail.
+	// Wait for the manager to exit, a secondary cache to fail, or a
+	// restart to be requested.
 	select {
 	case <-stop:
 		return nil
+	case <-o.restart:
+		return nil
 	case err := <-errChan:
+		o.health.setFailed()
 		return err
 	}
 }
 
-// ensureDefaultIngressController creates the default ingresscontroller if it
-// doesn't already exist.
-func (o *Operator) ensureDefaultIngressController() error {
-	ic := &operatorv1.IngressController{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      DefaultIngressController,
-			Namespace: o.namespace,
+// requestRestart makes Start return so that the operator restarts to apply a
+// configuration change.  It may be called more than once.
+func (o *Operator) requestRestart() {
+	o.restartOnce.Do(func() { close(o.restart) })
+}
+
+// eventIngressControllers returns a reconcile request for the ingresscontroller
+// that owns the service that the given event is about, if any.
+func eventIngressControllers(operandCache cache.Cache, operatorNamespace string, a handler.MapObject) []reconcile.Request {
+	event, ok := a.Object.(*corev1.Event)
+	if !ok {
+		return []reconcile.Request{}
+	}
+	service := &corev1.Service{}
+	name := types.NamespacedName{Namespace: event.InvolvedObject.Namespace, Name: event.InvolvedObject.Name}
+	if err := operandCache.Get(context.TODO(), name, service); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "failed to get service", "related", a.Meta.GetSelfLink())
+		}
+		return []reconcile.Request{}
+	}
+	ingressName, ok := service.Labels[manifests.OwningIngressControllerLabel]
+	if !ok {
+		return []reconcile.Request{}
+	}
+	log.Info("queueing ingress", "name", ingressName, "related", a.Meta.GetSelfLink())
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: operatorNamespace,
+			Name:      ingressName,
 		},
+	}}
+}
+
+// nodeIngressControllers returns a reconcile request for each
+// ingresscontroller whose replicas scale with the number of eligible nodes, and
+// for each HostNetwork ingresscontroller whose router node selector matches the
+// given node.
+func nodeIngressControllers(kubeClient client.Client, operatorNamespace string, a handler.MapObject) []reconcile.Request {
+	requests := []reconcile.Request{}
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := kubeClient.List(context.TODO(), ingresses, client.InNamespace(operatorNamespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers", "related", a.Meta.GetSelfLink())
+		return requests
 	}
-	err := o.client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, ic)
-	if err == nil {
-		return nil
+	node, _ := a.Object.(*corev1.Node)
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		_, scaled := ic.Annotations[operatorcontroller.MaxReplicasFromNodesAnnotation]
+		if !scaled && (node == nil || !operatorcontroller.RouterNodeSelectorMatches(ic, node)) {
+			continue
+		}
+		log.Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: operatorNamespace,
+				Name:      ic.Name,
+			},
+		})
 	}
-	if !errors.IsNotFound(err) {
-		return err
+	return requests
+}
+
+// allIngressControllers returns a reconcile request for each
+// ingresscontroller.
+func allIngressControllers(kubeClient client.Client, operatorNamespace string, a handler.MapObject) []reconcile.Request {
+	requests := []reconcile.Request{}
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := kubeClient.List(context.TODO(), ingresses, client.InNamespace(operatorNamespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers", "related", a.Meta.GetSelfLink())
+		return requests
 	}
-	err = o.client.Create(context.TODO(), ic)
-	if err != nil {
-		return err
+	for _, ic := range ingresses.Items {
+		log.Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: operatorNamespace,
+				Name:      ic.Name,
+			},
+		})
+	}
+	return requests
+}
+
+// referencingIngressControllers returns a reconcile request for each
+// ingresscontroller that references the given secret or configmap.
+func referencingIngressControllers(operatorCache cache.Cache, operatorNamespace string, a handler.MapObject) []reconcile.Request {
+	requests := []reconcile.Request{}
+	key := operatorcontroller.ReferencedObjectIndexKey(a.Object, a.Meta.GetName())
+	if len(key) == 0 {
+		return requests
+	}
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := operatorCache.List(context.TODO(), ingresses, client.InNamespace(operatorNamespace), client.MatchingField(operatorcontroller.ReferencedObjectsIndex, key)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers", "related", a.Meta.GetSelfLink())
+		return requests
+	}
+	for _, ic := range ingresses.Items {
+		log.Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: operatorNamespace,
+				Name:      ic.Name,
+			},
+		})
+	}
+	return requests
+}
+
+// mergeRequests returns the given reconcile requests without duplicates.
+func mergeRequests(requestLists ...[]reconcile.Request) []reconcile.Request {
+	requests := []reconcile.Request{}
+	seen := map[reconcile.Request]struct{}{}
+	for _, list := range requestLists {
+		for _, request := range list {
+			if _, ok := seen[request]; ok {
+				continue
+			}
+			seen[request] = struct{}{}
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// mountingIngressControllers returns a reconcile request for each
+// ingresscontroller whose router deployment mounts the given secret or
+// configmap.
+func mountingIngressControllers(operandCache cache.Cache, operatorNamespace string, a handler.MapObject) []reconcile.Request {
+	requests := []reconcile.Request{}
+	deployments := &appsv1.DeploymentList{}
+	if err := operandCache.List(context.TODO(), deployments, client.InNamespace(a.Meta.GetNamespace())); err != nil {
+		log.Error(err, "failed to list deployments", "related", a.Meta.GetSelfLink())
+		return requests
+	}
+	// Router daemonsets run the same pod template as router deployments,
+	// so check them as deployments.
+	daemonsets := &appsv1.DaemonSetList{}
+	if err := operandCache.List(context.TODO(), daemonsets, client.InNamespace(a.Meta.GetNamespace())); err != nil {
+		log.Error(err, "failed to list daemonsets", "related", a.Meta.GetSelfLink())
+		return requests
+	}
+	for _, daemonset := range daemonsets.Items {
+		deployments.Items = append(deployments.Items, appsv1.Deployment{
+			ObjectMeta: daemonset.ObjectMeta,
+			Spec:       appsv1.DeploymentSpec{Template: daemonset.Spec.Template},
+		})
+	}
+	for i := range deployments.Items {
+		ingressName, ok := deployments.Items[i].Labels[manifests.OwningIngressControllerLabel]
+		if !ok || !operatorcontroller.DeploymentMountsObject(&deployments.Items[i], a.Object, a.Meta.GetName()) {
+			continue
+		}
+		log.Info("queueing ingress", "name", ingressName, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: operatorNamespace,
+				Name:      ingressName,
+			},
+		})
 	}
-	log.Info("created default ingresscontroller", "namespace", ic.Namespace, "name", ic.Name)
-	return nil
+	return requests
 }
@@ -0,0 +1,48 @@
+package operator
+
+import (
+	"context"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+const (
+	// PprofBindAddress is the address on which the operator serves its
+	// pprof endpoints.  The address is on the loopback interface, so the
+	// endpoints are reachable only through "oc port-forward", which
+	// requires the pods/portforward permission in the operator namespace.
+	PprofBindAddress = "127.0.0.1:6061"
+)
+
+// pprofHandler returns a handler that serves the pprof endpoints under
+// /debug/pprof/.  Importing net/http/pprof also registers the endpoints on
+// http.DefaultServeMux, so no server in the operator may use
+// http.DefaultServeMux; the metrics and health servers have their own muxes,
+// and this dedicated mux is served only on PprofBindAddress.
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// servePprof serves the pprof endpoints on the given address until the stop
+// channel is closed.
+func servePprof(addr string, stop <-chan struct{}) {
+	server := &http.Server{Addr: addr, Handler: pprofHandler()}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "failed to serve pprof", "address", addr)
+		}
+	}()
+	<-stop
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error(err, "failed to shut down pprof server")
+	}
+}
@@ -0,0 +1,93 @@
+package operator
+
+import (
+	"reflect"
+
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// operandPredicate filters out update events for operands that do not reflect
+// any change the operator cares about.  Every write to an object bumps its
+// resourceVersion, including no-op updates and periodic resyncs, so without
+// filtering, each such event would re-enqueue the owning ingresscontroller.
+var operandPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return operandChanged(e.ObjectOld, e.ObjectNew)
+	},
+}
+
+// operandChanged returns true if old and new differ in anything other than
+// metadata that changes on every write.
+func operandChanged(old, new runtime.Object) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	oldCopy, newCopy := old.DeepCopyObject(), new.DeepCopyObject()
+	for _, o := range []runtime.Object{oldCopy, newCopy} {
+		accessor, err := meta.Accessor(o)
+		if err != nil {
+			return true
+		}
+		accessor.SetResourceVersion("")
+		accessor.SetSelfLink("")
+	}
+	return !reflect.DeepEqual(oldCopy, newCopy)
+}
+
+// nodePredicate filters out update events for nodes that do not change
+// whether the node can run router pods.  Nodes update their status
+// frequently, so without filtering, every heartbeat would re-enqueue the
+// ingresscontrollers that scale with nodes.
+var nodePredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		old, ok := e.ObjectOld.(*corev1.Node)
+		if !ok {
+			return true
+		}
+		new, ok := e.ObjectNew.(*corev1.Node)
+		if !ok {
+			return true
+		}
+		return nodeEligibilityChanged(old, new)
+	},
+}
+
+// nodeEligibilityChanged returns true if old and new differ in the labels,
+// schedulability, or readiness of the node.
+func nodeEligibilityChanged(old, new *corev1.Node) bool {
+	return !reflect.DeepEqual(old.Labels, new.Labels) ||
+		old.Spec.Unschedulable != new.Spec.Unschedulable ||
+		operatorcontroller.IsNodeReady(old) != operatorcontroller.IsNodeReady(new)
+}
+
+// loadBalancerEventPredicate filters out events other than load balancer
+// provisioning failures, which are the only events that the operator reports.
+var loadBalancerEventPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return isLoadBalancerFailureEvent(e.Object)
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return isLoadBalancerFailureEvent(e.ObjectNew)
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// isLoadBalancerFailureEvent returns true if the given object is an event that
+// reports a load balancer provisioning failure.
+func isLoadBalancerFailureEvent(o runtime.Object) bool {
+	e, ok := o.(*corev1.Event)
+	return ok && operatorcontroller.IsLoadBalancerFailureEvent(e)
+}
@@ -0,0 +1,142 @@
+package operator
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOperandChanged(t *testing.T) {
+	testCases := []struct {
+		description string
+		mutate      func(*appsv1.Deployment)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(_ *appsv1.Deployment) {},
+			expect:      false,
+		},
+		{
+			description: "if only .metadata.resourceVersion changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.ResourceVersion = "2"
+			},
+			expect: false,
+		},
+		{
+			description: "if .status.availableReplicas changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Status.AvailableReplicas = 2
+			},
+			expect: true,
+		},
+		{
+			description: "if .spec.template.spec.nodeSelector changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.NodeSelector = map[string]string{"xyzzy": "quux"}
+			},
+			expect: true,
+		},
+		{
+			description: "if a label is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Labels["foo"] = "bar"
+			},
+			expect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		original := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "router-default",
+				Namespace:       "openshift-ingress",
+				ResourceVersion: "1",
+				Labels:          map[string]string{},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						NodeSelector: map[string]string{"beta.kubernetes.io/os": "linux"},
+					},
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				AvailableReplicas: 1,
+			},
+		}
+		mutated := original.DeepCopy()
+		tc.mutate(mutated)
+		if changed := operandChanged(original, mutated); changed != tc.expect {
+			t.Errorf("%s, expected operandChanged to be %t, got %t", tc.description, tc.expect, changed)
+		}
+	}
+}
+
+func TestNodeEligibilityChanged(t *testing.T) {
+	testCases := []struct {
+		description string
+		mutate      func(*corev1.Node)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(_ *corev1.Node) {},
+			expect:      false,
+		},
+		{
+			description: "if only a heartbeat changes",
+			mutate: func(node *corev1.Node) {
+				node.ResourceVersion = "2"
+				node.Status.Conditions[0].LastHeartbeatTime = metav1.Unix(1, 0)
+			},
+			expect: false,
+		},
+		{
+			description: "if a label is added",
+			mutate: func(node *corev1.Node) {
+				node.Labels["node-role.kubernetes.io/infra"] = ""
+			},
+			expect: true,
+		},
+		{
+			description: "if the node is cordoned",
+			mutate: func(node *corev1.Node) {
+				node.Spec.Unschedulable = true
+			},
+			expect: true,
+		},
+		{
+			description: "if the node becomes not ready",
+			mutate: func(node *corev1.Node) {
+				node.Status.Conditions[0].Status = corev1.ConditionUnknown
+			},
+			expect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		original := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "worker-0",
+				ResourceVersion: "1",
+				Labels:          map[string]string{"node-role.kubernetes.io/worker": ""},
+			},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{
+					Type:   corev1.NodeReady,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+		}
+		mutated := original.DeepCopy()
+		tc.mutate(mutated)
+		if changed := nodeEligibilityChanged(original, mutated); changed != tc.expect {
+			t.Errorf("%s, expected nodeEligibilityChanged to be %t, got %t", tc.description, tc.expect, changed)
+		}
+	}
+}
@@ -207,7 +207,7 @@ func TestClusterProxyProtocol(t *testing.T) {
 	// Wait for the router deployment to exist.
 	deployment := &appsv1.Deployment{}
 	err = wait.PollImmediate(1*time.Second, 10*time.Second, func() (bool, error) {
-		if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ic), deployment); err != nil {
+		if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ic, ingresscontroller.DefaultOperandNamespace), deployment); err != nil {
 			return false, nil
 		}
 		return true, nil
@@ -234,7 +234,7 @@ func TestClusterProxyProtocol(t *testing.T) {
 	// Wait for the internal router service to exist.
 	internalService := &corev1.Service{}
 	err = wait.PollImmediate(1*time.Second, 10*time.Second, func() (bool, error) {
-		if err := cl.Get(context.TODO(), ingresscontroller.InternalIngressControllerServiceName(ic), internalService); err != nil {
+		if err := cl.Get(context.TODO(), ingresscontroller.InternalIngressControllerServiceName(ic, ingresscontroller.DefaultOperandNamespace), internalService); err != nil {
 			return false, nil
 		}
 		return true, nil
@@ -309,7 +309,7 @@ func TestIngressControllerUpdate(t *testing.T) {
 	// ingress controller, or the default if none is set, and store the
 	// secret name (if any) so we can reset it at the end of the test.
 	deployment := &appsv1.Deployment{}
-	if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ci), deployment); err != nil {
+	if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ci, ingresscontroller.DefaultOperandNamespace), deployment); err != nil {
 		t.Fatalf("failed to get default router deployment: %v", err)
 	}
 	originalSecret := ci.Spec.DefaultCertificate.DeepCopy()
@@ -476,7 +476,7 @@ func TestIngressControllerScale(t *testing.T) {
 	// Wait for the router deployment to exist.
 	deployment := &appsv1.Deployment{}
 	err = wait.PollImmediate(1*time.Second, 10*time.Second, func() (bool, error) {
-		if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ci), deployment); err != nil {
+		if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ci, ingresscontroller.DefaultOperandNamespace), deployment); err != nil {
 			return false, nil
 		}
 		return true, nil
@@ -671,7 +671,7 @@ func TestHostNetworkEndpointPublishingStrategy(t *testing.T) {
 	// Wait for the deployment to exist and be available.
 	err = wait.PollImmediate(1*time.Second, 60*time.Second, func() (bool, error) {
 		deployment := &appsv1.Deployment{}
-		if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ing), deployment); err != nil {
+		if err := cl.Get(context.TODO(), ingresscontroller.RouterDeploymentName(ing, ingresscontroller.DefaultOperandNamespace), deployment); err != nil {
 			return false, nil
 		}
 		if ing.Spec.Replicas == nil || deployment.Status.AvailableReplicas != *ing.Spec.Replicas {